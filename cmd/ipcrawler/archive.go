@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/archive"
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/privilege"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// archiveFormat returns the configured archive format, tar.gz by default
+func archiveFormat(cfg *config.Config) (string, error) {
+	switch format := strings.TrimPrefix(cfg.Output.Archive.Format, "."); format {
+	case "":
+		return archive.FormatTarGz, nil
+	case archive.FormatTarGz, archive.FormatZip:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown archive format %q (use tar.gz or zip)", cfg.Output.Archive.Format)
+	}
+}
+
+// archivePathFor is where a workspace's archive is written: output.archive.directory, or
+// next to the workspace
+func archivePathFor(cfg *config.Config, workspaceDir, format string) string {
+	dir := cfg.Output.Archive.Directory
+	if dir == "" {
+		dir = filepath.Dir(workspaceDir)
+	}
+	return filepath.Join(dir, archive.FileName(workspaceDir, format))
+}
+
+// archiveWorkspace packs a workspace, refusing one whose run has not finished, and removes the
+// directory afterwards when asked to. The archive is verified before anything is removed
+func archiveWorkspace(cfg *config.Config, workspaceDir, archivePath string, remove, force bool) (*archive.Summary, error) {
+	if manifest, err := session.LoadManifest(workspaceDir); err == nil && manifest.Status == session.RunStatusRunning && !force {
+		return nil, fmt.Errorf("%s is still running (use --force if the run died)", workspaceDir)
+	}
+	if _, err := os.Stat(archivePath); err == nil {
+		return nil, fmt.Errorf("%s already exists", archivePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(archivePath), cfg.Output.Permissions.DirPerm()); err != nil {
+		return nil, err
+	}
+	summary, err := workspace.New(workspaceDir).Archive(archivePath, cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		return nil, err
+	}
+	if remove {
+		if _, err := archive.Verify(archivePath); err != nil {
+			return summary, fmt.Errorf("kept %s: %v", workspaceDir, err)
+		}
+		if err := os.RemoveAll(workspaceDir); err != nil {
+			return summary, fmt.Errorf("failed to remove %s: %v", workspaceDir, err)
+		}
+	}
+	return summary, nil
+}
+
+// archiveRunWorkspace archives a finished run's workspace when output.archive.auto_archive is
+// set. Failed and interrupted runs are left alone, since they are resumed or rerun in place
+func archiveRunWorkspace(cfg *config.Config, workspaceDir, status string, invokingUser *privilege.RunAsUser, logger *log.Logger) {
+	settings := cfg.Output.Archive
+	if !settings.AutoArchive || status != session.RunStatusCompleted {
+		return
+	}
+	if _, err := os.Stat(workspaceDir); err != nil {
+		return // Stored and removed by upload_after_run
+	}
+	format, err := archiveFormat(cfg)
+	if err != nil {
+		logger.Warn("Failed to archive workspace", "error", err)
+		return
+	}
+	archivePath := archivePathFor(cfg, workspaceDir, format)
+	summary, err := archiveWorkspace(cfg, workspaceDir, archivePath, settings.RemoveWorkspace, false)
+	if err != nil {
+		logger.Warn("Failed to archive workspace", "archive", archivePath, "error", err)
+		return
+	}
+	if invokingUser != nil && cfg.Output.Permissions.ChownToInvokingUser {
+		if err := invokingUser.Chown(archivePath); err != nil {
+			logger.Warn("Failed to change archive ownership", "error", err)
+		}
+	}
+	logger.Info("Workspace archived", "archive", archivePath, "files", summary.Files)
+}
+
+// runArchiveCommand implements `ipcrawler archive`
+func runArchiveCommand(args []string) error {
+	fs := pflag.NewFlagSet("archive", pflag.ContinueOnError)
+	var (
+		format     = fs.String("format", "", "Archive format: tar.gz or zip (default: output.archive.format)")
+		outputPath = fs.StringP("output", "o", "", "Archive file to write (one workspace only)")
+		keep       = fs.Bool("keep", false, "Keep the workspace directory after archiving it")
+		force      = fs.Bool("force", false, "Archive a workspace whose manifest still says running")
+	)
+	fs.Usage = printArchiveUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printArchiveUsage()
+		return fmt.Errorf("a workspace is required")
+	}
+	if *outputPath != "" && fs.NArg() > 1 {
+		return fmt.Errorf("--output takes a single workspace")
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	if *format != "" {
+		cfg.Output.Archive.Format = *format
+	}
+	archiveType, err := archiveFormat(cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range fs.Args() {
+		workspaceDir, err := resolveWorkspace(arg)
+		if err != nil {
+			return err
+		}
+		archivePath := *outputPath
+		if archivePath == "" {
+			archivePath = archivePathFor(cfg, workspaceDir, archiveType)
+		}
+		summary, err := archiveWorkspace(cfg, workspaceDir, archivePath, !*keep, *force)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(archivePath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d files (%s) archived to %s (%s)\n", workspaceDir, summary.Files,
+			formatStoredSize(summary.Bytes), archivePath, formatStoredSize(info.Size()))
+	}
+	return nil
+}
+
+// runUnarchiveCommand implements `ipcrawler unarchive`
+func runUnarchiveCommand(args []string) error {
+	fs := pflag.NewFlagSet("unarchive", pflag.ContinueOnError)
+	var (
+		outputDir = fs.StringP("output", "o", "", "Directory to restore the workspace into (default: next to the archive)")
+		check     = fs.Bool("check", false, "Only verify the archive's checksums")
+		keep      = fs.Bool("keep", false, "Keep the archive after restoring it")
+	)
+	fs.Usage = printUnarchiveUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		printUnarchiveUsage()
+		return fmt.Errorf("exactly one archive is required")
+	}
+	archivePath := fs.Arg(0)
+
+	if *check {
+		summary, err := archive.Verify(archivePath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s, %d files (%s), every checksum matches\n", archivePath, summary.Workspace, summary.Files, formatStoredSize(summary.Bytes))
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	parent := *outputDir
+	if parent == "" {
+		parent = filepath.Dir(archivePath)
+	}
+	summary, err := archive.Extract(archivePath, parent, cfg.Output.Permissions.DirPerm(), cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		return err
+	}
+	workspaceDir := filepath.Join(parent, summary.Workspace)
+	if !*keep {
+		if err := os.Remove(archivePath); err != nil {
+			return fmt.Errorf("restored %s but failed to remove the archive: %v", workspaceDir, err)
+		}
+	}
+	fmt.Printf("%s: %d files (%s) restored to %s\n", archivePath, summary.Files, formatStoredSize(summary.Bytes), workspaceDir)
+	return nil
+}
+
+func printArchiveUsage() {
+	fmt.Println("Usage: ipcrawler archive [options] <workspace>...")
+	fmt.Println()
+	fmt.Println("Packs finished workspaces into single compressed files next to them (or in")
+	fmt.Println("output.archive.directory) and removes the directories. Each archive carries a")
+	fmt.Println("CHECKSUMS.sha256 manifest of its files, checked before a directory is removed and")
+	fmt.Println("again by `ipcrawler unarchive`. Set output.archive.auto_archive to archive every")
+	fmt.Println("completed run.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --format FORMAT   tar.gz or zip (default: output.archive.format, else tar.gz)")
+	fmt.Println("  -o, --output FILE     Archive file to write (one workspace only)")
+	fmt.Println("      --keep            Keep the workspace directory")
+	fmt.Println("      --force           Archive a workspace whose manifest still says running")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler archive ipcrawler_results/10_10_10_5_1735732800_1a2b3c4d")
+	fmt.Println("  ipcrawler archive --format zip --keep ipcrawler_results/*")
+}
+
+func printUnarchiveUsage() {
+	fmt.Println("Usage: ipcrawler unarchive [options] <archive>")
+	fmt.Println()
+	fmt.Println("Restores a workspace packed by `ipcrawler archive` next to the archive, then")
+	fmt.Println("removes the archive. Every file is checked against the archive's CHECKSUMS.sha256")
+	fmt.Println("first; nothing is restored from a damaged archive.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o, --output DIR      Directory to restore the workspace into")
+	fmt.Println("      --check           Only verify the checksums")
+	fmt.Println("      --keep            Keep the archive")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler unarchive ipcrawler_results/10_10_10_5_1735732800_1a2b3c4d.tar.gz")
+	fmt.Println("  ipcrawler unarchive --check backups/10_10_10_5_1735732800_1a2b3c4d.zip")
+}
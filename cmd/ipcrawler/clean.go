@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/retention"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// retentionPolicy converts output.retention into a policy
+func retentionPolicy(settings config.RetentionConfig) retention.Policy {
+	return retention.Policy{
+		MaxWorkspaces: settings.MaxWorkspaces,
+		MaxAge:        time.Duration(settings.MaxAgeDays) * 24 * time.Hour,
+		MaxTotalBytes: int64(settings.MaxTotalSizeGB * (1 << 30)),
+	}
+}
+
+// enforceRetention prunes the workspace base when a scan starts, keeping the scan's own
+// workspace. Failures are logged; they never stop the scan
+func enforceRetention(cfg *config.Config, baseDir, workspaceDir string, logger *log.Logger) {
+	settings := cfg.Output.Retention
+	policy := retentionPolicy(settings)
+	if !settings.EnforceAtStartup || !policy.Limited() {
+		return
+	}
+	workspaces, err := retention.Scan(baseDir, settings.IncludeArchives)
+	if err != nil {
+		logger.Warn("Failed to apply workspace retention", "error", err)
+		return
+	}
+	for _, workspace := range retention.Plan(workspaces, policy, time.Now(), workspaceDir) {
+		if err := retention.Remove(workspace); err != nil {
+			logger.Warn("Failed to remove workspace", "workspace", workspace.Path, "error", err)
+			continue
+		}
+		logger.Info("Workspace removed by retention policy", "workspace", workspace.Path, "reason", workspace.Reason)
+	}
+}
+
+// runCleanCommand implements `ipcrawler clean` and `ipcrawler clean pin|unpin`
+func runCleanCommand(args []string) error {
+	fs := pflag.NewFlagSet("clean", pflag.ContinueOnError)
+	var (
+		outputDir     = fs.StringP("output", "o", "", "Workspace base directory (default: as for scans)")
+		dryRun        = fs.BoolP("dry-run", "n", false, "List what would be removed without removing it")
+		maxWorkspaces = fs.Int("max-workspaces", -1, "Keep at most this many workspaces (default: output.retention)")
+		maxAgeDays    = fs.Int("max-age-days", -1, "Remove workspaces older than this (default: output.retention)")
+		maxSizeGB     = fs.Float64("max-total-size-gb", -1, "Remove the oldest until the rest fit (default: output.retention)")
+		archives      = fs.Bool("archives", false, "Apply the limits to workspace archives too")
+	)
+	fs.Usage = printCleanUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+
+	if fs.NArg() > 0 {
+		switch action := fs.Arg(0); action {
+		case "pin", "unpin":
+			if fs.NArg() < 2 {
+				return fmt.Errorf("usage: ipcrawler clean %s <workspace>...", action)
+			}
+			return pinWorkspaces(cfg, fs.Args()[1:], action == "pin")
+		default:
+			printCleanUsage()
+			return fmt.Errorf("unknown clean command %q", action)
+		}
+	}
+
+	settings := cfg.Output.Retention
+	if *maxWorkspaces >= 0 {
+		settings.MaxWorkspaces = *maxWorkspaces
+	}
+	if *maxAgeDays >= 0 {
+		settings.MaxAgeDays = *maxAgeDays
+	}
+	if *maxSizeGB >= 0 {
+		settings.MaxTotalSizeGB = *maxSizeGB
+	}
+	settings.IncludeArchives = settings.IncludeArchives || *archives
+	policy := retentionPolicy(settings)
+	if !policy.Limited() {
+		return fmt.Errorf("no retention limits set (output.retention in configs/output.yaml, or --max-workspaces, --max-age-days, --max-total-size-gb)")
+	}
+
+	baseDir := workspaceBaseDir(cfg, *outputDir)
+	workspaces, err := retention.Scan(baseDir, settings.IncludeArchives)
+	if err != nil {
+		return err
+	}
+	planned := retention.Plan(workspaces, policy, time.Now())
+	var freed int64
+	removed := 0
+	for _, workspace := range planned {
+		if *dryRun {
+			fmt.Printf("Would remove %s (%s, %s)\n", workspace.Path, workspace.Reason, formatStoredSize(workspace.Size))
+			continue
+		}
+		if err := retention.Remove(workspace); err != nil {
+			fmt.Printf("Kept %s: %v\n", workspace.Path, err)
+			continue
+		}
+		fmt.Printf("Removed %s (%s, %s)\n", workspace.Path, workspace.Reason, formatStoredSize(workspace.Size))
+		freed += workspace.Size
+		removed++
+	}
+
+	pinned := 0
+	for _, workspace := range workspaces {
+		if workspace.Pinned {
+			pinned++
+		}
+	}
+	if *dryRun {
+		fmt.Printf("%d of %d workspaces in %s would be removed (%d pinned)\n", len(planned), len(workspaces), baseDir, pinned)
+		return nil
+	}
+	fmt.Printf("%d of %d workspaces in %s removed, %s freed (%d pinned)\n", removed, len(workspaces), baseDir, formatStoredSize(freed), pinned)
+	return nil
+}
+
+// pinWorkspaces marks workspaces so retention never removes them, or clears the mark
+func pinWorkspaces(cfg *config.Config, args []string, pinned bool) error {
+	for _, arg := range args {
+		workspaceDir, err := resolveWorkspace(arg)
+		if err != nil {
+			return err
+		}
+		if err := workspace.New(workspaceDir).Pin(pinned, cfg.Output.Permissions.FilePerm()); err != nil {
+			return err
+		}
+		if pinned {
+			fmt.Printf("Pinned %s\n", workspaceDir)
+		} else {
+			fmt.Printf("Unpinned %s\n", workspaceDir)
+		}
+	}
+	return nil
+}
+
+func printCleanUsage() {
+	fmt.Println("Usage: ipcrawler clean [options]")
+	fmt.Println("       ipcrawler clean pin | unpin <workspace>...")
+	fmt.Println()
+	fmt.Println("Removes the oldest workspaces under the workspace base beyond the limits in")
+	fmt.Println("output.retention (configs/output.yaml) or given as options; with")
+	fmt.Println("enforce_at_startup the limits are also applied whenever a scan starts. Pinned")
+	fmt.Println("workspaces and runs still in progress are never counted or removed.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o, --output DIR              Workspace base directory (default: as for scans)")
+	fmt.Println("  -n, --dry-run                 List what would be removed")
+	fmt.Println("      --max-workspaces N        Keep at most N workspaces")
+	fmt.Println("      --max-age-days N          Remove workspaces older than N days")
+	fmt.Println("      --max-total-size-gb N     Remove the oldest until the rest fit in N GB")
+	fmt.Println("      --archives                Apply the limits to workspace archives too")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler clean --dry-run --max-age-days 30")
+	fmt.Println("  ipcrawler clean pin ipcrawler_results/10_10_10_5_1735732800_1a2b3c4d")
+}
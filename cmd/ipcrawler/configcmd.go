@@ -0,0 +1,425 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/schema"
+	"github.com/neur0map/ipcrawler/internal/workflow/loader"
+)
+
+// configReport is the result of `ipcrawler config validate`
+type configReport struct {
+	Files    []string       `json:"files"` // Every file checked
+	Issues   []schema.Issue `json:"issues"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+}
+
+// workflowDocument is a workflow file read for validation
+type workflowDocument struct {
+	path string
+	doc  *schema.Document
+	file loader.File
+}
+
+// runConfigCommand implements `ipcrawler config validate`
+func runConfigCommand(args []string) error {
+	fs := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the results as JSON")
+	strict := fs.Bool("strict", false, "Fail on warnings too")
+	fs.Usage = printConfigUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printConfigUsage()
+		return fmt.Errorf("a config command is required")
+	}
+	if action := fs.Arg(0); action != "validate" || fs.NArg() > 1 {
+		printConfigUsage()
+		return fmt.Errorf("unknown config command %q", strings.Join(fs.Args(), " "))
+	}
+
+	report := validateConfiguration()
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Println(issue)
+		}
+		if len(report.Issues) > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%d files checked: %d errors, %d warnings\n", len(report.Files), report.Errors, report.Warnings)
+	}
+	if report.Errors > 0 || (*strict && report.Warnings > 0) {
+		return fmt.Errorf("%d errors, %d warnings", report.Errors, report.Warnings)
+	}
+	return nil
+}
+
+// validateConfiguration checks the configs/ files, every tool config and every workflow
+func validateConfiguration() configReport {
+	var report configReport
+	configPath, issues := config.ValidateFiles()
+	report.Issues = append(report.Issues, issues...)
+	if entries, err := os.ReadDir(configPath); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".yaml" {
+				report.Files = append(report.Files, filepath.Join(configPath, entry.Name()))
+			}
+		}
+	}
+
+	// Workflows are read first: the variables their steps map are usable in every tool's args
+	workflows, issues := readWorkflowFiles("workflows")
+	report.Issues = append(report.Issues, issues...)
+	defined := make(map[string]bool)
+	for _, workflow := range workflows {
+		for _, step := range workflow.file.Steps {
+			for name, value := range step.Variables {
+				if executor.IsVariableTemplate(value) {
+					defined[name] = true
+				} else {
+					defined[value] = true
+				}
+			}
+			for _, block := range []*loader.StepVariables{step.Inputs, step.Outputs} {
+				if block != nil {
+					for _, mapping := range block.Variables {
+						defined[mapping.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	tools, files, issues := validateToolFiles("tools", defined)
+	report.Files = append(report.Files, files...)
+	report.Issues = append(report.Issues, issues...)
+	combiners := executor.NewWorkflowExecutor(nil)
+	for _, workflow := range workflows {
+		report.Files = append(report.Files, workflow.path)
+		report.Issues = append(report.Issues, validateWorkflow(workflow, tools, defined, combiners)...)
+		report.Issues = append(report.Issues, checkAfter(workflow, workflows)...)
+	}
+	report.Issues = append(report.Issues, checkProfiles(configPath, workflows)...)
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	for _, issue := range report.Issues {
+		if issue.Severity == schema.Error {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+// readWorkflowFiles parses the workflow files under dir, as discoverAllWorkflows finds them
+func readWorkflowFiles(dir string) ([]workflowDocument, []schema.Issue) {
+	var workflows []workflowDocument
+	var issues []schema.Issue
+	if _, err := os.Stat(dir); err != nil {
+		return nil, nil // The embedded workflows are used; they are checked when IPCrawler is built
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() == "descriptions.yaml" || !strings.HasSuffix(d.Name(), ".yaml") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			issues = append(issues, schema.Issue{File: path, Severity: schema.Error, Message: err.Error()})
+			return nil
+		}
+		doc, syntaxIssue := schema.Parse(path, data)
+		if syntaxIssue != nil {
+			issues = append(issues, *syntaxIssue)
+			return nil
+		}
+		workflow := workflowDocument{path: path, doc: doc}
+		yaml.Unmarshal(data, &workflow.file) // Unknown keys and type errors are reported by the schema check
+		workflows = append(workflows, workflow)
+		return nil
+	})
+	if err != nil {
+		issues = append(issues, schema.Issue{File: dir, Severity: schema.Error, Message: err.Error()})
+	}
+	return workflows, issues
+}
+
+// validateToolFiles checks tools/<tool>/config.yaml of every tool and returns the configs that load
+func validateToolFiles(dir string, defined map[string]bool) (map[string]*executor.ToolConfig, []string, []schema.Issue) {
+	tools := make(map[string]*executor.ToolConfig)
+	var files []string
+	var issues []schema.Issue
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return tools, nil, []schema.Issue{{File: dir, Severity: schema.Error, Message: err.Error()}}
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if _, err := os.Stat(filepath.Join(dir, entry.Name(), "config.yaml")); err == nil {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+
+	loader := executor.NewToolConfigLoader(dir)
+	for _, name := range names {
+		path := filepath.Join(dir, name, "config.yaml")
+		files = append(files, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			issues = append(issues, schema.Issue{File: path, Severity: schema.Error, Message: err.Error()})
+			continue
+		}
+		doc, syntaxIssue := schema.Parse(path, data)
+		if syntaxIssue != nil {
+			issues = append(issues, *syntaxIssue)
+			continue
+		}
+		issues = append(issues, doc.Check(doc.Root, "", &executor.ToolConfig{}, "yaml")...)
+
+		toolConfig, err := loader.LoadToolConfig(name)
+		if err != nil {
+			message := strings.TrimPrefix(err.Error(), "tool config "+path+": ")
+			issues = append(issues, doc.Issue("", schema.Error, "fails to load: %s", message))
+			continue
+		}
+		tools[name] = toolConfig
+		if len(toolConfig.Args) == 0 {
+			issues = append(issues, doc.Issue("args", schema.Error, "args is required: no modes are defined"))
+		}
+		for mode, args := range toolConfig.Args {
+			for i, arg := range args {
+				issues = append(issues, checkVariables(doc, fmt.Sprintf("args.%s[%d]", mode, i), arg, names, defined)...)
+			}
+		}
+	}
+	return tools, files, issues
+}
+
+// validateWorkflow checks a workflow's keys, required fields, tools and modes, depends_on
+// references and template variables, then loads it as a scan would
+func validateWorkflow(workflow workflowDocument, tools map[string]*executor.ToolConfig, defined map[string]bool, combiners *executor.WorkflowExecutor) []schema.Issue {
+	doc, file := workflow.doc, workflow.file
+	issues := doc.CheckStrict(doc.Root, "", &loader.File{}, "yaml")
+	if file.Name == "" {
+		issues = append(issues, doc.Issue("name", schema.Error, "name is required"))
+	}
+	if len(file.Steps) == 0 {
+		issues = append(issues, doc.Issue("steps", schema.Error, "steps is required: the workflow has no steps"))
+	}
+
+	toolNames := make([]string, 0, len(tools))
+	for name := range tools {
+		toolNames = append(toolNames, name)
+	}
+	stepNames := make(map[string]bool)
+	var names []string
+	for _, step := range file.Steps {
+		names = append(names, step.Name)
+	}
+
+	for i, step := range file.Steps {
+		path := fmt.Sprintf("steps[%d]", i)
+		if step.Name == "" {
+			issues = append(issues, doc.Issue(path, schema.Error, "%s: name is required", path))
+		} else if stepNames[step.Name] {
+			issues = append(issues, doc.Issue(path+".name", schema.Error, "duplicate step name %q", step.Name))
+		}
+		stepNames[step.Name] = true
+
+		for _, dependency := range step.DependsOn {
+			switch {
+			case dependency == step.Name:
+				issues = append(issues, doc.Issue(path+".depends_on", schema.Error, "step %q depends on itself", step.Name))
+			case !slices.Contains(names, dependency):
+				issues = append(issues, doc.Issue(path+".depends_on", schema.Error, "depends_on %q names no step of this workflow%s", dependency, closestHint(dependency, names)))
+			}
+		}
+
+		toolConfig := tools[step.Tool]
+		switch {
+		case step.Tool == "":
+			issues = append(issues, doc.Issue(path, schema.Error, "%s: tool is required", path))
+		case toolConfig == nil:
+			issues = append(issues, doc.Issue(path+".tool", schema.Error, "unknown tool %q (no loadable tools/%s/config.yaml)%s", step.Tool, step.Tool, closestHint(step.Tool, toolNames)))
+		}
+		if len(step.Modes) == 0 {
+			issues = append(issues, doc.Issue(path, schema.Error, "%s: modes is required", path))
+		}
+		if toolConfig != nil {
+			modes := make([]string, 0, len(toolConfig.Args))
+			for mode := range toolConfig.Args {
+				modes = append(modes, mode)
+			}
+			for key, list := range map[string][]string{"modes": step.Modes, "fallback_modes": step.FallbackModes} {
+				for j, mode := range list {
+					if _, ok := toolConfig.Args[mode]; !ok && !strings.Contains(mode, "{{") {
+						issues = append(issues, doc.Issue(fmt.Sprintf("%s.%s[%d]", path, key, j), schema.Error, "tool %s has no mode %q%s", step.Tool, mode, closestHint(mode, modes)))
+					}
+				}
+			}
+		}
+		if step.Tool != "" && len(step.Combiner) > 0 {
+			if _, err := combiners.ResolveCombinerOptions(step.Tool, step.Combiner); err != nil {
+				issues = append(issues, doc.Issue(path+".combiner", schema.Error, "invalid combiner options: %v", err))
+			}
+		}
+
+		for name, value := range step.Variables {
+			if !executor.IsVariableTemplate(value) {
+				issues = append(issues, checkVariableName(doc, path+".variables."+name, name, toolNames, defined)...)
+				continue
+			}
+			for _, used := range executor.TemplateVariables(value) {
+				if !executor.IsConfigVariable(used) {
+					issues = append(issues, checkVariableName(doc, path+".variables."+name, used, toolNames, defined)...)
+				}
+			}
+		}
+		for block, variables := range map[string]*loader.StepVariables{"inputs": step.Inputs, "outputs": step.Outputs} {
+			if variables != nil {
+				for j, mapping := range variables.Variables {
+					issues = append(issues, checkVariableName(doc, fmt.Sprintf("%s.%s.variables[%d].source", path, block, j), mapping.Source, toolNames, defined)...)
+				}
+			}
+		}
+		if step.RunIf != "" {
+			if condition, err := executor.ParseCondition(step.RunIf); err == nil {
+				for _, name := range condition.Variables() {
+					issues = append(issues, checkVariableName(doc, path+".run_if", name, toolNames, defined)...)
+				}
+			}
+		}
+	}
+
+	// The loader is the final word on everything else (retry, matrix, run_if and trigger syntax);
+	// what it fails to decode is reported above with its line
+	if _, err := loader.LoadWorkflow(workflow.path); err != nil && !strings.HasPrefix(err.Error(), "failed to parse workflow YAML ") {
+		message := err.Error()
+		for _, prefix := range []string{"invalid workflow " + workflow.path + ": ", "invalid matrix in workflow " + workflow.path + ": "} {
+			message = strings.TrimPrefix(message, prefix)
+		}
+		issues = append(issues, doc.Issue("", schema.Error, "fails to load: %s", message))
+	}
+	return issues
+}
+
+// checkAfter reports the after entries of a workflow that name none of the workflows; the
+// loader reports a workflow running after itself
+func checkAfter(workflow workflowDocument, workflows []workflowDocument) []schema.Issue {
+	var issues []schema.Issue
+	var candidates []string
+	for _, other := range workflows {
+		if other.path != workflow.path {
+			candidates = append(candidates, strings.TrimSuffix(filepath.Base(other.path), ".yaml"), other.file.Name)
+		}
+	}
+	for i, name := range workflow.file.After {
+		found := false
+		for _, other := range workflows {
+			found = found || strings.TrimSuffix(filepath.Base(other.path), ".yaml") == name || strings.EqualFold(other.file.Name, name)
+		}
+		if !found {
+			issues = append(issues, workflow.doc.Issue(fmt.Sprintf("after[%d]", i), schema.Error, "after %q names no workflow: it would never start%s", name, closestHint(name, candidates)))
+		}
+	}
+	return issues
+}
+
+// checkProfiles reports the profiles whose tag filter does not parse or selects no workflow
+func checkProfiles(configPath string, workflows []workflowDocument) []schema.Issue {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil // Reported with the configs/ files
+	}
+	var issues []schema.Issue
+	file := filepath.Join(configPath, "profiles.yaml")
+	for _, name := range profileNames(cfg) {
+		filter, err := executor.ParseTagFilter(cfg.Profiles[name].Tags)
+		if err != nil {
+			issues = append(issues, schema.Issue{File: file, Severity: schema.Error, Message: fmt.Sprintf("profile %s: %v", name, err)})
+			continue
+		}
+		selects := false
+		for _, workflow := range workflows {
+			selects = selects || filter.Matches(workflow.file.Tags)
+		}
+		if !selects {
+			issues = append(issues, schema.Issue{File: file, Severity: schema.Warning, Message: fmt.Sprintf("profile %s (%s) selects no workflow", name, filter)})
+		}
+	}
+	return issues
+}
+
+// checkVariables reports the template variables of value no tool, combiner or workflow sets
+func checkVariables(doc *schema.Document, path, value string, tools []string, defined map[string]bool) []schema.Issue {
+	var issues []schema.Issue
+	for _, name := range executor.TemplateVariables(value) {
+		issues = append(issues, checkVariableName(doc, path, name, tools, defined)...)
+	}
+	return issues
+}
+
+func checkVariableName(doc *schema.Document, path, name string, tools []string, defined map[string]bool) []schema.Issue {
+	if defined[name] || executor.IsKnownVariable(name, tools) {
+		return nil
+	}
+	candidates := executor.BuiltinVariables()
+	for variable := range defined {
+		candidates = append(candidates, variable)
+	}
+	return []schema.Issue{doc.Issue(path, schema.Warning, "unknown template variable {{%s}}: no built-in, combiner, parser or step variables sets it%s", name, closestHint(name, candidates))}
+}
+
+// closestHint returns ` (did you mean "x"?)` when a candidate is close to name
+func closestHint(name string, candidates []string) string {
+	if match := schema.Closest(name, candidates); match != "" {
+		return fmt.Sprintf(" (did you mean %q?)", match)
+	}
+	return ""
+}
+
+func printConfigUsage() {
+	fmt.Println("Usage: ipcrawler config validate [--json] [--strict]")
+	fmt.Println()
+	fmt.Println("Checks the configuration before a scan trips over it: the configs/ files,")
+	fmt.Println("every tools/<tool>/config.yaml and every workflow under workflows/. Reports")
+	fmt.Println("YAML syntax errors, unknown keys (typos the loaders silently ignore), values")
+	fmt.Println("of the wrong type, missing required fields, unknown tools and modes,")
+	fmt.Println("depends_on naming no step, and template variables nothing sets, each with")
+	fmt.Println("its file and line. Exits non-zero on errors.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --json              Print machine-readable JSON")
+	fmt.Println("      --strict            Exit non-zero on warnings too")
+}
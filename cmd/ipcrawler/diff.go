@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/report"
+	"github.com/neur0map/ipcrawler/internal/scope"
+)
+
+// runDiffCommand compares the parsed results of two workspaces
+func runDiffCommand(args []string) error {
+	fs := pflag.NewFlagSet("diff", pflag.ContinueOnError)
+	var (
+		kinds  = fs.StringSlice("kind", nil, "Compare only these result kinds (default: all)")
+		asJSON = fs.Bool("json", false, "Print the differences as JSON")
+	)
+	fs.Usage = printDiffUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		printDiffUsage()
+		return fmt.Errorf("exactly two workspaces are required")
+	}
+	for _, kind := range *kinds {
+		if !slices.Contains(report.DiffKinds, kind) {
+			return fmt.Errorf("unknown kind '%s' (available: %s)", kind, strings.Join(report.DiffKinds, ", "))
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	var workspaces [2]string
+	for i, arg := range fs.Args() {
+		if workspaces[i], err = resolveWorkspace(arg); err != nil {
+			return err
+		}
+	}
+	if workspaces[0] == workspaces[1] {
+		return fmt.Errorf("both arguments are the same workspace")
+	}
+
+	diff, err := diffWorkspaces(cfg, workspaces[0], workspaces[1])
+	if err != nil {
+		return err
+	}
+	if diff.Baseline.Target != diff.Current.Target {
+		fmt.Fprintf(os.Stderr, "Warning: the runs scanned different targets (%s and %s)\n", diff.Baseline.Target, diff.Current.Target)
+	}
+	if len(*kinds) > 0 {
+		diff.Filter(*kinds)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+	printDiff(diff)
+	return nil
+}
+
+func printDiffUsage() {
+	fmt.Println("Usage: ipcrawler diff [options] <baseline workspace> <current workspace>")
+	fmt.Println()
+	fmt.Println("Compares the parsed results of two runs, usually of the same target, and lists")
+	fmt.Println("what was added (+), removed (-) or changed (~) in the current run:")
+	fmt.Println()
+	fmt.Println("  port        Open ports; changed when the service, product, version or TLS differs")
+	fmt.Println("  url         Probed web URLs; changed when the status code or title differs")
+	fmt.Println("  dns         DNS records by type and name; changed when the answers differ")
+	fmt.Println("  subdomain   Subdomains of the target; changed when their addresses differ")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --kind LIST   Compare only these kinds (port, url, dns, subdomain)")
+	fmt.Println("      --json        Print machine-readable JSON")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler diff ipcrawler_results/example_com_1760000000_a1b2c3d4 ipcrawler_results/example_com_1760600000_e5f6a7b8")
+	fmt.Println("  ipcrawler diff --kind port,subdomain --json old/ new/ | jq '.entries[]'")
+}
+
+// diffWorkspaces compares the parsed results of a current workspace with a baseline workspace
+func diffWorkspaces(cfg *config.Config, baselineDir, currentDir string) (*report.Diff, error) {
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labels configuration: %v", err)
+	}
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+
+	baseline, err := loadDiffRun(baselineDir, catalog, labeler)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", baselineDir, err)
+	}
+	current, err := loadDiffRun(currentDir, catalog, labeler)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", currentDir, err)
+	}
+	return report.BuildDiff(baseline, current), nil
+}
+
+// loadDiffRun loads a workspace's findings and the DNS and subdomain records in its scan outputs
+func loadDiffRun(workspaceDir string, catalog *findings.Catalog, labeler *scope.Labeler) (report.DiffRun, error) {
+	summary, err := report.LoadTarget(workspaceDir, catalog, labeler.LabelsFor)
+	if err != nil {
+		return report.DiffRun{}, err
+	}
+	run := report.DiffRun{Summary: summary}
+
+	entries, err := os.ReadDir(filepath.Join(workspaceDir, "scans"))
+	if err != nil {
+		return report.DiffRun{}, fmt.Errorf("failed to read scans directory: %w", err)
+	}
+	dnsReaders := executor.DNSRecordReaders()
+	subdomainReaders := executor.SubdomainReaders()
+	for _, entry := range entries {
+		tool, _, found := strings.Cut(strings.ToLower(entry.Name()), "_")
+		if entry.IsDir() || !found {
+			continue
+		}
+		path := filepath.Join(workspaceDir, "scans", entry.Name())
+		if read, exists := dnsReaders[tool]; exists {
+			records, err := read(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipped %s: %v\n", entry.Name(), err)
+				continue
+			}
+			run.DNS = append(run.DNS, records...)
+		}
+		if read, exists := subdomainReaders[tool]; exists {
+			records, err := read(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipped %s: %v\n", entry.Name(), err)
+				continue
+			}
+			run.Subdomains = append(run.Subdomains, records...)
+		}
+	}
+	return run, nil
+}
+
+// printDiff prints the differences as a table followed by the totals
+func printDiff(diff *report.Diff) {
+	fmt.Printf("Baseline: %s\n", describeDiffRun(diff.Baseline))
+	fmt.Printf("Current:  %s\n", describeDiffRun(diff.Current))
+	fmt.Println()
+
+	if len(diff.Entries) == 0 {
+		fmt.Println("No differences")
+		return
+	}
+
+	symbols := map[string]string{report.DiffAdded: "+", report.DiffRemoved: "-", report.DiffChanged: "~"}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, entry := range diff.Entries {
+		detail := entry.After
+		switch entry.Change {
+		case report.DiffRemoved:
+			detail = entry.Before
+		case report.DiffChanged:
+			detail = fmt.Sprintf("%s -> %s", dashIfEmpty(entry.Before), dashIfEmpty(entry.After))
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\n", symbols[entry.Change], entry.Kind, dashIfEmpty(entry.Host), entry.Key, detail)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d added, %d removed, %d changed\n", diff.Added, diff.Removed, diff.Changed)
+}
+
+// describeDiffRun names a compared run: workspace, target and start time
+func describeDiffRun(run report.DiffRunInfo) string {
+	description := run.Workspace
+	if run.Target != "" {
+		description += " (" + run.Target
+		if !run.StartedAt.IsZero() {
+			description += ", " + run.StartedAt.Local().Format("2006-01-02 15:04")
+		}
+		description += ")"
+	}
+	return description
+}
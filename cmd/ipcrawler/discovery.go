@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// runHostDiscovery sweeps a CIDR target for live hosts, records them in hosts.json in a
+// workspace for the range, and scans each live host in its own workspace
+func runHostDiscovery(cidr string, cfg *config.Config, opts targetListOptions) (runErr error) {
+	discovery := cfg.Tools.HostDiscovery.WithDefaults()
+	if err := cfg.Output.Permissions.Validate(); err != nil {
+		return err
+	}
+	dirMode := cfg.Output.Permissions.DirPerm()
+	fileMode := cfg.Output.Permissions.FilePerm()
+
+	baseDir := opts.OutputDir
+	if baseDir == "" {
+		baseDir = cfg.Output.WorkspaceBase
+	}
+	scanID := session.NewScanID()
+	ws, err := workspace.Create(baseDir, opts.SessionName, cidr, scanID, time.Now(), dirMode)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %v", err)
+	}
+	workspaceDir := ws.Dir
+
+	started := time.Now()
+	manifest := &session.RunManifest{
+		ScanID:     scanID,
+		Version:    ipcrawlerVersion,
+		Target:     cidr,
+		Workspace:  workspaceDir,
+		OutputMode: opts.OutputMode.String(),
+		Status:     session.RunStatusRunning,
+		StartedAt:  started.Round(0),
+		Exclusions: opts.Exclusions.Entries(),
+		Labels:     opts.Labels,
+		Workflows:  []string{"host-discovery"},
+
+		SessionName: opts.SessionName,
+		Scope:       opts.Engagement.Path(),
+	}
+	if err := ws.WriteSessionInfo(manifest, fileMode); err != nil {
+		return err
+	}
+	var shutdown *shutdownWatch
+	defer func() {
+		finishedAt := time.Now().Round(0)
+		manifest.FinishedAt = &finishedAt
+		manifest.DurationSeconds = time.Since(started).Seconds()
+		manifest.Status = session.RunStatusCompleted
+		if runErr != nil {
+			manifest.Status = session.RunStatusFailed
+			manifest.Error = runErr.Error()
+		}
+		if sig, _ := shutdown.received(); sig != "" {
+			manifest.Status = session.RunStatusInterrupted
+			manifest.Error = fmt.Sprintf("interrupted by %s", sig)
+		}
+		ws.WriteSessionInfo(manifest, fileMode)
+	}()
+
+	engine := executor.NewToolExecutionEngine(cfg, "", opts.OutputMode)
+	engine.SetScanID(scanID)
+	engine.SetSessionName(opts.SessionName)
+	engine.SetExclusions(opts.Exclusions)
+	engine.SetEngagement(opts.Engagement)
+	engine.SetWorkspaceBase(workspaceDir)
+	engine.SetOutputMode(opts.OutputMode)
+	if err := engine.SetWorkspaceLoggers(workspaceDir); err != nil {
+		return fmt.Errorf("failed to setup tool execution engine logging: %v", err)
+	}
+
+	plain := opts.OutputMode == output.OutputModePlain
+	if plain {
+		printRecord(nil, "event", "discovery_started", "target", cidr, "tool", discovery.Tool, "mode", discovery.Mode)
+	} else {
+		fmt.Fprintf(os.Stderr, "Discovering live hosts in %s with %s %s\n", cidr, discovery.Tool, discovery.Mode)
+	}
+	// The sweep stops gracefully on SIGINT/SIGTERM; each host's scan then watches for itself
+	ctx, watch, stopSignals := watchShutdownSignals(context.Background(), engine, nil)
+	inventory, err := engine.DiscoverHosts(ctx, discovery, cidr)
+	stopSignals()
+	if sig, _ := watch.received(); sig != "" {
+		shutdown = watch
+		return fmt.Errorf("host discovery interrupted by %s", sig)
+	}
+	if err != nil {
+		return err
+	}
+	if err := executor.WriteHostInventory(workspaceDir, inventory, fileMode); err != nil {
+		return err
+	}
+	if plain {
+		printRecord(nil, "event", "discovery_finished", "target", cidr, "live", strconv.Itoa(len(inventory.Hosts)),
+			"excluded", strconv.Itoa(len(inventory.Excluded)), "inventory", filepath.Join(workspaceDir, executor.HostInventoryFileName))
+	} else {
+		fmt.Fprintf(os.Stderr, "Found %d live host(s), %d excluded; inventory in %s\n",
+			len(inventory.Hosts), len(inventory.Excluded), filepath.Join(workspaceDir, executor.HostInventoryFileName))
+	}
+
+	if len(inventory.Hosts) == 0 {
+		return nil
+	}
+	if len(inventory.Hosts) > discovery.MaxHosts {
+		return fmt.Errorf("%d live hosts exceeds host_discovery.max_hosts (%d) in tools.yaml; raise the limit or scan a smaller range",
+			len(inventory.Hosts), discovery.MaxHosts)
+	}
+
+	// Keep hosts.json current as each host's scan starts and ends
+	var mu sync.Mutex
+	record := func(address, workspace, status string) {
+		mu.Lock()
+		defer mu.Unlock()
+		inventory.SetHostResult(address, workspace, status)
+		executor.WriteHostInventory(workspaceDir, inventory, fileMode)
+	}
+	opts.OnWorkspace = func(target, hostWorkspace string) {
+		record(target, hostWorkspace, session.RunStatusRunning)
+	}
+	opts.OnFinished = func(target string, err error) {
+		status := session.RunStatusCompleted
+		if err != nil {
+			status = session.RunStatusFailed
+		}
+		record(target, "", status)
+	}
+	return runTargetList(inventory.Addresses(), opts)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// toolCheck is the result of checking one tool the workflows use
+type toolCheck struct {
+	Tool    string   `json:"tool"`
+	Path    string   `json:"path,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Error   string   `json:"error,omitempty"` // Why the tool cannot run; empty when it is ready
+	UsedBy  []string `json:"used_by"`         // Workflows (or host discovery) that run it
+	Install []string `json:"install,omitempty"`
+}
+
+// runDoctorCommand checks that every tool the workflows reference is installed
+func runDoctorCommand(args []string) error {
+	fs := pflag.NewFlagSet("doctor", pflag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the results as JSON")
+	fs.Usage = printDoctorUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("failed to discover workflows: %v", err)
+	}
+	engine := executor.NewToolExecutionEngine(cfg, "", output.OutputModeNormal)
+	checks := checkTools(engine, toolUsage(cfg, workflows))
+
+	var missing int
+	for _, check := range checks {
+		if check.Error != "" {
+			missing++
+		}
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(checks); err != nil {
+			return err
+		}
+	} else {
+		printToolChecks(checks)
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d of %d tools are not ready", missing, len(checks))
+	}
+	return nil
+}
+
+func printDoctorUsage() {
+	fmt.Println("Usage: ipcrawler doctor [--json]")
+	fmt.Println()
+	fmt.Println("Checks every tool the workflows use before a scan needs it: whether its")
+	fmt.Println("config in tools/ loads, where its executable is found (tools_path, then PATH)")
+	fmt.Println("and which version it reports. Missing tools, and tools outside the versions")
+	fmt.Println("their config supports (min_version/max_version), are listed with the install")
+	fmt.Println("commands for this OS from their tools/<tool>/config.yaml. Exits non-zero if")
+	fmt.Println("any tool is not ready.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --json              Print machine-readable JSON")
+}
+
+// toolUsage maps every tool the workflows (and host discovery) run to the names of what runs it
+func toolUsage(cfg *config.Config, workflows map[string]*executor.Workflow) map[string][]string {
+	usage := make(map[string][]string)
+	for name, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			if step.Tool != "" {
+				usage[step.Tool] = appendUnique(usage[step.Tool], name)
+			}
+		}
+	}
+	discovery := cfg.Tools.HostDiscovery.WithDefaults()
+	usage[discovery.Tool] = appendUnique(usage[discovery.Tool], "host discovery")
+	for _, users := range usage {
+		sort.Strings(users)
+	}
+	return usage
+}
+
+// checkTools checks each tool's config, executable and version, sorted by tool name
+func checkTools(engine *executor.ToolExecutionEngine, usage map[string][]string) []toolCheck {
+	checks := make([]toolCheck, 0, len(usage))
+	for tool, users := range usage {
+		check := toolCheck{Tool: tool, UsedBy: users}
+		toolConfig, err := engine.GetToolConfig(tool)
+		if err != nil {
+			check.Error = err.Error()
+			checks = append(checks, check)
+			continue
+		}
+		if check.Path, err = engine.ToolPath(tool); err != nil {
+			check.Error = "not installed: " + err.Error()
+			check.Install = toolConfig.InstallHintsFor(runtime.GOOS)
+			checks = append(checks, check)
+			continue
+		}
+		if len(toolConfig.VersionArgs) > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			check.Version, _ = engine.ToolVersion(ctx, tool)
+			_, err = engine.CheckToolVersion(ctx, tool)
+			cancel()
+			if _, unsupported := err.(*executor.UnsupportedVersionError); unsupported {
+				check.Error = err.Error()
+				check.Install = toolConfig.InstallHintsFor(runtime.GOOS)
+			}
+		}
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Tool < checks[j].Tool })
+	return checks
+}
+
+// printToolChecks prints one line per tool, with install hints under the ones not ready
+func printToolChecks(checks []toolCheck) {
+	for _, check := range checks {
+		if check.Error == "" {
+			fmt.Printf("  %s %-10s %s", output.FormatStatus(output.StatusOK, "ok     "), check.Tool, check.Path)
+			if check.Version != "" {
+				fmt.Printf("  (%s)", check.Version)
+			}
+			fmt.Println()
+			continue
+		}
+		status := output.FormatStatus(output.StatusFailed, "MISSING")
+		if check.Path != "" {
+			status = output.FormatStatus(output.StatusWarning, "VERSION") // Installed, but outside the supported versions
+		}
+		fmt.Printf("  %s %-10s %s\n", status, check.Tool, check.Error)
+		fmt.Printf("             used by: %s\n", strings.Join(check.UsedBy, ", "))
+		if len(check.Install) == 0 {
+			fmt.Printf("             no install hint for %s in tools/%s/config.yaml\n", runtime.GOOS, check.Tool)
+		}
+		for _, hint := range check.Install {
+			fmt.Printf("             install: %s\n", hint)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// runDryRun prints, for each target, the commands a scan would run and the files they would
+// write, in the order they would start, without running anything or creating a workspace
+func runDryRun(targets []string, opts targetListOptions) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("failed to discover workflows: %v", err)
+	}
+	if len(opts.Workflows) > 0 {
+		if workflows, err = selectWorkflows(workflows, opts.Workflows); err != nil {
+			return err
+		}
+	}
+	if opts.Profile != "" {
+		if workflows, err = profileWorkflows(cfg, workflows, opts.Profile); err != nil {
+			return err
+		}
+	}
+	if err := applyParameterOverrides(workflows, opts.Parameters); err != nil {
+		return err
+	}
+	selected := make([]*executor.Workflow, 0, len(workflows))
+	for _, workflow := range workflows {
+		// Workflows with triggers asked for by name are queued like the others, as in a scan
+		if len(opts.Workflows) > 0 {
+			copied := *workflow
+			copied.Triggers = nil
+			workflow = &copied
+		}
+		selected = append(selected, workflow)
+	}
+
+	scheduling := cfg.Tools.TargetScheduling
+	if opts.ConcurrentTargets > 0 {
+		scheduling.MaxConcurrentTargets = opts.ConcurrentTargets
+	}
+	if opts.RateLimit > 0 {
+		scheduling.GlobalRateLimit = opts.RateLimit
+	}
+	rateLimit := executor.NewTargetScheduler(scheduling).RateLimitFor(len(targets))
+	baseDir := opts.OutputDir
+	if baseDir == "" {
+		baseDir = cfg.Output.WorkspaceBase
+	}
+	secretStore, err := loadSecrets(log.New(os.Stderr))
+	if err != nil {
+		return fmt.Errorf("failed to load secrets: %v", err)
+	}
+	redactor, err := output.NewRedactor(secretStore, cfg.Output.Redaction)
+	if err != nil {
+		return fmt.Errorf("invalid redaction configuration: %v", err)
+	}
+
+	for i, target := range targets {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		// An engine of its own per target: the dry run fills its variables with placeholders
+		scanID := session.NewScanID()
+		engine := executor.NewToolExecutionEngine(cfg, "", opts.OutputMode)
+		engine.SetScanID(scanID)
+		engine.SetRateLimit(rateLimit)
+		pacing := cfg.Tools.Pacing
+		if opts.Pacing != "" {
+			pacing = opts.Pacing
+		}
+		if err := engine.SetPacing(pacing); err != nil {
+			return fmt.Errorf("invalid pacing: %v", err)
+		}
+		engine.SetSecrets(secretStore)
+		engine.SetRedactor(redactor)
+		engine.SetExclusions(opts.Exclusions)
+		engine.SetEngagement(opts.Engagement)
+		engine.ResolveTarget(target) // A hostname that does not resolve shows <resolved_ip>
+
+		workspaceDir := filepath.Join(baseDir, workspace.Name(opts.SessionName, target, time.Now(), scanID))
+		orchestrator := executor.NewWorkflowOrchestrator(executor.NewWorkflowExecutor(engine), cfg)
+		printDryRun(orchestrator.DryRun(selected, target, workspaceDir))
+	}
+	return nil
+}
+
+// printDryRun prints the plan of one target
+func printDryRun(plan *executor.DryRunPlan) {
+	fmt.Printf("Dry run for %s: nothing is executed\n", plan.Target)
+	fmt.Printf("Workspace: %s\n", plan.Workspace)
+	for i, workflow := range plan.Workflows {
+		fmt.Printf("\n%d. %s (%s, priority %d)\n", i+1, workflow.Name, workflow.ID, workflow.Priority)
+		if len(workflow.After) > 0 {
+			fmt.Printf("   after: %s\n", strings.Join(workflow.After, ", "))
+		}
+		if workflow.Error != "" {
+			fmt.Printf("   would not start: %s\n", workflow.Error)
+		}
+		for j, step := range workflow.Steps {
+			fmt.Printf("   %d.%d %s [%s]\n", i+1, j+1, step.Name, step.Tool)
+			if len(step.DependsOn) > 0 {
+				fmt.Printf("       depends on: %s\n", strings.Join(step.DependsOn, ", "))
+			}
+			if step.RunIf != "" {
+				fmt.Printf("       run_if: %s\n", step.RunIf)
+			}
+			if step.Concurrent {
+				fmt.Printf("       modes run at the same time\n")
+			}
+			for _, command := range step.Commands {
+				if command.Command != nil {
+					fmt.Printf("       %s: %s\n", command.Mode, shellJoin(command.Command))
+				}
+				for _, path := range command.Outputs {
+					fmt.Printf("         writes: %s\n", path)
+				}
+				if command.Error != "" {
+					fmt.Printf("         error: %s\n", command.Error)
+				}
+			}
+		}
+	}
+
+	if len(plan.Triggered) > 0 {
+		fmt.Printf("\nQueued only when a step discovers a matching service:\n")
+		for _, workflow := range plan.Triggered {
+			fmt.Printf("  %s (%s): when %s\n", workflow.Name, workflow.ID, strings.Join(workflow.Triggers, " or "))
+		}
+	}
+	if len(plan.Placeholders) > 0 {
+		names := make([]string, len(plan.Placeholders))
+		for i, name := range plan.Placeholders {
+			names[i] = "<" + name + ">"
+		}
+		fmt.Printf("\nSet while the scan runs, shown as placeholders: %s\n", strings.Join(names, ", "))
+	}
+}
+
+// shellJoin joins a command line, quoting the arguments a shell would split or expand
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
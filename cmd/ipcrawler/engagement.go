@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/neur0map/ipcrawler/internal/scope"
+)
+
+// loadEngagement reads a --scope file and adds its excluded hosts to exclusions, so tools with
+// native exclusion support are told about them too. No file means no scope (nil)
+func loadEngagement(path string, exclusions *scope.ExclusionList) (*scope.Engagement, error) {
+	if path == "" {
+		return nil, nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scope file path: %v", err)
+	}
+	engagement, err := scope.LoadEngagement(absPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range engagement.Exclusions() {
+		if err := exclusions.Add(entry); err != nil {
+			return nil, err
+		}
+	}
+	return engagement, nil
+}
+
+// refuseTarget returns why a target given to ipcrawler is outside the engagement's scope,
+// recording it in the audit log, or nil when it may be scanned
+func refuseTarget(engagement *scope.Engagement, target, sessionName string) error {
+	return engagement.Refuse(target, nil, scope.AuditEntry{Source: scope.AuditSourceTarget, Session: sessionName})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/features"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// applyFeatureFlags turns experimental features on from experimental.yaml, then the user
+// config, then $IPCRAWLER_EXPERIMENTAL
+func applyFeatureFlags() {
+	var layers []map[string]bool
+	if cfg, err := config.LoadConfig(); err == nil {
+		layers = append(layers, cfg.Experimental)
+	}
+	if userConfig, err := userconfig.LoadUserConfig(); err == nil {
+		layers = append(layers, userConfig.Experimental)
+	}
+	layers = append(layers, features.ParseList(os.Getenv(features.EnvVariable)))
+	if err := features.Configure(layers...); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// printFeatureFlags lists the experimental features and whether each is on
+func printFeatureFlags() {
+	fmt.Println("Experimental features:")
+	for _, flag := range features.Flags {
+		state := "off"
+		if features.Enabled(flag.Name) {
+			state = "on"
+		}
+		fmt.Printf("  %-18s %-4s %s\n", flag.Name, state, flag.Description)
+	}
+}
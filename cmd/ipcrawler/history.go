@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// historyEntry is one previous run for a target
+type historyEntry struct {
+	Number          int       `json:"number"` // 1 is the latest run
+	Workspace       string    `json:"workspace"`
+	ScanID          string    `json:"scan_id,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	StartedAt       time.Time `json:"started_at,omitempty"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	OpenPorts       int       `json:"open_ports"`
+	Warnings        int       `json:"warnings,omitempty"`
+}
+
+// runHistoryCommand lists a target's previous runs and compares one with the latest
+func runHistoryCommand(args []string) error {
+	fs := pflag.NewFlagSet("history", pflag.ContinueOnError)
+	var (
+		outputDir = fs.StringP("output", "o", "", "Directory holding the target's workspaces (default: as for scans)")
+		diff      = fs.Int("diff", 0, "Write a retest report comparing run N (from the list) with the latest run")
+		formats   = fs.StringSlice("format", nil, "Report formats for --diff (default from security.yaml reporting.formats)")
+		asJSON    = fs.Bool("json", false, "Print the runs as JSON")
+	)
+	fs.Usage = printHistoryUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case fs.NArg() == 0:
+		return printRecordedRuns("", *asJSON)
+	case fs.Arg(0) == "show":
+		if fs.NArg() != 2 {
+			printHistoryUsage()
+			return fmt.Errorf("history show takes exactly one run ID")
+		}
+		return showRecordedRun(fs.Arg(1), *asJSON)
+	case fs.NArg() != 1:
+		printHistoryUsage()
+		return fmt.Errorf("at most one target is allowed")
+	}
+	target := fs.Arg(0)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	history, err := loadTargetHistory(workspaceBaseDir(cfg, *outputDir), target)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		// The workspaces may have moved; the run database still lists the runs
+		if *diff == 0 {
+			return printRecordedRuns(target, *asJSON)
+		}
+		return fmt.Errorf("no previous runs for %s", target)
+	}
+
+	if *diff != 0 {
+		if *diff < 2 || *diff > len(history) {
+			return fmt.Errorf("--diff must name an earlier run (2-%d)", len(history))
+		}
+		if len(*formats) == 0 {
+			*formats = cfg.Security.Reporting.Formats
+		}
+		redactor, err := reportRedactor(cfg)
+		if err != nil {
+			return err
+		}
+		return writeRetestReport(cfg, history[*diff-1].Workspace, history[0].Workspace, *formats, redactor)
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(history)
+	}
+	printTargetHistory(os.Stdout, target, history, len(history))
+	return nil
+}
+
+func printHistoryUsage() {
+	fmt.Println("Usage: ipcrawler history [options] [target]")
+	fmt.Println("       ipcrawler history show <run-id>")
+	fmt.Println()
+	fmt.Println("Without a target, lists every run recorded in ~/.ipcrawler/runs.jsonl, latest")
+	fmt.Println("first: target, status, duration, findings and scan ID. Runs stay listed after")
+	fmt.Println("their workspace is moved or deleted. 'history show' prints one run in detail,")
+	fmt.Println("with the per-workflow summaries of its workspace; the ID may be shortened.")
+	fmt.Println()
+	fmt.Println("With a target, lists the target's previous runs found in the output directory,")
+	fmt.Println("latest first, with their status, duration, open ports and warnings (or its")
+	fmt.Println("recorded runs when no workspace is found). --diff N compares run N with the")
+	fmt.Println("latest run and writes a retest report (fixed, unchanged and new ports per host)")
+	fmt.Println("to the latest workspace's reports/ directory.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o, --output DIR        Directory holding the target's workspaces")
+	fmt.Println("      --diff N            Compare run N with the latest run")
+	fmt.Println("      --format LIST       Report formats for --diff (markdown, sarif)")
+	fmt.Println("      --json              Print machine-readable JSON")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler history")
+	fmt.Println("  ipcrawler history show 3f2a9c1e")
+	fmt.Println("  ipcrawler history 10.10.10.87")
+	fmt.Println("  ipcrawler history 10.10.10.87 --diff 2    # What changed since the previous run")
+}
+
+// workspaceBaseDir is where scans of this user put their workspaces: -o, the saved default, or output.yaml
+func workspaceBaseDir(cfg *config.Config, outputDir string) string {
+	userConfig, err := userconfig.LoadUserConfig()
+	if err != nil {
+		userConfig = &userconfig.UserConfig{}
+	}
+	if dir := userConfig.GetEffectiveOutputDirectory(outputDir, ""); dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+		return dir
+	}
+	return cfg.Output.WorkspaceBase
+}
+
+// loadTargetHistory returns the target's runs under baseDir, latest first
+func loadTargetHistory(baseDir, target string) ([]historyEntry, error) {
+	workspaces, err := findTargetWorkspaces(baseDir, "", target)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]historyEntry, 0, len(workspaces))
+	for i := len(workspaces) - 1; i >= 0; i-- {
+		entry := historyEntry{Number: len(history) + 1, Workspace: workspaces[i]}
+		if manifest, err := session.LoadManifest(workspaces[i]); err == nil {
+			entry.ScanID = manifest.ScanID
+			entry.Status = manifest.Status
+			entry.StartedAt = manifest.StartedAt
+			entry.DurationSeconds = manifest.DurationSeconds
+		}
+		if report, err := output.LoadRunReport(workspaces[i]); err == nil {
+			entry.OpenPorts = len(report.Ports)
+			entry.Warnings = len(report.Warnings)
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+// printTargetHistory prints up to limit runs as a table, latest first
+func printTargetHistory(out io.Writer, target string, history []historyEntry, limit int) {
+	warned := false
+	fmt.Fprintf(out, "Previous runs for %s:\n", target)
+	fmt.Fprintf(out, "  %3s  %-19s  %-13s  %9s  %5s  %s\n", "#", "STARTED", "STATUS", "DURATION", "PORTS", "WORKSPACE")
+	for i, entry := range history {
+		if i == limit {
+			fmt.Fprintf(out, "  ... %d older run(s); see ipcrawler history %s\n", len(history)-limit, target)
+			break
+		}
+		started, duration := "-", "-"
+		if !entry.StartedAt.IsZero() {
+			started = entry.StartedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if entry.DurationSeconds > 0 {
+			duration = time.Duration(entry.DurationSeconds * float64(time.Second)).Round(time.Second).String()
+		}
+		ports := strconv.Itoa(entry.OpenPorts)
+		if entry.Warnings > 0 {
+			ports += "*"
+			warned = true
+		}
+		status := output.FormatStatus(runStatusKind(entry.Status), fmt.Sprintf("%-11s", valueOrUnknown(entry.Status)))
+		fmt.Fprintf(out, "  %3d  %-19s  %s  %9s  %5s  %s\n", entry.Number, started, status, duration, ports, filepath.Base(entry.Workspace))
+	}
+	if warned {
+		fmt.Fprintf(out, "  (* ran with warnings)\n")
+	}
+	if len(history) > 1 {
+		fmt.Fprintf(out, "  Compare a run with the latest: ipcrawler history %s --diff N\n", target)
+	}
+}
+
+// runStatusKind maps a run's manifest status to the status kind it is shown as
+func runStatusKind(status string) string {
+	switch status {
+	case session.RunStatusCompleted:
+		return output.StatusOK
+	case session.RunStatusFailed:
+		return output.StatusFailed
+	case session.RunStatusInterrupted:
+		return output.StatusPaused
+	case session.RunStatusRunning:
+		return output.StatusRunning
+	}
+	return output.StatusPending
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/integrations/issues"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// runIssuesCommand opens a workspace's findings as GitHub or GitLab issues
+func runIssuesCommand(args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	settings := cfg.Integrations.Issues
+
+	fs := pflag.NewFlagSet("issues", pflag.ContinueOnError)
+	var (
+		provider = fs.String("provider", settings.Provider, "Issue tracker: github or gitlab")
+		repo     = fs.String("repo", settings.Repository, "Repository (owner/repo) or GitLab project (group/project or ID)")
+		groupBy  = fs.String("group-by", settings.GroupBy, "One issue per 'host' or per 'finding'")
+		dryRun   = fs.Bool("dry-run", false, "Print the issues that would be opened without contacting the tracker")
+	)
+	fs.Usage = printIssuesUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		printIssuesUsage()
+		return fmt.Errorf("exactly one workspace is required")
+	}
+
+	workspaceDir, err := resolveWorkspace(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	list, warnings, err := catalog.LoadWorkspace(workspaceDir)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %s\n", warning)
+	}
+
+	target := filepath.Base(workspaceDir)
+	var workspaceLabels []string
+	if manifest, err := session.LoadManifest(workspaceDir); err == nil {
+		target = manifest.Target
+		workspaceLabels = manifest.Labels
+	}
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid labels configuration: %v", err)
+	}
+	findings.ApplyLabels(list, labeler.LabelsFor, workspaceLabels)
+
+	built, err := issues.Build(target, list, *groupBy, issues.SeverityRules(settings.Severity), settings.Labels)
+	if err != nil {
+		return err
+	}
+
+	ledger, err := issues.LoadLedger(workspaceDir)
+	if err != nil {
+		return err
+	}
+	var pending []issues.Issue
+	for _, issue := range built {
+		if _, opened := ledger.Issues[issue.Key]; !opened {
+			pending = append(pending, issue)
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Printf("No new issues to open (%d already recorded in reports/%s)\n", len(ledger.Issues), issues.LedgerFileName)
+		return nil
+	}
+
+	if *dryRun {
+		for _, issue := range pending {
+			fmt.Printf("%s  %v\n", issue.Title, issue.Labels)
+		}
+		fmt.Printf("\n%d issue(s) would be opened\n", len(pending))
+		return nil
+	}
+
+	tokenEnv := settings.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = issues.DefaultTokenEnv(*provider)
+	}
+	tracker, err := issues.NewTracker(*provider, *repo, settings.BaseURL, os.Getenv(tokenEnv))
+	if err != nil {
+		return fmt.Errorf("issue tracker not configured: %v (see configs/integrations.yaml and $%s)", err, tokenEnv)
+	}
+
+	// Record each issue as soon as it is opened so an interrupted export never duplicates
+	ledger.Provider, ledger.Repository = tracker.Name(), *repo
+	filePerm := cfg.Output.Permissions.FilePerm()
+	opened := 0
+	for _, issue := range pending {
+		issueURL, err := tracker.Create(context.Background(), issue)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open issue for %s: %v\n", issue.Key, err)
+			continue
+		}
+		ledger.Issues[issue.Key] = issueURL
+		if err := ledger.Save(workspaceDir, filePerm); err != nil {
+			return err
+		}
+		opened++
+		fmt.Printf("Opened %s\n", issueURL)
+	}
+
+	if opened < len(pending) {
+		return fmt.Errorf("%d of %d issues could not be opened", len(pending)-opened, len(pending))
+	}
+	fmt.Printf("\n%d issue(s) opened in %s\n", opened, *repo)
+	return nil
+}
+
+func printIssuesUsage() {
+	fmt.Println("Usage: ipcrawler issues [options] <workspace>")
+	fmt.Println()
+	fmt.Println("Opens the workspace's open ports as GitHub or GitLab issues, labeled with")
+	fmt.Println("their severity and host labels. Issues already opened are recorded in")
+	fmt.Println("reports/issues.json and never opened twice.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --provider NAME     github or gitlab (default from configs/integrations.yaml)")
+	fmt.Println("      --repo REPO         owner/repo, or GitLab group/project or project ID")
+	fmt.Println("      --group-by MODE     host (one issue per host) or finding (one per open port)")
+	fmt.Println("      --dry-run           Show the issues without opening them")
+	fmt.Println()
+	fmt.Println("The API token is read from $GITHUB_TOKEN or $GITLAB_TOKEN unless token_env is set.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler issues --dry-run ipcrawler_results/10_10_10_5_...")
+	fmt.Println("  ipcrawler issues --provider github --repo acme/remediation <workspace>")
+}
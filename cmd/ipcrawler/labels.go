@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/scope"
+)
+
+// buildLabeler compiles the labeling rules from labels.yaml
+func buildLabeler(cfg *config.Config) (*scope.Labeler, error) {
+	labeler := scope.NewLabeler()
+	for _, rule := range cfg.Labels.Rules {
+		entries := append(append([]string{}, rule.CIDRs...), rule.Hosts...)
+		if err := labeler.AddRule(rule.Label, entries); err != nil {
+			return nil, err
+		}
+	}
+	return labeler, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+)
+
+// launchChoice is what the interactive launcher collected for one scan
+type launchChoice struct {
+	Target     string
+	Workflows  []string                     // Workflow file names; all workflows when empty
+	RateLimit  int                          // Packets per second; the configured limit when 0
+	Parameters map[string]map[string]string // Step parameters to use instead, keyed by "workflow/step"
+}
+
+// launcherHistoryRuns is how many previous runs of the target the launcher shows
+const launcherHistoryRuns = 5
+
+// runLauncher asks for a target and the workflows to run, showing the target's previous runs
+// under baseDir, and optionally lets the user edit the rate limit and step parameters. It is what `ipcrawler` opens on a terminal when no target is given;
+// --no-tui, a target or a non-terminal skips it
+func runLauncher(in io.Reader, out io.Writer, workflows map[string]*executor.Workflow, baseDir string) (*launchChoice, error) {
+	names := make([]string, 0, len(workflows))
+	for name := range workflows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(out, "IPCrawler v%s\n\n", ipcrawlerVersion)
+	fmt.Fprintln(out, "Workflows:")
+	for i, name := range names {
+		fmt.Fprintf(out, "  %2d. %-22s %s\n", i+1, name, workflows[name].Description)
+	}
+	fmt.Fprintln(out)
+
+	reader := bufio.NewReader(in)
+	choice := &launchChoice{}
+	for choice.Target == "" {
+		answer, err := promptLine(reader, out, "Target (IP, hostname or CIDR; empty to quit): ")
+		if err != nil || answer == "" {
+			return nil, fmt.Errorf("cancelled")
+		}
+		choice.Target = answer
+	}
+	if history, err := loadTargetHistory(baseDir, choice.Target); err == nil && len(history) > 0 {
+		fmt.Fprintln(out)
+		printTargetHistory(out, choice.Target, history, launcherHistoryRuns)
+		fmt.Fprintln(out)
+	}
+
+	for {
+		answer, err := promptLine(reader, out, "Workflows (numbers or names, comma-separated; default all): ")
+		if err != nil || answer == "" || strings.EqualFold(answer, "all") {
+			break
+		}
+		selected, err := parseWorkflowChoice(answer, names)
+		if err == nil {
+			choice.Workflows = selected
+			break
+		}
+		fmt.Fprintf(out, "  %v\n", err)
+	}
+
+	answer, err := promptLine(reader, out, "Edit parameters before queueing? [y/N]: ")
+	if err == nil && (strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")) {
+		selected := choice.Workflows
+		if len(selected) == 0 {
+			selected = names
+		}
+		editParameters(reader, out, choice, workflows, selected)
+	}
+	return choice, nil
+}
+
+// editParameters asks for the rate limit and for new parameters of every selected step whose
+// tool accepts parameters. Values are checked with the tool's parameter builder before they
+// are accepted; steps left unchanged are not recorded in choice
+func editParameters(reader *bufio.Reader, out io.Writer, choice *launchChoice, workflows map[string]*executor.Workflow, selected []string) {
+	for {
+		answer, err := promptLine(reader, out, "Rate limit in packets/second (empty keeps the configured limit): ")
+		if err != nil || answer == "" {
+			break
+		}
+		if rate, err := strconv.Atoi(answer); err == nil && rate > 0 {
+			choice.RateLimit = rate
+			break
+		}
+		fmt.Fprintln(out, "  rate limit must be a positive number")
+	}
+
+	manager := executor.NewToolParameterManager()
+	executor.RegisterAllParameterBuilders(manager)
+	for _, name := range selected {
+		for _, step := range workflows[name].Steps {
+			keys := manager.ParameterKeys(step.Tool)
+			if len(keys) == 0 && len(step.Parameters) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "\n%s / %s (%s)\n", name, step.Name, step.Tool)
+			fmt.Fprintf(out, "  current: %s\n", formatParameters(step.Parameters))
+			if len(keys) > 0 {
+				fmt.Fprintf(out, "  keys:    %s\n", strings.Join(keys, ", "))
+			}
+			for {
+				answer, err := promptLine(reader, out, "  key=value ... (empty keeps, key= removes, - clears all): ")
+				if err != nil || answer == "" {
+					break
+				}
+				params, err := parseParameterEdit(answer, step.Parameters)
+				if err == nil {
+					_, err = manager.BuildArguments(step.Tool, params)
+				}
+				if err == nil {
+					if choice.Parameters == nil {
+						choice.Parameters = make(map[string]map[string]string)
+					}
+					choice.Parameters[name+"/"+step.Name] = params
+					break
+				}
+				fmt.Fprintf(out, "  %v\n", err)
+			}
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+// parseParameterEdit applies space-separated key=value pairs to a copy of current; an empty
+// value removes the key and "-" alone clears every parameter
+func parseParameterEdit(answer string, current map[string]string) (map[string]string, error) {
+	params := make(map[string]string)
+	if answer == "-" {
+		return params, nil
+	}
+	for key, value := range current {
+		params[key] = value
+	}
+	for _, pair := range strings.Fields(answer) {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("%q is not key=value", pair)
+		}
+		if value == "" {
+			delete(params, key)
+			continue
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// formatParameters lists parameters as sorted key=value pairs
+func formatParameters(params map[string]string) string {
+	if len(params) == 0 {
+		return "(none)"
+	}
+	pairs := make([]string, 0, len(params))
+	for key, value := range params {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// promptLine prints prompt and returns the trimmed answer; err is set when input has ended
+func promptLine(reader *bufio.Reader, out io.Writer, prompt string) (string, error) {
+	fmt.Fprint(out, prompt)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(out)
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// parseWorkflowChoice resolves a comma-separated list of 1-based numbers or names against names
+func parseWorkflowChoice(answer string, names []string) ([]string, error) {
+	var selected []string
+	for _, item := range strings.Split(answer, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if number, err := strconv.Atoi(item); err == nil {
+			if number < 1 || number > len(names) {
+				return nil, fmt.Errorf("no workflow %d (choose 1-%d)", number, len(names))
+			}
+			selected = appendUnique(selected, names[number-1])
+			continue
+		}
+		found := false
+		for _, name := range names {
+			if strings.EqualFold(name, item) {
+				selected = appendUnique(selected, name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown workflow %q", item)
+		}
+	}
+	return selected, nil
+}
+
+// applyParameterOverrides replaces the parameters of the steps named by overrides' "workflow/step"
+// keys. Workflows that are not part of the run are ignored; an unknown step is an error
+func applyParameterOverrides(workflows map[string]*executor.Workflow, overrides map[string]map[string]string) error {
+	for key, params := range overrides {
+		workflowName, stepName, _ := strings.Cut(key, "/")
+		workflow, exists := workflows[workflowName]
+		if !exists {
+			continue
+		}
+		found := false
+		for _, step := range workflow.Steps {
+			if step.Name == stepName {
+				step.Parameters = params
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("workflow %s has no step %q to apply parameters to", workflowName, stepName)
+		}
+	}
+	return nil
+}
@@ -2,28 +2,42 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
-	"gopkg.in/yaml.v3"
 	"github.com/spf13/pflag"
 
 	"github.com/charmbracelet/log"
 	"github.com/neur0map/ipcrawler/embedded"
 	"github.com/neur0map/ipcrawler/internal/config"
 	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/features"
 	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/privilege"
+	"github.com/neur0map/ipcrawler/internal/profiling"
+	"github.com/neur0map/ipcrawler/internal/report"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/session"
 	"github.com/neur0map/ipcrawler/internal/userconfig"
+	"github.com/neur0map/ipcrawler/internal/workflow/loader"
+	"github.com/neur0map/ipcrawler/internal/workspace"
 )
 
+// ipcrawlerVersion is the current release version
+const ipcrawlerVersion = "1.0.0"
+
 // isValidHostname performs basic hostname validation
 func isValidHostname(hostname string) bool {
 	// Basic hostname validation
@@ -50,8 +64,6 @@ func isValidHostname(hostname string) bool {
 	return true
 }
 
-// sanitizeTargetForPath converts a target (IP, hostname, CIDR) to a safe directory name
-
 // getProjectDirectory returns the directory where the project files are located
 func getProjectDirectory() (string, error) {
 	// Try to get executable directory first (for built binaries)
@@ -112,136 +124,13 @@ func getTerminalSize() (int, int) {
 	return width, height
 }
 
-// loadWorkflowFromPath loads a workflow from a specific file path
-func loadWorkflowFromPath(filePath string) (*executor.Workflow, error) {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read workflow file %s: %v", filePath, err)
-	}
-
-	// Define a temporary struct with proper YAML tags for unmarshaling
-	type yamlWorkflowStep struct {
-		Name               string            `yaml:"name"`
-		Tool               string            `yaml:"tool"`
-		Description        string            `yaml:"description"`
-		Modes              []string          `yaml:"modes"`
-		Concurrent         bool              `yaml:"concurrent"`
-		CombineResults     bool              `yaml:"combine_results"`
-		DependsOn          string            `yaml:"depends_on"`
-		StepPriority       string            `yaml:"step_priority"`
-		MaxConcurrentTools int               `yaml:"max_concurrent_tools"`
-		Variables          map[string]string `yaml:"variables"`
-	}
-	
-	type yamlWorkflow struct {
-		Name                   string              `yaml:"name"`
-		Description            string              `yaml:"description"`
-		Category               string              `yaml:"category"`
-		ParallelWorkflow       bool                `yaml:"parallel_workflow"`
-		IndependentExecution   bool                `yaml:"independent_execution"`
-		MaxConcurrentWorkflows int                 `yaml:"max_concurrent_workflows"`
-		WorkflowPriority       string              `yaml:"workflow_priority"`
-		Steps                  []yamlWorkflowStep  `yaml:"steps"`
-	}
-
-	var yamlWf yamlWorkflow
-	if err := yaml.Unmarshal(data, &yamlWf); err != nil {
-		return nil, fmt.Errorf("failed to parse workflow YAML %s: %v", filePath, err)
-	}
-
-	// Convert to executor.Workflow
-	workflow := &executor.Workflow{
-		Name:                    yamlWf.Name,
-		Description:             yamlWf.Description,
-		Category:                yamlWf.Category,
-		ParallelWorkflow:        yamlWf.ParallelWorkflow,
-		IndependentExecution:    yamlWf.IndependentExecution,
-		MaxConcurrentWorkflows:  yamlWf.MaxConcurrentWorkflows,
-		WorkflowPriority:        yamlWf.WorkflowPriority,
-		Steps:                   make([]*executor.WorkflowStep, len(yamlWf.Steps)),
-	}
-
-	// Convert steps
-	for i, yamlStep := range yamlWf.Steps {
-		workflow.Steps[i] = &executor.WorkflowStep{
-			Name:               yamlStep.Name,
-			Tool:               yamlStep.Tool,
-			Description:        yamlStep.Description,
-			Modes:              yamlStep.Modes,
-			Concurrent:         yamlStep.Concurrent,
-			CombineResults:     yamlStep.CombineResults,
-			DependsOn:          yamlStep.DependsOn,
-			StepPriority:       yamlStep.StepPriority,
-			MaxConcurrentTools: yamlStep.MaxConcurrentTools,
-			Variables:          yamlStep.Variables,
-		}
-	}
-
-	return workflow, nil
-}
-
 // loadWorkflowFromEmbedded loads a workflow from embedded resources
 func loadWorkflowFromEmbedded(path string) (*executor.Workflow, error) {
 	data, err := embedded.ReadWorkflowFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read embedded workflow file %s: %v", path, err)
 	}
-	
-	// Define a temporary struct with proper YAML tags for unmarshaling
-	type yamlWorkflowStep struct {
-		Name                 string   `yaml:"name"`
-		Tool                 string   `yaml:"tool"`
-		Description          string   `yaml:"description"`
-		Modes                []string `yaml:"modes"`
-		Concurrent           bool     `yaml:"concurrent"`
-		CombineResults       bool     `yaml:"combine_results"`
-		StepPriority         string   `yaml:"step_priority"`
-		MaxConcurrentTools   int      `yaml:"max_concurrent_tools"`
-	}
-	
-	type yamlWorkflow struct {
-		Name                   string              `yaml:"name"`
-		Description            string              `yaml:"description"`
-		Category               string              `yaml:"category"`
-		ParallelWorkflow       bool                `yaml:"parallel_workflow"`
-		IndependentExecution   bool                `yaml:"independent_execution"`
-		MaxConcurrentWorkflows int                 `yaml:"max_concurrent_workflows"`
-		WorkflowPriority       string              `yaml:"workflow_priority"`
-		Steps                  []yamlWorkflowStep  `yaml:"steps"`
-	}
-	
-	var yamlWf yamlWorkflow
-	if err := yaml.Unmarshal(data, &yamlWf); err != nil {
-		return nil, fmt.Errorf("failed to parse embedded workflow YAML %s: %v", path, err)
-	}
-	
-	// Convert to executor.Workflow
-	workflow := &executor.Workflow{
-		Name:                    yamlWf.Name,
-		Description:             yamlWf.Description,
-		Category:                yamlWf.Category,
-		ParallelWorkflow:        yamlWf.ParallelWorkflow,
-		IndependentExecution:    yamlWf.IndependentExecution,
-		MaxConcurrentWorkflows:  yamlWf.MaxConcurrentWorkflows,
-		WorkflowPriority:        yamlWf.WorkflowPriority,
-		Steps:                   make([]*executor.WorkflowStep, len(yamlWf.Steps)),
-	}
-	
-	// Convert steps
-	for i, yamlStep := range yamlWf.Steps {
-		workflow.Steps[i] = &executor.WorkflowStep{
-			Name:               yamlStep.Name,
-			Tool:               yamlStep.Tool,
-			Description:        yamlStep.Description,
-			Modes:              yamlStep.Modes,
-			Concurrent:         yamlStep.Concurrent,
-			CombineResults:     yamlStep.CombineResults,
-			StepPriority:       yamlStep.StepPriority,
-			MaxConcurrentTools: yamlStep.MaxConcurrentTools,
-		}
-	}
-	
-	return workflow, nil
+	return loader.ParseWorkflow(data, "embedded:"+path)
 }
 
 // discoverAllWorkflows automatically discovers all workflow files in the workflows directory
@@ -262,7 +151,7 @@ func discoverAllWorkflows() (map[string]*executor.Workflow, error) {
 			
 			// Process .yaml files
 			if strings.HasSuffix(d.Name(), ".yaml") {
-				workflow, err := loadWorkflowFromPath(path)
+				workflow, err := loader.LoadWorkflow(path)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "WARN: Failed to load workflow %s: %v\n", path, err)
 					return nil
@@ -307,7 +196,47 @@ func discoverAllWorkflows() (map[string]*executor.Workflow, error) {
 
 
 // runCLI executes all workflows in CLI mode without TUI
-func runCLI(target string, outputMode output.OutputMode, customOutputDir string) error {
+// scanHooks let callers other than a plain CLI run drive and observe a run
+type scanHooks struct {
+	Context      context.Context // Cancels the run when done
+	ScanID       string          // Use this scan ID instead of generating one
+	Workflows    []string        // Run only these workflows (file name or title); all when empty
+	Resume       *resumeRun      // Continue an interrupted run's queue in its workspace
+	RateLimit    int             // {{rate_limit}} for this target; the configured default when 0
+	Pacing       string          // Pacing preset for every tool; tools.yaml pacing when empty
+	Parameters   map[string]map[string]string // Step parameters to use instead, keyed by "workflow/step"
+	NoHistory    bool            // Keep the run out of the run database and ETA history (self-test)
+	OnWorkspace  func(workspaceDir string)
+	OnFinished   func(workspaceDir string) // Runs once the reports are written, before the workspace may be uploaded or archived away
+	OnEvent      func(event executor.Event) // Receives the orchestrator's lifecycle events (no tool output lines)
+	OnWarning    func(warning output.RunWarning)
+	OnExecution  func(status func() executor.ExecutionStatus) // Receives the engine's live tool execution state
+	WorkflowFile string          // Run only the workflow in this file, on its own: its after and triggers are ignored
+	SessionName  string          // --session-name: prefixes the workspace and report file names, tags the manifest and logs
+	Profile      string          // -p: run only the workflows whose tags pass this profile's filter
+	Engagement   *scope.Engagement // --scope: every tool target and derived host must be in it
+	ToolMock     executor.ToolMock // Runs instead of every tool (workflow test)
+}
+
+func runCLI(target string, outputMode output.OutputMode, customOutputDir string, exclusions *scope.ExclusionList, targetLabels []string, dropPrivileges bool, hooks *scanHooks) (runErr error) {
+	// Unique identifier for this run, embedded in logs, filenames and the manifest
+	scanID := session.NewScanID()
+	if hooks != nil && hooks.ScanID != "" {
+		scanID = hooks.ScanID
+	}
+	var sessionName string
+	var engagement *scope.Engagement
+	if hooks != nil {
+		sessionName = hooks.SessionName
+		engagement = hooks.Engagement
+	}
+	var resume *resumeRun
+	if hooks != nil && hooks.Resume != nil {
+		resume = hooks.Resume
+		scanID = resume.Manifest.ScanID
+		sessionName = resume.Manifest.SessionName
+	}
+	
 	// Initialize logger for CLI output - suppress if not in verbose/debug mode
 	var logger *log.Logger
 	if outputMode == output.OutputModeVerbose || outputMode == output.OutputModeDebug {
@@ -327,7 +256,10 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 		})
 	}
 	
-	logger.Info("=== IPCrawler CLI Mode ===", "target", target)
+	logger = logger.With("scan_id", scanID)
+	if sessionName != "" {
+		logger = logger.With("session", sessionName)
+	}
 	
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -335,14 +267,58 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 		return fmt.Errorf("failed to load configuration: %v", err)
 	}
 	
+	// API keys for {{secret:name}} arguments, and the redaction of their values and of the
+	// configured patterns from everything the run prints and writes
+	secretStore, err := loadSecrets(logger)
+	if err != nil {
+		return fmt.Errorf("failed to load secrets: %v", err)
+	}
+	redactor, err := output.NewRedactor(secretStore, cfg.Output.Redaction)
+	if err != nil {
+		return fmt.Errorf("invalid redaction configuration: %v", err)
+	}
+	if outputMode == output.OutputModeVerbose || outputMode == output.OutputModeDebug {
+		logger.SetOutput(output.NewRedactingWriter(os.Stderr, redactor))
+	}
+	logger.Info("=== IPCrawler CLI Mode ===", "target", target)
+	
 	// Validate target
 	if target == "" {
 		return fmt.Errorf("target cannot be empty")
 	}
 	
-	// Create workspace directory
-	sanitizedTarget := sanitizeTargetForPath(target)
-	timestamp := time.Now().Unix()
+	// Resolve target labels from labeling rules plus any given with --label
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid labels configuration: %v", err)
+	}
+	targetLabels = scope.MergeLabels(targetLabels, labeler.LabelsFor(target))
+	
+	// Reject unknown report formats before scanning rather than after
+	if cfg.Security.Reporting.AutoGenerate {
+		if err := report.ValidateFormats(cfg.Security.Reporting.Formats); err != nil {
+			return fmt.Errorf("invalid reporting configuration: %v", err)
+		}
+	}
+	if err := output.ValidateResultsEncoding(cfg.Output.ResultsEncoding); err != nil {
+		return err
+	}
+	if err := executor.ValidateVersionPolicy(cfg.Tools.VersionPolicy); err != nil {
+		return err
+	}
+	if cfg.Tools.ToolExecution.MaxParallelExecutions > 0 {
+		logger.Warn("tool_execution.max_parallel_executions is deprecated and has no effect; tool slots follow max_concurrent_executions")
+	}
+	
+	// Apply the configured permission policy before anything is written
+	if err := cfg.Output.Permissions.Validate(); err != nil {
+		return err
+	}
+	if umask, ok := cfg.Output.Permissions.UmaskValue(); ok {
+		privilege.SetUmask(umask)
+	}
+	dirMode := cfg.Output.Permissions.DirPerm()
+	fileMode := cfg.Output.Permissions.FilePerm()
 	
 	// Use custom output directory if provided, otherwise use config default
 	var baseDir string
@@ -352,27 +328,181 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 		baseDir = cfg.Output.WorkspaceBase
 	}
 	
-	workspaceDir := filepath.Join(baseDir, fmt.Sprintf("%s_%d", sanitizedTarget, timestamp))
+	// Create the workspace directory, or complete the resumed run's
+	ws := workspace.New(filepath.Join(baseDir, workspace.Name(sessionName, target, time.Now(), scanID)))
+	if resume != nil {
+		ws = workspace.New(resume.Workspace)
+		baseDir = filepath.Dir(ws.Dir)
+	}
+	workspaceDir := ws.Dir
 	
-	if err := createWorkspaceStructure(workspaceDir); err != nil {
+	if err := ws.Ensure(dirMode); err != nil {
 		return fmt.Errorf("failed to create workspace: %v", err)
 	}
 	
 	logger.Info("Workspace created", "path", workspaceDir)
+	if hooks == nil || !hooks.NoHistory {
+		enforceRetention(cfg, baseDir, workspaceDir, logger)
+	}
+	if hooks != nil && hooks.OnWorkspace != nil {
+		hooks.OnWorkspace(workspaceDir)
+	}
+	if outputMode == output.OutputModePlain {
+		printRecord(redactor, "event", "scan_started", "target", target, "scan_id", scanID, "workspace", workspaceDir)
+	}
+	
+	// Resolve the invoking user when started through sudo
+	invokingUser, err := privilege.FromSudo()
+	if err != nil {
+		return fmt.Errorf("failed to resolve sudo user: %v", err)
+	}
+	
+	// Hand the workspace back to the invoking user now and again at exit, so files
+	// written by this process or by privileged tools are not left owned by root
+	if invokingUser != nil && cfg.Output.Permissions.ChownToInvokingUser {
+		if err := invokingUser.ChownTree(workspaceDir); err != nil {
+			logger.Warn("Failed to change workspace ownership", "error", err)
+		}
+		if err := invokingUser.Chown(baseDir); err != nil {
+			logger.Warn("Failed to change workspace base ownership", "error", err)
+		}
+		defer func() {
+			if _, err := os.Stat(workspaceDir); err != nil {
+				return // Archived or stored and removed
+			}
+			if err := invokingUser.ChownTree(workspaceDir); err != nil {
+				logger.Warn("Failed to change workspace ownership", "error", err)
+			}
+		}()
+	}
+	
+	// Run non-privileged tools as the invoking user when privilege dropping is enabled
+	var runAsUser *privilege.RunAsUser
+	if dropPrivileges || cfg.Security.Execution.DropPrivileges {
+		if invokingUser == nil {
+			logger.Warn("Privilege drop requested but not running as root via sudo; continuing as current user")
+		} else {
+			runAsUser = invokingUser
+			logger.Info("Dropping privileges for non-privileged tools", "user", runAsUser.String())
+		}
+	}
+	
+	// Resolve the engagement timezone for target-local times in the manifest
+	location, err := executor.LoadEngagementLocation(cfg.Output.Timezone)
+	if err != nil {
+		logger.Warn("Falling back to local timezone", "error", err)
+	}
+	
+	// Record the run in the workspace manifest and finalize it on exit
+	runStarted := time.Now()
+	manifest := &session.RunManifest{
+		ScanID:     scanID,
+		Version:    ipcrawlerVersion,
+		Target:     target,
+		Workspace:  workspaceDir,
+		OutputMode: outputMode.String(),
+		Status:     session.RunStatusRunning,
+		StartedAt:  runStarted.Round(0),
+		Exclusions: exclusions.Entries(),
+		Labels:     targetLabels,
+		SessionName: sessionName,
+		Experimental: features.EnabledNames(),
+		Environment: session.CaptureEnvironment(ipcrawlerVersion),
+	}
+	versionInfo := collectVersionInfo()
+	manifest.Environment.WorkflowsSHA256 = versionInfo.WorkflowHash
+	manifest.Environment.ToolsSHA256 = versionInfo.ToolsHash
+	if resume != nil {
+		// Keep the original run's record and note when it was resumed
+		manifest = resume.Manifest
+		manifest.Status = session.RunStatusRunning
+		manifest.Error = ""
+		manifest.FinishedAt = nil
+		manifest.ResumedAt = append(manifest.ResumedAt, runStarted.Round(0))
+		if err := session.RemoveShutdownMarker(workspaceDir); err != nil {
+			logger.Warn("Failed to remove shutdown marker", "error", err)
+		}
+	}
+	manifest.SetTargetTimezone(location)
+	if err := session.WriteManifest(workspaceDir, manifest, fileMode); err != nil {
+		logger.Warn("Failed to write run manifest", "error", err)
+	}
+	runReport := output.NewReportGenerator(scanID, target, workspaceDir, runStarted.Round(0))
+	runReport.SetSession(sessionName)
+	runReport.SetTimezone(location)
+	runReport.SetEncoding(cfg.Output.ResultsEncoding)
+	runReport.SetRedactor(redactor)
+	index := newWorkspaceIndex(cfg, workspaceDir, redactor, logger)
+	var shutdown *shutdownWatch
+	defer func() {
+		finishedAt := time.Now().Round(0)
+		manifest.FinishedAt = &finishedAt
+		manifest.DurationSeconds = time.Since(runStarted).Seconds()
+		manifest.SetTargetTimezone(location)
+		manifest.Status = session.RunStatusCompleted
+		if runErr != nil {
+			manifest.Status = session.RunStatusFailed
+			manifest.Error = runErr.Error()
+		}
+		if sig, _ := shutdown.received(); sig != "" {
+			manifest.Status = session.RunStatusInterrupted
+			manifest.Error = fmt.Sprintf("interrupted by %s", sig)
+		}
+		if err := session.WriteManifest(workspaceDir, manifest, fileMode); err != nil {
+			logger.Warn("Failed to finalize run manifest", "error", err)
+		}
+		writeShutdownMarker(workspaceDir, shutdown, fileMode, logger)
+		
+		// Reports are generated last so they reflect the finalized manifest
+		runReport.Finish(manifest.Status, manifest.Error, finishedAt, time.Since(runStarted))
+		summary := writeRunReport(cfg, runReport, workspaceDir, hooks == nil || !hooks.NoHistory, logger)
+		generateRunReports(cfg, workspaceDir, redactor, logger)
+		buildRawIndex(cfg, workspaceDir, fileMode, logger)
+		index.update(summary)
+		shipRunResults(cfg, workspaceDir, logger)
+		recordUsage(cfg, runReport.Report(), logger)
+		if hooks == nil || !hooks.NoHistory {
+			recordDurations(runReport.Report(), logger)
+			recordRun(cfg, manifest, runReport.Report(), summary, logger)
+		}
+		if outputMode == output.OutputModePlain {
+			printPlainFinished(redactor, runReport.Report())
+		} else if hooks == nil || hooks.OnWarning == nil {
+			printRunWarnings(runReport.Report())
+		}
+		if hooks != nil && hooks.OnFinished != nil {
+			hooks.OnFinished(workspaceDir)
+		}
+		if hooks == nil || !hooks.NoHistory {
+			// Last: both may remove the workspace
+			uploadRunWorkspace(cfg, workspaceDir, logger)
+			archiveRunWorkspace(cfg, workspaceDir, manifest.Status, invokingUser, logger)
+		}
+	}()
 	
 	// Set up workspace file logging
-	debugLogger, infoLogger, rawLogger, err := setupWorkspaceLogging(workspaceDir)
+	fileLoggers, err := ws.Loggers(scanID, sessionName, fileMode, func(w io.Writer) io.Writer {
+		return output.NewRedactingWriter(w, redactor)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to setup workspace logging: %v", err)
 	}
+	debugLogger, infoLogger := fileLoggers.Debug, fileLoggers.Info
 	// Note: File handles will be closed when the function exits
 	
 	// Make loggers available globally for executors
-	setGlobalLoggers(debugLogger, infoLogger, rawLogger)
+	setGlobalLoggers(debugLogger, infoLogger, fileLoggers.Raw)
 	
 	// Discover all workflows
-	workflows, err := discoverAllWorkflows()
-	if err != nil {
+	var workflows map[string]*executor.Workflow
+	if hooks != nil && hooks.WorkflowFile != "" {
+		workflow, err := loader.LoadWorkflow(hooks.WorkflowFile)
+		if err != nil {
+			return fmt.Errorf("failed to load workflow %s: %v", hooks.WorkflowFile, err)
+		}
+		workflow.After, workflow.Triggers = nil, nil
+		workflows = map[string]*executor.Workflow{strings.TrimSuffix(filepath.Base(hooks.WorkflowFile), ".yaml"): workflow}
+	} else if workflows, err = discoverAllWorkflows(); err != nil {
 		return fmt.Errorf("failed to discover workflows: %v", err)
 	}
 	
@@ -380,12 +510,37 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 		return fmt.Errorf("no workflows found in workflows directory")
 	}
 	
+	// Restrict the run to the requested workflows (API scans may select a subset)
+	if hooks != nil && len(hooks.Workflows) > 0 {
+		if workflows, err = selectWorkflows(workflows, hooks.Workflows); err != nil {
+			return err
+		}
+	}
+	
+	// Keep the workflows the profile's tag filter selects
+	if hooks != nil && hooks.Profile != "" {
+		if workflows, err = profileWorkflows(cfg, workflows, hooks.Profile); err != nil {
+			return err
+		}
+		manifest.Profile = hooks.Profile
+	}
+	
+	// Replace the parameters of steps edited in the launcher before anything is validated
+	if hooks != nil {
+		if err := applyParameterOverrides(workflows, hooks.Parameters); err != nil {
+			return err
+		}
+	}
+	
 	// Initialize output controller for tree display
 	outputController := output.NewOutputController(outputMode)
-	globalOutputController = outputController
+	outputController.SetRedactor(redactor)
+	setGlobalOutputController(outputController)
 	
-	// Display workflow tree (always shown regardless of output mode)
-	outputController.PrintWorkflowTree("workflows", nil)
+	// Display workflow tree (always shown regardless of output mode, unless one workflow file runs)
+	if hooks == nil || hooks.WorkflowFile == "" {
+		outputController.PrintWorkflowTree("workflows", nil)
+	}
 	
 	// Log discovered workflows
 	workflowNames := make([]string, 0, len(workflows))
@@ -393,12 +548,78 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 		workflowNames = append(workflowNames, name)
 		logger.Info("Discovered workflow", "name", name, "title", workflow.Name, "description", workflow.Description)
 	}
+	sort.Strings(workflowNames)
+	if resume == nil {
+		manifest.Workflows = workflowNames
+	}
 	
 	logger.Info("Starting workflow execution", "count", len(workflows), "workflows", strings.Join(workflowNames, ", "))
 	
 	// Initialize execution engine and orchestrator
 	executionEngine := executor.NewToolExecutionEngine(cfg, "", outputMode)
 	
+	// Propagate the run identifier and session name before workspace loggers are created
+	executionEngine.SetScanID(scanID)
+	executionEngine.SetSessionName(sessionName)
+	if hooks != nil && hooks.OnExecution != nil {
+		hooks.OnExecution(executionEngine.GetExecutionStatus)
+	}
+	
+	// Give tools this target's share of the rate budget as {{rate_limit}}
+	rateLimit := executor.NewTargetScheduler(cfg.Tools.TargetScheduling).RateLimitFor(1)
+	if hooks != nil && hooks.RateLimit > 0 {
+		rateLimit = hooks.RateLimit
+	}
+	executionEngine.SetRateLimit(rateLimit)
+	manifest.RateLimit = rateLimit
+	logger.Info("Rate limit", "packets_per_second", rateLimit)
+	
+	// One pacing preset slows down (or speeds up) every tool through its pacing flags
+	pacing := cfg.Tools.Pacing
+	if hooks != nil && hooks.Pacing != "" {
+		pacing = hooks.Pacing
+	}
+	if err := executionEngine.SetPacing(pacing); err != nil {
+		return fmt.Errorf("invalid pacing: %v", err)
+	}
+	manifest.Pacing = executionEngine.GetPacing()
+	if manifest.Pacing != "" {
+		logger.Info("Pacing", "preset", manifest.Pacing)
+	}
+	
+	executionEngine.SetSecrets(secretStore)
+	executionEngine.SetRedactor(redactor)
+	
+	// Enforce host exclusions for every tool, including chained workflows
+	executionEngine.SetExclusions(exclusions)
+	if engagement != nil {
+		executionEngine.SetEngagement(engagement)
+		manifest.Scope = engagement.Path()
+		logger.Info("Scope enforced", "file", engagement.Path(), "audit_log", engagement.AuditLog())
+	}
+	
+	// Run non-privileged tools as the sudo user when dropping privileges
+	executionEngine.SetRunAsUser(runAsUser)
+	if hooks != nil && hooks.ToolMock != nil {
+		executionEngine.SetToolMock(hooks.ToolMock)
+	}
+	if !exclusions.IsEmpty() {
+		logger.Info("Host exclusions active", "entries", strings.Join(exclusions.Entries(), ","))
+	}
+	if len(targetLabels) > 0 {
+		logger.Info("Target labels", "labels", strings.Join(targetLabels, ","))
+	}
+	
+	// Resolve the target once for the whole run: tools share the answer as {{resolved_ip}}
+	if ips, err := executionEngine.ResolveTarget(target); err != nil {
+		logger.Warn("Target does not resolve, tools will look it up themselves", "target", target, "error", err)
+	} else if resolved := executionEngine.ResolvedHosts(); len(resolved) > 0 {
+		logger.Info("Target resolved", "target", target, "addresses", strings.Join(ips, ","))
+		if resume == nil {
+			manifest.ResolvedHosts = resolved
+		}
+	}
+	
 	// Set the workspace base directory for consistent path resolution
 	executionEngine.SetWorkspaceBase(workspaceDir)
 	
@@ -410,41 +631,159 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 		return fmt.Errorf("failed to setup tool execution engine logging: %v", err)
 	}
 	
+	// Warnings (scan imperfect) are kept apart from errors (scan failed) in the report and summary
+	executionEngine.SetWarningHandler(func(warning output.RunWarning) {
+		runReport.RecordWarning(warning)
+		logger.Warn("Scan warning", "kind", warning.Kind, "tool", warning.Tool, "mode", warning.Mode, "message", warning.Message)
+		if hooks != nil && hooks.OnWarning != nil {
+			hooks.OnWarning(warning)
+		}
+	})
+	
+	// Record the version of every tool the workflows use (a resumed run keeps its original record)
+	if resume == nil {
+		if hooks == nil || hooks.ToolMock == nil {
+			recordToolVersions(manifest.Environment, workflows, executionEngine, logger)
+		}
+		if err := session.WriteManifest(workspaceDir, manifest, fileMode); err != nil {
+			logger.Warn("Failed to write run manifest", "error", err)
+		}
+	}
+	
 	workflowExecutor := executor.NewWorkflowExecutor(executionEngine)
+	
+	// Validate combiner options up front and record the effective values in the manifest
+	manifest.Combiners = make(map[string]map[string]string)
+	manifest.Parameters = make(map[string]map[string]string)
+	for workflowName, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			if len(step.Parameters) > 0 {
+				manifest.Parameters[workflowName+"/"+step.Name] = step.Parameters
+			}
+			if !step.CombineResults {
+				continue
+			}
+			options, err := workflowExecutor.ResolveCombinerOptions(step.Tool, step.Combiner)
+			if err != nil {
+				return fmt.Errorf("invalid combiner options in workflow %s step %q: %v", workflowName, step.Name, err)
+			}
+			if options != nil {
+				manifest.Combiners[workflowName+"/"+step.Name] = options
+			}
+		}
+	}
 	workflowOrchestrator := executor.NewWorkflowOrchestrator(workflowExecutor, cfg)
 	
 	// Set output mode before setting up loggers
 	workflowOrchestrator.SetOutputMode(outputMode)
+	workflowOrchestrator.SetReportGenerator(runReport)
+	
+	// Persist the queue so an interrupted run can continue with --resume-queue
+	workflowOrchestrator.SetQueueStatePath(filepath.Join(workspaceDir, executor.QueueStateFileName), fileMode)
 	
 	// Set up workspace logging for workflow orchestrator
 	if err := workflowOrchestrator.SetWorkspaceLoggers(workspaceDir); err != nil {
 		return fmt.Errorf("failed to setup workflow orchestrator logging: %v", err)
 	}
 	
-	// Set up status callback for CLI logging
-	workflowOrchestrator.SetStatusCallback(func(workflowName, target, status, message string) {
-		logger.Info("Workflow status", "workflow", workflowName, "target", target, "status", status, "message", message)
+	// Summarize each finished workflow for later workflows and scripts reading the workspace
+	workflowOrchestrator.SetSummaryDir(filepath.Join(workspaceDir, executor.WorkflowSummaryDirName), dirMode, fileMode)
+	
+	// Queue triggered workflows as steps discover matching services
+	triggers, err := executor.NewTriggerEngine(workflows, executionEngine)
+	if err != nil {
+		return err
+	}
+	if triggers != nil {
+		workflowOrchestrator.SetTriggerEngine(triggers)
+	}
+	
+	// Choose brute-force wordlists from the technologies steps detect
+	wordlists, err := executor.NewWordlistSelector(cfg.Wordlists)
+	if err != nil {
+		return fmt.Errorf("invalid wordlists configuration: %v", err)
+	}
+	workflowOrchestrator.SetWordlistSelector(wordlists)
+	
+	// Estimate the time workflows have left from the tool durations of past runs
+	workflowOrchestrator.SetDurationHistory(loadDurationHistory(logger))
+	
+	// Dump orchestrator state on SIGQUIT/SIGUSR1 to debug hangs without stopping the scan
+	stopSnapshots := watchSnapshotSignals(workflowOrchestrator, filepath.Join(workspaceDir, "logs", "debug"), logger)
+	defer stopSnapshots()
+	
+	// Log workflow status updates; callers driving the run subscribe alongside
+	workflowOrchestrator.Events().Subscribe(func(event executor.Event) {
+		logger.Info("Workflow status", "workflow", event.Workflow, "target", event.Target, "status", event.Status(), "message", event.Message)
 	})
+	if hooks != nil && hooks.OnEvent != nil {
+		workflowOrchestrator.Events().Subscribe(hooks.OnEvent)
+	}
+	if outputMode == output.OutputModePlain {
+		subscribePlainStatus(workflowOrchestrator, redactor)
+	}
+
+	// Keep every lifecycle event in the workspace for later tooling
+	eventLog, err := executor.OpenEventLog(filepath.Join(workspaceDir, executor.EventLogFileName), fileMode)
+	if err != nil {
+		logger.Warn("Failed to open event log", "error", err)
+	} else {
+		eventLog.SetRedactor(redactor)
+		workflowOrchestrator.Events().Subscribe(eventLog.Record)
+		defer eventLog.Close()
+	}
+
+	// Keep INDEX.md (findings counts, links to every artifact) current as workflows finish
+	index.subscribe(workflowOrchestrator)
+	
+	// Post workflow results and new findings to the configured webhooks
+	notifier, err := buildNotifier(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid notifications configuration: %v", err)
+	}
+	if notifier != nil {
+		subscribeNotifications(workflowOrchestrator, notifier, scanID)
+		defer closeNotifier(notifier, logger)
+	}
 	
 	// Queue all workflows
 	var ctx context.Context
 	var cancel context.CancelFunc
+	parent := context.Background()
+	if hooks != nil && hooks.Context != nil {
+		parent = hooks.Context
+	} else {
+		// SIGINT/SIGTERM stops the running tools gracefully, keeps the queue for --resume-queue
+		// and still finalizes the manifest and reports
+		var stopSignals func()
+		parent, shutdown, stopSignals = watchShutdownSignals(parent, executionEngine, logger)
+		defer stopSignals()
+	}
 	
 	// Set timeout from configuration
 	if cfg.Tools.CLIMode.ExecutionTimeoutSeconds > 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(cfg.Tools.CLIMode.ExecutionTimeoutSeconds)*time.Second)
+		ctx, cancel = context.WithTimeout(parent, time.Duration(cfg.Tools.CLIMode.ExecutionTimeoutSeconds)*time.Second)
 		logger.Info("CLI execution timeout set", "seconds", cfg.Tools.CLIMode.ExecutionTimeoutSeconds)
 	} else {
-		ctx, cancel = context.WithCancel(context.Background())
+		ctx, cancel = context.WithCancel(parent)
 		logger.Info("CLI execution timeout disabled (unlimited)")
 	}
 	defer cancel()
 	
-	for workflowName, workflow := range workflows {
-		logger.Info("Queueing workflow", "name", workflowName, "title", workflow.Name)
-		if err := workflowOrchestrator.QueueWorkflow(workflow, target); err != nil {
-			logger.Error("Failed to queue workflow", "name", workflowName, "error", err)
-			continue
+	if resume != nil {
+		requeueWorkflows(workflowOrchestrator, workflows, resume.Queue, logger)
+	} else {
+		for workflowName, workflow := range workflows {
+			// Workflows with triggers wait for a matching service unless they were asked for by name
+			if len(workflow.Triggers) > 0 && (hooks == nil || len(hooks.Workflows) == 0) {
+				logger.Info("Workflow waits for its triggers", "name", workflowName, "title", workflow.Name)
+				continue
+			}
+			logger.Info("Queueing workflow", "name", workflowName, "title", workflow.Name)
+			if err := workflowOrchestrator.QueueWorkflow(workflow, target); err != nil {
+				logger.Error("Failed to queue workflow", "name", workflowName, "error", err)
+				continue
+			}
 		}
 	}
 	
@@ -461,78 +800,24 @@ func runCLI(target string, outputMode output.OutputMode, customOutputDir string)
 	return nil
 }
 
-// Helper functions for CLI mode
-func sanitizeTargetForPath(target string) string {
-	// Replace special characters for safe directory names
-	sanitized := strings.ReplaceAll(target, ".", "_")
-	sanitized = strings.ReplaceAll(sanitized, ":", "_")
-	sanitized = strings.ReplaceAll(sanitized, "/", "_")
-	sanitized = strings.ReplaceAll(sanitized, "\\", "_")
-	return sanitized
-}
-
-func createWorkspaceStructure(workspaceDir string) error {
-	// Create base workspace directory
-	if err := os.MkdirAll(workspaceDir, 0755); err != nil {
-		return err
-	}
-	
-	// Create subdirectories
-	subdirs := []string{"logs/info", "logs/debug", "logs/error", "logs/warning", "raw", "scans", "reports"}
-	for _, subdir := range subdirs {
-		if err := os.MkdirAll(filepath.Join(workspaceDir, subdir), 0755); err != nil {
-			return err
+// recordToolVersions asks each tool used by the workflows for its version
+func recordToolVersions(env *session.RunEnvironment, workflows map[string]*executor.Workflow, engine *executor.ToolExecutionEngine, logger *log.Logger) {
+	tools := make(map[string]bool)
+	for _, workflow := range workflows {
+		for _, step := range workflow.Steps {
+			tools[step.Tool] = true
 		}
 	}
-	
-	return nil
-}
-
-// setupWorkspaceLogging creates file loggers for the workspace
-func setupWorkspaceLogging(workspaceDir string) (*log.Logger, *log.Logger, *log.Logger, error) {
-	// Create debug logger
-	debugFile, err := os.OpenFile(filepath.Join(workspaceDir, "logs/debug/execution.log"), 
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create debug log file: %v", err)
-	}
-	
-	debugLogger := log.NewWithOptions(debugFile, log.Options{
-		ReportCaller:    false,
-		ReportTimestamp: true,
-		TimeFormat:      time.RFC3339,
-		Prefix:          "DEBUG",
-	})
-	
-	// Create info logger
-	infoFile, err := os.OpenFile(filepath.Join(workspaceDir, "logs/info/workflow.log"),
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create info log file: %v", err)
-	}
-	
-	infoLogger := log.NewWithOptions(infoFile, log.Options{
-		ReportCaller:    false,
-		ReportTimestamp: true,
-		TimeFormat:      time.RFC3339,
-		Prefix:          "INFO",
-	})
-	
-	// Create raw output logger
-	rawFile, err := os.OpenFile(filepath.Join(workspaceDir, "raw/tool_output.log"),
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create raw output file: %v", err)
+	for tool := range tools {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		version, err := engine.ToolVersion(ctx, tool)
+		cancel()
+		if err != nil {
+			logger.Debug("Tool version not recorded", "tool", tool, "error", err)
+			continue
+		}
+		env.SetToolVersion(tool, version)
 	}
-	
-	rawLogger := log.NewWithOptions(rawFile, log.Options{
-		ReportCaller:    false,
-		ReportTimestamp: true,
-		TimeFormat:      time.RFC3339,
-		Prefix:          "RAW",
-	})
-	
-	return debugLogger, infoLogger, rawLogger, nil
 }
 
 // Global loggers for executor modules
@@ -542,8 +827,13 @@ var (
 	globalRawLogger   *log.Logger
 )
 
+// globalsMu guards the globals above when several targets are scanned concurrently
+var globalsMu sync.Mutex
+
 // setGlobalLoggers makes the workspace loggers available to executor modules
 func setGlobalLoggers(debugLogger, infoLogger, rawLogger *log.Logger) {
+	globalsMu.Lock()
+	defer globalsMu.Unlock()
 	globalDebugLogger = debugLogger
 	globalInfoLogger = infoLogger
 	globalRawLogger = rawLogger
@@ -552,6 +842,13 @@ func setGlobalLoggers(debugLogger, infoLogger, rawLogger *log.Logger) {
 // Global output controller for use across the application
 var globalOutputController *output.OutputController
 
+// setGlobalOutputController replaces the global output controller
+func setGlobalOutputController(controller *output.OutputController) {
+	globalsMu.Lock()
+	defer globalsMu.Unlock()
+	globalOutputController = controller
+}
+
 // logDebug writes debug messages to both console and file
 func logDebug(msg string, args ...interface{}) {
 	// Use output controller if available, otherwise fallback to direct printing
@@ -594,6 +891,83 @@ func logRaw(toolName, mode, output string) {
 	}
 }
 
+// runSubcommand runs a subcommand that parses its own flags and reports whether name was one
+func runSubcommand(name string, args []string) bool {
+	var err error
+	switch name {
+	case "view":
+		err = runViewCommand(args)
+	case "search":
+		err = runSearchCommand(args)
+	case "report":
+		err = runReportCommand(args)
+	case "issues":
+		err = runIssuesCommand(args)
+	case "triage":
+		err = runTriageCommand(args)
+	case "ship":
+		err = runShipCommand(args)
+	case "serve":
+		err = runServeCommand(args)
+	case "version":
+		err = runVersionCommand(args)
+	case "stats":
+		err = runStatsCommand(args)
+	case "plan":
+		err = runPlanCommand(args)
+	case "merge":
+		err = runMergeCommand(args)
+	case "diff":
+		err = runDiffCommand(args)
+	case "watch":
+		err = runWatchCommand(args)
+	case "doctor":
+		err = runDoctorCommand(args)
+	case "history":
+		err = runHistoryCommand(args)
+	case "secrets":
+		err = runSecretsCommand(args)
+	case "storage":
+		err = runStorageCommand(args)
+	case "passive":
+		err = runPassiveCommand(args)
+	case "archive":
+		err = runArchiveCommand(args)
+	case "unarchive":
+		err = runUnarchiveCommand(args)
+	case "clean":
+		err = runCleanCommand(args)
+	case "config":
+		err = runConfigCommand(args)
+	case "verify":
+		err = runVerifyCommand(args)
+	case "selftest":
+		err = runSelftestCommand(args)
+	case "workflow":
+		err = runWorkflowCommand(args)
+	default:
+		return false
+	}
+	
+	if err != nil && err != flag.ErrHelp && err != pflag.ErrHelp {
+		fmt.Fprintf(os.Stderr, "%s command failed: %v\n", name, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// applyStatusStyle configures how statuses are shown (ui.display.status_symbols/symbols_only)
+func applyStatusStyle() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return // Commands that need the configuration report the error themselves
+	}
+	display := cfg.UI.Display
+	if err := output.ConfigureStatusStyle(display.StatusSymbols, display.SymbolsOnly); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v (ui.yaml display)\n", err)
+	}
+}
+
 func main() {
 	// Define flags
 	var (
@@ -605,10 +979,46 @@ func main() {
 		setDefaultOutput    = pflag.String("set-default-output", "", "Set permanent default output directory")
 		clearDefaultOutput  = pflag.Bool("clear-default-output", false, "Clear permanent default output directory")
 		showConfig          = pflag.Bool("show-config", false, "Show current configuration")
+		exclude             = pflag.String("exclude", "", "Comma-separated hosts, IPs or CIDRs that must never be scanned")
+		excludeFile         = pflag.String("exclude-file", "", "File of hosts, IPs or CIDRs to exclude (one per line)")
+		scopeFile           = pflag.String("scope", "", "Scope file of the networks and domains the engagement may scan; other hosts are refused and audited")
+		dropPrivileges      = pflag.Bool("drop-privileges", false, "Under sudo, run non-privileged tools and write workspace files as the invoking user")
+		labels              = pflag.String("label", "", "Comma-separated labels for the target (e.g. dmz,critical), added to labels from labels.yaml")
+		pprofAddr           = pflag.String("pprof", "", "Serve pprof profiling endpoints on a loopback address (default "+profiling.DefaultAddress+" when given without a value)")
+		resumeQueue         = pflag.String("resume-queue", "", "Continue the unfinished workflows of an interrupted run's workspace (default: the latest one)")
+		inputList           = pflag.String("input-list", "", "File of targets (IPs, CIDRs or hostnames), one per line; also accepted as -iL")
+		concurrentTargets   = pflag.Int("concurrent-targets", 0, "Targets from -iL scanned at once (default from tools.yaml target_scheduling)")
+		rateLimit           = pflag.Int("rate-limit", 0, "Global packets/requests per second shared by all targets, passed to tools as {{rate_limit}}")
+		pacing              = pflag.String("pacing", "", "Pace every tool: paranoid, sneaky, normal or aggressive (default from tools.yaml pacing)")
+		resumeWorkspace     = pflag.Bool("resume", false, "If the target already has a workspace, continue its unfinished workflows")
+		overwriteWorkspace  = pflag.Bool("overwrite", false, "If the target already has a workspace, delete it and scan again")
+		newWorkspace        = pflag.Bool("new", false, "Always create a new workspace without asking")
+		discover            = pflag.Bool("discover", false, "For a CIDR target, sweep for live hosts and scan each one (tools.yaml host_discovery)")
+		noTUI               = pflag.Bool("no-tui", false, "Never open the interactive launcher; without a target, exit with an error")
+		plain               = pflag.Bool("plain", false, "Print status only as single-line key=value records on stdout, for scripts (no banners, colors or spinners)")
+		dryRun              = pflag.Bool("dry-run", false, "Print the commands and output files the scan would run, in order, without running anything")
+		profile             = pflag.StringP("profile", "p", "", "Run the workflows selected by a profile's tag filter (configs/profiles.yaml), e.g. stealth")
+		sessionName         = pflag.String("session-name", "", "Name the engagement (e.g. acme-external-q3): prefixes workspace and report file names, tags the manifest and logs")
 	)
+	pflag.Lookup("pprof").NoOptDefVal = profiling.DefaultAddress
+	pflag.Lookup("resume-queue").NoOptDefVal = latestWorkspace
+	
+	// Status symbols and colors from ui.yaml apply to every command
+	applyStatusStyle()
+	
+	// So do experimental feature flags
+	applyFeatureFlags()
+	
+	// Subcommands with their own flags are dispatched before global flag parsing
+	if len(os.Args) > 1 && runSubcommand(os.Args[1], os.Args[2:]) {
+		return
+	}
+	
 	
-	// Parse flags
-	pflag.Parse()
+	// Parse flags, accepting nmap's -iL for --input-list
+	if err := pflag.CommandLine.Parse(expandShortFlags(os.Args[1:])); err != nil {
+		os.Exit(2)
+	}
 	
 	// Load user configuration
 	userConfig, err := userconfig.LoadUserConfig()
@@ -619,14 +1029,15 @@ func main() {
 	
 	// Handle version flag
 	if *version {
-		fmt.Printf("IPCrawler v1.0.0\n")
-		fmt.Printf("Built for penetration testing and security assessment\n")
+		printVersion(collectVersionInfo())
 		os.Exit(0)
 	}
 	
 	// Handle show-config flag
 	if *showConfig {
 		fmt.Print(userConfig.GetConfigInfo())
+		printFeatureFlags()
+		printProfiles()
 		os.Exit(0)
 	}
 	
@@ -653,7 +1064,31 @@ func main() {
 	// Show help if requested
 	if *help {
 		fmt.Fprintf(os.Stderr, "Usage: %s [FLAGS] <target>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s                                (on a terminal: pick a target and workflows)\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "       %s registry <command>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s view [options] <file>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s search [options] '<query>'\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s report [options] <workspace>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s issues [options] <workspace>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s triage [options] <workspace>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s ship [options] <workspace>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s serve [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s plan [options] <range>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s merge <workspace>... --out <workspace>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s diff [options] <workspace> <workspace>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s watch [options] <target>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s doctor [--json]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s history [options] [target | show <run-id>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s secrets list | set <name> [--tools LIST] | remove <name>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s storage list | push <workspace>... | pull <workspace> [dir]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s passive [--sources LIST] [--json | -o FILE] <domain>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s archive [options] <workspace>... | unarchive [options] <archive>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s clean [--dry-run] [options] | clean pin | unpin <workspace>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s config validate [--json] [--strict]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s workflow test [--keep] <test.yaml>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s verify --baseline FILE [options] <target> | --workspace DIR\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s stats --usage [--json]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s version [--json]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nFlags:\n")
 		pflag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nOutput Directory Priority:\n")
@@ -664,11 +1099,26 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  Normal (default): Only raw tool output\n")
 		fmt.Fprintf(os.Stderr, "  -v, --verbose:    Both logs and raw tool output\n")
 		fmt.Fprintf(os.Stderr, "  -d, --debug:      Only logs, no raw tool output\n")
+		fmt.Fprintf(os.Stderr, "  --plain:          Only key=value status records, one per line (for scripts)\n")
 		fmt.Fprintf(os.Stderr, "\nBasic Examples:\n")
 		fmt.Fprintf(os.Stderr, "  %s 10.10.10.87                        # Scan HTB machine\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s 192.168.1.1 -o /tmp/scan1          # Custom output directory\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s example.com -o Desktop/results     # Relative output path\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -v google.com                      # Verbose output\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s 10.0.0.0/24 --exclude 10.0.0.1     # Never touch excluded hosts\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s 10.0.0.0/24 --scope acme.yaml      # Refuse and audit hosts outside the scope file\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s 10.10.1.5 --label dmz,critical     # Label the target's findings\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --pprof 10.10.10.87                # Profile at http://localhost:6060/debug/pprof/\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --resume-queue                     # Continue the latest interrupted run\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s 10.10.10.87 --overwrite            # Replace the target's existing workspace\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s 10.10.10.0/24 --discover           # Ping sweep, then scan each live host\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -iL targets.txt                    # Scan every host in a target list\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -iL hosts.txt --concurrent-targets 4 --rate-limit 2000  # Share 2000 pps across 4 hosts\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --pacing sneaky 10.10.10.5   # Slow every tool down (nmap -T1, low naabu/httpx rates)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --plain 10.10.10.5 | grep '^event=step_failed'  # Script around a scan\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --dry-run 10.10.10.5               # Print every command the scan would run\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -iL acme.txt --session-name acme-external-q3  # Name the engagement's workspaces and reports\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -p stealth 10.10.10.5              # Run the workflows of a profile (configs/profiles.yaml)\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nConfiguration Examples:\n")
 		fmt.Fprintf(os.Stderr, "  %s --set-default-output /opt/scans    # Set permanent default\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --show-config                      # Show current settings\n", os.Args[0])
@@ -676,6 +1126,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\nRegistry Commands:\n")
 		fmt.Fprintf(os.Stderr, "  %s registry list                      # List available tools\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s registry validate                  # Validate configurations\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nViewing Results:\n")
+		fmt.Fprintf(os.Stderr, "  %s view raw/nmap_output.txt           # Page a huge raw output file\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s view -search open raw/naabu.txt    # Print matching lines\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s search 'port:445 AND state:open' -w <workspace>  # Query findings\n", os.Args[0])
 		os.Exit(0)
 	}
 	
@@ -691,8 +1145,50 @@ func main() {
 		return
 	}
 	
+	// Without a target, a terminal gets the interactive launcher; scripts get an error
+	var launchWorkflows []string
+	var launchParameters map[string]map[string]string
+	if len(args) < 1 && *resumeQueue == "" && *inputList == "" && !*noTUI && !*plain && isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		workflows, err := discoverAllWorkflows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to discover workflows: %v\n", err)
+			os.Exit(1)
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load configuration: %v\n", err)
+			os.Exit(1)
+		}
+		choice, err := runLauncher(os.Stdin, os.Stdout, workflows, workspaceBaseDir(cfg, *outputDir))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		args, launchWorkflows, launchParameters = []string{choice.Target}, choice.Workflows, choice.Parameters
+		if choice.RateLimit > 0 {
+			*rateLimit = choice.RateLimit
+		}
+	}
+	
+	if err := executor.ValidatePacing(*pacing); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --pacing: %v\n", err)
+		os.Exit(1)
+	}
+	if *profile != "" {
+		if err := checkProfile(*profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --profile: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *sessionName != "" {
+		if err := session.ValidateSessionName(*sessionName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --session-name: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	
 	// Require target argument
-	if len(args) < 1 {
+	if len(args) < 1 && *resumeQueue == "" && *inputList == "" {
 		fmt.Fprintf(os.Stderr, "Error: target argument is required\n")
 		fmt.Fprintf(os.Stderr, "Usage: %s [FLAGS] <target>\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Use --help for more information\n")
@@ -704,6 +1200,11 @@ func main() {
 	if *debug && *verbose {
 		fmt.Fprintf(os.Stderr, "Error: cannot use both --debug and --verbose flags together\n")
 		os.Exit(1)
+	} else if *plain && (*debug || *verbose) {
+		fmt.Fprintf(os.Stderr, "Error: --plain cannot be combined with --debug or --verbose\n")
+		os.Exit(1)
+	} else if *plain {
+		outputMode = output.OutputModePlain
 	} else if *debug {
 		outputMode = output.OutputModeDebug
 	} else if *verbose {
@@ -713,10 +1214,22 @@ func main() {
 	}
 	
 	// Set global output controller before running CLI
-	globalOutputController = output.NewOutputController(outputMode)
+	setGlobalOutputController(output.NewOutputController(outputMode))
+	
+	// Continue an interrupted run in its existing workspace instead of starting a new one
+	if *resumeQueue != "" {
+		workspace := *resumeQueue
+		if workspace == latestWorkspace && len(args) > 0 {
+			workspace = args[0] // "--resume-queue <workspace>" leaves the workspace as an argument
+		}
+		if err := runResumeQueue(workspace, outputMode, userConfig.GetEffectiveOutputDirectory(*outputDir, ""), *dropPrivileges); err != nil {
+			fmt.Fprintf(os.Stderr, "Resume failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	
 	// Determine effective output directory
-	target := args[0]
 	effectiveOutputDir := userConfig.GetEffectiveOutputDirectory(*outputDir, "")
 	
 	// Validate and create output directory
@@ -727,16 +1240,146 @@ func main() {
 			os.Exit(1)
 		}
 		
-		if err := os.MkdirAll(absOutputDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: cannot create output directory %s: %v\n", absOutputDir, err)
-			os.Exit(1)
+		// A dry run creates nothing
+		if !*dryRun {
+			if err := os.MkdirAll(absOutputDir, 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: cannot create output directory %s: %v\n", absOutputDir, err)
+				os.Exit(1)
+			}
 		}
 		
 		effectiveOutputDir = absOutputDir
 	}
 	
+	// Build host exclusion list
+	exclusions := scope.NewExclusionList()
+	if err := exclusions.AddList(*exclude); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *excludeFile != "" {
+		if err := exclusions.LoadFile(*excludeFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	
+	// Refuse every host outside the engagement's scope file
+	engagement, err := loadEngagement(*scopeFile, exclusions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	
+	// Expand a target list file into one scan per host
+	var targets []string
+	if *inputList != "" {
+		if targets, err = buildTargetList(*inputList, args, exclusions, engagement, *sessionName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		targets = []string{args[0]}
+		if err := refuseTarget(engagement, args[0], *sessionName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: target refused: %v\n", err)
+			os.Exit(1)
+		}
+		if err := exclusions.CheckResolved(args[0], nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: target refused: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	
+	listOptions := targetListOptions{
+		OutputMode:        outputMode,
+		OutputDir:         effectiveOutputDir,
+		Exclusions:        exclusions,
+		Labels:            scope.ParseLabelList(*labels),
+		DropPrivileges:    *dropPrivileges,
+		ConcurrentTargets: *concurrentTargets,
+		RateLimit:         *rateLimit,
+		Pacing:            *pacing,
+		Workflows:         launchWorkflows,
+		Parameters:        launchParameters,
+		SessionName:       *sessionName,
+		Profile:           *profile,
+		Engagement:        engagement,
+	}
+	
+	// Show what the scan would run instead of running it
+	if *dryRun {
+		if err := runDryRun(targets, listOptions); err != nil {
+			fmt.Fprintf(os.Stderr, "Dry run failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
+	// Start the opt-in profiling listener for diagnosing CPU and memory usage
+	if *pprofAddr != "" {
+		profiler, err := profiling.Start(*pprofAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer profiler.Stop()
+		fmt.Fprintf(os.Stderr, "pprof listening on %s\n", profiler.URL())
+	}
+	
+	// Without --resume/--overwrite/--new, an existing workspace for the target prompts on a terminal
+	conflictPolicy, err := workspaceConflictPolicy(*resumeWorkspace, *overwriteWorkspace, *newWorkspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	
+	listOptions.ConflictPolicy = conflictPolicy
+	if len(targets) > 1 {
+		if err := runTargetList(targets, listOptions); err != nil {
+			fmt.Fprintf(os.Stderr, "CLI execution failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	
+	// Sweep a CIDR target for live hosts first and scan each one, when enabled
+	if _, _, cidrErr := net.ParseCIDR(targets[0]); cidrErr == nil && (*discover || cfg.Tools.HostDiscovery.Enabled) {
+		if err := runHostDiscovery(targets[0], cfg, listOptions); err != nil {
+			fmt.Fprintf(os.Stderr, "CLI execution failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
+	baseDir := effectiveOutputDir
+	if baseDir == "" {
+		baseDir = cfg.Output.WorkspaceBase
+	}
+	resumeDir, err := prepareTargetWorkspace(baseDir, *sessionName, targets[0], conflictPolicy, isTerminal(os.Stdin))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if resumeDir != "" {
+		if err := runResumeQueue(resumeDir, outputMode, effectiveOutputDir, *dropPrivileges); err != nil {
+			fmt.Fprintf(os.Stderr, "Resume failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
 	// Run CLI with target, output mode, and output directory
-	if err := runCLI(target, outputMode, effectiveOutputDir); err != nil {
+	var hooks *scanHooks
+	if *rateLimit > 0 || *pacing != "" || len(launchWorkflows) > 0 || len(launchParameters) > 0 || *sessionName != "" || *profile != "" || engagement != nil {
+		hooks = &scanHooks{RateLimit: *rateLimit, Pacing: *pacing, Workflows: launchWorkflows, Parameters: launchParameters, SessionName: *sessionName, Profile: *profile, Engagement: engagement}
+	}
+	if err := runCLI(targets[0], outputMode, effectiveOutputDir, exclusions, scope.ParseLabelList(*labels), *dropPrivileges, hooks); err != nil {
 		fmt.Fprintf(os.Stderr, "CLI execution failed: %v\n", err)
 		os.Exit(1)
 	}
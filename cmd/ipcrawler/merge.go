@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// runMergeCommand combines several workspaces of one engagement into a consolidated workspace
+func runMergeCommand(args []string) error {
+	fs := pflag.NewFlagSet("merge", pflag.ContinueOnError)
+	outDir := fs.String("out", "", "Consolidated workspace to create (must not exist or be empty)")
+	fs.Usage = printMergeUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		printMergeUsage()
+		return fmt.Errorf("at least two workspaces are required")
+	}
+	if *outDir == "" {
+		printMergeUsage()
+		return fmt.Errorf("--out is required")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	redactor, err := reportRedactor(cfg)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]string, 0, fs.NArg())
+	seen := make(map[string]bool)
+	for _, arg := range fs.Args() {
+		workspaceDir, err := resolveWorkspace(arg)
+		if err != nil {
+			return err
+		}
+		if seen[workspaceDir] {
+			return fmt.Errorf("%s is given more than once", workspaceDir)
+		}
+		seen[workspaceDir] = true
+		sources = append(sources, workspaceDir)
+	}
+
+	target, err := filepath.Abs(*outDir)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %v", err)
+	}
+	if seen[target] {
+		return fmt.Errorf("--out cannot be one of the merged workspaces")
+	}
+	if entries, err := os.ReadDir(target); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", target)
+	}
+
+	manifest, err := mergeWorkspaces(cfg, sources, target)
+	if err != nil {
+		return err
+	}
+	for _, run := range manifest.MergedFrom {
+		fmt.Printf("%s: %s, %d files\n", run.Workspace, run.Target, len(run.Files))
+	}
+	fmt.Printf("Merged %d workspaces into %s\n", len(manifest.MergedFrom), target)
+
+	// Regenerate the run report and configured reports over the combined results
+	logger := log.NewWithOptions(os.Stderr, log.Options{Prefix: "IPCrawler merge"})
+	generator := mergedRunReport(cfg, manifest, sources)
+	generator.SetRedactor(redactor)
+	writeRunReport(cfg, generator, target, false, logger)
+	generateRunReports(cfg, target, redactor, logger)
+	fmt.Printf("Report the merged results with: ipcrawler report %s\n", target)
+	return nil
+}
+
+func printMergeUsage() {
+	fmt.Println("Usage: ipcrawler merge <workspace> <workspace>... --out <workspace>")
+	fmt.Println()
+	fmt.Println("Combines the workspaces of one engagement (e.g. shards run on several")
+	fmt.Println("machines, or a scan and its follow-ups) into one workspace for unified")
+	fmt.Println("reporting. Scan outputs are copied into scans/, each workspace's raw output")
+	fmt.Println("into raw/<workspace name>/, and manifest.json lists every merged run with")
+	fmt.Println("the files it contributed. The source workspaces are not modified.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --out DIR   Consolidated workspace to create (must not exist or be empty)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler merge results/shard-*/* --out engagement/")
+	fmt.Println("  ipcrawler merge ws-external ws-vpn --out ws-combined && ipcrawler search 'port:443' ws-combined")
+}
+
+// mergeWorkspaces copies the sources' scan outputs and raw logs into target and writes its manifest
+func mergeWorkspaces(cfg *config.Config, sources []string, target string) (*session.RunManifest, error) {
+	dirPerm, filePerm := cfg.Output.Permissions.DirPerm(), cfg.Output.Permissions.FilePerm()
+	for _, dir := range []string{"scans", "raw", "reports", "logs"} {
+		if err := os.MkdirAll(filepath.Join(target, dir), dirPerm); err != nil {
+			return nil, fmt.Errorf("failed to create workspace: %v", err)
+		}
+	}
+
+	merged := &session.RunManifest{
+		ScanID:    session.NewScanID(),
+		Version:   ipcrawlerVersion,
+		Workspace: target,
+		Status:    session.RunStatusCompleted,
+	}
+	var targets, incomplete []string
+	rawNames := make(map[string]bool)
+	scanIDs := make(map[string]string)
+	for _, source := range sources {
+		manifest, err := session.LoadManifest(source)
+		if err != nil {
+			// Older workspaces have no manifest; the directory name stands in for the target
+			manifest = &session.RunManifest{Target: filepath.Base(source), Status: session.RunStatusCompleted}
+		}
+		run := session.MergedRun{
+			ScanID:      manifest.ScanID,
+			Target:      manifest.Target,
+			Workspace:   source,
+			Status:      manifest.Status,
+			StartedAt:   manifest.StartedAt,
+			FinishedAt:  manifest.FinishedAt,
+			Environment: manifest.Environment,
+		}
+
+		if manifest.ScanID != "" {
+			if scanIDs[manifest.ScanID] != "" {
+				return nil, fmt.Errorf("%s and %s are the same run (scan %s)", scanIDs[manifest.ScanID], source, manifest.ScanID)
+			}
+			scanIDs[manifest.ScanID] = source
+		}
+
+		// Scan outputs keep their names so extractors still recognize them; a clash with
+		// another run's file gets the run's short scan ID after the tool name
+		tag := session.ShortScanID(manifest.ScanID)
+		if tag == "" {
+			tag = filepath.Base(source)
+		}
+		files, err := copyTree(filepath.Join(source, "scans"), target, "scans", func(rel string) string {
+			dir, name := filepath.Split(rel)
+			if tool, rest, found := strings.Cut(name, "_"); found {
+				return filepath.Join(dir, tool+"_"+tag+"_"+rest)
+			}
+			return filepath.Join(dir, tag+"_"+name)
+		}, dirPerm, filePerm)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", source, err)
+		}
+		run.Files = append(run.Files, files...)
+
+		// Raw logs are per run, so each keeps its own directory
+		rawName := filepath.Base(source)
+		for suffix := 2; rawNames[rawName]; suffix++ {
+			rawName = fmt.Sprintf("%s-%d", filepath.Base(source), suffix)
+		}
+		rawNames[rawName] = true
+		files, err = copyTree(filepath.Join(source, "raw"), target, filepath.Join("raw", rawName), nil, dirPerm, filePerm)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", source, err)
+		}
+		run.Files = append(run.Files, files...)
+
+		merged.MergedFrom = append(merged.MergedFrom, run)
+		targets = appendUnique(targets, manifest.Target)
+		merged.Workflows = appendUnique(merged.Workflows, manifest.Workflows...)
+		merged.Labels = appendUnique(merged.Labels, manifest.Labels...)
+		merged.Exclusions = appendUnique(merged.Exclusions, manifest.Exclusions...)
+		if !manifest.StartedAt.IsZero() && (merged.StartedAt.IsZero() || manifest.StartedAt.Before(merged.StartedAt)) {
+			merged.StartedAt = manifest.StartedAt
+		}
+		if manifest.FinishedAt != nil && (merged.FinishedAt == nil || manifest.FinishedAt.After(*merged.FinishedAt)) {
+			merged.FinishedAt = manifest.FinishedAt
+		}
+		if manifest.Status != session.RunStatusCompleted {
+			incomplete = append(incomplete, fmt.Sprintf("%s (%s)", filepath.Base(source), manifest.Status))
+		}
+	}
+
+	merged.Target = strings.Join(targets, ", ")
+	sort.Strings(merged.Workflows)
+	if len(incomplete) > 0 {
+		merged.Status = session.RunStatusFailed
+		merged.Error = "merged runs that did not complete: " + strings.Join(incomplete, ", ")
+	}
+	if err := session.WriteManifest(target, merged, filePerm); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// copyTree copies the files under srcDir to destDir/prefix and returns their workspace-relative
+// paths. rename, if set, gives the name to use when a file's path is already taken. A missing
+// srcDir copies nothing
+func copyTree(srcDir, destDir, prefix string, rename func(rel string) string, dirPerm, filePerm os.FileMode) ([]string, error) {
+	var copied []string
+	err := filepath.WalkDir(srcDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == srcDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(destDir, prefix, rel)
+		if _, err := os.Stat(dest); err == nil {
+			if rename == nil {
+				return fmt.Errorf("%s already exists in the merged workspace", filepath.Join(prefix, rel))
+			}
+			dest = filepath.Join(destDir, prefix, rename(rel))
+		}
+		if err := copyFile(path, dest, dirPerm, filePerm); err != nil {
+			return err
+		}
+		destRel, _ := filepath.Rel(destDir, dest)
+		copied = append(copied, destRel)
+		return nil
+	})
+	return copied, err
+}
+
+// copyFile copies one file, keeping its modification time; it refuses to overwrite
+func copyFile(src, dest string, dirPerm, filePerm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), dirPerm); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if info, err := in.Stat(); err == nil {
+		os.Chtimes(dest, info.ModTime(), info.ModTime())
+	}
+	return nil
+}
+
+// mergedRunReport combines the sources' run reports; workflows keep their own targets
+func mergedRunReport(cfg *config.Config, manifest *session.RunManifest, sources []string) *output.ReportGenerator {
+	generator := output.NewReportGenerator(manifest.ScanID, manifest.Target, manifest.Workspace, manifest.StartedAt)
+	generator.SetEncoding(cfg.Output.ResultsEncoding)
+	var duration time.Duration
+	for _, source := range sources {
+		report, err := output.LoadRunReport(source)
+		if err != nil {
+			continue
+		}
+		for _, workflow := range report.Workflows {
+			generator.RecordWorkflow(workflow)
+		}
+		for _, warning := range report.Warnings {
+			generator.RecordWarning(warning)
+		}
+		duration += time.Duration(report.DurationSeconds * float64(time.Second))
+	}
+	finishedAt := manifest.StartedAt
+	if manifest.FinishedAt != nil {
+		finishedAt = *manifest.FinishedAt
+	}
+	generator.Finish(manifest.Status, manifest.Error, finishedAt, duration)
+	return generator
+}
+
+// appendUnique appends the values not already in list, skipping empty ones
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := value == ""
+		for _, existing := range list {
+			if existing == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
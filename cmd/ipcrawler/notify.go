@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/integrations/notify"
+)
+
+// buildNotifier starts the webhooks configured under integrations.notifications; nil when none are
+func buildNotifier(cfg *config.Config) (*notify.Notifier, error) {
+	settings := cfg.Integrations.Notifications
+	if len(settings.Webhooks) == 0 {
+		return nil, nil
+	}
+	webhooks := make([]notify.Webhook, 0, len(settings.Webhooks))
+	for _, webhook := range settings.Webhooks {
+		url := webhook.URL
+		if value := envOrEmpty(webhook.URLEnv); value != "" {
+			url = value
+		}
+		webhooks = append(webhooks, notify.Webhook{
+			Name:    webhook.Name,
+			URL:     url,
+			Format:  webhook.Format,
+			Events:  webhook.Events,
+			Timeout: time.Duration(webhook.TimeoutSeconds) * time.Second,
+		})
+	}
+	return notify.New(webhooks)
+}
+
+// subscribeNotifications sends finished workflows and new findings of a run to the webhooks
+func subscribeNotifications(orchestrator *executor.WorkflowOrchestrator, notifier *notify.Notifier, scanID string) {
+	orchestrator.Events().Subscribe(func(event executor.Event) {
+		notification := notify.Event{ScanID: scanID, Target: event.Target, Workflow: event.Workflow, Message: event.Message}
+		switch event.Type {
+		case executor.EventWorkflowCompleted:
+			notification.Type = notify.EventWorkflowCompleted
+		case executor.EventWorkflowFailed:
+			notification.Type = notify.EventWorkflowFailed
+		case executor.EventFindingsDiscovered:
+			notification.Type = notify.EventNewFindings
+			notification.Message = ""
+			notification.Findings = event.Findings
+		}
+		notifier.Notify(notification)
+	}, executor.EventWorkflowCompleted, executor.EventWorkflowFailed, executor.EventFindingsDiscovered)
+}
+
+// closeNotifier delivers the notifications still queued and logs the ones that failed
+func closeNotifier(notifier *notify.Notifier, logger *log.Logger) {
+	if err := notifier.Close(); err != nil {
+		logger.Warn("Failed to deliver notifications", "error", err)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/passive"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// defaultPassiveCacheTTL is how long source answers are reused when passive.cache_ttl is unset
+const defaultPassiveCacheTTL = 24 * time.Hour
+
+// runPassiveCommand queries the passive sources about a domain. Workflows run it as the
+// built-in "passive" tool, which writes the results as JSON lines with --output
+func runPassiveCommand(args []string) error {
+	fs := pflag.NewFlagSet("passive", pflag.ContinueOnError)
+	var (
+		sourceNames = fs.StringSlice("sources", nil, "Sources to query, or \"all\" (default: passive.sources)")
+		outputFile  = fs.StringP("output", "o", "", "Write the results to a file as JSON lines")
+		jsonOutput  = fs.Bool("json", false, "Print the results as JSON lines")
+		noCache     = fs.Bool("no-cache", false, "Query every source even when a cached answer exists")
+		list        = fs.Bool("list", false, "List the available sources")
+	)
+	fs.Usage = printPassiveUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *list {
+		printPassiveSources()
+		return nil
+	}
+	if fs.NArg() != 1 {
+		printPassiveUsage()
+		return fmt.Errorf("a domain is required")
+	}
+	domain := fs.Arg(0)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	logger := log.NewWithOptions(os.Stderr, log.Options{Prefix: "IPCrawler passive"})
+	options, err := passiveOptions(cfg, *sourceNames, !*noCache, logger)
+	if err != nil {
+		return err
+	}
+	options.OnSourceDone = func(source string, results int, err error) {
+		if err != nil {
+			logger.Warn("Source failed", "source", source, "results", results, "error", err)
+			return
+		}
+		logger.Info("Source done", "source", source, "results", results)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	report, err := passive.Run(ctx, domain, options)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *outputFile != "":
+		if err := writePassiveResults(*outputFile, report.Results, cfg.Output.Permissions.FilePerm()); err != nil {
+			return err
+		}
+	case *jsonOutput:
+		if err := passive.WriteResults(os.Stdout, report.Results); err != nil {
+			return err
+		}
+	default:
+		printPassiveReport(report)
+	}
+
+	if len(report.Errors) == len(report.Counts) {
+		return fmt.Errorf("every source failed")
+	}
+	return nil
+}
+
+// passiveOptions builds the options of a run from configs/passive.yaml and the secrets store
+func passiveOptions(cfg *config.Config, sourceNames []string, useCache bool, logger *log.Logger) (passive.Options, error) {
+	settings := cfg.Passive
+	options := passive.Options{
+		Sources:           sourceNames,
+		RequestsPerMinute: settings.RateLimits,
+		UserAgent:         settings.UserAgent,
+	}
+	if len(options.Sources) == 0 {
+		options.Sources = settings.Sources
+	}
+	if settings.TimeoutSeconds > 0 {
+		options.HTTPClient = &http.Client{Timeout: time.Duration(settings.TimeoutSeconds) * time.Second}
+	}
+
+	if useCache {
+		ttl := defaultPassiveCacheTTL
+		if settings.CacheTTL != "" {
+			parsed, err := time.ParseDuration(settings.CacheTTL)
+			if err != nil {
+				return options, fmt.Errorf("invalid passive.cache_ttl %q: %v", settings.CacheTTL, err)
+			}
+			ttl = parsed
+		}
+		if dir, err := userconfig.Dir(); err == nil && ttl > 0 {
+			options.Cache = passive.NewCache(filepath.Join(dir, "cache", "passive"), ttl)
+		}
+	}
+
+	store, err := loadSecrets(logger)
+	if err != nil {
+		return options, fmt.Errorf("failed to load secrets: %v", err)
+	}
+	options.Secret = func(name string) (string, error) {
+		return store.Lookup(name, "passive")
+	}
+	return options, nil
+}
+
+// writePassiveResults writes the results file atomically, so a workflow never reads half of it
+func writePassiveResults(path string, results []passive.Result, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to write results: %v", err)
+	}
+	err = passive.WriteResults(file, results)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write results: %v", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// printPassiveReport prints the subdomains and ports found, and how many URLs were archived
+func printPassiveReport(report *passive.Report) {
+	urls := 0
+	var subdomains, services []passive.Result
+	for _, result := range report.Results {
+		switch result.Type {
+		case passive.ResultSubdomain:
+			subdomains = append(subdomains, result)
+		case passive.ResultService:
+			services = append(services, result)
+		case passive.ResultURL:
+			urls++
+		}
+	}
+	if len(subdomains) > 0 {
+		fmt.Printf("  %-48s  %-39s  %s\n", "SUBDOMAIN", "ADDRESS", "SOURCES")
+		for _, result := range subdomains {
+			fmt.Printf("  %-48s  %-39s  %s\n", result.Host, dashIfEmpty(result.Address), result.Source)
+		}
+	}
+	if len(services) > 0 {
+		fmt.Println()
+		for _, result := range services {
+			fmt.Printf("  %s:%d  %s  (%s)\n", result.Host, result.Port, result.Service, result.Source)
+		}
+	}
+	fmt.Printf("\n%d subdomains, %d ports, %d archived URLs (--json lists them)\n", len(subdomains), len(services), urls)
+}
+
+func printPassiveSources() {
+	fmt.Printf("  %-14s  %-8s  %-7s  %s\n", "SOURCE", "DEFAULT", "REQ/MIN", "DESCRIPTION")
+	for _, info := range passive.Sources() {
+		isDefault := "no"
+		if info.Default {
+			isDefault = "yes"
+		}
+		description := info.Description
+		if info.Secret != "" {
+			description += " (secret " + info.Secret + ")"
+		}
+		fmt.Printf("  %-14s  %-8s  %-7d  %s\n", info.Name, isDefault, info.RequestsPerMinute, description)
+	}
+}
+
+func printPassiveUsage() {
+	fmt.Println("Usage: ipcrawler passive [options] <domain>")
+	fmt.Println()
+	fmt.Println("Queries public data sources about a domain without touching it: certificate")
+	fmt.Println("transparency, archived URLs, passive DNS and Shodan. Every source is rate limited")
+	fmt.Println("and its answers are cached (configs/passive.yaml). Workflows run it as the")
+	fmt.Println("\"passive\" tool, whose subdomains join {{discovered_subdomains}}.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --sources LIST    Sources to query, or \"all\" (default: passive.sources, else every free source)")
+	fmt.Println("  -o, --output FILE     Write the results to a file as JSON lines")
+	fmt.Println("      --json            Print the results as JSON lines")
+	fmt.Println("      --no-cache        Query every source even when a cached answer exists")
+	fmt.Println("      --list            List the available sources")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler passive example.com")
+	fmt.Println("  ipcrawler passive --sources crtsh,shodan --json example.com")
+	fmt.Println("  ipcrawler secrets set shodan_api_key --tools passive   # enables the shodan source")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// With --plain, a run's status is a stream of single-line key=value records on stdout, each
+// starting with event=, for scripts to read with grep and awk; errors still go to stderr
+
+// plainMutex keeps records of concurrent runs (-iL) from interleaving
+var plainMutex sync.Mutex
+
+// printRecord writes one record, redacted when a redactor is given
+func printRecord(redactor *output.Redactor, keyvals ...string) {
+	record := redactor.Redact(output.FormatRecord(keyvals...))
+	plainMutex.Lock()
+	defer plainMutex.Unlock()
+	fmt.Fprintln(os.Stdout, record)
+}
+
+// subscribePlainStatus prints the orchestrator's lifecycle events as records
+func subscribePlainStatus(orchestrator *executor.WorkflowOrchestrator, redactor *output.Redactor) {
+	orchestrator.Events().Subscribe(func(event executor.Event) {
+		printRecord(redactor, plainEventFields(event)...)
+	})
+}
+
+// plainEventFields lists the fields of an event that apply to its type
+func plainEventFields(event executor.Event) []string {
+	fields := []string{"event", string(event.Type), "time", event.Time.Format(time.RFC3339), "target", event.Target, "workflow", event.Workflow}
+	optional := func(key, value string) {
+		if value != "" {
+			fields = append(fields, key, value)
+		}
+	}
+	switch event.Type {
+	case executor.EventStepStarted, executor.EventStepCompleted, executor.EventStepSkipped, executor.EventStepFailed:
+		fields = append(fields, "step", event.Step, "tool", event.Tool, "step_index", strconv.Itoa(event.StepIndex), "step_count", strconv.Itoa(event.StepCount))
+	case executor.EventWorkflowProgress:
+		fields = append(fields, "percent", strconv.Itoa(event.Percent), "remaining_seconds", strconv.Itoa(event.Remaining))
+	case executor.EventFindingsDiscovered:
+		fields = append(fields, "count", strconv.Itoa(len(event.Findings)))
+	}
+	optional("triggered_by", event.TriggeredBy)
+	if event.Type == executor.EventStepSkipped {
+		optional("message", event.Message)
+	}
+	optional("error", event.Error)
+	return fields
+}
+
+// printPlainFinished reports the end of a run and its warnings as records
+func printPlainFinished(redactor *output.Redactor, runReport output.RunReport) {
+	for _, warning := range runReport.Warnings {
+		printRecord(redactor, "event", "warning", "target", runReport.Target, "kind", warning.Kind, "tool", warning.Tool, "mode", warning.Mode, "message", warning.Message)
+	}
+	fields := []string{"event", "scan_finished", "target", runReport.Target, "scan_id", runReport.ScanID, "status", runReport.Status,
+		"duration_seconds", strconv.FormatFloat(runReport.DurationSeconds, 'f', 1, 64), "warnings", strconv.Itoa(len(runReport.Warnings)), "workspace", runReport.Workspace}
+	if runReport.Error != "" {
+		fields = append(fields, "error", runReport.Error)
+	}
+	printRecord(redactor, fields...)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/usage"
+	"github.com/spf13/pflag"
+)
+
+// scanPlan is the output of `ipcrawler plan`, also written to plan.json for job runners
+type scanPlan struct {
+	Hosts        uint64       `json:"hosts"`
+	Workflows    []string     `json:"workflows"`
+	Triggered    []string     `json:"triggered_workflows,omitempty"` // Run only for matching services, not estimated
+	Estimate     planEstimate `json:"estimate"`
+	Shards       []planShard  `json:"shards"`
+	MergeCommand string       `json:"merge_command,omitempty"`
+}
+
+// planEstimate is the expected work per host. Expected durations come from the local usage
+// statistics when recorded; the worst case assumes every tool run reaches its timeout
+type planEstimate struct {
+	ToolRunsPerHost         int     `json:"tool_runs_per_host"`
+	RunsWithHistory         int     `json:"runs_with_history"`
+	ExpectedSecondsPerHost  float64 `json:"expected_seconds_per_host,omitempty"`
+	WorstCaseSecondsPerHost float64 `json:"worst_case_seconds_per_host"`
+	ConcurrentTargets       int     `json:"concurrent_targets"`
+}
+
+// planShard is one slice of the range and how to scan it
+type planShard struct {
+	Index            int      `json:"index"`
+	Hosts            uint64   `json:"hosts"`
+	Targets          []string `json:"targets"`
+	TargetFile       string   `json:"target_file,omitempty"`
+	ResultsDir       string   `json:"results_dir,omitempty"`
+	Command          string   `json:"command,omitempty"`
+	ExpectedSeconds  float64  `json:"expected_seconds,omitempty"`
+	WorstCaseSeconds float64  `json:"worst_case_seconds"`
+}
+
+// runPlanCommand implements `ipcrawler plan`
+func runPlanCommand(args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+
+	fs := pflag.NewFlagSet("plan", pflag.ContinueOnError)
+	var (
+		inputList         = fs.String("input-list", "", "File of targets, IPs and CIDRs (also -iL)")
+		exclude           = fs.String("exclude", "", "Comma-separated hosts, IPs or CIDRs to leave out")
+		excludeFile       = fs.String("exclude-file", "", "File of hosts, IPs or CIDRs to leave out")
+		shardCount        = fs.Int("shards", 1, "Number of slices to split the range into")
+		outDir            = fs.String("out", "", "Write shard target lists, plan.json and per-shard commands to this directory")
+		resultsDir        = fs.String("results", "ipcrawler_results", "Base results directory used in the emitted commands")
+		concurrentTargets = fs.Int("concurrent-targets", 0, "Targets each shard scans at once (default target_scheduling.max_concurrent_targets)")
+		asJSON            = fs.Bool("json", false, "Print the plan as JSON")
+	)
+	fs.Usage = printPlanUsage
+	if err := fs.Parse(expandShortFlags(args)); err != nil {
+		return err
+	}
+
+	entries := fs.Args()
+	if *inputList != "" {
+		listed, err := scope.ReadTargetEntries(*inputList)
+		if err != nil {
+			return err
+		}
+		entries = append(listed, entries...)
+	}
+	if len(entries) == 0 {
+		printPlanUsage()
+		return fmt.Errorf("at least one target range is required")
+	}
+	if *shardCount < 1 {
+		return fmt.Errorf("--shards must be at least 1")
+	}
+
+	ranges, hostnames, err := scope.ParseRanges(entries)
+	if err != nil {
+		return err
+	}
+	exclusions := scope.NewExclusionList()
+	if err := exclusions.AddList(*exclude); err != nil {
+		return err
+	}
+	if *excludeFile != "" {
+		if err := exclusions.LoadFile(*excludeFile); err != nil {
+			return err
+		}
+	}
+	excludedRanges, excludedHostnames, err := scope.ParseRanges(exclusions.Entries())
+	if err != nil {
+		return err
+	}
+	ranges = scope.SubtractRanges(ranges, excludedRanges)
+	hostnames = withoutHostnames(hostnames, excludedHostnames)
+
+	concurrent := cfg.Tools.TargetScheduling.MaxConcurrentTargets
+	if *concurrentTargets > 0 {
+		concurrent = *concurrentTargets
+	}
+	if concurrent < 1 {
+		concurrent = 1
+	}
+	plan, err := estimatePlan(cfg, concurrent)
+	if err != nil {
+		return err
+	}
+
+	for i, shard := range scope.SplitShards(ranges, hostnames, *shardCount) {
+		entry := planShard{Index: i + 1, Hosts: shard.Hosts(), Targets: shard.Targets()}
+		plan.Hosts += entry.Hosts
+		entry.ExpectedSeconds = shardSeconds(entry.Hosts, plan.Estimate.ExpectedSecondsPerHost, concurrent)
+		entry.WorstCaseSeconds = shardSeconds(entry.Hosts, plan.Estimate.WorstCaseSecondsPerHost, concurrent)
+		if *outDir != "" {
+			name := fmt.Sprintf("shard-%02d", entry.Index)
+			entry.TargetFile = filepath.Join(*outDir, name+".txt")
+			entry.ResultsDir = filepath.Join(*resultsDir, name)
+			entry.Command = shardCommand(entry, *exclude, *excludeFile, *concurrentTargets)
+		}
+		plan.Shards = append(plan.Shards, entry)
+	}
+	if plan.Hosts == 0 {
+		return fmt.Errorf("every target is in the exclusion list")
+	}
+	if *outDir != "" {
+		plan.MergeCommand = fmt.Sprintf("ipcrawler report --rollup-dir %s %s", *resultsDir, filepath.Join(*resultsDir, "shard-*", "*"))
+		if err := writePlan(*outDir, plan); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+	printPlan(plan, *outDir)
+	return nil
+}
+
+// estimatePlan counts the tool runs every host gets from the workflows that run up front
+func estimatePlan(cfg *config.Config, concurrent int) (*scanPlan, error) {
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover workflows: %v", err)
+	}
+
+	// Usage history is optional; without it only the worst case is known
+	var history *usage.Stats
+	if path, err := usagePath(); err == nil {
+		history, _ = usage.Load(path)
+	}
+
+	engine := executor.NewToolExecutionEngine(cfg, "", output.OutputModeNormal)
+	plan := &scanPlan{Estimate: planEstimate{ConcurrentTargets: concurrent}}
+	for _, workflow := range workflows {
+		if len(workflow.Triggers) > 0 {
+			plan.Triggered = append(plan.Triggered, workflow.Name)
+			continue
+		}
+		plan.Workflows = append(plan.Workflows, workflow.Name)
+		for _, step := range workflow.Steps {
+			for _, mode := range step.Modes {
+				plan.Estimate.ToolRunsPerHost++
+				plan.Estimate.WorstCaseSecondsPerHost += engine.ModeTimeout(step.Tool, mode).Seconds()
+				if history == nil {
+					continue
+				}
+				if entry := history.Tools[step.Tool+" "+mode]; entry != nil && entry.Uses > 0 {
+					plan.Estimate.RunsWithHistory++
+					plan.Estimate.ExpectedSecondsPerHost += entry.AverageSeconds()
+				}
+			}
+		}
+	}
+	sort.Strings(plan.Workflows)
+	sort.Strings(plan.Triggered)
+	return plan, nil
+}
+
+// shardSeconds is the wall time of a shard when its hosts are scanned concurrent at a time
+func shardSeconds(hosts uint64, secondsPerHost float64, concurrent int) float64 {
+	return math.Ceil(float64(hosts)/float64(concurrent)) * secondsPerHost
+}
+
+// shardCommand is the ipcrawler invocation that scans one shard into its own results directory
+func shardCommand(shard planShard, exclude, excludeFile string, concurrentTargets int) string {
+	command := fmt.Sprintf("ipcrawler -iL %s -o %s --new", shard.TargetFile, shard.ResultsDir)
+	if exclude != "" {
+		command += " --exclude " + exclude
+	}
+	if excludeFile != "" {
+		command += " --exclude-file " + excludeFile
+	}
+	if concurrentTargets > 0 {
+		command += fmt.Sprintf(" --concurrent-targets %d", concurrentTargets)
+	}
+	return command
+}
+
+// writePlan writes each shard's target list and plan.json
+func writePlan(dir string, plan *scanPlan) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create plan directory: %v", err)
+	}
+	for _, shard := range plan.Shards {
+		content := fmt.Sprintf("# Shard %d of %d: %d host(s)\n%s\n", shard.Index, len(plan.Shards), shard.Hosts, strings.Join(shard.Targets, "\n"))
+		if err := os.WriteFile(shard.TargetFile, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write shard target list: %v", err)
+		}
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "plan.json"), data, 0644)
+}
+
+// printPlan prints the estimate, one line per shard and the commands to run
+func printPlan(plan *scanPlan, outDir string) {
+	estimate := plan.Estimate
+	fmt.Printf("Plan: %d host(s) in %d shard(s), %d workflow(s), %d tool run(s) per host\n",
+		plan.Hosts, len(plan.Shards), len(plan.Workflows), estimate.ToolRunsPerHost)
+	if len(plan.Triggered) > 0 {
+		fmt.Printf("Not estimated (run only for matching services): %s\n", strings.Join(plan.Triggered, ", "))
+	}
+	if estimate.RunsWithHistory > 0 {
+		fmt.Printf("Per host: ~%s expected (usage history for %d/%d runs), at most %s (timeouts)\n",
+			secondsString(estimate.ExpectedSecondsPerHost), estimate.RunsWithHistory, estimate.ToolRunsPerHost, secondsString(estimate.WorstCaseSecondsPerHost))
+	} else {
+		fmt.Printf("Per host: at most %s (timeouts); enable output.usage_stats for expected durations\n",
+			secondsString(estimate.WorstCaseSecondsPerHost))
+	}
+	fmt.Printf("Each shard scans %d target(s) at a time\n\n", estimate.ConcurrentTargets)
+
+	fmt.Printf("%-6s  %9s  %10s  %10s  %s\n", "Shard", "Hosts", "Expected", "Worst case", "Targets")
+	oversized := false
+	for _, shard := range plan.Shards {
+		expected := "-"
+		if estimate.RunsWithHistory > 0 {
+			expected = secondsString(shard.ExpectedSeconds)
+		}
+		fmt.Printf("%-6d  %9d  %10s  %10s  %s\n", shard.Index, shard.Hosts, expected, secondsString(shard.WorstCaseSeconds), summarizeTargets(shard.Targets))
+		oversized = oversized || shard.Hosts > scope.MaxExpandedHosts
+	}
+	if oversized {
+		minimum := (plan.Hosts + scope.MaxExpandedHosts - 1) / scope.MaxExpandedHosts
+		fmt.Printf("\nWarning: -iL scans at most %d hosts per run; use --shards %d or more\n", scope.MaxExpandedHosts, minimum)
+	}
+
+	if outDir == "" {
+		fmt.Println("\nUse --out DIR to write the shard target lists and per-shard commands.")
+		return
+	}
+	fmt.Printf("\nWrote %s and %d target list(s). Run each shard (on any machine):\n", filepath.Join(outDir, "plan.json"), len(plan.Shards))
+	for _, shard := range plan.Shards {
+		fmt.Printf("  %s\n", shard.Command)
+	}
+	fmt.Printf("\nThen combine the shards' workspaces into one engagement roll-up:\n  %s\n", plan.MergeCommand)
+}
+
+// summarizeTargets shortens long shard target lists for the table
+func summarizeTargets(targets []string) string {
+	if len(targets) <= 3 {
+		return strings.Join(targets, ", ")
+	}
+	return fmt.Sprintf("%s ... (%d blocks)", strings.Join(targets[:2], ", "), len(targets))
+}
+
+// secondsString formats an estimate as a rounded duration, in days beyond two days
+func secondsString(seconds float64) string {
+	duration := time.Duration(seconds * float64(time.Second))
+	if duration >= 48*time.Hour {
+		return fmt.Sprintf("%.1fd", duration.Hours()/24)
+	}
+	return duration.Round(time.Second).String()
+}
+
+// withoutHostnames drops excluded hostnames, keeping order
+func withoutHostnames(hostnames, excluded []string) []string {
+	skip := make(map[string]bool, len(excluded))
+	for _, hostname := range excluded {
+		skip[hostname] = true
+	}
+	kept := hostnames[:0]
+	for _, hostname := range hostnames {
+		if !skip[hostname] {
+			kept = append(kept, hostname)
+		}
+	}
+	return kept
+}
+
+func printPlanUsage() {
+	fmt.Println("Usage: ipcrawler plan [options] <range>...")
+	fmt.Println()
+	fmt.Println("Estimates the work a large scan takes and splits its targets into shards of")
+	fmt.Println("nearly equal size, each a few contiguous CIDR blocks, to run on several")
+	fmt.Println("machines or one after another. Ranges may be larger than the /16 a single")
+	fmt.Println("-iL run accepts. Durations come from the local usage statistics when")
+	fmt.Println("output.usage_stats is enabled; the worst case assumes every tool times out.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -iL, --input-list FILE     Targets, IPs and CIDRs (one or more per line)")
+	fmt.Println("      --exclude LIST         Hosts, IPs or CIDRs to leave out (passed on to each shard)")
+	fmt.Println("      --exclude-file FILE    File of hosts, IPs or CIDRs to leave out")
+	fmt.Println("      --shards N             Number of shards (default 1)")
+	fmt.Println("      --out DIR              Write shard-NN.txt target lists and plan.json, and print")
+	fmt.Println("                             the command for each shard and the merge command")
+	fmt.Println("      --results DIR          Results directory in the commands (default ipcrawler_results)")
+	fmt.Println("      --concurrent-targets N Targets each shard scans at once")
+	fmt.Println("      --json                 Print the plan as JSON")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler plan 10.0.0.0/12 --shards 16")
+	fmt.Println("  ipcrawler plan -iL scope.txt --exclude 10.0.0.1 --shards 4 --out plan/")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+)
+
+// profileWorkflows returns the workflows a profile of configs/profiles.yaml selects by their
+// tags. A profile that selects nothing is an error, not an empty scan
+func profileWorkflows(cfg *config.Config, workflows map[string]*executor.Workflow, name string) (map[string]*executor.Workflow, error) {
+	profile, exists := cfg.Profiles[name]
+	if !exists {
+		if len(cfg.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile '%s': no profiles are defined (configs/profiles.yaml)", name)
+		}
+		return nil, fmt.Errorf("unknown profile '%s' (available: %s)", name, strings.Join(profileNames(cfg), ", "))
+	}
+	if strings.TrimSpace(profile.Tags) == "" {
+		return nil, fmt.Errorf("profile '%s' has no tags filter", name)
+	}
+	filter, err := executor.ParseTagFilter(profile.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("profile '%s': %v", name, err)
+	}
+
+	selected := make(map[string]*executor.Workflow)
+	for key, workflow := range workflows {
+		if filter.Matches(workflow.Tags) {
+			selected[key] = workflow
+		}
+	}
+	if len(selected) == 0 {
+		tags := executor.WorkflowTags(workflows)
+		if len(tags) == 0 {
+			tags = []string{"none"}
+		}
+		return nil, fmt.Errorf("profile '%s' (%s) selects no workflow (tags in use: %s)", name, filter, strings.Join(tags, ", "))
+	}
+	return selected, nil
+}
+
+// checkProfile fails before any scan starts when the profile is unknown, invalid or selects
+// no workflow
+func checkProfile(name string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("failed to discover workflows: %v", err)
+	}
+	_, err = profileWorkflows(cfg, workflows, name)
+	return err
+}
+
+// profileNames returns the defined profiles, sorted
+func profileNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printProfiles lists the profiles -p can choose and the workflows each one runs
+func printProfiles() {
+	cfg, err := config.LoadConfig()
+	if err != nil || len(cfg.Profiles) == 0 {
+		return
+	}
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return
+	}
+	fmt.Println("Profiles (-p):")
+	for _, name := range profileNames(cfg) {
+		profile := cfg.Profiles[name]
+		fmt.Printf("  %-12s %s\n", name, profile.Description)
+		selected, err := profileWorkflows(cfg, workflows, name)
+		if err != nil {
+			fmt.Printf("  %-12s ! %v\n", "", err)
+			continue
+		}
+		keys := make([]string, 0, len(selected))
+		for key := range selected {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Printf("  %-12s tags: %s -> %s\n", "", profile.Tags, strings.Join(keys, ", "))
+	}
+}
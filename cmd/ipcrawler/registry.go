@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/neur0map/ipcrawler/internal/output"
 	"github.com/neur0map/ipcrawler/internal/registry"
 	"github.com/neur0map/ipcrawler/internal/registry/scanners"
 )
@@ -200,11 +201,11 @@ func runRegistryValidate(args []string) error {
 	issues := manager.ValidateRegistry()
 
 	if len(issues) == 0 {
-		fmt.Println("✅ Registry validation passed. No issues found.")
+		fmt.Println(output.FormatStatus(output.StatusOK, "Registry validation passed. No issues found."))
 		return nil
 	}
 
-	fmt.Printf("❌ Registry validation found %d issues:\n\n", len(issues))
+	fmt.Printf("%s\n\n", output.FormatStatus(output.StatusFailed, fmt.Sprintf("Registry validation found %d issues:", len(issues))))
 	for i, issue := range issues {
 		fmt.Printf("%d. %s\n", i+1, issue)
 	}
@@ -250,10 +251,10 @@ func runRegistryScan(args []string) error {
 
 	newVariables := finalCount - initialCount
 	if newVariables > 0 {
-		fmt.Printf("✅ Scan completed. Found and registered %d new variables.\n", newVariables)
+		fmt.Println(output.FormatStatus(output.StatusOK, fmt.Sprintf("Scan completed. Found and registered %d new variables.", newVariables)))
 		fmt.Printf("Total variables in registry: %d\n", finalCount)
 	} else {
-		fmt.Println("✅ Scan completed. No new variables found.")
+		fmt.Println(output.FormatStatus(output.StatusOK, "Scan completed. No new variables found."))
 	}
 
 	return nil
@@ -404,7 +405,7 @@ func printVariableDetailed(variable *registry.VariableRecord) {
 	}
 
 	if variable.Deprecated {
-		fmt.Printf("⚠️  Deprecated: true\n")
+		fmt.Println(output.FormatStatus(output.StatusWarning, "Deprecated: true"))
 		if variable.ReplacedBy != "" {
 			fmt.Printf("Replaced By: %s\n", variable.ReplacedBy)
 		}
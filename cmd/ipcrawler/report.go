@@ -0,0 +1,349 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/heuristics"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/report"
+)
+
+// runReportCommand (re)generates per-target reports for workspaces and an engagement roll-up
+func runReportCommand(args []string) error {
+	fs := pflag.NewFlagSet("report", pflag.ContinueOnError)
+	var (
+		formats   = fs.StringSlice("format", nil, "Report formats (default from security.yaml reporting.formats)")
+		rollupDir = fs.String("rollup-dir", "", "Directory for the engagement roll-up (default: parent of the first workspace)")
+		noRollup  = fs.Bool("no-rollup", false, "Only generate per-target reports")
+		baseline  = fs.String("baseline", "", "Baseline workspace; compare one retest workspace against it")
+	)
+	fs.Usage = printReportUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printReportUsage()
+		return fmt.Errorf("at least one workspace is required")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	if len(*formats) == 0 {
+		*formats = cfg.Security.Reporting.Formats
+	}
+	if len(*formats) == 0 {
+		*formats = []string{"markdown"}
+	}
+	if err := report.ValidateFormats(*formats); err != nil {
+		return err
+	}
+
+	// Stored workspaces (s3://...) are downloaded to a temporary directory, and the reports
+	// generated there are uploaded back next to them
+	var tmpRoot string
+	defer func() {
+		if tmpRoot != "" {
+			os.RemoveAll(tmpRoot)
+		}
+	}()
+	resolve := func(arg string) (string, *remoteWorkspace, error) {
+		if !isRemoteWorkspace(arg) {
+			workspaceDir, err := resolveWorkspace(arg)
+			return workspaceDir, nil, err
+		}
+		if tmpRoot == "" {
+			if tmpRoot, err = os.MkdirTemp("", "ipcrawler-report-"); err != nil {
+				return "", nil, err
+			}
+		}
+		remote, err := fetchWorkspace(cfg, arg, tmpRoot)
+		if err != nil {
+			return "", nil, err
+		}
+		return remote.dir, remote, nil
+	}
+
+	workspaces := make([]string, 0, fs.NArg())
+	var remotes []*remoteWorkspace
+	for _, arg := range fs.Args() {
+		workspaceDir, remote, err := resolve(arg)
+		if err != nil {
+			return err
+		}
+		workspaces = append(workspaces, workspaceDir)
+		if remote != nil {
+			remotes = append(remotes, remote)
+		}
+	}
+
+	redactor, err := reportRedactor(cfg)
+	if err != nil {
+		return err
+	}
+
+	if *baseline != "" {
+		if len(workspaces) != 1 {
+			return fmt.Errorf("--baseline compares exactly one retest workspace")
+		}
+		baselineDir, _, err := resolve(*baseline)
+		if err != nil {
+			return err
+		}
+		if err := writeRetestReport(cfg, baselineDir, workspaces[0], *formats, redactor); err != nil {
+			return err
+		}
+		return storeRemoteReports(remotes)
+	}
+
+	summaries, err := buildTargetReports(cfg, workspaces, *formats, redactor)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		fmt.Printf("%s: %d hosts, %d open ports", summary.Target, len(summary.Hosts), summary.OpenPortCount())
+		if len(summary.Anomalies) > 0 {
+			fmt.Printf(", %d review hints", len(summary.Anomalies))
+		}
+		fmt.Println()
+	}
+	if err := storeRemoteReports(remotes); err != nil {
+		return err
+	}
+
+	if *noRollup || len(summaries) < 2 && *rollupDir == "" {
+		return nil
+	}
+
+	dir := *rollupDir
+	if dir == "" {
+		dir = filepath.Dir(workspaces[0])
+		if isRemoteWorkspace(fs.Arg(0)) {
+			dir = "." // The download is temporary
+		}
+	}
+	if err := os.MkdirAll(dir, cfg.Output.Permissions.DirPerm()); err != nil {
+		return fmt.Errorf("failed to create roll-up directory: %v", err)
+	}
+	paths, err := report.WriteRollupReports(report.BuildRollup(summaries), dir, *formats, cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Printf("Engagement roll-up: %s\n", path)
+	}
+	return nil
+}
+
+func printReportUsage() {
+	fmt.Println("Usage: ipcrawler report [options] <workspace>...")
+	fmt.Println()
+	fmt.Println("Generates a report in each workspace's reports/ directory, refreshes its")
+	fmt.Println("INDEX.md and, for several workspaces, an engagement-level roll-up (top")
+	fmt.Println("exposed ports, service frequency, host matrix).")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Printf("      --format LIST       Report formats: %s\n", strings.Join(report.Formats(), ", "))
+	fmt.Println("      --rollup-dir DIR    Where to write the roll-up (default: parent of the first workspace)")
+	fmt.Println("      --no-rollup         Only generate per-target reports")
+	fmt.Println("      --baseline DIR      Retest mode: compare the workspace against a baseline workspace")
+	fmt.Println()
+	fmt.Println("A workspace may be stored in S3 (s3://bucket/prefix/workspace, see configs/storage.yaml):")
+	fmt.Println("it is downloaded, and its reports are uploaded back to its reports/ prefix. The")
+	fmt.Println("roll-up of stored workspaces defaults to the current directory.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler report ipcrawler_results/*")
+	fmt.Println("  ipcrawler report --rollup-dir engagement/ ws1 ws2 ws3")
+	fmt.Println("  ipcrawler report --baseline ws-january ws-retest   # fixed/unchanged/new per host")
+	fmt.Println("  ipcrawler report s3://scans/workspaces/10_10_10_5_1735732800_1a2b3c4d")
+}
+
+// storeRemoteReports uploads the reports generated for stored workspaces
+func storeRemoteReports(remotes []*remoteWorkspace) error {
+	for _, remote := range remotes {
+		url, err := remote.storeReports()
+		if err != nil {
+			return fmt.Errorf("failed to store reports of %s: %v", remote.backend.URL(remote.key), err)
+		}
+		fmt.Printf("Reports stored in %s\n", url)
+	}
+	return nil
+}
+
+// buildTargetReports loads and renders per-target reports for the workspaces in parallel.
+// The returned summaries are redacted, as the reports are
+func buildTargetReports(cfg *config.Config, workspaces []string, formats []string, redactor *output.Redactor) ([]*report.TargetSummary, error) {
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labels configuration: %v", err)
+	}
+	detector, err := heuristics.New(cfg.Heuristics)
+	if err != nil {
+		return nil, fmt.Errorf("invalid heuristics configuration: %v", err)
+	}
+
+	summaries := make([]*report.TargetSummary, len(workspaces))
+	errs := make([]error, len(workspaces))
+
+	workers := runtime.NumCPU()
+	if workers > len(workspaces) {
+		workers = len(workspaces)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				summary, err := report.LoadTarget(workspaces[i], catalog, labeler.LabelsFor)
+				if err == nil {
+					summary.Anomalies = detector.Evaluate(summary.Findings)
+					summary = redactor.RedactCopy(summary).(*report.TargetSummary)
+					_, err = report.WriteTargetReports(summary, formats, cfg.Output.Permissions.FilePerm())
+				}
+				if err == nil {
+					err = writeWorkspaceIndex(summary, cfg.Output.Permissions.FilePerm())
+				}
+				summaries[i], errs[i] = summary, err
+			}
+		}()
+	}
+	for i := range workspaces {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", workspaces[i], err)
+		}
+	}
+	return summaries, nil
+}
+
+// writeRetestReport compares a retest workspace with its baseline and writes the comparison
+func writeRetestReport(cfg *config.Config, baselineArg, currentDir string, formats []string, redactor *output.Redactor) error {
+	baselineDir, err := resolveWorkspace(baselineArg)
+	if err != nil {
+		return err
+	}
+
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid labels configuration: %v", err)
+	}
+
+	baseline, err := report.LoadTarget(baselineDir, catalog, labeler.LabelsFor)
+	if err != nil {
+		return fmt.Errorf("%s: %v", baselineDir, err)
+	}
+	current, err := report.LoadTarget(currentDir, catalog, labeler.LabelsFor)
+	if err != nil {
+		return fmt.Errorf("%s: %v", currentDir, err)
+	}
+
+	baseline = redactor.RedactCopy(baseline).(*report.TargetSummary)
+	current = redactor.RedactCopy(current).(*report.TargetSummary)
+	comparison := report.BuildComparison(baseline, current)
+	paths, err := report.WriteComparisonReports(comparison, formats, cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Retest: %d fixed, %d unchanged, %d new\n", comparison.Fixed, comparison.Unchanged, comparison.New)
+	for _, path := range paths {
+		fmt.Printf("Retest report: %s\n", path)
+	}
+	return nil
+}
+
+// generateRunReports writes the configured reports for a finished run's workspace
+func generateRunReports(cfg *config.Config, workspaceDir string, redactor *output.Redactor, logger *log.Logger) {
+	if !cfg.Security.Reporting.AutoGenerate || len(cfg.Security.Reporting.Formats) == 0 {
+		return
+	}
+	summaries, err := buildTargetReports(cfg, []string{workspaceDir}, cfg.Security.Reporting.Formats, redactor)
+	if err != nil {
+		logger.Warn("Failed to generate reports", "error", err)
+		return
+	}
+	logger.Info("Reports generated", "path", filepath.Join(workspaceDir, "reports"), "open_ports", summaries[0].OpenPortCount(), "review_hints", len(summaries[0].Anomalies))
+}
+
+// writeRunReport fills in the discovered ports and writes the run report (report.json or report.pb) and report.html,
+// which charts the target's earlier runs from the run database when history is set.
+// It returns the findings summary the ports came from, nil if the workspace could not be read
+func writeRunReport(cfg *config.Config, generator *output.ReportGenerator, workspaceDir string, history bool, logger *log.Logger) *report.TargetSummary {
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	summary, err := report.LoadTarget(workspaceDir, catalog, nil)
+	if err != nil {
+		logger.Warn("Failed to collect discovered ports for run report", "error", err)
+	} else {
+		var ports []output.DiscoveredPort
+		for _, host := range summary.Hosts {
+			for _, port := range host.OpenPorts {
+				ports = append(ports, output.DiscoveredPort{
+					Host:     host.Host,
+					Port:     port.Port,
+					Protocol: port.Protocol,
+					Service:  port.Service,
+					Product:  port.Product,
+					Version:  port.Version,
+					Tools:    port.Tools,
+				})
+			}
+		}
+		generator.SetDiscovered(ports)
+	}
+
+	reportPath, err := generator.Write(cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		logger.Warn("Failed to write run report", "error", err)
+	} else {
+		logger.Debug("Run report written", "path", reportPath)
+	}
+
+	if history {
+		generator.SetTrend(runTrend(cfg, generator.Report(), summary, logger))
+	}
+	htmlPath, err := generator.WriteHTML(cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		logger.Warn("Failed to write HTML report", "error", err)
+		return summary
+	}
+	logger.Info("HTML report written", "path", htmlPath)
+	return summary
+}
+
+// printRunWarnings ends a run with its warnings, so an imperfect scan is not mistaken for a clean one
+func printRunWarnings(runReport output.RunReport) {
+	if len(runReport.Warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\nScan %s with %d warning(s); results may be incomplete:\n", runReport.Status, len(runReport.Warnings))
+	for _, warning := range runReport.Warnings {
+		fmt.Fprintf(os.Stderr, "  %s %s %s: %s\n", output.FormatStatus(output.StatusWarning, fmt.Sprintf("%-19s", warning.Kind)), warning.Tool, warning.Mode, warning.Message)
+	}
+	fmt.Fprintf(os.Stderr, "See the Scan Quality section of the report: %s\n", filepath.Join(runReport.Workspace, "reports"))
+}
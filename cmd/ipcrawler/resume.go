@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// latestWorkspace is the --resume-queue value used when no workspace is given
+const latestWorkspace = "latest"
+
+// resumeRun is an interrupted run whose unfinished workflows are continued in place
+type resumeRun struct {
+	Workspace string
+	Manifest  *session.RunManifest
+	Queue     *executor.QueueState
+}
+
+// runResumeQueue continues the persisted queue of an interrupted run in its own workspace
+func runResumeQueue(workspaceArg string, outputMode output.OutputMode, outputDir string, dropPrivileges bool) error {
+	workspaceDir := workspaceArg
+	if workspaceArg == latestWorkspace {
+		if outputDir == "" {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %v", err)
+			}
+			outputDir = cfg.Output.WorkspaceBase
+		}
+		latest, err := findResumableWorkspace(outputDir)
+		if err != nil {
+			return err
+		}
+		workspaceDir = latest
+	}
+	workspaceDir, err := resolveWorkspace(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := session.LoadManifest(workspaceDir)
+	if err != nil {
+		return err
+	}
+	queue, err := executor.LoadQueueState(workspaceDir)
+	if err != nil {
+		return fmt.Errorf("%s has no resumable queue: %v", workspaceDir, err)
+	}
+	if len(queue.Items) == 0 {
+		return fmt.Errorf("nothing to resume: every workflow in %s has finished", workspaceDir)
+	}
+
+	// The original run's exclusions still apply to everything it resumes
+	exclusions := scope.NewExclusionList()
+	for _, entry := range manifest.Exclusions {
+		if err := exclusions.Add(entry); err != nil {
+			return fmt.Errorf("invalid exclusion in manifest: %v", err)
+		}
+	}
+	engagement, err := loadEngagement(manifest.Scope, exclusions)
+	if err != nil {
+		return fmt.Errorf("scope file of the interrupted run: %v", err)
+	}
+
+	if outputMode == output.OutputModePlain {
+		printRecord(nil, "event", "resume_started", "target", manifest.Target, "workflows", strconv.Itoa(len(queue.Items)), "workspace", workspaceDir)
+	} else if marker, err := session.LoadShutdownMarker(workspaceDir); err == nil {
+		fmt.Fprintf(os.Stderr, "Run was stopped by %s at %s", marker.Signal, marker.StoppedAt.Format(time.RFC3339))
+		if len(marker.StoppedTools) > 0 {
+			fmt.Fprintf(os.Stderr, " while running %s", strings.Join(marker.StoppedTools, ", "))
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	if outputMode != output.OutputModePlain {
+		fmt.Fprintf(os.Stderr, "Resuming %d unfinished workflow(s) for %s in %s\n", len(queue.Items), manifest.Target, workspaceDir)
+	}
+	hooks := &scanHooks{Resume: &resumeRun{Workspace: workspaceDir, Manifest: manifest, Queue: queue}, Engagement: engagement}
+	return runCLI(manifest.Target, outputMode, filepath.Dir(workspaceDir), exclusions, manifest.Labels, dropPrivileges, hooks)
+}
+
+// findResumableWorkspace returns the most recently updated workspace under baseDir with unfinished workflows
+func findResumableWorkspace(baseDir string) (string, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", baseDir, err)
+	}
+
+	var latest string
+	var latestState *executor.QueueState
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(baseDir, entry.Name())
+		state, err := executor.LoadQueueState(dir)
+		if err != nil || len(state.Items) == 0 {
+			continue
+		}
+		if latestState == nil || state.UpdatedAt.After(latestState.UpdatedAt) {
+			latest, latestState = dir, state
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no interrupted runs with unfinished workflows found in %s", baseDir)
+	}
+	return latest, nil
+}
+
+// requeueWorkflows restores persisted queue items, matching them to workflow definitions by name
+func requeueWorkflows(orchestrator *executor.WorkflowOrchestrator, workflows map[string]*executor.Workflow, queue *executor.QueueState, logger *log.Logger) {
+	byName := make(map[string]*executor.Workflow, len(workflows))
+	for _, workflow := range workflows {
+		byName[workflow.Name] = workflow
+	}
+
+	for _, item := range queue.Items {
+		workflow, exists := byName[item.Workflow]
+		if !exists {
+			logger.Warn("Skipping queued workflow that no longer exists", "workflow", item.Workflow)
+			fmt.Fprintf(os.Stderr, "Warning: workflow %q is no longer defined; skipping it\n", item.Workflow)
+			continue
+		}
+		// A workflow waited for that is not in the queue file finished before the interruption
+		var pending []string
+		for _, dependency := range item.Dependencies {
+			for _, other := range queue.Items {
+				if other.Target == item.Target && byName[other.Workflow] != nil && byName[other.Workflow].MatchesName(dependency) {
+					pending = append(pending, dependency)
+					break
+				}
+			}
+		}
+		item.Dependencies = pending
+		logger.Info("Requeueing workflow", "title", item.Workflow, "state", item.State, "priority", item.Priority)
+		orchestrator.RequeueWorkflow(workflow, item)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/integrations/issues"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/report"
+	"github.com/neur0map/ipcrawler/internal/rundb"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// runDBPath is the run database in the user's IPCrawler directory
+func runDBPath() (string, error) {
+	dir, err := userconfig.Dir()
+	if err != nil {
+		return "", err
+	}
+	return rundb.Path(dir), nil
+}
+
+// recordRun adds a finished run to the run database
+func recordRun(cfg *config.Config, manifest *session.RunManifest, runReport output.RunReport, summary *report.TargetSummary, logger *log.Logger) {
+	path, err := runDBPath()
+	if err != nil {
+		logger.Warn("Failed to locate run database", "error", err)
+		return
+	}
+	run := rundb.Run{
+		ScanID:          manifest.ScanID,
+		Target:          manifest.Target,
+		Labels:          manifest.Labels,
+		Workflows:       make([]string, 0, len(runReport.Workflows)),
+		Status:          manifest.Status,
+		Error:           manifest.Error,
+		StartedAt:       manifest.StartedAt,
+		DurationSeconds: manifest.DurationSeconds,
+		OpenPorts:       len(runReport.Ports),
+		Warnings:        len(runReport.Warnings),
+		Workspace:       manifest.Workspace,
+	}
+	if manifest.FinishedAt != nil {
+		run.FinishedAt = *manifest.FinishedAt
+	}
+	for _, workflow := range runReport.Workflows {
+		if !containsFold(run.Workflows, workflow.Name) {
+			run.Workflows = append(run.Workflows, workflow.Name)
+		}
+	}
+	if summary != nil {
+		run.Findings = len(summary.Findings)
+		run.Severities = findingSeverities(cfg, summary)
+	}
+	if err := rundb.Append(path, run); err != nil {
+		logger.Warn("Failed to record run", "error", err)
+	}
+}
+
+// findingSeverities counts a run's open ports by the issues.severity levels
+func findingSeverities(cfg *config.Config, summary *report.TargetSummary) map[string]int {
+	return issues.SeverityRules(cfg.Integrations.Issues.Severity).Count(summary.Findings)
+}
+
+// trendRuns is how many runs of a target the HTML report charts, the reported one included
+const trendRuns = 30
+
+// runTrend returns the target's completed runs from the run database followed by the reported
+// run, oldest first; nil when the database has no earlier run of the target
+func runTrend(cfg *config.Config, runReport output.RunReport, summary *report.TargetSummary, logger *log.Logger) *output.Trend {
+	runs, err := loadRecordedRuns()
+	if err != nil {
+		logger.Warn("Failed to read run database for the report trend", "error", err)
+		return nil
+	}
+	trend := &output.Trend{Levels: issues.Levels()}
+	for _, run := range rundb.ForTarget(runs, runReport.Target) {
+		// A resumed run is recorded again; a failed one would show ports closing that were not scanned
+		if run.ScanID == runReport.ScanID || run.Status != session.RunStatusCompleted {
+			continue
+		}
+		trend.Points = append(trend.Points, output.TrendPoint{
+			ScanID:     run.ScanID,
+			StartedAt:  run.StartedAt,
+			OpenPorts:  run.OpenPorts,
+			Severities: run.Severities,
+		})
+		if len(trend.Points) == trendRuns-1 {
+			break
+		}
+	}
+	if len(trend.Points) == 0 {
+		return nil
+	}
+	slices.Reverse(trend.Points)
+
+	current := output.TrendPoint{ScanID: runReport.ScanID, StartedAt: runReport.StartedAt, OpenPorts: len(runReport.Ports)}
+	if summary != nil {
+		current.Severities = findingSeverities(cfg, summary)
+	}
+	trend.Points = append(trend.Points, current)
+	return trend
+}
+
+// loadRecordedRuns reads the run database, latest run first
+func loadRecordedRuns() ([]rundb.Run, error) {
+	path, err := runDBPath()
+	if err != nil {
+		return nil, err
+	}
+	return rundb.Load(path)
+}
+
+// printRecordedRuns lists the recorded runs, of one target or of all
+func printRecordedRuns(target string, asJSON bool) error {
+	runs, err := loadRecordedRuns()
+	if err != nil {
+		return err
+	}
+	if target != "" {
+		runs = rundb.ForTarget(runs, target)
+	}
+	if asJSON {
+		if runs == nil {
+			runs = []rundb.Run{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(runs)
+	}
+	if len(runs) == 0 {
+		if target != "" {
+			return fmt.Errorf("no previous runs for %s", target)
+		}
+		fmt.Println("No runs recorded yet")
+		return nil
+	}
+
+	fmt.Printf("  %-8s  %-19s  %-24s  %-13s  %9s  %8s\n", "ID", "STARTED", "TARGET", "STATUS", "DURATION", "FINDINGS")
+	for _, run := range runs {
+		duration := "-"
+		if run.DurationSeconds > 0 {
+			duration = time.Duration(run.DurationSeconds * float64(time.Second)).Round(time.Second).String()
+		}
+		status := output.FormatStatus(runStatusKind(run.Status), fmt.Sprintf("%-11s", valueOrUnknown(run.Status)))
+		fmt.Printf("  %-8s  %-19s  %-24s  %s  %9s  %8d\n", session.ShortScanID(run.ScanID),
+			run.StartedAt.Local().Format("2006-01-02 15:04:05"), truncateTarget(run.Target, 24), status, duration, run.Findings)
+	}
+	fmt.Println("  Details of a run: ipcrawler history show <id>")
+	return nil
+}
+
+// showRecordedRun prints one recorded run and, while its workspace exists, its workflow summaries
+func showRecordedRun(id string, asJSON bool) error {
+	runs, err := loadRecordedRuns()
+	if err != nil {
+		return err
+	}
+	run, err := rundb.Find(runs, id)
+	if err != nil {
+		return err
+	}
+	summaries, summaryErr := executor.LoadWorkflowSummaries(run.Workspace)
+	if _, err := os.Stat(run.Workspace); err != nil {
+		summaries, summaryErr = nil, nil
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			*rundb.Run
+			Summaries []*executor.WorkflowSummary `json:"summaries,omitempty"`
+		}{run, summaries})
+	}
+
+	fmt.Printf("Run %s\n", run.ScanID)
+	fmt.Printf("  Target:     %s\n", run.Target)
+	if len(run.Labels) > 0 {
+		fmt.Printf("  Labels:     %s\n", strings.Join(run.Labels, ", "))
+	}
+	fmt.Printf("  Status:     %s\n", output.FormatStatus(runStatusKind(run.Status), valueOrUnknown(run.Status)))
+	if run.Error != "" {
+		fmt.Printf("  Error:      %s\n", run.Error)
+	}
+	fmt.Printf("  Started:    %s\n", run.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	if !run.FinishedAt.IsZero() {
+		fmt.Printf("  Finished:   %s (%s)\n", run.FinishedAt.Local().Format("2006-01-02 15:04:05"),
+			time.Duration(run.DurationSeconds*float64(time.Second)).Round(time.Second))
+	}
+	fmt.Printf("  Workflows:  %s\n", strings.Join(run.Workflows, ", "))
+	fmt.Printf("  Findings:   %d (%d open ports)\n", run.Findings, run.OpenPorts)
+	if run.Warnings > 0 {
+		fmt.Printf("  Warnings:   %d\n", run.Warnings)
+	}
+	fmt.Printf("  Workspace:  %s\n", run.Workspace)
+
+	switch {
+	case summaryErr != nil:
+		fmt.Printf("\n  Workflow summaries unavailable: %v\n", summaryErr)
+	case summaries == nil:
+		fmt.Printf("\n  The workspace no longer exists; only the recorded totals are available\n")
+	}
+	for _, summary := range summaries {
+		fmt.Printf("\n  %s: %s\n", summary.Workflow, summary.Status)
+		if len(summary.OpenPorts) > 0 {
+			ports := make([]string, len(summary.OpenPorts))
+			for i, port := range summary.OpenPorts {
+				ports[i] = fmt.Sprint(port)
+			}
+			fmt.Printf("    Open ports:  %s\n", strings.Join(ports, ", "))
+		}
+		if len(summary.HostsAlive) > 0 {
+			fmt.Printf("    Hosts alive: %s\n", strings.Join(summary.HostsAlive, ", "))
+		}
+		for _, url := range summary.URLs {
+			fmt.Printf("    URL:         %s\n", url)
+		}
+	}
+	if summaries != nil {
+		fmt.Printf("\n  Full results: ipcrawler report %s\n", filepath.Clean(run.Workspace))
+	}
+	return nil
+}
+
+// truncateTarget shortens long targets to fit a table column
+func truncateTarget(target string, width int) string {
+	if len(target) <= width {
+		return target
+	}
+	return target[:width-3] + "..."
+}
+
+func containsFold(values []string, value string) bool {
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/rawindex"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// runSearchCommand queries the findings of a workspace
+func runSearchCommand(args []string) error {
+	fs := pflag.NewFlagSet("search", pflag.ContinueOnError)
+	var (
+		workspace = fs.StringP("workspace", "w", "", "Workspace directory to search (default: current directory)")
+		asJSON    = fs.Bool("json", false, "Print matches as JSON")
+		raw       = fs.Bool("raw", false, "Search the raw tool output for text instead of the findings")
+		index     = fs.Bool("index", false, "Build or refresh the raw output index first")
+		limit     = fs.Int("limit", 200, "Maximum raw output lines to print (0 = all)")
+	)
+	fs.Usage = printSearchUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workspaceDir, err := resolveWorkspace(*workspace)
+	if err != nil {
+		return err
+	}
+	if *raw || *index {
+		return searchRawOutput(workspaceDir, strings.Join(fs.Args(), " "), *index, *limit, *asJSON)
+	}
+
+	query, err := findings.ParseQuery(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return fmt.Errorf("invalid query: %v", err)
+	}
+
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+
+	all, warnings, err := catalog.LoadWorkspace(workspaceDir)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %s\n", warning)
+	}
+
+	// Label findings from the labeling rules and the labels recorded for the run's target
+	var workspaceLabels []string
+	if manifest, err := session.LoadManifest(workspaceDir); err == nil {
+		workspaceLabels = manifest.Labels
+	}
+	if cfg, err := config.LoadConfig(); err == nil {
+		labeler, err := buildLabeler(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid labels configuration: %v", err)
+		}
+		findings.ApplyLabels(all, labeler.LabelsFor, workspaceLabels)
+	} else {
+		findings.ApplyLabels(all, nil, workspaceLabels)
+	}
+
+	matches := query.Filter(all)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(matches)
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No findings matched (%d total in %s)\n", len(all), workspaceDir)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tPORT\tPROTO\tSTATE\tSERVICE\tPRODUCT\tTOOL\tLABELS")
+	fmt.Fprintln(w, "----\t----\t-----\t-----\t-------\t-------\t----\t------")
+	for _, f := range matches {
+		port := "-"
+		if f.Port > 0 {
+			port = strconv.Itoa(f.Port)
+		}
+		product := strings.TrimSpace(f.Product + " " + f.Version)
+		if f.TLS {
+			product = strings.TrimSpace(product + " [tls]")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.Host, port, dashIfEmpty(f.Protocol), dashIfEmpty(f.State),
+			dashIfEmpty(f.Service), dashIfEmpty(product), f.Tool, dashIfEmpty(strings.Join(f.Labels, ",")))
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d of %d findings matched\n", len(matches), len(all))
+	return nil
+}
+
+// searchRawOutput implements `ipcrawler search --raw`: a case-insensitive text search of the
+// raw tool output, narrowed by the workspace's index when one was built
+func searchRawOutput(workspaceDir, text string, buildIndex bool, limit int, asJSON bool) error {
+	var index *rawindex.Index
+	var err error
+	if buildIndex {
+		dirPerm, filePerm := os.FileMode(0755), os.FileMode(0644)
+		if cfg, err := config.LoadConfig(); err == nil {
+			dirPerm, filePerm = cfg.Output.Permissions.DirPerm(), cfg.Output.Permissions.FilePerm()
+		}
+		started := time.Now()
+		if index, err = rawindex.Build(workspaceDir, dirPerm, filePerm); err != nil {
+			return err
+		}
+		if !asJSON {
+			fmt.Printf("Indexed %d raw output files in %s\n", len(index.Files), time.Since(started).Round(time.Millisecond))
+		}
+		if text == "" {
+			return nil
+		}
+	} else if index, err = rawindex.Load(workspaceDir); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Warning: %v; searching every file\n", err)
+	}
+	if text == "" {
+		printSearchUsage()
+		return fmt.Errorf("search text is required with --raw")
+	}
+
+	started := time.Now()
+	hits, stats, err := rawindex.Search(workspaceDir, index, text, limit)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(hits)
+	}
+	for _, hit := range hits {
+		fmt.Printf("%s:%d: %s\n", hit.Path, hit.Line, hit.Text)
+	}
+	if len(hits) == 0 {
+		fmt.Printf("No raw output matched %q in %s\n", text, workspaceDir)
+	} else if limit > 0 && len(hits) >= limit {
+		fmt.Printf("\nFirst %d matching lines (--limit 0 prints all)\n", limit)
+	}
+	elapsed := time.Since(started).Round(time.Millisecond)
+	switch {
+	case index == nil:
+		fmt.Printf("Searched %d files in %s; `ipcrawler search --index` builds an index for faster searches\n", stats.Scanned, elapsed)
+	case stats.Unindexed > 0:
+		fmt.Printf("Searched %d of %d indexed files in %s, plus %d new since indexing (--index refreshes it)\n",
+			stats.Scanned-stats.Unindexed, stats.Indexed, elapsed, stats.Unindexed)
+	default:
+		fmt.Printf("Searched %d of %d indexed files in %s\n", stats.Scanned, stats.Indexed, elapsed)
+	}
+	return nil
+}
+
+// buildRawIndex indexes a finished run's raw output when output.raw_index is set
+func buildRawIndex(cfg *config.Config, workspaceDir string, fileMode os.FileMode, logger *log.Logger) {
+	if !cfg.Output.RawIndex {
+		return
+	}
+	index, err := rawindex.Build(workspaceDir, cfg.Output.Permissions.DirPerm(), fileMode)
+	if err != nil {
+		logger.Warn("Failed to index raw output", "error", err)
+		return
+	}
+	logger.Debug("Raw output indexed", "files", len(index.Files), "trigrams", len(index.Postings))
+}
+
+func printSearchUsage() {
+	fmt.Println("Usage: ipcrawler search [options] '<query>'")
+	fmt.Println("       ipcrawler search --raw [options] '<text>'")
+	fmt.Println()
+	fmt.Println("Searches the findings parsed from a workspace's scan outputs, or with --raw the")
+	fmt.Println("raw tool output itself for a line containing the text (case-insensitive). Raw")
+	fmt.Println("searches use the workspace's index (built by --index, or after every run with")
+	fmt.Println("output.raw_index) to read only the files that can match.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -w, --workspace DIR   Workspace directory (default: current directory)")
+	fmt.Println("      --json            Print matches as JSON")
+	fmt.Println("      --raw             Search the raw tool output for text")
+	fmt.Println("      --index           Build or refresh the raw output index first")
+	fmt.Println("      --limit N         Maximum raw output lines to print (default 200, 0 = all)")
+	fmt.Println()
+	fmt.Println("Query syntax:")
+	fmt.Println("  field:value           Exact match (case-insensitive); * and ? are wildcards")
+	fmt.Println("  port:1-1024           Port range; also port:>N, port:>=N, port:<N, port:<=N")
+	fmt.Println("  status:>=400          HTTP status code, with the same ranges and comparisons")
+	fmt.Println("  word                  Matches any text field")
+	fmt.Println("  AND, OR, NOT, -term   Boolean operators (AND is implicit); use () to group")
+	fmt.Println()
+	fmt.Printf("Fields: %s (aliases: ip, proto, svc, tag, file, technology)\n", strings.Join(findings.Fields, ", "))
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler search 'port:445 AND service:microsoft-ds' -w ipcrawler_results/10_10_10_5_...")
+	fmt.Println("  ipcrawler search 'state:open (service:http* OR tls:true)'")
+	fmt.Println("  ipcrawler search 'port:<1024 -tool:naabu' --json")
+	fmt.Println("  ipcrawler search 'label:dmz AND port:445'")
+	fmt.Println("  ipcrawler search 'tech:wordpress* OR title:*admin*'")
+	fmt.Println("  ipcrawler search --raw \"anonymous login\" -w ipcrawler_results/10_10_10_5_...")
+}
+
+// resolveWorkspace validates a workspace directory, defaulting to the current directory
+func resolveWorkspace(dir string) (string, error) {
+	ws, err := workspace.Open(dir)
+	if err != nil {
+		return "", err
+	}
+	return ws.Dir, nil
+}
+
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/secrets"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// secretsPath is the encrypted secrets file in the user's IPCrawler directory
+func secretsPath() (string, error) {
+	dir, err := userconfig.Dir()
+	if err != nil {
+		return "", err
+	}
+	return secrets.Path(dir), nil
+}
+
+// loadSecrets builds the secrets store of a run from the environment and, when
+// IPCRAWLER_SECRETS_PASSPHRASE is set, the secrets file
+func loadSecrets(logger *log.Logger) (*secrets.Store, error) {
+	path, err := secretsPath()
+	if err != nil {
+		return nil, err
+	}
+	passphrase := os.Getenv(secrets.PassphraseEnv)
+	if passphrase == "" {
+		if _, err := os.Stat(path); err == nil {
+			logger.Warn("Secrets file not unlocked; only IPCRAWLER_SECRET_* variables are available", "file", path, "passphrase_env", secrets.PassphraseEnv)
+		}
+	}
+	store, err := secrets.Load(path, passphrase, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	if store.Len() > 0 {
+		logger.Info("Secrets loaded", "count", store.Len())
+	}
+	return store, nil
+}
+
+// reportRedactor is the redactor of commands that rewrite reports outside a run: the
+// configured patterns and the secrets available without prompting
+func reportRedactor(cfg *config.Config) (*output.Redactor, error) {
+	path, err := secretsPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := secrets.Load(path, os.Getenv(secrets.PassphraseEnv), os.Environ())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %v", err)
+	}
+	redactor, err := output.NewRedactor(store, cfg.Output.Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction configuration: %v", err)
+	}
+	return redactor, nil
+}
+
+// runSecretsCommand implements `ipcrawler secrets list|set|remove`
+func runSecretsCommand(args []string) error {
+	fs := pflag.NewFlagSet("secrets", pflag.ContinueOnError)
+	tools := fs.StringSlice("tools", nil, "With set: tools whose arguments may use the secret (default: every tool)")
+	fs.Usage = printSecretsUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printSecretsUsage()
+		return fmt.Errorf("a secrets command is required")
+	}
+	path, err := secretsPath()
+	if err != nil {
+		return err
+	}
+
+	switch action := fs.Arg(0); action {
+	case "list":
+		return listSecrets(path)
+	case "set", "remove":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("usage: ipcrawler secrets %s <name>", action)
+		}
+		name := strings.ToLower(fs.Arg(1))
+		if !secrets.ValidName(name) {
+			return fmt.Errorf("invalid secret name %q: use lower-case letters, digits and _", name)
+		}
+		passphrase, err := readSecret("Passphrase: ", os.Getenv(secrets.PassphraseEnv))
+		if err != nil {
+			return err
+		}
+		stored, err := secrets.ReadFile(path, passphrase)
+		if err != nil {
+			return err
+		}
+		if action == "remove" {
+			if _, exists := stored[name]; !exists {
+				return fmt.Errorf("secret %q is not in %s", name, path)
+			}
+			delete(stored, name)
+		} else {
+			value, err := readSecret(fmt.Sprintf("Value of %s: ", name), "")
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				return fmt.Errorf("empty value for secret %q", name)
+			}
+			stored[name] = secrets.Secret{Value: value, Tools: *tools}
+		}
+		if err := secrets.WriteFile(path, passphrase, stored); err != nil {
+			return err
+		}
+		if action == "remove" {
+			fmt.Printf("Secret %s removed from %s\n", name, path)
+		} else {
+			fmt.Printf("Secret %s saved in %s; use it as {{secret:%s}}\n", name, path, name)
+		}
+		return nil
+	default:
+		printSecretsUsage()
+		return fmt.Errorf("unknown secrets command %q", action)
+	}
+}
+
+// listSecrets prints the names of the secrets and where each comes from, never their values
+func listSecrets(path string) error {
+	passphrase := os.Getenv(secrets.PassphraseEnv)
+	if passphrase == "" {
+		if _, err := os.Stat(path); err == nil {
+			var err error
+			if passphrase, err = readSecret("Passphrase: ", ""); err != nil {
+				return err
+			}
+		}
+	}
+	store, err := secrets.Load(path, passphrase, os.Environ())
+	if err != nil {
+		return err
+	}
+	sources := store.Names()
+	if len(sources) == 0 {
+		fmt.Println("No secrets set")
+		return nil
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("  %-28s  %s\n", "NAME", "SOURCE")
+	for _, name := range names {
+		fmt.Printf("  %-28s  %s\n", name, sources[name])
+	}
+	return nil
+}
+
+// stdinReader is shared by every prompt, so piped lines are not lost to a reader's buffer
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readSecret returns preset when it is set, else reads a line from the terminal without
+// echoing it, or from standard input when it is not a terminal
+func readSecret(prompt, preset string) (string, error) {
+	if preset != "" {
+		return preset, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", strings.TrimSuffix(prompt, ": "), err)
+		}
+		return string(value), nil
+	}
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read %s from standard input: %v", strings.TrimSuffix(prompt, ": "), err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func printSecretsUsage() {
+	fmt.Println("Usage: ipcrawler secrets list")
+	fmt.Println("       ipcrawler secrets set <name> [--tools LIST]")
+	fmt.Println("       ipcrawler secrets remove <name>")
+	fmt.Println()
+	fmt.Println("Keeps API keys and credentials for tool arguments in ~/.ipcrawler/secrets.enc,")
+	fmt.Println("encrypted with a passphrase (asked for, or IPCRAWLER_SECRETS_PASSPHRASE). Tool")
+	fmt.Println("configs use them as {{secret:name}}; values are redacted from logs, raw output")
+	fmt.Println("and reports. IPCRAWLER_SECRET_<NAME> environment variables work without the file.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --tools LIST   With set: tools whose arguments may use the secret (default: every tool)")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler secrets set shodan_api_key --tools subfinder")
+	fmt.Println("  IPCRAWLER_SECRET_CENSYS_API_ID=... ipcrawler 10.10.10.10")
+}
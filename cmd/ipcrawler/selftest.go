@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/selftest"
+)
+
+// selftestResult is the --json output of the self-test
+type selftestResult struct {
+	Workspace string              `json:"workspace,omitempty"`
+	Fixtures  []*selftest.Fixture `json:"fixtures"`
+	Tools     []toolCheck         `json:"tools"`
+	Checks    []selftest.Check    `json:"checks"`
+	Passed    bool                `json:"passed"`
+}
+
+// runSelftestCommand scans local fixtures with the default workflows and checks the findings
+func runSelftestCommand(args []string) error {
+	fs := pflag.NewFlagSet("selftest", pflag.ContinueOnError)
+	keep := fs.Bool("keep", false, "Keep the self-test workspace even when every check passes")
+	verbose := fs.BoolP("verbose", "v", false, "Show tool progress while the workflows run")
+	asJSON := fs.Bool("json", false, "Print the results as JSON")
+	fs.Usage = printSelftestUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return fmt.Errorf("failed to discover workflows: %v", err)
+	}
+
+	// Tools that are not ready would fail every workflow; report them the way doctor does.
+	// The self-test scans one host, so host discovery is not checked
+	usage := toolUsage(cfg, workflows)
+	for tool, users := range usage {
+		users = slices.DeleteFunc(users, func(user string) bool { return user == "host discovery" })
+		if len(users) == 0 {
+			delete(usage, tool)
+		} else {
+			usage[tool] = users
+		}
+	}
+	result := selftestResult{Tools: checkTools(executor.NewToolExecutionEngine(cfg, "", output.OutputModeNormal), usage)}
+	if !*asJSON {
+		printToolChecks(result.Tools)
+	}
+	var notReady []string
+	for _, check := range result.Tools {
+		if check.Error != "" {
+			notReady = append(notReady, check.Tool)
+		}
+	}
+	if len(notReady) > 0 {
+		if *asJSON {
+			printSelftestJSON(result)
+		}
+		return fmt.Errorf("not ready: %s; run 'ipcrawler doctor' for install commands", strings.Join(notReady, ", "))
+	}
+
+	fixtures := selftest.StartFixtures()
+	defer fixtures.Close()
+	result.Fixtures = fixtures.Fixtures
+	if !*asJSON {
+		fmt.Println()
+		for _, fixture := range fixtures.Fixtures {
+			if fixture.Running() {
+				fmt.Printf("  %s %-8s on %s\n", output.FormatStatus(output.StatusRunning, "fixture"), fixture.Name, fixture.Address())
+			} else {
+				fmt.Printf("  %s %-8s on %s: %s\n", output.FormatStatus(output.StatusSkipped, "skipped"), fixture.Name, fixture.Address(), fixture.Error)
+			}
+		}
+		fmt.Println()
+	}
+	if len(fixtures.Running()) == 0 {
+		return fmt.Errorf("no fixture could listen on %s", selftest.Host)
+	}
+
+	outputDir, err := os.MkdirTemp("", "ipcrawler-selftest-")
+	if err != nil {
+		return fmt.Errorf("failed to create self-test directory: %v", err)
+	}
+	outputMode := output.OutputModeNormal
+	if *verbose {
+		outputMode = output.OutputModeVerbose
+	}
+	hooks := &scanHooks{NoHistory: true, OnWorkspace: func(workspaceDir string) { result.Workspace = workspaceDir }}
+	stdout := os.Stdout
+	if *asJSON {
+		os.Stdout = os.Stderr // Keep the scan's console output out of the JSON
+	}
+	runErr := runCLI(selftest.Host, outputMode, outputDir, scope.NewExclusionList(), []string{"selftest"}, false, hooks)
+	os.Stdout = stdout
+	if result.Workspace == "" {
+		os.RemoveAll(outputDir)
+		return fmt.Errorf("self-test scan did not start: %v", runErr)
+	}
+
+	report, err := output.LoadRunReport(result.Workspace)
+	if err != nil {
+		return fmt.Errorf("self-test scan wrote no report (workspace %s): %v", result.Workspace, err)
+	}
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	found, _, err := catalog.LoadWorkspace(result.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to load self-test findings: %v", err)
+	}
+
+	result.Checks = selftest.Verify(report, found, fixtures.Running())
+	failed := selftest.Failed(result.Checks)
+	result.Passed = failed == 0
+	if *asJSON {
+		printSelftestJSON(result)
+	} else {
+		fmt.Println()
+		for _, check := range result.Checks {
+			status, label := output.StatusOK, "pass"
+			if !check.Passed {
+				status, label = output.StatusFailed, "FAIL"
+			}
+			line := fmt.Sprintf("  %s %s", output.FormatStatus(status, label), check.Name)
+			if check.Detail != "" {
+				line += ": " + check.Detail
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed; workspace kept at %s", failed, len(result.Checks), result.Workspace)
+	}
+	if *keep {
+		fmt.Fprintf(os.Stderr, "Self-test workspace: %s\n", result.Workspace)
+	} else {
+		os.RemoveAll(outputDir)
+	}
+	if !*asJSON {
+		fmt.Printf("\nSelf-test passed: %d checks against %s\n", len(result.Checks), strings.Join(fixtureAddresses(fixtures.Running()), ", "))
+	}
+	return nil
+}
+
+func printSelftestUsage() {
+	fmt.Println("Usage: ipcrawler selftest [options]")
+	fmt.Println()
+	fmt.Println("Verifies the install end to end: starts local fixtures on 127.0.0.1 (an SSH")
+	fmt.Println("banner on 22 and web servers on 80 and 8080), runs the default workflows")
+	fmt.Println("against them in a temporary workspace, and checks that the run completed and")
+	fmt.Println("that each fixture was found open with the right service. Ports below 1024")
+	fmt.Println("need root, and a port already in use is skipped. Tools that are not ready")
+	fmt.Println("are reported as by 'ipcrawler doctor' before anything runs. A failed")
+	fmt.Println("self-test keeps its workspace for inspection and exits non-zero.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --keep              Keep the workspace when every check passes")
+	fmt.Println("  -v, --verbose           Show tool progress while the workflows run")
+	fmt.Println("      --json              Print machine-readable JSON")
+}
+
+func printSelftestJSON(result selftestResult) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+}
+
+func fixtureAddresses(fixtures []*selftest.Fixture) []string {
+	addresses := make([]string, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		addresses = append(addresses, fixture.Address())
+	}
+	return addresses
+}
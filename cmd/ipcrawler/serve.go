@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/api"
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// defaultServeAddress keeps the API on loopback unless another address is given
+const defaultServeAddress = "127.0.0.1:8080"
+
+// runServeCommand exposes the orchestrator over HTTP so other tooling can queue scans
+func runServeCommand(args []string) error {
+	fs := pflag.NewFlagSet("serve", pflag.ContinueOnError)
+	var (
+		listen      = fs.String("listen", defaultServeAddress, "Address to listen on")
+		tokenEnv    = fs.String("token-env", "IPCRAWLER_API_TOKEN", "Environment variable holding the bearer token")
+		outputDir   = fs.StringP("output", "o", "", "Output directory for scan results")
+		exclude     = fs.String("exclude", "", "Comma-separated hosts, IPs or CIDRs that must never be scanned")
+		excludeFile = fs.String("exclude-file", "", "File of hosts, IPs or CIDRs to exclude (one per line)")
+		scopeFile   = fs.String("scope", "", "Scope file of the networks and domains that may be scanned")
+	)
+	fs.Usage = printServeUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	if err := output.ValidateResultsEncoding(cfg.Output.ResultsEncoding); err != nil {
+		return err
+	}
+
+	token := envOrEmpty(*tokenEnv)
+	if token == "" && !isLoopbackAddress(*listen) {
+		return fmt.Errorf("refusing to listen on %s without a token; set $%s or listen on loopback", *listen, *tokenEnv)
+	}
+
+	exclusions := scope.NewExclusionList()
+	if err := exclusions.AddList(*exclude); err != nil {
+		return err
+	}
+	if *excludeFile != "" {
+		if err := exclusions.LoadFile(*excludeFile); err != nil {
+			return err
+		}
+	}
+	engagement, err := loadEngagement(*scopeFile, exclusions)
+	if err != nil {
+		return err
+	}
+
+	userConfig, err := userconfig.LoadUserConfig()
+	if err != nil {
+		userConfig = &userconfig.UserConfig{}
+	}
+	effectiveOutputDir := userConfig.GetEffectiveOutputDirectory(*outputDir, "")
+	if effectiveOutputDir != "" {
+		if effectiveOutputDir, err = filepath.Abs(effectiveOutputDir); err != nil {
+			return fmt.Errorf("invalid output directory path: %v", err)
+		}
+		if err := os.MkdirAll(effectiveOutputDir, 0755); err != nil {
+			return fmt.Errorf("cannot create output directory %s: %v", effectiveOutputDir, err)
+		}
+	}
+	setGlobalOutputController(output.NewOutputController(output.OutputModeNormal))
+
+	server := api.NewServer(api.Options{
+		Token:           token,
+		ResultsEncoding: cfg.Output.ResultsEncoding,
+		Validate: func(request api.ScanRequest) error {
+			if err := validateScanRequest(request, exclusions); err != nil {
+				return err
+			}
+			if err := refuseTarget(engagement, request.Target, ""); err != nil {
+				return fmt.Errorf("target refused: %v", err)
+			}
+			return nil
+		},
+		Runner: func(ctx context.Context, progress *api.Progress) error {
+			request := progress.Request()
+			hooks := &scanHooks{
+				Context:     ctx,
+				ScanID:      progress.ID(),
+				Workflows:   request.Workflows,
+				Engagement:  engagement,
+				OnWorkspace: progress.SetWorkspace,
+				OnEvent:     progress.Event,
+				OnExecution: progress.SetExecutionStatus,
+				OnWarning: func(warning output.RunWarning) {
+					progress.Warning(warning.Kind, fmt.Sprintf("%s %s: %s", warning.Tool, warning.Mode, warning.Message))
+				},
+			}
+			return runCLI(request.Target, output.OutputModeNormal, effectiveOutputDir, exclusions, scope.MergeLabels(request.Labels), false, hooks)
+		},
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go server.Run(ctx)
+
+	httpServer := &http.Server{
+		Addr:              *listen,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "IPCrawler API listening on http://%s\n", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func printServeUsage() {
+	fmt.Println("Usage: ipcrawler serve [options]")
+	fmt.Println()
+	fmt.Println("Runs an HTTP API that queues scans on the same engine as the CLI. Scans run")
+	fmt.Println("one after another; each scan runs its workflows concurrently as usual.")
+	fmt.Println()
+	fmt.Println("Endpoints:")
+	fmt.Println("  POST /scans               Queue a scan: {\"target\": \"10.10.10.5\", \"workflows\": [\"port-scanning\"]}")
+	fmt.Println("  GET  /scans               List scans")
+	fmt.Println("  GET  /scans/{id}          Status and per-workflow step progress")
+	fmt.Println("  GET  /scans/{id}/results  Run report, in output.results_encoding unless Accept says otherwise")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --listen ADDR       Address to listen on (default " + defaultServeAddress + ")")
+	fmt.Println("      --token-env NAME    Variable holding the bearer token (default IPCRAWLER_API_TOKEN)")
+	fmt.Println("  -o, --output DIR        Output directory for scan results")
+	fmt.Println("      --exclude LIST      Hosts, IPs or CIDRs that must never be scanned")
+	fmt.Println("      --exclude-file FILE File of hosts, IPs or CIDRs to exclude")
+	fmt.Println("      --scope FILE        Scope file; hosts outside it are refused and audited")
+	fmt.Println()
+	fmt.Println("A token is required to listen on a non-loopback address.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler serve")
+	fmt.Println("  IPCRAWLER_API_TOKEN=s3cret ipcrawler serve --listen 0.0.0.0:8080")
+}
+
+// validateScanRequest rejects malformed or excluded targets and unknown workflows before queueing
+func validateScanRequest(request api.ScanRequest, exclusions *scope.ExclusionList) error {
+	target := request.Target
+	if net.ParseIP(target) == nil && !isValidHostname(target) {
+		if _, _, err := net.ParseCIDR(target); err != nil {
+			return fmt.Errorf("invalid target '%s': must be an IP address, CIDR or hostname", target)
+		}
+	}
+	if err := exclusions.CheckResolved(target, nil); err != nil {
+		return fmt.Errorf("target refused: %w", err)
+	}
+	if len(request.Workflows) > 0 {
+		workflows, err := discoverAllWorkflows()
+		if err != nil {
+			return fmt.Errorf("failed to discover workflows: %v", err)
+		}
+		if _, err := selectWorkflows(workflows, request.Workflows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectWorkflows keeps the named workflows, matched by file name or title (case-insensitive)
+func selectWorkflows(workflows map[string]*executor.Workflow, names []string) (map[string]*executor.Workflow, error) {
+	selected := make(map[string]*executor.Workflow)
+	for _, name := range names {
+		found := false
+		for key, workflow := range workflows {
+			if strings.EqualFold(key, name) || strings.EqualFold(workflow.Name, name) {
+				selected[key] = workflow
+				found = true
+			}
+		}
+		if !found {
+			available := make([]string, 0, len(workflows))
+			for key := range workflows {
+				available = append(available, key)
+			}
+			sort.Strings(available)
+			return nil, fmt.Errorf("unknown workflow '%s' (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// isLoopbackAddress reports whether a listen address only accepts local connections
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/integrations/elastic"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// runShipCommand indexes the results of existing workspaces into Elasticsearch/OpenSearch
+func runShipCommand(args []string) error {
+	fs := pflag.NewFlagSet("ship", pflag.ContinueOnError)
+	url := fs.String("url", "", "Cluster URL (default from configs/integrations.yaml)")
+	fs.Usage = printShipUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printShipUsage()
+		return fmt.Errorf("at least one workspace is required")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	if *url != "" {
+		cfg.Integrations.Elasticsearch.URL = *url
+	}
+
+	for _, arg := range fs.Args() {
+		workspaceDir, err := resolveWorkspace(arg)
+		if err != nil {
+			return err
+		}
+		indexed, err := shipWorkspace(cfg, workspaceDir)
+		if err != nil {
+			return fmt.Errorf("%s: %v", workspaceDir, err)
+		}
+		fmt.Printf("%s: %d documents indexed\n", workspaceDir, indexed)
+	}
+	return nil
+}
+
+func printShipUsage() {
+	fmt.Println("Usage: ipcrawler ship [options] <workspace>...")
+	fmt.Println()
+	fmt.Println("Indexes each workspace's findings and tool executions into Elasticsearch or")
+	fmt.Println("OpenSearch for dashboards over recurring scans. Re-shipping a workspace")
+	fmt.Println("updates its documents instead of duplicating them.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --url URL           Cluster URL (default: integrations.elasticsearch.url)")
+	fmt.Println()
+	fmt.Println("Set integrations.elasticsearch.enabled to ship every run automatically.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler ship ipcrawler_results/*")
+	fmt.Println("  ipcrawler ship --url https://localhost:9200 <workspace>")
+}
+
+// shipWorkspace sends one workspace's findings and executions to the configured cluster
+func shipWorkspace(cfg *config.Config, workspaceDir string) (int, error) {
+	settings := cfg.Integrations.Elasticsearch
+	sink, err := elastic.NewSink(elastic.Options{
+		URL:                settings.URL,
+		FindingsIndex:      settings.FindingsIndex,
+		ExecutionsIndex:    settings.ExecutionsIndex,
+		Username:           settings.Username,
+		Password:           envOrEmpty(settings.PasswordEnv),
+		APIKey:             envOrEmpty(settings.APIKeyEnv),
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+		Timeout:            time.Duration(settings.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Older workspaces have no report.json; their findings are still shipped
+	runReport, err := output.LoadRunReport(workspaceDir)
+	if err != nil {
+		runReport = &output.RunReport{Target: filepath.Base(workspaceDir), Workspace: workspaceDir}
+		if manifest, err := session.LoadManifest(workspaceDir); err == nil {
+			runReport.ScanID, runReport.Target, runReport.StartedAt = manifest.ScanID, manifest.Target, manifest.StartedAt
+		}
+	}
+	if runReport.ScanID == "" {
+		runReport.ScanID = filepath.Base(workspaceDir)
+	}
+
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	list, _, err := catalog.LoadWorkspace(workspaceDir)
+	if err != nil {
+		list = nil // No scans directory; executions are still shipped
+	}
+	var workspaceLabels []string
+	if manifest, err := session.LoadManifest(workspaceDir); err == nil {
+		workspaceLabels = manifest.Labels
+	}
+	if labeler, err := buildLabeler(cfg); err == nil {
+		findings.ApplyLabels(list, labeler.LabelsFor, workspaceLabels)
+	}
+
+	return sink.Ship(context.Background(), *runReport, list)
+}
+
+// shipRunResults ships a finished run when automatic shipping is enabled
+func shipRunResults(cfg *config.Config, workspaceDir string, logger *log.Logger) {
+	if !cfg.Integrations.Elasticsearch.Enabled {
+		return
+	}
+	indexed, err := shipWorkspace(cfg, workspaceDir)
+	if err != nil {
+		logger.Warn("Failed to ship results to Elasticsearch", "error", err)
+		return
+	}
+	logger.Info("Results shipped to Elasticsearch", "documents", indexed)
+}
+
+// envOrEmpty reads an environment variable if a name is configured
+func envOrEmpty(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// shutdownWatch cancels a run on SIGINT/SIGTERM and remembers the signal that stopped it
+type shutdownWatch struct {
+	mutex  sync.Mutex
+	signal string // Name of the signal received, e.g. SIGINT; empty until one arrives
+	at     time.Time
+	engine *executor.ToolExecutionEngine
+}
+
+// watchShutdownSignals returns a context cancelled by the first SIGINT or SIGTERM. Tools run
+// in their own process groups, so a terminal's Ctrl-C no longer reaches them: cancelling
+// the run is what stops them, with SIGTERM and then SIGKILL after the engine's grace period.
+// A second signal exits immediately. logger may be nil. The returned function stops watching
+func watchShutdownSignals(parent context.Context, engine *executor.ToolExecutionEngine, logger *log.Logger) (context.Context, *shutdownWatch, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	watch := &shutdownWatch{engine: engine}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-sigChan:
+			// Restore the default handling so the next signal ends the process
+			signal.Stop(sigChan)
+			watch.mutex.Lock()
+			watch.signal, watch.at = signalName(sig), time.Now().Round(0)
+			watch.mutex.Unlock()
+
+			grace := engine.ShutdownGrace()
+			running := engine.RunningTools()
+			if logger != nil {
+				logger.Warn("Shutting down", "signal", signalName(sig), "running_tools", strings.Join(running, ", "), "grace", grace)
+			}
+			fmt.Fprintf(os.Stderr, "\nipcrawler: %s received, stopping %d running tool(s) (up to %s); interrupt again to exit immediately\n", signalName(sig), len(running), grace)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, watch, func() {
+		signal.Stop(sigChan)
+		cancel()
+	}
+}
+
+// received returns the name of the signal that stopped the run and when it arrived; "" if there was none
+func (w *shutdownWatch) received() (string, time.Time) {
+	if w == nil {
+		return "", time.Time{}
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.signal, w.at
+}
+
+// writeShutdownMarker records an interrupted run in its workspace so --resume-queue can continue it
+func writeShutdownMarker(workspaceDir string, watch *shutdownWatch, fileMode os.FileMode, logger *log.Logger) {
+	sig, at := watch.received()
+	if sig == "" {
+		return
+	}
+	marker := &session.ShutdownMarker{
+		Signal:       sig,
+		StoppedAt:    at,
+		GraceSeconds: watch.engine.ShutdownGrace().Seconds(),
+		StoppedTools: watch.engine.StoppedTools(),
+	}
+	if queue, err := executor.LoadQueueState(workspaceDir); err == nil {
+		marker.Unfinished = len(queue.Items)
+	}
+	if err := session.WriteShutdownMarker(workspaceDir, marker, fileMode); err != nil {
+		logger.Warn("Failed to write shutdown marker", "error", err)
+		return
+	}
+	logger.Info("Run interrupted", "signal", marker.Signal, "stopped_tools", len(marker.StoppedTools), "unfinished_workflows", marker.Unfinished)
+	if marker.Unfinished > 0 {
+		fmt.Fprintf(os.Stderr, "ipcrawler: %d unfinished workflow(s) saved; continue with: ipcrawler --resume-queue %s\n", marker.Unfinished, workspaceDir)
+	}
+}
+
+// signalName returns the conventional name of a shutdown signal
+func signalName(sig os.Signal) string {
+	switch sig {
+	case os.Interrupt:
+		return "SIGINT"
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	}
+	return sig.String()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/charmbracelet/log"
+	"github.com/neur0map/ipcrawler/internal/executor"
+)
+
+// watchSnapshotSignals writes an orchestrator snapshot to dir each time a snapshot
+// signal arrives, without interrupting the scan. The returned function stops watching
+func watchSnapshotSignals(orchestrator *executor.WorkflowOrchestrator, dir string, logger *log.Logger) func() {
+	if len(snapshotSignals) == 0 {
+		return func() {}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, snapshotSignals...)
+
+	go func() {
+		for {
+			select {
+			case sig := <-sigChan:
+				path, err := orchestrator.WriteSnapshot(dir)
+				if err != nil {
+					logger.Error("Failed to write state snapshot", "signal", sig.String(), "error", err)
+					fmt.Fprintf(os.Stderr, "ipcrawler: failed to write state snapshot: %v\n", err)
+					continue
+				}
+				logger.Info("State snapshot written", "signal", sig.String(), "path", path)
+				fmt.Fprintf(os.Stderr, "ipcrawler: state snapshot written to %s\n", path)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// snapshotSignals trigger a state snapshot. SIGQUIT normally kills a Go program
+// with a stack dump; catching it keeps the scan running instead
+var snapshotSignals = []os.Signal{syscall.SIGQUIT, syscall.SIGUSR1}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// snapshotSignals is empty on Windows, which has no SIGQUIT or SIGUSR1
+var snapshotSignals []os.Signal
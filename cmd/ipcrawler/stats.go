@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/estimate"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/usage"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+	"github.com/spf13/pflag"
+)
+
+// runStatsCommand implements `ipcrawler stats --usage`
+func runStatsCommand(args []string) error {
+	fs := pflag.NewFlagSet("stats", pflag.ContinueOnError)
+	var (
+		showUsage = fs.Bool("usage", false, "Summarize how often each workflow and tool ran and how long it took")
+		asJSON    = fs.Bool("json", false, "Print the usage database as JSON")
+		reset     = fs.Bool("reset", false, "Delete the recorded usage statistics")
+	)
+	fs.Usage = printStatsUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*showUsage && !*reset {
+		printStatsUsage()
+		return fmt.Errorf("--usage or --reset is required")
+	}
+
+	path, err := usagePath()
+	if err != nil {
+		return err
+	}
+	if *reset {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete usage stats: %v", err)
+		}
+		fmt.Printf("Usage statistics cleared (%s)\n", path)
+		return nil
+	}
+
+	stats, err := usage.Load(path)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	printUsageStats(stats)
+	return nil
+}
+
+// usagePath is the usage database in the user's IPCrawler directory
+func usagePath() (string, error) {
+	dir, err := userconfig.Dir()
+	if err != nil {
+		return "", err
+	}
+	return usage.Path(dir), nil
+}
+
+// printUsageStats prints workflow and tool tables, then the workflows that never ran
+func printUsageStats(stats *usage.Stats) {
+	if stats.Runs == 0 {
+		fmt.Println("No usage recorded yet.")
+		fmt.Println("Enable it with output.usage_stats: true in configs/output.yaml; nothing is ever sent anywhere.")
+		return
+	}
+
+	fmt.Printf("Usage statistics: %d run(s) since %s (local only)\n\n", stats.Runs, stats.Since.Format("2006-01-02"))
+	printUsageTable("Workflow", usage.Sorted(stats.Workflows))
+	fmt.Println()
+	printUsageTable("Tool", usage.Sorted(stats.Tools))
+
+	// Workflows that exist but never ran are the candidates for pruning
+	workflows, err := discoverAllWorkflows()
+	if err != nil {
+		return
+	}
+	var unused []string
+	for _, workflow := range workflows {
+		if stats.Workflows[workflow.Name] == nil {
+			unused = append(unused, workflow.Name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		fmt.Println()
+		fmt.Println("Never used:")
+		for _, name := range unused {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+}
+
+// printUsageTable prints one table of usage entries
+func printUsageTable(kind string, entries []usage.NamedEntry) {
+	width := len(kind)
+	for _, entry := range entries {
+		if len(entry.Name) > width {
+			width = len(entry.Name)
+		}
+	}
+	fmt.Printf("%-*s  %6s  %8s  %12s  %s\n", width, kind, "Uses", "Failures", "Avg duration", "Last used")
+	for _, entry := range entries {
+		average := time.Duration(entry.AverageSeconds() * float64(time.Second)).Round(100 * time.Millisecond)
+		fmt.Printf("%-*s  %6d  %8d  %12s  %s\n", width, entry.Name, entry.Uses, entry.Failures, average, entry.LastUsed.Format("2006-01-02"))
+	}
+}
+
+// recordUsage adds a finished run to the local usage database when output.usage_stats is enabled
+func recordUsage(cfg *config.Config, report output.RunReport, logger *log.Logger) {
+	if !cfg.Output.UsageStats {
+		return
+	}
+	path, err := usagePath()
+	if err != nil {
+		logger.Warn("Failed to locate usage stats", "error", err)
+		return
+	}
+	stats, err := usage.Load(path)
+	if err != nil {
+		logger.Warn("Failed to load usage stats", "error", err)
+		return
+	}
+	stats.Record(report)
+	if err := stats.Save(path); err != nil {
+		logger.Warn("Failed to save usage stats", "error", err)
+	}
+}
+
+// loadDurationHistory reads the tool durations workflow ETAs are estimated from; nil when
+// there is no usable history
+func loadDurationHistory(logger *log.Logger) *estimate.History {
+	dir, err := userconfig.Dir()
+	if err != nil {
+		return nil
+	}
+	history, err := estimate.Load(estimate.Path(dir))
+	if err != nil {
+		logger.Warn("Failed to load duration history", "error", err)
+		return nil
+	}
+	return history
+}
+
+// recordDurations adds a finished run's tool durations to the history later ETAs use
+func recordDurations(report output.RunReport, logger *log.Logger) {
+	dir, err := userconfig.Dir()
+	if err != nil {
+		logger.Warn("Failed to locate duration history", "error", err)
+		return
+	}
+	path := estimate.Path(dir)
+	history, err := estimate.Load(path)
+	if err != nil {
+		logger.Warn("Failed to load duration history", "error", err)
+		return
+	}
+	history.Record(report)
+	if err := history.Save(path); err != nil {
+		logger.Warn("Failed to save duration history", "error", err)
+	}
+}
+
+func printStatsUsage() {
+	fmt.Println("Usage: ipcrawler stats --usage [--json]")
+	fmt.Println("       ipcrawler stats --reset")
+	fmt.Println()
+	fmt.Println("Summarizes how often each workflow and tool ran, how often it failed and its")
+	fmt.Println("average duration, and lists workflows that never ran, to help prune the")
+	fmt.Println("workflow set. Statistics are recorded only when output.usage_stats is enabled")
+	fmt.Println("and are kept in ~/.ipcrawler/usage.json; nothing is ever sent anywhere.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --usage             Show the usage summary")
+	fmt.Println("      --json              Print the raw usage database as JSON")
+	fmt.Println("      --reset             Delete the recorded statistics")
+}
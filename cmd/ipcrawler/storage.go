@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/report"
+	"github.com/neur0map/ipcrawler/internal/storage"
+)
+
+// openStorage connects to the backend configured in configs/storage.yaml
+func openStorage(cfg *config.Config) (storage.Backend, error) {
+	switch cfg.Storage.Backend {
+	case "local":
+		return storage.NewLocal(cfg.Storage.Local.Directory)
+	case "s3":
+		return openS3(cfg.Storage.S3)
+	case "":
+		return nil, fmt.Errorf("no storage backend configured (storage.backend in configs/storage.yaml)")
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use local or s3)", cfg.Storage.Backend)
+	}
+}
+
+// openS3 connects to a bucket with the credentials named in the configuration, by default
+// the standard AWS environment variables
+func openS3(settings config.S3StorageConfig) (storage.Backend, error) {
+	if settings.AccessKeyEnv == "" {
+		settings.AccessKeyEnv = "AWS_ACCESS_KEY_ID"
+	}
+	if settings.SecretKeyEnv == "" {
+		settings.SecretKeyEnv = "AWS_SECRET_ACCESS_KEY"
+	}
+	return storage.NewS3(storage.S3Options{
+		Endpoint:           settings.Endpoint,
+		Region:             settings.Region,
+		Bucket:             settings.Bucket,
+		PathStyle:          settings.PathStyle,
+		AccessKey:          envOrEmpty(settings.AccessKeyEnv),
+		SecretKey:          envOrEmpty(settings.SecretKeyEnv),
+		SessionToken:       envOrEmpty(settings.SessionTokenEnv),
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+		Timeout:            time.Duration(settings.TimeoutSeconds) * time.Second,
+	})
+}
+
+// isRemoteWorkspace reports whether a workspace argument names a stored workspace
+func isRemoteWorkspace(arg string) bool {
+	return strings.HasPrefix(arg, "s3://")
+}
+
+// storedWorkspace finds the backend and key of a stored workspace: an s3://bucket/key URL
+// (any bucket reachable with the configured credentials) or a workspace name under the
+// configured backend's prefix
+func storedWorkspace(cfg *config.Config, arg string) (storage.Backend, string, error) {
+	if bucket, key, ok := storage.ParseS3URL(arg); ok {
+		if key == "" {
+			return nil, "", fmt.Errorf("%s names a bucket, not a workspace", arg)
+		}
+		settings := cfg.Storage.S3
+		settings.Bucket = bucket
+		backend, err := openS3(settings)
+		return backend, key, err
+	}
+	if isRemoteWorkspace(arg) {
+		return nil, "", fmt.Errorf("invalid storage URL %q", arg)
+	}
+	backend, err := openStorage(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return backend, storage.JoinKey(cfg.Storage.Prefix, arg), nil
+}
+
+// workspaceKey is where a local workspace is stored: the prefix and the directory's name
+func workspaceKey(cfg *config.Config, workspaceDir string) string {
+	return storage.JoinKey(cfg.Storage.Prefix, filepath.Base(workspaceDir))
+}
+
+// uploadRunWorkspace stores a finished run's workspace when upload_after_run is set, and
+// removes the local copy when remove_after_upload is set too
+func uploadRunWorkspace(cfg *config.Config, workspaceDir string, logger *log.Logger) {
+	if !cfg.Storage.UploadAfterRun {
+		return
+	}
+	backend, err := openStorage(cfg)
+	if err != nil {
+		logger.Warn("Failed to open workspace storage", "error", err)
+		return
+	}
+	key := workspaceKey(cfg, workspaceDir)
+	uploaded, err := storage.UploadDir(context.Background(), backend, workspaceDir, key)
+	if err != nil {
+		logger.Warn("Failed to store workspace", "storage", backend.URL(key), "error", err)
+		return
+	}
+	logger.Info("Workspace stored", "storage", backend.URL(key), "files", uploaded)
+	if cfg.Storage.RemoveAfterUpload {
+		if err := os.RemoveAll(workspaceDir); err != nil {
+			logger.Warn("Failed to remove local workspace", "workspace", workspaceDir, "error", err)
+		}
+	}
+}
+
+// remoteWorkspace is a stored workspace downloaded into a temporary directory
+type remoteWorkspace struct {
+	backend storage.Backend
+	key     string
+	dir     string
+}
+
+// fetchWorkspace downloads a stored workspace below tmpRoot for commands that read workspaces
+func fetchWorkspace(cfg *config.Config, arg, tmpRoot string) (*remoteWorkspace, error) {
+	backend, key, err := storedWorkspace(cfg, arg)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(tmpRoot, "")
+	if err != nil {
+		return nil, err
+	}
+	dir = filepath.Join(dir, path.Base(key)) // Reports name the workspace after its directory
+	if _, err := storage.DownloadDir(context.Background(), backend, key, dir, cfg.Output.Permissions.DirPerm(), cfg.Output.Permissions.FilePerm()); err != nil {
+		return nil, err
+	}
+	return &remoteWorkspace{backend: backend, key: key, dir: dir}, nil
+}
+
+// storeReports uploads the reports generated in a fetched workspace back next to it
+func (w *remoteWorkspace) storeReports() (string, error) {
+	ctx := context.Background()
+	reportsKey := storage.JoinKey(w.key, "reports")
+	if _, err := os.Stat(filepath.Join(w.dir, "reports")); err == nil {
+		if _, err := storage.UploadDir(ctx, w.backend, filepath.Join(w.dir, "reports"), reportsKey); err != nil {
+			return "", err
+		}
+	}
+	index := filepath.Join(w.dir, report.IndexFileName)
+	if _, err := os.Stat(index); err == nil {
+		if err := storage.UploadFile(ctx, w.backend, index, storage.JoinKey(w.key, report.IndexFileName)); err != nil {
+			return "", err
+		}
+	}
+	return w.backend.URL(reportsKey), nil
+}
+
+// runStorageCommand implements `ipcrawler storage list|push|pull`
+func runStorageCommand(args []string) error {
+	fs := pflag.NewFlagSet("storage", pflag.ContinueOnError)
+	fs.Usage = printStorageUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printStorageUsage()
+		return fmt.Errorf("a storage command is required")
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+
+	switch action := fs.Arg(0); action {
+	case "list":
+		if fs.NArg() > 2 {
+			return fmt.Errorf("usage: ipcrawler storage list [name-prefix]")
+		}
+		return listStoredWorkspaces(cfg, fs.Arg(1))
+	case "push":
+		if fs.NArg() < 2 {
+			return fmt.Errorf("usage: ipcrawler storage push <workspace>...")
+		}
+		backend, err := openStorage(cfg)
+		if err != nil {
+			return err
+		}
+		for _, arg := range fs.Args()[1:] {
+			workspaceDir, err := resolveWorkspace(arg)
+			if err != nil {
+				return err
+			}
+			key := workspaceKey(cfg, workspaceDir)
+			uploaded, err := storage.UploadDir(context.Background(), backend, workspaceDir, key)
+			if err != nil {
+				return fmt.Errorf("%s: %v", workspaceDir, err)
+			}
+			fmt.Printf("%s: %d files stored in %s\n", workspaceDir, uploaded, backend.URL(key))
+		}
+		return nil
+	case "pull":
+		if fs.NArg() < 2 || fs.NArg() > 3 {
+			return fmt.Errorf("usage: ipcrawler storage pull <workspace | s3://bucket/key> [directory]")
+		}
+		backend, key, err := storedWorkspace(cfg, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		dir := fs.Arg(2)
+		if dir == "" {
+			dir = path.Base(key)
+		}
+		if _, err := os.Stat(dir); err == nil {
+			return fmt.Errorf("%s already exists", dir)
+		}
+		downloaded, err := storage.DownloadDir(context.Background(), backend, key, dir, cfg.Output.Permissions.DirPerm(), cfg.Output.Permissions.FilePerm())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d files written to %s\n", backend.URL(key), downloaded, dir)
+		return nil
+	default:
+		printStorageUsage()
+		return fmt.Errorf("unknown storage command %q", action)
+	}
+}
+
+// listStoredWorkspaces prints the workspaces under the configured prefix with their size
+func listStoredWorkspaces(cfg *config.Config, namePrefix string) error {
+	backend, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	prefix := storage.JoinKey(cfg.Storage.Prefix)
+	if prefix != "" {
+		prefix += "/"
+	}
+	objects, err := backend.List(context.Background(), prefix+namePrefix)
+	if err != nil {
+		return err
+	}
+
+	type storedSummary struct {
+		files    int
+		size     int64
+		modified time.Time
+	}
+	workspaces := make(map[string]*storedSummary)
+	for _, object := range objects {
+		name, _, found := strings.Cut(strings.TrimPrefix(object.Key, prefix), "/")
+		if !found {
+			continue // A file directly under the prefix is not a workspace
+		}
+		summary := workspaces[name]
+		if summary == nil {
+			summary = &storedSummary{}
+			workspaces[name] = summary
+		}
+		summary.files++
+		summary.size += object.Size
+		if object.Modified.After(summary.modified) {
+			summary.modified = object.Modified
+		}
+	}
+	if len(workspaces) == 0 {
+		fmt.Printf("No workspaces in %s\n", backend.URL(prefix))
+		return nil
+	}
+	names := make([]string, 0, len(workspaces))
+	for name := range workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("  %-48s  %6s  %10s  %s\n", "WORKSPACE", "FILES", "SIZE", "STORED")
+	for _, name := range names {
+		summary := workspaces[name]
+		fmt.Printf("  %-48s  %6d  %10s  %s\n", name, summary.files, formatStoredSize(summary.size), summary.modified.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func formatStoredSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func printStorageUsage() {
+	fmt.Println("Usage: ipcrawler storage list [name-prefix]")
+	fmt.Println("       ipcrawler storage push <workspace>...")
+	fmt.Println("       ipcrawler storage pull <workspace | s3://bucket/key> [directory]")
+	fmt.Println()
+	fmt.Println("Copies workspaces to and from the backend in configs/storage.yaml: a shared")
+	fmt.Println("directory or an S3-compatible bucket (AWS S3, MinIO). Workspaces are stored")
+	fmt.Println("under storage.prefix by directory name; set storage.upload_after_run to store")
+	fmt.Println("every run. Reports can be generated from stored workspaces with")
+	fmt.Println("`ipcrawler report s3://bucket/key`.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler storage push ipcrawler_results/*")
+	fmt.Println("  ipcrawler storage list 10_10_10_5")
+	fmt.Println("  ipcrawler storage pull 10_10_10_5_1735732800_1a2b3c4d")
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+)
+
+// expandShortFlags rewrites nmap-style flags pflag cannot parse (-iL) to their long form
+func expandShortFlags(args []string) []string {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case arg == "-iL":
+			expanded[i] = "--input-list"
+		case strings.HasPrefix(arg, "-iL="):
+			expanded[i] = "--input-list=" + strings.TrimPrefix(arg, "-iL=")
+		default:
+			expanded[i] = arg
+		}
+	}
+	return expanded
+}
+
+// buildTargetList combines a -iL file with targets given as arguments, expanding CIDRs,
+// dropping duplicates and skipping excluded hosts and, auditing them, hosts outside the scope file
+func buildTargetList(listFile string, args []string, exclusions *scope.ExclusionList, engagement *scope.Engagement, sessionName string) ([]string, error) {
+	targets, err := scope.LoadTargetList(listFile)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		seen[target] = true
+	}
+	for _, arg := range args {
+		hosts, err := scope.ExpandTarget(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			if !seen[host] {
+				seen[host] = true
+				targets = append(targets, host)
+			}
+		}
+	}
+
+	included := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if err := refuseTarget(engagement, target, sessionName); err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping out-of-scope target: %v\n", err)
+			continue
+		}
+		if err := exclusions.CheckResolved(target, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping excluded target: %v\n", err)
+			continue
+		}
+		included = append(included, target)
+	}
+	if len(included) == 0 {
+		return nil, fmt.Errorf("every target in %s is excluded or out of scope", listFile)
+	}
+	return included, nil
+}
+
+// targetListOptions configures a multi-target run
+type targetListOptions struct {
+	OutputMode        output.OutputMode
+	OutputDir         string
+	Exclusions        *scope.ExclusionList
+	Labels            []string
+	DropPrivileges    bool
+	ConcurrentTargets int                          // Overrides target_scheduling.max_concurrent_targets when > 0
+	RateLimit         int                          // Overrides target_scheduling.global_rate_limit when > 0
+	Pacing            string                       // Overrides tools.yaml pacing when set
+	ConflictPolicy    string                       // --resume/--overwrite/--new for targets that already have a workspace
+	Workflows         []string                     // Run only these workflows; all when empty
+	Parameters        map[string]map[string]string // Step parameter overrides keyed by "workflow/step"
+	SessionName       string                       // --session-name of every target's run
+	Profile           string                       // -p: select workflows by the profile's tag filter
+	Engagement        *scope.Engagement            // --scope file enforced for every target's run
+
+	// Optional callbacks when a target's workspace is created and when its scan ends
+	OnWorkspace func(target, workspaceDir string)
+	OnFinished  func(target string, err error)
+}
+
+// runTargetList scans each target into its own workspace through the target scheduler, which
+// bounds how many targets run at once and splits the global rate budget between them.
+// Within a target, workflows still run concurrently up to max_concurrent_workflows
+func runTargetList(targets []string, opts targetListOptions) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	scheduling := cfg.Tools.TargetScheduling
+	if opts.ConcurrentTargets > 0 {
+		scheduling.MaxConcurrentTargets = opts.ConcurrentTargets
+	}
+	if opts.RateLimit > 0 {
+		scheduling.GlobalRateLimit = opts.RateLimit
+	}
+	scheduler := executor.NewTargetScheduler(scheduling)
+	baseDir := opts.OutputDir
+	if baseDir == "" {
+		baseDir = cfg.Output.WorkspaceBase
+	}
+	plain := opts.OutputMode == output.OutputModePlain
+	if plain {
+		printRecord(nil, "event", "targets_started", "count", strconv.Itoa(len(targets)),
+			"concurrent", strconv.Itoa(scheduler.MaxConcurrentTargets()), "rate_limit", strconv.Itoa(scheduler.RateLimitFor(len(targets))))
+	} else {
+		fmt.Fprintf(os.Stderr, "Scanning %d target(s), %d at a time at %d packets/s each\n",
+			len(targets), scheduler.MaxConcurrentTargets(), scheduler.RateLimitFor(len(targets)))
+	}
+
+	var started atomic.Int32
+	errs := scheduler.Run(context.Background(), targets, func(ctx context.Context, target string, rateLimit int) (err error) {
+		if index := started.Add(1); plain {
+			printRecord(nil, "event", "target_started", "target", target, "index", strconv.Itoa(int(index)), "total", strconv.Itoa(len(targets)))
+		} else {
+			fmt.Fprintf(os.Stderr, "[%d/%d] Scanning %s\n", index, len(targets), target)
+		}
+		if opts.OnFinished != nil {
+			defer func() { opts.OnFinished(target, err) }()
+		}
+
+		// Existing workspaces follow --resume/--overwrite/--new; there is no prompt per target
+		resumeDir, err := prepareTargetWorkspace(baseDir, opts.SessionName, target, opts.ConflictPolicy, false)
+		if err != nil {
+			return err
+		}
+		if resumeDir != "" {
+			if opts.OnWorkspace != nil {
+				opts.OnWorkspace(target, resumeDir)
+			}
+			return runResumeQueue(resumeDir, opts.OutputMode, opts.OutputDir, opts.DropPrivileges)
+		}
+		hooks := &scanHooks{Context: ctx, RateLimit: rateLimit, Pacing: opts.Pacing, Workflows: opts.Workflows, Parameters: opts.Parameters, SessionName: opts.SessionName, Profile: opts.Profile, Engagement: opts.Engagement}
+		if opts.OnWorkspace != nil {
+			hooks.OnWorkspace = func(workspaceDir string) { opts.OnWorkspace(target, workspaceDir) }
+		}
+		return runCLI(target, opts.OutputMode, opts.OutputDir, opts.Exclusions, opts.Labels, opts.DropPrivileges, hooks)
+	})
+
+	var failed []string
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if plain {
+			printRecord(nil, "event", "target_failed", "target", targets[i], "error", err.Error())
+		} else {
+			fmt.Fprintf(os.Stderr, "Scan of %s failed: %v\n", targets[i], err)
+		}
+		failed = append(failed, targets[i])
+	}
+	if plain {
+		printRecord(nil, "event", "targets_finished", "count", strconv.Itoa(len(targets)), "failed", strconv.Itoa(len(failed)))
+	} else {
+		fmt.Fprintf(os.Stderr, "Scanned %d target(s), %d failed\n", len(targets), len(failed))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d target(s) failed: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
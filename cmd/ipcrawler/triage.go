@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/heuristics"
+	"github.com/neur0map/ipcrawler/internal/report"
+)
+
+// runTriageCommand writes a workspace's open ports as a Markdown checklist for manual review
+func runTriageCommand(args []string) error {
+	fs := pflag.NewFlagSet("triage", pflag.ContinueOnError)
+	var (
+		outputPath = fs.StringP("output", "o", "", "Checklist file (default: <workspace>/reports/triage.md; - for stdout)")
+		fresh      = fs.Bool("fresh", false, "Start with every box unticked instead of keeping the existing checklist's")
+	)
+	fs.Usage = printTriageUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		printTriageUsage()
+		return fmt.Errorf("exactly one workspace is required")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	workspaceDir, err := resolveWorkspace(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid labels configuration: %v", err)
+	}
+	detector, err := heuristics.New(cfg.Heuristics)
+	if err != nil {
+		return fmt.Errorf("invalid heuristics configuration: %v", err)
+	}
+	redactor, err := reportRedactor(cfg)
+	if err != nil {
+		return err
+	}
+
+	summary, err := report.LoadTarget(workspaceDir, catalog, labeler.LabelsFor)
+	if err != nil {
+		return err
+	}
+	for _, warning := range summary.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %s\n", warning)
+	}
+	summary.Anomalies = detector.Evaluate(summary.Findings)
+	summary = redactor.RedactCopy(summary).(*report.TargetSummary)
+	triage := report.BuildTriage(summary, cfg.Triage)
+
+	if *outputPath == "-" {
+		return report.RenderTriage(os.Stdout, triage)
+	}
+	path := *outputPath
+	if path == "" {
+		path = filepath.Join(workspaceDir, "reports", report.TriageFileName)
+	}
+
+	// Keep the boxes already ticked in the checklist being replaced
+	if previous, err := os.Open(path); err == nil && !*fresh {
+		err = triage.MarkDone(previous)
+		previous.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read existing checklist %s: %v", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), cfg.Output.Permissions.DirPerm()); err != nil {
+		return fmt.Errorf("failed to create checklist directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, cfg.Output.Permissions.FilePerm())
+	if err != nil {
+		return fmt.Errorf("failed to create checklist: %v", err)
+	}
+	err = report.RenderTriage(file, triage)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write checklist: %v", err)
+	}
+
+	done := 0
+	for _, host := range triage.Hosts {
+		for _, port := range host.Ports {
+			if port.Item.Done {
+				done++
+			}
+		}
+	}
+	fmt.Printf("Triage checklist: %s (%d hosts, %d of %d open ports ticked)\n", path, len(triage.Hosts), done, triage.PortCount)
+	return nil
+}
+
+func printTriageUsage() {
+	fmt.Println("Usage: ipcrawler triage [options] <workspace>")
+	fmt.Println()
+	fmt.Println("Turns the workspace's findings into a Markdown checklist for the manual phase of")
+	fmt.Println("an assessment: one checkbox per host and open port, with the suggested checks")
+	fmt.Println("of configs/triage.yaml and the report's review hints nested under it.")
+	fmt.Println("Regenerating the checklist (e.g. after a rescan) keeps the boxes already ticked.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -o, --output FILE       Checklist file (default: <workspace>/reports/triage.md; - for stdout)")
+	fmt.Println("      --fresh             Untick every box of the existing checklist")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler triage ipcrawler_results/10_10_10_5_...")
+	fmt.Println("  ipcrawler triage -o - <workspace> | less")
+}
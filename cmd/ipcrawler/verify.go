@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/api"
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/report"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// verifyFileName is where a verified run stores the result of the check
+const verifyFileName = "verify.json"
+
+// runVerifyCommand scans a target, or reads an existing run, and checks its open ports against
+// an approved exposure baseline
+func runVerifyCommand(args []string) error {
+	fs := pflag.NewFlagSet("verify", pflag.ContinueOnError)
+	var (
+		baselineFile = fs.String("baseline", "", "Approved exposure baseline (JSON)")
+		workspaceArg = fs.StringP("workspace", "w", "", "Check this existing run instead of scanning")
+		approve      = fs.Bool("approve", false, "Write the run's open ports as the baseline instead of checking")
+		strict       = fs.Bool("strict", false, "Fail when approved ports are not found open too")
+		asJSON       = fs.Bool("json", false, "Print the result as JSON")
+		workflows    = fs.StringSlice("workflow", nil, "Run only these workflows (file name or title; default: all)")
+		outputDir    = fs.StringP("output", "o", "", "Output directory for scan results")
+		verbose      = fs.BoolP("verbose", "v", false, "Show both logs and raw tool output")
+	)
+	fs.Usage = printVerifyUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *baselineFile == "" {
+		printVerifyUsage()
+		return fmt.Errorf("--baseline is required")
+	}
+	if (*workspaceArg == "") == (fs.NArg() != 1) {
+		printVerifyUsage()
+		return fmt.Errorf("give exactly one target to scan, or --workspace")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+	var baseline *report.ExposureBaseline
+	if !*approve {
+		if baseline, err = report.LoadExposureBaseline(*baselineFile); err != nil {
+			return err
+		}
+	}
+
+	labeler, err := buildLabeler(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid labels configuration: %v", err)
+	}
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+
+	// A scanned run is read as soon as its reports are written, before auto-archiving or an
+	// upload can remove the workspace, so the result is saved and archived with it
+	var summary *report.TargetSummary
+	var check *report.ExposureCheck
+	var loadErr error
+	inspect := func(workspaceDir string) {
+		if summary, loadErr = report.LoadTarget(workspaceDir, catalog, labeler.LabelsFor); loadErr != nil {
+			loadErr = fmt.Errorf("%s: %v", workspaceDir, loadErr)
+			return
+		}
+		if baseline != nil {
+			check = report.CheckExposure(baseline, summary)
+			saveExposureCheck(cfg, workspaceDir, check)
+		}
+	}
+	if *workspaceArg != "" {
+		workspaceDir, err := resolveWorkspace(*workspaceArg)
+		if err != nil {
+			return err
+		}
+		inspect(workspaceDir)
+	} else if err := runVerifyScan(fs.Arg(0), *workflows, *outputDir, *verbose, *asJSON, inspect); err != nil {
+		return err
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if *approve {
+		approved := report.ExposureBaselineFrom(summary)
+		if err := approved.Write(*baselineFile, cfg.Output.Permissions.FilePerm()); err != nil {
+			return fmt.Errorf("failed to write baseline: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Approved %d open ports of %s in %s\n", len(approved.Ports), summary.Target, *baselineFile)
+		return nil
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(check); err != nil {
+			return err
+		}
+	} else {
+		printExposureCheck(check, *baselineFile, len(baseline.Ports))
+	}
+	if check.Failed(*strict) {
+		return fmt.Errorf("exposure of %s differs from the baseline: %d unexpected, %d changed, %d missing", check.Target, check.Unexpected, check.Changed, check.Missing)
+	}
+	return nil
+}
+
+// saveExposureCheck stores the result of verify as reports/verify.json in the run's workspace
+func saveExposureCheck(cfg *config.Config, workspaceDir string, check *report.ExposureCheck) {
+	checkPath := filepath.Join(workspaceDir, workspace.ReportsDir, verifyFileName)
+	data, err := json.MarshalIndent(check, "", "  ")
+	if err == nil {
+		err = os.WriteFile(checkPath, data, cfg.Output.Permissions.FilePerm())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", checkPath, err)
+	}
+}
+
+// runVerifyScan scans the target for verify, calling inspect with the run's workspace once
+// its reports are written; the scan's console output goes to stderr when the result is
+// printed as JSON
+func runVerifyScan(target string, workflows []string, outputDir string, verbose, asJSON bool, inspect func(workspaceDir string)) error {
+	exclusions := scope.NewExclusionList()
+	if err := validateScanRequest(api.ScanRequest{Target: target, Workflows: workflows}, exclusions); err != nil {
+		return err
+	}
+	userConfig, err := userconfig.LoadUserConfig()
+	if err != nil {
+		userConfig = &userconfig.UserConfig{}
+	}
+	effectiveOutputDir := userConfig.GetEffectiveOutputDirectory(outputDir, "")
+	if effectiveOutputDir != "" {
+		if effectiveOutputDir, err = filepath.Abs(effectiveOutputDir); err != nil {
+			return fmt.Errorf("invalid output directory path: %v", err)
+		}
+	}
+	outputMode := output.OutputModeNormal
+	if verbose {
+		outputMode = output.OutputModeVerbose
+	}
+	setGlobalOutputController(output.NewOutputController(outputMode))
+
+	workspaceDir := ""
+	hooks := &scanHooks{Workflows: workflows, OnWorkspace: func(dir string) { workspaceDir = dir }, OnFinished: inspect}
+	stdout := os.Stdout
+	if asJSON {
+		os.Stdout = os.Stderr
+	}
+	runErr := runCLI(target, outputMode, effectiveOutputDir, exclusions, nil, false, hooks)
+	os.Stdout = stdout
+	if runErr != nil {
+		// A partial run could hide exposed ports; it is not verified
+		if workspaceDir != "" {
+			return fmt.Errorf("scan failed, nothing verified (workspace %s): %v", workspaceDir, runErr)
+		}
+		return fmt.Errorf("scan failed, nothing verified: %v", runErr)
+	}
+	return nil
+}
+
+// printExposureCheck prints the ports that differ from the baseline followed by the totals
+func printExposureCheck(check *report.ExposureCheck, baselineFile string, approved int) {
+	fmt.Printf("Baseline: %s (%d approved ports)\n", baselineFile, approved)
+	fmt.Printf("Run:      %s (%s)\n", check.Workspace, check.Target)
+	fmt.Println()
+
+	if len(check.Entries) == 0 {
+		fmt.Println("Exposure matches the baseline")
+	} else {
+		symbols := map[string]string{report.ExposureUnexpected: "+", report.ExposureChanged: "~", report.ExposureMissing: "-"}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, entry := range check.Entries {
+			detail := entry.Found
+			switch entry.Result {
+			case report.ExposureChanged:
+				detail = fmt.Sprintf("expected %s, found %s", entry.Expected, dashIfEmpty(entry.Found))
+			case report.ExposureMissing:
+				detail = "expected open"
+				if entry.Expected != "" {
+					detail = "expected " + entry.Expected
+				}
+			}
+			fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\n", symbols[entry.Result], entry.Result, dashIfEmpty(entry.Host), entry.Port, dashIfEmpty(detail))
+		}
+		w.Flush()
+	}
+	fmt.Printf("\n%d matched, %d unexpected, %d changed, %d missing\n", check.Matched, check.Unexpected, check.Changed, check.Missing)
+}
+
+func printVerifyUsage() {
+	fmt.Println("Usage: ipcrawler verify --baseline FILE [options] <target>")
+	fmt.Println("       ipcrawler verify --baseline FILE --workspace DIR")
+	fmt.Println()
+	fmt.Println("Checks a target's exposure against an approved baseline, for infrastructure")
+	fmt.Println("CI: scans the target (or reads an existing run), compares its open ports with")
+	fmt.Println("the baseline and exits non-zero when anything unexpected is exposed:")
+	fmt.Println()
+	fmt.Println("  + unexpected   Open, but not in the baseline")
+	fmt.Println("  ~ changed      Approved port running a service the baseline does not allow")
+	fmt.Println("  - missing      Approved port not found open (fails only with --strict)")
+	fmt.Println()
+	fmt.Println("The baseline is JSON, usually written once with --approve and then reviewed")
+	fmt.Println("and committed. Each entry of \"ports\" has a \"port\" (\"443/tcp\"), and")
+	fmt.Println("optionally a \"host\" (default: any host), a \"service\" name or pattern")
+	fmt.Println("(\"http*\"; ports whose service was not identified are not compared) and")
+	fmt.Println("\"optional\": true for ports that may be closed. The result is also saved as")
+	fmt.Println("reports/verify.json in the run's workspace.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --baseline FILE      Approved exposure baseline")
+	fmt.Println("  -w, --workspace DIR      Check an existing run instead of scanning")
+	fmt.Println("      --approve            Write the run's open ports as the baseline")
+	fmt.Println("      --strict             Fail when approved ports are not found open too")
+	fmt.Println("      --json               Print machine-readable JSON")
+	fmt.Println("      --workflow LIST      Run only these workflows (file name or title)")
+	fmt.Println("  -o, --output DIR         Output directory for scan results")
+	fmt.Println("  -v, --verbose            Show both logs and raw tool output")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler verify --baseline infra/edge.json --approve 203.0.113.10")
+	fmt.Println("  ipcrawler verify --baseline infra/edge.json 203.0.113.10")
+	fmt.Println("  ipcrawler verify --baseline infra/edge.json --workspace ipcrawler_results/203_0_113_10_1735732800_1a2b3c4d --json")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/embedded"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/spf13/pflag"
+)
+
+// toolsDir holds the tool definitions, read from disk at run time (they are not embedded)
+const toolsDir = "./tools"
+
+// versionInfo identifies a binary and the definitions it runs with, for support requests and audit logs
+type versionInfo struct {
+	Version      string `json:"version"`
+	Commit       string `json:"commit,omitempty"`
+	Date         string `json:"date,omitempty"`
+	GoVersion    string `json:"go_version"`
+	OS           string `json:"goos"`
+	Arch         string `json:"goarch"`
+	WorkflowHash string `json:"workflows_sha256,omitempty"` // Embedded workflow bundle
+	ToolsHash    string `json:"tools_sha256,omitempty"`     // tools/*/config.yaml in the working directory
+}
+
+// collectVersionInfo gathers build metadata and bundle hashes; a bundle that cannot be read is left out
+func collectVersionInfo() versionInfo {
+	build := session.ReadBuildInfo()
+	info := versionInfo{
+		Version:   ipcrawlerVersion,
+		Commit:    build.Revision,
+		Date:      build.Time,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if hash, err := embedded.WorkflowBundleHash(); err == nil {
+		info.WorkflowHash = hash
+	}
+	if _, err := os.Stat(toolsDir); err == nil {
+		hash, err := embedded.HashFS(os.DirFS(toolsDir), func(name string) bool {
+			return strings.Count(name, "/") == 1 && path.Base(name) == "config.yaml"
+		})
+		if err == nil {
+			info.ToolsHash = hash
+		}
+	}
+	return info
+}
+
+// runVersionCommand implements `ipcrawler version`
+func runVersionCommand(args []string) error {
+	fs := pflag.NewFlagSet("version", pflag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the version information as JSON")
+	fs.Usage = printVersionUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	info := collectVersionInfo()
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+	printVersion(info)
+	return nil
+}
+
+// printVersion prints the human-readable version block shared by `version` and --version
+func printVersion(info versionInfo) {
+	fmt.Printf("IPCrawler v%s\n", info.Version)
+	fmt.Printf("Built for penetration testing and security assessment\n")
+	fmt.Println()
+	fmt.Printf("  Commit:     %s\n", valueOrUnknown(info.Commit))
+	fmt.Printf("  Date:       %s\n", valueOrUnknown(info.Date))
+	fmt.Printf("  Go:         %s %s/%s\n", info.GoVersion, info.OS, info.Arch)
+	fmt.Printf("  Workflows:  sha256:%s\n", valueOrUnknown(info.WorkflowHash))
+	if info.ToolsHash != "" {
+		fmt.Printf("  Tools:      sha256:%s\n", info.ToolsHash)
+	} else {
+		fmt.Printf("  Tools:      not found (%s)\n", toolsDir)
+	}
+}
+
+// valueOrUnknown substitutes "unknown" for build metadata that was not embedded
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+func printVersionUsage() {
+	fmt.Println("Usage: ipcrawler version [--json]")
+	fmt.Println()
+	fmt.Println("Prints the release, the commit and date the binary was built from, its platform,")
+	fmt.Println("and SHA-256 hashes of the embedded workflows and of tools/*/config.yaml, so")
+	fmt.Println("results can be traced to the exact definitions that produced them.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --json              Print machine-readable JSON")
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/viewer"
+)
+
+// runViewCommand pages through a (possibly huge) raw output file without loading it into memory
+func runViewCommand(args []string) error {
+	fs := flag.NewFlagSet("view", flag.ContinueOnError)
+	var (
+		from     = fs.Int("from", 1, "Line number to start at")
+		lines    = fs.Int("lines", 0, "Print this many lines and exit (non-interactive)")
+		search   = fs.String("search", "", "Print matching lines with line numbers and exit")
+		useRegex = fs.Bool("regex", false, "Treat search patterns as regular expressions")
+	)
+	fs.Usage = printViewUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		printViewUsage()
+		return fmt.Errorf("expected exactly one file")
+	}
+
+	file, err := viewer.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	start := *from - 1
+	if start < 0 {
+		start = 0
+	}
+
+	if *search != "" {
+		match, err := viewer.NewMatcher(*search, *useRegex)
+		if err != nil {
+			return err
+		}
+		return printMatches(os.Stdout, file, start, match)
+	}
+
+	if *lines > 0 || !isTerminal(os.Stdout) {
+		return printLines(os.Stdout, file, start, *lines)
+	}
+
+	return runPager(file, start, *useRegex)
+}
+
+func printViewUsage() {
+	fmt.Println("Usage: ipcrawler view [options] <file>")
+	fmt.Println()
+	fmt.Println("Pages large raw output files by seeking, so multi-hundred-MB files open instantly.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -from N        Line number to start at (default 1)")
+	fmt.Println("  -lines N       Print N lines and exit")
+	fmt.Println("  -search TEXT   Print matching lines with line numbers and exit")
+	fmt.Println("  -regex         Treat search patterns as regular expressions")
+	fmt.Println()
+	fmt.Println("Pager keys (type then press Enter):")
+	fmt.Println("  Enter / f      Next page")
+	fmt.Println("  b              Previous page")
+	fmt.Println("  g N            Go to line N")
+	fmt.Println("  G              Go to end of file")
+	fmt.Println("  /TEXT          Search forward")
+	fmt.Println("  ?TEXT          Search backward")
+	fmt.Println("  n / N          Repeat search forward / backward")
+	fmt.Println("  q              Quit")
+}
+
+// printLines writes count lines (all remaining when count is 0) starting at start
+func printLines(w io.Writer, file *viewer.File, start, count int) error {
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	printed := 0
+	return file.Each(start, func(lineNum int, line []byte) bool {
+		out.Write(line)
+		out.WriteByte('\n')
+		printed++
+		return count == 0 || printed < count
+	})
+}
+
+// printMatches writes every matching line prefixed with its 1-based line number
+func printMatches(w io.Writer, file *viewer.File, start int, match viewer.Matcher) error {
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	return file.Each(start, func(lineNum int, line []byte) bool {
+		if match(line) {
+			fmt.Fprintf(out, "%d:%s\n", lineNum+1, line)
+		}
+		return true
+	})
+}
+
+// runPager runs a line-driven pager over the file
+func runPager(file *viewer.File, start int, useRegex bool) error {
+	_, height := getTerminalSize()
+	pageSize := height - 2
+	if pageSize < 5 {
+		pageSize = 5
+	}
+
+	input := bufio.NewReader(os.Stdin)
+	current := start
+	var lastMatch viewer.Matcher
+	status := ""
+
+	for {
+		page, err := file.ReadPage(current, pageSize)
+		if err != nil {
+			return err
+		}
+
+		for i, line := range page.Lines {
+			fmt.Printf("%7d  %s\n", page.Start+i+1, line)
+		}
+		fmt.Println(pagerStatus(file, page, status))
+		status = ""
+
+		command, err := input.ReadString('\n')
+		if err != nil && command == "" {
+			return nil // stdin closed
+		}
+		command = strings.TrimRight(command, "\r\n")
+
+		switch {
+		case command == "" || command == "f" || command == " ":
+			if !page.EOF {
+				current += len(page.Lines)
+			} else {
+				status = "(end of file)"
+			}
+		case command == "q":
+			return nil
+		case command == "b":
+			current -= pageSize
+			if current < 0 {
+				current = 0
+			}
+		case command == "G":
+			total, err := file.TotalLines()
+			if err != nil {
+				return err
+			}
+			current = total - pageSize
+			if current < 0 {
+				current = 0
+			}
+		case strings.HasPrefix(command, "g"):
+			lineNum, err := strconv.Atoi(strings.TrimSpace(command[1:]))
+			if err != nil || lineNum < 1 {
+				status = "(usage: g LINE)"
+				continue
+			}
+			current = lineNum - 1
+		case strings.HasPrefix(command, "/") || strings.HasPrefix(command, "?"):
+			match, err := viewer.NewMatcher(command[1:], useRegex)
+			if err != nil {
+				status = fmt.Sprintf("(%v)", err)
+				continue
+			}
+			lastMatch = match
+			current, status = pagerSearch(file, current, match, command[0] == '/')
+		case command == "n" || command == "N":
+			if lastMatch == nil {
+				status = "(no previous search)"
+				continue
+			}
+			current, status = pagerSearch(file, current, lastMatch, command == "n")
+		default:
+			status = "(unknown command; q to quit, see 'ipcrawler view -h')"
+		}
+	}
+}
+
+// pagerSearch moves to the next match in the given direction, staying put when none is found
+func pagerSearch(file *viewer.File, current int, match viewer.Matcher, forward bool) (int, string) {
+	var found int
+	var err error
+	if forward {
+		found, err = file.SearchForward(current+1, match)
+	} else {
+		found, err = file.SearchBackward(current, match)
+	}
+	if err != nil {
+		return current, fmt.Sprintf("(search failed: %v)", err)
+	}
+	if found < 0 {
+		return current, "(pattern not found)"
+	}
+	return found, fmt.Sprintf("(match at line %d)", found+1)
+}
+
+// pagerStatus formats the status line shown under each page
+func pagerStatus(file *viewer.File, page *viewer.Page, message string) string {
+	end := page.Start + len(page.Lines)
+	percent := 100.0
+	if file.Size() > 0 {
+		percent = float64(page.EndOffset) * 100 / float64(file.Size())
+	}
+
+	lines, complete := file.KnownLines()
+	total := "?"
+	if complete {
+		total = strconv.Itoa(lines)
+	}
+	return fmt.Sprintf("-- lines %d-%d of %s (%.0f%%) %s-- [Enter] next [b] back [/text] search [q] quit",
+		page.Start+1, end, total, percent, message+" ")
+}
+
+// isTerminal reports whether the file is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
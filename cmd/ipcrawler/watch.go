@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/api"
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/report"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/userconfig"
+)
+
+// watchDiffFileName is where each watched run stores its differences from the previous run
+const watchDiffFileName = "diff.json"
+
+// runWatchCommand re-scans a target on an interval and reports what changed between runs
+func runWatchCommand(args []string) error {
+	fs := pflag.NewFlagSet("watch", pflag.ContinueOnError)
+	var (
+		every       = fs.Duration("every", 24*time.Hour, "Time between the starts of two runs")
+		runs        = fs.Int("runs", 0, "Stop after this many runs (default: until interrupted)")
+		workflows   = fs.StringSlice("workflow", nil, "Run only these workflows (file name or title; default: all)")
+		onChange    = fs.String("on-change", "", "Command to run when new ports or hosts appear")
+		outputDir   = fs.StringP("output", "o", "", "Output directory for scan results")
+		exclude     = fs.String("exclude", "", "Comma-separated hosts, IPs or CIDRs that must never be scanned")
+		excludeFile = fs.String("exclude-file", "", "File of hosts, IPs or CIDRs to exclude (one per line)")
+		scopeFile   = fs.String("scope", "", "Scope file of the networks and domains that may be scanned")
+		labels      = fs.String("label", "", "Comma-separated labels for the target")
+		verbose     = fs.BoolP("verbose", "v", false, "Show both logs and raw tool output")
+	)
+	fs.Usage = printWatchUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		printWatchUsage()
+		return fmt.Errorf("exactly one target is required")
+	}
+	if *every < time.Minute {
+		return fmt.Errorf("--every must be at least 1m")
+	}
+	target := fs.Arg(0)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %v", err)
+	}
+
+	exclusions := scope.NewExclusionList()
+	if err := exclusions.AddList(*exclude); err != nil {
+		return err
+	}
+	if *excludeFile != "" {
+		if err := exclusions.LoadFile(*excludeFile); err != nil {
+			return err
+		}
+	}
+	engagement, err := loadEngagement(*scopeFile, exclusions)
+	if err != nil {
+		return err
+	}
+	if err := validateScanRequest(api.ScanRequest{Target: target, Workflows: *workflows}, exclusions); err != nil {
+		return err
+	}
+	if err := refuseTarget(engagement, target, ""); err != nil {
+		return fmt.Errorf("target refused: %v", err)
+	}
+
+	userConfig, err := userconfig.LoadUserConfig()
+	if err != nil {
+		userConfig = &userconfig.UserConfig{}
+	}
+	effectiveOutputDir := userConfig.GetEffectiveOutputDirectory(*outputDir, "")
+	if effectiveOutputDir != "" {
+		if effectiveOutputDir, err = filepath.Abs(effectiveOutputDir); err != nil {
+			return fmt.Errorf("invalid output directory path: %v", err)
+		}
+	}
+	outputMode := output.OutputModeNormal
+	if *verbose {
+		outputMode = output.OutputModeVerbose
+	}
+	setGlobalOutputController(output.NewOutputController(outputMode))
+
+	// The first run is compared with the target's latest existing workspace, if any
+	previous := ""
+	if existing, err := findTargetWorkspaces(workspaceBaseDir(cfg, *outputDir), "", target); err == nil && len(existing) > 0 {
+		previous = existing[len(existing)-1]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for run := 1; ; run++ {
+		started := time.Now()
+		fmt.Fprintf(os.Stderr, "Watch run %d of %s started at %s\n", run, target, started.Format("2006-01-02 15:04:05"))
+
+		workspaceDir := ""
+		hooks := &scanHooks{
+			Context:     ctx,
+			Workflows:   *workflows,
+			Engagement:  engagement,
+			OnWorkspace: func(dir string) { workspaceDir = dir },
+		}
+		runErr := runCLI(target, outputMode, effectiveOutputDir, exclusions, scope.ParseLabelList(*labels), false, hooks)
+		switch {
+		case ctx.Err() != nil:
+			fmt.Fprintf(os.Stderr, "Watch of %s stopped\n", target)
+			return nil
+		case runErr != nil:
+			// A failed run is not a baseline; the next run is compared with the last good one
+			fmt.Fprintf(os.Stderr, "Watch run %d failed: %v\n", run, runErr)
+		case previous == "":
+			fmt.Fprintf(os.Stderr, "Watch run %d is the first run of %s; later runs are compared with it\n", run, target)
+			previous = workspaceDir
+		default:
+			if err := reportWatchChanges(cfg, previous, workspaceDir, *onChange); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			previous = workspaceDir
+		}
+
+		if *runs > 0 && run >= *runs {
+			return nil
+		}
+		next := started.Add(*every)
+		if wait := time.Until(next); wait > 0 {
+			fmt.Fprintf(os.Stderr, "Next run at %s\n", next.Format("2006-01-02 15:04:05"))
+			select {
+			case <-ctx.Done():
+				fmt.Fprintf(os.Stderr, "Watch of %s stopped\n", target)
+				return nil
+			case <-time.After(wait):
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: run %d took longer than --every %s; starting the next run now\n", run, *every)
+		}
+	}
+}
+
+func printWatchUsage() {
+	fmt.Println("Usage: ipcrawler watch [options] <target>")
+	fmt.Println()
+	fmt.Println("Scans the target again on an interval, each run in its own timestamped")
+	fmt.Println("workspace, and compares every run with the previous one as 'ipcrawler diff'")
+	fmt.Println("does. The differences are printed and saved as reports/diff.json in the new")
+	fmt.Println("workspace. The first run is compared with the target's latest existing")
+	fmt.Println("workspace, if there is one. Stop watching with Ctrl+C.")
+	fmt.Println()
+	fmt.Println("When new ports or hosts appear, --on-change runs a shell command with:")
+	fmt.Println("  IPCRAWLER_TARGET, IPCRAWLER_WORKSPACE, IPCRAWLER_BASELINE   the target and both workspaces")
+	fmt.Println("  IPCRAWLER_DIFF                                            path of the run's diff.json")
+	fmt.Println("  IPCRAWLER_NEW_PORTS, IPCRAWLER_NEW_HOSTS                  how many appeared")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --every DURATION     Time between the starts of two runs (default 24h)")
+	fmt.Println("      --runs N             Stop after N runs (default: until interrupted)")
+	fmt.Println("      --workflow LIST      Run only these workflows (file name or title)")
+	fmt.Println("      --on-change CMD      Command to run when new ports or hosts appear")
+	fmt.Println("  -o, --output DIR         Output directory for scan results")
+	fmt.Println("      --exclude LIST       Hosts, IPs or CIDRs that must never be scanned")
+	fmt.Println("      --exclude-file FILE  File of hosts, IPs or CIDRs to exclude")
+	fmt.Println("      --scope FILE         Scope file; hosts outside it are refused and audited")
+	fmt.Println("      --label LIST         Labels for the target")
+	fmt.Println("  -v, --verbose            Show both logs and raw tool output")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  ipcrawler watch example.com --every 6h --workflow port-discovery")
+	fmt.Println("  ipcrawler watch 10.0.0.0/24 --every 1h --on-change 'jq .entries \"$IPCRAWLER_DIFF\" | mail -s \"$IPCRAWLER_TARGET changed\" soc@example.com'")
+}
+
+// reportWatchChanges compares a watched run with the previous one, saves and prints the
+// differences, and runs the --on-change command when new ports or hosts appeared
+func reportWatchChanges(cfg *config.Config, previous, current, onChange string) error {
+	diff, err := diffWorkspaces(cfg, previous, current)
+	if err != nil {
+		return fmt.Errorf("failed to compare with %s: %v", previous, err)
+	}
+
+	diffPath := filepath.Join(current, "reports", watchDiffFileName)
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err == nil {
+		err = os.WriteFile(diffPath, data, cfg.Output.Permissions.FilePerm())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", diffPath, err)
+	}
+
+	fmt.Println()
+	printDiff(diff)
+
+	newPorts := diff.NewPorts()
+	if len(newPorts) == 0 && len(diff.NewHosts) == 0 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%s New since the previous run: %d port(s), %d host(s)\n",
+		output.FormatStatus(output.StatusWarning, "ALERT"), len(newPorts), len(diff.NewHosts))
+	if onChange == "" {
+		return nil
+	}
+	return runWatchHook(onChange, diff, previous, current, diffPath, len(newPorts))
+}
+
+// runWatchHook runs the --on-change command through the platform shell
+func runWatchHook(command string, diff *report.Diff, previous, current, diffPath string, newPorts int) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"IPCRAWLER_TARGET="+diff.Current.Target,
+		"IPCRAWLER_WORKSPACE="+current,
+		"IPCRAWLER_BASELINE="+previous,
+		"IPCRAWLER_DIFF="+diffPath,
+		"IPCRAWLER_NEW_PORTS="+strconv.Itoa(newPorts),
+		"IPCRAWLER_NEW_HOSTS="+strconv.Itoa(len(diff.NewHosts)),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("--on-change command failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/selftest"
+	"github.com/neur0map/ipcrawler/internal/workflowtest"
+)
+
+// runWorkflowCommand implements `ipcrawler workflow test`. `workflow mock-tool` is what runs
+// instead of each tool during a test
+func runWorkflowCommand(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		printWorkflowUsage()
+		if len(args) == 0 {
+			return fmt.Errorf("a workflow command is required")
+		}
+		return pflag.ErrHelp
+	}
+	switch args[0] {
+	case "test":
+		return runWorkflowTest(args[1:])
+	case "mock-tool":
+		return runMockTool(args[1:])
+	default:
+		printWorkflowUsage()
+		return fmt.Errorf("unknown workflow command '%s'", args[0])
+	}
+}
+
+// runWorkflowTest runs each test's workflow with its fixtures standing in for the tools and
+// checks the variables and findings the run produced
+func runWorkflowTest(args []string) error {
+	fs := pflag.NewFlagSet("workflow test", pflag.ContinueOnError)
+	keep := fs.Bool("keep", false, "Keep the test workspaces even when every check passes")
+	verbose := fs.BoolP("verbose", "v", false, "Show tool progress while the workflow runs")
+	fs.Usage = printWorkflowUsage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		printWorkflowUsage()
+		return fmt.Errorf("a test file is required")
+	}
+
+	// Load every test first: a mistake in the last one should not wait for the others to run
+	specs := make([]*workflowtest.Spec, fs.NArg())
+	for i, path := range fs.Args() {
+		spec, err := workflowtest.Load(path)
+		if err != nil {
+			return err
+		}
+		specs[i] = spec
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the ipcrawler executable: %v", err)
+	}
+	outputMode := output.OutputModeNormal
+	if *verbose {
+		outputMode = output.OutputModeVerbose
+	}
+
+	var failedTests []string
+	for i, spec := range specs {
+		path := fs.Arg(i)
+		checks, workspaceDir, err := runWorkflowTestSpec(spec, executable, outputMode)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		fmt.Printf("\n%s\n", path)
+		for _, check := range checks {
+			status, label := output.StatusOK, "pass"
+			if !check.Passed {
+				status, label = output.StatusFailed, "FAIL"
+			}
+			line := fmt.Sprintf("  %s %s", output.FormatStatus(status, label), check.Name)
+			if check.Detail != "" {
+				line += ": " + check.Detail
+			}
+			fmt.Println(line)
+		}
+		if failed := selftest.Failed(checks); failed > 0 {
+			fmt.Printf("  %d of %d checks failed; workspace kept at %s\n", failed, len(checks), workspaceDir)
+			failedTests = append(failedTests, path)
+		} else if *keep {
+			fmt.Printf("  Workspace: %s\n", workspaceDir)
+		} else {
+			os.RemoveAll(workspaceDir)
+		}
+	}
+
+	if len(failedTests) > 0 {
+		return fmt.Errorf("%d of %d tests failed", len(failedTests), len(specs))
+	}
+	fmt.Printf("\n%d tests passed\n", len(specs))
+	return nil
+}
+
+// runWorkflowTestSpec runs one test in a temporary directory and returns its checks and the
+// directory, which holds the run's workspace
+func runWorkflowTestSpec(spec *workflowtest.Spec, executable string, outputMode output.OutputMode) ([]selftest.Check, string, error) {
+	outputDir, err := os.MkdirTemp("", "ipcrawler-workflow-test-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create test directory: %v", err)
+	}
+	var workspaceDir string
+	mock := &fixtureMock{spec: spec, executable: executable}
+	hooks := &scanHooks{
+		NoHistory:    true,
+		WorkflowFile: spec.Workflow,
+		ToolMock:     mock.command,
+		OnWorkspace:  func(dir string) { workspaceDir = dir },
+	}
+	runErr := runCLI(spec.Target, outputMode, outputDir, scope.NewExclusionList(), []string{"workflow-test"}, false, hooks)
+	if workspaceDir == "" {
+		os.RemoveAll(outputDir)
+		return nil, "", fmt.Errorf("test run did not start: %v", runErr)
+	}
+
+	report, err := output.LoadRunReport(workspaceDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("test run wrote no report (workspace %s): %v", workspaceDir, err)
+	}
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	found, _, err := catalog.LoadWorkspace(workspaceDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load findings (workspace %s): %v", workspaceDir, err)
+	}
+
+	// The step errors only say the tool failed; name the executions that had no fixture
+	var checks []selftest.Check
+	for _, execution := range mock.unmatched() {
+		checks = append(checks, selftest.Check{Name: "fixture for " + execution, Detail: "none matches, so the execution failed"})
+	}
+	return append(checks, workflowtest.Verify(spec.Expect, report, found)...), outputDir, nil
+}
+
+// fixtureMock runs `ipcrawler workflow mock-tool` with a test's fixture instead of each tool
+type fixtureMock struct {
+	spec       *workflowtest.Spec
+	executable string
+
+	mu      sync.Mutex
+	missing []string // Executions no fixture matched
+}
+
+func (m *fixtureMock) command(call executor.ToolCall) []string {
+	command := []string{m.executable, "workflow", "mock-tool"}
+	fixture := m.spec.Fixture(call)
+	if fixture == nil {
+		execution := fmt.Sprintf("%s %s in step %s", call.Tool, call.Mode, call.Step)
+		m.mu.Lock()
+		m.missing = append(m.missing, execution)
+		m.mu.Unlock()
+		return append(command, "--missing", execution)
+	}
+	if fixture.Output != "" {
+		command = append(command, "--output", fixture.Output)
+		if len(call.Outputs) > 0 {
+			command = append(command, "--to", call.Outputs[0])
+		}
+	}
+	if fixture.Stdout != "" {
+		command = append(command, "--stdout", fixture.Stdout)
+	}
+	if fixture.ExitCode != 0 {
+		command = append(command, "--exit-code", strconv.Itoa(fixture.ExitCode))
+	}
+	return command
+}
+
+// unmatched returns the executions no fixture matched, once each
+func (m *fixtureMock) unmatched() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []string
+	for _, execution := range m.missing {
+		if !slices.Contains(result, execution) {
+			result = append(result, execution)
+		}
+	}
+	return result
+}
+
+// runMockTool produces a fixture's output the way the tool it replaces would: the output file
+// where the tool was told to write, or on standard output
+func runMockTool(args []string) error {
+	fs := pflag.NewFlagSet("workflow mock-tool", pflag.ContinueOnError)
+	outputFile := fs.String("output", "", "Fixture output file")
+	to := fs.String("to", "", "File the tool was told to write; standard output when empty")
+	stdout := fs.String("stdout", "", "Fixture printed on standard output")
+	exitCode := fs.Int("exit-code", 0, "Exit code")
+	missing := fs.String("missing", "", "Execution no fixture matched")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *missing != "" {
+		return fmt.Errorf("no fixture for %s", *missing)
+	}
+
+	if *outputFile != "" {
+		data, err := os.ReadFile(*outputFile)
+		if err != nil {
+			return err
+		}
+		if *to == "" || *to == "-" {
+			os.Stdout.Write(data)
+		} else if err := os.WriteFile(*to, data, 0644); err != nil {
+			return err
+		}
+	}
+	if *stdout != "" {
+		data, err := os.ReadFile(*stdout)
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+	}
+	if *exitCode != 0 {
+		os.Exit(*exitCode)
+	}
+	return nil
+}
+
+func printWorkflowUsage() {
+	fmt.Println("Usage: ipcrawler workflow test [options] <test.yaml>...")
+	fmt.Println()
+	fmt.Println("Runs a workflow against fixture outputs instead of its tools and checks what")
+	fmt.Println("the run produced, so a workflow can be tried before it meets a real target.")
+	fmt.Println("No tool runs and nothing is scanned: each execution gets the output of the")
+	fmt.Println("first fixture matching its tool (and step and mode, when the fixture names")
+	fmt.Println("them), written where the tool was told to write, and an execution no fixture")
+	fmt.Println("matches fails. The workflow runs on its own; its after and triggers are")
+	fmt.Println("ignored. A failed test keeps its workspace and the command exits non-zero.")
+	fmt.Println()
+	fmt.Println("Test file (paths are relative to it):")
+	fmt.Println("  workflow: ../workflows/reconnaissance/port-scanning.yaml")
+	fmt.Println("  target: 10.0.0.5                 # 127.0.0.1 when omitted; never contacted")
+	fmt.Println("  fixtures:")
+	fmt.Println("    - tool: naabu")
+	fmt.Println("      output: fixtures/naabu.json   # Copied to the tool's output file")
+	fmt.Println("    - tool: nmap")
+	fmt.Println("      mode: pipeline_service_scan   # Optional, as is step")
+	fmt.Println("      output: fixtures/nmap.xml")
+	fmt.Println("      # stdout: file printed on standard output; exit_code: exit code")
+	fmt.Println("  expect:")
+	fmt.Println("    status: completed               # Run status (default); every step must pass")
+	fmt.Println("    variables:                      # Exact values")
+	fmt.Println("      combined_naabu_ports: \"22,80\"")
+	fmt.Println("    produces: [high_coverage_ports] # Set, whatever the value")
+	fmt.Println("    findings:                       # Counts, by 'ipcrawler search' query")
+	fmt.Println("      - query: \"state:open\"")
+	fmt.Println("        count: 2")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("      --keep              Keep the workspaces when every check passes")
+	fmt.Println("  -v, --verbose           Show tool progress while the workflow runs")
+}
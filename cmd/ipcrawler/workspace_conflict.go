@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// What to do when a workspace for the target already exists
+const (
+	conflictPrompt    = ""          // Ask on a terminal, otherwise create a new workspace
+	conflictResume    = "resume"    // Continue the latest workspace's unfinished workflows
+	conflictOverwrite = "overwrite" // Delete the latest workspace and scan again
+	conflictNew       = "new"       // Create another timestamped workspace
+)
+
+// workspaceConflictPolicy returns the policy chosen with --resume, --overwrite or --new
+func workspaceConflictPolicy(resume, overwrite, newWorkspace bool) (string, error) {
+	var chosen []string
+	if resume {
+		chosen = append(chosen, conflictResume)
+	}
+	if overwrite {
+		chosen = append(chosen, conflictOverwrite)
+	}
+	if newWorkspace {
+		chosen = append(chosen, conflictNew)
+	}
+	if len(chosen) > 1 {
+		return "", fmt.Errorf("--%s cannot be combined with --%s", chosen[0], chosen[1])
+	}
+	if len(chosen) == 1 {
+		return chosen[0], nil
+	}
+	return conflictPrompt, nil
+}
+
+// findTargetWorkspaces returns the target's existing workspaces in a session under baseDir,
+// oldest first. An empty sessionName finds the workspaces of runs without one
+func findTargetWorkspaces(baseDir, sessionName, target string) ([]string, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %v", baseDir, err)
+	}
+
+	// Workspaces are named [<session name>_]<target>_<unix time>_<short scan ID>
+	pattern := regexp.MustCompile("^" + regexp.QuoteMeta(workspace.Prefix(sessionName, target)) + `_(\d+)_[0-9a-f-]+$`)
+	type workspace struct {
+		path    string
+		created int64
+	}
+	var found []workspace
+	for _, entry := range entries {
+		match := pattern.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || match == nil {
+			continue
+		}
+		created, _ := strconv.ParseInt(match[1], 10, 64)
+		found = append(found, workspace{path: filepath.Join(baseDir, entry.Name()), created: created})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].created < found[j].created })
+
+	paths := make([]string, len(found))
+	for i, ws := range found {
+		paths[i] = ws.path
+	}
+	return paths, nil
+}
+
+// resolveWorkspaceConflict decides what to do with an existing workspace for the target and
+// returns the action and the workspace it applies to; conflictNew when there is none
+func resolveWorkspaceConflict(baseDir, sessionName, target, policy string, interactive bool) (string, string, error) {
+	existing, err := findTargetWorkspaces(baseDir, sessionName, target)
+	if err != nil || len(existing) == 0 {
+		return conflictNew, "", err
+	}
+	latest := existing[len(existing)-1]
+
+	if policy == conflictPrompt {
+		if !interactive {
+			return conflictNew, latest, nil
+		}
+		if policy, err = promptWorkspaceConflict(os.Stdin, os.Stderr, target, latest, len(existing)); err != nil {
+			return "", "", err
+		}
+	}
+	return policy, latest, nil
+}
+
+// promptWorkspaceConflict asks whether to resume, overwrite or start a new workspace
+func promptWorkspaceConflict(in io.Reader, out io.Writer, target, latest string, count int) (string, error) {
+	fmt.Fprintf(out, "A workspace for %s already exists: %s\n", target, latest)
+	if manifest, err := session.LoadManifest(latest); err == nil {
+		fmt.Fprintf(out, "  Started %s, status %s\n", manifest.StartedAt.Format("2006-01-02 15:04:05"), output.FormatStatus(runStatusKind(manifest.Status), manifest.Status))
+	}
+	if count > 1 {
+		fmt.Fprintf(out, "  (%d workspaces exist for this target)\n", count)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "[r]esume, [o]verwrite, [n]ew workspace, [q]uit (default n): ")
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(out)
+			return conflictNew, nil // No input (e.g. stdin is /dev/null): keep the default
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "n", "new":
+			return conflictNew, nil
+		case "r", "resume":
+			return conflictResume, nil
+		case "o", "overwrite":
+			return conflictOverwrite, nil
+		case "q", "quit":
+			return "", fmt.Errorf("cancelled")
+		}
+	}
+}
+
+// removeWorkspace deletes a workspace being overwritten. Only directories with a run
+// manifest or the standard logs/ layout are removed, so a mistyped path is never wiped
+func removeWorkspace(workspaceDir string) error {
+	_, manifestErr := os.Stat(filepath.Join(workspaceDir, session.ManifestFileName))
+	_, logsErr := os.Stat(filepath.Join(workspaceDir, "logs"))
+	if manifestErr != nil && logsErr != nil {
+		return fmt.Errorf("%s does not look like an IPCrawler workspace; not removing it", workspaceDir)
+	}
+	if err := os.RemoveAll(workspaceDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %v", workspaceDir, err)
+	}
+	return nil
+}
+
+// prepareTargetWorkspace applies the conflict policy before scanning a target. An overwritten
+// workspace is deleted here; when resuming, the workspace to resume is returned
+func prepareTargetWorkspace(baseDir, sessionName, target, policy string, interactive bool) (string, error) {
+	action, existing, err := resolveWorkspaceConflict(baseDir, sessionName, target, policy, interactive)
+	if err != nil {
+		return "", err
+	}
+	switch action {
+	case conflictResume:
+		if existing == "" {
+			return "", fmt.Errorf("no existing workspace for %s to resume", target)
+		}
+		return existing, nil
+	case conflictOverwrite:
+		if existing != "" {
+			fmt.Fprintf(os.Stderr, "Overwriting workspace %s\n", existing)
+			if err := removeWorkspace(existing); err != nil {
+				return "", err
+			}
+		}
+	}
+	return "", nil
+}
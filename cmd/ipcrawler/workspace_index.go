@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/report"
+)
+
+// workspaceIndex keeps the workspace's INDEX.md current while a run goes on
+type workspaceIndex struct {
+	mutex        sync.Mutex
+	cfg          *config.Config
+	workspaceDir string
+	catalog      *findings.Catalog
+	redactor     *output.Redactor
+	logger       *log.Logger
+}
+
+func newWorkspaceIndex(cfg *config.Config, workspaceDir string, redactor *output.Redactor, logger *log.Logger) *workspaceIndex {
+	catalog := findings.NewCatalog()
+	executor.RegisterAllFindingsExtractors(catalog)
+	return &workspaceIndex{cfg: cfg, workspaceDir: workspaceDir, catalog: catalog, redactor: redactor, logger: logger}
+}
+
+// subscribe rewrites the index whenever a workflow finishes
+func (wi *workspaceIndex) subscribe(orchestrator *executor.WorkflowOrchestrator) {
+	orchestrator.Events().Subscribe(func(executor.Event) {
+		wi.update(nil)
+	}, executor.EventWorkflowCompleted, executor.EventWorkflowFailed, executor.EventWorkflowCancelled)
+}
+
+// update rewrites the index, from the given target summary when the caller already built one
+func (wi *workspaceIndex) update(summary *report.TargetSummary) {
+	wi.mutex.Lock()
+	defer wi.mutex.Unlock()
+
+	if summary == nil {
+		var err error
+		if summary, err = report.LoadTarget(wi.workspaceDir, wi.catalog, nil); err != nil {
+			wi.logger.Warn("Failed to collect findings for workspace index", "error", err)
+			return
+		}
+	}
+	summary = wi.redactor.RedactCopy(summary).(*report.TargetSummary)
+	if err := writeWorkspaceIndex(summary, wi.cfg.Output.Permissions.FilePerm()); err != nil {
+		wi.logger.Warn("Failed to write workspace index", "error", err)
+	}
+}
+
+// writeWorkspaceIndex writes INDEX.md for a (redacted) target summary and its workspace's workflow summaries
+func writeWorkspaceIndex(summary *report.TargetSummary, perm os.FileMode) error {
+	var workflows []report.IndexWorkflow
+	if summaries, err := executor.LoadWorkflowSummaries(summary.Workspace); err == nil {
+		for _, s := range summaries {
+			workflows = append(workflows, report.IndexWorkflow{
+				Name:      s.Workflow,
+				Status:    s.Status,
+				OpenPorts: len(s.OpenPorts),
+				URLs:      len(s.URLs),
+			})
+		}
+	}
+	_, err := report.WriteIndex(summary, workflows, perm)
+	return err
+}
@@ -1,7 +1,9 @@
 package embedded
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
 	"os"
@@ -107,7 +109,8 @@ func GetAllWorkflowPaths() (map[string][]string, error) {
 			return err
 		}
 		
-		if !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+		// descriptions.yaml describes the categories; it is not a workflow
+		if !d.IsDir() && strings.HasSuffix(path, ".yaml") && d.Name() != "descriptions.yaml" {
 			// Extract category from path (first directory)
 			parts := strings.Split(path, "/")
 			category := "uncategorized"
@@ -124,6 +127,40 @@ func GetAllWorkflowPaths() (map[string][]string, error) {
 	return workflowsByCategory, err
 }
 
+// WorkflowBundleHash returns the SHA-256 of the embedded workflow definitions, so results can be
+// traced to the exact workflows a binary shipped with
+func WorkflowBundleHash() (string, error) {
+	return HashFS(GetWorkflowFS(), func(path string) bool {
+		return strings.HasSuffix(path, ".yaml")
+	})
+}
+
+// HashFS returns a SHA-256 over the paths and contents of the files in fsys accepted by match.
+// Files are visited in lexical order, so the hash only changes when a definition does
+func HashFS(fsys fs.FS, match func(path string) bool) (string, error) {
+	hash := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !match(path) {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		// Length-prefix both parts so that moving bytes between a path and its content changes the hash
+		fmt.Fprintf(hash, "%d:%s\n%d:", len(path), path, len(data))
+		hash.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 // ExtractEmbeddedResources extracts all embedded resources to a directory
 // This is useful for development or when the filesystem is needed
 func ExtractEmbeddedResources(targetDir string) error {
@@ -0,0 +1,89 @@
+package api
+
+import "github.com/neur0map/ipcrawler/internal/executor"
+
+// Progress is handed to the Runner to describe the scan and record its progress
+type Progress struct {
+	server *Server
+	scan   *Scan
+}
+
+// ID returns the scan ID, which the run uses as its scan_id
+func (p *Progress) ID() string {
+	return p.scan.ID
+}
+
+// Request returns the request the scan was queued with
+func (p *Progress) Request() ScanRequest {
+	return ScanRequest{Target: p.scan.Target, Workflows: p.scan.Workflows, Labels: p.scan.Labels}
+}
+
+// SetWorkspace records the workspace directory once the run has created it
+func (p *Progress) SetWorkspace(dir string) {
+	p.server.mutex.Lock()
+	defer p.server.mutex.Unlock()
+	p.scan.Workspace = dir
+}
+
+// SetExecutionStatus sets where GET /scans/{id} reads the tool execution state while the scan runs
+func (p *Progress) SetExecutionStatus(status func() executor.ExecutionStatus) {
+	p.server.mutex.Lock()
+	defer p.server.mutex.Unlock()
+	p.scan.executionStatus = status
+}
+
+// Event records an orchestrator lifecycle event in the workflow's progress
+func (p *Progress) Event(event executor.Event) {
+	if event.Type == executor.EventToolOutputLine {
+		return
+	}
+	p.server.mutex.Lock()
+	defer p.server.mutex.Unlock()
+
+	progress := p.workflow(event.Workflow)
+	if event.Message != "" {
+		progress.LastMessage = event.Message
+	}
+	switch event.Type {
+	case executor.EventWorkflowQueued, executor.EventWorkflowTriggered:
+		progress.TotalSteps = event.StepCount
+	case executor.EventWorkflowStarted:
+		progress.Status = ScanRunning
+	case executor.EventWorkflowProgress:
+		progress.Percent, progress.ETASeconds = event.Percent, event.Remaining
+	case executor.EventStepCompleted:
+		progress.CompletedSteps++
+	case executor.EventStepFailed:
+		progress.FailedSteps++
+	case executor.EventStepSkipped:
+		progress.SkippedSteps++
+	case executor.EventWorkflowCompleted:
+		progress.Status = ScanCompleted
+		if progress.Percent > 0 {
+			progress.Percent, progress.ETASeconds = 100, 0
+		}
+	case executor.EventWorkflowFailed:
+		progress.Status = ScanFailed
+	case executor.EventWorkflowCancelled:
+		progress.Status = ScanCancelled
+	}
+}
+
+// Warning records a scan warning; kind is one of the output.Warning* kinds
+func (p *Progress) Warning(kind, message string) {
+	p.server.mutex.Lock()
+	defer p.server.mutex.Unlock()
+	p.scan.Warnings = append(p.scan.Warnings, ScanWarning{Kind: kind, Message: message})
+}
+
+// workflow returns the progress entry for a workflow, creating it if needed; callers hold the lock
+func (p *Progress) workflow(name string) *WorkflowProgress {
+	for _, progress := range p.scan.Progress {
+		if progress.Workflow == name {
+			return progress
+		}
+	}
+	progress := &WorkflowProgress{Workflow: name, Status: ScanQueued}
+	p.scan.Progress = append(p.scan.Progress, progress)
+	return progress
+}
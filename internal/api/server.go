@@ -0,0 +1,323 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// Scan states reported by the API
+const (
+	ScanQueued    = "queued"
+	ScanRunning   = "running"
+	ScanCompleted = "completed"
+	ScanFailed    = "failed"
+	ScanCancelled = "cancelled"
+)
+
+// ScanRequest is the body of POST /scans
+type ScanRequest struct {
+	Target    string   `json:"target"`
+	Workflows []string `json:"workflows,omitempty"` // Workflow names; all workflows when empty
+	Labels    []string `json:"labels,omitempty"`
+}
+
+// Scan is the status of one queued or executed scan
+type Scan struct {
+	ID         string              `json:"id"`
+	Target     string              `json:"target"`
+	Workflows  []string            `json:"workflows,omitempty"`
+	Labels     []string            `json:"labels,omitempty"`
+	Status     string              `json:"status"`
+	Workspace  string              `json:"workspace,omitempty"`
+	QueuedAt   time.Time           `json:"queued_at"`
+	StartedAt  *time.Time          `json:"started_at,omitempty"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	Progress   []*WorkflowProgress `json:"progress"`
+	Warnings   []ScanWarning       `json:"warnings,omitempty"`
+
+	// Tool slots, queue and running tools; only in GET /scans/{id} while the scan runs
+	Execution *executor.ExecutionStatus `json:"execution,omitempty"`
+
+	executionStatus func() executor.ExecutionStatus
+}
+
+// ScanWarning is something that made the scan imperfect without failing it, e.g. a tool that
+// timed out with partial results
+type ScanWarning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// WorkflowProgress tracks the steps of one workflow within a scan
+type WorkflowProgress struct {
+	Workflow       string `json:"workflow"`
+	Status         string `json:"status"`
+	TotalSteps     int    `json:"total_steps"`
+	CompletedSteps int    `json:"completed_steps"`
+	FailedSteps    int    `json:"failed_steps"`
+	SkippedSteps   int    `json:"skipped_steps"`
+	Percent        int    `json:"percent,omitempty"`     // Estimated from past tool durations, when known
+	ETASeconds     int    `json:"eta_seconds,omitempty"` // Estimated seconds until the workflow ends
+	LastMessage    string `json:"last_message,omitempty"`
+}
+
+// Runner executes one scan and reports its progress; it is called for one scan at a time
+type Runner func(ctx context.Context, scan *Progress) error
+
+// Validator rejects requests before they are queued (unknown workflows, excluded targets)
+type Validator func(request ScanRequest) error
+
+// Options configure a Server
+type Options struct {
+	Runner          Runner
+	Validate        Validator
+	Token           string // Required as "Authorization: Bearer <token>" when set
+	ResultsEncoding string // Default encoding for GET /scans/{id}/results
+	QueueSize       int
+}
+
+// Server exposes the orchestrator over HTTP. Scans are queued and executed one after
+// another; each scan still runs its workflows concurrently
+type Server struct {
+	options Options
+	queue   chan *Scan
+
+	mutex sync.RWMutex
+	scans map[string]*Scan
+	order []string
+}
+
+// NewServer creates a server; call Run to start executing queued scans
+func NewServer(options Options) *Server {
+	if options.QueueSize <= 0 {
+		options.QueueSize = 64
+	}
+	return &Server{
+		options: options,
+		queue:   make(chan *Scan, options.QueueSize),
+		scans:   make(map[string]*Scan),
+	}
+}
+
+// Run executes queued scans until ctx is cancelled
+func (s *Server) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case scan := <-s.queue:
+			s.execute(ctx, scan)
+		}
+	}
+}
+
+// execute runs one scan and records its final state
+func (s *Server) execute(ctx context.Context, scan *Scan) {
+	started := time.Now().Round(0)
+	s.mutex.Lock()
+	scan.Status = ScanRunning
+	scan.StartedAt = &started
+	s.mutex.Unlock()
+
+	err := s.options.Runner(ctx, &Progress{server: s, scan: scan})
+
+	finished := time.Now().Round(0)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	scan.FinishedAt = &finished
+	scan.Status = ScanCompleted
+	if err != nil {
+		scan.Status = ScanFailed
+		scan.Error = err.Error()
+	}
+}
+
+// Handler returns the HTTP routes:
+//
+//	POST /scans               queue a target
+//	GET  /scans               list scans
+//	GET  /scans/{id}          status, step progress and tool execution state
+//	GET  /scans/{id}/results  the run report (JSON or protobuf)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scans", s.handleCreate)
+	mux.HandleFunc("GET /scans", s.handleList)
+	mux.HandleFunc("GET /scans/{id}", s.handleStatus)
+	mux.HandleFunc("GET /scans/{id}/results", s.handleResults)
+	return s.authenticate(mux)
+}
+
+// authenticate requires the bearer token when one is configured
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.options.Token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.options.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var request ScanRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	request.Target = strings.TrimSpace(request.Target)
+	if request.Target == "" {
+		writeError(w, http.StatusBadRequest, "target is required")
+		return
+	}
+	if s.options.Validate != nil {
+		if err := s.options.Validate(request); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	scan := &Scan{
+		ID:        session.NewScanID(),
+		Target:    request.Target,
+		Workflows: request.Workflows,
+		Labels:    request.Labels,
+		Status:    ScanQueued,
+		QueuedAt:  time.Now().Round(0),
+		Progress:  make([]*WorkflowProgress, 0),
+	}
+
+	s.mutex.Lock()
+	select {
+	case s.queue <- scan:
+		s.scans[scan.ID] = scan
+		s.order = append(s.order, scan.ID)
+	default:
+		s.mutex.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "scan queue is full")
+		return
+	}
+	response := s.copyScan(scan)
+	s.mutex.Unlock()
+
+	w.Header().Set("Location", "/scans/"+scan.ID)
+	writeJSON(w, http.StatusAccepted, response)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	scans := make([]Scan, 0, len(s.order))
+	for _, id := range s.order {
+		scans = append(scans, s.copyScan(s.scans[id]))
+	}
+	s.mutex.RUnlock()
+	writeJSON(w, http.StatusOK, map[string][]Scan{"scans": scans})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	scan, exists := s.scans[r.PathValue("id")]
+	var response Scan
+	var executionStatus func() executor.ExecutionStatus
+	if exists {
+		response = s.copyScan(scan)
+		if scan.Status == ScanRunning {
+			executionStatus = scan.executionStatus
+		}
+	}
+	s.mutex.RUnlock()
+
+	if !exists {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+	if executionStatus != nil {
+		status := executionStatus()
+		response.Execution = &status
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	scan, exists := s.scans[r.PathValue("id")]
+	var status, workspace string
+	if exists {
+		status, workspace = scan.Status, scan.Workspace
+	}
+	s.mutex.RUnlock()
+
+	switch {
+	case !exists:
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	case status == ScanQueued || status == ScanRunning:
+		writeError(w, http.StatusConflict, fmt.Sprintf("scan is %s; results are available once it finishes", status))
+		return
+	case workspace == "":
+		writeError(w, http.StatusNotFound, "scan produced no workspace")
+		return
+	}
+
+	report, err := output.LoadRunReport(workspace)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no results: %v", err))
+		return
+	}
+
+	encoding := s.options.ResultsEncoding
+	switch accept := r.Header.Get("Accept"); {
+	case strings.Contains(accept, "application/x-protobuf"):
+		encoding = output.EncodingProtobuf
+	case strings.Contains(accept, "application/json"):
+		encoding = output.EncodingJSON
+	}
+	if encoding == output.EncodingProtobuf {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+		w.Write(output.MarshalRunReportProto(*report))
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// copyScan returns a snapshot safe to encode outside the lock; callers hold s.mutex
+func (s *Server) copyScan(scan *Scan) Scan {
+	snapshot := *scan
+	snapshot.Progress = make([]*WorkflowProgress, len(scan.Progress))
+	for i, progress := range scan.Progress {
+		copied := *progress
+		snapshot.Progress[i] = &copied
+	}
+	snapshot.Warnings = append([]ScanWarning(nil), scan.Warnings...)
+	snapshot.executionStatus = nil
+	return snapshot
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,488 @@
+// Package archive packs a finished workspace into a single compressed file and restores it.
+// Every archive carries a checksum manifest of the files it holds, which restoring verifies
+// before the workspace appears on disk
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChecksumFileName is the checksum manifest stored at the root of every archive, in the
+// format of sha256sum so it can also be checked with `sha256sum -c`
+const ChecksumFileName = "CHECKSUMS.sha256"
+
+// Archive formats
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+)
+
+// Summary counts what was archived or restored
+type Summary struct {
+	Workspace string // Name of the workspace directory
+	Files     int
+	Bytes     int64 // Uncompressed size of the files
+}
+
+// FormatOf returns the format of an archive file from its extension
+func FormatOf(archivePath string) (string, error) {
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatTarGz, nil
+	case strings.HasSuffix(name, ".zip"):
+		return FormatZip, nil
+	default:
+		return "", fmt.Errorf("%s is not a .tar.gz or .zip archive", archivePath)
+	}
+}
+
+// FileName is the name of a workspace's archive in the given format
+func FileName(workspaceDir, format string) string {
+	return filepath.Base(workspaceDir) + "." + format
+}
+
+// IsArchive reports whether a path names an archive by its extension
+func IsArchive(archivePath string) bool {
+	_, err := FormatOf(archivePath)
+	return err == nil
+}
+
+// entryWriter adds files to an archive being written
+type entryWriter interface {
+	add(name string, info os.FileInfo, body io.Reader) error
+	Close() error
+}
+
+// Create packs workspaceDir into archivePath, in the format its extension names. Entries are
+// stored under the workspace's directory name, followed by the checksum manifest. The archive
+// is written to a temporary file first, so an interrupted run never leaves half of one
+func Create(workspaceDir, archivePath string, perm os.FileMode) (*Summary, error) {
+	format, err := FormatOf(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := workspaceFiles(workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath := archivePath + ".tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	summary, err := writeArchive(out, format, workspaceDir, files)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, archivePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to write %s: %w", archivePath, err)
+	}
+	return summary, nil
+}
+
+// workspaceFiles lists the regular files below dir by slash-separated relative path. A
+// checksum manifest left by an earlier restore is skipped, since a new one is written
+func workspaceFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil // Directories are implied by the files; links and devices are not archived
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != ChecksumFileName {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func writeArchive(out io.Writer, format, workspaceDir string, files []string) (*Summary, error) {
+	var writer entryWriter
+	if format == FormatZip {
+		writer = &zipWriter{zip: zip.NewWriter(out)}
+	} else {
+		writer = newTarWriter(out)
+	}
+	root := filepath.Base(workspaceDir)
+	summary := &Summary{Workspace: root}
+
+	var checksums strings.Builder
+	for _, rel := range files {
+		file, err := os.Open(filepath.Join(workspaceDir, filepath.FromSlash(rel)))
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+		info, err := file.Stat()
+		if err == nil {
+			hash := sha256.New()
+			err = writer.add(path.Join(root, rel), info, io.TeeReader(file, hash))
+			fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(hash.Sum(nil)), rel)
+			summary.Files++
+			summary.Bytes += info.Size()
+		}
+		file.Close()
+		if err != nil {
+			writer.Close()
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	}
+
+	manifest := checksumInfo{size: int64(checksums.Len()), modTime: time.Now()}
+	if err := writer.add(path.Join(root, ChecksumFileName), manifest, strings.NewReader(checksums.String())); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return summary, writer.Close()
+}
+
+// checksumInfo describes the generated checksum manifest to the archive writers
+type checksumInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (i checksumInfo) Name() string       { return ChecksumFileName }
+func (i checksumInfo) Size() int64        { return i.size }
+func (i checksumInfo) Mode() os.FileMode  { return 0644 }
+func (i checksumInfo) ModTime() time.Time { return i.modTime }
+func (i checksumInfo) IsDir() bool        { return false }
+func (i checksumInfo) Sys() interface{}   { return nil }
+
+type tarWriter struct {
+	gzip *gzip.Writer
+	tar  *tar.Writer
+}
+
+func newTarWriter(out io.Writer) *tarWriter {
+	gz := gzip.NewWriter(out)
+	return &tarWriter{gzip: gz, tar: tar.NewWriter(gz)}
+}
+
+func (w *tarWriter) add(name string, info os.FileInfo, body io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     info.Size(),
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+		Format:   tar.FormatPAX,
+	}
+	if err := w.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	// A file growing while it is archived is cut at the size in its header
+	_, err := io.Copy(w.tar, io.LimitReader(body, info.Size()))
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	err := w.tar.Close()
+	if gzErr := w.gzip.Close(); err == nil {
+		err = gzErr
+	}
+	return err
+}
+
+type zipWriter struct {
+	zip *zip.Writer
+}
+
+func (w *zipWriter) add(name string, info os.FileInfo, body io.Reader) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: info.ModTime()}
+	header.SetMode(info.Mode().Perm())
+	entry, err := w.zip.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, io.LimitReader(body, info.Size()))
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zip.Close()
+}
+
+// Extract restores an archive as a workspace directory below parentDir, which must not hold
+// one of that name yet. The files are written to a temporary directory and only moved into
+// place once every checksum in the manifest matches
+func Extract(archivePath, parentDir string, dirPerm, filePerm os.FileMode) (*Summary, error) {
+	if err := os.MkdirAll(parentDir, dirPerm); err != nil {
+		return nil, err
+	}
+	tmpDir, err := os.MkdirTemp(parentDir, ".restore-")
+	if err != nil {
+		return nil, err
+	}
+	summary, err := readArchive(archivePath, func(rel string, mode os.FileMode, body io.Reader) error {
+		target := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), dirPerm); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode&filePerm|0600)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, body)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+	if err == nil {
+		err = os.Chmod(tmpDir, dirPerm)
+	}
+	if err == nil {
+		destDir := filepath.Join(parentDir, summary.Workspace)
+		if _, statErr := os.Lstat(destDir); statErr == nil {
+			err = fmt.Errorf("%s already exists", destDir)
+		} else {
+			err = os.Rename(tmpDir, destDir)
+		}
+	}
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Verify checks every file of an archive against its checksum manifest without writing them
+func Verify(archivePath string) (*Summary, error) {
+	return readArchive(archivePath, func(rel string, mode os.FileMode, body io.Reader) error {
+		_, err := io.Copy(io.Discard, body)
+		return err
+	})
+}
+
+// errFound stops reading an archive once ReadFile has the file it wants
+var errFound = errors.New("found")
+
+// ReadFile returns one file of an archive by its path relative to the workspace root, without
+// checking the archive's checksums
+func ReadFile(archivePath, rel string) ([]byte, error) {
+	format, err := FormatOf(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	visit := func(name string, mode os.FileMode, body io.Reader) error {
+		if _, entryRel, err := splitEntry(name); err != nil || entryRel != rel {
+			return err
+		}
+		if data, err = io.ReadAll(io.LimitReader(body, 64<<20)); err != nil {
+			return err
+		}
+		return errFound
+	}
+	if format == FormatZip {
+		err = readZip(archivePath, visit)
+	} else {
+		err = readTar(archivePath, visit)
+	}
+	if err == errFound {
+		return data, nil
+	}
+	if err == nil {
+		err = os.ErrNotExist
+	}
+	return nil, fmt.Errorf("%s in %s: %w", rel, archivePath, err)
+}
+
+// readArchive passes every file of an archive to store with its path relative to the
+// workspace root, hashing what store reads, then checks the hashes against the manifest
+func readArchive(archivePath string, store func(rel string, mode os.FileMode, body io.Reader) error) (*Summary, error) {
+	format, err := FormatOf(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{}
+	hashes := make(map[string]string)
+	var manifest []byte
+
+	visit := func(name string, mode os.FileMode, body io.Reader) error {
+		root, rel, err := splitEntry(name)
+		if err != nil {
+			return err
+		}
+		if summary.Workspace == "" {
+			summary.Workspace = root
+		} else if root != summary.Workspace {
+			return fmt.Errorf("archive holds more than one workspace (%s and %s)", summary.Workspace, root)
+		}
+		if _, exists := hashes[rel]; exists {
+			return fmt.Errorf("%s appears twice in the archive", rel)
+		}
+		hash := sha256.New()
+		counter := &countingReader{reader: io.TeeReader(body, hash)}
+		if rel == ChecksumFileName {
+			if manifest, err = io.ReadAll(io.LimitReader(counter, 64<<20)); err != nil {
+				return err
+			}
+			counter = &countingReader{reader: strings.NewReader(string(manifest))}
+		}
+		if err := store(rel, mode, counter); err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		hashes[rel] = hex.EncodeToString(hash.Sum(nil))
+		if rel != ChecksumFileName {
+			summary.Files++
+			summary.Bytes += counter.read
+		}
+		return nil
+	}
+
+	if format == FormatZip {
+		err = readZip(archivePath, visit)
+	} else {
+		err = readTar(archivePath, visit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", archivePath, err)
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s has no %s; it was not created by ipcrawler archive", archivePath, ChecksumFileName)
+	}
+	if err := checkManifest(manifest, hashes); err != nil {
+		return nil, fmt.Errorf("%s is damaged: %w", archivePath, err)
+	}
+	return summary, nil
+}
+
+// splitEntry splits an entry name into the workspace directory and a path below it, rejecting
+// names that would leave the workspace
+func splitEntry(name string) (string, string, error) {
+	root, rel, found := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+	if !found || rel == "" || !filepath.IsLocal(root) || !filepath.IsLocal(filepath.FromSlash(rel)) || strings.Contains(rel, "\\") {
+		return "", "", fmt.Errorf("unexpected entry %q", name)
+	}
+	return root, path.Clean(rel), nil
+}
+
+// checkManifest compares the hashes of the extracted files with the checksum manifest; a file
+// missing from either side is as much a mismatch as a changed one
+func checkManifest(manifest []byte, hashes map[string]string) error {
+	listed := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(string(manifest)))
+	for scanner.Scan() {
+		sum, rel, found := strings.Cut(scanner.Text(), "  ")
+		if !found {
+			continue
+		}
+		listed[rel] = true
+		hash, exists := hashes[rel]
+		if !exists {
+			return fmt.Errorf("%s is listed in %s but missing", rel, ChecksumFileName)
+		}
+		if hash != sum {
+			return fmt.Errorf("checksum mismatch for %s", rel)
+		}
+	}
+	for rel := range hashes {
+		if rel != ChecksumFileName && !listed[rel] {
+			return fmt.Errorf("%s is not listed in %s", rel, ChecksumFileName)
+		}
+	}
+	return nil
+}
+
+func readTar(archivePath string, visit func(name string, mode os.FileMode, body io.Reader) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeReg:
+			if err := visit(header.Name, os.FileMode(header.Mode).Perm(), reader); err != nil {
+				return err
+			}
+		case tar.TypeDir:
+		default:
+			return fmt.Errorf("unexpected entry %q", header.Name)
+		}
+	}
+}
+
+func readZip(archivePath string, visit func(name string, mode os.FileMode, body io.Reader) error) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if !entry.Mode().IsRegular() {
+			return fmt.Errorf("unexpected entry %q", entry.Name)
+		}
+		body, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = visit(entry.Name, entry.Mode().Perm(), body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	return n, err
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -14,6 +16,18 @@ type Config struct {
 	Security SecurityConfig `mapstructure:"security"`
 	Output   OutputConfig   `mapstructure:"output"`
 	Tools    ToolsConfig    `mapstructure:"tools"`
+	Labels   LabelsConfig   `mapstructure:"labels"`
+
+	Integrations IntegrationsConfig `mapstructure:"integrations"`
+	Wordlists    WordlistsConfig    `mapstructure:"wordlists"`
+	Storage      StorageConfig      `mapstructure:"storage"`
+	Passive      PassiveConfig      `mapstructure:"passive"`
+	Heuristics   HeuristicsConfig   `mapstructure:"heuristics"`
+	Triage       TriageConfig       `mapstructure:"triage"`
+	Experimental map[string]bool    `mapstructure:"experimental"` // Experimental feature flags by name (see internal/features)
+
+	// Scan profiles by name, chosen with -p (see ProfileConfig)
+	Profiles map[string]ProfileConfig `mapstructure:"profiles"`
 }
 
 // UIConfig represents UI configuration
@@ -139,11 +153,13 @@ type PerformanceConfig struct {
 }
 
 type DisplayConfig struct {
-	ShowTimestamps  bool `mapstructure:"show_timestamps"`
-	ShowProgress    bool `mapstructure:"show_progress"`
-	ShowSpinner     bool `mapstructure:"show_spinner"`
-	WordWrap        bool `mapstructure:"word_wrap"`
-	SyntaxHighlight bool `mapstructure:"syntax_highlight"`
+	ShowTimestamps  bool   `mapstructure:"show_timestamps"`
+	ShowProgress    bool   `mapstructure:"show_progress"`
+	ShowSpinner     bool   `mapstructure:"show_spinner"`
+	WordWrap        bool   `mapstructure:"word_wrap"`
+	SyntaxHighlight bool   `mapstructure:"syntax_highlight"`
+	StatusSymbols   string `mapstructure:"status_symbols"` // auto, unicode or ascii
+	SymbolsOnly     bool   `mapstructure:"symbols_only"`   // No colors; statuses are told apart by symbol
 }
 
 type FormattingConfig struct {
@@ -164,12 +180,15 @@ type SecurityConfig struct {
 	Scanning    ScanningConfig          `mapstructure:"scanning"`
 	Detection   DetectionConfig         `mapstructure:"detection"`
 	Reporting   ReportingConfig         `mapstructure:"reporting"`
+	Evasion     EvasionConfig           `mapstructure:"evasion"`
 }
 
 type SecurityExecutionConfig struct {
-	ToolsRoot       string `mapstructure:"tools_root"`
-	ArgsValidation  bool   `mapstructure:"args_validation"`
-	ExecValidation  bool   `mapstructure:"exec_validation"`
+	ToolsRoot         string   `mapstructure:"tools_root"`
+	ArgsValidation    bool     `mapstructure:"args_validation"`
+	ExecValidation    bool     `mapstructure:"exec_validation"`
+	DropPrivileges    bool     `mapstructure:"drop_privileges"`
+	AllowedOutputDirs []string `mapstructure:"allowed_output_dirs"` // Directories outside the workspace tools may write to
 }
 
 type ScanningConfig struct {
@@ -181,6 +200,16 @@ type ScanningConfig struct {
 	SkipSSLVerify  bool     `mapstructure:"skip_ssl_verify"`
 }
 
+// EvasionConfig controls which stealth options workflows may request via step parameters
+type EvasionConfig struct {
+	AllowDecoys        bool `mapstructure:"allow_decoys"`
+	MaxDecoys          int  `mapstructure:"max_decoys"`
+	AllowFragmentation bool `mapstructure:"allow_fragmentation"`
+	AllowSourcePort    bool `mapstructure:"allow_source_port"`
+	MinTiming          int  `mapstructure:"min_timing"`
+	MaxTiming          int  `mapstructure:"max_timing"`
+}
+
 type DetectionConfig struct {
 	SeverityLevels   []string `mapstructure:"severity_levels"`
 	IgnorePatterns   []string `mapstructure:"ignore_patterns"`
@@ -195,6 +224,160 @@ type ReportingConfig struct {
 	Redaction    bool     `mapstructure:"redaction"`
 }
 
+// LabelsConfig assigns labels to hosts for organizing findings by network segment or importance
+type LabelsConfig struct {
+	Rules []LabelRule `mapstructure:"rules"`
+}
+
+// LabelRule applies a label to every host matching one of its CIDRs or hosts
+type LabelRule struct {
+	Label string   `mapstructure:"label"`
+	CIDRs []string `mapstructure:"cidrs"`
+	Hosts []string `mapstructure:"hosts"`
+}
+
+// WordlistsConfig picks brute-force wordlists from the technologies detected on the target
+type WordlistsConfig struct {
+	Default string         `mapstructure:"default"` // Used until (or unless) a rule matches
+	Rules   []WordlistRule `mapstructure:"rules"`   // Earlier rules win
+}
+
+// WordlistRule selects a wordlist when a detected technology contains one of its keywords
+type WordlistRule struct {
+	Name     string   `mapstructure:"name"`
+	Match    []string `mapstructure:"match"` // Case-insensitive keywords, e.g. "iis", "wordpress"
+	Wordlist string   `mapstructure:"wordlist"`
+}
+
+// IntegrationsConfig configures exporters that push results into external systems
+type IntegrationsConfig struct {
+	Issues        IssuesConfig        `mapstructure:"issues"`
+	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+}
+
+// NotificationsConfig posts scan events to webhooks as they happen
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `mapstructure:"webhooks"`
+}
+
+// WebhookConfig is one webhook and the events sent to it
+type WebhookConfig struct {
+	Name           string   `mapstructure:"name"`
+	URL            string   `mapstructure:"url"`
+	URLEnv         string   `mapstructure:"url_env"`         // Environment variable holding the URL (takes precedence over url)
+	Format         string   `mapstructure:"format"`          // "json" (default), "slack" or "discord"
+	Events         []string `mapstructure:"events"`          // workflow_completed, workflow_failed, new_findings (default: all)
+	TimeoutSeconds int      `mapstructure:"timeout_seconds"`
+}
+
+// ElasticsearchConfig configures indexing results into Elasticsearch or OpenSearch
+type ElasticsearchConfig struct {
+	Enabled            bool   `mapstructure:"enabled"` // Ship every run automatically when it finishes
+	URL                string `mapstructure:"url"`
+	FindingsIndex      string `mapstructure:"findings_index"`
+	ExecutionsIndex    string `mapstructure:"executions_index"`
+	Username           string `mapstructure:"username"`
+	PasswordEnv        string `mapstructure:"password_env"` // Environment variable holding the password
+	APIKeyEnv          string `mapstructure:"api_key_env"`  // Environment variable holding an API key
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	TimeoutSeconds     int    `mapstructure:"timeout_seconds"`
+}
+
+// HeuristicsConfig flags findings for manual review in reports (see internal/heuristics)
+type HeuristicsConfig struct {
+	Enabled         bool            `mapstructure:"enabled"`
+	BannerConflicts bool            `mapstructure:"banner_conflicts"` // Flag ports where tools identify different services or servers
+	MaxOpenPorts    int             `mapstructure:"max_open_ports"`   // Flag hosts with more open ports than this (0 disables)
+	ExpectedPorts   []ExpectedPorts `mapstructure:"expected_ports"`   // Flag services found outside their usual ports
+	Rules           []HeuristicRule `mapstructure:"rules"`
+}
+
+// ExpectedPorts lists the ports a service usually runs on
+type ExpectedPorts struct {
+	Service string `mapstructure:"service"`
+	Ports   []int  `mapstructure:"ports"`
+}
+
+// HeuristicRule flags findings whose banners match a regular expression
+type HeuristicRule struct {
+	Name    string   `mapstructure:"name"`
+	Kind    string   `mapstructure:"kind"`    // e.g. "honeypot"; default "banner"
+	Pattern string   `mapstructure:"pattern"` // Go regular expression
+	Fields  []string `mapstructure:"fields"`  // service, product, title, technologies, scripts, url (default: all)
+	Message string   `mapstructure:"message"`
+}
+
+// TriageConfig suggests manual checks for `ipcrawler triage` checklists (see internal/report)
+type TriageConfig struct {
+	Services []TriageService `mapstructure:"services"`
+	Default  []string        `mapstructure:"default"` // Checks for open ports no entry matches
+}
+
+// TriageService lists the manual checks for a service, matched by name or port
+type TriageService struct {
+	Service string   `mapstructure:"service"` // Matched like heuristics expected_ports: ssl/ dropped, http* is http
+	Ports   []int    `mapstructure:"ports"`   // Also match these ports, whatever the service
+	Checks  []string `mapstructure:"checks"`  // {{host}}, {{port}}, {{protocol}} and {{url}} are filled in
+}
+
+// ProfileConfig is a scan profile: `ipcrawler -p <name>` runs the workflows whose tags pass
+// its filter
+type ProfileConfig struct {
+	Description string `mapstructure:"description"`
+	Tags        string `mapstructure:"tags"` // Tag filter, e.g. "passive && !loud" (see executor.ParseTagFilter)
+}
+
+// PassiveConfig configures the passive sources of `ipcrawler passive` (see internal/passive)
+type PassiveConfig struct {
+	Sources        []string       `mapstructure:"sources"`         // Queried when none are named (default: every free source)
+	CacheTTL       string         `mapstructure:"cache_ttl"`       // How long answers are reused, e.g. "24h"; "0" disables the cache
+	TimeoutSeconds int            `mapstructure:"timeout_seconds"` // Per request
+	UserAgent      string         `mapstructure:"user_agent"`
+	RateLimits     map[string]int `mapstructure:"rate_limits"` // Requests per minute by source, replacing the built-in limits
+}
+
+// StorageConfig configures the backend workspaces are copied to (see internal/storage)
+type StorageConfig struct {
+	Backend           string             `mapstructure:"backend"`             // "" (workspaces stay local only), "local" or "s3"
+	Prefix            string             `mapstructure:"prefix"`              // Key prefix workspaces are stored under
+	UploadAfterRun    bool               `mapstructure:"upload_after_run"`    // Copy every run's workspace when it finishes
+	RemoveAfterUpload bool               `mapstructure:"remove_after_upload"` // Delete the local workspace once it is stored
+	Local             LocalStorageConfig `mapstructure:"local"`
+	S3                S3StorageConfig    `mapstructure:"s3"`
+}
+
+// LocalStorageConfig stores workspaces below a directory, e.g. a shared mount
+type LocalStorageConfig struct {
+	Directory string `mapstructure:"directory"`
+}
+
+// S3StorageConfig stores workspaces in an S3-compatible bucket (AWS S3, MinIO)
+type S3StorageConfig struct {
+	Endpoint           string `mapstructure:"endpoint"` // Empty for AWS; e.g. http://minio:9000
+	Region             string `mapstructure:"region"`
+	Bucket             string `mapstructure:"bucket"`
+	PathStyle          bool   `mapstructure:"path_style"`        // endpoint/bucket addressing, as MinIO needs
+	AccessKeyEnv       string `mapstructure:"access_key_env"`    // Environment variable holding the access key
+	SecretKeyEnv       string `mapstructure:"secret_key_env"`    // Environment variable holding the secret key
+	SessionTokenEnv    string `mapstructure:"session_token_env"` // Environment variable holding a session token
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	TimeoutSeconds     int    `mapstructure:"timeout_seconds"`
+}
+
+// IssuesConfig configures opening findings as GitHub or GitLab issues
+type IssuesConfig struct {
+	Provider   string   `mapstructure:"provider"`   // "github" or "gitlab"
+	Repository string   `mapstructure:"repository"` // owner/repo (GitHub) or group/project or numeric ID (GitLab)
+	BaseURL    string   `mapstructure:"base_url"`   // API root for self-hosted instances
+	TokenEnv   string   `mapstructure:"token_env"`  // Environment variable holding the API token
+	GroupBy    string   `mapstructure:"group_by"`   // "host" or "finding"
+	Labels     []string `mapstructure:"labels"`     // Added to every issue
+
+	// Severity maps a level to the services (globs) or port numbers that raise a finding to it
+	Severity map[string][]string `mapstructure:"severity"`
+}
+
 // OutputConfig matches the current configs/output.yaml schema (multi-sink by level)
 // Example (top-level file without an "output:" wrapper):
 //
@@ -207,13 +390,118 @@ type OutputConfig struct {
 	WorkspaceBase      string        `mapstructure:"workspace_base"`
 	Timestamp          bool          `mapstructure:"timestamp"`
 	TimeFormat         string        `mapstructure:"time_format"`
+	Timezone           string        `mapstructure:"timezone"`
 	ScanOutputMode     string        `mapstructure:"scan_output_mode"`
+	ResultsEncoding    string        `mapstructure:"results_encoding"`
 	CreateLatestLinks  bool          `mapstructure:"create_latest_links"`
+	UsageStats         bool          `mapstructure:"usage_stats"` // Record workflow/tool usage locally for `ipcrawler stats --usage`
+	RawIndex           bool          `mapstructure:"raw_index"`   // Index raw tool output after each run for `ipcrawler search --raw`
 	Info               LogSinkConfig `mapstructure:"info"`
 	Error              LogSinkConfig `mapstructure:"error"`
 	Warning            LogSinkConfig `mapstructure:"warning"`
 	Debug              LogSinkConfig `mapstructure:"debug"`
 	Raw                RawSinkConfig `mapstructure:"raw"`
+	Permissions        PermissionsConfig `mapstructure:"permissions"`
+	Redaction          RedactionConfig   `mapstructure:"redaction"`
+	Archive            ArchiveConfig     `mapstructure:"archive"`
+	Retention          RetentionConfig   `mapstructure:"retention"`
+}
+
+// RetentionConfig limits the workspaces kept under the workspace base (see internal/retention)
+type RetentionConfig struct {
+	MaxWorkspaces    int     `mapstructure:"max_workspaces"`     // Keep at most this many (0 = unlimited)
+	MaxAgeDays       int     `mapstructure:"max_age_days"`       // Remove workspaces older than this (0 = unlimited)
+	MaxTotalSizeGB   float64 `mapstructure:"max_total_size_gb"`  // Remove the oldest until the rest fit (0 = unlimited)
+	IncludeArchives  bool    `mapstructure:"include_archives"`   // Apply the limits to workspace archives too
+	EnforceAtStartup bool    `mapstructure:"enforce_at_startup"` // Prune when a scan starts, not only with `ipcrawler clean`
+}
+
+// ArchiveConfig controls packing finished workspaces into archives (see internal/archive)
+type ArchiveConfig struct {
+	AutoArchive     bool   `mapstructure:"auto_archive"`     // Archive every completed run's workspace
+	Format          string `mapstructure:"format"`           // "tar.gz" (default) or "zip"
+	Directory       string `mapstructure:"directory"`        // Where archives are written (default: next to the workspace)
+	RemoveWorkspace bool   `mapstructure:"remove_workspace"` // Delete the workspace directory once its archive is verified
+}
+
+// RedactionConfig lists patterns scrubbed from console output, logs and reports
+type RedactionConfig struct {
+	Enabled  bool               `mapstructure:"enabled"`
+	Patterns []RedactionPattern `mapstructure:"patterns"`
+}
+
+// RedactionPattern is one regular expression to redact; with capture groups only the groups are replaced
+type RedactionPattern struct {
+	Name        string `mapstructure:"name"`
+	Pattern     string `mapstructure:"pattern"`
+	Replacement string `mapstructure:"replacement"` // Default "[redacted:<name>]"
+}
+
+// PermissionsConfig controls modes and ownership of workspace directories and files
+type PermissionsConfig struct {
+	Umask               string `mapstructure:"umask"`                  // Process umask applied at startup (octal, empty = inherit)
+	DirMode             string `mapstructure:"dir_mode"`               // Mode for created directories (octal)
+	FileMode            string `mapstructure:"file_mode"`              // Mode for created files (octal)
+	ChownToInvokingUser bool   `mapstructure:"chown_to_invoking_user"` // After sudo runs, give the workspace back to SUDO_UID
+}
+
+// Default workspace permissions
+const (
+	DefaultDirMode  os.FileMode = 0755
+	DefaultFileMode os.FileMode = 0644
+)
+
+// Validate checks that configured modes are valid octal permissions
+func (p PermissionsConfig) Validate() error {
+	for name, value := range map[string]string{"umask": p.Umask, "dir_mode": p.DirMode, "file_mode": p.FileMode} {
+		if value == "" {
+			continue
+		}
+		if _, err := parseOctalMode(value); err != nil {
+			return fmt.Errorf("invalid output.permissions.%s '%s': %w", name, value, err)
+		}
+	}
+	return nil
+}
+
+// DirPerm returns the mode for created workspace directories
+func (p PermissionsConfig) DirPerm() os.FileMode {
+	if mode, err := parseOctalMode(p.DirMode); err == nil && p.DirMode != "" {
+		return mode
+	}
+	return DefaultDirMode
+}
+
+// FilePerm returns the mode for created workspace files
+func (p PermissionsConfig) FilePerm() os.FileMode {
+	if mode, err := parseOctalMode(p.FileMode); err == nil && p.FileMode != "" {
+		return mode
+	}
+	return DefaultFileMode
+}
+
+// UmaskValue returns the configured umask and whether one is set
+func (p PermissionsConfig) UmaskValue() (int, bool) {
+	if p.Umask == "" {
+		return 0, false
+	}
+	mode, err := parseOctalMode(p.Umask)
+	if err != nil {
+		return 0, false
+	}
+	return int(mode), true
+}
+
+// parseOctalMode parses an octal permission string such as "0750" or "750"
+func parseOctalMode(value string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimSpace(value), 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("must be an octal mode like 0755")
+	}
+	if mode > 0777 {
+		return 0, fmt.Errorf("mode out of range (max 0777)")
+	}
+	return os.FileMode(mode), nil
 }
 
 type LogSinkConfig struct {
@@ -222,7 +510,8 @@ type LogSinkConfig struct {
 }
 
 type RawSinkConfig struct {
-	Directory string `mapstructure:"directory"`
+	Directory  string `mapstructure:"directory"`
+	Interleave bool   `mapstructure:"interleave"` // Record stdout/stderr line by line with timestamps as produced
 }
 
 // ToolsConfig for tools.yaml configuration
@@ -230,15 +519,54 @@ type ToolsConfig struct {
 	ToolExecution         ToolExecutionConfig         `mapstructure:"tool_execution"`
 	WorkflowOrchestration WorkflowOrchestrationConfig `mapstructure:"workflow_orchestration"`
 	DefaultTimeout        int                         `mapstructure:"default_timeout_seconds"`
+	MaxTimeout            int                         `mapstructure:"max_timeout_seconds"` // Ceiling on any tool mode's timeout (0 = none)
+	ShutdownGraceSeconds  int                         `mapstructure:"shutdown_grace_seconds"` // Time a cancelled tool gets to exit after SIGTERM before SIGKILL
+	VersionPolicy         string                      `mapstructure:"version_policy"` // "warn" (default), "refuse" or "off" for tools outside min_version/max_version
+	Pacing                string                      `mapstructure:"pacing"`         // Pacing preset for every tool (also --pacing); "" = the tools' own flags
 	RetryAttempts         int                         `mapstructure:"retry_attempts"`
 	ArgvPolicy            ArgvPolicyConfig            `mapstructure:"argv_policy"`
 	Execution             ExecutionConfig             `mapstructure:"execution"`
 	CLIMode               CLIModeConfig               `mapstructure:"cli_mode"`
+	TargetScheduling      TargetSchedulingConfig      `mapstructure:"target_scheduling"`
+	HostDiscovery         HostDiscoveryConfig         `mapstructure:"host_discovery"`
+}
+
+// HostDiscoveryConfig controls the sweep run before scanning a CIDR target
+type HostDiscoveryConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`        // Sweep CIDR targets and scan only live hosts (also --discover)
+	Tool          string `mapstructure:"tool"`           // Tool used for the sweep
+	Mode          string `mapstructure:"mode"`           // Tool mode that performs the sweep
+	HostsVariable string `mapstructure:"hosts_variable"` // Parser variable listing live hosts, comma-separated
+	MaxHosts      int    `mapstructure:"max_hosts"`      // Refuse to fan out to more live hosts than this
+}
+
+// WithDefaults fills settings missing from tools.yaml: an nmap ping sweep, at most 256 hosts
+func (h HostDiscoveryConfig) WithDefaults() HostDiscoveryConfig {
+	if h.Tool == "" {
+		h.Tool = "nmap"
+	}
+	if h.Mode == "" {
+		h.Mode = "ping_scan"
+	}
+	if h.HostsVariable == "" {
+		h.HostsVariable = "live_hosts"
+	}
+	if h.MaxHosts <= 0 {
+		h.MaxHosts = 256
+	}
+	return h
+}
+
+// TargetSchedulingConfig controls multi-target runs (-iL) and the packet budget tools inherit
+type TargetSchedulingConfig struct {
+	MaxConcurrentTargets int `mapstructure:"max_concurrent_targets"` // Targets scanned at once
+	GlobalRateLimit      int `mapstructure:"global_rate_limit"`      // Packets/requests per second shared by all targets; 0 = no budget
+	DefaultRateLimit     int `mapstructure:"default_rate_limit"`     // {{rate_limit}} when no global budget is set
 }
 
 type ToolExecutionConfig struct {
 	MaxConcurrentExecutions int `mapstructure:"max_concurrent_executions"`
-	MaxParallelExecutions   int `mapstructure:"max_parallel_executions"`
+	MaxParallelExecutions   int `mapstructure:"max_parallel_executions"` // Deprecated: ignored; tool slots follow MaxConcurrentExecutions
 }
 
 type WorkflowOrchestrationConfig struct {
@@ -253,6 +581,11 @@ type ResourceLimitsConfig struct {
 	MaxCPUUsage     float64 `mapstructure:"max_cpu_usage"`
 	MaxMemoryUsage  float64 `mapstructure:"max_memory_usage"`
 	MaxActiveTools  int     `mapstructure:"max_active_tools"`
+	
+	// Limits on the resources workflows reserve (resources: in workflow YAML)
+	MaxNetworkHeavy     int `mapstructure:"max_network_heavy_workflows"` // Workflows with network: heavy at once
+	MaxCPUHeavy         int `mapstructure:"max_cpu_heavy_workflows"`     // Workflows with cpu: heavy at once
+	MaxReservedMemoryMB int `mapstructure:"max_reserved_memory_mb"`      // Total memory_mb of running workflows; 0 = no budget
 }
 
 type PriorityWeightsConfig struct {
@@ -304,12 +637,14 @@ func LoadConfig() (*Config, error) {
 		setUIDefaults(&config.UI)
 	}
 
-	// Load Security config
+	// Load Security config over the switches that default to on, so a file can turn them off
+	presetSecurityDefaults(&config.Security)
 	if err := loadConfigFile(configPath, "security", &config.Security); err != nil {
 		setSecurityDefaults(&config.Security)
 	}
 
-	// Load Output config
+	// Load Output config, likewise over its switches that default to on
+	presetOutputDefaults(&config.Output)
 	if err := loadConfigFile(configPath, "output", &config.Output); err != nil {
 		setOutputDefaults(&config.Output)
 	}
@@ -319,6 +654,51 @@ func LoadConfig() (*Config, error) {
 		setToolsDefaults(&config.Tools)
 	}
 
+	// Load host labeling rules (optional; no labels when the file is missing)
+	if err := loadConfigFile(configPath, "labels", &config.Labels); err != nil {
+		config.Labels = LabelsConfig{}
+	}
+
+	// Load integrations (optional; exporters stay unconfigured when the file is missing)
+	if err := loadConfigFile(configPath, "integrations", &config.Integrations); err != nil {
+		config.Integrations = IntegrationsConfig{}
+	}
+
+	// Load smart wordlist rules (optional; {{smart_wordlist}} stays empty when the file is missing)
+	if err := loadConfigFile(configPath, "wordlists", &config.Wordlists); err != nil {
+		config.Wordlists = WordlistsConfig{}
+	}
+
+	// Load the workspace storage backend (optional; workspaces stay local when the file is missing)
+	if err := loadConfigFile(configPath, "storage", &config.Storage); err != nil {
+		config.Storage = StorageConfig{}
+	}
+
+	// Load the passive source settings (optional; built-in defaults apply when the file is missing)
+	if err := loadConfigFile(configPath, "passive", &config.Passive); err != nil {
+		config.Passive = PassiveConfig{}
+	}
+
+	// Load the review heuristics (optional; reports carry no hints when the file is missing)
+	if err := loadConfigFile(configPath, "heuristics", &config.Heuristics); err != nil {
+		config.Heuristics = HeuristicsConfig{}
+	}
+
+	// Load the triage checks (optional; checklists list ports without suggestions when the file is missing)
+	if err := loadConfigFile(configPath, "triage", &config.Triage); err != nil {
+		config.Triage = TriageConfig{}
+	}
+
+	// Load the scan profiles (optional; -p has no profiles to choose from when the file is missing)
+	if err := loadConfigFile(configPath, "profiles", &config.Profiles); err != nil {
+		config.Profiles = nil
+	}
+
+	// Load experimental feature flags (optional; every feature is off when the file is missing)
+	if err := loadConfigFile(configPath, "experimental", &config.Experimental); err != nil {
+		config.Experimental = nil
+	}
+
 	return config, nil
 }
 
@@ -501,6 +881,16 @@ func setUIDefaults(ui *UIConfig) {
 	}
 }
 
+// presetSecurityDefaults sets the switches that are on unless security.yaml turns them off.
+// They are set before the file is read: the keys it leaves out keep these values, and an
+// explicit false replaces them
+func presetSecurityDefaults(sec *SecurityConfig) {
+	sec.Evasion.AllowDecoys = true
+	sec.Evasion.AllowFragmentation = true
+	sec.Evasion.AllowSourcePort = true
+	sec.Reporting.AutoGenerate = true
+}
+
 func setSecurityDefaults(sec *SecurityConfig) {
 	// Set minimal defaults if config file is missing
 	if !sec.OSDetection {
@@ -527,6 +917,25 @@ func setSecurityDefaults(sec *SecurityConfig) {
 	if sec.Scanning.RetryAttempts == 0 {
 		sec.Scanning.RetryAttempts = 3
 	}
+	
+	// Set defaults for evasion policy (its allow_* switches are preset, see presetSecurityDefaults)
+	if sec.Evasion.MaxDecoys == 0 {
+		sec.Evasion.MaxDecoys = 10
+	}
+	if sec.Evasion.MaxTiming == 0 {
+		sec.Evasion.MaxTiming = 5
+	}
+	
+	// Set defaults for report generation (auto_generate is preset)
+	if len(sec.Reporting.Formats) == 0 {
+		sec.Reporting.Formats = []string{"markdown"}
+	}
+}
+
+// presetOutputDefaults sets the switches that are on unless output.yaml turns them off (see
+// presetSecurityDefaults)
+func presetOutputDefaults(out *OutputConfig) {
+	out.Permissions.ChownToInvokingUser = true
 }
 
 func setOutputDefaults(out *OutputConfig) {
@@ -540,6 +949,9 @@ func setOutputDefaults(out *OutputConfig) {
 	if out.ScanOutputMode == "" {
 		out.ScanOutputMode = "both"
 	}
+	if out.ResultsEncoding == "" {
+		out.ResultsEncoding = "json"
+	}
 	// CreateLatestLinks defaults to true if not explicitly set
 	if !out.CreateLatestLinks {
 		out.CreateLatestLinks = true
@@ -578,9 +990,6 @@ func setToolsDefaults(tools *ToolsConfig) {
 	if tools.ToolExecution.MaxConcurrentExecutions == 0 {
 		tools.ToolExecution.MaxConcurrentExecutions = 3
 	}
-	if tools.ToolExecution.MaxParallelExecutions == 0 {
-		tools.ToolExecution.MaxParallelExecutions = 2
-	}
 	if tools.DefaultTimeout == 0 {
 		tools.DefaultTimeout = 1800 // 30 minutes
 	}
@@ -604,6 +1013,12 @@ func setToolsDefaults(tools *ToolsConfig) {
 	if tools.WorkflowOrchestration.ResourceLimits.MaxActiveTools == 0 {
 		tools.WorkflowOrchestration.ResourceLimits.MaxActiveTools = 15
 	}
+	if tools.WorkflowOrchestration.ResourceLimits.MaxNetworkHeavy <= 0 {
+		tools.WorkflowOrchestration.ResourceLimits.MaxNetworkHeavy = 1
+	}
+	if tools.WorkflowOrchestration.ResourceLimits.MaxCPUHeavy <= 0 {
+		tools.WorkflowOrchestration.ResourceLimits.MaxCPUHeavy = 2
+	}
 	if tools.WorkflowOrchestration.PriorityWeights.High == 0 {
 		tools.WorkflowOrchestration.PriorityWeights.High = 30
 	}
@@ -626,6 +1041,17 @@ func setToolsDefaults(tools *ToolsConfig) {
 		tools.WorkflowOrchestration.Scheduling.ResourceCheckIntervalMs = 1000
 	}
 	
+	// Set defaults for multi-target scheduling
+	if tools.TargetScheduling.MaxConcurrentTargets <= 0 {
+		tools.TargetScheduling.MaxConcurrentTargets = 1
+	}
+	if tools.TargetScheduling.DefaultRateLimit <= 0 {
+		tools.TargetScheduling.DefaultRateLimit = 1000
+	}
+	
+	// Set defaults for CIDR host discovery
+	tools.HostDiscovery = tools.HostDiscovery.WithDefaults()
+	
 	// Set defaults for argv policy
 	if tools.ArgvPolicy.MaxArgs == 0 {
 		tools.ArgvPolicy.MaxArgs = 64
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/schema"
+)
+
+// ValidateFiles checks the files of the configs directory (the one LoadConfig reads) against
+// the configuration structs: YAML syntax, unknown keys, values of the wrong type and files
+// that fail to load, which LoadConfig would otherwise replace with defaults without a word.
+// It returns the directory checked and the issues found
+func ValidateFiles() (string, []schema.Issue) {
+	configPath := findConfigPath()
+	var issues []schema.Issue
+
+	// Every section of Config is read from configs/<section>.yaml
+	sections := make(map[string]bool)
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		name := field.Tag.Get("mapstructure")
+		sections[name] = true
+		file := filepath.Join(configPath, name+".yaml")
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				issues = append(issues, schema.Issue{File: file, Severity: schema.Error, Message: err.Error()})
+			}
+			continue // Missing files are optional; LoadConfig applies defaults
+		}
+		issues = append(issues, validateSection(file, name, data, reflect.New(field.Type).Interface())...)
+	}
+
+	// Files LoadConfig never reads are likely misnamed
+	entries, _ := os.ReadDir(configPath)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (filepath.Ext(name) != ".yaml" && filepath.Ext(name) != ".yml") {
+			continue
+		}
+		if section := strings.TrimSuffix(name, filepath.Ext(name)); !sections[section] || filepath.Ext(name) == ".yml" {
+			issues = append(issues, schema.Issue{
+				File:     filepath.Join(configPath, name),
+				Severity: schema.Warning,
+				Message:  fmt.Sprintf("not read by IPCrawler (configuration files are %s)", knownFiles(sections)),
+			})
+		}
+	}
+
+	// Semantic checks on the loaded configuration
+	if cfg, err := LoadConfig(); err == nil {
+		if err := cfg.Output.Permissions.Validate(); err != nil {
+			issues = append(issues, schema.Issue{File: filepath.Join(configPath, "output.yaml"), Severity: schema.Error, Message: err.Error()})
+		}
+	}
+	return configPath, issues
+}
+
+// validateSection checks one configs/ file; like loadConfigFile it accepts the section under
+// its top-level key or a flat file
+func validateSection(file, name string, data []byte, target interface{}) []schema.Issue {
+	doc, syntaxIssue := schema.Parse(file, data)
+	if syntaxIssue != nil {
+		return []schema.Issue{*syntaxIssue}
+	}
+	var issues []schema.Issue
+	if node := doc.Node(name); node != nil {
+		issues = doc.Check(node, name, target, "mapstructure")
+		for i := 0; doc.Root != nil && i+1 < len(doc.Root.Content); i += 2 {
+			if key := doc.Root.Content[i]; key.Value != name {
+				issues = append(issues, doc.Issue(key.Value, schema.Warning, "top-level key %s is ignored (only %s: is read)", key.Value, name))
+			}
+		}
+	} else if doc.Root != nil && doc.Root.Kind != 0 {
+		issues = doc.Check(doc.Root, "", target, "mapstructure")
+	}
+
+	// The decoder is the final word: a file it rejects is replaced by defaults
+	if err := loadConfigFile(filepath.Dir(file), name, target); err != nil {
+		message := strings.Join(strings.Fields(strings.TrimPrefix(err.Error(), "decoding failed due to the following error(s):")), " ")
+		issues = append(issues, schema.Issue{File: file, Severity: schema.Error, Message: "fails to load, so defaults are used instead: " + message})
+	}
+	return issues
+}
+
+func knownFiles(sections map[string]bool) string {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name+".yaml")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
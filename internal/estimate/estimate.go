@@ -0,0 +1,149 @@
+package estimate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// FileName is the duration history kept in the user's IPCrawler directory (~/.ipcrawler)
+const FileName = "durations.json"
+
+// recentRuns is how many runs the average leans on; older runs fade out as new ones come in
+const recentRuns = 10
+
+// Target size classes: tool durations grow with the number of addresses scanned
+const (
+	SizeHost   = "host"   // One host, hostname or host:port
+	SizeSmall  = "small"  // Up to 16 addresses
+	SizeMedium = "medium" // Up to 256 addresses (a /24)
+	SizeLarge  = "large"  // More than 256 addresses
+)
+
+// History is how long each tool mode took in past runs, by target size. It holds durations
+// only, no targets, and never leaves the machine
+type History struct {
+	UpdatedAt time.Time          `json:"updated_at"`
+	Durations map[string]*Sample `json:"durations"` // Keyed by "tool mode size"
+}
+
+// Sample is the recent average duration of one tool mode on one target size
+type Sample struct {
+	Runs           int     `json:"runs"`
+	AverageSeconds float64 `json:"average_seconds"`
+}
+
+// Path returns the duration history's location inside the given user directory
+func Path(userDir string) string {
+	return filepath.Join(userDir, FileName)
+}
+
+// Load reads the duration history; a missing file is an empty history
+func Load(path string) (*History, error) {
+	history := &History{Durations: make(map[string]*Sample)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read duration history: %v", err)
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse duration history %s: %v", path, err)
+	}
+	if history.Durations == nil {
+		history.Durations = make(map[string]*Sample)
+	}
+	return history, nil
+}
+
+// Save writes the duration history, replacing the previous file atomically
+func (h *History) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create duration history directory: %v", err)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode duration history: %v", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write duration history: %v", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Record adds the successful tool executions of a finished run. Failed executions are left out:
+// a tool that failed fast says nothing about how long it takes to finish
+func (h *History) Record(report output.RunReport) {
+	h.UpdatedAt = time.Now()
+	for _, workflow := range report.Workflows {
+		size := SizeClass(workflow.Target)
+		for _, step := range workflow.Steps {
+			for _, execution := range step.Executions {
+				if execution.Success && execution.DurationSeconds > 0 {
+					h.add(key(execution.Tool, execution.Mode, size), execution.DurationSeconds)
+				}
+			}
+		}
+	}
+}
+
+func (h *History) add(key string, seconds float64) {
+	sample := h.Durations[key]
+	if sample == nil {
+		sample = &Sample{}
+		h.Durations[key] = sample
+	}
+	sample.Runs++
+	weight := sample.Runs
+	if weight > recentRuns {
+		weight = recentRuns
+	}
+	sample.AverageSeconds += (seconds - sample.AverageSeconds) / float64(weight)
+}
+
+// Expected returns how long a tool mode is expected to take on a target, and whether any
+// run of it on a target of that size was recorded
+func (h *History) Expected(tool, mode, target string) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	sample := h.Durations[key(tool, mode, SizeClass(target))]
+	if sample == nil || sample.Runs == 0 {
+		return 0, false
+	}
+	return time.Duration(sample.AverageSeconds * float64(time.Second)), true
+}
+
+func key(tool, mode, size string) string {
+	return tool + " " + mode + " " + size
+}
+
+// SizeClass returns the size class of a target: CIDR ranges by their number of addresses,
+// anything else is one host
+func SizeClass(target string) string {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(target))
+	if err != nil {
+		return SizeHost
+	}
+	ones, bits := network.Mask.Size()
+	addresses := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	switch {
+	case addresses.Cmp(big.NewInt(1)) == 0:
+		return SizeHost
+	case addresses.Cmp(big.NewInt(16)) <= 0:
+		return SizeSmall
+	case addresses.Cmp(big.NewInt(256)) <= 0:
+		return SizeMedium
+	default:
+		return SizeLarge
+	}
+}
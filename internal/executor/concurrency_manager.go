@@ -307,6 +307,26 @@ func (cm *ConcurrencyManager) ReleaseExecution(request *ExecutionRequest) {
 	cm.logger.Debug("Execution slot released", "tool", request.ToolName, "profile", request.Profile)
 }
 
+// Abandon withdraws a request whose caller stopped waiting (e.g. its workflow was cancelled):
+// it leaves the queue, and a slot granted to it in the meantime goes to the next request
+func (cm *ConcurrencyManager) Abandon(request *ExecutionRequest) {
+	cm.queueMutex.Lock()
+	for i, queued := range cm.executionQueue {
+		if queued == request {
+			cm.executionQueue = append(cm.executionQueue[:i], cm.executionQueue[i+1:]...)
+			break
+		}
+	}
+	cm.queueMutex.Unlock()
+	request.Cancel()
+
+	select {
+	case <-request.StartChan:
+		cm.ReleaseExecution(request)
+	default:
+	}
+}
+
 // processQueue checks if any queued tools can now be executed - prioritizes by priority, not profile
 func (cm *ConcurrencyManager) processQueue(releasedProfile ToolPerformanceProfile) {
 	cm.queueMutex.Lock()
@@ -373,45 +393,45 @@ func (cm *ConcurrencyManager) getActiveCountByProfile(profile ToolPerformancePro
 	return count
 }
 
-// GetStatus returns current concurrency status
-func (cm *ConcurrencyManager) GetStatus() map[string]interface{} {
+// GetStatus returns the current slot usage, queue, running tools and metrics. The queue and
+// the running tools are copied under their own lock, one after the other: processQueue holds
+// queueMutex while trackToolStart takes activeMutex, so holding both here could deadlock
+func (cm *ConcurrencyManager) GetStatus() ExecutionStatus {
+	cm.queueMutex.Lock()
+	queue := QueueStatus{Size: len(cm.executionQueue), Tools: cm.getQueuedToolNames()}
+	cm.queueMutex.Unlock()
+	
 	cm.activeMutex.RLock()
 	defer cm.activeMutex.RUnlock()
 	
-	cm.queueMutex.Lock()
-	defer cm.queueMutex.Unlock()
+	status := ExecutionStatus{
+		Slots:       make(map[string]SlotStatus),
+		Queue:       queue,
+		ActiveTools: cm.copyActiveTools(),
+	}
 	
 	// Calculate slot utilization
-	fastActive := cm.getActiveCountByProfile(FastTool)
-	mediumActive := cm.getActiveCountByProfile(MediumTool)
-	heavyActive := cm.getActiveCountByProfile(HeavyTool)
-	
-	status := map[string]interface{}{
-		"slots": map[string]interface{}{
-			"fast": map[string]interface{}{
-				"active":    fastActive,
-				"available": cm.limits.FastToolLimit - fastActive,
-				"total":     cm.limits.FastToolLimit,
-				"usage":     float64(fastActive) / float64(cm.limits.FastToolLimit),
-			},
-			"medium": map[string]interface{}{
-				"active":    mediumActive,
-				"available": cm.limits.MediumToolLimit - mediumActive,
-				"total":     cm.limits.MediumToolLimit,
-				"usage":     float64(mediumActive) / float64(cm.limits.MediumToolLimit),
-			},
-			"heavy": map[string]interface{}{
-				"active":    heavyActive,
-				"available": cm.limits.HeavyToolLimit - heavyActive,
-				"total":     cm.limits.HeavyToolLimit,
-				"usage":     float64(heavyActive) / float64(cm.limits.HeavyToolLimit),
-			},
-		},
-		"queue": map[string]interface{}{
-			"size":  len(cm.executionQueue),
-			"tools": cm.getQueuedToolNames(),
-		},
-		"active_tools": cm.copyActiveTools(),
+	for profile, total := range map[ToolPerformanceProfile]int{
+		FastTool:   cm.limits.FastToolLimit,
+		MediumTool: cm.limits.MediumToolLimit,
+		HeavyTool:  cm.limits.HeavyToolLimit,
+	} {
+		active := cm.getActiveCountByProfile(profile)
+		slot := SlotStatus{Active: active, Available: total - active, Total: total}
+		if total > 0 {
+			slot.Usage = float64(active) / float64(total)
+		}
+		status.Slots[profile.String()] = slot
+	}
+	
+	metrics := cm.GetMetrics()
+	status.Metrics = ExecutionMetrics{
+		TotalExecuted:    metrics.TotalExecuted,
+		QueuedExecutions: metrics.QueuedExecutions,
+		PeakConcurrency:  make(map[string]int),
+	}
+	for profile, peak := range metrics.PeakConcurrency {
+		status.Metrics.PeakConcurrency[profile.String()] = peak
 	}
 	
 	return status
@@ -0,0 +1,483 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Step conditions (run_if) are small expressions over magic variables:
+//
+//	{{combined_port_count}} > 0
+//	contains(open_services, "http") && !empty(nmap_open_ports)
+//
+// Variables are referenced as {{name}} or by bare name; undefined variables are empty.
+// Values are compared as numbers when both sides are numeric, as strings otherwise.
+
+// Condition is a parsed run_if expression
+type Condition struct {
+	expression string
+	root       conditionNode
+}
+
+// conditionNode evaluates to a string value; boolean results are "true" or "false"
+type conditionNode interface {
+	eval(vars map[string]string) (string, error)
+}
+
+// conditionFunctions are the functions available in run_if expressions, by name and arity
+var conditionFunctions = map[string]int{
+	"contains": 2, // contains(list, item): item is one of the comma-separated values (case-insensitive)
+	"empty":    1, // empty(value): value is undefined or empty
+	"count":    1, // count(list): number of comma-separated values
+	"slice":    3, // slice(list, start, end): the values from start up to end (0-based, end excluded)
+}
+
+// ParseCondition parses a run_if expression
+func ParseCondition(expression string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run_if %q: %v", expression, err)
+	}
+	root, err := parseConditionTokens(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run_if %q: %v", expression, err)
+	}
+	return &Condition{expression: expression, root: root}, nil
+}
+
+// parseConditionTokens parses a whole tokenized expression
+func parseConditionTokens(tokens []conditionToken) (conditionNode, error) {
+	parser := &conditionParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err == nil && parser.pos < len(parser.tokens) {
+		err = fmt.Errorf("unexpected %q", parser.tokens[parser.pos].text)
+	}
+	return root, err
+}
+
+// Evaluate reports whether the condition holds for the given variables
+func (c *Condition) Evaluate(vars map[string]string) (bool, error) {
+	value, err := c.root.eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("run_if %q: %v", c.expression, err)
+	}
+	return truthy(value), nil
+}
+
+// String returns the original expression
+func (c *Condition) String() string {
+	return c.expression
+}
+
+// ValidateConditions parses every step's run_if so that mistakes surface when the workflow loads
+func ValidateConditions(workflow *Workflow) error {
+	for _, step := range workflow.Steps {
+		if step.RunIf == "" {
+			continue
+		}
+		if _, err := ParseCondition(step.RunIf); err != nil {
+			return fmt.Errorf("step '%s': %v", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// truthy treats empty, "0" and "false" as false
+func truthy(value string) bool {
+	value = strings.TrimSpace(value)
+	return value != "" && value != "0" && !strings.EqualFold(value, "false")
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// listValues splits a comma-separated variable into trimmed, non-empty values
+func listValues(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// Tokenizer
+
+type conditionTokenKind int
+
+const (
+	tokenIdent conditionTokenKind = iota
+	tokenVariable
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type conditionToken struct {
+	kind conditionTokenKind
+	text string
+}
+
+// conditionOperators are matched longest first
+var conditionOperators = []string{"&&", "||", "==", "!=", ">=", "<=", ">", "<", "!", "(", ")", ","}
+
+func tokenizeCondition(expression string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(expression[i:], "{{"):
+			end := strings.Index(expression[i:], "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated {{ at offset %d", i)
+			}
+			name := strings.TrimSpace(expression[i+2 : i+end])
+			if name == "" {
+				return nil, fmt.Errorf("empty variable reference at offset %d", i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenVariable, text: name})
+			i += end + 2
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenString, text: expression[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(expression) && expression[i+1] >= '0' && expression[i+1] <= '9':
+			start := i
+			for i++; i < len(expression) && (expression[i] >= '0' && expression[i] <= '9' || expression[i] == '.'); i++ {
+			}
+			tokens = append(tokens, conditionToken{kind: tokenNumber, text: expression[start:i]})
+		case isIdentRune(rune(c)):
+			start := i
+			for i < len(expression) && (isIdentRune(rune(expression[i])) || expression[i] >= '0' && expression[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: tokenIdent, text: expression[start:i]})
+		default:
+			matched := false
+			for _, operator := range conditionOperators {
+				if strings.HasPrefix(expression[i:], operator) {
+					tokens = append(tokens, conditionToken{kind: tokenOperator, text: operator})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r)
+}
+
+// Parser: or := and ('||' and)*; and := unary ('&&' unary)*;
+// unary := '!' unary | comparison; comparison := operand (op operand)?
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) peekOperator(operators ...string) string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator {
+		return ""
+	}
+	for _, operator := range operators {
+		if p.tokens[p.pos].text == operator {
+			return operator
+		}
+	}
+	return ""
+}
+
+func (p *conditionParser) expect(operator string) error {
+	if p.peekOperator(operator) == "" {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("expected %q at end of expression", operator)
+		}
+		return fmt.Errorf("expected %q, found %q", operator, p.tokens[p.pos].text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("||") != "" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator("&&") != "" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.peekOperator("!") != "" {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operator := p.peekOperator("==", "!=", ">=", "<=", ">", "<")
+	if operator == "" {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{operator: operator, left: left, right: right}, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+
+	switch token.kind {
+	case tokenString, tokenNumber:
+		return literalNode(token.text), nil
+	case tokenVariable:
+		return variableNode(token.text), nil
+	case tokenIdent:
+		if p.peekOperator("(") == "" {
+			switch token.text {
+			case "true", "false":
+				return literalNode(token.text), nil
+			}
+			return variableNode(token.text), nil
+		}
+		return p.parseCall(token.text)
+	default:
+		if token.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		}
+		return nil, fmt.Errorf("unexpected %q", token.text)
+	}
+}
+
+func (p *conditionParser) parseCall(name string) (conditionNode, error) {
+	arity, known := conditionFunctions[name]
+	if !known {
+		return nil, fmt.Errorf("unknown function %s() (available: contains, empty, count, slice)", name)
+	}
+	p.pos++ // "("
+	var args []conditionNode
+	for p.peekOperator(")") == "" {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.pos++ // ")"
+	if len(args) != arity {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name, arity, len(args))
+	}
+	return &callNode{name: name, args: args}, nil
+}
+
+// Nodes
+
+type literalNode string
+
+func (n literalNode) eval(map[string]string) (string, error) {
+	return string(n), nil
+}
+
+type variableNode string
+
+func (n variableNode) eval(vars map[string]string) (string, error) {
+	return vars[string(n)], nil
+}
+
+type notNode struct {
+	operand conditionNode
+}
+
+func (n *notNode) eval(vars map[string]string) (string, error) {
+	value, err := n.operand.eval(vars)
+	if err != nil {
+		return "", err
+	}
+	return boolValue(!truthy(value)), nil
+}
+
+type logicalNode struct {
+	and         bool
+	left, right conditionNode
+}
+
+func (n *logicalNode) eval(vars map[string]string) (string, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return "", err
+	}
+	// Short-circuit like the usual && and ||
+	if truthy(left) != n.and {
+		return boolValue(truthy(left)), nil
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return "", err
+	}
+	return boolValue(truthy(right)), nil
+}
+
+type compareNode struct {
+	operator    string
+	left, right conditionNode
+}
+
+func (n *compareNode) eval(vars map[string]string) (string, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return "", err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return "", err
+	}
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+
+	leftNumber, leftErr := strconv.ParseFloat(left, 64)
+	rightNumber, rightErr := strconv.ParseFloat(right, 64)
+	numeric := leftErr == nil && rightErr == nil
+
+	switch n.operator {
+	case "==":
+		if numeric {
+			return boolValue(leftNumber == rightNumber), nil
+		}
+		return boolValue(left == right), nil
+	case "!=":
+		if numeric {
+			return boolValue(leftNumber != rightNumber), nil
+		}
+		return boolValue(left != right), nil
+	}
+
+	// Ordering needs numbers; a variable that was never set cannot satisfy it
+	if left == "" || right == "" {
+		return "false", nil
+	}
+	if !numeric {
+		return "", fmt.Errorf("cannot compare %q %s %q: both sides must be numbers", left, n.operator, right)
+	}
+	switch n.operator {
+	case ">":
+		return boolValue(leftNumber > rightNumber), nil
+	case ">=":
+		return boolValue(leftNumber >= rightNumber), nil
+	case "<":
+		return boolValue(leftNumber < rightNumber), nil
+	default:
+		return boolValue(leftNumber <= rightNumber), nil
+	}
+}
+
+type callNode struct {
+	name string
+	args []conditionNode
+}
+
+func (n *callNode) eval(vars map[string]string) (string, error) {
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return "", err
+		}
+		args[i] = value
+	}
+
+	switch n.name {
+	case "contains":
+		item := strings.TrimSpace(args[1])
+		for _, value := range listValues(args[0]) {
+			if strings.EqualFold(value, item) {
+				return "true", nil
+			}
+		}
+		return "false", nil
+	case "empty":
+		return boolValue(strings.TrimSpace(args[0]) == ""), nil
+	case "slice":
+		values := listValues(args[0])
+		start, startErr := strconv.Atoi(strings.TrimSpace(args[1]))
+		end, endErr := strconv.Atoi(strings.TrimSpace(args[2]))
+		if startErr != nil || endErr != nil || start < 0 || end < 0 {
+			return "", fmt.Errorf("slice() needs positions 0 or above, got %q and %q", args[1], args[2])
+		}
+		start, end = min(start, len(values)), min(end, len(values))
+		if start >= end {
+			return "", nil
+		}
+		return strings.Join(values[start:end], ","), nil
+	default: // count
+		return strconv.Itoa(len(listValues(args[0]))), nil
+	}
+}
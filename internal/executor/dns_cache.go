@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dnsLookupTimeout bounds one hostname lookup; a target that does not resolve in time is
+// passed to tools as written
+const dnsLookupTimeout = 10 * time.Second
+
+// DNSCache resolves each hostname once per run, so every tool sees the same addresses even
+// when the DNS answers rotate, and repeated steps do not repeat the lookup. The scope and
+// exclusion checks before each tool run check these same addresses
+type DNSCache struct {
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+	mutex   sync.Mutex
+	entries map[string]*dnsCacheEntry
+}
+
+type dnsCacheEntry struct {
+	once sync.Once
+	ips  []string
+	err  error
+}
+
+// NewDNSCache creates an empty cache using the system resolver
+func NewDNSCache() *DNSCache {
+	return &DNSCache{
+		lookup:  net.DefaultResolver.LookupIPAddr,
+		entries: make(map[string]*dnsCacheEntry),
+	}
+}
+
+// Resolve returns the addresses of a hostname, IPv4 first, looking it up only the first time.
+// Concurrent callers wait for the same lookup; a failed lookup is not retried within the run
+func (c *DNSCache) Resolve(host string) ([]string, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	c.mutex.Lock()
+	entry, exists := c.entries[host]
+	if !exists {
+		entry = &dnsCacheEntry{}
+		c.entries[host] = entry
+	}
+	c.mutex.Unlock()
+
+	entry.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+		defer cancel()
+		addrs, err := c.lookup(ctx, host)
+		// Keep the resolver's order within each family: it is the order tools would have used
+		sort.SliceStable(addrs, func(i, j int) bool {
+			return addrs[i].IP.To4() != nil && addrs[j].IP.To4() == nil
+		})
+		ips := make([]string, 0, len(addrs))
+		for _, addr := range addrs {
+			ips = append(ips, addr.String())
+		}
+		c.mutex.Lock() // Resolved reads entries while other lookups are in flight
+		entry.ips, entry.err = ips, err
+		c.mutex.Unlock()
+	})
+	return entry.ips, entry.err
+}
+
+// Resolved returns the addresses of every hostname resolved so far
+func (c *DNSCache) Resolved() map[string][]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	resolved := make(map[string][]string)
+	for host, entry := range c.entries {
+		if len(entry.ips) > 0 {
+			resolved[host] = entry.ips
+		}
+	}
+	return resolved
+}
+
+// ResolveTarget returns the addresses of a target: the target itself when it is an address,
+// the cached lookup when it is a hostname (with or without a port), nothing for ranges
+func (c *DNSCache) ResolveTarget(target string) ([]string, error) {
+	host := targetHost(target)
+	if host == "" {
+		return nil, nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	return c.Resolve(host)
+}
+
+// targetHost returns the host of a "host" or "host:port" target, or "" for CIDR ranges
+func targetHost(target string) string {
+	target = strings.TrimSpace(target)
+	if target == "" || strings.Contains(target, "/") {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return strings.Trim(target, "[]")
+}
+
+// withTargetAddress replaces the host of a "host" or "host:port" target with an address
+func withTargetAddress(target, ip string) string {
+	if _, port, err := net.SplitHostPort(strings.TrimSpace(target)); err == nil {
+		return net.JoinHostPort(ip, port)
+	}
+	return ip
+}
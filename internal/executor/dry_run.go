@@ -0,0 +1,340 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// A dry run resolves what a scan of a target would execute without executing anything: the
+// order the orchestrator would start the workflows in (priority, then after), the order of
+// each workflow's steps (depends_on) and, for every mode, the command line and the files it
+// writes. Variables only known while a scan runs (parsed results, combined results, step
+// outputs, a hostname's address when it does not resolve) become placeholders named like the
+// variable, <combined_ports>. run_if conditions are not evaluated: every step is shown
+
+// DryRunPlan is what a scan of a target would execute
+type DryRunPlan struct {
+	Target       string
+	Workspace    string
+	Workflows    []DryRunWorkflow // In the order they would start
+	Triggered    []DryRunWorkflow // Queued only when a step discovers a matching service
+	Placeholders []string         // Variables resolved to <name>, sorted
+}
+
+// DryRunWorkflow is one workflow of a dry run
+type DryRunWorkflow struct {
+	ID       string
+	Name     string
+	Priority int
+	After    []string
+	Triggers []string     // The when conditions of a triggered workflow
+	Error    string       // Why the workflow would never start
+	Steps    []DryRunStep // In the order they would start
+}
+
+// DryRunStep is one step of a dry run workflow
+type DryRunStep struct {
+	Name       string
+	Tool       string
+	DependsOn  []string
+	RunIf      string
+	Concurrent bool // Its modes would run at the same time
+	Commands   []DryRunCommand
+}
+
+// DryRunCommand is the command one mode of a step would run
+type DryRunCommand struct {
+	Mode    string
+	Command []string // The executable (the tool's name when it is not installed) and its arguments
+	Outputs []string // Paths among the arguments, else the file stdout is saved to
+	Error   string   // Why the command could not be built or would not run
+}
+
+// DryRun resolves the commands a scan of target would run with the given workflows, writing
+// into workspaceDir. It uses the orchestrator's engine, whose variables it changes: give it an
+// engine of its own
+func (wo *WorkflowOrchestrator) DryRun(workflows []*Workflow, target, workspaceDir string) *DryRunPlan {
+	plan := &DryRunPlan{Target: target, Workspace: workspaceDir}
+	engine := wo.executor.engine
+	engine.workspaceBase = workspaceDir // Not SetWorkspaceBase: a dry run writes no error log
+	engine.debugLogger = log.New(io.Discard)
+	placeholders := make(map[string]bool)
+
+	// Queued as a scan queues them (ties in name order, where a scan's are random), triggered
+	// workflows aside
+	var queue []*Workflow
+	for _, workflow := range sortedWorkflows(workflows) {
+		if len(workflow.Triggers) > 0 {
+			triggered := DryRunWorkflow{ID: workflow.ID, Name: workflow.Name, Priority: wo.calculatePriority(workflow), After: workflow.After}
+			for _, trigger := range workflow.Triggers {
+				triggered.Triggers = append(triggered.Triggers, trigger.When)
+			}
+			plan.Triggered = append(plan.Triggered, triggered)
+			continue
+		}
+		queue = append(queue, workflow)
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		return wo.calculatePriority(queue[i]) > wo.calculatePriority(queue[j])
+	})
+
+	// The first queued workflow whose after entries have all run starts next
+	var started []*Workflow
+	for len(queue) > 0 {
+		next := -1
+		for i, workflow := range queue {
+			if len(dryRunWaitsFor(workflow, started)) == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+		workflow := queue[next]
+		queue = append(queue[:next], queue[next+1:]...)
+		started = append(started, workflow)
+		plan.Workflows = append(plan.Workflows, wo.dryRunWorkflow(workflow, target, placeholders))
+	}
+	for _, workflow := range queue {
+		plan.Workflows = append(plan.Workflows, DryRunWorkflow{ID: workflow.ID, Name: workflow.Name,
+			Priority: wo.calculatePriority(workflow), After: workflow.After, Error: dryRunBlocked(workflow, queue, target)})
+	}
+
+	for name := range placeholders {
+		plan.Placeholders = append(plan.Placeholders, name)
+	}
+	sort.Strings(plan.Placeholders)
+	return plan
+}
+
+// sortedWorkflows returns the workflows ordered by ID, then name
+func sortedWorkflows(workflows []*Workflow) []*Workflow {
+	sorted := append([]*Workflow(nil), workflows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ID != sorted[j].ID {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// dryRunWaitsFor returns the after entries of workflow that name none of the started workflows
+func dryRunWaitsFor(workflow *Workflow, started []*Workflow) []string {
+	var waiting []string
+	for _, name := range workflow.After {
+		found := false
+		for _, other := range started {
+			found = found || other.MatchesName(name)
+		}
+		if !found {
+			waiting = append(waiting, name)
+		}
+	}
+	return waiting
+}
+
+// dryRunBlocked explains why a workflow left in the queue would never start, as the
+// orchestrator reports it
+func dryRunBlocked(workflow *Workflow, queue []*Workflow, target string) string {
+	var pending []string
+	for _, name := range dryRunWaitsFor(workflow, nil) {
+		queued := false
+		for _, other := range queue {
+			queued = queued || other.MatchesName(name)
+		}
+		if !queued {
+			return fmt.Sprintf("unsatisfiable dependency: waits for '%s', which is not queued for %s", name, target)
+		}
+		pending = append(pending, "'"+name+"'")
+	}
+	return fmt.Sprintf("unsatisfiable dependency: waits for %s, which cannot start before it (dependency cycle)", strings.Join(pending, ", "))
+}
+
+// dryRunWorkflow resolves the commands of a workflow's steps in the order they would start,
+// applying each step's inputs, variables and outputs as a scan does
+func (wo *WorkflowOrchestrator) dryRunWorkflow(workflow *Workflow, target string, placeholders map[string]bool) DryRunWorkflow {
+	result := DryRunWorkflow{ID: workflow.ID, Name: workflow.Name, Priority: wo.calculatePriority(workflow), After: workflow.After}
+	graph, err := newStepGraph(workflow.Steps)
+	if err != nil {
+		result.Error = err.Error() // Rejected when the workflow loads
+		return result
+	}
+
+	engine := wo.executor.engine
+	resolver := engine.templateResolver
+	for _, index := range graph.order() {
+		step := workflow.Steps[index]
+		dryStep := DryRunStep{Name: step.Name, Tool: step.Tool, DependsOn: step.DependsOn, RunIf: step.RunIf,
+			Concurrent: step.Concurrent && len(step.Modes) > 1}
+		resolver.ApplyStepInputs(step.Inputs)
+
+		// What the step's variables read, then what its arguments read, once the variables are set
+		execCtx := engine.newExecutionContext(target, step.Tool, "", workflow.Name, step.Name)
+		var read []string
+		for name, value := range step.Variables {
+			if IsVariableTemplate(value) {
+				read = append(read, TemplateVariables(value)...)
+			} else {
+				read = append(read, name)
+			}
+		}
+		wo.setPlaceholders(read, step.Variables, execCtx, placeholders)
+		if step.Variables != nil {
+			if _, err := resolver.ApplyStepVariables(step.Variables, execCtx); err != nil {
+				dryStep.Commands = append(dryStep.Commands, DryRunCommand{Error: err.Error()})
+				result.Steps = append(result.Steps, dryStep)
+				continue
+			}
+		}
+
+		for _, mode := range step.Modes {
+			read = nil
+			if toolConfig, err := engine.configLoader.LoadToolConfig(step.Tool); err == nil {
+				if args, err := toolConfig.GetToolArguments(mode); err == nil {
+					for _, arg := range args {
+						read = append(read, TemplateVariables(arg)...)
+					}
+				}
+			}
+			wo.setPlaceholders(read, nil, engine.newExecutionContext(target, step.Tool, mode, workflow.Name, step.Name), placeholders)
+			dryStep.Commands = append(dryStep.Commands, engine.dryRunCommand(step, mode, target, workflow.Name))
+		}
+
+		// Later steps read the step's outputs; a scan publishes them from its results
+		resolver.PublishStepOutputs(step.Outputs, nil)
+		result.Steps = append(result.Steps, dryStep)
+	}
+	return result
+}
+
+// setPlaceholders gives the variables in names that are not set a <name> placeholder, except
+// configuration values and those the step's variables define
+func (wo *WorkflowOrchestrator) setPlaceholders(names []string, stepVariables map[string]string, execCtx *ExecutionContext, placeholders map[string]bool) {
+	resolver := wo.executor.engine.templateResolver
+	vars := resolver.buildVariableMap(execCtx)
+	for _, name := range names {
+		if _, set := vars[name]; set || IsConfigVariable(name) {
+			continue
+		}
+		if value, defined := stepVariables[name]; defined && IsVariableTemplate(value) {
+			continue
+		}
+		resolver.magicMutex.Lock()
+		resolver.magicVars[name] = "<" + name + ">"
+		resolver.magicMutex.Unlock()
+		vars[name] = "<" + name + ">"
+		placeholders[name] = true
+	}
+}
+
+// dryRunCommand builds the command one mode of a step would run
+func (tee *ToolExecutionEngine) dryRunCommand(step *WorkflowStep, mode, target, workflowName string) DryRunCommand {
+	command := DryRunCommand{Mode: mode}
+	args, outputs, err := tee.previewArguments(step.Tool, mode, target, workflowName, step.Name, step.Parameters)
+	if err != nil {
+		command.Error = err.Error()
+		return command
+	}
+	command.Outputs = outputs
+	executable, err := tee.findToolExecutable(step.Tool)
+	if err != nil {
+		executable = step.Tool
+		command.Error = fmt.Sprintf("failed to find tool executable: %v", err)
+	}
+	command.Command = append([]string{executable}, args...)
+	return command
+}
+
+// previewArguments resolves the arguments a tool run would get, as ExecuteToolWithContext
+// builds them, and the files it writes: the output paths among them, else the file its stdout
+// is saved to. Both are redacted
+func (tee *ToolExecutionEngine) previewArguments(toolName, mode, target, workflowName, stepName string, parameters map[string]string) ([]string, []string, error) {
+	if err := tee.exclusions.CheckResolved(target, tee.templateResolver.dnsCache.Resolve); err != nil {
+		return nil, nil, fmt.Errorf("target refused: %w", err)
+	}
+	if err := tee.engagement.Check(target, tee.templateResolver.dnsCache.Resolve); err != nil {
+		return nil, nil, err // Nothing is launched, so nothing is audited
+	}
+	toolConfig, err := tee.configLoader.LoadToolConfig(toolName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tool config: %w", err)
+	}
+	argsTemplate, err := toolConfig.GetToolArguments(mode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tool arguments: %w", err)
+	}
+
+	execCtx := tee.newExecutionContext(target, toolName, mode, workflowName, stepName)
+	if toolConfig.File != "" {
+		execCtx.OutputFile = toolConfig.File
+	}
+	execCtx.TargetIP = toolConfig.TargetIP
+	resolved, err := tee.templateResolver.resolveArgumentTemplates(argsTemplate, execCtx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve template variables: %w", err)
+	}
+	args := resolved.args
+	if err := tee.validator.ValidateOutputPaths(execCtx.Workspace, resolved.templates, args); err != nil {
+		return nil, nil, fmt.Errorf("output path validation failed: %v", err)
+	}
+	outputs := outputPathArguments(resolved.templates, args)
+	if len(outputs) == 0 {
+		outputs = []string{tee.templateResolver.buildVariableMap(execCtx)["output_path"]}
+	}
+
+	if tee.pacing != "" {
+		args = applyPacing(args, toolConfig.Pacing[tee.pacing])
+	}
+	if len(parameters) > 0 {
+		if err := tee.validator.ValidateStepParameters(parameters); err != nil {
+			return nil, nil, fmt.Errorf("parameter validation failed: %v", err)
+		}
+		paramArgs, err := tee.parameterManager.BuildArguments(toolName, parameters)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build parameter arguments: %v", err)
+		}
+		args = append(args, paramArgs...) // After the mode arguments, as ExecuteToolWithContext adds them
+	}
+	if !tee.exclusions.IsEmpty() {
+		exclusionArgs, supported := tee.parameterManager.BuildExclusionArguments(toolName, tee.exclusions.Entries())
+		if supported {
+			args = append(exclusionArgs, args...)
+		} else if tee.exclusions.Overlaps(target) {
+			return nil, nil, fmt.Errorf("tool %s has no native exclusion support and target %s overlaps excluded hosts", toolName, target)
+		}
+	}
+
+	return tee.redactor.RedactAll(args), tee.redactor.RedactAll(outputs), nil
+}
+
+// order returns the steps in the order they would start: each after its dependencies, ties in
+// file order
+func (g *stepGraph) order() []int {
+	done := make([]bool, len(g.dependencies))
+	order := make([]int, 0, len(g.dependencies))
+	for len(order) < len(g.dependencies) {
+		progress := false
+		for step, dependencies := range g.dependencies {
+			ready := !done[step]
+			for _, dependency := range dependencies {
+				ready = ready && done[dependency]
+			}
+			if ready {
+				done[step] = true
+				order = append(order, step)
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			break // Cycles are rejected when the graph is built
+		}
+	}
+	return order
+}
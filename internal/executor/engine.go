@@ -1,7 +1,6 @@
 package executor
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -10,23 +9,17 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/neur0map/ipcrawler/internal/config"
 	"github.com/neur0map/ipcrawler/internal/output"
-)
-
-// ANSI color codes for terminal output
-const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
-	colorBlue   = "\033[34m"
-	colorCyan   = "\033[36m"
-	colorGray   = "\033[90m"
-	colorBold   = "\033[1m"
+	"github.com/neur0map/ipcrawler/internal/parsers"
+	"github.com/neur0map/ipcrawler/internal/privilege"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/secrets"
+	"github.com/neur0map/ipcrawler/internal/workspace"
 )
 
 // ToolError represents a tool execution error with context
@@ -46,6 +39,10 @@ type ToolError struct {
 // ErrorHandler manages tool error reporting and logging
 type ErrorHandler struct {
 	workspaceDir string
+	scanID       string
+	sessionName  string
+	dirMode      os.FileMode
+	fileMode     os.FileMode
 	outputMode   output.OutputMode
 	errorLogger  *log.Logger
 	mutex        sync.Mutex
@@ -56,6 +53,8 @@ func NewErrorHandler(workspaceDir string, outputMode output.OutputMode) *ErrorHa
 	return &ErrorHandler{
 		workspaceDir: workspaceDir,
 		outputMode:   outputMode,
+		dirMode:      config.DefaultDirMode,
+		fileMode:     config.DefaultFileMode,
 	}
 }
 
@@ -69,14 +68,13 @@ func (eh *ErrorHandler) SetupErrorLogging() error {
 	defer eh.mutex.Unlock()
 	
 	// Create error log directory
-	errorDir := filepath.Join(eh.workspaceDir, "logs", "errors")
-	if err := os.MkdirAll(errorDir, 0755); err != nil {
+	errorLogPath := workspace.New(eh.workspaceDir).Paths().ErrorLog
+	if err := os.MkdirAll(filepath.Dir(errorLogPath), eh.dirMode); err != nil {
 		return fmt.Errorf("failed to create error log directory: %w", err)
 	}
 	
 	// Open error log file
-	errorLogPath := filepath.Join(errorDir, "error.log")
-	errorFile, err := os.OpenFile(errorLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	errorFile, err := os.OpenFile(errorLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, eh.fileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open error log file: %w", err)
 	}
@@ -86,6 +84,12 @@ func (eh *ErrorHandler) SetupErrorLogging() error {
 	eh.errorLogger.SetReportCaller(false)
 	eh.errorLogger.SetReportTimestamp(true)
 	eh.errorLogger.SetLevel(log.ErrorLevel)
+	if eh.scanID != "" {
+		eh.errorLogger = eh.errorLogger.With("scan_id", eh.scanID)
+	}
+	if eh.sessionName != "" {
+		eh.errorLogger = eh.errorLogger.With("session", eh.sessionName)
+	}
 	
 	return nil
 }
@@ -120,33 +124,34 @@ func (eh *ErrorHandler) HandleToolError(toolErr *ToolError) {
 
 // displayShortError shows a brief error message for normal mode
 func (eh *ErrorHandler) displayShortError(toolErr *ToolError) {
-	fmt.Printf("\n%s⚠️  %s [%s] failed%s\n", colorYellow, toolErr.ToolName, toolErr.Mode, colorReset)
+	fmt.Printf("\n%s\n", output.FormatStatus(output.StatusFailed, fmt.Sprintf("%s [%s] failed", toolErr.ToolName, toolErr.Mode)))
 }
 
 // displayDetailedError shows comprehensive error information for verbose/debug mode
 func (eh *ErrorHandler) displayDetailedError(toolErr *ToolError) {
-	fmt.Printf("\n%s════════════════════════════════════════════════════════════════════════════════%s\n", colorRed, colorReset)
-	fmt.Printf("%s%s⚠️  ERROR: %s [%s] failed%s%s\n", colorBold, colorRed, toolErr.ToolName, toolErr.Mode, colorReset, colorReset)
-	fmt.Printf("%s════════════════════════════════════════════════════════════════════════════════%s\n", colorRed, colorReset)
+	rule := strings.Repeat("═", 80)
+	fmt.Printf("\n%s\n", output.Paint(output.StatusFailed, rule))
+	fmt.Println(output.FormatStatus(output.StatusFailed, fmt.Sprintf("ERROR: %s [%s] failed", toolErr.ToolName, toolErr.Mode)))
+	fmt.Println(output.Paint(output.StatusFailed, rule))
 	
-	fmt.Printf("%sTarget:%s %s\n", colorCyan, colorReset, toolErr.Target)
-	fmt.Printf("%sCommand:%s %s\n", colorCyan, colorReset, strings.Join(toolErr.Command, " "))
-	fmt.Printf("%sExit Code:%s %d\n", colorCyan, colorReset, toolErr.ExitCode)
-	fmt.Printf("%sDuration:%s %v\n", colorCyan, colorReset, toolErr.Duration)
+	fmt.Printf("%s %s\n", output.Paint(output.StatusInfo, "Target:"), toolErr.Target)
+	fmt.Printf("%s %s\n", output.Paint(output.StatusInfo, "Command:"), strings.Join(toolErr.Command, " "))
+	fmt.Printf("%s %d\n", output.Paint(output.StatusInfo, "Exit Code:"), toolErr.ExitCode)
+	fmt.Printf("%s %v\n", output.Paint(output.StatusInfo, "Duration:"), toolErr.Duration)
 	
 	if toolErr.ErrorMsg != "" {
-		fmt.Printf("%sError:%s %s\n", colorCyan, colorReset, toolErr.ErrorMsg)
+		fmt.Printf("%s %s\n", output.Paint(output.StatusInfo, "Error:"), toolErr.ErrorMsg)
 	}
 	
 	if toolErr.Stderr != "" {
-		fmt.Printf("%sStderr:%s\n%s\n", colorCyan, colorReset, toolErr.Stderr)
+		fmt.Printf("%s\n%s\n", output.Paint(output.StatusInfo, "Stderr:"), toolErr.Stderr)
 	}
 	
 	if toolErr.Stdout != "" && len(toolErr.Stdout) < 500 {
-		fmt.Printf("%sStdout:%s\n%s\n", colorCyan, colorReset, toolErr.Stdout)
+		fmt.Printf("%s\n%s\n", output.Paint(output.StatusInfo, "Stdout:"), toolErr.Stdout)
 	}
 	
-	fmt.Printf("%s────────────────────────────────────────────────────────────────────────────────%s\n", colorGray, colorReset)
+	fmt.Println(output.Paint(output.StatusPending, strings.Repeat("─", 80)))
 }
 
 // ExecutionResult represents the result of a tool execution
@@ -164,6 +169,14 @@ type ExecutionResult struct {
 	CommandLine  []string      `json:"command_line"`
 	Stdout       string        `json:"stdout,omitempty"`
 	Stderr       string        `json:"stderr,omitempty"`
+
+	// Set when this run replaced another mode: the privileged mode swapped for its unprivileged
+	// alternative, or a mode that failed for lack of privileges (see WorkflowStep.FallbackModes)
+	FallbackFor    string `json:"fallback_for,omitempty"`
+	FallbackReason string `json:"fallback_reason,omitempty"`
+
+	// Set when the exit code is declared a warning in the tool's exit_codes
+	Warning string `json:"warning,omitempty"`
 }
 
 // ExecutionOptions contains options for tool execution
@@ -174,6 +187,8 @@ type ExecutionOptions struct {
 	CaptureOutput  bool              // Whether to capture stdout/stderr
 	ValidateOutput bool              // Whether to validate output file was created
 	Priority       int               // Execution priority for concurrency queue (higher = more priority)
+	Parameters     map[string]string // Workflow step parameters translated into tool flags
+	Retry          *RetryPolicy      // Step retry policy (nil = tools.retry_attempts on non-zero exits)
 }
 
 // ToolExecutionEngine orchestrates tool execution with template resolution
@@ -184,16 +199,26 @@ type ToolExecutionEngine struct {
 	toolsPath        string
 	validator        *SecurityValidator
 	magicVarManager  *MagicVariableManager
+	parameterManager *ToolParameterManager
+	pacing           string // Pacing preset applied to every tool's arguments ("" = none)
+	secrets          *secrets.Store // {{secret:name}} values for tool arguments
+	redactor         *output.Redactor // Scrubs secrets and redaction patterns from everything the engine records
 	workspaceBase    string // Base workspace directory for this execution session
-	
-	// Dynamic concurrency control
+	scanID           string // Unique identifier of the current run
+	sessionName      string // --session-name of the run ("" = none)
+	location         *time.Location // Engagement timezone for recorded timestamps
+	exclusions       *scope.ExclusionList // Out-of-scope hosts that must never be scanned
+	engagement       *scope.Engagement // Scope file every host must be in (nil = none)
+	runAsUser        *privilege.RunAsUser // Unprivileged user for non-raw-socket tools (nil = no drop)
+	events           *EventBus // Receives tool output lines (nil = not published)
+	dirMode          os.FileMode // Mode for created workspace directories
+	fileMode         os.FileMode // Mode for created workspace files
+	
+	// Dynamic concurrency control; the only source of execution slots and status
 	concurrencyManager *ConcurrencyManager
 	
-	// Legacy concurrency control (deprecated but kept for compatibility)
-	concurrentSem    chan struct{}
-	parallelSem      chan struct{}
-	runningMutex     sync.RWMutex
-	runningTools     map[string]int // toolName -> count
+	// Workflow resource reservations reported with the execution status (set by the orchestrator)
+	resourceMonitor atomic.Pointer[ResourceMonitor]
 	
 	// Execution tracking for magic variables
 	completedTools   map[string]*ExecutionResult
@@ -208,6 +233,20 @@ type ToolExecutionEngine struct {
 	
 	// Error handling
 	errorHandler *ErrorHandler
+
+	// Started tool processes, stopped gracefully when their execution is cancelled
+	processes *processTracker
+
+	// Receives scan warnings (see SetWarningHandler)
+	warningHandler func(warning output.RunWarning)
+	warningMutex   sync.RWMutex
+
+	// Installed version checks against min_version/max_version, by tool
+	versionChecks map[string]*versionCheck
+	versionMutex  sync.Mutex
+
+	// Runs instead of every tool (see SetToolMock; nil = the real tools)
+	toolMock ToolMock
 }
 
 // NewToolExecutionEngine creates a new tool execution engine  
@@ -218,16 +257,11 @@ func NewToolExecutionEngine(globalConfig *config.Config, toolsPath string, outpu
 	}
 	// Get concurrency limits from config or use defaults
 	maxConcurrent := 3
-	maxParallel := 2
 	
 	if globalConfig != nil && globalConfig.Tools.ToolExecution.MaxConcurrentExecutions > 0 {
 		maxConcurrent = globalConfig.Tools.ToolExecution.MaxConcurrentExecutions
 	}
 	
-	if globalConfig != nil && globalConfig.Tools.ToolExecution.MaxParallelExecutions > 0 {
-		maxParallel = globalConfig.Tools.ToolExecution.MaxParallelExecutions
-	}
-	
 	// Create dynamic concurrency limits based on total concurrent limit
 	// Fast tools get more slots, heavy tools get fewer
 	fastLimit := maxConcurrent * 2     // 2x multiplier for fast tools
@@ -244,6 +278,10 @@ func NewToolExecutionEngine(globalConfig *config.Config, toolsPath string, outpu
 	magicVarManager := NewMagicVariableManager()
 	RegisterAllParsers(magicVarManager)
 	
+	// Initialize parameter manager for workflow step parameters
+	parameterManager := NewToolParameterManager()
+	RegisterAllParameterBuilders(parameterManager)
+	
 	// Setup default loggers (will be overridden when workspace is set)
 	debugLogger := log.New(os.Stderr)
 	debugLogger.SetLevel(log.DebugLevel)
@@ -251,8 +289,28 @@ func NewToolExecutionEngine(globalConfig *config.Config, toolsPath string, outpu
 	infoLogger := log.New(os.Stderr) 
 	infoLogger.SetLevel(log.InfoLevel)
 	
+	// Resolve workspace permission policy
+	dirMode := config.DefaultDirMode
+	fileMode := config.DefaultFileMode
+	if globalConfig != nil {
+		dirMode = globalConfig.Output.Permissions.DirPerm()
+		fileMode = globalConfig.Output.Permissions.FilePerm()
+	}
+	
 	// Create error handler  
 	errorHandler := NewErrorHandler("", outputMode)
+	errorHandler.dirMode = dirMode
+	errorHandler.fileMode = fileMode
+	
+	// Resolve engagement timezone for recorded timestamps
+	location := time.Local
+	if globalConfig != nil {
+		if loc, err := LoadEngagementLocation(globalConfig.Output.Timezone); err != nil {
+			debugLogger.Warn("Falling back to local timezone", "error", err)
+		} else {
+			location = loc
+		}
+	}
 	
 	// Create dynamic concurrency manager
 	concurrencyLimits := ConcurrencyLimits{
@@ -262,14 +320,27 @@ func NewToolExecutionEngine(globalConfig *config.Config, toolsPath string, outpu
 	}
 	concurrencyManager := NewConcurrencyManager(concurrencyLimits, debugLogger)
 	
+	// Tool configs may declare a generic output parser instead of a tool-specific one
+	configLoader := NewToolConfigLoader(configToolsPath)
+	magicVarManager.SetDeclaredParserLookup(func(toolName string) string {
+		if toolConfig, err := configLoader.LoadToolConfig(toolName); err == nil {
+			return toolConfig.Parser
+		}
+		return ""
+	})
+	
 	return &ToolExecutionEngine{
-		configLoader:     NewToolConfigLoader(configToolsPath),
+		configLoader:     configLoader,
 		templateResolver: NewTemplateResolver(globalConfig),
 		globalConfig:     globalConfig,
 		toolsPath:        toolsPath, // This can be empty for system PATH
 		validator:        NewSecurityValidator(globalConfig),
 		magicVarManager:  magicVarManager,
+		parameterManager: parameterManager,
 		workspaceBase:    "", // Will be set by SetWorkspaceBase if needed
+		location:         location,
+		dirMode:          dirMode,
+		fileMode:         fileMode,
 		debugLogger:      debugLogger,
 		infoLogger:       infoLogger,
 		outputController: output.NewOutputController(outputMode),
@@ -280,16 +351,115 @@ func NewToolExecutionEngine(globalConfig *config.Config, toolsPath string, outpu
 		// Error handling
 		errorHandler: errorHandler,
 		
-		// Legacy concurrency control (kept for compatibility)
-		concurrentSem:    make(chan struct{}, maxConcurrent),
-		parallelSem:      make(chan struct{}, maxParallel),
-		runningTools:     make(map[string]int),
+		processes: newProcessTracker(),
+		versionChecks: make(map[string]*versionCheck),
 		
 		// Initialize execution tracking
 		completedTools:   make(map[string]*ExecutionResult),
 	}
 }
 
+// SetRateLimit sets the packets/requests-per-second budget tools receive as {{rate_limit}}
+func (tee *ToolExecutionEngine) SetRateLimit(rateLimit int) {
+	tee.templateResolver.SetRateLimit(rateLimit)
+}
+
+// SetSecrets makes {{secret:name}} available in tool arguments. Output a tool only printed
+// keeps their values out of its output file; SetRedactor scrubs them from everything else
+func (tee *ToolExecutionEngine) SetSecrets(store *secrets.Store) {
+	tee.secrets = store
+	tee.templateResolver.SetSecrets(store)
+}
+
+// SetRedactor redacts command lines, console and raw output, logs and error messages.
+// Call before SetWorkspaceLoggers so the log files are redacted too
+func (tee *ToolExecutionEngine) SetRedactor(redactor *output.Redactor) {
+	tee.redactor = redactor
+	if tee.outputController != nil {
+		tee.outputController.SetRedactor(redactor)
+	}
+}
+
+// SetScanID sets the run identifier embedded in logs and output filenames.
+// Call before SetWorkspaceBase and SetWorkspaceLoggers so file loggers pick it up
+func (tee *ToolExecutionEngine) SetScanID(scanID string) {
+	tee.scanID = scanID
+	tee.templateResolver.SetScanID(scanID)
+	if tee.errorHandler != nil {
+		tee.errorHandler.scanID = scanID
+	}
+}
+
+// SetSessionName tags the engine's logs with the run's session name, like SetScanID
+func (tee *ToolExecutionEngine) SetSessionName(sessionName string) {
+	tee.sessionName = sessionName
+	if tee.errorHandler != nil {
+		tee.errorHandler.sessionName = sessionName
+	}
+}
+
+// GetSessionName returns the run's session name, empty when it has none
+func (tee *ToolExecutionEngine) GetSessionName() string {
+	return tee.sessionName
+}
+
+// logTag is the "[session ...] [scan ...]" tag of the engine's plain text log lines, empty
+// without a scan ID
+func (tee *ToolExecutionEngine) logTag() string {
+	if tee.scanID == "" {
+		return ""
+	}
+	if tee.sessionName != "" {
+		return fmt.Sprintf("[session %s] [scan %s]", tee.sessionName, tee.scanID)
+	}
+	return fmt.Sprintf("[scan %s]", tee.scanID)
+}
+
+// SetEventBus publishes every tool output line to bus as an EventToolOutputLine
+func (tee *ToolExecutionEngine) SetEventBus(bus *EventBus) {
+	tee.events = bus
+}
+
+// SetExclusions sets the out-of-scope hosts enforced for every tool execution
+func (tee *ToolExecutionEngine) SetExclusions(exclusions *scope.ExclusionList) {
+	tee.exclusions = exclusions
+}
+
+// SetEngagement sets the scope file every tool target and derived host must be in; refused
+// hosts are recorded in its audit log
+func (tee *ToolExecutionEngine) SetEngagement(engagement *scope.Engagement) {
+	tee.engagement = engagement
+}
+
+// refuseOutOfScope returns why a host is outside the engagement's scope, recording it in the
+// audit log, or nil when it is in scope. Hostnames are checked by the addresses the run's DNS
+// cache gives tools as {{resolved_ip}}
+func (tee *ToolExecutionEngine) refuseOutOfScope(host, source, workflowName, toolName string) error {
+	return tee.engagement.Refuse(host, tee.templateResolver.dnsCache.Resolve, scope.AuditEntry{
+		Source:   source,
+		ScanID:   tee.scanID,
+		Session:  tee.sessionName,
+		Workflow: workflowName,
+		Tool:     toolName,
+	})
+}
+
+// SetRunAsUser drops non-privileged tool executions to the given user.
+// Modes listed in a tool's privileged_modes keep the engine's own privileges
+func (tee *ToolExecutionEngine) SetRunAsUser(runAsUser *privilege.RunAsUser) {
+	tee.runAsUser = runAsUser
+}
+
+// GetLocation returns the engagement timezone used for recorded timestamps
+func (tee *ToolExecutionEngine) GetLocation() *time.Location {
+	return tee.location
+}
+
+// GetScanID returns the run identifier for this execution session
+func (tee *ToolExecutionEngine) GetScanID() string {
+	return tee.scanID
+}
+
 // SetWorkspaceBase sets the base workspace directory for this execution session
 func (tee *ToolExecutionEngine) SetWorkspaceBase(workspaceDir string) {
 	tee.workspaceBase = workspaceDir
@@ -311,6 +481,7 @@ func (tee *ToolExecutionEngine) SetOutputMode(mode output.OutputMode) {
 	// Update the output controller if it exists
 	if tee.outputController != nil {
 		tee.outputController = output.NewOutputController(mode)
+		tee.outputController.SetRedactor(tee.redactor)
 	}
 	
 	// Update error handler output mode
@@ -336,20 +507,21 @@ func (tee *ToolExecutionEngine) SetOutputMode(mode output.OutputMode) {
 
 // SetWorkspaceLoggers sets up loggers that write to workspace log files
 func (tee *ToolExecutionEngine) SetWorkspaceLoggers(workspaceDir string) error {
-	debugsDir := filepath.Join(workspaceDir, "logs", "debug")
-	infoDir := filepath.Join(workspaceDir, "logs", "info")
+	paths := workspace.New(workspaceDir).Paths()
+	debugsDir := filepath.Dir(paths.ToolsDebug)
+	infoDir := filepath.Dir(paths.ToolsInfo)
 	
 	// Create log directories
-	if err := os.MkdirAll(debugsDir, 0755); err != nil {
+	if err := os.MkdirAll(debugsDir, tee.dirMode); err != nil {
 		return fmt.Errorf("failed to create debug log directory: %v", err)
 	}
-	if err := os.MkdirAll(infoDir, 0755); err != nil {
+	if err := os.MkdirAll(infoDir, tee.dirMode); err != nil {
 		return fmt.Errorf("failed to create info log directory: %v", err)
 	}
 	
 	// Setup debug logger to write to both console and file
-	debugFile, err := os.OpenFile(filepath.Join(debugsDir, "tools.log"), 
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	debugFile, err := os.OpenFile(paths.ToolsDebug, 
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, tee.fileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open debug log file: %v", err)
 	}
@@ -363,14 +535,14 @@ func (tee *ToolExecutionEngine) SetWorkspaceLoggers(workspaceDir string) error {
 		// In normal mode, write only to file
 		debugMultiWriter = debugFile
 	}
-	tee.debugLogger = log.New(debugMultiWriter)
+	tee.debugLogger = log.New(output.NewRedactingWriter(debugMultiWriter, tee.redactor))
 	tee.debugLogger.SetReportCaller(false)
 	tee.debugLogger.SetReportTimestamp(true)
 	tee.debugLogger.SetLevel(log.DebugLevel)
 	
 	// Setup info logger to write to both console and file  
-	infoFile, err := os.OpenFile(filepath.Join(infoDir, "tools.log"),
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	infoFile, err := os.OpenFile(paths.ToolsInfo,
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, tee.fileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open info log file: %v", err)
 	}
@@ -384,24 +556,39 @@ func (tee *ToolExecutionEngine) SetWorkspaceLoggers(workspaceDir string) error {
 		// In normal mode, write only to file
 		infoMultiWriter = infoFile
 	}
-	tee.infoLogger = log.New(infoMultiWriter)
+	tee.infoLogger = log.New(output.NewRedactingWriter(infoMultiWriter, tee.redactor))
 	tee.infoLogger.SetReportCaller(false)
 	tee.infoLogger.SetReportTimestamp(true)
 	tee.infoLogger.SetLevel(log.InfoLevel)
 	
+	// Tag every entry with the run identifier
+	if tee.scanID != "" {
+		tee.debugLogger = tee.debugLogger.With("scan_id", tee.scanID)
+		tee.infoLogger = tee.infoLogger.With("scan_id", tee.scanID)
+	}
+	if tee.sessionName != "" {
+		tee.debugLogger = tee.debugLogger.With("session", tee.sessionName)
+		tee.infoLogger = tee.infoLogger.With("session", tee.sessionName)
+	}
+	
 	return nil
 }
 
-// writeRawOutput writes tool output to the raw output log file
-func (tee *ToolExecutionEngine) writeRawOutput(toolName, mode, outputType, content string) {
+// interleaveRawOutput reports whether output.raw.interleave is set
+func (tee *ToolExecutionEngine) interleaveRawOutput() bool {
+	return tee.globalConfig != nil && tee.globalConfig.Output.Raw.Interleave
+}
+
+// writeRawOutput writes a captured output stream to the raw output log file
+func (tee *ToolExecutionEngine) writeRawOutput(toolName, mode, outputType string, content *captureStream) {
 	if tee.workspaceBase == "" {
 		return // No workspace set
 	}
 	
-	rawLogPath := filepath.Join(tee.workspaceBase, "raw", "tool_output.log")
+	rawLogPath := workspace.New(tee.workspaceBase).Paths().RawLog
 	
 	// Create raw directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(rawLogPath), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(rawLogPath), tee.dirMode); err != nil {
 		if tee.debugLogger != nil {
 			tee.debugLogger.Error("Failed to create raw log directory", "error", err)
 		}
@@ -409,7 +596,7 @@ func (tee *ToolExecutionEngine) writeRawOutput(toolName, mode, outputType, conte
 	}
 	
 	// Open log file in append mode
-	file, err := os.OpenFile(rawLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := os.OpenFile(rawLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, tee.fileMode)
 	if err != nil {
 		if tee.debugLogger != nil {
 			tee.debugLogger.Error("Failed to open raw log file", "error", err)
@@ -421,10 +608,15 @@ func (tee *ToolExecutionEngine) writeRawOutput(toolName, mode, outputType, conte
 	// Write timestamped entry
 	timestamp := time.Now().Format(time.RFC3339)
 	header := fmt.Sprintf("\n[%s] === %s: %s %s ===\n", timestamp, outputType, toolName, mode)
+	if tag := tee.logTag(); tag != "" {
+		header = fmt.Sprintf("\n[%s] %s === %s: %s %s ===\n", timestamp, tag, outputType, toolName, mode)
+	}
 	footer := fmt.Sprintf("=== END %s ===\n", outputType)
 	
 	file.WriteString(header)
-	file.WriteString(content)
+	if err := content.copyRedactedTo(file, tee.redactor); err != nil && tee.debugLogger != nil {
+		tee.debugLogger.Error("Failed to copy tool output to raw log", "error", err)
+	}
 	file.WriteString(footer)
 }
 
@@ -434,15 +626,15 @@ func (tee *ToolExecutionEngine) writeDebugLog(message string, args ...interface{
 		return // No workspace set
 	}
 	
-	debugLogPath := filepath.Join(tee.workspaceBase, "logs", "debug", "execution.log")
+	debugLogPath := workspace.New(tee.workspaceBase).Paths().ExecutionLog
 	
 	// Create debug directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(debugLogPath), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(debugLogPath), tee.dirMode); err != nil {
 		return // Silent failure to avoid infinite loops
 	}
 	
 	// Open log file in append mode
-	file, err := os.OpenFile(debugLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := os.OpenFile(debugLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, tee.fileMode)
 	if err != nil {
 		return // Silent failure
 	}
@@ -456,7 +648,12 @@ func (tee *ToolExecutionEngine) writeDebugLog(message string, args ...interface{
 	} else {
 		logMessage = message
 	}
+	logMessage = tee.redactor.Redact(logMessage)
 	
+	if tag := tee.logTag(); tag != "" {
+		file.WriteString(fmt.Sprintf("[%s] %s %s\n", timestamp, tag, logMessage))
+		return
+	}
 	file.WriteString(fmt.Sprintf("[%s] %s\n", timestamp, logMessage))
 }
 
@@ -476,10 +673,24 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 		ToolName:  toolName,
 		Mode:      mode,
 		Target:    target,
-		StartTime: startTime,
+		StartTime: wallTime(startTime, tee.location),
 		Success:   false,
 	}
 
+	// Never touch out-of-scope targets, including ones passed in by chained workflows
+	if err := tee.refuseOutOfScope(target, scope.AuditSourceTool, workflowName, toolName); err != nil {
+		tee.infoLogger.Warn("Refused out-of-scope target", "tool", toolName, "target", target, "reason", err)
+		result.ErrorMessage = err.Error()
+		tee.finishResult(result, startTime)
+		return result, err
+	}
+	if err := tee.exclusions.CheckResolved(target, tee.templateResolver.dnsCache.Resolve); err != nil {
+		err = fmt.Errorf("target refused: %w", err)
+		result.ErrorMessage = err.Error()
+		tee.finishResult(result, startTime)
+		return result, err
+	}
+
 	// Determine priority from options or use default
 	priority := 100 // Default medium priority
 	if options != nil && options.Priority > 0 {
@@ -495,16 +706,15 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 	executionRequest, err := tee.concurrencyManager.RequestExecution(ctx, toolName, priority)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to request execution slot: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+		tee.finishResult(result, startTime)
 		return result, err
 	}
 	
 	// Wait for execution slot to become available
 	if err := executionRequest.WaitForExecution(); err != nil {
+		tee.concurrencyManager.Abandon(executionRequest)
 		result.ErrorMessage = "execution cancelled while waiting for slot"
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+		tee.finishResult(result, startTime)
 		return result, err
 	}
 	
@@ -520,99 +730,143 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 	if err != nil {
 		tee.debugLogger.Error("Failed to load tool config", "tool", toolName, "error", err)
 		result.ErrorMessage = fmt.Sprintf("failed to load tool config: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+		tee.finishResult(result, startTime)
 		return result, err
 	}
 	tee.debugLogger.Debug("Tool config loaded successfully", "tool", toolName)
 	tee.writeDebugLog("Tool config loaded successfully")
 
+	// Argument templates are written for min_version..max_version; see version_policy
+	if err := tee.enforceToolVersion(ctx, toolName, mode, target, toolConfig); err != nil {
+		result.ErrorMessage = err.Error()
+		tee.finishResult(result, startTime)
+		return result, err
+	}
+
 
 	// Get tool arguments for the specified mode
 	argsTemplate, err := toolConfig.GetToolArguments(mode)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to get tool arguments: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+		tee.finishResult(result, startTime)
 		return result, err
 	}
 
 	// Create execution context
-	execCtx := tee.templateResolver.CreateExecutionContextWithWorkflow(target, toolName, mode, workflowName, stepName)
-
-	// Generate workspace paths - use workspaceBase if set, otherwise generate from target
-	var workspaceDir string
-	if tee.workspaceBase != "" {
-		// Use the pre-created workspace directory from CLI
-		workspaceDir = tee.workspaceBase
-		tee.debugLogger.Debug("Using preset workspace", "workspace", workspaceDir)
-	} else {
-		// Generate workspace path from target (fallback for TUI mode)
-		sanitizedTarget := sanitizeForFilename(target)
-		workspaceDir = filepath.Join("./workspace", sanitizedTarget)
-		tee.debugLogger.Debug("Generated workspace", "workspace", workspaceDir)
-	}
-	
-	execCtx.Workspace = workspaceDir
-	execCtx.OutputDir = workspaceDir
-	execCtx.ScansDir = filepath.Join(workspaceDir, "scans")
-	execCtx.LogsDir = filepath.Join(workspaceDir, "logs")
-	execCtx.ReportsDir = filepath.Join(workspaceDir, "reports")
-	execCtx.RawDir = filepath.Join(workspaceDir, "raw")
+	execCtx := tee.newExecutionContext(target, toolName, mode, workflowName, stepName)
+	workspaceDir := execCtx.Workspace
 
 	// Set custom output file if tool config specifies one
 	if toolConfig.File != "" {
 		execCtx.OutputFile = toolConfig.File
 	}
+	execCtx.TargetIP = toolConfig.TargetIP
 
 
 	// Resolve template variables in arguments
-	resolvedArgs, err := tee.templateResolver.ResolveArguments(argsTemplate, execCtx)
+	resolved, err := tee.templateResolver.resolveArgumentTemplates(argsTemplate, execCtx)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to resolve template variables: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+		tee.finishResult(result, startTime)
 		return result, err
 	}
+	resolvedArgs := resolved.args
 
-	// Validate arguments against security policies
-	if err := tee.validator.ValidateArguments(resolvedArgs); err != nil {
-		result.ErrorMessage = fmt.Sprintf("argument validation failed: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+	// Keep the files the tool writes inside the workspace, whatever its YAML or the target says
+	if err := tee.validator.ValidateOutputPaths(workspaceDir, resolved.templates, resolvedArgs); err != nil {
+		result.ErrorMessage = fmt.Sprintf("output path validation failed: %v", err)
+		tee.finishResult(result, startTime)
 		return result, err
 	}
 
-	result.CommandLine = append([]string{toolName}, resolvedArgs...)
+	// Slow down or speed up the tool as the run's pacing preset asks
+	if tee.pacing != "" {
+		resolvedArgs = applyPacing(resolvedArgs, toolConfig.Pacing[tee.pacing])
+	}
 
-	// Determine the tool executable path
-	toolExecutable, err := tee.findToolExecutable(toolName)
-	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("failed to find tool executable: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
-		return result, err
+	// Translate workflow step parameters into tool flags (validated by the evasion policy)
+	if options != nil && len(options.Parameters) > 0 {
+		if err := tee.validator.ValidateStepParameters(options.Parameters); err != nil {
+			result.ErrorMessage = fmt.Sprintf("parameter validation failed: %v", err)
+			tee.finishResult(result, startTime)
+			return result, err
+		}
+		paramArgs, err := tee.parameterManager.BuildArguments(toolName, options.Parameters)
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("failed to build parameter arguments: %v", err)
+			tee.finishResult(result, startTime)
+			return result, err
+		}
+		// After the mode arguments: nmap keeps the last -T it is given, and modes already set -T4
+		resolvedArgs = append(resolvedArgs, paramArgs...)
+	}
+
+	// Pass host exclusions to tools with native support; refuse ranges that would hit excluded hosts otherwise
+	if !tee.exclusions.IsEmpty() {
+		exclusionArgs, supported := tee.parameterManager.BuildExclusionArguments(toolName, tee.exclusions.Entries())
+		if supported {
+			resolvedArgs = append(exclusionArgs, resolvedArgs...)
+		} else if tee.exclusions.Overlaps(target) {
+			err := fmt.Errorf("tool %s has no native exclusion support and target %s overlaps excluded hosts", toolName, target)
+			result.ErrorMessage = err.Error()
+			tee.finishResult(result, startTime)
+			return result, err
+		}
 	}
 
-	// Validate executable path against security policies
-	if err := tee.validator.ValidateExecutable(toolExecutable); err != nil {
-		result.ErrorMessage = fmt.Sprintf("executable validation failed: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
-		return result, err
+	// Validate arguments against security policies
+	if err := tee.validator.ValidateArguments(resolvedArgs); err != nil {
+		result.ErrorMessage = fmt.Sprintf("argument validation failed: %v", err)
+		tee.finishResult(result, startTime)
+		return result, fmt.Errorf("%s", tee.redactor.Redact(err.Error())) // The error quotes the argument
+	}
+
+	result.CommandLine = append([]string{toolName}, tee.redactor.RedactAll(resolvedArgs)...)
+
+	// Determine the tool executable path; a tool mock replaces the whole command (workflow test)
+	var toolExecutable string
+	if tee.toolMock != nil {
+		toolExecutable, resolvedArgs = tee.mockedCommand(ToolCall{
+			Tool:     toolName,
+			Mode:     mode,
+			Workflow: workflowName,
+			Step:     stepName,
+			Args:     resolvedArgs,
+			Outputs:  outputPathArguments(resolved.templates, resolved.args),
+		})
+	} else {
+		toolExecutable, err = tee.findToolExecutable(toolName)
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("failed to find tool executable: %v", err)
+			tee.finishResult(result, startTime)
+			return result, err
+		}
+
+		// Validate executable path against security policies (a built-in tool is IPCrawler itself)
+		if err := tee.validator.ValidateExecutable(toolExecutable); err != nil && !toolConfig.Builtin {
+			result.ErrorMessage = fmt.Sprintf("executable validation failed: %v", err)
+			tee.finishResult(result, startTime)
+			return result, err
+		}
 	}
 
 	// Set up execution options
 	if options == nil {
 		options = &ExecutionOptions{}
 	}
+
+	// Use the step's retry policy, or tools.retry_attempts when it has none
+	retry := options.Retry
+	if retry == nil {
+		retry = tee.defaultRetryPolicy()
+	}
+	retryAttempts := retry.Attempts - 1
+
+	// Each attempt gets the mode's timeout; unless the caller set one, the whole execution
+	// gets every attempt and the waits between them
+	timeout := tee.ModeTimeout(toolName, mode)
 	if options.Timeout == 0 {
-		// Use timeout from global config if available, otherwise use a reasonable default
-		if tee.globalConfig != nil && tee.globalConfig.Tools.DefaultTimeout > 0 {
-			options.Timeout = time.Duration(tee.globalConfig.Tools.DefaultTimeout) * time.Second
-		} else {
-			options.Timeout = 30 * time.Minute // Fallback default
-		}
+		options.Timeout = retry.budget(timeout)
 	}
 
 	// Create context with timeout
@@ -632,10 +886,9 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 		if dir != "" {
 			// Check if directory already exists before creating (CLI mode pre-creates these)
 			if _, err := os.Stat(dir); os.IsNotExist(err) {
-				if err := os.MkdirAll(dir, 0755); err != nil {
+				if err := os.MkdirAll(dir, tee.dirMode); err != nil {
 					result.ErrorMessage = fmt.Sprintf("failed to create directory %s: %v", dir, err)
-					result.EndTime = time.Now()
-					result.Duration = result.EndTime.Sub(result.StartTime)
+					tee.finishResult(result, startTime)
 					return result, err
 				}
 			}
@@ -647,30 +900,31 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 
 	// Store the expected output path (remove hardcoded tool-specific extensions)
 	if outputPath, exists := vars["output_path"]; exists {
+		if err := tee.validator.ValidateOutputPath(workspaceDir, outputPath); err != nil {
+			result.ErrorMessage = fmt.Sprintf("output path validation failed: %v", err)
+			tee.finishResult(result, startTime)
+			return result, err
+		}
 		result.OutputPath = outputPath
 	}
 
-	// Prepare output buffers
-	var stdoutBuf, stderrBuf bytes.Buffer
-
-	// Execute with retry logic
-	retryAttempts := 1
-	if tee.globalConfig != nil && tee.globalConfig.Tools.RetryAttempts > 0 {
-		retryAttempts = tee.globalConfig.Tools.RetryAttempts
-	}
+	// Output captured from stdout that became the output file, and the variables parsed from it
+	var promoted bool
+	var streamed parsers.LineStream
 
 	var lastErr error
 	for attempt := 0; attempt <= retryAttempts; attempt++ {
-		// Reset buffers for each attempt
-		if options.CaptureOutput {
-			stdoutBuf.Reset()
-			stderrBuf.Reset()
+		// A failed attempt's stdout is not kept as the output file
+		if promoted {
+			os.Remove(result.OutputPath)
+			promoted, streamed = false, nil
 		}
 
 		// Create a new command for each attempt
-		tee.debugLogger.Debug("Executing command", "executable", toolExecutable, "args", resolvedArgs)
-		tee.writeDebugLog("Executing command: %s %v", toolExecutable, resolvedArgs)
+		tee.debugLogger.Debug("Executing command", "executable", toolExecutable, "args", tee.redactor.RedactAll(resolvedArgs))
+		tee.writeDebugLog("Executing command: %s %v", toolExecutable, tee.redactor.RedactAll(resolvedArgs))
 		execCmd := exec.CommandContext(execContext, toolExecutable, resolvedArgs...)
+		tee.processes.manage(execCmd, toolName+" "+mode, tee.ShutdownGrace())
 		
 		// Set working directory
 		if options.WorkingDir != "" {
@@ -683,14 +937,33 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 			execCmd.Env = append(execCmd.Env, fmt.Sprintf("%s=%s", key, value))
 		}
 
-		// Set up output capture using temporary files instead of pipes to avoid deadlocks
-		var stdoutFile, stderrFile *os.File
+		// Drop privileges unless this mode needs raw sockets
+		if tee.runAsUser != nil && !toolConfig.RequiresPrivileges(mode) {
+			tee.runAsUser.Apply(execCmd)
+			tee.debugLogger.Debug("Running tool without root privileges", "tool", toolName, "mode", mode, "user", tee.runAsUser.String())
+		}
+
+		// Stream output through pipes: lines reach the console, the raw log and the tool's
+		// line parser while it runs, and stdout is spooled to disk rather than held in memory
+		var capture *outputCapture
+		var interleaved *interleavedRawLog
+		var stream parsers.LineStream
 		if options.CaptureOutput {
-			// Create temporary files for stdout and stderr
-			stdoutFile, _ = os.CreateTemp("", "ipcrawler-stdout-*")
-			stderrFile, _ = os.CreateTemp("", "ipcrawler-stderr-*")
-			execCmd.Stdout = stdoutFile
-			execCmd.Stderr = stderrFile
+			// Interleave mode records both streams to the raw log as they are produced
+			if tee.interleaveRawOutput() {
+				interleaved = tee.openInterleavedRawLog(toolName, mode)
+			}
+			stream = tee.magicVarManager.StreamFor(toolName)
+
+			var err error
+			if capture, err = tee.newOutputCapture(toolName, mode, result.OutputPath, interleaved, stream); err != nil {
+				interleaved.Close()
+				result.ErrorMessage = err.Error()
+				tee.finishResult(result, startTime)
+				return result, err
+			}
+			tee.publishOutputLines(capture, execCtx)
+			capture.attach(execCmd)
 		} else {
 			// If not capturing, just connect directly to console
 			execCmd.Stdout = os.Stdout
@@ -704,16 +977,22 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 		if err := execCmd.Start(); err != nil {
 			lastErr = err
 			tee.debugLogger.Debug("Failed to start command", "error", lastErr)
+			tee.processes.done(execCmd)
+			if capture != nil {
+				capture.finish()
+				capture.discard()
+			}
+			interleaved.Close()
 			continue
 		}
 
-		// SIMPLIFIED EXECUTION using temporary files
 		if options.CaptureOutput {
 			var progress *SimpleProgress
 			
 			// Start progress tracking if needed
-			if toolConfig.ShowSeparator {
+			if toolConfig.ShowSeparator && tee.outputController.ShouldShowProgress() {
 				progress = NewSimpleProgress(toolName, mode)
+				progress.SetDeadline(time.Now().Add(timeout))
 			}
 
 			// Wait for command to complete with timeout
@@ -722,117 +1001,109 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 				done <- execCmd.Wait()
 			}()
 			
-			// Set tool-specific timeout
-			timeout := 5 * time.Second
-			if toolName == "nmap" {
-				timeout = 15 * time.Second // nmap service detection needs more time
-			}
-			
 			select {
 			case lastErr = <-done:
 				// Command completed normally
 			case <-time.After(timeout):
-				// Command timeout - kill it and continue
-				execCmd.Process.Kill()
+				// Command timeout - kill it (and anything it started) and continue
+				killProcessGroup(execCmd)
 				lastErr = fmt.Errorf("command timeout after %v", timeout)
 				<-done // Wait for the goroutine to finish
 				
-				tee.debugLogger.Debug("Command timed out - will check for valid output after reading files", "timeout", timeout)
+				tee.debugLogger.Debug("Command timed out - will check for valid output it already wrote", "timeout", timeout)
 			}
-			
-			// Close files and read their contents
-			if stdoutFile != nil {
-				stdoutFile.Close()
-				if data, err := os.ReadFile(stdoutFile.Name()); err == nil {
-					stdoutBuf.Write(data)
-				}
-				os.Remove(stdoutFile.Name()) // Clean up temp file
+			capture.finish()
+			interleaved.Close()
+			if capture.stdout.err != nil {
+				tee.debugLogger.Error("Failed to spool stdout", "tool", toolName, "error", capture.stdout.err)
 			}
-			
-			if stderrFile != nil {
-				stderrFile.Close()
-				if data, err := os.ReadFile(stderrFile.Name()); err == nil {
-					stderrBuf.Write(data)
+
+			// Output the tool only printed becomes its output file
+			if result.OutputPath != "" && capture.stdout.size > 0 && capture.stdout.err == nil {
+				if _, err := os.Stat(result.OutputPath); os.IsNotExist(err) {
+					if err := capture.promoteStdout(result.OutputPath, tee.fileMode, tee.secrets); err != nil {
+						tee.debugLogger.Error("Failed to save stdout", "path", result.OutputPath, "error", err)
+					} else {
+						tee.debugLogger.Debug("Saved captured stdout", "path", result.OutputPath, "bytes", capture.stdout.size)
+						promoted = true
+						streamed = stream
+					}
 				}
-				os.Remove(stderrFile.Name()) // Clean up temp file
 			}
 			
 			// Complete the progress tracking
 			if progress != nil {
 				progress.Complete()
-				
-				// Only show raw output in verbose mode
-				if tee.outputController.ShouldShowRaw() {
-					if stdoutBuf.Len() > 0 || stderrBuf.Len() > 0 {
-						if toolConfig.ShowSeparator {
-							tee.outputController.PrintCompleteToolOutput(toolName, mode, stdoutBuf.String(), stderrBuf.String(), lastErr != nil)
-						} else {
-							// Just print raw output without separators for tools that don't want them
-							if stdoutBuf.Len() > 0 {
-								fmt.Print(stdoutBuf.String())
-							}
-							if stderrBuf.Len() > 0 {
-								fmt.Fprintf(os.Stderr, "\033[31m%s\033[0m", stderrBuf.String())
-							}
-						}
-					}
-				}
-			} else if stdoutBuf.Len() > 0 || stderrBuf.Len() > 0 {
-				// Tool completed without showing progress (no separator config)
-				if tee.outputController.ShouldShowRaw() {
-					if stdoutBuf.Len() > 0 {
-						fmt.Print(stdoutBuf.String())
-					}
-					if stderrBuf.Len() > 0 {
-						fmt.Fprintf(os.Stderr, "\033[31m%s\033[0m", stderrBuf.String())
-					}
-				}
+			}
+
+			// Lines were shown as they arrived in verbose mode; say so when there were none
+			if tee.outputController.ShouldShowRaw() && toolConfig.ShowSeparator && !capture.hasOutput() {
+				tee.outputController.PrintCompleteToolOutput(toolName, mode, "", "", lastErr != nil)
 			}
 		} else {
 			// Just wait for command if not capturing
 			lastErr = execCmd.Wait()
 		}
+		stopped := tee.processes.done(execCmd)
 
-		tee.debugLogger.Debug("Command completed", "error", lastErr)
+		// Map the exit code through the mode's exit_codes; killed and stopped runs keep their error
+		exitCode := -1
+		if execCmd.ProcessState != nil {
+			exitCode = execCmd.ProcessState.ExitCode()
+		}
+		result.Warning = ""
+		if codes, declared := toolConfig.ExitCodesFor(mode); declared && !stopped && exitCode >= 0 {
+			switch codes.Classify(exitCode) {
+			case ExitClassSuccess:
+				lastErr = nil
+			case ExitClassWarning:
+				lastErr = nil
+				result.Warning = fmt.Sprintf("%s %s exited with code %d", toolName, mode, exitCode)
+				tee.warn(output.WarningExitCode, toolName, mode, target, "exited with code %d, declared a warning in its exit_codes", exitCode)
+			case ExitClassFailure:
+				if lastErr == nil {
+					lastErr = &declaredFailureError{code: exitCode}
+				}
+			}
+		}
+
+		tee.debugLogger.Debug("Command completed", "error", lastErr, "stopped", stopped)
 		tee.writeDebugLog("Command completed with error: %v", lastErr)
 
 		// Check for timeout errors and validate if tool produced valid output
 		if lastErr != nil && strings.Contains(lastErr.Error(), "timeout") {
 			toolProducedValidOutput := false
 			
-			// Check if output file was created successfully
-			if result.OutputPath != "" {
+			// Check the mode's declared artifact, or fall back to any output file being created
+			if expected, declared := toolConfig.ExpectedOutputFor(mode); declared && result.OutputPath != "" {
+				if err := expected.Check(result.OutputPath, nil); err == nil {
+					toolProducedValidOutput = true
+					tee.debugLogger.Debug("Command timed out but produced its expected output, treating as success", "output_path", expected.Path(result.OutputPath))
+				} else {
+					tee.debugLogger.Debug("Command timed out without its expected output", "error", err)
+				}
+			} else if result.OutputPath != "" {
 				outputPaths := []string{result.OutputPath, result.OutputPath + ".json", result.OutputPath + ".xml"}
 				
 				for _, path := range outputPaths {
+					if promoted && path == result.OutputPath {
+						continue // Saved stdout is judged by its content below
+					}
 					if _, err := os.Stat(path); err == nil {
-						// For nmap XML files, verify they contain scan data
-						if strings.HasSuffix(path, ".xml") && toolName == "nmap" {
-							if data, err := os.ReadFile(path); err == nil {
-								content := string(data)
-								// Check for nmap XML structure with scan initiation
-								if strings.Contains(content, "<nmaprun") && strings.Contains(content, "scan initiated") {
-									toolProducedValidOutput = true
-									tee.debugLogger.Debug("Command timed out but valid nmap XML created, treating as success", "output_path", path)
-									break
-								}
-							}
-						} else {
-							toolProducedValidOutput = true
-							tee.debugLogger.Debug("Command timed out but output file created, treating as success", "output_path", path)
-							break
-						}
+						toolProducedValidOutput = true
+						tee.debugLogger.Debug("Command timed out but output file created, treating as success", "output_path", path)
+						break
 					}
 				}
 			}
 			
 			// Also check if stdout contains valid JSON output (for tools like naabu)
-			if !toolProducedValidOutput && stdoutBuf.Len() > 0 {
-				stdout := stdoutBuf.String()
+			if !toolProducedValidOutput && capture != nil && capture.stdout.head.Len() > 0 {
+				stdout := capture.stdout.head.String()
 				// Check for JSON output patterns (naabu produces JSON lines)
 				if strings.Contains(stdout, `"host":`) && strings.Contains(stdout, `"port":`) && strings.Contains(stdout, `"protocol":`) {
 					toolProducedValidOutput = true
-					tee.debugLogger.Debug("Command timed out but produced valid JSON output, treating as success", "stdout_length", len(stdout))
+					tee.debugLogger.Debug("Command timed out but produced valid JSON output, treating as success", "stdout_length", capture.stdout.size)
 				}
 			}
 			
@@ -840,29 +1111,38 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 			if toolProducedValidOutput {
 				lastErr = nil
 				tee.debugLogger.Debug("Tool timeout overridden due to valid output production")
+				tee.warn(output.WarningCoverageReduced, toolName, mode, target, "timed out after %s; only the results it wrote before then are included", timeout)
 			} else {
 				tee.debugLogger.Debug("Command timed out with no valid output detected")
 			}
 		}
 
+		// Store the start of the captured output in the result; the rest is in the output file and raw log
+		if capture != nil {
+			result.Stdout = tee.redactor.Redact(capture.stdout.head.String())
+			result.Stderr = tee.redactor.Redact(capture.stderr.head.String())
+		}
+
 		// Handle tool errors if execution failed
 		if lastErr != nil {
 			toolErr := &ToolError{
 				ToolName:  toolName,
 				Mode:      mode,
 				Target:    target,
-				Command:   append([]string{toolExecutable}, resolvedArgs...),
+				Command:   append([]string{toolExecutable}, tee.redactor.RedactAll(resolvedArgs)...),
 				ExitCode:  -1, // Will be updated below if possible
-				Stderr:    stderrBuf.String(),
-				Stdout:    stdoutBuf.String(),
+				Stderr:    result.Stderr,
+				Stdout:    result.Stdout,
 				ErrorMsg:  lastErr.Error(),
-				Timestamp: time.Now(),
+				Timestamp: wallTime(time.Now(), tee.location),
 				Duration:  time.Since(startTime),
 			}
 			
 			// Extract exit code if available
 			if exitErr, ok := lastErr.(*exec.ExitError); ok {
 				toolErr.ExitCode = exitErr.ExitCode()
+			} else if declared, ok := lastErr.(*declaredFailureError); ok {
+				toolErr.ExitCode = declared.code
 			}
 			
 			// Report the error
@@ -871,41 +1151,82 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 			}
 		}
 
-		// Store captured output in result
-		if options.CaptureOutput {
-			result.Stdout = stdoutBuf.String()
-			result.Stderr = stderrBuf.String()
-			
-			// Write captured output to raw output files (real-time display already handled above)
-			if result.Stdout != "" {
-				tee.writeRawOutput(toolName, mode, "STDOUT", result.Stdout)
-			}
-			if result.Stderr != "" {
-				tee.writeRawOutput(toolName, mode, "STDERR", result.Stderr)
+		// Write captured output to raw output files unless it was already interleaved as produced
+		if capture != nil {
+			if interleaved == nil {
+				if capture.stdout.size > 0 {
+					tee.writeRawOutput(toolName, mode, "STDOUT", capture.stdout)
+				}
+				if capture.stderr.size > 0 {
+					tee.writeRawOutput(toolName, mode, "STDERR", capture.stderr)
+				}
 			}
+			capture.discard()
 		}
 
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(result.StartTime)
+		tee.finishResult(result, startTime)
 
 		if lastErr == nil {
 			// Success
 			result.Success = true
 			result.ExitCode = 0
-			// Tool end marker is now handled in PrintCompleteToolOutput
+			if exitCode > 0 {
+				result.ExitCode = exitCode // Declared a success or warning in exit_codes
+			}
+			
+			// An empty result is only retried when the policy asks for it; the last attempt stands
+			if attempt < retryAttempts && retry.retries(RetryOnEmptyOutput) && producedNoOutput(toolConfig, mode, result, capture != nil && capture.stdout.content) {
+				tee.debugLogger.Debug("Tool produced no output, retrying", "tool", toolName, "mode", mode, "attempt", attempt+1, "policy", retry.String())
+				result.Success = false
+				if !tee.waitBeforeRetry(execContext, retry, attempt, result) {
+					return result, execContext.Err()
+				}
+				continue
+			}
 			break
 		}
 
+		// A tool stopped mid-run keeps what it printed as a partial result
+		if stopped && promoted {
+			if err := os.Rename(result.OutputPath, result.OutputPath+partialOutputSuffix); err != nil {
+				tee.debugLogger.Error("Failed to keep partial output", "path", result.OutputPath, "error", err)
+			} else {
+				tee.debugLogger.Debug("Kept partial output of stopped tool", "path", result.OutputPath+partialOutputSuffix)
+				promoted, streamed = false, nil
+			}
+		}
+
+		// Stdout of a failed run is not kept as its output file
+		if promoted {
+			os.Remove(result.OutputPath)
+			promoted, streamed = false, nil
+		}
+
 		// Handle error
 		if exitError, ok := lastErr.(*exec.ExitError); ok {
 			result.ExitCode = exitError.ExitCode()
+		} else if declared, ok := lastErr.(*declaredFailureError); ok {
+			result.ExitCode = declared.code
 		} else {
 			result.ExitCode = -1
 		}
 
-		// Don't retry timeout errors - they'll just timeout again (unless they were validated as successful)
+		// Timeouts (that were not validated as successful) are only retried when the policy asks for it
 		if lastErr != nil && strings.Contains(lastErr.Error(), "timeout") {
-			result.ErrorMessage = fmt.Sprintf("tool execution timed out: %v", lastErr)
+			if attempt == retryAttempts || !retry.retries(RetryOnTimeout) || execContext.Err() != nil {
+				result.ErrorMessage = fmt.Sprintf("tool execution timed out: %v", lastErr)
+				return result, lastErr
+			}
+			tee.debugLogger.Debug("Tool timed out, retrying", "tool", toolName, "mode", mode, "attempt", attempt+1, "policy", retry.String())
+			if !tee.waitBeforeRetry(execContext, retry, attempt, result) {
+				return result, execContext.Err()
+			}
+			continue
+		}
+
+		// Other failures are retried unless the policy excludes them
+		if attempt < retryAttempts && !retry.retries(RetryOnNonzeroExit) {
+			result.ErrorMessage = fmt.Sprintf("tool execution failed: %v", lastErr)
 			return result, lastErr
 		}
 
@@ -917,33 +1238,21 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 
 		// Wait before retrying (exponential backoff)
 		if attempt < retryAttempts {
-			waitTime := time.Duration(attempt+1) * time.Second
-			select {
-			case <-time.After(waitTime):
-				// Continue to retry
-			case <-execContext.Done():
-				result.ErrorMessage = "execution cancelled during retry wait"
+			if !tee.waitBeforeRetry(execContext, retry, attempt, result) {
 				return result, execContext.Err()
 			}
 		}
 	}
 
-	// Save captured stdout to file if tool succeeded and has output but no file was created
-	if result.Success && options.CaptureOutput && result.Stdout != "" && result.OutputPath != "" {
-		if _, err := os.Stat(result.OutputPath); os.IsNotExist(err) {
-			// Tool didn't create output file, so save captured stdout
-			tee.debugLogger.Debug("Saving captured stdout", "path", result.OutputPath)
-			if err := os.WriteFile(result.OutputPath, []byte(result.Stdout), 0644); err != nil {
-				tee.debugLogger.Error("Failed to save stdout", "error", err)
-			} else {
-				tee.debugLogger.Debug("Successfully saved stdout", "bytes", len(result.Stdout))
-			}
-		}
-	}
-
-	// Validate output file was created if requested
+	// Validate output file was created if requested, using the mode's declaration when present
 	if options.ValidateOutput && result.OutputPath != "" {
-		if _, err := os.Stat(result.OutputPath); os.IsNotExist(err) {
+		if expected, declared := toolConfig.ExpectedOutputFor(mode); declared {
+			if err := expected.Check(result.OutputPath, nil); err != nil {
+				result.Success = false
+				result.ErrorMessage = err.Error()
+				return result, fmt.Errorf("output validation failed: %s", result.ErrorMessage)
+			}
+		} else if _, err := os.Stat(result.OutputPath); os.IsNotExist(err) {
 			result.Success = false
 			result.ErrorMessage = fmt.Sprintf("expected output file not created: %s", result.OutputPath)
 			return result, fmt.Errorf("output validation failed: %s", result.ErrorMessage)
@@ -955,11 +1264,15 @@ func (tee *ToolExecutionEngine) ExecuteToolWithContext(ctx context.Context, tool
 	tee.completedTools[toolName] = result
 	tee.completedMutex.Unlock()
 
-	// Auto-process magic variables if tool succeeded
-	if result.Success && result.OutputPath != "" {
+	// Auto-process magic variables if tool succeeded; output parsed while it streamed is not read again
+	if result.Success && result.OutputPath != "" && streamed != nil {
+		for varName, varValue := range tee.magicVarManager.ProcessStreamedOutput(toolName, result.OutputPath, streamed) {
+			tee.templateResolver.AddVariable(varName, varValue)
+		}
+	} else if result.Success && result.OutputPath != "" {
 		if err := tee.processToolOutputForMagicVariables(toolName, []string{result.OutputPath}); err != nil {
-			// Log warning but don't fail the execution
-			tee.outputController.PrintWarning("Failed to process magic variables for %s: %v", toolName, err)
+			// A parse failure leaves later steps without this tool's variables but does not fail the execution
+			tee.warn(output.WarningParserDegraded, toolName, mode, target, "output could not be parsed for variables: %v", err)
 		}
 	}
 
@@ -1040,13 +1353,60 @@ func (tee *ToolExecutionEngine) GetMagicVariables() map[string]string {
 	return tee.templateResolver.GetAllVariables()
 }
 
+// newExecutionContext returns the template context of a tool run, with the workspace paths
+func (tee *ToolExecutionEngine) newExecutionContext(target, toolName, mode, workflowName, stepName string) *ExecutionContext {
+	execCtx := tee.templateResolver.CreateExecutionContextWithWorkflow(target, toolName, mode, workflowName, stepName)
+
+	// Generate workspace paths - use workspaceBase if set, otherwise generate from target
+	var workspaceDir string
+	if tee.workspaceBase != "" {
+		// Use the pre-created workspace directory from CLI
+		workspaceDir = tee.workspaceBase
+		tee.debugLogger.Debug("Using preset workspace", "workspace", workspaceDir)
+	} else {
+		// Generate workspace path from target (fallback for TUI mode)
+		sanitizedTarget := sanitizeForFilename(target)
+		workspaceDir = filepath.Join("./workspace", sanitizedTarget)
+		tee.debugLogger.Debug("Generated workspace", "workspace", workspaceDir)
+	}
+	
+	paths := workspace.New(workspaceDir).Paths()
+	execCtx.Workspace = workspaceDir
+	execCtx.OutputDir = workspaceDir
+	execCtx.ScansDir = paths.Scans
+	execCtx.LogsDir = paths.Logs
+	execCtx.ReportsDir = paths.Reports
+	execCtx.RawDir = paths.Raw
+	return execCtx
+}
+
+// ResolveTarget resolves a target through the run's DNS cache: the addresses tools then see
+// as {{resolved_ip}} and {{resolved_ips}}
+func (tee *ToolExecutionEngine) ResolveTarget(target string) ([]string, error) {
+	return tee.templateResolver.dnsCache.ResolveTarget(target)
+}
+
+// ResolvedHosts returns the addresses of every hostname the run resolved
+func (tee *ToolExecutionEngine) ResolvedHosts() map[string][]string {
+	return tee.templateResolver.dnsCache.Resolved()
+}
+
 // GetTemplateResolver returns the template resolver for workflow variable mapping
 func (tee *ToolExecutionEngine) GetTemplateResolver() *TemplateResolver {
 	return tee.templateResolver
 }
 
+// ToolPath returns the executable a tool would run from: tools_path first, then the system PATH
+func (tee *ToolExecutionEngine) ToolPath(toolName string) (string, error) {
+	return tee.findToolExecutable(toolName)
+}
+
 // findToolExecutable locates the executable for a tool
 func (tee *ToolExecutionEngine) findToolExecutable(toolName string) (string, error) {
+	if toolConfig, err := tee.configLoader.LoadToolConfig(toolName); err == nil && toolConfig.Builtin {
+		return os.Executable()
+	}
+
 	var candidates []string
 	
 	// If toolsPath is set, try tools directory first (security priority)
@@ -1099,6 +1459,45 @@ func (tee *ToolExecutionEngine) GetToolConfig(toolName string) (*ToolConfig, err
 	return tee.configLoader.LoadToolConfig(toolName)
 }
 
+// ToolVersion runs the tool with its version_args and returns the first line it prints
+func (tee *ToolExecutionEngine) ToolVersion(ctx context.Context, toolName string) (string, error) {
+	toolConfig, err := tee.configLoader.LoadToolConfig(toolName)
+	if err != nil {
+		return "", err
+	}
+	out, err := tee.versionOutput(ctx, toolName, toolConfig)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("%s printed no version", toolName)
+}
+
+// versionOutput runs the tool with its version_args and returns everything it printed
+func (tee *ToolExecutionEngine) versionOutput(ctx context.Context, toolName string, toolConfig *ToolConfig) (string, error) {
+	if len(toolConfig.VersionArgs) == 0 {
+		return "", fmt.Errorf("tool '%s' declares no version_args", toolName)
+	}
+	toolExecutable, err := tee.findToolExecutable(toolName)
+	if err != nil {
+		return "", err
+	}
+
+	// Some tools print their version on stderr or exit non-zero; use whatever they printed
+	versionCmd := exec.CommandContext(ctx, toolExecutable, toolConfig.VersionArgs...)
+	versionCmd.WaitDelay = processWaitDelay
+	setProcessGroup(versionCmd)
+	out, err := versionCmd.CombinedOutput()
+	if err != nil && strings.TrimSpace(string(out)) == "" {
+		return "", fmt.Errorf("%s %s failed: %v", toolName, strings.Join(toolConfig.VersionArgs, " "), err)
+	}
+	return string(out), nil
+}
+
 // ValidateToolConfiguration validates that a tool is properly configured and executable
 func (tee *ToolExecutionEngine) ValidateToolConfiguration(toolName string) error {
 	// Load tool config
@@ -1128,30 +1527,10 @@ func (tee *ToolExecutionEngine) PreviewCommand(toolName, mode, target string) ([
 
 // PreviewCommandWithContext generates the command with workflow context
 func (tee *ToolExecutionEngine) PreviewCommandWithContext(toolName, mode, target, workflowName, stepName string) ([]string, error) {
-	// Load tool configuration
-	toolConfig, err := tee.configLoader.LoadToolConfig(toolName)
+	// Resolve the arguments as a run would (see dry_run.go)
+	resolvedArgs, _, err := tee.previewArguments(toolName, mode, target, workflowName, stepName, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load tool config: %w", err)
-	}
-
-	// Get tool arguments for the specified mode
-	argsTemplate, err := toolConfig.GetToolArguments(mode)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tool arguments: %w", err)
-	}
-
-	// Create execution context
-	execCtx := tee.templateResolver.CreateExecutionContextWithWorkflow(target, toolName, mode, workflowName, stepName)
-
-	// Set custom output file if tool config specifies one
-	if toolConfig.File != "" {
-		execCtx.OutputFile = toolConfig.File
-	}
-
-	// Resolve template variables in arguments
-	resolvedArgs, err := tee.templateResolver.ResolveArguments(argsTemplate, execCtx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve template variables: %w", err)
+		return nil, err
 	}
 
 	// Find tool executable
@@ -1163,34 +1542,13 @@ func (tee *ToolExecutionEngine) PreviewCommandWithContext(toolName, mode, target
 	return append([]string{toolExecutable}, resolvedArgs...), nil
 }
 
-// GetExecutionStatus returns information about current executions
-func (tee *ToolExecutionEngine) GetExecutionStatus() map[string]interface{} {
-	// Get dynamic concurrency status
-	dynamicStatus := tee.concurrencyManager.GetStatus()
-	
-	// Add legacy status for compatibility
-	tee.runningMutex.RLock()
-	defer tee.runningMutex.RUnlock()
-	
-	legacyStatus := map[string]interface{}{
-		"concurrent_slots_available": cap(tee.concurrentSem) - len(tee.concurrentSem),
-		"concurrent_slots_total":     cap(tee.concurrentSem),
-		"parallel_slots_available":   cap(tee.parallelSem) - len(tee.parallelSem),
-		"parallel_slots_total":       cap(tee.parallelSem),
-		"running_tools_legacy":       make(map[string]int),
+// GetExecutionStatus returns the current tool execution state from the concurrency manager
+func (tee *ToolExecutionEngine) GetExecutionStatus() ExecutionStatus {
+	status := tee.concurrencyManager.GetStatus()
+	if monitor := tee.resourceMonitor.Load(); monitor != nil {
+		reservations := monitor.Reservations()
+		status.Reservations = &reservations
 	}
-	
-	// Copy legacy running tools map
-	runningTools := make(map[string]int)
-	for tool, count := range tee.runningTools {
-		runningTools[tool] = count
-	}
-	legacyStatus["running_tools_legacy"] = runningTools
-	
-	// Merge dynamic and legacy status
-	status := dynamicStatus
-	status["legacy"] = legacyStatus
-	
 	return status
 }
 
@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// EventType identifies an execution lifecycle event
+type EventType string
+
+// Execution lifecycle events, roughly in the order a workflow produces them
+const (
+	EventWorkflowQueued     EventType = "workflow_queued"
+	EventWorkflowTriggered  EventType = "workflow_triggered" // Queued by another workflow's finding
+	EventWorkflowStarted    EventType = "workflow_started"
+	EventStepStarted        EventType = "step_started"
+	EventToolOutputLine     EventType = "tool_output_line"
+	EventStepCompleted      EventType = "step_completed"
+	EventStepSkipped        EventType = "step_skipped"
+	EventStepFailed         EventType = "step_failed"
+	EventWorkflowProgress   EventType = "workflow_progress"   // Estimated time left, when past durations allow
+	EventFindingsDiscovered EventType = "findings_discovered" // Open ports and URLs new to the run
+	EventWorkflowCompleted  EventType = "workflow_completed"
+	EventWorkflowFailed     EventType = "workflow_failed"
+	EventWorkflowCancelled  EventType = "workflow_cancelled"
+)
+
+// Event is one execution lifecycle event; fields that do not apply to its type are empty
+type Event struct {
+	Type        EventType          `json:"type"`
+	Time        time.Time          `json:"time"`
+	Workflow    string             `json:"workflow,omitempty"`
+	Target      string             `json:"target,omitempty"`
+	Step        string             `json:"step,omitempty"`
+	StepIndex   int                `json:"step_index,omitempty"` // 1-based position of the step in its workflow
+	StepCount   int                `json:"step_count,omitempty"`
+	Tool        string             `json:"tool,omitempty"`
+	Mode        string             `json:"mode,omitempty"`
+	Stream      string             `json:"stream,omitempty"` // "stdout" or "stderr" of a tool output line
+	Line        string             `json:"line,omitempty"`
+	Message     string             `json:"message,omitempty"` // Human-readable description
+	Error       string             `json:"error,omitempty"`
+	TriggeredBy string             `json:"triggered_by,omitempty"`
+	Percent     int                `json:"percent,omitempty"`           // Share of the workflow's estimated duration spent
+	Remaining   int                `json:"remaining_seconds,omitempty"` // Estimated seconds until the workflow ends
+	Findings    []findings.Finding `json:"findings,omitempty"`
+}
+
+// Status returns the short status name logs and progress displays show: the step events keep
+// their type, workflow events drop the "workflow_" prefix (e.g. "completed", "triggered")
+func (e Event) Status() string {
+	switch e.Type {
+	case EventWorkflowQueued:
+		return "queued"
+	case EventWorkflowTriggered:
+		return "triggered"
+	case EventWorkflowStarted:
+		return "started"
+	case EventWorkflowProgress:
+		return "progress"
+	case EventWorkflowCompleted:
+		return "completed"
+	case EventWorkflowFailed:
+		return "failed"
+	case EventWorkflowCancelled:
+		return "cancelled"
+	default:
+		return string(e.Type)
+	}
+}
+
+// EventHandler receives events
+type EventHandler func(event Event)
+
+// EventBus delivers execution events to every subscriber (console log, API progress,
+// notifications, ...). Handlers run on the publishing goroutine, and workflows, steps and tools
+// publish concurrently: handlers must be safe for concurrent use and quick, handing slow work
+// such as network calls to their own goroutine. A slow tool output handler blocks the tool
+type EventBus struct {
+	mutex         sync.RWMutex
+	nextID        int
+	subscriptions []eventSubscription
+}
+
+type eventSubscription struct {
+	id      int
+	handler EventHandler
+	types   map[EventType]bool // nil = every type but EventToolOutputLine
+}
+
+// NewEventBus creates a bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers handler for the given event types, or for all of them when none are
+// given, and returns the function that removes it again. EventToolOutputLine, one event per
+// line of every tool, is only delivered to handlers that name it
+func (b *EventBus) Subscribe(handler EventHandler, types ...EventType) (unsubscribe func()) {
+	subscription := eventSubscription{handler: handler}
+	if len(types) > 0 {
+		subscription.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			subscription.types[t] = true
+		}
+	}
+	return b.subscribe(subscription)
+}
+
+// Publish stamps the event's time if unset and delivers it to the matching subscribers in the
+// order they subscribed; call it outside the orchestrator lock
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, subscription := range b.current() {
+		if subscription.wants(event.Type) {
+			subscription.handler(event)
+		}
+	}
+}
+
+// Wants reports whether any subscriber receives an event type, so publishers can skip
+// building events nobody reads
+func (b *EventBus) Wants(t EventType) bool {
+	if b == nil {
+		return false
+	}
+	for _, subscription := range b.current() {
+		if subscription.wants(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s eventSubscription) wants(t EventType) bool {
+	if s.types == nil {
+		return t != EventToolOutputLine
+	}
+	return s.types[t]
+}
+
+// subscribe adds a subscription and returns the function that removes it again. Subscribing
+// and unsubscribing replace the slice, so Publish only holds the lock long enough to take the
+// current one and handlers may unsubscribe themselves
+func (b *EventBus) subscribe(subscription eventSubscription) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.nextID++
+	subscription.id = b.nextID
+	list := make([]eventSubscription, len(b.subscriptions), len(b.subscriptions)+1)
+	copy(list, b.subscriptions)
+	b.subscriptions = append(list, subscription)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.unsubscribe(subscription.id) })
+	}
+}
+
+func (b *EventBus) unsubscribe(id int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	list := make([]eventSubscription, 0, len(b.subscriptions))
+	for _, subscription := range b.subscriptions {
+		if subscription.id != id {
+			list = append(list, subscription)
+		}
+	}
+	b.subscriptions = list
+}
+
+// current returns the subscriptions at the time of the call
+func (b *EventBus) current() []eventSubscription {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.subscriptions
+}
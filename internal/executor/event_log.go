@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// EventLogFileName is the workspace file the lifecycle events of a run are appended to
+const EventLogFileName = "logs/events.jsonl"
+
+// EventLog writes events as JSON lines, so a run can be replayed or inspected after it ends
+type EventLog struct {
+	mutex    sync.Mutex
+	file     *os.File
+	encoder  *json.Encoder
+	redactor *output.Redactor // Applied to every recorded event (nil = none)
+}
+
+// OpenEventLog appends to the event log at path, creating it when needed
+func OpenEventLog(path string, perm os.FileMode) (*EventLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLog{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// SetRedactor redacts the events before they are written
+func (l *EventLog) SetRedactor(redactor *output.Redactor) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.redactor = redactor
+}
+
+// Record writes one event; it is an EventHandler
+func (l *EventLog) Record(event Event) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.redactor.Enabled() {
+		event = l.redactor.RedactCopy(event).(Event) // A copy: other subscribers share the event's slices
+	}
+	if l.file != nil {
+		l.encoder.Encode(event)
+	}
+}
+
+// Close stops recording and closes the file
+func (l *EventLog) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,47 @@
+package executor
+
+// ExecutionStatus is the engine's tool execution state: slot usage per performance profile,
+// the tools waiting for a slot, the running tools and run totals. It is the one schema for
+// execution state; state snapshots and the API's scan status both report it
+type ExecutionStatus struct {
+	Slots       map[string]SlotStatus `json:"slots"` // Keyed by profile: "fast", "medium", "heavy"
+	Queue       QueueStatus           `json:"queue"`
+	ActiveTools map[string]int        `json:"active_tools"` // Tool name -> running instances
+	Metrics     ExecutionMetrics      `json:"metrics"`
+
+	// Resources held by running workflows; absent before workflows are orchestrated
+	Reservations *ReservationStatus `json:"reservations,omitempty"`
+}
+
+// SlotStatus is the usage of one profile's execution slots
+type SlotStatus struct {
+	Active    int     `json:"active"`
+	Available int     `json:"available"`
+	Total     int     `json:"total"`
+	Usage     float64 `json:"usage"` // Active / Total
+}
+
+// QueueStatus lists the tools waiting for a slot, in queue order
+type QueueStatus struct {
+	Size  int      `json:"size"`
+	Tools []string `json:"tools"`
+}
+
+// ExecutionMetrics are totals since the engine was created
+type ExecutionMetrics struct {
+	TotalExecuted    int            `json:"total_executed"`
+	QueuedExecutions int            `json:"queued_executions"` // Executions that had to wait for a slot
+	PeakConcurrency  map[string]int `json:"peak_concurrency"`  // Most tools of each profile running at once
+}
+
+// String returns the profile's slot name
+func (p ToolPerformanceProfile) String() string {
+	switch p {
+	case FastTool:
+		return "fast"
+	case HeavyTool:
+		return "heavy"
+	default:
+		return "medium"
+	}
+}
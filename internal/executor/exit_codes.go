@@ -0,0 +1,92 @@
+package executor
+
+import "fmt"
+
+// DefaultExitCodesMode is the exit_codes key applied to modes without their own entry
+const DefaultExitCodesMode = "default"
+
+// Classes an exit code can map to
+const (
+	ExitClassSuccess = "success" // The run worked
+	ExitClassWarning = "warning" // The run worked but the exit code is reported, e.g. "no results"
+	ExitClassFailure = "failure" // The run failed and may be retried
+)
+
+// ExitCodeMap classifies a tool mode's exit codes, for tools that exit non-zero on benign
+// outcomes such as finding nothing. Codes not listed keep their usual meaning: 0 succeeds and
+// anything else fails
+type ExitCodeMap struct {
+	Success []int `yaml:"success"` // Codes that mean the run worked
+	Warning []int `yaml:"warning"` // Codes that count as success but are reported as warnings
+	Failure []int `yaml:"failure"` // Codes that fail the run, including 0 for tools that exit 0 on errors
+}
+
+// ExitCodesFor returns the exit code map for a mode, falling back to the "default" entry
+func (tc *ToolConfig) ExitCodesFor(mode string) (ExitCodeMap, bool) {
+	if codes, exists := tc.ExitCodes[mode]; exists {
+		return codes, true
+	}
+	codes, exists := tc.ExitCodes[DefaultExitCodesMode]
+	return codes, exists
+}
+
+// Classify returns the class of an exit code
+func (m ExitCodeMap) Classify(code int) string {
+	switch {
+	case containsCode(m.Success, code):
+		return ExitClassSuccess
+	case containsCode(m.Warning, code):
+		return ExitClassWarning
+	case containsCode(m.Failure, code):
+		return ExitClassFailure
+	case code == 0:
+		return ExitClassSuccess
+	}
+	return ExitClassFailure
+}
+
+// declaredFailureError fails a run whose exit code the tool config declares a failure even
+// though the process itself succeeded
+type declaredFailureError struct {
+	code int
+}
+
+func (e *declaredFailureError) Error() string {
+	return fmt.Sprintf("exit code %d is declared a failure in exit_codes", e.code)
+}
+
+// validateExitCodes checks that exit_codes only names declared modes and lists each code in one class
+func (tc *ToolConfig) validateExitCodes() error {
+	for mode, codes := range tc.ExitCodes {
+		if _, exists := tc.Args[mode]; !exists && mode != DefaultExitCodesMode {
+			return fmt.Errorf("exit_codes: unknown mode '%s'", mode)
+		}
+		classes := make(map[int]string)
+		lists := []struct {
+			class string
+			codes []int
+		}{{ExitClassSuccess, codes.Success}, {ExitClassWarning, codes.Warning}, {ExitClassFailure, codes.Failure}}
+		for _, list := range lists {
+			class := list.class
+			for _, code := range list.codes {
+				if code < 0 || code > 255 {
+					return fmt.Errorf("exit_codes.%s.%s: %d is not an exit code (0-255)", mode, class, code)
+				}
+				if other, listed := classes[code]; listed && other != class {
+					return fmt.Errorf("exit_codes.%s: %d is listed as both %s and %s", mode, code, other, class)
+				}
+				classes[code] = class
+			}
+		}
+	}
+	return nil
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, candidate := range codes {
+		if candidate == code {
+			return true
+		}
+	}
+	return false
+}
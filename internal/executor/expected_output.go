@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultExpectedOutputMode is the expected_outputs key applied to modes without their own entry
+const DefaultExpectedOutputMode = "default"
+
+// ExpectedOutput declares the artifact a tool mode must produce for its run to count as valid
+type ExpectedOutput struct {
+	Extension   string   `yaml:"extension"`    // Appended to output_path (e.g. ".xml"); empty means output_path itself
+	MinSize     int64    `yaml:"min_size"`     // Minimum artifact size in bytes
+	MustContain []string `yaml:"must_contain"` // Markers that must all appear in the artifact
+}
+
+// ExpectedOutputFor returns the declaration for a mode, falling back to the "default" entry
+func (tc *ToolConfig) ExpectedOutputFor(mode string) (ExpectedOutput, bool) {
+	if expected, exists := tc.ExpectedOutputs[mode]; exists {
+		return expected, true
+	}
+	expected, exists := tc.ExpectedOutputs[DefaultExpectedOutputMode]
+	return expected, exists
+}
+
+// Path returns the artifact path for a run's output_path
+func (eo ExpectedOutput) Path(outputPath string) string {
+	return outputPath + eo.Extension
+}
+
+// Check verifies the artifact for outputPath. When the artifact is output_path itself and
+// the file does not exist yet, captured stdout is checked instead, since the engine saves
+// stdout there after the run
+func (eo ExpectedOutput) Check(outputPath string, stdout []byte) error {
+	path := eo.Path(outputPath)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && eo.Extension == "" && len(stdout) > 0 {
+		data, err = stdout, nil
+		path = "captured stdout"
+	}
+	if os.IsNotExist(err) {
+		return fmt.Errorf("tool produced no output: %s was not created", path)
+	}
+	if err != nil {
+		return fmt.Errorf("tool output unreadable: %w", err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 && (eo.MinSize > 0 || len(eo.MustContain) > 0) {
+		return fmt.Errorf("tool produced empty output: %s", path)
+	}
+	if int64(len(data)) < eo.MinSize {
+		return fmt.Errorf("tool produced too little output: %s is %d bytes, expected at least %d", path, len(data), eo.MinSize)
+	}
+	for _, marker := range eo.MustContain {
+		if !bytes.Contains(data, []byte(marker)) {
+			return fmt.Errorf("tool produced invalid output: %s does not contain %q", path, marker)
+		}
+	}
+	return nil
+}
+
+// validateExpectedOutputs checks that expected_outputs only names declared modes
+func (tc *ToolConfig) validateExpectedOutputs() error {
+	for mode, expected := range tc.ExpectedOutputs {
+		if _, exists := tc.Args[mode]; !exists && mode != DefaultExpectedOutputMode {
+			return fmt.Errorf("expected_outputs: unknown mode '%s'", mode)
+		}
+		if expected.MinSize < 0 {
+			return fmt.Errorf("expected_outputs.%s: min_size must not be negative", mode)
+		}
+		if strings.ContainsAny(expected.Extension, `/\`) {
+			return fmt.Errorf("expected_outputs.%s: extension must not contain a path separator", mode)
+		}
+	}
+	return nil
+}
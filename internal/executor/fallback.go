@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// permissionFailureMarkers are error and stderr fragments showing a mode lacked the privileges it needs
+var permissionFailureMarkers = []string{
+	"requires root",
+	"root privileges",
+	"must be root",
+	"are you root",
+	"permission denied",
+	"operation not permitted",
+	"insufficient privileges",
+	"cap_net_raw",
+}
+
+// isPermissionFailure reports whether a failed execution failed for lack of privileges. A privileged
+// mode that fails while not running as root is treated as a permission failure as well
+func isPermissionFailure(result *ExecutionResult, err error, privilegedMode bool) bool {
+	if err == nil && (result == nil || result.Success) {
+		return false
+	}
+	if privilegedMode && os.Geteuid() != 0 {
+		return true
+	}
+
+	var text strings.Builder
+	if err != nil {
+		text.WriteString(err.Error())
+	}
+	if result != nil {
+		text.WriteString("\n" + result.ErrorMessage + "\n" + result.Stderr)
+	}
+	lowered := strings.ToLower(text.String())
+	for _, marker := range permissionFailureMarkers {
+		if strings.Contains(lowered, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// unprivilegedReason is recorded when a privileged mode is swapped for its unprivileged alternative
+const unprivilegedReason = "mode requires root; ran unprivileged alternative"
+
+// executeModeWithFallback runs one mode of a step. Without root, a privileged mode is replaced
+// up front by the tool's unprivileged_modes alternative; a mode that still fails for lack of
+// privileges falls back to the step's fallback modes in order. A substituted result records
+// the mode it replaced and why
+func (we *WorkflowExecutor) executeModeWithFallback(ctx context.Context, step *WorkflowStep, mode, target, workflowName string, options *ExecutionOptions) (*ExecutionResult, error) {
+	toolConfig, configErr := we.engine.GetToolConfig(step.Tool)
+	privileged := func(m string) bool { return configErr == nil && toolConfig.RequiresPrivileges(m) }
+
+	runMode := mode
+	if privileged(mode) && os.Geteuid() != 0 {
+		if alternative, exists := toolConfig.UnprivilegedAlternative(mode); exists {
+			we.engine.debugLogger.Info("Not running as root, selecting unprivileged mode",
+				"tool", step.Tool, "mode", mode, "selected", alternative)
+			runMode = alternative
+		}
+	}
+
+	result, err := we.engine.ExecuteToolWithContext(ctx, step.Tool, runMode, target, workflowName, step.Name, options)
+	if runMode != mode && result != nil {
+		result.FallbackFor = mode
+		result.FallbackReason = unprivilegedReason
+		we.engine.warn(output.WarningModeDowngraded, step.Tool, mode, target, "ran %s instead: %s", runMode, unprivilegedReason)
+	}
+	if len(step.FallbackModes) == 0 || ctx.Err() != nil {
+		return result, err
+	}
+	if !isPermissionFailure(result, err, privileged(runMode)) {
+		return result, err
+	}
+
+	reason := ""
+	if result != nil {
+		reason = result.ErrorMessage
+	}
+	if reason == "" && err != nil {
+		reason = err.Error()
+	}
+
+	for _, fallback := range step.FallbackModes {
+		if fallback == mode || fallback == runMode {
+			continue
+		}
+		we.engine.debugLogger.Info("Mode failed for lack of privileges, trying fallback",
+			"tool", step.Tool, "mode", runMode, "fallback", fallback, "reason", reason)
+
+		fallbackResult, fallbackErr := we.engine.ExecuteToolWithContext(ctx, step.Tool, fallback, target, workflowName, step.Name, options)
+		if fallbackResult != nil {
+			fallbackResult.FallbackFor = mode
+			fallbackResult.FallbackReason = reason
+			we.engine.warn(output.WarningModeDowngraded, step.Tool, mode, target, "fell back to %s after a permission failure: %s", fallback, reason)
+		}
+		result, err = fallbackResult, fallbackErr
+
+		// Stop at the first fallback that succeeds or fails for another reason
+		if ctx.Err() != nil || !isPermissionFailure(result, err, privileged(fallback)) {
+			break
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/scope"
+)
+
+// HostInventoryFileName is the live-host inventory written to a CIDR target's workspace
+const HostInventoryFileName = "hosts.json"
+
+// HostInventory records the hosts a discovery sweep found in a CIDR target and where each was scanned
+type HostInventory struct {
+	Target       string          `json:"target"`
+	ScanID       string          `json:"scan_id"`
+	Tool         string          `json:"tool"`
+	Mode         string          `json:"mode"`
+	DiscoveredAt time.Time       `json:"discovered_at"`
+	OutputPath   string          `json:"output_path,omitempty"`
+	Hosts        []InventoryHost `json:"hosts"`
+	Excluded     []string        `json:"excluded,omitempty"` // Live hosts skipped because they are out of scope
+}
+
+// InventoryHost is one live host and the outcome of its scan
+type InventoryHost struct {
+	Address   string `json:"address"`
+	Workspace string `json:"workspace,omitempty"`
+	Status    string `json:"status,omitempty"` // "pending" until scanned, then the run's status
+}
+
+// DiscoverHosts sweeps a CIDR target with the configured tool and returns its live hosts,
+// read from the tool parser's hosts variable. Excluded hosts are listed separately
+func (tee *ToolExecutionEngine) DiscoverHosts(ctx context.Context, cfg config.HostDiscoveryConfig, target string) (*HostInventory, error) {
+	toolConfig, err := tee.GetToolConfig(cfg.Tool)
+	if err != nil {
+		return nil, fmt.Errorf("host discovery tool: %v", err)
+	}
+	if _, exists := toolConfig.Args[cfg.Mode]; !exists {
+		return nil, fmt.Errorf("host discovery: tool %s has no mode '%s'", cfg.Tool, cfg.Mode)
+	}
+	parseOutput, exists := tee.magicVarManager.parserFor(cfg.Tool)
+	if !exists {
+		return nil, fmt.Errorf("host discovery: tool %s has no output parser to read live hosts from", cfg.Tool)
+	}
+
+	options := &ExecutionOptions{
+		CaptureOutput: true,
+		Timeout:       time.Duration(tee.globalConfig.Tools.DefaultTimeout) * time.Second,
+	}
+	result, err := tee.ExecuteToolWithContext(ctx, cfg.Tool, cfg.Mode, target, "Host Discovery", "Sweep", options)
+	if err != nil {
+		return nil, fmt.Errorf("host discovery sweep failed: %v", err)
+	}
+
+	outputPath := result.OutputPath
+	if expected, declared := toolConfig.ExpectedOutputFor(cfg.Mode); declared {
+		outputPath = expected.Path(result.OutputPath)
+	}
+	vars := parseOutput(outputPath)
+	if vars == nil {
+		return nil, fmt.Errorf("host discovery: could not parse %s", outputPath)
+	}
+
+	inventory := &HostInventory{
+		Target:       target,
+		ScanID:       tee.scanID,
+		Tool:         cfg.Tool,
+		Mode:         cfg.Mode,
+		DiscoveredAt: time.Now().Round(0),
+		OutputPath:   outputPath,
+		Hosts:        []InventoryHost{},
+	}
+	for _, host := range sortHosts(strings.Split(vars[cfg.HostsVariable], ",")) {
+		if tee.refuseOutOfScope(host, scope.AuditSourceHostDiscovery, "Host Discovery", cfg.Tool) != nil || tee.exclusions.Excludes(host) {
+			inventory.Excluded = append(inventory.Excluded, host)
+			continue
+		}
+		inventory.Hosts = append(inventory.Hosts, InventoryHost{Address: host, Status: "pending"})
+	}
+	return inventory, nil
+}
+
+// SetHostResult records where a host was scanned and how the scan ended
+func (inv *HostInventory) SetHostResult(address, workspace, status string) {
+	for i := range inv.Hosts {
+		if inv.Hosts[i].Address == address {
+			if workspace != "" {
+				inv.Hosts[i].Workspace = workspace
+			}
+			inv.Hosts[i].Status = status
+			return
+		}
+	}
+}
+
+// Addresses returns the live hosts to scan
+func (inv *HostInventory) Addresses() []string {
+	addresses := make([]string, len(inv.Hosts))
+	for i, host := range inv.Hosts {
+		addresses[i] = host.Address
+	}
+	return addresses
+}
+
+// WriteHostInventory writes hosts.json to the workspace atomically
+func WriteHostInventory(workspaceDir string, inventory *HostInventory, perm os.FileMode) error {
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode host inventory: %v", err)
+	}
+	path := filepath.Join(workspaceDir, HostInventoryFileName)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, perm); err != nil {
+		return fmt.Errorf("failed to write host inventory: %v", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// sortHosts drops empty and duplicate entries and orders IPs numerically, then hostnames
+func sortHosts(hosts []string) []string {
+	seen := make(map[string]bool)
+	var sorted []string
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		if host != "" && !seen[host] {
+			seen[host] = true
+			sorted = append(sorted, host)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := net.ParseIP(sorted[i]), net.ParseIP(sorted[j])
+		switch {
+		case a != nil && b != nil:
+			return bytes.Compare(a.To16(), b.To16()) < 0
+		case a != nil || b != nil:
+			return a != nil
+		default:
+			return sorted[i] < sorted[j]
+		}
+	})
+	return sorted
+}
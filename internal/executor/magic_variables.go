@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/neur0map/ipcrawler/internal/parsers"
 	"github.com/neur0map/ipcrawler/internal/registry"
 )
 
@@ -20,6 +21,9 @@ type ToolOutputParser interface {
 type MagicVariableManager struct {
 	parsers         map[string]ToolOutputParser
 	registryManager registry.RegistryManager // Optional registry for auto-detection
+	
+	// Returns the parser a tool's config declares ("" = use the tool's registered parser)
+	declaredParser func(toolName string) string
 }
 
 // NewMagicVariableManager creates a new magic variable manager
@@ -40,14 +44,43 @@ func (mvm *MagicVariableManager) RegisterParser(parser ToolOutputParser) {
 	mvm.parsers[toolName] = parser
 }
 
+// SetDeclaredParserLookup sets how the parser declared in a tool's config is found
+func (mvm *MagicVariableManager) SetDeclaredParserLookup(lookup func(toolName string) string) {
+	mvm.declaredParser = lookup
+}
+
+// parserFor returns the parse function for a tool: the parser its config declares,
+// falling back to the tool-specific parser registered in code
+func (mvm *MagicVariableManager) parserFor(toolName string) (func(outputPath string) map[string]string, bool) {
+	if mvm.declaredParser != nil {
+		if name := mvm.declaredParser(toolName); name != "" {
+			if parser, exists := parsers.Lookup(name); exists {
+				return func(outputPath string) map[string]string {
+					vars, err := parser.Parse(outputPath)
+					if err != nil {
+						return nil // Unreadable output = no magic variables from this file
+					}
+					return vars
+				}, true
+			}
+		}
+	}
+
+	parser, exists := mvm.parsers[toolName]
+	if !exists {
+		return nil, false
+	}
+	return parser.ParseOutput, true
+}
+
 // ProcessToolOutput processes the output files from a completed tool
 // and creates magic variables. This is completely generic.
 func (mvm *MagicVariableManager) ProcessToolOutput(toolName string, outputFiles []string) map[string]string {
 	toolName = strings.ToLower(toolName)
 	
-	parser, exists := mvm.parsers[toolName]
+	parseOutput, exists := mvm.parserFor(toolName)
 	if !exists {
-		// No parser registered = no magic variables created
+		// No parser registered or declared = no magic variables created
 		return make(map[string]string)
 	}
 
@@ -60,33 +93,59 @@ func (mvm *MagicVariableManager) ProcessToolOutput(toolName string, outputFiles
 		}
 
 		// Let the tool-specific parser extract data
-		toolVars := parser.ParseOutput(outputFile)
+		mvm.publish(toolName, outputFile, parseOutput(outputFile), magicVariables)
+	}
 
-		// Create magic variables with tool prefix
-		for key, value := range toolVars {
-			magicVarName := fmt.Sprintf("%s_%s", toolName, key)
-			magicVariables[magicVarName] = value
-			
-			// Auto-register with registry if available
-			if mvm.registryManager != nil {
-				context := registry.DetectionContext{
-					FilePath:   outputFile,
-					LineNumber: 0,
-					Context:    fmt.Sprintf("Magic variable from %s parser: %s", toolName, key),
-					Source:     registry.ToolParserSource,
-					Tool:       toolName,
-					Timestamp:  time.Now(),
-				}
-				
-				// Register the variable (ignore errors to avoid disrupting execution)
-				mvm.registryManager.AutoRegisterVariable(fmt.Sprintf("{{%s}}", magicVarName), context)
-			}
-		}
+	return magicVariables
+}
+
+// StreamFor returns a line stream for the parser a tool's config declares, or nil if that
+// parser can only read finished files; the engine feeds it stdout while the tool runs
+func (mvm *MagicVariableManager) StreamFor(toolName string) parsers.LineStream {
+	if mvm.declaredParser == nil {
+		return nil
 	}
+	parser, exists := parsers.Lookup(mvm.declaredParser(strings.ToLower(toolName)))
+	if !exists {
+		return nil
+	}
+	if lineParser, ok := parser.(parsers.LineParser); ok {
+		return lineParser.NewStream()
+	}
+	return nil
+}
 
+// ProcessStreamedOutput creates magic variables from a stream fed while the tool ran, in place
+// of parsing its output file again
+func (mvm *MagicVariableManager) ProcessStreamedOutput(toolName, outputFile string, stream parsers.LineStream) map[string]string {
+	magicVariables := make(map[string]string)
+	mvm.publish(strings.ToLower(toolName), outputFile, stream.Variables(), magicVariables)
 	return magicVariables
 }
 
+// publish prefixes parsed values with the tool name and registers them with the registry
+func (mvm *MagicVariableManager) publish(toolName, outputFile string, toolVars, magicVariables map[string]string) {
+	for key, value := range toolVars {
+		magicVarName := fmt.Sprintf("%s_%s", toolName, key)
+		magicVariables[magicVarName] = value
+		
+		// Auto-register with registry if available
+		if mvm.registryManager != nil {
+			context := registry.DetectionContext{
+				FilePath:   outputFile,
+				LineNumber: 0,
+				Context:    fmt.Sprintf("Magic variable from %s parser: %s", toolName, key),
+				Source:     registry.ToolParserSource,
+				Tool:       toolName,
+				Timestamp:  time.Now(),
+			}
+			
+			// Register the variable (ignore errors to avoid disrupting execution)
+			mvm.registryManager.AutoRegisterVariable(fmt.Sprintf("{{%s}}", magicVarName), context)
+		}
+	}
+}
+
 // GetAvailableParsers returns a list of tools that have output parsers registered
 func (mvm *MagicVariableManager) GetAvailableParsers() []string {
 	var tools []string
@@ -96,8 +155,8 @@ func (mvm *MagicVariableManager) GetAvailableParsers() []string {
 	return tools
 }
 
-// HasParser checks if a parser is registered for the given tool
+// HasParser checks if a parser is registered or declared for the given tool
 func (mvm *MagicVariableManager) HasParser(toolName string) bool {
-	_, exists := mvm.parsers[strings.ToLower(toolName)]
+	_, exists := mvm.parserFor(strings.ToLower(toolName))
 	return exists
 }
\ No newline at end of file
@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// matrixReferencePattern matches {{matrix.<key>}} placeholders in step fields
+var matrixReferencePattern = regexp.MustCompile(`\{\{\s*matrix\.([A-Za-z0-9_]+)\s*\}\}`)
+
+// ExpandMatrix replaces every step that references {{matrix.<key>}} with one instance per
+// combination of the keys it references. Instances are named "<step> [key=value, ...]",
+// carry their combination in WorkflowStep.Matrix and run alongside the other steps under
+// the concurrency manager. Steps without matrix references are left untouched.
+func ExpandMatrix(workflow *Workflow) error {
+	if len(workflow.Matrix) == 0 {
+		for _, step := range workflow.Steps {
+			if keys := matrixKeys(step); len(keys) > 0 {
+				return fmt.Errorf("step '%s' references matrix.%s but the workflow declares no matrix", step.Name, keys[0])
+			}
+		}
+		return nil
+	}
+	for key, values := range workflow.Matrix {
+		if len(values) == 0 {
+			return fmt.Errorf("matrix key '%s' has no values", key)
+		}
+	}
+
+	// Original step name -> expanded instances, used to rewrite depends_on
+	instances := make(map[string][]*WorkflowStep)
+	var expanded []*WorkflowStep
+	for _, step := range workflow.Steps {
+		keys := matrixKeys(step)
+		for _, key := range keys {
+			if _, exists := workflow.Matrix[key]; !exists {
+				return fmt.Errorf("step '%s' references undeclared matrix key '%s'", step.Name, key)
+			}
+		}
+		if len(keys) == 0 {
+			expanded = append(expanded, step)
+			continue
+		}
+		for _, combination := range matrixCombinations(keys, workflow.Matrix) {
+			instance := expandStep(step, keys, combination)
+			instances[step.Name] = append(instances[step.Name], instance)
+			expanded = append(expanded, instance)
+		}
+	}
+
+	// A dependency on an expanded step resolves to the instances with the same matrix values:
+	// the one instance when the step shares all of its keys, every instance when it shares none
+	for _, step := range expanded {
+		var resolved []string
+		for _, name := range step.DependsOn {
+			dependencies, isMatrixStep := instances[name]
+			if !isMatrixStep {
+				resolved = append(resolved, name)
+				continue
+			}
+			matched := 0
+			for _, dependency := range dependencies {
+				if matrixAgrees(dependency.Matrix, step.Matrix) {
+					resolved = append(resolved, dependency.Name)
+					matched++
+				}
+			}
+			if matched == 0 {
+				return fmt.Errorf("step '%s' depends on matrix step '%s' but none of its instances has the same matrix values", step.Name, name)
+			}
+		}
+		step.DependsOn = resolved
+	}
+
+	workflow.Steps = expanded
+	return nil
+}
+
+// matrixKeys returns the sorted matrix keys referenced anywhere in a step
+func matrixKeys(step *WorkflowStep) []string {
+	seen := make(map[string]bool)
+	collect := func(value string) {
+		for _, match := range matrixReferencePattern.FindAllStringSubmatch(value, -1) {
+			seen[match[1]] = true
+		}
+	}
+
+	collect(step.Tool)
+	collect(step.Description)
+	for _, dependency := range step.DependsOn {
+		collect(dependency)
+	}
+	collect(step.RunIf)
+	for _, mode := range append(append([]string{}, step.Modes...), step.FallbackModes...) {
+		collect(mode)
+	}
+	for _, fields := range []map[string]string{step.Variables, step.Parameters, step.Combiner} {
+		for _, value := range fields {
+			collect(value)
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matrixCombinations returns the cartesian product of the given keys' values in declaration order
+func matrixCombinations(keys []string, matrix map[string][]string) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range matrix[key] {
+				extended := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					extended[k] = v
+				}
+				extended[key] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// expandStep creates one step instance with all matrix placeholders substituted
+func expandStep(step *WorkflowStep, keys []string, combination map[string]string) *WorkflowStep {
+	substitute := func(value string) string {
+		return matrixReferencePattern.ReplaceAllStringFunc(value, func(match string) string {
+			return combination[matrixReferencePattern.FindStringSubmatch(match)[1]]
+		})
+	}
+	substituteMap := func(fields map[string]string) map[string]string {
+		if fields == nil {
+			return nil
+		}
+		result := make(map[string]string, len(fields))
+		for k, v := range fields {
+			result[k] = substitute(v)
+		}
+		return result
+	}
+
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		labels[i] = key + "=" + combination[key]
+	}
+
+	instance := *step
+	instance.Name = fmt.Sprintf("%s [%s]", step.Name, strings.Join(labels, ", "))
+	instance.Tool = substitute(step.Tool)
+	instance.Description = substitute(step.Description)
+	if step.DependsOn != nil {
+		instance.DependsOn = make([]string, len(step.DependsOn))
+		for i, dependency := range step.DependsOn {
+			instance.DependsOn[i] = substitute(dependency)
+		}
+	}
+	instance.RunIf = substitute(step.RunIf)
+	instance.Modes = make([]string, len(step.Modes))
+	for i, mode := range step.Modes {
+		instance.Modes[i] = substitute(mode)
+	}
+	if step.FallbackModes != nil {
+		instance.FallbackModes = make([]string, len(step.FallbackModes))
+		for i, mode := range step.FallbackModes {
+			instance.FallbackModes[i] = substitute(mode)
+		}
+	}
+	instance.Variables = substituteMap(step.Variables)
+	instance.Parameters = substituteMap(step.Parameters)
+	instance.Combiner = substituteMap(step.Combiner)
+	instance.Matrix = combination
+	return &instance
+}
+
+// matrixAgrees reports whether the keys a and b share have the same values
+func matrixAgrees(a, b map[string]string) bool {
+	for key, value := range a {
+		if other, shared := b[key]; shared && other != value {
+			return false
+		}
+	}
+	return true
+}
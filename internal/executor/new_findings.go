@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// seenFindings remembers the findings already reported to the run
+type seenFindings struct {
+	mutex sync.Mutex
+	keys  map[string]bool
+}
+
+// publishNewFindings reports the open ports and web URLs of a finished step that no earlier
+// step of the run had reported. Step outputs are only parsed for this while someone subscribes
+func (wo *WorkflowOrchestrator) publishNewFindings(source *WorkflowQueueItem, step *WorkflowStep, result *WorkflowResult) {
+	if result == nil || result.Skipped || !wo.events.Wants(EventFindingsDiscovered) {
+		return
+	}
+
+	var found []findings.Finding
+	wo.seenFindings.mutex.Lock()
+	if wo.seenFindings.keys == nil {
+		wo.seenFindings.keys = make(map[string]bool)
+	}
+	for _, f := range stepFindings(wo.executor.engine, wo.findingsCatalog(), result) {
+		if f.Host == "" {
+			f.Host = source.Target
+		}
+		var key string
+		switch {
+		case f.URL != "":
+			key = f.URL
+		case f.Port > 0 && (f.State == "" || f.State == "open"):
+			key = fmt.Sprintf("%s:%d/%s", f.Host, f.Port, f.Protocol)
+		default:
+			continue
+		}
+		if wo.seenFindings.keys[key] {
+			continue
+		}
+		wo.seenFindings.keys[key] = true
+		found = append(found, f)
+	}
+	wo.seenFindings.mutex.Unlock()
+
+	if len(found) == 0 {
+		return
+	}
+	wo.events.Publish(Event{
+		Type:     EventFindingsDiscovered,
+		Workflow: source.Workflow.Name,
+		Target:   source.Target,
+		Step:     step.Name,
+		Tool:     step.Tool,
+		Message:  fmt.Sprintf("%d new finding(s) from %s", len(found), step.Name),
+		Findings: found,
+	})
+}
+
+// findingsCatalog returns the extractors used to read step outputs, created on first use
+func (wo *WorkflowOrchestrator) findingsCatalog() *findings.Catalog {
+	wo.catalogOnce.Do(func() {
+		wo.catalog = findings.NewCatalog()
+		RegisterAllFindingsExtractors(wo.catalog)
+	})
+	return wo.catalog
+}
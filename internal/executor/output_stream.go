@@ -0,0 +1,274 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/neur0map/ipcrawler/internal/parsers"
+	"github.com/neur0map/ipcrawler/internal/secrets"
+)
+
+const (
+	// captureHeadBytes is how much of each stream is kept in memory, for error reports,
+	// ExecutionResult.Stdout/Stderr and timeout validation; the full stream is spooled to disk
+	captureHeadBytes = 64 * 1024
+
+	// captureReadBytes is the read buffer of each stream
+	captureReadBytes = 64 * 1024
+
+	// captureLineBytes splits longer lines so a runaway line cannot exhaust memory
+	captureLineBytes = 1024 * 1024
+)
+
+// outputCapture streams a tool's stdout and stderr through pipes while it runs. Each line
+// reaches the console (verbose mode), the interleaved raw log and the tool's line parser
+// as soon as it is produced. A slow consumer blocks the tool's writes instead of buffering
+type outputCapture struct {
+	stdout *captureStream
+	stderr *captureStream
+	wg     sync.WaitGroup
+}
+
+// captureStream is one of a tool's output streams
+type captureStream struct {
+	name    string
+	reader  *io.PipeReader
+	writer  *io.PipeWriter
+	spool   *os.File // Full stream on disk; nil when it is not needed
+	kept    bool     // The spool became the output file
+	head    bytes.Buffer
+	size    int64
+	content bool // Saw non-whitespace output
+	sinks   []func(line string)
+	err     error // First spool write error
+}
+
+// newOutputCapture prepares the capture of one execution. Stdout is spooled next to
+// outputPath so it can become the output file without a copy; stderr is only spooled when
+// the raw log is written in blocks after the run
+func (tee *ToolExecutionEngine) newOutputCapture(toolName, mode, outputPath string, interleaved *interleavedRawLog, stream parsers.LineStream) (*outputCapture, error) {
+	spoolDir, spoolPattern := os.TempDir(), "ipcrawler-stdout-*"
+	if outputPath != "" {
+		spoolDir, spoolPattern = filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".stdout-*"
+	}
+	capture := &outputCapture{stdout: newCaptureStream("stdout"), stderr: newCaptureStream("stderr")}
+
+	var err error
+	if capture.stdout.spool, err = os.CreateTemp(spoolDir, spoolPattern); err != nil {
+		return nil, fmt.Errorf("failed to create stdout spool: %w", err)
+	}
+	if interleaved == nil && tee.workspaceBase != "" {
+		if capture.stderr.spool, err = os.CreateTemp("", "ipcrawler-stderr-*"); err != nil {
+			capture.discard()
+			return nil, fmt.Errorf("failed to create stderr spool: %w", err)
+		}
+	}
+
+	if tee.outputController != nil && tee.outputController.ShouldShowRaw() {
+		capture.stdout.sinks = append(capture.stdout.sinks, func(line string) {
+			tee.outputController.PrintToolLine(toolName, mode, line, false)
+		})
+		capture.stderr.sinks = append(capture.stderr.sinks, func(line string) {
+			tee.outputController.PrintToolLine(toolName, mode, line, true)
+		})
+	}
+	if interleaved != nil {
+		for _, cs := range []*captureStream{capture.stdout, capture.stderr} {
+			name := cs.name
+			cs.sinks = append(cs.sinks, func(line string) { interleaved.Record(name, tee.redactor.Redact(line)) })
+		}
+	}
+	if stream != nil {
+		capture.stdout.sinks = append(capture.stdout.sinks, stream.Line)
+	}
+	return capture, nil
+}
+
+// publishOutputLines adds the event bus to the sinks of both streams when anyone subscribes
+// to tool output lines
+func (tee *ToolExecutionEngine) publishOutputLines(capture *outputCapture, execCtx *ExecutionContext) {
+	if !tee.events.Wants(EventToolOutputLine) {
+		return
+	}
+	for _, cs := range []*captureStream{capture.stdout, capture.stderr} {
+		name := cs.name
+		cs.sinks = append(cs.sinks, func(line string) {
+			tee.events.Publish(Event{
+				Type:     EventToolOutputLine,
+				Workflow: execCtx.WorkflowName,
+				Target:   execCtx.Target,
+				Step:     execCtx.StepName,
+				Tool:     execCtx.ToolName,
+				Mode:     execCtx.Mode,
+				Stream:   name,
+				Line:     tee.redactor.Redact(line),
+			})
+		})
+	}
+}
+
+func newCaptureStream(name string) *captureStream {
+	reader, writer := io.Pipe()
+	return &captureStream{name: name, reader: reader, writer: writer}
+}
+
+// attach connects the capture to a command and starts reading; call before Start
+func (c *outputCapture) attach(cmd *exec.Cmd) {
+	cmd.Stdout = c.stdout.writer
+	cmd.Stderr = c.stderr.writer
+	for _, cs := range []*captureStream{c.stdout, c.stderr} {
+		c.wg.Add(1)
+		go func(cs *captureStream) {
+			defer c.wg.Done()
+			cs.read()
+		}(cs)
+	}
+}
+
+// finish waits for the readers to drain what the command wrote; call after Wait (or a failed Start)
+func (c *outputCapture) finish() {
+	c.stdout.writer.Close()
+	c.stderr.writer.Close()
+	c.wg.Wait()
+}
+
+// hasOutput reports whether the command wrote anything at all
+func (c *outputCapture) hasOutput() bool {
+	return c.stdout.size > 0 || c.stderr.size > 0
+}
+
+// promoteStdout moves the stdout spool to outputPath, so output the tool only printed becomes its output file.
+// With secrets loaded the spool is copied with their values redacted instead
+func (c *outputCapture) promoteStdout(outputPath string, mode os.FileMode, store *secrets.Store) error {
+	if store.Len() > 0 {
+		file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		err = c.stdout.copyRedactedTo(file, store)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	if err := os.Rename(c.stdout.spool.Name(), outputPath); err != nil {
+		return err
+	}
+	c.stdout.kept = true
+	return os.Chmod(outputPath, mode)
+}
+
+// discard closes the spool files and removes those that did not become the output file
+func (c *outputCapture) discard() {
+	for _, cs := range []*captureStream{c.stdout, c.stderr} {
+		if cs.spool != nil {
+			cs.spool.Close()
+			if !cs.kept {
+				os.Remove(cs.spool.Name())
+			}
+			cs.spool = nil
+		}
+	}
+}
+
+// read consumes the stream until the writer is closed, splitting it into lines for the sinks
+func (cs *captureStream) read() {
+	reader := bufio.NewReaderSize(cs.reader, captureReadBytes)
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			cs.store(chunk)
+			line = append(line, chunk...)
+			if chunk[len(chunk)-1] == '\n' || len(line) >= captureLineBytes {
+				cs.emit(line)
+				line = line[:0]
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			break
+		}
+	}
+	if len(line) > 0 {
+		cs.emit(line)
+	}
+}
+
+// store keeps the stream's bytes: the head in memory, everything in the spool
+func (cs *captureStream) store(chunk []byte) {
+	cs.size += int64(len(chunk))
+	if !cs.content && len(bytes.TrimSpace(chunk)) > 0 {
+		cs.content = true
+	}
+	if room := captureHeadBytes - cs.head.Len(); room > 0 {
+		cs.head.Write(chunk[:min(room, len(chunk))])
+	}
+	if cs.spool != nil && cs.err == nil {
+		_, cs.err = cs.spool.Write(chunk)
+	}
+}
+
+// emit hands one line, without its line ending, to every sink
+func (cs *captureStream) emit(line []byte) {
+	text := string(bytes.TrimRight(line, "\r\n"))
+	for _, sink := range cs.sinks {
+		sink(text)
+	}
+}
+
+// lineRedactor scrubs a line of tool output: a secrets store or an output.Redactor
+type lineRedactor interface {
+	Enabled() bool
+	Redact(text string) string
+}
+
+// copyRedactedTo appends the spooled stream to w redacted line by line
+func (cs *captureStream) copyRedactedTo(w io.Writer, redactor lineRedactor) error {
+	if !redactor.Enabled() {
+		return cs.copyTo(w)
+	}
+	var stream io.Reader = bytes.NewReader(cs.head.Bytes())
+	if cs.spool != nil {
+		if _, err := cs.spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		stream = cs.spool
+	}
+	reader := bufio.NewReaderSize(stream, captureReadBytes)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, writeErr := io.WriteString(w, redactor.Redact(line)); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyTo appends the spooled stream to w
+func (cs *captureStream) copyTo(w io.Writer) error {
+	if cs.spool == nil {
+		_, err := w.Write(cs.head.Bytes())
+		return err
+	}
+	if _, err := cs.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, cs.spool)
+	return err
+}
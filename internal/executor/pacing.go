@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Pacing presets, slowest first, named after nmap's timing templates. Each tool's config maps
+// them to its own flags (pacing: in tools/<tool>/config.yaml), so one preset paces every tool
+const (
+	PacingParanoid   = "paranoid"
+	PacingSneaky     = "sneaky"
+	PacingNormal     = "normal"
+	PacingAggressive = "aggressive"
+)
+
+// PacingPresets lists the presets
+var PacingPresets = []string{PacingParanoid, PacingSneaky, PacingNormal, PacingAggressive}
+
+// ValidatePacing accepts a preset name, or "" for no pacing
+func ValidatePacing(preset string) error {
+	if preset == "" {
+		return nil
+	}
+	for _, known := range PacingPresets {
+		if preset == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown pacing '%s' (available: %s)", preset, strings.Join(PacingPresets, ", "))
+}
+
+// SetPacing applies a pacing preset to every tool run; "" leaves the tools' own flags alone
+func (tee *ToolExecutionEngine) SetPacing(preset string) error {
+	preset = strings.ToLower(strings.TrimSpace(preset))
+	if err := ValidatePacing(preset); err != nil {
+		return err
+	}
+	tee.pacing = preset
+	return nil
+}
+
+// GetPacing returns the pacing preset tools run with, "" when none
+func (tee *ToolExecutionEngine) GetPacing() string {
+	return tee.pacing
+}
+
+// applyPacing sets the flags of a pacing preset in a mode's arguments. A flag's value is
+// replaced wherever the flag appears, as its own argument followed by the value ("-rate 500")
+// or as a short flag with the number attached ("-T4"); a flag the mode does not use is added
+// in front. Tools without an entry for the preset run unchanged
+func applyPacing(args []string, flags map[string]string) []string {
+	if len(flags) == 0 {
+		return args
+	}
+	names := make([]string, 0, len(flags))
+	for flag := range flags {
+		names = append(names, flag)
+	}
+	sort.Strings(names)
+
+	paced := append([]string(nil), args...)
+	var added []string
+	for _, flag := range names {
+		value := flags[flag]
+		found := false
+		for i := 0; i < len(paced); i++ {
+			switch {
+			case paced[i] == flag && i+1 < len(paced):
+				paced[i+1] = value
+				found = true
+				i++
+			case len(flag) == 2 && len(paced[i]) > 2 && strings.HasPrefix(paced[i], flag) && isDigits(paced[i][2:]):
+				paced[i] = flag + value
+				found = true
+			}
+		}
+		if !found {
+			added = append(added, flag, value)
+		}
+	}
+	return append(added, paced...)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package executor
+
+import "os/exec"
+
+// setProcessGroup is a no-op on Windows; cancellation kills the tool process itself
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills a started tool
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// terminateProcessGroup kills a started tool; Windows has no SIGTERM to let it exit cleanly
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return killProcessGroup(cmd)
+}
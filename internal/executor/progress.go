@@ -38,9 +38,10 @@ type SimpleProgress struct {
 	Mode      string
 	StartTime time.Time
 	key       string
-	ticker    *time.Ticker // Keep for compatibility
-	done      chan bool    // Keep for compatibility
-	mu        sync.Mutex   // Keep for compatibility
+	ticker    *time.Ticker // Refreshes the remaining time once a deadline is set
+	done      chan bool
+	mu        sync.Mutex   // Protects deadline
+	deadline  time.Time
 }
 
 // NewSimpleProgress creates a new progress indicator using PTerm
@@ -52,14 +53,14 @@ func NewSimpleProgress(toolName, mode string) *SimpleProgress {
 		Mode:      mode,
 		StartTime: time.Now(),
 		key:       key,
-		ticker:    time.NewTicker(1 * time.Hour), // Create but don't use
-		done:      make(chan bool),               // Create but don't use
+		ticker:    time.NewTicker(1 * time.Hour), // Reset to every second by SetDeadline
+		done:      make(chan bool),
 	}
 
 	// Register with PTerm tracker
 	globalTracker.addExecution(key, toolName, mode)
 	
-	// Start dummy update loop for compatibility
+	// Refresh the remaining time while the tool runs
 	go progress.updateLoop()
 	
 	return progress
@@ -97,9 +98,46 @@ func (et *ExecutionTracker) addExecution(key, toolName, mode string) {
 	}
 }
 
-// updateLoop is kept for compatibility but does nothing
+// SetDeadline shows the time left before the run is killed, refreshed every second
+func (sp *SimpleProgress) SetDeadline(deadline time.Time) {
+	sp.mu.Lock()
+	sp.deadline = deadline
+	sp.mu.Unlock()
+	globalTracker.updateRemaining(sp.key, time.Until(deadline))
+	sp.ticker.Reset(time.Second)
+}
+
+// updateLoop refreshes the remaining time until the tool finishes
 func (sp *SimpleProgress) updateLoop() {
-	<-sp.done
+	for {
+		select {
+		case <-sp.done:
+			return
+		case <-sp.ticker.C:
+			sp.mu.Lock()
+			deadline := sp.deadline
+			sp.mu.Unlock()
+			if !deadline.IsZero() {
+				globalTracker.updateRemaining(sp.key, time.Until(deadline))
+			}
+		}
+	}
+}
+
+// updateRemaining shows an execution's remaining time next to its name
+func (et *ExecutionTracker) updateRemaining(key string, remaining time.Duration) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	entry, exists := et.executions[key]
+	if !exists {
+		return
+	}
+	status := "timing out"
+	if remaining > 0 {
+		status = formatDuration(remaining.Truncate(time.Second)) + " left"
+	}
+	entry.Spinner.UpdateText(fmt.Sprintf("%s [%s] (%s)", entry.ToolName, entry.Mode, status))
 }
 
 // Complete marks the tool as completed
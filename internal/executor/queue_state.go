@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// QueueStateFileName is the persisted orchestrator queue written to the workspace root
+const QueueStateFileName = "queue.json"
+
+// Queue item states recorded in the queue file
+const (
+	QueueItemQueued = "queued"
+	QueueItemActive = "active" // Started but not finished when the state was written
+)
+
+// QueueState is the orchestrator queue as persisted for --resume-queue
+type QueueState struct {
+	ScanID    string           `json:"scan_id,omitempty"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Items     []QueueStateItem `json:"items"`
+}
+
+// QueueStateItem is one workflow that has not finished yet
+type QueueStateItem struct {
+	Workflow     string    `json:"workflow"` // Workflow name (title)
+	Target       string    `json:"target"`
+	Priority     int       `json:"priority"`
+	QueuedAt     time.Time `json:"queued_at"`
+	Dependencies []string  `json:"dependencies,omitempty"`
+	State        string    `json:"state"`
+	TriggeredBy  string    `json:"triggered_by,omitempty"` // Set when a workflow trigger queued the item
+}
+
+// LoadQueueState reads the persisted queue from a workspace
+func LoadQueueState(workspaceDir string) (*QueueState, error) {
+	data, err := os.ReadFile(filepath.Join(workspaceDir, QueueStateFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue state: %w", err)
+	}
+	var state QueueState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse queue state: %w", err)
+	}
+	return &state, nil
+}
+
+// SetQueueStatePath enables persisting the queue to path whenever it changes
+func (wo *WorkflowOrchestrator) SetQueueStatePath(path string, perm os.FileMode) {
+	wo.mutex.Lock()
+	defer wo.mutex.Unlock()
+	wo.queueStatePath = path
+	wo.queueStatePerm = perm
+	if wo.activeQueueItems == nil {
+		wo.activeQueueItems = make(map[string]*WorkflowQueueItem)
+	}
+}
+
+// RequeueWorkflow restores a persisted item with its original priority, queue time and
+// dependencies, so a resumed queue runs in the same order it would have
+func (wo *WorkflowOrchestrator) RequeueWorkflow(workflow *Workflow, item QueueStateItem) {
+	wo.mutex.Lock()
+	defer wo.mutex.Unlock()
+
+	dependencies := item.Dependencies
+	if dependencies == nil {
+		dependencies = make([]string, 0)
+	}
+	wo.insertByPriority(&WorkflowQueueItem{
+		Workflow:     workflow,
+		Target:       item.Target,
+		Priority:     item.Priority,
+		QueueTime:    item.QueuedAt,
+		Dependencies: dependencies,
+		TriggeredBy:  item.TriggeredBy,
+	})
+	wo.debugLogger.Printf("Requeued workflow: %s for target: %s (priority %d)", workflow.Name, item.Target, item.Priority)
+	wo.saveQueueState()
+}
+
+// saveQueueState writes queued and unfinished active workflows; callers hold wo.mutex
+func (wo *WorkflowOrchestrator) saveQueueState() {
+	if wo.queueStatePath == "" {
+		return
+	}
+
+	state := QueueState{UpdatedAt: wo.wallNow(), Items: make([]QueueStateItem, 0)}
+	if wo.executor != nil && wo.executor.engine != nil {
+		state.ScanID = wo.executor.engine.GetScanID()
+	}
+
+	keys := make([]string, 0, len(wo.activeQueueItems))
+	for key := range wo.activeQueueItems {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		state.Items = append(state.Items, queueStateItem(wo.activeQueueItems[key], QueueItemActive))
+	}
+	for _, item := range wo.workflowQueue {
+		state.Items = append(state.Items, queueStateItem(item, QueueItemQueued))
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		wo.debugLogger.Printf("Warning: failed to encode queue state: %v", err)
+		return
+	}
+
+	// Write and rename so a crash mid-write never leaves a truncated queue file
+	tmpPath := wo.queueStatePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, wo.queueStatePerm); err != nil {
+		wo.debugLogger.Printf("Warning: failed to write queue state: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, wo.queueStatePath); err != nil {
+		wo.debugLogger.Printf("Warning: failed to replace queue state: %v", err)
+	}
+}
+
+func queueStateItem(item *WorkflowQueueItem, state string) QueueStateItem {
+	return QueueStateItem{
+		Workflow:     item.Workflow.Name,
+		Target:       item.Target,
+		Priority:     item.Priority,
+		QueuedAt:     item.QueueTime,
+		Dependencies: item.Dependencies,
+		State:        state,
+		TriggeredBy:  item.TriggeredBy,
+	}
+}
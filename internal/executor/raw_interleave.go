@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// interleavedRawLog records a tool's stdout and stderr to the raw log line by line as they are
+// produced, each line tagged with a timestamp and its stream, so the true ordering survives
+type interleavedRawLog struct {
+	mu       sync.Mutex
+	file     *os.File
+	prefix   string // "<tool> <mode>", plus the scan ID when set
+	location *time.Location
+}
+
+// openInterleavedRawLog opens the raw log for one tool execution, or returns nil if there is no workspace
+func (tee *ToolExecutionEngine) openInterleavedRawLog(toolName, mode string) *interleavedRawLog {
+	if tee.workspaceBase == "" {
+		return nil
+	}
+
+	rawLogPath := workspace.New(tee.workspaceBase).Paths().RawLog
+	if err := os.MkdirAll(filepath.Dir(rawLogPath), tee.dirMode); err != nil {
+		tee.debugLogger.Error("Failed to create raw log directory", "error", err)
+		return nil
+	}
+	file, err := os.OpenFile(rawLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, tee.fileMode)
+	if err != nil {
+		tee.debugLogger.Error("Failed to open raw log file", "error", err)
+		return nil
+	}
+
+	prefix := toolName + " " + mode
+	if tag := tee.logTag(); tag != "" {
+		prefix = tag + " " + prefix
+	}
+	return &interleavedRawLog{file: file, prefix: prefix, location: tee.location}
+}
+
+// Record writes one line of the given stream, tagged with the time it arrived
+func (l *interleavedRawLog) Record(stream, line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	timestamp := wallTime(time.Now(), l.location).Format(time.RFC3339Nano)
+	fmt.Fprintf(l.file, "[%s] [%s] %s | %s\n", timestamp, stream, l.prefix, line)
+}
+
+// Close closes the log; lines are written as they arrive, so nothing is pending
+func (l *interleavedRawLog) Close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file.Close()
+}
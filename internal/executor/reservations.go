@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Resource classes a workflow declares for the network and the CPU
+const (
+	ResourceLight = "light" // The default
+	ResourceHeavy = "heavy" // Saturates it: a port sweep for the network, brute forcing for the CPU
+)
+
+// WorkflowResources are what a workflow expects to use while it runs. The orchestrator holds
+// them as a reservation and starts no workflow whose reservation would exceed the limits in
+// workflow_orchestration.resource_limits
+type WorkflowResources struct {
+	Network  string // ResourceLight or ResourceHeavy; empty is light
+	CPU      string // ResourceLight or ResourceHeavy; empty is light
+	MemoryMB int    // Expected peak memory; 0 = not declared
+}
+
+// networkHeavy and cpuHeavy report the declared classes
+func (r WorkflowResources) networkHeavy() bool { return r.Network == ResourceHeavy }
+func (r WorkflowResources) cpuHeavy() bool     { return r.CPU == ResourceHeavy }
+
+// ValidateResources checks a workflow's resources declaration
+func ValidateResources(workflow *Workflow) error {
+	for field, class := range map[string]string{"network": workflow.Resources.Network, "cpu": workflow.Resources.CPU} {
+		if class != "" && class != ResourceLight && class != ResourceHeavy {
+			return fmt.Errorf("resources.%s: %q is not %q or %q", field, class, ResourceLight, ResourceHeavy)
+		}
+	}
+	if workflow.Resources.MemoryMB < 0 {
+		return fmt.Errorf("resources.memory_mb: %d is negative", workflow.Resources.MemoryMB)
+	}
+	return nil
+}
+
+// ResourceReservation is what one running workflow holds
+type ResourceReservation struct {
+	Workflow string `json:"workflow"`
+	Target   string `json:"target"`
+	Network  string `json:"network,omitempty"`
+	CPU      string `json:"cpu,omitempty"`
+	MemoryMB int    `json:"memory_mb,omitempty"`
+}
+
+// ReservationStatus is the resources running workflows hold and the limits they are held to
+type ReservationStatus struct {
+	Active          []ResourceReservation `json:"active"`
+	NetworkHeavy    int                   `json:"network_heavy"`
+	MaxNetworkHeavy int                   `json:"max_network_heavy"`
+	CPUHeavy        int                   `json:"cpu_heavy"`
+	MaxCPUHeavy     int                   `json:"max_cpu_heavy"`
+	MemoryMB        int                   `json:"memory_mb"`
+	MaxMemoryMB     int                   `json:"max_memory_mb,omitempty"` // 0 = no memory budget
+}
+
+// canReserve reports whether a workflow's resources fit next to the reservations held, and
+// why not. With nothing reserved everything fits, so a workflow larger than a limit still
+// runs, alone
+func (rm *ResourceMonitor) canReserve(resources WorkflowResources) (bool, string) {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+	if len(rm.reservations) == 0 {
+		return true, ""
+	}
+	held := rm.heldLocked()
+	switch {
+	case resources.networkHeavy() && held.NetworkHeavy >= rm.maxNetworkHeavy:
+		return false, fmt.Sprintf("%d network-heavy workflows running (max %d)", held.NetworkHeavy, rm.maxNetworkHeavy)
+	case resources.cpuHeavy() && held.CPUHeavy >= rm.maxCPUHeavy:
+		return false, fmt.Sprintf("%d CPU-heavy workflows running (max %d)", held.CPUHeavy, rm.maxCPUHeavy)
+	case rm.maxReservedMemoryMB > 0 && resources.MemoryMB > 0 && held.MemoryMB+resources.MemoryMB > rm.maxReservedMemoryMB:
+		return false, fmt.Sprintf("%d MB reserved, %d MB more exceeds %d MB", held.MemoryMB, resources.MemoryMB, rm.maxReservedMemoryMB)
+	}
+	return true, ""
+}
+
+// reserve records the resources of a workflow that starts, under its orchestrator key
+func (rm *ResourceMonitor) reserve(key string, workflow *Workflow, target string) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	if rm.reservations == nil {
+		rm.reservations = make(map[string]ResourceReservation)
+	}
+	rm.reservations[key] = ResourceReservation{
+		Workflow: workflow.Name,
+		Target:   target,
+		Network:  workflow.Resources.Network,
+		CPU:      workflow.Resources.CPU,
+		MemoryMB: workflow.Resources.MemoryMB,
+	}
+}
+
+// release frees the resources of a finished workflow
+func (rm *ResourceMonitor) release(key string) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	delete(rm.reservations, key)
+}
+
+// Reservations returns the resources running workflows hold, by workflow name
+func (rm *ResourceMonitor) Reservations() ReservationStatus {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+	status := rm.heldLocked()
+	sort.Slice(status.Active, func(i, j int) bool {
+		a, b := status.Active[i], status.Active[j]
+		if a.Workflow != b.Workflow {
+			return strings.ToLower(a.Workflow) < strings.ToLower(b.Workflow)
+		}
+		return a.Target < b.Target
+	})
+	return status
+}
+
+// heldLocked totals the reservations; callers hold rm.mutex
+func (rm *ResourceMonitor) heldLocked() ReservationStatus {
+	status := ReservationStatus{
+		Active:          make([]ResourceReservation, 0, len(rm.reservations)),
+		MaxNetworkHeavy: rm.maxNetworkHeavy,
+		MaxCPUHeavy:     rm.maxCPUHeavy,
+		MaxMemoryMB:     rm.maxReservedMemoryMB,
+	}
+	for _, reservation := range rm.reservations {
+		status.Active = append(status.Active, reservation)
+		resources := WorkflowResources{Network: reservation.Network, CPU: reservation.CPU}
+		if resources.networkHeavy() {
+			status.NetworkHeavy++
+		}
+		if resources.cpuHeavy() {
+			status.CPUHeavy++
+		}
+		status.MemoryMB += reservation.MemoryMB
+	}
+	return status
+}
@@ -0,0 +1,50 @@
+package executor
+
+import "fmt"
+
+// maxCollectorFailures is how many readings in a row a system collector may fail before it
+// is switched off for the rest of the run
+const maxCollectorFailures = 3
+
+// systemCollector tracks one system reading (CPU or memory). Hardened containers deny some
+// readings on every call, so a collector that keeps failing is switched off instead of retried
+// and its value is reported as unavailable
+type systemCollector struct {
+	name      string
+	failures  int  // Failed readings in a row
+	disabled  bool // Switched off after maxCollectorFailures failures
+	available bool // The last reading succeeded
+}
+
+// record notes the outcome of a reading. It returns an error only when this failure switches
+// the collector off, so the caller warns once instead of on every reading
+func (c *systemCollector) record(err error) error {
+	if err == nil {
+		c.failures = 0
+		c.available = true
+		return nil
+	}
+	c.failures++
+	c.available = false
+	if c.failures < maxCollectorFailures {
+		return nil
+	}
+	c.disabled = true
+	return fmt.Errorf("%s usage unavailable after %d failed readings, no longer collected: %v", c.name, c.failures, err)
+}
+
+// reading returns the value when it is available, nil otherwise
+func (c *systemCollector) reading(value float64) *float64 {
+	if !c.available {
+		return nil
+	}
+	return &value
+}
+
+// formatPercent formats a resource reading, "n/a" when it is unavailable
+func formatPercent(value *float64) string {
+	if value == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", *value)
+}
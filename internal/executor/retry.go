@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Failure kinds a retry policy can retry on
+const (
+	RetryOnTimeout     = "timeout"      // The tool ran out of time without producing valid output
+	RetryOnNonzeroExit = "nonzero_exit" // The tool failed to start or exited non-zero
+	RetryOnEmptyOutput = "empty_output" // The tool succeeded but produced no output
+)
+
+// defaultRetryBackoff is the wait before the first retry when a policy does not set one
+const defaultRetryBackoff = time.Second
+
+// RetryPolicy controls how often a step's tool executions are retried and on which failures.
+// The wait between attempts starts at Backoff and doubles after each retry
+type RetryPolicy struct {
+	Attempts int           // Total attempts including the first (1 = never retry)
+	Backoff  time.Duration // Wait before the first retry
+	RetryOn  []string      // Failure kinds that trigger a retry
+}
+
+// NewRetryPolicy builds a policy from its workflow YAML form, e.g. attempts: 3, backoff: "2s",
+// retry_on: ["timeout", "empty_output"]. retry_on defaults to nonzero_exit
+func NewRetryPolicy(attempts int, backoff string, retryOn []string) (*RetryPolicy, error) {
+	if attempts < 1 {
+		return nil, fmt.Errorf("retry attempts must be at least 1 (1 disables retries), got %d", attempts)
+	}
+	policy := &RetryPolicy{Attempts: attempts, Backoff: defaultRetryBackoff, RetryOn: []string{RetryOnNonzeroExit}}
+	if backoff != "" {
+		duration, err := time.ParseDuration(backoff)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid retry backoff '%s': use a duration such as \"2s\"", backoff)
+		}
+		policy.Backoff = duration
+	}
+	if len(retryOn) > 0 {
+		policy.RetryOn = nil
+		for _, kind := range retryOn {
+			kind = strings.ToLower(strings.TrimSpace(kind))
+			switch kind {
+			case RetryOnTimeout, RetryOnNonzeroExit, RetryOnEmptyOutput:
+				policy.RetryOn = append(policy.RetryOn, kind)
+			default:
+				return nil, fmt.Errorf("unknown retry_on '%s' (use %s, %s or %s)", kind, RetryOnTimeout, RetryOnNonzeroExit, RetryOnEmptyOutput)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// defaultRetryPolicy is used for executions without a step policy: tools.retry_attempts
+// retries after non-zero exits only, as before step policies existed
+func (tee *ToolExecutionEngine) defaultRetryPolicy() *RetryPolicy {
+	retries := 1
+	if tee.globalConfig != nil && tee.globalConfig.Tools.RetryAttempts > 0 {
+		retries = tee.globalConfig.Tools.RetryAttempts
+	}
+	return &RetryPolicy{Attempts: retries + 1, Backoff: defaultRetryBackoff, RetryOn: []string{RetryOnNonzeroExit}}
+}
+
+// retries reports whether the policy retries the given failure kind
+func (p *RetryPolicy) retries(kind string) bool {
+	for _, candidate := range p.RetryOn {
+		if candidate == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// wait returns the delay before the retry that follows the given zero-based attempt
+func (p *RetryPolicy) wait(attempt int) time.Duration {
+	return p.Backoff << uint(attempt)
+}
+
+// budget returns the time all attempts and the waits between them can take when each attempt
+// runs for up to perAttempt
+func (p *RetryPolicy) budget(perAttempt time.Duration) time.Duration {
+	total := perAttempt * time.Duration(p.Attempts)
+	for attempt := 0; attempt < p.Attempts-1; attempt++ {
+		total += p.wait(attempt)
+	}
+	return total
+}
+
+// String describes the policy for logs
+func (p *RetryPolicy) String() string {
+	return fmt.Sprintf("%d attempt(s), backoff %s, retry on %s", p.Attempts, p.Backoff, strings.Join(p.RetryOn, ","))
+}
+
+// producedNoOutput reports whether an execution left neither captured stdout nor a non-empty artifact
+func producedNoOutput(toolConfig *ToolConfig, mode string, result *ExecutionResult, printedOutput bool) bool {
+	if printedOutput || result.OutputPath == "" {
+		return false
+	}
+	path := result.OutputPath
+	if expected, declared := toolConfig.ExpectedOutputFor(mode); declared {
+		path = expected.Path(result.OutputPath)
+	}
+	info, err := os.Stat(path)
+	return err != nil || info.Size() == 0
+}
+
+// waitBeforeRetry sleeps for the policy's backoff; it returns false if the execution was cancelled meanwhile
+func (tee *ToolExecutionEngine) waitBeforeRetry(ctx context.Context, retry *RetryPolicy, attempt int, result *ExecutionResult) bool {
+	select {
+	case <-time.After(retry.wait(attempt)):
+		return true
+	case <-ctx.Done():
+		result.ErrorMessage = "execution cancelled during retry wait"
+		return false
+	}
+}
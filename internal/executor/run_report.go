@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// SetReportGenerator sets the generator that collects finished workflows for report.json
+func (wo *WorkflowOrchestrator) SetReportGenerator(generator *output.ReportGenerator) {
+	wo.mutex.Lock()
+	defer wo.mutex.Unlock()
+	wo.reportGenerator = generator
+}
+
+// recordWorkflowReport adds a finished workflow and the current magic variables to the run report
+func (wo *WorkflowOrchestrator) recordWorkflowReport(execution *WorkflowExecution) {
+	wo.mutex.RLock()
+	generator := wo.reportGenerator
+	wo.mutex.RUnlock()
+	if generator == nil {
+		return
+	}
+
+	workflow := output.WorkflowReport{
+		Name:       execution.Workflow.Name,
+		Target:     execution.Target,
+		Status:     execution.Status.String(),
+		StartedAt:  execution.StartTime,
+		FinishedAt: execution.EndTime,
+		Steps:      make([]output.StepReport, 0, len(execution.StepResults)),
+	}
+	if !execution.EndTime.IsZero() {
+		workflow.DurationSeconds = execution.Duration.Seconds()
+	}
+	if execution.Error != nil {
+		workflow.Error = execution.Error.Error()
+	}
+
+	for _, step := range execution.StepResults {
+		workflow.Steps = append(workflow.Steps, stepReport(step))
+	}
+
+	generator.RecordWorkflow(workflow)
+	if wo.executor != nil && wo.executor.engine != nil {
+		generator.SetVariables(wo.executor.engine.GetMagicVariables())
+	}
+}
+
+// stepReport converts a workflow step result for the run report
+func stepReport(step *WorkflowResult) output.StepReport {
+	report := output.StepReport{
+		Name:              step.StepName,
+		Tool:              step.Tool,
+		Modes:             step.Modes,
+		Matrix:            step.Matrix,
+		DependsOn:         step.DependsOn,
+		Order:             step.Order,
+		Success:           step.Success,
+		Skipped:           step.Skipped,
+		SkipReason:        step.SkipReason,
+		Error:             step.ErrorMessage,
+		DurationSeconds:   step.Duration.Seconds(),
+		CombinedVariables: step.CombinedVars,
+		Executions:        make([]output.ToolExecution, 0, len(step.Results)),
+	}
+
+	for _, result := range step.Results {
+		if result == nil {
+			continue
+		}
+		report.Executions = append(report.Executions, output.ToolExecution{
+			Tool:            result.ToolName,
+			Mode:            result.Mode,
+			Command:         result.CommandLine,
+			ExitCode:        result.ExitCode,
+			Success:         result.Success,
+			StartTime:       result.StartTime,
+			EndTime:         result.EndTime,
+			DurationSeconds: result.Duration.Seconds(),
+			OutputPath:      result.OutputPath,
+			Error:           result.ErrorMessage,
+			FallbackFor:     result.FallbackFor,
+			FallbackReason:  result.FallbackReason,
+			Warning:         result.Warning,
+		})
+	}
+	return report
+}
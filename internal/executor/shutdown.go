@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultShutdownGrace is how long tools may take to exit after SIGTERM when
+// tools.shutdown_grace_seconds is not set
+const defaultShutdownGrace = 5 * time.Second
+
+// partialOutputSuffix is appended to the stdout kept from a tool that was stopped mid-run, so
+// a resumed run neither mistakes it for finished output nor refuses to save its own
+const partialOutputSuffix = ".partial"
+
+// processTracker knows every tool process the engine started. Cancelling an execution (the
+// run being interrupted, or CancelWorkflow) stops it in two stages: SIGTERM to the tool's
+// process group so it can flush what it found (nmap completes its XML, naabu prints its last
+// results), then SIGKILL for whatever is still running once the grace period is over
+type processTracker struct {
+	mutex   sync.Mutex
+	running map[*exec.Cmd]*trackedProcess
+	stopped []string // "tool mode" of every execution stopped by cancellation
+}
+
+// trackedProcess is one started tool
+type trackedProcess struct {
+	label      string
+	terminated bool
+	kill       *time.Timer
+}
+
+func newProcessTracker() *processTracker {
+	return &processTracker{running: make(map[*exec.Cmd]*trackedProcess)}
+}
+
+// manage puts cmd in its own process group and tracks it until done is called; cancelling
+// its context terminates the group and kills it after grace. Call before Start
+func (pt *processTracker) manage(cmd *exec.Cmd, label string, grace time.Duration) {
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return pt.terminate(cmd, grace) }
+	// Pipes stay open for the grace period, so output flushed on SIGTERM is still captured
+	cmd.WaitDelay = grace + processWaitDelay
+
+	pt.mutex.Lock()
+	pt.running[cmd] = &trackedProcess{label: label}
+	pt.mutex.Unlock()
+}
+
+// terminate asks a tool's process group to exit and schedules the kill
+func (pt *processTracker) terminate(cmd *exec.Cmd, grace time.Duration) error {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	process, tracked := pt.running[cmd]
+	if !tracked || process.terminated || cmd.Process == nil {
+		return killProcessGroup(cmd)
+	}
+	process.terminated = true
+	pt.stopped = append(pt.stopped, process.label)
+	process.kill = time.AfterFunc(grace, func() { killProcessGroup(cmd) })
+	return terminateProcessGroup(cmd)
+}
+
+// done stops tracking cmd once Wait has returned (or Start failed) and reports whether it was
+// stopped by cancellation. Processes of a stopped tool's group that outlived it are killed
+func (pt *processTracker) done(cmd *exec.Cmd) bool {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	process, tracked := pt.running[cmd]
+	if !tracked {
+		return false
+	}
+	delete(pt.running, cmd)
+	if !process.terminated {
+		return false
+	}
+	process.kill.Stop()
+	killProcessGroup(cmd)
+	return true
+}
+
+// RunningTools returns the "tool mode" of every tool process currently running
+func (tee *ToolExecutionEngine) RunningTools() []string {
+	tee.processes.mutex.Lock()
+	defer tee.processes.mutex.Unlock()
+	labels := make([]string, 0, len(tee.processes.running))
+	for _, process := range tee.processes.running {
+		labels = append(labels, process.label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// StoppedTools returns the "tool mode" of every execution stopped by cancellation, in the order they were stopped
+func (tee *ToolExecutionEngine) StoppedTools() []string {
+	tee.processes.mutex.Lock()
+	defer tee.processes.mutex.Unlock()
+	return append([]string(nil), tee.processes.stopped...)
+}
+
+// ShutdownGrace returns how long a cancelled tool may take to exit after SIGTERM before it is killed
+func (tee *ToolExecutionEngine) ShutdownGrace() time.Duration {
+	if tee.globalConfig != nil && tee.globalConfig.Tools.ShutdownGraceSeconds > 0 {
+		return time.Duration(tee.globalConfig.Tools.ShutdownGraceSeconds) * time.Second
+	}
+	return defaultShutdownGrace
+}
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// Variables set by the wordlist selector
+const (
+	SmartWordlistVariable     = "smart_wordlist"      // Wordlist of the first matching rule, else the default
+	SmartWordlistsVariable    = "smart_wordlists"     // Wordlists of every matching rule, comma-separated
+	SmartWordlistRuleVariable = "smart_wordlist_rule" // Name of the rule that chose smart_wordlist
+)
+
+// WordlistSelector picks brute-force wordlists from the technologies detected so far in a run,
+// so directory brute-force steps use {{smart_wordlist}} instead of one list for every server
+type WordlistSelector struct {
+	defaultList string
+	rules       []config.WordlistRule
+
+	mutex        sync.Mutex
+	technologies map[string]bool // Lower-cased technology strings seen in step outputs
+	matched      map[int]bool    // Indexes of the rules that matched
+}
+
+// NewWordlistSelector validates the wordlist rules
+func NewWordlistSelector(cfg config.WordlistsConfig) (*WordlistSelector, error) {
+	selector := &WordlistSelector{
+		defaultList:  cfg.Default,
+		technologies: make(map[string]bool),
+		matched:      make(map[int]bool),
+	}
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.Wordlist == "" {
+			return nil, fmt.Errorf("wordlist rule %q: no wordlist configured", rule.Name)
+		}
+		if len(rule.Match) == 0 {
+			return nil, fmt.Errorf("wordlist rule %q: no match keywords configured", rule.Name)
+		}
+		for j, keyword := range rule.Match {
+			rule.Match[j] = strings.ToLower(strings.TrimSpace(keyword))
+		}
+		selector.rules = append(selector.rules, rule)
+	}
+	return selector, nil
+}
+
+// Observe records the technologies in findings (web technologies, products and page titles)
+// and reports whether the selection changed
+func (s *WordlistSelector) Observe(found []findings.Finding) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := false
+	for _, f := range found {
+		candidates := append([]string{f.Product, f.Title}, f.Technologies...)
+		for _, technology := range candidates {
+			technology = strings.ToLower(strings.TrimSpace(technology))
+			if technology == "" || s.technologies[technology] {
+				continue
+			}
+			s.technologies[technology] = true
+			for i, rule := range s.rules {
+				if !s.matched[i] && matchesAnyKeyword(technology, rule.Match) {
+					s.matched[i] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// Variables returns the current selection as magic variables
+func (s *WordlistSelector) Variables() map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	vars := map[string]string{
+		SmartWordlistVariable:     s.defaultList,
+		SmartWordlistsVariable:    s.defaultList,
+		SmartWordlistRuleVariable: "default",
+	}
+	var lists []string
+	for i, rule := range s.rules {
+		if !s.matched[i] || containsString(lists, rule.Wordlist) {
+			continue
+		}
+		if len(lists) == 0 {
+			vars[SmartWordlistVariable] = rule.Wordlist
+			vars[SmartWordlistRuleVariable] = rule.Name
+		}
+		lists = append(lists, rule.Wordlist)
+	}
+	if len(lists) > 0 {
+		vars[SmartWordlistsVariable] = strings.Join(lists, ",")
+	}
+	return vars
+}
+
+func matchesAnyKeyword(technology string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(technology, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetWordlistSelector exports {{smart_wordlist}} and updates it as steps detect technologies
+func (wo *WorkflowOrchestrator) SetWordlistSelector(selector *WordlistSelector) {
+	wo.wordlists = selector
+	wo.exportWordlists()
+}
+
+// selectWordlists feeds a finished step's findings to the wordlist selector
+func (wo *WorkflowOrchestrator) selectWordlists(result *WorkflowResult) {
+	if wo.wordlists == nil || result == nil || result.Skipped {
+		return
+	}
+	if wo.wordlists.Observe(stepFindings(wo.executor.engine, wo.findingsCatalog(), result)) {
+		wo.exportWordlists()
+	}
+}
+
+func (wo *WorkflowOrchestrator) exportWordlists() {
+	vars := wo.wordlists.Variables()
+	for name, value := range vars {
+		wo.executor.engine.GetTemplateResolver().AddVariable(name, value)
+	}
+	wo.debugLogger.Printf("Smart wordlist: %s (rule: %s)", vars[SmartWordlistVariable], vars[SmartWordlistRuleVariable])
+}
@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OrchestratorSnapshot is a point-in-time view of orchestrator state used to debug hangs
+type OrchestratorSnapshot struct {
+	ScanID       string                `json:"scan_id,omitempty"`
+	TakenAt      time.Time             `json:"taken_at"`
+	Goroutines   int                   `json:"goroutines"`
+	MaxWorkflows int                   `json:"max_concurrent_workflows"`
+	Queue        []QueuedWorkflowState `json:"queue"`
+	Active       []ActiveWorkflowState `json:"active"`
+	Concurrency  *ExecutionStatus      `json:"concurrency,omitempty"`
+	Resources    ResourceState         `json:"resources"`
+}
+
+// QueuedWorkflowState describes a workflow waiting in the orchestrator queue
+type QueuedWorkflowState struct {
+	Workflow       string   `json:"workflow"`
+	Target         string   `json:"target"`
+	Priority       int      `json:"priority"`
+	WaitingSeconds float64  `json:"waiting_seconds"`
+	Dependencies   []string `json:"dependencies,omitempty"`
+}
+
+// ActiveWorkflowState describes a workflow that is currently executing
+type ActiveWorkflowState struct {
+	Key            string  `json:"key"`
+	Workflow       string  `json:"workflow"`
+	Target         string  `json:"target"`
+	Status         string  `json:"status"`
+	TotalSteps     int     `json:"total_steps"`
+	RunningSeconds float64 `json:"running_seconds"`
+}
+
+// ResourceState is the resource monitor's last observed usage
+type ResourceState struct {
+	CPUPercent     *float64 `json:"cpu_percent"`    // null when the reading is unavailable
+	MemoryPercent  *float64 `json:"memory_percent"` // null when the reading is unavailable
+	ActiveTools    int      `json:"active_tools"`
+	MaxActiveTools int      `json:"max_active_tools"`
+}
+
+// String returns the lowercase name of the workflow status
+func (s WorkflowStatus) String() string {
+	switch s {
+	case WorkflowStatusQueued:
+		return "queued"
+	case WorkflowStatusRunning:
+		return "running"
+	case WorkflowStatusCompleted:
+		return "completed"
+	case WorkflowStatusFailed:
+		return "failed"
+	case WorkflowStatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Snapshot captures the current queue, active workflows, slot usage and resource state.
+// It only takes read locks and never blocks running workflows for long
+func (wo *WorkflowOrchestrator) Snapshot() *OrchestratorSnapshot {
+	now := time.Now() // Keeps its monotonic reading, so running times survive clock steps
+	snapshot := &OrchestratorSnapshot{
+		TakenAt:    wo.wallNow(),
+		Goroutines: runtime.NumGoroutine(),
+		Queue:      make([]QueuedWorkflowState, 0),
+		Active:     make([]ActiveWorkflowState, 0),
+	}
+
+	wo.mutex.RLock()
+	snapshot.MaxWorkflows = wo.maxConcurrentWorkflows
+	for _, item := range wo.workflowQueue {
+		snapshot.Queue = append(snapshot.Queue, QueuedWorkflowState{
+			Workflow:       item.Workflow.Name,
+			Target:         item.Target,
+			Priority:       item.Priority,
+			WaitingSeconds: now.Sub(item.QueueTime).Seconds(),
+			Dependencies:   item.Dependencies,
+		})
+	}
+	for key, execution := range wo.activeWorkflows {
+		snapshot.Active = append(snapshot.Active, ActiveWorkflowState{
+			Key:            key,
+			Workflow:       execution.Workflow.Name,
+			Target:         execution.Target,
+			Status:         execution.Status.String(),
+			TotalSteps:     execution.TotalSteps,
+			RunningSeconds: now.Sub(execution.started).Seconds(),
+		})
+	}
+	wo.mutex.RUnlock()
+
+	sort.Slice(snapshot.Active, func(i, j int) bool {
+		return snapshot.Active[i].Key < snapshot.Active[j].Key
+	})
+
+	if wo.executor != nil && wo.executor.engine != nil {
+		snapshot.ScanID = wo.executor.engine.GetScanID()
+		status := wo.executor.engine.GetExecutionStatus()
+		snapshot.Concurrency = &status
+	}
+
+	if wo.ResourceMonitor != nil {
+		wo.ResourceMonitor.mutex.RLock()
+		snapshot.Resources = ResourceState{
+			CPUPercent:     wo.ResourceMonitor.cpuReading.reading(wo.ResourceMonitor.currentCPU),
+			MemoryPercent:  wo.ResourceMonitor.memoryReading.reading(wo.ResourceMonitor.currentMemory),
+			ActiveTools:    wo.ResourceMonitor.activeTools,
+			MaxActiveTools: wo.ResourceMonitor.maxActiveTools,
+		}
+		wo.ResourceMonitor.mutex.RUnlock()
+	}
+
+	return snapshot
+}
+
+// WriteSnapshot writes the orchestrator state and all goroutine stacks to dir.
+// It returns the path of the state file; the stacks are written alongside it
+func (wo *WorkflowOrchestrator) WriteSnapshot(dir string) (string, error) {
+	snapshot := wo.Snapshot()
+
+	perm := os.FileMode(0644)
+	if wo.config != nil {
+		perm = wo.config.Output.Permissions.FilePerm()
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	baseName := fmt.Sprintf("snapshot_%s", time.Now().Format("20060102_150405.000"))
+	baseName = strings.ReplaceAll(baseName, ".", "_")
+	statePath := filepath.Join(dir, baseName+".json")
+	if err := os.WriteFile(statePath, data, perm); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	stacksFile, err := os.OpenFile(filepath.Join(dir, baseName+"_goroutines.txt"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return statePath, fmt.Errorf("failed to create goroutine dump: %w", err)
+	}
+	defer stacksFile.Close()
+
+	// debug=2 prints full stacks in the same format as an unrecovered panic
+	if err := pprof.Lookup("goroutine").WriteTo(stacksFile, 2); err != nil {
+		return statePath, fmt.Errorf("failed to write goroutine dump: %w", err)
+	}
+
+	return statePath, nil
+}
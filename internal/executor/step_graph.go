@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A step's depends_on names the steps it waits for, one or a list. The steps form a graph:
+// each starts as soon as all of its dependencies have finished, so independent steps run at
+// the same time and a step several others depend on (a diamond) runs once
+
+// stepGraph holds, for each step of a workflow, the indexes of the steps it depends on
+type stepGraph struct {
+	dependencies [][]int
+}
+
+// newStepGraph builds the dependency graph of steps, rejecting dependencies that name no
+// step, a step depending on itself and cycles
+func newStepGraph(steps []*WorkflowStep) (*stepGraph, error) {
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		index[step.Name] = i
+	}
+
+	graph := &stepGraph{dependencies: make([][]int, len(steps))}
+	for i, step := range steps {
+		seen := make(map[string]bool, len(step.DependsOn))
+		for _, name := range step.DependsOn {
+			dependency, exists := index[name]
+			switch {
+			case name == step.Name:
+				return nil, fmt.Errorf("step '%s' depends on itself", step.Name)
+			case !exists:
+				return nil, fmt.Errorf("step '%s' depends on '%s', which is not a step of this workflow", step.Name, name)
+			case seen[name]:
+				return nil, fmt.Errorf("step '%s' lists dependency '%s' twice", step.Name, name)
+			}
+			seen[name] = true
+			graph.dependencies[i] = append(graph.dependencies[i], dependency)
+		}
+	}
+
+	if cycle := graph.cycle(); cycle != nil {
+		names := make([]string, len(cycle))
+		for i, step := range cycle {
+			names[i] = "'" + steps[step].Name + "'"
+		}
+		return nil, fmt.Errorf("dependency cycle: %s (each step waits for the next)", strings.Join(names, " -> "))
+	}
+	return graph, nil
+}
+
+// cycle returns the steps of a dependency cycle, the first step repeated at the end, or nil
+func (g *stepGraph) cycle() []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.dependencies))
+	var path []int
+	var visit func(step int) []int
+	visit = func(step int) []int {
+		state[step] = visiting
+		path = append(path, step)
+		for _, dependency := range g.dependencies[step] {
+			switch state[dependency] {
+			case visiting:
+				for i, onPath := range path {
+					if onPath == dependency {
+						return append(append([]int{}, path[i:]...), dependency)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dependency); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[step] = visited
+		return nil
+	}
+	for step := range g.dependencies {
+		if state[step] == unvisited {
+			if cycle := visit(step); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// run calls run for every step, each in its own goroutine once its dependencies have
+// returned, and waits for all of them. order is the position in which the step started,
+// from 1
+func (g *stepGraph) run(run func(step, order int)) {
+	done := make([]chan struct{}, len(g.dependencies))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	var mutex sync.Mutex
+	started := 0
+	var wg sync.WaitGroup
+	for i := range g.dependencies {
+		wg.Add(1)
+		go func(step int) {
+			defer wg.Done()
+			defer close(done[step])
+			for _, dependency := range g.dependencies[step] {
+				<-done[dependency]
+			}
+			mutex.Lock()
+			started++
+			order := started
+			mutex.Unlock()
+			run(step, order)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// ValidateDependencies checks the depends_on of every step when the workflow loads
+func ValidateDependencies(workflow *Workflow) error {
+	_, err := newStepGraph(workflow.Steps)
+	return err
+}
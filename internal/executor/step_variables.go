@@ -0,0 +1,325 @@
+package executor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+)
+
+// A step's variables: either copy a variable to a new name (source: target), or, when the
+// value is a template, define a variable from it (name: template):
+//
+//	variables:
+//	  combined_ports: "open_ports"                          # open_ports = combined_ports
+//	  top_ports: "{{slice(combined_ports, 0, 100)}}"        # the first 100 open ports
+//	  port_args: "-p {{top_ports}} --max-rate {{config.tools.target_scheduling.default_rate_limit}}"
+//
+// Templates see the built-in and magic variables, the step's other variables and the global
+// configuration as config.<file>.<key> (the credentials of integrations and storage are not
+// exposed). They resolve when the step starts, each after the variables it uses; a cycle is
+// reported when the workflow loads. A template using a plain {{variable}} that is not set yet
+// leaves its variable unset, as a mapping of an unset source does.
+
+// A step's inputs and outputs wire variables between steps by name, so a step does not depend
+// on which tool happened to set a magic variable last:
+//
+//	outputs:
+//	  variables:
+//	    - name: "naabu_ports"       # published when the step finishes
+//	      source: "combined_ports"  # from the step's combined results, else the magic variables
+//	inputs:
+//	  variables:
+//	    - name: "combined_ports"    # set before the step's variables and tools
+//	      source: "naabu_ports"
+//
+// A source that is not set leaves its variable as it was.
+
+// configVariablePrefix starts the names of configuration values in step variable templates
+const configVariablePrefix = "config."
+
+// configSectionsHidden are not exposed as config.* variables: they hold credentials
+var configSectionsHidden = map[string]bool{"integrations": true, "storage": true}
+
+// IsVariableTemplate reports whether a step variables value is a template rather than the
+// name a variable is copied to
+func IsVariableTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// IsConfigVariable reports whether a name refers to a configuration value (config.<file>.<key>)
+func IsConfigVariable(name string) bool {
+	return strings.HasPrefix(name, configVariablePrefix)
+}
+
+// ValidateStepVariables checks the names and templates of every step's variables and rejects
+// templates that use each other in a cycle, so mistakes surface when the workflow loads
+func ValidateStepVariables(workflow *Workflow) error {
+	for _, step := range workflow.Steps {
+		templates := make(map[string]string)
+		for name, value := range step.Variables {
+			if !IsVariableTemplate(value) {
+				continue
+			}
+			if !isPlainVariable(name) || strings.Contains(name, ".") {
+				return fmt.Errorf("step '%s': variables: invalid variable name %q", step.Name, name)
+			}
+			if err := ValidateTemplate(value); err != nil {
+				return fmt.Errorf("step '%s': variables.%s: %v", step.Name, name, err)
+			}
+			for _, content := range placeholders(value) {
+				if strings.HasPrefix(content, secretPrefix) {
+					return fmt.Errorf("step '%s': variables.%s: secrets are only available in tool arguments", step.Name, name)
+				}
+			}
+			templates[name] = value
+		}
+		if _, err := stepVariableOrder(templates); err != nil {
+			return fmt.Errorf("step '%s': variables: %v", step.Name, err)
+		}
+		for block, mappings := range map[string][]VariableMapping{"inputs": step.Inputs, "outputs": step.Outputs} {
+			if err := validateVariableMappings(mappings); err != nil {
+				return fmt.Errorf("step '%s': %s.variables: %v", step.Name, block, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateVariableMappings checks the names and sources of an inputs or outputs block
+func validateVariableMappings(mappings []VariableMapping) error {
+	names := make(map[string]bool, len(mappings))
+	for i, mapping := range mappings {
+		for field, value := range map[string]string{"name": mapping.Name, "source": mapping.Source} {
+			if !isPlainVariable(value) {
+				return fmt.Errorf("[%d]: invalid %s %q", i, field, value)
+			}
+		}
+		if names[mapping.Name] {
+			return fmt.Errorf("[%d]: %q is set twice", i, mapping.Name)
+		}
+		names[mapping.Name] = true
+	}
+	return nil
+}
+
+// stepVariableOrder returns the names of a step's template variables ordered so each comes
+// after the others it uses
+func stepVariableOrder(templates map[string]string) ([]string, error) {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var order, path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for path[start] != name {
+				start++
+			}
+			return fmt.Errorf("cycle %s -> %s", strings.Join(path[start:], " -> "), name)
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, used := range TemplateVariables(templates[name]) {
+			if _, isTemplate := templates[used]; isTemplate {
+				if err := visit(used); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// ApplyStepVariables sets a step's variables before it runs: mappings first, then templates
+// resolved against the variables of ctx and the configuration, in dependency order. It
+// returns the template variables left unset because a variable they use is not set yet
+func (tr *TemplateResolver) ApplyStepVariables(variables map[string]string, ctx *ExecutionContext) ([]string, error) {
+	templates := make(map[string]string)
+	sources := make([]string, 0, len(variables))
+	for name, value := range variables {
+		if IsVariableTemplate(value) {
+			templates[name] = value
+		} else {
+			sources = append(sources, name)
+		}
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		tr.MapWorkflowVariable(source, variables[source])
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	order, err := stepVariableOrder(templates)
+	if err != nil {
+		return nil, err
+	}
+	vars := tr.buildVariableMap(ctx)
+	for name, value := range configVariables(tr.config) {
+		vars[name] = value
+	}
+	var unset []string
+	for _, name := range order {
+		if missing := undefinedVariables(templates[name], vars); len(missing) > 0 {
+			unset = append(unset, fmt.Sprintf("%s (needs %s)", name, strings.Join(missing, ", ")))
+			continue
+		}
+		value, _, err := resolveTemplate(templates[name], vars, nil)
+		if err != nil {
+			return unset, fmt.Errorf("variables.%s: %v", name, err)
+		}
+		vars[name] = value
+		tr.AddVariable(name, value)
+	}
+	return unset, nil
+}
+
+// ApplyStepInputs sets a step's inputs from the variables gathered so far. It returns the
+// inputs left unset because their source is not set
+func (tr *TemplateResolver) ApplyStepInputs(inputs []VariableMapping) []string {
+	vars := tr.GetAllVariables()
+	var unset []string
+	for _, input := range inputs {
+		value, exists := vars[input.Source]
+		if !exists {
+			unset = append(unset, fmt.Sprintf("%s (needs %s)", input.Name, input.Source))
+			continue
+		}
+		tr.AddVariable(input.Name, value)
+	}
+	return unset
+}
+
+// PublishStepOutputs sets a step's outputs from its combined results, falling back to the
+// magic variables its tools set. It returns the values published and the outputs left unset
+func (tr *TemplateResolver) PublishStepOutputs(outputs []VariableMapping, combined map[string]string) (map[string]string, []string) {
+	vars := tr.GetAllVariables()
+	published := make(map[string]string, len(outputs))
+	var unset []string
+	for _, output := range outputs {
+		value, exists := combined[output.Source]
+		if !exists {
+			value, exists = vars[output.Source]
+		}
+		if !exists {
+			unset = append(unset, fmt.Sprintf("%s (needs %s)", output.Name, output.Source))
+			continue
+		}
+		published[output.Name] = value
+		tr.AddVariable(output.Name, value)
+	}
+	return published, unset
+}
+
+// undefinedVariables returns the plain {{variable}} placeholders of a template that vars does
+// not define; expressions treat undefined variables as empty
+func undefinedVariables(template string, vars map[string]string) []string {
+	var missing []string
+	for _, content := range placeholders(template) {
+		if _, defined := vars[content]; isPlainVariable(content) && !defined {
+			missing = append(missing, content)
+		}
+	}
+	return missing
+}
+
+// configVariables flattens the configuration into config.<file>.<key> variables: values as
+// written in the files, lists comma-separated
+func configVariables(cfg *config.Config) map[string]string {
+	vars := make(map[string]string)
+	if cfg == nil {
+		return vars
+	}
+	value := reflect.ValueOf(*cfg)
+	for i := 0; i < value.NumField(); i++ {
+		section := value.Type().Field(i).Tag.Get("mapstructure")
+		if !configSectionsHidden[section] {
+			flattenConfigValue(vars, configVariablePrefix+section, value.Field(i))
+		}
+	}
+	return vars
+}
+
+func flattenConfigValue(vars map[string]string, name string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			flattenConfigValue(vars, name, value.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if field.PkgPath != "" || key == "-" {
+				continue
+			}
+			if key == "" {
+				key = strings.ToLower(field.Name)
+			}
+			flattenConfigValue(vars, name+"."+key, value.Field(i))
+		}
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range value.MapKeys() {
+			flattenConfigValue(vars, name+"."+key.String(), value.MapIndex(key))
+		}
+	case reflect.Slice, reflect.Array:
+		var items []string
+		for i := 0; i < value.Len(); i++ {
+			item, ok := configScalar(value.Index(i))
+			if !ok {
+				return // Lists of sections are not values
+			}
+			items = append(items, item)
+		}
+		vars[name] = strings.Join(items, ",")
+	default:
+		if scalar, ok := configScalar(value); ok {
+			vars[name] = scalar
+		}
+	}
+}
+
+func configScalar(value reflect.Value) (string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), true
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+)
+
+// TargetScanFunc scans one target with its share of the global rate budget
+type TargetScanFunc func(ctx context.Context, target string, rateLimit int) error
+
+// TargetScheduler bounds how many targets are scanned at once and splits a global
+// packets/requests-per-second budget between them. Workflows within a target are still
+// limited by the orchestrator's max_concurrent_workflows
+type TargetScheduler struct {
+	maxTargets  int
+	globalRate  int
+	defaultRate int
+}
+
+// NewTargetScheduler creates a scheduler from the target_scheduling configuration
+func NewTargetScheduler(cfg config.TargetSchedulingConfig) *TargetScheduler {
+	maxTargets := cfg.MaxConcurrentTargets
+	if maxTargets <= 0 {
+		maxTargets = 1
+	}
+	defaultRate := cfg.DefaultRateLimit
+	if defaultRate <= 0 {
+		defaultRate = 1000
+	}
+	return &TargetScheduler{
+		maxTargets:  maxTargets,
+		globalRate:  cfg.GlobalRateLimit,
+		defaultRate: defaultRate,
+	}
+}
+
+// MaxConcurrentTargets returns how many targets may be scanned at once
+func (ts *TargetScheduler) MaxConcurrentTargets() int {
+	return ts.maxTargets
+}
+
+// RateLimitFor returns each target's rate when targetCount targets are scheduled. A global
+// budget is divided evenly between the targets that can run at once (at least 1 each);
+// without one, every target gets the default rate
+func (ts *TargetScheduler) RateLimitFor(targetCount int) int {
+	if ts.globalRate <= 0 {
+		return ts.defaultRate
+	}
+	concurrent := ts.maxTargets
+	if targetCount > 0 && targetCount < concurrent {
+		concurrent = targetCount
+	}
+	if share := ts.globalRate / concurrent; share > 0 {
+		return share
+	}
+	return 1
+}
+
+// Run scans every target, at most MaxConcurrentTargets at a time, and returns each target's
+// error (nil on success) in target order. Targets not started before ctx is cancelled
+// report the context error
+func (ts *TargetScheduler) Run(ctx context.Context, targets []string, scan TargetScanFunc) []error {
+	errs := make([]error, len(targets))
+	rateLimit := ts.RateLimitFor(len(targets))
+	slots := make(chan struct{}, ts.maxTargets)
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(targets); j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = scan(ctx, target, rateLimit)
+		}(i, target)
+	}
+	wg.Wait()
+	return errs
+}
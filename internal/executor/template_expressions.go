@@ -0,0 +1,301 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/secrets"
+)
+
+// Argument templates may transform a value with filters, applied left to right:
+//
+//	{{http_ports|default:"80,443"}}            the value, or 80,443 when it is undefined or empty
+//	{{discovered_subdomains|join:" "}}         the comma-separated values joined with a space
+//	{{combined_ports|first:100}}               the first 100 comma-separated values
+//	{{slice(combined_ports, 100, 200)}}        the 101st to 200th comma-separated values
+//	{{combined_port_count > 100|if:"-T4"}}     -T4 when the expression holds, else nothing
+//
+// The part before the first filter is a run_if expression (see conditions.go): a bare
+// variable name is its value, comparisons and functions give true or false. An argument that
+// is a single filtered expression and resolves to nothing is left out of the command line,
+// so {{...|if:"--flag"}} adds a flag only when it applies.
+//
+// {{secret:name}} is replaced with a secret from the secrets store (internal/secrets). Secrets
+// resolve in tool arguments only and never become variables, so they cannot reach reports.
+
+// templateFilters are the filters available in argument templates, by name and whether they
+// take an argument
+var templateFilters = map[string]bool{
+	"default": true,  // default:"x": x when the value is empty
+	"join":    true,  // join:" ": comma-separated values joined with the separator
+	"first":   true,  // first:N: the first N comma-separated values
+	"last":    true,  // last:N: the last N comma-separated values
+	"if":      true,  // if:"x": x when the value is true (not empty, 0 or false), else empty
+	"else":    true,  // else:"x": x when the value is false, else empty
+	"count":   false, // count: number of comma-separated values
+	"lower":   false, // lower: the value in lower case
+	"upper":   false, // upper: the value in upper case
+}
+
+// templateExpression is a parsed {{value|filter:arg|...}} placeholder
+type templateExpression struct {
+	text    string
+	value   conditionNode
+	filters []templateFilter
+}
+
+type templateFilter struct {
+	name string
+	arg  string
+}
+
+// isPlainVariable reports whether a placeholder is a bare variable name, substituted as before
+// filters existed: left as written when the variable is undefined
+func isPlainVariable(content string) bool {
+	if content == "" {
+		return false
+	}
+	for _, r := range content {
+		if !isIdentRune(r) && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTemplateExpression parses the inside of a {{...}} placeholder
+func parseTemplateExpression(content string) (*templateExpression, error) {
+	parts := splitFilters(content)
+	head := strings.TrimSpace(parts[0])
+	if head == "" {
+		return nil, fmt.Errorf("invalid template {{%s}}: no value before the first filter", content)
+	}
+	tokens, err := tokenizeCondition(head)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template {{%s}}: %v", content, err)
+	}
+	parser := &conditionParser{tokens: tokens}
+	value, err := parser.parseOr()
+	if err == nil && parser.pos < len(parser.tokens) {
+		err = fmt.Errorf("unexpected %q", parser.tokens[parser.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid template {{%s}}: %v", content, err)
+	}
+
+	expression := &templateExpression{text: content, value: value}
+	for _, part := range parts[1:] {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(part), ":")
+		name = strings.TrimSpace(name)
+		takesArg, known := templateFilters[name]
+		if !known {
+			return nil, fmt.Errorf("invalid template {{%s}}: unknown filter %q", content, name)
+		}
+		if takesArg != hasArg {
+			if takesArg {
+				return nil, fmt.Errorf("invalid template {{%s}}: filter %s needs an argument (%s:...)", content, name, name)
+			}
+			return nil, fmt.Errorf("invalid template {{%s}}: filter %s takes no argument", content, name)
+		}
+		arg = unquoteFilterArg(strings.TrimSpace(arg))
+		if name == "first" || name == "last" {
+			if n, err := strconv.Atoi(arg); err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid template {{%s}}: %s needs a count, not %q", content, name, arg)
+			}
+		}
+		expression.filters = append(expression.filters, templateFilter{name: name, arg: arg})
+	}
+	return expression, nil
+}
+
+// evaluate resolves the expression against the variables
+func (e *templateExpression) evaluate(vars map[string]string) (string, error) {
+	value, err := e.value.eval(vars)
+	if err != nil {
+		return "", fmt.Errorf("template {{%s}}: %v", e.text, err)
+	}
+	for _, filter := range e.filters {
+		switch filter.name {
+		case "default":
+			if strings.TrimSpace(value) == "" {
+				value = filter.arg
+			}
+		case "join":
+			value = strings.Join(listValues(value), filter.arg)
+		case "first", "last":
+			values := listValues(value)
+			n, _ := strconv.Atoi(filter.arg)
+			if n < len(values) {
+				if filter.name == "first" {
+					values = values[:n]
+				} else {
+					values = values[len(values)-n:]
+				}
+			}
+			value = strings.Join(values, ",")
+		case "if":
+			if truthy(value) {
+				value = filter.arg
+			} else {
+				value = ""
+			}
+		case "else":
+			if truthy(value) {
+				value = ""
+			} else {
+				value = filter.arg
+			}
+		case "count":
+			value = strconv.Itoa(len(listValues(value)))
+		case "lower":
+			value = strings.ToLower(value)
+		case "upper":
+			value = strings.ToUpper(value)
+		}
+	}
+	return value, nil
+}
+
+// secretPrefix starts a {{secret:name}} placeholder
+const secretPrefix = "secret:"
+
+// resolveTemplate replaces the placeholders in a template string, looking {{secret:name}} up
+// with secret (nil where secrets are not allowed). omit reports that the template is a single
+// filtered expression that resolved to nothing
+func resolveTemplate(input string, vars map[string]string, secret func(name string) (string, error)) (resolved string, omit bool, err error) {
+	var builder strings.Builder
+	expressions := 0
+	rest := input
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			builder.WriteString(rest)
+			break
+		}
+		end := placeholderEnd(rest[start+2:])
+		if end < 0 {
+			builder.WriteString(rest) // Unterminated: left as written
+			break
+		}
+		builder.WriteString(rest[:start])
+		content := rest[start+2 : start+2+end]
+		placeholder := rest[start : start+2+end+2]
+		rest = rest[start+2+end+2:]
+
+		if name, isSecret := strings.CutPrefix(strings.TrimSpace(content), secretPrefix); isSecret {
+			if secret == nil {
+				return "", false, fmt.Errorf("{{%s}}: secrets are only available in tool arguments", content)
+			}
+			value, err := secret(strings.TrimSpace(name))
+			if err != nil {
+				return "", false, err
+			}
+			builder.WriteString(value)
+			continue
+		}
+		if isPlainVariable(content) {
+			if value, exists := vars[content]; exists {
+				builder.WriteString(value)
+			} else {
+				builder.WriteString(placeholder) // Not yet set (e.g. a magic variable of a later step)
+			}
+			continue
+		}
+		expression, err := parseTemplateExpression(content)
+		if err != nil {
+			return "", false, err
+		}
+		value, err := expression.evaluate(vars)
+		if err != nil {
+			return "", false, err
+		}
+		builder.WriteString(value)
+		expressions++
+	}
+
+	resolved = builder.String()
+	trimmed := strings.TrimSpace(input)
+	single := expressions == 1 && strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") &&
+		placeholderEnd(trimmed[2:]) == len(trimmed)-4
+	return resolved, single && resolved == "", nil
+}
+
+// ValidateTemplate parses every filtered placeholder of a template string, so mistakes in tool
+// YAML surface without running the tool
+func ValidateTemplate(input string) error {
+	rest := input
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return nil
+		}
+		end := placeholderEnd(rest[start+2:])
+		if end < 0 {
+			return fmt.Errorf("unterminated {{ in %q", input)
+		}
+		content := rest[start+2 : start+2+end]
+		if name, isSecret := strings.CutPrefix(strings.TrimSpace(content), secretPrefix); isSecret {
+			if !secrets.ValidName(strings.TrimSpace(name)) {
+				return fmt.Errorf("invalid template {{%s}}: secret names use lower-case letters, digits and _", content)
+			}
+		} else if !isPlainVariable(content) {
+			if _, err := parseTemplateExpression(content); err != nil {
+				return err
+			}
+		}
+		rest = rest[start+2+end+2:]
+	}
+}
+
+// placeholderEnd returns the offset of the "}}" closing a placeholder, skipping quoted filter
+// arguments, -1 if there is none
+func placeholderEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}' && i+1 < len(s) && s[i+1] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// splitFilters splits a placeholder on the "|" between filters, leaving "||" (or) and quoted
+// arguments intact
+func splitFilters(content string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '|':
+			if i+1 < len(content) && content[i+1] == '|' {
+				i++
+				continue
+			}
+			parts = append(parts, content[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, content[start:])
+}
+
+func unquoteFilterArg(arg string) string {
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') && arg[len(arg)-1] == arg[0] {
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/neur0map/ipcrawler/internal/config"
 	"github.com/neur0map/ipcrawler/internal/registry"
+	"github.com/neur0map/ipcrawler/internal/secrets"
+	"github.com/neur0map/ipcrawler/internal/session"
 )
 
 // ExecutionContext holds the runtime context for template resolution
@@ -24,11 +27,13 @@ type ExecutionContext struct {
 	OutputFile   string            // Specific output filename for this execution
 	Timestamp    string            // Execution timestamp
 	SessionID    string            // Unique session identifier
+	ScanID       string            // Unique identifier of the current run
 	ToolName     string            // Name of the tool being executed
 	Mode         string            // Execution mode (aggressive, quick_scan, etc.)
 	WorkflowName string            // Name of the workflow (for unique filenames)
 	StepName     string            // Name of the workflow step (for unique filenames)
 	CustomVars   map[string]string // Additional custom variables
+	TargetIP     bool              // {{target}} is the address a hostname target resolves to
 }
 
 // TemplateResolver resolves template variables in tool configurations
@@ -37,9 +42,13 @@ type TemplateResolver struct {
 	magicVars      map[string]string
 	magicMutex     sync.RWMutex
 	registryManager registry.RegistryManager // Optional registry for auto-detection
+	scanID         string                   // Run identifier embedded in contexts and filenames
+	rateLimit      int                      // Packets/requests per second for {{rate_limit}}
+	secrets        *secrets.Store           // Values of {{secret:name}} in tool arguments (nil = none)
+	dnsCache       *DNSCache                // Addresses of hostname targets for {{resolved_ip}}, once per run
 	
 	// Performance optimization: cache resolved arguments
-	argCache       map[string][]string  // key = toolName:mode:target, value = resolved args
+	argCache       map[string]resolvedArguments // key = toolName:mode:target
 	cacheMutex     sync.RWMutex
 }
 
@@ -48,7 +57,8 @@ func NewTemplateResolver(cfg *config.Config) *TemplateResolver {
 	return &TemplateResolver{
 		config:    cfg,
 		magicVars: make(map[string]string),
-		argCache:  make(map[string][]string),
+		dnsCache:  NewDNSCache(),
+		argCache:  make(map[string]resolvedArguments),
 	}
 }
 
@@ -57,19 +67,53 @@ func (tr *TemplateResolver) SetRegistryManager(manager registry.RegistryManager)
 	tr.registryManager = manager
 }
 
+// SetScanID sets the run identifier used for new execution contexts
+func (tr *TemplateResolver) SetScanID(scanID string) {
+	tr.scanID = scanID
+}
+
+// SetRateLimit sets the per-target rate budget exposed as {{rate_limit}}
+func (tr *TemplateResolver) SetRateLimit(rateLimit int) {
+	tr.rateLimit = rateLimit
+	tr.ClearArgumentCache()
+}
+
+// SetSecrets sets the store {{secret:name}} is resolved from
+func (tr *TemplateResolver) SetSecrets(store *secrets.Store) {
+	tr.secrets = store
+	tr.ClearArgumentCache()
+}
+
 // ResolveArguments resolves template variables in tool arguments
 func (tr *TemplateResolver) ResolveArguments(args []string, ctx *ExecutionContext) ([]string, error) {
+	resolved, err := tr.resolveArgumentTemplates(args, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.args, nil
+}
+
+// resolvedArguments are resolved arguments and the templates they came from, index for index;
+// arguments whose template resolved to nothing (see template_expressions.go) are in neither
+type resolvedArguments struct {
+	args      []string
+	templates []string
+}
+
+// resolveArgumentTemplates resolves template variables and expressions in arguments, keeping
+// the template of each resolved argument
+func (tr *TemplateResolver) resolveArgumentTemplates(args []string, ctx *ExecutionContext) (resolvedArguments, error) {
 	if ctx == nil {
-		return nil, fmt.Errorf("execution context cannot be nil")
+		return resolvedArguments{}, fmt.Errorf("execution context cannot be nil")
 	}
 
 	// Validate required context fields
 	if err := tr.validateContext(ctx); err != nil {
-		return nil, fmt.Errorf("invalid execution context: %w", err)
+		return resolvedArguments{}, fmt.Errorf("invalid execution context: %w", err)
 	}
 
 	// Generate cache key for performance optimization
-	cacheKey := fmt.Sprintf("%s:%s:%s", ctx.ToolName, ctx.Mode, ctx.Target)
+	cacheKey := fmt.Sprintf("%s:%s:%s:%t", ctx.ToolName, ctx.Mode, ctx.Target, ctx.TargetIP)
 	
 	// Check cache first (only for basic args, not with workflow context)
 	if ctx.WorkflowName == "" && ctx.StepName == "" && len(ctx.CustomVars) == 0 {
@@ -85,9 +129,22 @@ func (tr *TemplateResolver) ResolveArguments(args []string, ctx *ExecutionContex
 	vars := tr.buildVariableMap(ctx)
 
 	// Resolve each argument
-	resolved := make([]string, len(args))
-	for i, arg := range args {
-		resolved[i] = tr.resolveString(arg, vars)
+	resolved := resolvedArguments{
+		args:      make([]string, 0, len(args)),
+		templates: make([]string, 0, len(args)),
+	}
+	for _, arg := range args {
+		value, omit, err := resolveTemplate(arg, vars, func(name string) (string, error) {
+			return tr.secrets.Lookup(name, ctx.ToolName)
+		})
+		if err != nil {
+			return resolvedArguments{}, err
+		}
+		if omit {
+			continue
+		}
+		resolved.args = append(resolved.args, value)
+		resolved.templates = append(resolved.templates, arg)
 	}
 
 	// Cache result for future use (only basic contexts to avoid memory bloat)
@@ -117,6 +174,16 @@ func (tr *TemplateResolver) buildVariableMap(ctx *ExecutionContext) map[string]s
 
 	// Target-related variables
 	vars["target"] = ctx.Target
+	if ips, _ := tr.dnsCache.ResolveTarget(ctx.Target); len(ips) > 0 {
+		vars["resolved_ip"] = ips[0]
+		vars["resolved_ips"] = strings.Join(ips, ",")
+		if ctx.TargetIP {
+			vars["target"] = withTargetAddress(ctx.Target, ips[0])
+		}
+	}
+	if tr.rateLimit > 0 {
+		vars["rate_limit"] = strconv.Itoa(tr.rateLimit)
+	}
 
 	// Workspace and output directory variables
 	if ctx.Workspace != "" {
@@ -165,7 +232,12 @@ func (tr *TemplateResolver) buildVariableMap(ctx *ExecutionContext) map[string]s
 			workflowID = "_" + strings.ReplaceAll(strings.ToLower(ctx.WorkflowName), " ", "-")
 		}
 		if ctx.StepName != "" {
-			workflowID += "_" + strings.ReplaceAll(strings.ToLower(ctx.StepName), " ", "-")
+			workflowID += "_" + stepNameReplacer.Replace(strings.ToLower(ctx.StepName))
+		}
+		
+		// Scan ID keeps files from concurrent or repeated runs in the same second apart
+		if ctx.ScanID != "" {
+			timestamp += "_" + session.ShortScanID(ctx.ScanID)
 		}
 		
 		switch outputMode {
@@ -191,6 +263,9 @@ func (tr *TemplateResolver) buildVariableMap(ctx *ExecutionContext) map[string]s
 	if ctx.SessionID != "" {
 		vars["session_id"] = ctx.SessionID
 	}
+	if ctx.ScanID != "" {
+		vars["scan_id"] = ctx.ScanID
+	}
 	if ctx.Timestamp != "" {
 		vars["timestamp"] = ctx.Timestamp
 	}
@@ -234,19 +309,6 @@ func (tr *TemplateResolver) buildVariableMap(ctx *ExecutionContext) map[string]s
 	return vars
 }
 
-// resolveString resolves template variables in a single string
-func (tr *TemplateResolver) resolveString(input string, vars map[string]string) string {
-	result := input
-
-	// Replace all {{variable}} patterns
-	for varName, varValue := range vars {
-		placeholder := fmt.Sprintf("{{%s}}", varName)
-		result = strings.ReplaceAll(result, placeholder, varValue)
-	}
-
-	return result
-}
-
 // sanitizeForFilename removes or replaces characters that are problematic in filenames
 func (tr *TemplateResolver) sanitizeForFilename(input string) string {
 	// Replace common problematic characters
@@ -288,12 +350,18 @@ func (tr *TemplateResolver) GetAvailableVariables() []string {
 		"output_path_latest", // Full path to latest version (when available)
 		"timestamp",          // Execution timestamp
 		"session_id",         // Session identifier
+		"scan_id",            // Unique run identifier (UUID)
+		"rate_limit",         // This target's share of the packets/requests-per-second budget
 		"tool_name",          // Name of the tool
 		"mode",               // Execution mode
 		// Custom variables can be added via ExecutionContext.CustomVars
 	}
 }
 
+// stepNameReplacer makes step names filename-safe, including matrix instance labels
+// such as "Directory Fuzzing [wordlist=/usr/share/wordlists/common.txt]"
+var stepNameReplacer = strings.NewReplacer(" ", "-", "/", "_", "\\", "_", "[", "", "]", "", ",", "", "=", "-")
+
 // CreateExecutionContext creates a basic execution context with configurable defaults
 func (tr *TemplateResolver) CreateExecutionContext(target, toolName, mode string) *ExecutionContext {
 	return tr.CreateExecutionContextWithWorkflow(target, toolName, mode, "", "")
@@ -311,6 +379,7 @@ func (tr *TemplateResolver) CreateExecutionContextWithWorkflow(target, toolName,
 		StepName:     stepName,
 		Timestamp:    timestamp,
 		SessionID:    sessionID,
+		ScanID:       tr.scanID,
 		CustomVars:   make(map[string]string),
 	}
 }
@@ -388,7 +457,7 @@ func (tr *TemplateResolver) ClearMagicVariables() {
 func (tr *TemplateResolver) ClearArgumentCache() {
 	tr.cacheMutex.Lock()
 	defer tr.cacheMutex.Unlock()
-	tr.argCache = make(map[string][]string)
+	tr.argCache = make(map[string]resolvedArguments)
 }
 
 // MapWorkflowVariable maps a workflow variable from source to target name
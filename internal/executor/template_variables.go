@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"sort"
+	"strings"
+)
+
+// builtinVariables are set by the template resolver for every command (see buildVariableMap)
+var builtinVariables = []string{
+	"target", "resolved_ip", "resolved_ips", "workspace", "output_dir", "output_file",
+	"output_file_latest", "output_path", "output_path_latest", "logs_dir", "scans_dir",
+	"reports_dir", "raw_dir", "timestamp", "session_id", "scan_id", "rate_limit", "tool_name", "mode",
+	SmartWordlistVariable, SmartWordlistsVariable, SmartWordlistRuleVariable,
+}
+
+// runtimeVariablePrefixes start the names result combiners publish (combined_ports,
+// discovered_subdomains, http_ports, live_http_urls); parsers prefix theirs with the tool name
+var runtimeVariablePrefixes = []string{"combined_", "discovered_", "http_", "live_"}
+
+// BuiltinVariables returns the template variables every command can use
+func BuiltinVariables() []string {
+	return append([]string(nil), builtinVariables...)
+}
+
+// IsKnownVariable reports whether name is a built-in variable or is named like the variables
+// result combiners and the parsers of the given tools publish while a run progresses
+func IsKnownVariable(name string, tools []string) bool {
+	for _, builtin := range builtinVariables {
+		if name == builtin {
+			return true
+		}
+	}
+	for _, prefix := range runtimeVariablePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	for _, tool := range tools {
+		if strings.HasPrefix(name, tool+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateVariables returns the variables the {{...}} placeholders of input read, sorted.
+// Secrets and matrix references are not variables; invalid placeholders are skipped (see
+// ValidateTemplate)
+func TemplateVariables(input string) []string {
+	names := make(map[string]bool)
+	for _, content := range placeholders(input) {
+		switch {
+		case strings.HasPrefix(content, secretPrefix), matrixReferencePattern.MatchString("{{" + content + "}}"):
+		case isPlainVariable(content):
+			names[content] = true
+		default:
+			if expression, err := parseTemplateExpression(content); err == nil {
+				collectVariables(expression.value, names)
+			}
+		}
+	}
+	return sortedNames(names)
+}
+
+// placeholders returns the trimmed insides of the {{...}} placeholders of input
+func placeholders(input string) []string {
+	var contents []string
+	rest := input
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return contents
+		}
+		end := placeholderEnd(rest[start+2:])
+		if end < 0 {
+			return contents
+		}
+		contents = append(contents, strings.TrimSpace(rest[start+2:start+2+end]))
+		rest = rest[start+2+end+2:]
+	}
+}
+
+// Variables returns the variables a run_if condition reads, sorted
+func (c *Condition) Variables() []string {
+	names := make(map[string]bool)
+	collectVariables(c.root, names)
+	return sortedNames(names)
+}
+
+// collectVariables adds the variables an expression reads to names
+func collectVariables(node conditionNode, names map[string]bool) {
+	switch n := node.(type) {
+	case variableNode:
+		names[string(n)] = true
+	case *notNode:
+		collectVariables(n.operand, names)
+	case *logicalNode:
+		collectVariables(n.left, names)
+		collectVariables(n.right, names)
+	case *compareNode:
+		collectVariables(n.left, names)
+		collectVariables(n.right, names)
+	case *callNode:
+		for _, arg := range n.args {
+			collectVariables(arg, names)
+		}
+	}
+}
+
+func sortedNames(names map[string]bool) []string {
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
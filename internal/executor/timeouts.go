@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultModeTimeout bounds a run when neither the tool's timeouts nor tools.default_timeout_seconds set one
+const defaultModeTimeout = 30 * time.Minute
+
+// processWaitDelay is how long a killed tool's children may keep its output pipes open before
+// they are closed, so a wrapper script's orphaned subprocess cannot outlast the timeout
+const processWaitDelay = 2 * time.Second
+
+// DefaultTimeoutMode is the timeouts key applied to modes without their own entry
+const DefaultTimeoutMode = "default"
+
+// TimeoutFor returns a mode's timeout from the tool's timeouts, falling back to the "default"
+// entry; it returns 0 when neither is declared
+func (tc *ToolConfig) TimeoutFor(mode string) time.Duration {
+	value, exists := tc.Timeouts[mode]
+	if !exists {
+		value = tc.Timeouts[DefaultTimeoutMode]
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// validateTimeouts checks that timeouts only names declared modes and holds positive durations
+func (tc *ToolConfig) validateTimeouts() error {
+	for mode, value := range tc.Timeouts {
+		if _, exists := tc.Args[mode]; !exists && mode != DefaultTimeoutMode {
+			return fmt.Errorf("timeouts: unknown mode '%s'", mode)
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("timeouts.%s: '%s' is not a positive duration such as \"120s\"", mode, value)
+		}
+	}
+	return nil
+}
+
+// ModeTimeout returns how long one run of a tool mode may take: the tool's timeouts entry,
+// else tools.default_timeout_seconds, capped by tools.max_timeout_seconds
+func (tee *ToolExecutionEngine) ModeTimeout(toolName, mode string) time.Duration {
+	timeout := time.Duration(0)
+	if toolConfig, err := tee.GetToolConfig(toolName); err == nil {
+		timeout = toolConfig.TimeoutFor(mode)
+	}
+	if timeout == 0 {
+		timeout = defaultModeTimeout
+		if tee.globalConfig != nil && tee.globalConfig.Tools.DefaultTimeout > 0 {
+			timeout = time.Duration(tee.globalConfig.Tools.DefaultTimeout) * time.Second
+		}
+	}
+	if tee.globalConfig != nil && tee.globalConfig.Tools.MaxTimeout > 0 {
+		if ceiling := time.Duration(tee.globalConfig.Tools.MaxTimeout) * time.Second; timeout > ceiling {
+			timeout = ceiling
+		}
+	}
+	return timeout
+}
+
+// StepTimeout returns the longest run time among a step's modes, for status messages
+func (tee *ToolExecutionEngine) StepTimeout(step *WorkflowStep) time.Duration {
+	var longest time.Duration
+	for _, mode := range step.Modes {
+		if timeout := tee.ModeTimeout(step.Tool, mode); timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest
+}
+
+// stepStartedMessage announces a step with its timeout, so status consumers know how long it may run
+func (wo *WorkflowOrchestrator) stepStartedMessage(stepIndex int, workflow *Workflow, step *WorkflowStep) string {
+	message := fmt.Sprintf("Started step %d/%d: %s", stepIndex+1, len(workflow.Steps), step.Name)
+	if wo.executor == nil || wo.executor.engine == nil || step.Tool == "" {
+		return message
+	}
+	timeout := wo.executor.engine.StepTimeout(step)
+	if timeout == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s (each run times out after %s)", message, timeout)
+}
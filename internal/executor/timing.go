@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Durations are always measured against the monotonic clock reading captured when
+// work starts. Timestamps stored in results are wall-clock values converted to the
+// engagement timezone, which also strips the monotonic reading so records never
+// feed back into duration math.
+
+// LoadEngagementLocation resolves the configured engagement timezone (IANA name).
+// An empty name or "Local" uses the host timezone
+func LoadEngagementLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local, fmt.Errorf("invalid timezone '%s': %w", name, err)
+	}
+	return location, nil
+}
+
+// wallTime converts t to the given location for recording in results
+func wallTime(t time.Time, location *time.Location) time.Time {
+	if location == nil {
+		location = time.Local
+	}
+	return t.In(location)
+}
+
+// finishResult stamps the end time and computes the duration from the monotonic start
+func (tee *ToolExecutionEngine) finishResult(result *ExecutionResult, startTime time.Time) {
+	result.EndTime = wallTime(time.Now(), tee.location)
+	result.Duration = time.Since(startTime)
+	result.ErrorMessage = tee.redactor.Redact(result.ErrorMessage)
+}
+
+// wallNow returns the current wall-clock time in the engagement timezone
+func (wo *WorkflowOrchestrator) wallNow() time.Time {
+	return wo.wallTime(time.Now())
+}
+
+// wallTime converts t to the engagement timezone for recording; the result has no monotonic
+// reading, so durations are measured from the original t
+func (wo *WorkflowOrchestrator) wallTime(t time.Time) time.Time {
+	var location *time.Location
+	if wo.executor != nil && wo.executor.engine != nil {
+		location = wo.executor.engine.GetLocation()
+	}
+	return wallTime(t, location)
+}
+
+// finishExecution stamps a workflow's end time and measures its duration from the monotonic start
+func (wo *WorkflowOrchestrator) finishExecution(execution *WorkflowExecution) {
+	execution.EndTime = wo.wallTime(time.Now())
+	execution.Duration = time.Since(execution.started)
+}
@@ -8,6 +8,8 @@ import (
 	"sync"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/neur0map/ipcrawler/internal/parsers"
 )
 
 // ToolConfig represents a tool configuration loaded from tools/*/config.yaml
@@ -22,6 +24,49 @@ type ToolConfig struct {
 	// Output configuration for separator display
 	ShowSeparator     bool `yaml:"show_separator"`     // Whether to show visual separator for this tool
 	SeparatorPriority int  `yaml:"separator_priority"` // Priority for separator display (higher = shown first)
+	
+	// Modes that need raw sockets and keep root when privileges are dropped
+	PrivilegedModes   []string `yaml:"privileged_modes"`
+	
+	// Mode run instead of a privileged mode when IPCrawler is not root (e.g. syn_scan: tcp_connect_scan)
+	UnprivilegedModes map[string]string `yaml:"unprivileged_modes"`
+	
+	// Output parser for magic variables (e.g. "json_lines", "nmap_xml"); see internal/parsers
+	Parser            string `yaml:"parser"`
+	
+	// Artifact each mode must produce, checked by validate_output and on timeouts (keyed by mode or "default")
+	ExpectedOutputs   map[string]ExpectedOutput `yaml:"expected_outputs"`
+	
+	// Arguments that make the tool print its version (recorded in the run manifest)
+	VersionArgs       []string `yaml:"version_args"`
+	
+	// Supported versions of the installed tool; a bare major version as max_version allows any minor of it
+	MinVersion        string `yaml:"min_version"`
+	MaxVersion        string `yaml:"max_version"`
+	
+	// How long one run of each mode may take, e.g. fast_scan: "120s" (keyed by mode or "default")
+	Timeouts          map[string]string `yaml:"timeouts"`
+	
+	// Which exit codes mean success, warning or failure (keyed by mode or "default")
+	ExitCodes         map[string]ExitCodeMap `yaml:"exit_codes"`
+	
+	// Install commands shown by `ipcrawler doctor`, keyed by OS (linux, darwin, windows) or "any"
+	Install           map[string][]string `yaml:"install"`
+	
+	// Flags each --pacing preset sets, keyed by preset then flag, e.g. sneaky: {"-T": "1"}
+	Pacing            map[string]map[string]string `yaml:"pacing"`
+	
+	// Runs IPCrawler's own binary instead of an external one; args start with its subcommand
+	Builtin           bool `yaml:"builtin"`
+	
+	// IP-oriented tool: {{target}} is the address a hostname target resolved to (once per run)
+	TargetIP          bool `yaml:"target_ip"`
+}
+
+// InstallHintsFor returns the install commands for an OS followed by those for any OS
+func (tc *ToolConfig) InstallHintsFor(goos string) []string {
+	hints := append([]string(nil), tc.Install[goos]...)
+	return append(hints, tc.Install["any"]...)
 }
 
 // ToolConfigLoader loads and manages tool configurations
@@ -72,6 +117,30 @@ func (tcl *ToolConfigLoader) LoadToolConfig(toolName string) (*ToolConfig, error
 	if config.Tool == "" {
 		config.Tool = toolName // Default to directory name if not specified
 	}
+	if config.Parser != "" {
+		if _, exists := parsers.Lookup(config.Parser); !exists {
+			return nil, fmt.Errorf("tool config %s: unknown parser '%s' (available: %s)",
+				configPath, config.Parser, strings.Join(parsers.Names(), ", "))
+		}
+	}
+	if err := config.validateExpectedOutputs(); err != nil {
+		return nil, fmt.Errorf("tool config %s: %w", configPath, err)
+	}
+	if err := config.validateUnprivilegedModes(); err != nil {
+		return nil, fmt.Errorf("tool config %s: %w", configPath, err)
+	}
+	if err := config.validateTimeouts(); err != nil {
+		return nil, fmt.Errorf("tool config %s: %w", configPath, err)
+	}
+	if err := config.validateExitCodes(); err != nil {
+		return nil, fmt.Errorf("tool config %s: %w", configPath, err)
+	}
+	if err := config.validateVersionRange(); err != nil {
+		return nil, fmt.Errorf("tool config %s: %w", configPath, err)
+	}
+	if err := config.validateArgumentTemplates(); err != nil {
+		return nil, fmt.Errorf("tool config %s: %w", configPath, err)
+	}
 
 	// Cache the config (write lock)
 	tcl.mutex.Lock()
@@ -158,3 +227,47 @@ func (tc *ToolConfig) GetAvailableModes() []string {
 	return modes
 }
 
+
+// RequiresPrivileges reports whether a mode needs root (raw sockets) to run
+func (tc *ToolConfig) RequiresPrivileges(mode string) bool {
+	for _, privilegedMode := range tc.PrivilegedModes {
+		if privilegedMode == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// UnprivilegedAlternative returns the mode to run instead of a privileged mode without root
+func (tc *ToolConfig) UnprivilegedAlternative(mode string) (string, bool) {
+	alternative, exists := tc.UnprivilegedModes[mode]
+	return alternative, exists && alternative != ""
+}
+
+// validateArgumentTemplates parses the filtered placeholders ({{value|filter}}) of every mode
+func (tc *ToolConfig) validateArgumentTemplates() error {
+	for mode, args := range tc.Args {
+		for _, arg := range args {
+			if err := ValidateTemplate(arg); err != nil {
+				return fmt.Errorf("args.%s: %w", mode, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateUnprivilegedModes checks that each mapping replaces a privileged mode with an existing unprivileged one
+func (tc *ToolConfig) validateUnprivilegedModes() error {
+	for mode, alternative := range tc.UnprivilegedModes {
+		if !tc.RequiresPrivileges(mode) {
+			return fmt.Errorf("unprivileged_modes: '%s' is not listed in privileged_modes", mode)
+		}
+		if _, exists := tc.Args[alternative]; !exists {
+			return fmt.Errorf("unprivileged_modes: '%s' maps to unknown mode '%s'", mode, alternative)
+		}
+		if tc.RequiresPrivileges(alternative) {
+			return fmt.Errorf("unprivileged_modes: '%s' maps to '%s', which also requires privileges", mode, alternative)
+		}
+	}
+	return nil
+}
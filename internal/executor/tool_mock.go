@@ -0,0 +1,27 @@
+package executor
+
+// ToolCall is one tool execution handed to a ToolMock, with its arguments fully resolved
+type ToolCall struct {
+	Tool     string
+	Mode     string
+	Workflow string
+	Step     string
+	Args     []string
+	Outputs  []string // Files the arguments tell the tool to write, in argument order
+}
+
+// ToolMock returns the command that runs instead of a tool: an executable and its arguments.
+// It stands in for every tool, so a mocked run never reaches a real target (ipcrawler workflow test)
+type ToolMock func(call ToolCall) []string
+
+// SetToolMock runs mock's commands instead of the tools. Version checks are skipped, since no
+// tool runs
+func (tee *ToolExecutionEngine) SetToolMock(mock ToolMock) {
+	tee.toolMock = mock
+}
+
+// mockedCommand returns the executable and arguments the tool mock runs for a call
+func (tee *ToolExecutionEngine) mockedCommand(call ToolCall) (string, []string) {
+	command := tee.toolMock(call)
+	return command[0], command[1:]
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToolParameterBuilder defines the interface for translating workflow step parameters
+// into tool-specific command-line flags. Each tool implements this in its own isolated package
+type ToolParameterBuilder interface {
+	BuildArguments(params map[string]string) ([]string, error)
+	GetToolName() string
+}
+
+// ToolExclusionBuilder is implemented by parameter builders whose tool has a native
+// host exclusion flag (e.g. nmap --exclude)
+type ToolExclusionBuilder interface {
+	ExclusionArguments(entries []string) []string
+}
+
+// ToolParameterKeys is implemented by parameter builders that can list the step parameters
+// they accept, so they can be offered for editing before a run
+type ToolParameterKeys interface {
+	ParameterKeys() []string
+}
+
+// ToolParameterManager routes workflow step parameters to the registered tool builder.
+// This is generic code with NO tool-specific logic.
+type ToolParameterManager struct {
+	builders map[string]ToolParameterBuilder
+}
+
+// NewToolParameterManager creates a new tool parameter manager
+func NewToolParameterManager() *ToolParameterManager {
+	return &ToolParameterManager{
+		builders: make(map[string]ToolParameterBuilder),
+	}
+}
+
+// RegisterBuilder registers a tool-specific parameter builder
+func (tpm *ToolParameterManager) RegisterBuilder(builder ToolParameterBuilder) {
+	toolName := strings.ToLower(builder.GetToolName())
+	tpm.builders[toolName] = builder
+}
+
+// BuildArguments converts step parameters into extra arguments for the given tool
+func (tpm *ToolParameterManager) BuildArguments(toolName string, params map[string]string) ([]string, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	builder, exists := tpm.builders[strings.ToLower(toolName)]
+	if !exists {
+		return nil, fmt.Errorf("tool '%s' does not support workflow parameters", toolName)
+	}
+
+	return builder.BuildArguments(params)
+}
+
+// HasBuilder checks if a parameter builder is registered for the given tool
+func (tpm *ToolParameterManager) HasBuilder(toolName string) bool {
+	_, exists := tpm.builders[strings.ToLower(toolName)]
+	return exists
+}
+
+// ParameterKeys returns the step parameters a tool accepts, or nil when it accepts none or
+// its builder cannot list them
+func (tpm *ToolParameterManager) ParameterKeys(toolName string) []string {
+	builder, exists := tpm.builders[strings.ToLower(toolName)]
+	if !exists {
+		return nil
+	}
+	if keyed, ok := builder.(ToolParameterKeys); ok {
+		return keyed.ParameterKeys()
+	}
+	return nil
+}
+
+// BuildExclusionArguments returns the native exclusion flags for a tool.
+// The boolean is false when the tool has no native exclusion support
+func (tpm *ToolParameterManager) BuildExclusionArguments(toolName string, entries []string) ([]string, bool) {
+	builder, exists := tpm.builders[strings.ToLower(toolName)]
+	if !exists {
+		return nil, false
+	}
+
+	exclusionBuilder, ok := builder.(ToolExclusionBuilder)
+	if !ok {
+		return nil, false
+	}
+
+	return exclusionBuilder.ExclusionArguments(entries), true
+}
@@ -1,8 +1,17 @@
 package executor
 
 import (
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/parsers"
+	"github.com/neur0map/ipcrawler/internal/tools/amass"
+	"github.com/neur0map/ipcrawler/internal/tools/httpx"
+	"github.com/neur0map/ipcrawler/internal/tools/masscan"
 	"github.com/neur0map/ipcrawler/internal/tools/naabu"
 	"github.com/neur0map/ipcrawler/internal/tools/nmap"
+	"github.com/neur0map/ipcrawler/internal/tools/nslookup"
+	"github.com/neur0map/ipcrawler/internal/tools/passive"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+	"github.com/neur0map/ipcrawler/internal/tools/subfinder"
 )
 
 // RegisterAllParsers registers all available tool output parsers
@@ -14,8 +23,76 @@ func RegisterAllParsers(manager *MagicVariableManager) {
 	
 	// Register nmap parser
 	manager.RegisterParser(&nmap.OutputParser{})
+	
+	// Register masscan parser
+	manager.RegisterParser(&masscan.OutputParser{})
+	
+	// Register httpx parser
+	manager.RegisterParser(&httpx.OutputParser{})
+	
+	// Register subdomain enumeration parsers
+	manager.RegisterParser(&subfinder.OutputParser{})
+	manager.RegisterParser(&amass.OutputParser{})
+	manager.RegisterParser(&passive.OutputParser{})
+
+	// Expose tool parsers by output format so other tools can declare them
+	// in their config.yaml (e.g. "parser: nmap_xml")
+	parsers.Register(parsers.Adapt("naabu_json", (&naabu.OutputParser{}).ParseOutput))
+	parsers.Register(parsers.Adapt("nmap_xml", (&nmap.OutputParser{}).ParseOutput))
+	parsers.Register(parsers.Adapt("masscan_json", (&masscan.OutputParser{}).ParseOutput))
+	parsers.Register(parsers.Adapt("httpx_json", (&httpx.OutputParser{}).ParseOutput))
+	parsers.Register(parsers.Adapt("subfinder_json", (&subfinder.OutputParser{}).ParseOutput))
+	parsers.Register(parsers.Adapt("amass_text", (&amass.OutputParser{}).ParseOutput))
+	parsers.Register(parsers.Adapt("passive_json", (&passive.OutputParser{}).ParseOutput))
+}
+
+// RegisterAllParameterBuilders registers all available tool parameter builders
+// Tools without a builder reject workflow step parameters
+func RegisterAllParameterBuilders(manager *ToolParameterManager) {
+	// Register nmap stealth/evasion parameter builder
+	manager.RegisterBuilder(&nmap.ParameterBuilder{})
+	
+	// Register naabu builder (host exclusion only)
+	manager.RegisterBuilder(&naabu.ParameterBuilder{})
+	
+	// Register masscan builder (host exclusion only)
+	manager.RegisterBuilder(&masscan.ParameterBuilder{})
+}
+
+// RegisterAllFindingsExtractors registers all available tool findings extractors
+// used to query workspace results after a scan
+func RegisterAllFindingsExtractors(catalog *findings.Catalog) {
+	// Register naabu extractor
+	catalog.Register(&naabu.FindingsExtractor{})
+	
+	// Register nmap extractor
+	catalog.Register(&nmap.FindingsExtractor{})
+	
+	// Register masscan extractor
+	catalog.Register(&masscan.FindingsExtractor{})
+	
+	// Register httpx extractor
+	catalog.Register(&httpx.FindingsExtractor{})
+	
+	// Register subdomain enumeration extractors
+	catalog.Register(&subfinder.FindingsExtractor{})
+	catalog.Register(&amass.FindingsExtractor{})
+	catalog.Register(&passive.FindingsExtractor{})
+}
+
+// SubdomainReaders returns the record readers of the subdomain enumeration tools, keyed by
+// tool name; their result combiner and workspace diffs read the same records
+func SubdomainReaders() map[string]subdomains.Reader {
+	return map[string]subdomains.Reader{
+		"subfinder": subfinder.ReadRecords,
+		"amass":     amass.ReadRecords,
+		"passive":   passive.ReadRecords,
+	}
+}
 
-	// Future parsers can be added here:
-	// manager.RegisterParser(&subfinder.OutputParser{})
-	// manager.RegisterParser(&httpx.OutputParser{})
-}
\ No newline at end of file
+// DNSRecordReaders returns the record readers of the DNS lookup tools, keyed by tool name
+func DNSRecordReaders() map[string]func(outputPath string) ([]nslookup.Record, error) {
+	return map[string]func(outputPath string) ([]nslookup.Record, error){
+		"nslookup": nslookup.ReadRecords,
+	}
+}
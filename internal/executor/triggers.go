@@ -0,0 +1,246 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// defaultTriggerTarget is the target a triggered workflow runs against unless the trigger names one
+const defaultTriggerTarget = "{{host}}:{{port}}"
+
+// WorkflowTrigger queues its workflow when a step discovers a matching open port.
+// When is a run_if expression over the port's fields: host, port, protocol, service,
+// product, version, tls and tool; e.g. `service == "http" || port == 8080`
+type WorkflowTrigger struct {
+	When   string
+	Target string // Target template for the queued workflow (default "{{host}}:{{port}}")
+}
+
+// TriggeredWorkflow is a workflow to queue for a discovered service
+type TriggeredWorkflow struct {
+	Workflow *Workflow
+	Target   string
+	Finding  findings.Finding
+}
+
+// triggerRule is one parsed trigger of a workflow
+type triggerRule struct {
+	workflow  *Workflow
+	trigger   WorkflowTrigger
+	condition *Condition
+}
+
+// TriggerEngine matches the open ports found by completed steps against workflow triggers.
+// Each workflow is queued at most once per target for the lifetime of the engine
+type TriggerEngine struct {
+	rules   []triggerRule
+	catalog *findings.Catalog
+	engine  *ToolExecutionEngine
+	fired   map[string]bool
+	mutex   sync.Mutex
+}
+
+// NewTriggerEngine collects the triggers declared by the given workflows; it returns nil if there are none
+func NewTriggerEngine(workflows map[string]*Workflow, engine *ToolExecutionEngine) (*TriggerEngine, error) {
+	var rules []triggerRule
+	for _, workflow := range workflows {
+		for _, trigger := range workflow.Triggers {
+			condition, err := ParseCondition(trigger.When)
+			if err != nil {
+				return nil, fmt.Errorf("workflow '%s' trigger: %v", workflow.Name, err)
+			}
+			if trigger.Target == "" {
+				trigger.Target = defaultTriggerTarget
+			}
+			rules = append(rules, triggerRule{workflow: workflow, trigger: trigger, condition: condition})
+		}
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	catalog := findings.NewCatalog()
+	RegisterAllFindingsExtractors(catalog)
+	return &TriggerEngine{
+		rules:   rules,
+		catalog: catalog,
+		engine:  engine,
+		fired:   make(map[string]bool),
+	}, nil
+}
+
+// ValidateTriggers parses every trigger of a workflow so that mistakes surface when it loads
+func ValidateTriggers(workflow *Workflow) error {
+	for _, trigger := range workflow.Triggers {
+		if _, err := ParseCondition(trigger.When); err != nil {
+			return fmt.Errorf("trigger: %v", err)
+		}
+	}
+	return nil
+}
+
+// Match returns the workflows to queue for the open ports found in a step's outputs.
+// A workflow that already fired for a target is not returned again
+func (te *TriggerEngine) Match(result *WorkflowResult, sourceTarget string) []TriggeredWorkflow {
+	var matches []TriggeredWorkflow
+	for _, finding := range stepFindings(te.engine, te.catalog, result) {
+		if finding.Port == 0 || (finding.State != "" && finding.State != "open") {
+			continue
+		}
+		if finding.Host == "" {
+			finding.Host = sourceTarget
+		}
+		vars := findingVariables(finding)
+
+		for _, rule := range te.rules {
+			matched, err := rule.condition.Evaluate(vars)
+			if err != nil {
+				te.engine.debugLogger.Warn("Trigger condition failed", "workflow", rule.workflow.Name, "error", err)
+				continue
+			}
+			if !matched {
+				continue
+			}
+			target := rule.trigger.Target
+			for name, value := range vars {
+				target = strings.ReplaceAll(target, "{{"+name+"}}", value)
+			}
+			if te.markFired(rule.workflow.Name, target) {
+				matches = append(matches, TriggeredWorkflow{Workflow: rule.workflow, Target: target, Finding: finding})
+			}
+		}
+	}
+	return matches
+}
+
+// describe names the workflow and port that caused the trigger, e.g. "Port Scan: 80/tcp http on 10.0.0.5"
+func (tw TriggeredWorkflow) describe(sourceWorkflow string) string {
+	description := fmt.Sprintf("%s: %d/%s", sourceWorkflow, tw.Finding.Port, tw.Finding.Protocol)
+	if tw.Finding.Service != "" {
+		description += " " + tw.Finding.Service
+	}
+	return description + " on " + tw.Finding.Host
+}
+
+// markFired records a workflow/target pair and reports whether it is new
+func (te *TriggerEngine) markFired(workflowName, target string) bool {
+	te.mutex.Lock()
+	defer te.mutex.Unlock()
+	key := workflowName + "\x00" + target
+	if te.fired[key] {
+		return false
+	}
+	te.fired[key] = true
+	return true
+}
+
+// stepFindings extracts findings from the output files of a step's executions
+func stepFindings(engine *ToolExecutionEngine, catalog *findings.Catalog, result *WorkflowResult) []findings.Finding {
+	var found []findings.Finding
+	for _, execution := range result.Results {
+		if execution == nil || !execution.Success || execution.OutputPath == "" {
+			continue
+		}
+		outputPath := outputFile(engine, execution)
+		extractor, exists := catalog.ExtractorFor(outputPath)
+		if !exists {
+			continue
+		}
+		list, err := extractor.ExtractFindings(outputPath)
+		if err != nil {
+			engine.debugLogger.Debug("Could not read tool output", "path", outputPath, "error", err)
+			continue
+		}
+		for i := range list {
+			list[i].Tool = extractor.GetToolName()
+			list[i].Source = filepath.Join("scans", filepath.Base(outputPath))
+		}
+		found = append(found, list...)
+	}
+	return found
+}
+
+// outputFile returns the artifact an execution wrote: its declared expected output, or the
+// first of output_path, .xml and .json that exists
+func outputFile(engine *ToolExecutionEngine, execution *ExecutionResult) string {
+	if toolConfig, err := engine.GetToolConfig(execution.ToolName); err == nil {
+		if expected, declared := toolConfig.ExpectedOutputFor(execution.Mode); declared {
+			return expected.Path(execution.OutputPath)
+		}
+	}
+	for _, path := range []string{execution.OutputPath, execution.OutputPath + ".xml", execution.OutputPath + ".json"} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return execution.OutputPath
+}
+
+// findingVariables exposes a finding's fields to trigger conditions and target templates
+func findingVariables(finding findings.Finding) map[string]string {
+	return map[string]string{
+		"host":     finding.Host,
+		"port":     strconv.Itoa(finding.Port),
+		"protocol": finding.Protocol,
+		"service":  finding.Service,
+		"product":  finding.Product,
+		"version":  finding.Version,
+		"tls":      strconv.FormatBool(finding.TLS),
+		"tool":     finding.Tool,
+	}
+}
+
+// SetTriggerEngine enables reactive workflows: services discovered by any step queue the
+// workflows whose triggers match them
+func (wo *WorkflowOrchestrator) SetTriggerEngine(triggers *TriggerEngine) {
+	wo.mutex.Lock()
+	defer wo.mutex.Unlock()
+	wo.triggers = triggers
+}
+
+// fireTriggers queues and starts the workflows triggered by a finished step
+func (wo *WorkflowOrchestrator) fireTriggers(ctx context.Context, source *WorkflowQueueItem, result *WorkflowResult) {
+	if wo.triggers == nil || result.Skipped || ctx.Err() != nil {
+		return
+	}
+	matches := wo.triggers.Match(result, source.Target)
+	if len(matches) == 0 {
+		return
+	}
+
+	wo.mutex.Lock()
+	for _, match := range matches {
+		triggeredBy := match.describe(source.Workflow.Name)
+		wo.debugLogger.Printf("Trigger queued workflow: %s for target: %s (%s)", match.Workflow.Name, match.Target, triggeredBy)
+		wo.insertByPriority(&WorkflowQueueItem{
+			Workflow:     match.Workflow,
+			Target:       match.Target,
+			Priority:     wo.calculatePriority(match.Workflow),
+			QueueTime:    time.Now(),
+			Dependencies: wo.extractDependencies(match.Workflow),
+			TriggeredBy:  triggeredBy,
+		})
+	}
+	wo.saveQueueState()
+	wo.mutex.Unlock()
+
+	// Report the new workflows before they start, outside the lock like other status updates
+	for _, match := range matches {
+		triggeredBy := match.describe(source.Workflow.Name)
+		wo.events.Publish(Event{Type: EventWorkflowTriggered, Workflow: match.Workflow.Name, Target: match.Target,
+			StepCount: len(match.Workflow.Steps), TriggeredBy: triggeredBy, Message: "Queued by " + triggeredBy})
+	}
+
+	wo.mutex.Lock()
+	wo.startQueuedWorkflows(ctx)
+	wo.saveQueueState()
+	wo.mutex.Unlock()
+}
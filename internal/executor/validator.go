@@ -2,12 +2,15 @@ package executor
 
 import (
 	"fmt"
+	"net"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/tools/nmap"
 )
 
 // SecurityValidator handles security validation for tool execution
@@ -134,6 +137,198 @@ func (sv *SecurityValidator) ValidateExecutable(executablePath string) error {
 	return nil
 }
 
+// outputPathVariables are the template variables that build paths inside the workspace
+var outputPathVariables = []string{
+	"{{workspace}}", "{{output_dir}}", "{{scans_dir}}", "{{logs_dir}}", "{{reports_dir}}", "{{raw_dir}}",
+	"{{output_path}}", "{{output_path_latest}}", "{{output_file}}", "{{output_file_latest}}",
+}
+
+// outputFlags are flags whose next argument is a file the tool writes
+var outputFlags = map[string]bool{
+	"-o": true, "-oN": true, "-oX": true, "-oG": true, "-oA": true, "-oJ": true,
+	"-output": true, "--output": true, "-oL": true, "--output-file": true,
+}
+
+// ValidateOutputPaths checks that every path a tool writes stays inside the workspace or one of
+// allowed_output_dirs. Tool YAML is user-editable and tools may run as root, so a ../ in a file
+// name or an absolute path after -o would otherwise write anywhere. Output paths are arguments
+// built from the workspace variables and the argument after an output flag
+func (sv *SecurityValidator) ValidateOutputPaths(workspace string, argsTemplate, resolvedArgs []string) error {
+	for _, path := range outputPathArguments(argsTemplate, resolvedArgs) {
+		if err := sv.ValidateOutputPath(workspace, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputPathArguments returns the output paths among resolved arguments
+func outputPathArguments(argsTemplate, resolvedArgs []string) []string {
+	var paths []string
+	for i, template := range argsTemplate {
+		if i >= len(resolvedArgs) {
+			break
+		}
+		path := ""
+		if start := firstOutputVariable(template); start >= 0 && strings.HasPrefix(resolvedArgs[i], template[:start]) {
+			path = resolvedArgs[i][start:] // Drops a literal prefix like --output=
+		} else if i > 0 && outputFlags[argsTemplate[i-1]] {
+			path = resolvedArgs[i]
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ValidateOutputPath checks that one output path stays inside the workspace or one of
+// allowed_output_dirs once relative parts and symlinked directories are resolved
+func (sv *SecurityValidator) ValidateOutputPath(workspace, path string) error {
+	if path == "-" {
+		return nil // Standard output
+	}
+	resolved, err := resolveOutputPath(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path %s: %w", path, err)
+	}
+	roots := append([]string{workspace}, sv.config.Security.Execution.AllowedOutputDirs...)
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		resolvedRoot, err := resolveOutputPath(root)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(resolvedRoot, resolved); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("output path %s is outside the workspace %s (allowed_output_dirs lists other directories)", path, workspace)
+}
+
+// resolveOutputPath makes a path absolute and resolves symlinks in the part of it that exists,
+// so a symlinked directory inside the workspace cannot point the output elsewhere
+func resolveOutputPath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	existing, rest := absPath, ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return absPath, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
+
+// firstOutputVariable returns where the first workspace path variable starts in an argument
+// template, -1 if it has none
+func firstOutputVariable(template string) int {
+	first := -1
+	for _, variable := range outputPathVariables {
+		if index := strings.Index(template, variable); index >= 0 && (first < 0 || index < first) {
+			first = index
+		}
+	}
+	return first
+}
+
+// ValidateStepParameters validates workflow step parameters against the evasion policy
+func (sv *SecurityValidator) ValidateStepParameters(params map[string]string) error {
+	policy := sv.config.Security.Evasion
+
+	for key, rawValue := range params {
+		value := strings.TrimSpace(rawValue)
+		if value == "" {
+			continue
+		}
+
+		switch key {
+		case "decoys":
+			if !policy.AllowDecoys {
+				return fmt.Errorf("decoys are disabled by evasion policy (allow_decoys)")
+			}
+			count, err := countDecoys(value)
+			if err != nil {
+				return err
+			}
+			if policy.MaxDecoys > 0 && count > policy.MaxDecoys {
+				return fmt.Errorf("too many decoys: %d > %d (max_decoys)", count, policy.MaxDecoys)
+			}
+		case "fragment":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid fragment value '%s': must be true or false", value)
+			}
+			if enabled && !policy.AllowFragmentation {
+				return fmt.Errorf("fragmentation is disabled by evasion policy (allow_fragmentation)")
+			}
+		case "mtu":
+			if !policy.AllowFragmentation {
+				return fmt.Errorf("custom MTU is disabled by evasion policy (allow_fragmentation)")
+			}
+			mtu, err := strconv.Atoi(value)
+			if err != nil || mtu <= 0 || mtu%8 != 0 {
+				return fmt.Errorf("invalid mtu '%s': must be a positive multiple of 8", value)
+			}
+		case "source_port":
+			if !policy.AllowSourcePort {
+				return fmt.Errorf("source port spoofing is disabled by evasion policy (allow_source_port)")
+			}
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("invalid source_port '%s': must be between 1 and 65535", value)
+			}
+		case "timing":
+			level, err := nmap.ParseTimingTemplate(value)
+			if err != nil {
+				return err
+			}
+			if level < policy.MinTiming || (policy.MaxTiming > 0 && level > policy.MaxTiming) {
+				return fmt.Errorf("timing template T%d outside allowed range T%d-T%d (min_timing/max_timing)",
+					level, policy.MinTiming, policy.MaxTiming)
+			}
+		}
+	}
+
+	return nil
+}
+
+// countDecoys validates a decoy list and returns the number of decoys it generates
+func countDecoys(value string) (int, error) {
+	count := 0
+	for _, decoy := range strings.Split(value, ",") {
+		decoy = strings.TrimSpace(decoy)
+		upper := strings.ToUpper(decoy)
+		switch {
+		case upper == "ME":
+			// The real scanner address does not count as a decoy
+		case strings.HasPrefix(upper, "RND:"):
+			n, err := strconv.Atoi(decoy[4:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid random decoy count '%s'", decoy)
+			}
+			count += n
+		case upper == "RND":
+			count++
+		case net.ParseIP(decoy) != nil:
+			count++
+		default:
+			return 0, fmt.Errorf("invalid decoy '%s': must be an IP address, ME, or RND[:n]", decoy)
+		}
+	}
+	return count, nil
+}
+
 // checkShellMetacharacters checks for dangerous shell metacharacters
 func (sv *SecurityValidator) checkShellMetacharacters(arg string) error {
 	// Common shell metacharacters that could be dangerous
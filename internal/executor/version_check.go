@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// What happens when an installed tool is outside its supported version range (tools.yaml version_policy)
+const (
+	VersionPolicyWarn   = "warn"   // Run the tool and report a version_unsupported warning (default)
+	VersionPolicyRefuse = "refuse" // Fail the tool's executions without running them
+	VersionPolicyOff    = "off"    // Do not check versions
+)
+
+// ValidateVersionPolicy checks a tools.yaml version_policy value
+func ValidateVersionPolicy(policy string) error {
+	switch policy {
+	case "", VersionPolicyWarn, VersionPolicyRefuse, VersionPolicyOff:
+		return nil
+	default:
+		return fmt.Errorf("invalid version_policy '%s': must be %s, %s or %s", policy, VersionPolicyWarn, VersionPolicyRefuse, VersionPolicyOff)
+	}
+}
+
+// versionCheckTimeout bounds how long a tool's version command may take
+const versionCheckTimeout = 5 * time.Second
+
+// versionNumberPattern finds the first dotted version number in a tool's version output
+var versionNumberPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// UnsupportedVersionError reports an installed tool outside its config's min_version/max_version
+type UnsupportedVersionError struct {
+	Tool      string
+	Installed string
+	Min       string
+	Max       string
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%s %s is not supported (supported: %s)", e.Tool, e.Installed, versionRange(e.Min, e.Max))
+}
+
+// versionCheck is the cached result of checking one tool's version
+type versionCheck struct {
+	version string
+	err     error
+	warned  bool
+}
+
+// versionRange describes min/max for messages, e.g. ">= 7.80, <= 7.x"
+func versionRange(min, max string) string {
+	var parts []string
+	if min != "" {
+		parts = append(parts, ">= "+min)
+	}
+	if max != "" {
+		if strings.Count(max, ".") == 0 {
+			max += ".x"
+		}
+		parts = append(parts, "<= "+max)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseVersion returns the numeric components of the first version number in s
+func parseVersion(s string) ([]int, bool) {
+	match := versionNumberPattern.FindString(s)
+	if match == "" {
+		// A bare number is only accepted when it is the whole value (e.g. max_version: "2")
+		if _, err := strconv.Atoi(strings.TrimSpace(s)); err != nil {
+			return nil, false
+		}
+		match = strings.TrimSpace(s)
+	}
+	fields := strings.Split(match, ".")
+	version := make([]int, len(fields))
+	for i, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		version[i] = number
+	}
+	return version, true
+}
+
+// compareVersions compares a with b over b's components only, so b = [2] matches any 2.x.y.
+// Missing components of a count as 0
+func compareVersions(a, b []int) int {
+	for i, want := range b {
+		have := 0
+		if i < len(a) {
+			have = a[i]
+		}
+		if have != want {
+			if have < want {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// validateVersionRange checks that min_version and max_version parse and are in order
+func (tc *ToolConfig) validateVersionRange() error {
+	var min, max []int
+	var ok bool
+	if tc.MinVersion != "" {
+		if min, ok = parseVersion(tc.MinVersion); !ok {
+			return fmt.Errorf("invalid min_version %q", tc.MinVersion)
+		}
+	}
+	if tc.MaxVersion != "" {
+		if max, ok = parseVersion(tc.MaxVersion); !ok {
+			return fmt.Errorf("invalid max_version %q", tc.MaxVersion)
+		}
+	}
+	if min != nil && max != nil && compareVersions(min, max) > 0 {
+		return fmt.Errorf("min_version %s is above max_version %s", tc.MinVersion, tc.MaxVersion)
+	}
+	if (min != nil || max != nil) && len(tc.VersionArgs) == 0 {
+		return fmt.Errorf("min_version/max_version need version_args to read the installed version")
+	}
+	return nil
+}
+
+// SupportsVersion reports whether version (any text containing a version number) is within
+// the config's min_version/max_version
+func (tc *ToolConfig) SupportsVersion(version string) (bool, error) {
+	installed, ok := parseVersion(version)
+	if !ok {
+		return false, fmt.Errorf("no version number in %q", version)
+	}
+	if min, ok := parseVersion(tc.MinVersion); ok && compareVersions(installed, min) < 0 {
+		return false, nil
+	}
+	if max, ok := parseVersion(tc.MaxVersion); ok && compareVersions(installed, max) > 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// CheckToolVersion runs the tool's version_args and checks the version against its
+// min_version/max_version, returning the version it found. An *UnsupportedVersionError means
+// the tool is outside the range; other errors mean the version could not be determined.
+// Tools without a range are not run. Results are cached for the engine's lifetime
+func (tee *ToolExecutionEngine) CheckToolVersion(ctx context.Context, toolName string) (string, error) {
+	toolConfig, err := tee.configLoader.LoadToolConfig(toolName)
+	if err != nil {
+		return "", err
+	}
+	if toolConfig.MinVersion == "" && toolConfig.MaxVersion == "" {
+		return "", nil
+	}
+	check := tee.versionCheck(ctx, toolName, toolConfig)
+	return check.version, check.err
+}
+
+// versionCheck returns the cached check of a tool with a version range, running it on first use
+func (tee *ToolExecutionEngine) versionCheck(ctx context.Context, toolName string, toolConfig *ToolConfig) *versionCheck {
+	tee.versionMutex.Lock()
+	defer tee.versionMutex.Unlock()
+	if check, exists := tee.versionChecks[toolName]; exists {
+		return check
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+	defer cancel()
+	check := &versionCheck{}
+	out, err := tee.versionOutput(checkCtx, toolName, toolConfig)
+	if err != nil {
+		check.err = err
+	} else if match := versionNumberPattern.FindString(out); match == "" {
+		check.err = fmt.Errorf("%s %s printed no version number", toolName, strings.Join(toolConfig.VersionArgs, " "))
+	} else {
+		check.version = match
+		if supported, _ := toolConfig.SupportsVersion(match); !supported {
+			check.err = &UnsupportedVersionError{Tool: toolName, Installed: match, Min: toolConfig.MinVersion, Max: toolConfig.MaxVersion}
+		}
+	}
+	if ctx.Err() == nil {
+		tee.versionChecks[toolName] = check // A cancelled run's check says nothing about the tool
+	}
+	return check
+}
+
+// VersionPolicy returns the configured version_policy, defaulting to warn
+func (tee *ToolExecutionEngine) VersionPolicy() string {
+	if tee.globalConfig != nil && tee.globalConfig.Tools.VersionPolicy != "" {
+		return tee.globalConfig.Tools.VersionPolicy
+	}
+	return VersionPolicyWarn
+}
+
+// enforceToolVersion applies the version policy before a tool runs. It returns an error only
+// when the policy refuses an unsupported version; otherwise problems are warned about once
+func (tee *ToolExecutionEngine) enforceToolVersion(ctx context.Context, toolName, mode, target string, toolConfig *ToolConfig) error {
+	policy := tee.VersionPolicy()
+	if policy == VersionPolicyOff || (toolConfig.MinVersion == "" && toolConfig.MaxVersion == "") || tee.toolMock != nil {
+		return nil
+	}
+	if _, err := tee.findToolExecutable(toolName); err != nil {
+		return nil // The execution itself reports the missing tool
+	}
+	check := tee.versionCheck(ctx, toolName, toolConfig)
+	if check.err == nil {
+		return nil
+	}
+	_, unsupported := check.err.(*UnsupportedVersionError)
+	if unsupported && policy == VersionPolicyRefuse {
+		return check.err
+	}
+
+	tee.versionMutex.Lock()
+	warned := check.warned
+	check.warned = true
+	tee.versionMutex.Unlock()
+	if warned {
+		return nil
+	}
+	if unsupported {
+		tee.warn(output.WarningVersionUnsupported, toolName, mode, target, "%v; its arguments may not work as configured", check.err)
+	} else {
+		tee.warn(output.WarningVersionUnsupported, toolName, mode, target, "could not check %s's version (%v); supported: %s", toolName, check.err, versionRange(toolConfig.MinVersion, toolConfig.MaxVersion))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// SetWarningHandler sets the function that receives the run's warnings: conditions that
+// make the scan imperfect (partial output, a downgraded mode, unparsed output) without
+// failing it. Errors keep going to the error handler
+func (tee *ToolExecutionEngine) SetWarningHandler(handler func(warning output.RunWarning)) {
+	tee.warningMutex.Lock()
+	defer tee.warningMutex.Unlock()
+	tee.warningHandler = handler
+}
+
+// warn reports a warning to the handler and, in verbose mode, on the console
+func (tee *ToolExecutionEngine) warn(kind, toolName, mode, target, format string, args ...interface{}) {
+	warning := output.RunWarning{
+		Kind:    kind,
+		Tool:    toolName,
+		Mode:    mode,
+		Target:  target,
+		Message: fmt.Sprintf(format, args...),
+		Time:    wallTime(time.Now(), tee.location),
+	}
+	tee.debugLogger.Warn("Scan warning", "kind", kind, "tool", toolName, "mode", mode, "message", warning.Message)
+	if tee.outputController != nil {
+		tee.outputController.PrintWarning("%s [%s]: %s", toolName, mode, warning.Message)
+	}
+
+	tee.warningMutex.RLock()
+	handler := tee.warningHandler
+	tee.warningMutex.RUnlock()
+	if handler != nil {
+		handler(warning)
+	}
+}
@@ -0,0 +1,244 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A workflow's after names workflows it waits for: on each target it is queued for, it starts
+// only once every one of them has completed on the same target. A name matches a workflow's
+// file name without .yaml ("port-scanning") or, ignoring case, its name. A workflow that can
+// never start is failed instead of left in the queue: one it waits for failed or was
+// cancelled, is not queued for the target once nothing else runs (nothing could still queue
+// it), or waits in turn for the waiting workflow
+
+// finishedWorkflow is a workflow that finished on a target, which after entries are checked against
+type finishedWorkflow struct {
+	workflow *Workflow
+	target   string
+	status   WorkflowStatus
+}
+
+// MatchesName reports whether an after entry names the workflow
+func (w *Workflow) MatchesName(name string) bool {
+	return (w.ID != "" && w.ID == name) || strings.EqualFold(w.Name, name)
+}
+
+// ValidateAfter checks the after of a workflow when it loads
+func ValidateAfter(workflow *Workflow) error {
+	seen := make(map[string]bool, len(workflow.After))
+	for _, name := range workflow.After {
+		switch {
+		case strings.TrimSpace(name) == "":
+			return fmt.Errorf("after lists an empty workflow name")
+		case workflow.MatchesName(name):
+			return fmt.Errorf("workflow '%s' runs after itself", workflow.Name)
+		case seen[name]:
+			return fmt.Errorf("after lists workflow '%s' twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// dependencyStatus is where a queued workflow stands with one of its dependencies
+type dependencyStatus int
+
+const (
+	dependencyDone    dependencyStatus = iota // Completed on the target
+	dependencyPending                         // Queued or running on the target
+	dependencyFailed                          // Only failed or cancelled runs on the target
+	dependencyMissing                         // Neither queued, running nor finished on the target
+)
+
+// dependencyStatus reports how far the dependency name of item has got; callers hold wo.mutex
+func (wo *WorkflowOrchestrator) dependencyStatus(item *WorkflowQueueItem, name string) (dependencyStatus, WorkflowStatus) {
+	status := dependencyMissing
+	var finished WorkflowStatus
+	for _, done := range wo.finishedWorkflows {
+		if done.target != item.Target || !done.workflow.MatchesName(name) {
+			continue
+		}
+		if done.status == WorkflowStatusCompleted {
+			return dependencyDone, done.status
+		}
+		status, finished = dependencyFailed, done.status
+	}
+
+	// Another run that may still complete beats an earlier failure
+	for _, started := range wo.startedWorkflows {
+		if started.Target == item.Target && started.Workflow.MatchesName(name) {
+			return dependencyPending, finished
+		}
+	}
+	for _, queued := range wo.workflowQueue {
+		if queued != item && queued.Target == item.Target && queued.Workflow.MatchesName(name) {
+			return dependencyPending, finished
+		}
+	}
+	return status, finished
+}
+
+// areDependenciesSatisfied reports whether every workflow item waits for has completed on
+// its target; callers hold wo.mutex
+func (wo *WorkflowOrchestrator) areDependenciesSatisfied(item *WorkflowQueueItem) bool {
+	for _, name := range item.Dependencies {
+		if status, _ := wo.dependencyStatus(item, name); status != dependencyDone {
+			return false
+		}
+	}
+	return true
+}
+
+// unsatisfiableDependency explains why item can never start, or returns "". Missing
+// dependencies only count once idle is set: while a workflow runs, its triggers may still
+// queue them. Callers hold wo.mutex
+func (wo *WorkflowOrchestrator) unsatisfiableDependency(item *WorkflowQueueItem, idle bool) string {
+	for _, name := range item.Dependencies {
+		switch status, finished := wo.dependencyStatus(item, name); {
+		case status == dependencyFailed && finished == WorkflowStatusCancelled:
+			return fmt.Sprintf("waits for '%s', which was cancelled on %s", name, item.Target)
+		case status == dependencyFailed:
+			return fmt.Sprintf("waits for '%s', which failed on %s", name, item.Target)
+		case status == dependencyMissing && idle:
+			return fmt.Sprintf("waits for '%s', which is not queued for %s", name, item.Target)
+		}
+	}
+	return ""
+}
+
+// dropUnsatisfiable fails the queued workflows that can never start. Once nothing runs, a
+// workflow that still waits and is not waiting on one that could start is part of a
+// dependency cycle. Callers hold wo.mutex
+func (wo *WorkflowOrchestrator) dropUnsatisfiable(ctx context.Context) {
+	if ctx.Err() != nil {
+		return // An interrupted run keeps its queue to be resumed
+	}
+	idle := len(wo.startedWorkflows) == 0
+
+	for {
+		for dropped := true; dropped; {
+			dropped = false
+			for i := 0; i < len(wo.workflowQueue); i++ {
+				if reason := wo.unsatisfiableDependency(wo.workflowQueue[i], idle); reason != "" {
+					wo.failQueuedWorkflow(i, reason)
+					dropped = true
+					i--
+				}
+			}
+		}
+		if !idle {
+			return
+		}
+
+		// Fail one workflow of a cycle at a time: the others then wait for a failed workflow
+		cycle := wo.dependencyCycle()
+		if cycle == -1 {
+			return
+		}
+		item := wo.workflowQueue[cycle]
+		var waitsFor []string
+		for _, name := range item.Dependencies {
+			if status, _ := wo.dependencyStatus(item, name); status == dependencyPending {
+				waitsFor = append(waitsFor, "'"+name+"'")
+			}
+		}
+		wo.failQueuedWorkflow(cycle, fmt.Sprintf("dependency cycle: waits for %s, which waits for it in turn", strings.Join(waitsFor, ", ")))
+	}
+}
+
+// dependencyCycle returns the index of a queued workflow that waits, through others, for
+// itself, or -1. It is called once nothing runs and no dependency has failed or
+// is missing; callers hold wo.mutex
+func (wo *WorkflowOrchestrator) dependencyCycle() int {
+	// A workflow can start eventually when each of its dependencies has completed or is
+	// queued as a workflow that can
+	canStart := make(map[*WorkflowQueueItem]bool, len(wo.workflowQueue))
+	for progress := true; progress; {
+		progress = false
+		for _, item := range wo.workflowQueue {
+			if canStart[item] {
+				continue
+			}
+			ready := true
+			for _, name := range item.Dependencies {
+				if status, _ := wo.dependencyStatus(item, name); status == dependencyDone {
+					continue
+				}
+				queued := false
+				for _, other := range wo.workflowQueue {
+					queued = queued || (canStart[other] && other.Target == item.Target && other.Workflow.MatchesName(name))
+				}
+				ready = ready && queued
+			}
+			if ready {
+				canStart[item] = true
+				progress = true
+			}
+		}
+	}
+
+	// Follow what a workflow that cannot start waits for until a workflow repeats: that one is
+	// on the cycle, rather than merely waiting for it
+	var current *WorkflowQueueItem
+	for _, item := range wo.workflowQueue {
+		if !canStart[item] {
+			current = item
+			break
+		}
+	}
+	visited := make(map[*WorkflowQueueItem]bool)
+	for current != nil && !visited[current] {
+		visited[current] = true
+		var next *WorkflowQueueItem
+		for _, name := range current.Dependencies {
+			for _, other := range wo.workflowQueue {
+				if next == nil && !canStart[other] && other != current && other.Target == current.Target && other.Workflow.MatchesName(name) {
+					next = other
+				}
+			}
+		}
+		current = next
+	}
+	for i, item := range wo.workflowQueue {
+		if item == current {
+			return i
+		}
+	}
+	return -1
+}
+
+// failQueuedWorkflow removes the queued workflow at index and records it as failed with
+// "unsatisfiable dependency: <reason>". Callers hold wo.mutex; the event and the report
+// entry follow outside it
+func (wo *WorkflowOrchestrator) failQueuedWorkflow(index int, reason string) {
+	item := wo.workflowQueue[index]
+	wo.workflowQueue = append(wo.workflowQueue[:index], wo.workflowQueue[index+1:]...)
+	wo.finishedWorkflows = append(wo.finishedWorkflows, finishedWorkflow{workflow: item.Workflow, target: item.Target, status: WorkflowStatusFailed})
+	wo.saveQueueState()
+
+	err := fmt.Errorf("unsatisfiable dependency: %s", reason)
+	wo.debugLogger.Printf("Workflow %s for target %s cannot start: %v", item.Workflow.Name, item.Target, err)
+	now := time.Now()
+	execution := &WorkflowExecution{
+		Workflow:   item.Workflow,
+		Target:     item.Target,
+		Status:     WorkflowStatusFailed,
+		StartTime:  wo.wallTime(now),
+		EndTime:    wo.wallTime(now),
+		started:    now,
+		Error:      err,
+		TotalSteps: len(item.Workflow.Steps),
+	}
+
+	wo.wg.Add(1)
+	go func() {
+		defer wo.wg.Done()
+		wo.events.Publish(Event{Type: EventWorkflowFailed, Workflow: item.Workflow.Name, Target: item.Target,
+			StepCount: len(item.Workflow.Steps), TriggeredBy: item.TriggeredBy,
+			Message: fmt.Sprintf("Workflow failed: %v", err), Error: err.Error()})
+		wo.recordWorkflowReport(execution)
+	}()
+}
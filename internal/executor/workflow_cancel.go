@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errWorkflowCancelled is the error of a workflow stopped by CancelWorkflow
+var errWorkflowCancelled = errors.New("workflow cancelled")
+
+// CancelWorkflow stops one workflow without affecting the rest of the run. id is either a
+// workflow name, which matches it on every target, or a "<name>_<target>" key as listed by
+// GetExecutionStatus. A running match has its tools killed together with their child
+// processes, finishes as WorkflowStatusCancelled and frees its slot for the next queued
+// workflow; a match still waiting in the queue is dropped. It returns an error if nothing matched
+func (wo *WorkflowOrchestrator) CancelWorkflow(id string) error {
+	wo.mutex.Lock()
+	var cancelled int
+	for key, execution := range wo.activeWorkflows {
+		if key != id && execution.Workflow.Name != id {
+			continue
+		}
+		if execution.cancel != nil {
+			execution.cancel()
+		}
+		cancelled++
+		wo.debugLogger.Printf("Cancelling workflow: %s for target: %s", execution.Workflow.Name, execution.Target)
+	}
+
+	var dropped []*WorkflowQueueItem
+	remaining := wo.workflowQueue[:0]
+	for _, item := range wo.workflowQueue {
+		if item.Workflow.Name == id || fmt.Sprintf("%s_%s", item.Workflow.Name, item.Target) == id {
+			dropped = append(dropped, item)
+			wo.finishedWorkflows = append(wo.finishedWorkflows, finishedWorkflow{workflow: item.Workflow, target: item.Target, status: WorkflowStatusCancelled})
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+	wo.workflowQueue = remaining
+	if len(dropped) > 0 {
+		wo.saveQueueState()
+	}
+	wo.mutex.Unlock()
+
+	if cancelled == 0 && len(dropped) == 0 {
+		return fmt.Errorf("no running or queued workflow matches '%s'", id)
+	}
+
+	// Running workflows report their own cancellation once their tools have stopped
+	for _, item := range dropped {
+		wo.events.Publish(Event{Type: EventWorkflowCancelled, Workflow: item.Workflow.Name, Target: item.Target,
+			TriggeredBy: item.TriggeredBy, Message: "Workflow cancelled before it started"})
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/estimate"
+)
+
+// progressInterval is how often a running workflow with an estimate publishes its progress
+const progressInterval = 10 * time.Second
+
+// SetDurationHistory estimates how long workflows have left from past tool durations and
+// publishes it as EventWorkflowProgress
+func (wo *WorkflowOrchestrator) SetDurationHistory(history *estimate.History) {
+	wo.durations = history
+}
+
+// workflowEstimate predicts when a running workflow finishes. Each step is expected to take
+// the recorded duration of its modes (the longest for concurrent modes, their sum otherwise),
+// starting when it starts or, before that, when the step it depends on is expected to end
+type workflowEstimate struct {
+	mutex    sync.Mutex
+	item     *WorkflowQueueItem
+	expected []time.Duration // Per step
+	started  []time.Time
+	finished []bool
+	begun    time.Time
+}
+
+// newWorkflowEstimate returns nil when any step has a mode without recorded durations for a
+// target of this size: a partial estimate would be confidently wrong
+func (wo *WorkflowOrchestrator) newWorkflowEstimate(item *WorkflowQueueItem) *workflowEstimate {
+	if wo.durations == nil {
+		return nil
+	}
+	steps := item.Workflow.Steps
+	e := &workflowEstimate{
+		item:     item,
+		expected: make([]time.Duration, len(steps)),
+		started:  make([]time.Time, len(steps)),
+		finished: make([]bool, len(steps)),
+		begun:    time.Now(),
+	}
+	for i, step := range steps {
+		for _, mode := range step.Modes {
+			duration, ok := wo.durations.Expected(step.Tool, mode, item.Target)
+			if !ok {
+				return nil
+			}
+			if !step.Concurrent {
+				e.expected[i] += duration
+			} else if duration > e.expected[i] {
+				e.expected[i] = duration
+			}
+		}
+	}
+	return e
+}
+
+func (e *workflowEstimate) stepStarted(index int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.started[index] = time.Now()
+}
+
+func (e *workflowEstimate) stepFinished(index int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.finished[index] = true
+}
+
+// event describes the workflow's progress at now: the remaining time, the share of the
+// expected total already spent, and the running step that is expected to end last
+func (e *workflowEstimate) event(now time.Time) Event {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	steps := e.item.Workflow.Steps
+	ends := make([]time.Time, len(steps))
+	var end func(i int) time.Time
+	end = func(i int) time.Time {
+		if !ends[i].IsZero() {
+			return ends[i]
+		}
+		switch {
+		case e.finished[i]:
+			ends[i] = now
+		case !e.started[i].IsZero():
+			ends[i] = e.started[i].Add(e.expected[i])
+			if ends[i].Before(now) {
+				ends[i] = now // Overdue: expected to end any moment
+			}
+		default:
+			start := now
+			ends[i] = now // Guards against dependency cycles while recursing
+			for _, name := range steps[i].DependsOn {
+				if dependency := stepIndex(steps, name); dependency >= 0 && dependency != i {
+					if dependencyEnd := end(dependency); dependencyEnd.After(start) {
+						start = dependencyEnd
+					}
+				}
+			}
+			ends[i] = start.Add(e.expected[i])
+		}
+		return ends[i]
+	}
+
+	last, longest := now, -1
+	for i := range steps {
+		if stepEnd := end(i); stepEnd.After(last) {
+			last = stepEnd
+			if !e.started[i].IsZero() && !e.finished[i] {
+				longest = i
+			}
+		}
+	}
+	remaining := last.Sub(now)
+	elapsed := now.Sub(e.begun)
+	percent := 100
+	if total := elapsed + remaining; total > 0 {
+		percent = int(100 * elapsed / total)
+	}
+
+	event := Event{
+		Type:      EventWorkflowProgress,
+		Workflow:  e.item.Workflow.Name,
+		Target:    e.item.Target,
+		StepCount: len(steps),
+		Percent:   percent,
+		Remaining: int(remaining.Round(time.Second).Seconds()),
+		Message:   fmt.Sprintf("%d%% done, ~%s remaining", percent, formatRemaining(remaining)),
+	}
+	if longest >= 0 {
+		step := steps[longest]
+		event.Step, event.StepIndex, event.Tool = step.Name, longest+1, step.Tool
+		label := step.Name
+		if len(step.Modes) == 1 {
+			label = step.Tool + " " + strings.ReplaceAll(step.Modes[0], "_", " ")
+		}
+		event.Message = fmt.Sprintf("%s ~%s remaining (%d%% of %s done)", label, formatRemaining(ends[longest].Sub(now)), percent, e.item.Workflow.Name)
+	}
+	return event
+}
+
+// publishProgress publishes the workflow's progress every progressInterval until done closes
+func (wo *WorkflowOrchestrator) publishProgress(e *workflowEstimate, done <-chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			wo.events.Publish(e.event(now))
+		}
+	}
+}
+
+// stepIndex returns the index of the named step, -1 if there is none
+func stepIndex(steps []*WorkflowStep, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, step := range steps {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatRemaining rounds a remaining time the way people say it: "40s", "3m", "1h20m"
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Round(time.Second).Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Round(time.Minute).Minutes()))
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,9 +13,17 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/estimate"
+	"github.com/neur0map/ipcrawler/internal/findings"
 	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/scope"
+	"github.com/neur0map/ipcrawler/internal/tools/httpx"
+	"github.com/neur0map/ipcrawler/internal/tools/masscan"
+	"github.com/neur0map/ipcrawler/internal/tools/merge"
 	"github.com/neur0map/ipcrawler/internal/tools/naabu"
 	"github.com/neur0map/ipcrawler/internal/tools/nmap"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+	"github.com/neur0map/ipcrawler/internal/workspace"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/mem"
 )
@@ -22,10 +31,16 @@ import (
 
 // Workflow represents a complete workflow definition with enhanced parallelism support
 type Workflow struct {
+	ID                      string // File name without .yaml, e.g. "port-scanning"
 	Name                    string
 	Description             string
 	Category                string
 	Steps                   []*WorkflowStep
+	Matrix                  map[string][]string // Matrix keys and values expanded into step instances (see ExpandMatrix)
+	Triggers                []WorkflowTrigger   // Discovered services that queue this workflow (see TriggerEngine)
+	Resources               WorkflowResources   // Network, CPU and memory the workflow reserves while it runs
+	After                   []string            // Workflows that must complete on the target first (see ValidateAfter)
+	Tags                    []string            // Selected by profile tag filters, e.g. passive, loud, web (see ParseTagFilter)
 	
 	// Enhanced workflow-level parallelism controls
 	ParallelWorkflow        bool   // Can run simultaneously with other workflows
@@ -40,16 +55,31 @@ type WorkflowStep struct {
 	Tool                string
 	Description         string
 	Modes               []string
+	FallbackModes       []string // Modes tried in order when a mode fails for lack of privileges
 	Concurrent          bool
 	CombineResults      bool
-	DependsOn           string
+	DependsOn           []string          // Steps that must finish before this one starts
 	Variables           map[string]string // Variable mappings for this step
+	Parameters          map[string]string // Tool parameters (e.g. nmap decoys, fragmentation, timing)
+	Combiner            map[string]string // Result combiner options (thresholds, dedupe rules)
+	Matrix              map[string]string // Matrix values this instance was expanded with
+	RunIf               string            // Condition over magic variables; the step is skipped when false
+	Retry               *RetryPolicy      // Retry policy for this step's executions (nil = tools.retry_attempts)
+	Inputs              []VariableMapping // Variables the step reads, from the inputs block
+	Outputs             []VariableMapping // Variables the step publishes, from the outputs block
 	
 	// Enhanced parallelism controls
 	StepPriority        string // "low", "medium", "high" - execution priority
 	MaxConcurrentTools  int    // Maximum number of tool instances to run simultaneously
 }
 
+// VariableMapping is one entry of a step's inputs or outputs: the variable Name is read from
+// the variable Source
+type VariableMapping struct {
+	Name   string
+	Source string
+}
+
 // WorkflowResult represents the result of executing a workflow step
 type WorkflowResult struct {
 	StepName      string
@@ -58,8 +88,15 @@ type WorkflowResult struct {
 	Success       bool
 	Results       []*ExecutionResult
 	CombinedVars  map[string]string
+	CombinerOptions map[string]string // Effective combiner options used for CombinedVars
+	Outputs       map[string]string // Variables published by the step's outputs block
+	Matrix        map[string]string // Matrix values of the step instance, if expanded from a matrix
+	DependsOn     []string          // Steps this one waited for
+	Order         int               // Position in which the step started within its workflow, from 1
 	Duration      time.Duration
 	ErrorMessage  string
+	Skipped       bool   // run_if was false, so no tool ran
+	SkipReason    string // The condition that skipped the step
 }
 
 // WorkflowExecutor handles execution of multi-step workflows with parallel support
@@ -82,9 +119,6 @@ func getPriorityFromString(priority string) int {
 	}
 }
 
-// WorkflowStatusCallback is a callback function for workflow status updates
-type WorkflowStatusCallback func(workflowName, target, status, message string)
-
 // WorkflowOrchestrator manages parallel execution of multiple workflows
 type WorkflowOrchestrator struct {
 	executor             *WorkflowExecutor
@@ -93,7 +127,7 @@ type WorkflowOrchestrator struct {
 	workflowQueue         []*WorkflowQueueItem
 	ResourceMonitor       *ResourceMonitor // Made public for TUI access
 	config               *config.Config // Configuration reference for priority calculations
-	statusCallback       WorkflowStatusCallback // Callback for status updates
+	events               *EventBus // Execution lifecycle events for every subscriber
 	mutex                sync.RWMutex
 	wg                   sync.WaitGroup // WaitGroup to track active workflows
 	
@@ -103,6 +137,37 @@ type WorkflowOrchestrator struct {
 	
 	// Output mode for controlling console logging
 	outputMode   output.OutputMode
+	
+	// Collects finished workflows for the machine-readable run report (nil = disabled)
+	reportGenerator *output.ReportGenerator
+	
+	// Queue persistence for --resume-queue (disabled when queueStatePath is empty)
+	queueStatePath   string
+	queueStatePerm   os.FileMode
+	activeQueueItems map[string]*WorkflowQueueItem // Started workflows by key, kept until they finish
+	
+	// Queues workflows whose triggers match discovered services (nil = no triggers)
+	triggers *TriggerEngine
+	
+	// Started workflows by key until they are released, and the workflows finished since, which
+	// queued workflows' after entries wait for (activeWorkflows only lists a workflow once its
+	// goroutine runs)
+	startedWorkflows  map[string]*WorkflowQueueItem
+	finishedWorkflows []finishedWorkflow
+	
+	// Key results of finished workflows, exported as {{workflow_<name>_*}} variables
+	summaries workflowSummaries
+	
+	// Findings already reported to the run, and the extractors reading step outputs
+	seenFindings seenFindings
+	catalog     *findings.Catalog
+	catalogOnce sync.Once
+	
+	// Picks {{smart_wordlist}} from detected technologies (nil = not configured)
+	wordlists *WordlistSelector
+	
+	// Past tool durations the workflow ETA is estimated from (nil = no estimates)
+	durations *estimate.History
 }
 
 // WorkflowExecution tracks the execution state of a workflow
@@ -110,13 +175,17 @@ type WorkflowExecution struct {
 	Workflow        *Workflow
 	Target          string
 	Status          WorkflowStatus
-	StartTime       time.Time
-	EndTime         time.Time
+	StartTime       time.Time     // Wall clock in the engagement timezone
+	EndTime         time.Time     // Wall clock in the engagement timezone
+	Duration        time.Duration // Measured on the monotonic clock when the workflow ends
 	CurrentStep     int
 	StepResults     []*WorkflowResult
 	Error           error
 	TotalSteps      int
 	CompletedSteps  int
+	
+	started time.Time          // Monotonic start reading, for Duration and running times
+	cancel  context.CancelFunc // Stops this workflow alone (see CancelWorkflow)
 }
 
 // WorkflowQueueItem represents a workflow waiting to be executed
@@ -126,6 +195,7 @@ type WorkflowQueueItem struct {
 	Priority      int // Calculated priority based on workflow settings
 	QueueTime     time.Time
 	Dependencies  []string // List of workflow names this depends on
+	TriggeredBy   string   // Workflow and service that queued this item, if a trigger did
 }
 
 // WorkflowStatus represents the current state of workflow execution
@@ -145,10 +215,18 @@ type ResourceMonitor struct {
 	maxMemoryUsage float64
 	currentCPU     float64
 	currentMemory  float64
+	cpuReading     systemCollector
+	memoryReading  systemCollector
 	activeTools    int
 	maxActiveTools int
 	mutex          sync.RWMutex
 	debugLogger    *log.Logger
+	
+	// Resources declared by running workflows, keyed like activeWorkflows, and their limits
+	reservations        map[string]ResourceReservation
+	maxNetworkHeavy     int
+	maxCPUHeavy         int
+	maxReservedMemoryMB int // 0 = no memory budget
 }
 
 // NewWorkflowExecutor creates a new workflow executor
@@ -161,6 +239,17 @@ func NewWorkflowExecutor(engine *ToolExecutionEngine) *WorkflowExecutor {
 	// Register tool-specific result combiners
 	we.combiners["naabu"] = &naabu.ResultCombiner{}
 	we.combiners["nmap"] = &nmap.ResultCombiner{}
+	we.combiners["masscan"] = &masscan.ResultCombiner{}
+	we.combiners["httpx"] = &httpx.ResultCombiner{}
+	
+	// Subdomain tools share one combiner so their results are deduplicated together
+	subdomainCombiner := subdomains.NewResultCombiner(SubdomainReaders())
+	we.combiners["subfinder"] = subdomainCombiner
+	we.combiners["amass"] = subdomainCombiner
+	we.combiners["passive"] = subdomainCombiner
+	subdomainCombiner.SetFilter(func(name string) bool {
+		return engine.refuseOutOfScope(name, scope.AuditSourceSubdomains, "", "") == nil
+	})
 
 	return we
 }
@@ -190,6 +279,16 @@ func NewWorkflowOrchestrator(executor *WorkflowExecutor, cfg *config.Config) *Wo
 		maxActiveTools = orchestrationConfig.ResourceLimits.MaxActiveTools
 	}
 	
+	maxNetworkHeavy := 1 // Default value
+	if orchestrationConfig.ResourceLimits.MaxNetworkHeavy > 0 {
+		maxNetworkHeavy = orchestrationConfig.ResourceLimits.MaxNetworkHeavy
+	}
+	
+	maxCPUHeavy := 2 // Default value
+	if orchestrationConfig.ResourceLimits.MaxCPUHeavy > 0 {
+		maxCPUHeavy = orchestrationConfig.ResourceLimits.MaxCPUHeavy
+	}
+	
 	// Setup default loggers (will be overridden when workspace is set)
 	debugLogger := log.New(os.Stderr)
 	debugLogger.SetLevel(log.DebugLevel)
@@ -197,29 +296,58 @@ func NewWorkflowOrchestrator(executor *WorkflowExecutor, cfg *config.Config) *Wo
 	infoLogger := log.New(os.Stderr) 
 	infoLogger.SetLevel(log.InfoLevel)
 	
-	return &WorkflowOrchestrator{
+	wo := &WorkflowOrchestrator{
 		executor:               executor,
 		maxConcurrentWorkflows: maxConcurrentWorkflows,
 		activeWorkflows:        make(map[string]*WorkflowExecution),
 		workflowQueue:          make([]*WorkflowQueueItem, 0),
+		startedWorkflows:       make(map[string]*WorkflowQueueItem),
 		config:                 cfg,
-		statusCallback:         nil, // Will be set by caller
+		events:                 NewEventBus(),
 		debugLogger:            debugLogger,
 		infoLogger:             infoLogger,
 		ResourceMonitor: &ResourceMonitor{
 			maxCPUUsage:    maxCPUUsage,
 			maxMemoryUsage: maxMemoryUsage,
 			maxActiveTools: maxActiveTools,
+			cpuReading:     systemCollector{name: "CPU"},
+			memoryReading:  systemCollector{name: "memory"},
 			debugLogger:    debugLogger, // Use the same debug logger
+			
+			reservations:        make(map[string]ResourceReservation),
+			maxNetworkHeavy:     maxNetworkHeavy,
+			maxCPUHeavy:         maxCPUHeavy,
+			maxReservedMemoryMB: orchestrationConfig.ResourceLimits.MaxReservedMemoryMB,
 		},
 	}
+	
+	// Tools report their output lines on the same bus, and the engine's execution status
+	// shows the workflows' reservations
+	if executor != nil && executor.engine != nil {
+		executor.engine.SetEventBus(wo.events)
+		executor.engine.resourceMonitor.Store(wo.ResourceMonitor)
+	}
+	return wo
 }
 
-// SetStatusCallback sets the callback for workflow status updates
-func (wo *WorkflowOrchestrator) SetStatusCallback(callback WorkflowStatusCallback) {
-	wo.mutex.Lock()
-	defer wo.mutex.Unlock()
-	wo.statusCallback = callback
+// Events returns the bus carrying the lifecycle events of the orchestrator's workflows and
+// the tools they run
+func (wo *WorkflowOrchestrator) Events() *EventBus {
+	return wo.events
+}
+
+// stepEvent describes a step of a running workflow
+func stepEvent(eventType EventType, item *WorkflowQueueItem, stepIndex int, step *WorkflowStep, message string) Event {
+	return Event{
+		Type:      eventType,
+		Workflow:  item.Workflow.Name,
+		Target:    item.Target,
+		Step:      step.Name,
+		StepIndex: stepIndex + 1,
+		StepCount: len(item.Workflow.Steps),
+		Tool:      step.Tool,
+		Message:   message,
+	}
 }
 
 // SetOutputMode configures the output mode for logging
@@ -229,20 +357,27 @@ func (wo *WorkflowOrchestrator) SetOutputMode(mode output.OutputMode) {
 
 // SetWorkspaceLoggers sets up loggers that write to workspace log files
 func (wo *WorkflowOrchestrator) SetWorkspaceLoggers(workspaceDir string) error {
-	debugsDir := filepath.Join(workspaceDir, "logs", "debug")
-	infoDir := filepath.Join(workspaceDir, "logs", "info")
+	paths := workspace.New(workspaceDir).Paths()
+	dirMode := wo.config.Output.Permissions.DirPerm()
+	fileMode := wo.config.Output.Permissions.FilePerm()
 	
 	// Create log directories
-	if err := os.MkdirAll(debugsDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(paths.WorkflowDebug), dirMode); err != nil {
 		return fmt.Errorf("failed to create debug log directory: %v", err)
 	}
-	if err := os.MkdirAll(infoDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(paths.WorkflowLog), dirMode); err != nil {
 		return fmt.Errorf("failed to create info log directory: %v", err)
 	}
 	
+	// The engine's redactor scrubs secrets and redaction patterns from both logs
+	var redactor *output.Redactor
+	if wo.executor != nil && wo.executor.engine != nil {
+		redactor = wo.executor.engine.redactor
+	}
+	
 	// Setup debug logger to write to both console and file
-	debugFile, err := os.OpenFile(filepath.Join(debugsDir, "workflow.log"), 
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	debugFile, err := os.OpenFile(paths.WorkflowDebug, 
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open debug log file: %v", err)
 	}
@@ -256,14 +391,14 @@ func (wo *WorkflowOrchestrator) SetWorkspaceLoggers(workspaceDir string) error {
 		// In normal mode, write only to file
 		debugMultiWriter = debugFile
 	}
-	wo.debugLogger = log.New(debugMultiWriter)
+	wo.debugLogger = log.New(output.NewRedactingWriter(debugMultiWriter, redactor))
 	wo.debugLogger.SetReportCaller(false)
 	wo.debugLogger.SetReportTimestamp(true)
 	wo.debugLogger.SetLevel(log.DebugLevel)
 	
 	// Setup info logger to write to both console and file  
-	infoFile, err := os.OpenFile(filepath.Join(infoDir, "workflow.log"),
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	infoFile, err := os.OpenFile(paths.WorkflowLog,
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open info log file: %v", err)
 	}
@@ -277,11 +412,22 @@ func (wo *WorkflowOrchestrator) SetWorkspaceLoggers(workspaceDir string) error {
 		// In normal mode, write only to file
 		infoMultiWriter = infoFile
 	}
-	wo.infoLogger = log.New(infoMultiWriter)
+	wo.infoLogger = log.New(output.NewRedactingWriter(infoMultiWriter, redactor))
 	wo.infoLogger.SetReportCaller(false)
 	wo.infoLogger.SetReportTimestamp(true)
 	wo.infoLogger.SetLevel(log.InfoLevel)
 	
+	// Tag every entry with the engine's run identifier
+	if wo.executor != nil && wo.executor.engine != nil && wo.executor.engine.GetScanID() != "" {
+		scanID := wo.executor.engine.GetScanID()
+		wo.debugLogger = wo.debugLogger.With("scan_id", scanID)
+		wo.infoLogger = wo.infoLogger.With("scan_id", scanID)
+		if sessionName := wo.executor.engine.GetSessionName(); sessionName != "" {
+			wo.debugLogger = wo.debugLogger.With("session", sessionName)
+			wo.infoLogger = wo.infoLogger.With("session", sessionName)
+		}
+	}
+	
 	// Update ResourceMonitor logger
 	wo.ResourceMonitor.debugLogger = wo.debugLogger
 	
@@ -314,7 +460,6 @@ func (wo *WorkflowOrchestrator) GetExecutionStatus() (queuedCount, activeCount i
 // QueueWorkflow adds a workflow to the execution queue
 func (wo *WorkflowOrchestrator) QueueWorkflow(workflow *Workflow, target string) error {
 	wo.mutex.Lock()
-	defer wo.mutex.Unlock()
 
 	wo.debugLogger.Printf("Queuing workflow: %s for target: %s", workflow.Name, target)
 
@@ -333,9 +478,13 @@ func (wo *WorkflowOrchestrator) QueueWorkflow(workflow *Workflow, target string)
 
 	// Insert into queue based on priority
 	wo.insertByPriority(queueItem)
+	wo.saveQueueState()
 	
 	wo.debugLogger.Printf("Workflow queued successfully. Total queue size: %d", len(wo.workflowQueue))
+	wo.mutex.Unlock()
 
+	wo.events.Publish(Event{Type: EventWorkflowQueued, Workflow: workflow.Name, Target: target,
+		StepCount: len(workflow.Steps), Message: fmt.Sprintf("Workflow queued with priority %d", priority)})
 	return nil
 }
 
@@ -346,9 +495,29 @@ func (wo *WorkflowOrchestrator) ExecuteQueuedWorkflows(ctx context.Context) erro
 	wo.debugLogger.Printf("Starting ExecuteQueuedWorkflows - Queue size: %d, Active workflows: %d, Max concurrent: %d",
 		len(wo.workflowQueue), len(wo.activeWorkflows), wo.maxConcurrentWorkflows)
 
-	// Update resource monitor before processing
+	wo.startQueuedWorkflows(ctx)
+
+	wo.debugLogger.Printf("ExecuteQueuedWorkflows completed - Final queue size: %d, Active workflows: %d",
+		len(wo.workflowQueue), len(wo.activeWorkflows))
+	wo.saveQueueState()
+	
+	// Release the mutex before waiting for workflows to complete
+	wo.mutex.Unlock()
+	
+	// Wait for all started workflows to complete
+	wo.debugLogger.Printf("Waiting for all workflows to complete...")
+	wo.wg.Wait()
+	wo.debugLogger.Printf("All workflows completed!")
+	
+	return nil
+}
+
+// startQueuedWorkflows starts queued workflows while there is capacity; callers hold wo.mutex.
+// It also runs when a workflow finishes or a trigger queues one, so later arrivals are not stranded
+func (wo *WorkflowOrchestrator) startQueuedWorkflows(ctx context.Context) {
+	// Refresh resource usage before deciding what fits
 	if err := wo.ResourceMonitor.UpdateResourceUsageFromSystem(); err != nil {
-		wo.debugLogger.Printf("Warning: Failed to update resource usage: %v", err)
+		wo.debugLogger.Warn("Resource monitoring degraded", "error", err)
 	}
 
 	for len(wo.workflowQueue) > 0 && len(wo.activeWorkflows) < wo.maxConcurrentWorkflows {
@@ -363,7 +532,7 @@ func (wo *WorkflowOrchestrator) ExecuteQueuedWorkflows(ctx context.Context) erro
 		// Find next executable workflow (dependencies satisfied)
 		nextIndex := wo.findNextExecutableWorkflow()
 		if nextIndex == -1 {
-			wo.debugLogger.Printf("No executable workflows found (dependencies not satisfied)")
+			wo.debugLogger.Printf("No executable workflows found (dependencies not satisfied or resources reserved)")
 			break // No workflows can be executed right now
 		}
 
@@ -372,38 +541,45 @@ func (wo *WorkflowOrchestrator) ExecuteQueuedWorkflows(ctx context.Context) erro
 		wo.workflowQueue = append(wo.workflowQueue[:nextIndex], wo.workflowQueue[nextIndex+1:]...)
 		
 		wo.debugLogger.Printf("Starting workflow: %s for target: %s", queueItem.Workflow.Name, queueItem.Target)
+		workflowKey := fmt.Sprintf("%s_%s", queueItem.Workflow.Name, queueItem.Target)
+		if wo.activeQueueItems != nil {
+			wo.activeQueueItems[workflowKey] = queueItem
+		}
+		wo.startedWorkflows[workflowKey] = queueItem
+		// Reserved before the next queued workflow is considered, released by releaseWorkflow
+		wo.ResourceMonitor.reserve(workflowKey, queueItem.Workflow, queueItem.Target)
 
 		// Start workflow execution in a separate goroutine
 		wo.wg.Add(1)
 		go wo.executeWorkflowAsync(ctx, queueItem)
 	}
-
-	wo.debugLogger.Printf("ExecuteQueuedWorkflows completed - Final queue size: %d, Active workflows: %d",
-		len(wo.workflowQueue), len(wo.activeWorkflows))
-	
-	// Release the mutex before waiting for workflows to complete
-	wo.mutex.Unlock()
 	
-	// Wait for all started workflows to complete
-	wo.debugLogger.Printf("Waiting for all workflows to complete...")
-	wo.wg.Wait()
-	wo.debugLogger.Printf("All workflows completed!")
-	
-	return nil
+	// Fail what waits for workflows that failed or will never run, rather than leave it queued
+	wo.dropUnsatisfiable(ctx)
 }
 
 // executeWorkflowAsync executes a workflow asynchronously
 func (wo *WorkflowOrchestrator) executeWorkflowAsync(ctx context.Context, queueItem *WorkflowQueueItem) {
 	wo.debugLogger.Printf("GOROUTINE STARTED: %s for target: %s", queueItem.Workflow.Name, queueItem.Target)
+	defer wo.wg.Done()
 	
+	// The workflow's own context lets CancelWorkflow stop it without touching the others
+	workflowCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	started := time.Now()
 	execution := &WorkflowExecution{
 		Workflow:      queueItem.Workflow,
 		Target:        queueItem.Target,
 		Status:        WorkflowStatusRunning,
-		StartTime:     time.Now(),
+		StartTime:     wo.wallTime(started),
+		started:       started,
 		TotalSteps:    len(queueItem.Workflow.Steps),
 		StepResults:   make([]*WorkflowResult, 0),
+		cancel:        cancel,
 	}
+	workflowKey := fmt.Sprintf("%s_%s", queueItem.Workflow.Name, queueItem.Target)
+	defer wo.recoverWorkflow(ctx, workflowKey, queueItem, execution)
 
 	wo.debugLogger.Printf("Starting workflow execution: %s for target: %s", queueItem.Workflow.Name, queueItem.Target)
 
@@ -411,127 +587,120 @@ func (wo *WorkflowOrchestrator) executeWorkflowAsync(ctx context.Context, queueI
 	wo.debugLogger.Printf("About to acquire mutex for: %s", queueItem.Workflow.Name)
 	wo.mutex.Lock()
 	wo.debugLogger.Printf("Acquired mutex for: %s", queueItem.Workflow.Name)
-	workflowKey := fmt.Sprintf("%s_%s", queueItem.Workflow.Name, queueItem.Target)
 	wo.activeWorkflows[workflowKey] = execution
-	callback := wo.statusCallback // Capture callback while holding lock
 	wo.mutex.Unlock()
 	wo.debugLogger.Printf("Released mutex for: %s", queueItem.Workflow.Name)
 
 	// Notify start
-	if callback != nil {
-		callback(queueItem.Workflow.Name, queueItem.Target, "started", "Workflow execution started")
-	}
+	wo.events.Publish(Event{Type: EventWorkflowStarted, Workflow: queueItem.Workflow.Name, Target: queueItem.Target,
+		StepCount: len(queueItem.Workflow.Steps), TriggeredBy: queueItem.TriggeredBy, Message: "Workflow execution started"})
 
 	// Execute workflow steps IN PARALLEL for true simultaneous execution
 	wo.debugLogger.Printf("Workflow has %d steps - executing ALL SIMULTANEOUSLY", len(queueItem.Workflow.Steps))
 	
 	// Check if context is already cancelled
 	select {
-	case <-ctx.Done():
-		wo.debugLogger.Printf("Context already cancelled before workflow steps: %v", ctx.Err())
-		execution.Error = ctx.Err()
+	case <-workflowCtx.Done():
+		wo.debugLogger.Printf("Context already cancelled before workflow steps: %v", workflowCtx.Err())
+		execution.Error = workflowCtx.Err()
 		execution.Status = WorkflowStatusCancelled
-		wo.wg.Done()
+		wo.finishExecution(execution)
+		wo.recordWorkflowReport(execution)
+		wo.releaseWorkflow(ctx, workflowKey)
 		return
 	default:
 		// Continue
 	}
 	
-	// SMART PARALLEL EXECUTION: Respect dependencies while maximizing parallelism
+	// Estimate the time left from past tool durations, when every step has some
+	eta := wo.newWorkflowEstimate(queueItem)
+	etaDone := make(chan struct{})
+	if eta != nil {
+		go wo.publishProgress(eta, etaDone)
+	}
+	
+	// Run the steps as a dependency graph: each starts once every step it depends on finished
 	stepResults := make([]*WorkflowResult, len(queueItem.Workflow.Steps))
 	stepErrors := make([]error, len(queueItem.Workflow.Steps))
-	stepCompleted := make([]bool, len(queueItem.Workflow.Steps))
-	stepCompletionChans := make([]chan bool, len(queueItem.Workflow.Steps))
-	
-	// Initialize completion channels for each step
-	for i := range queueItem.Workflow.Steps {
-		stepCompletionChans[i] = make(chan bool, 1)
+	graph, graphErr := newStepGraph(queueItem.Workflow.Steps)
+	if graphErr != nil {
+		wo.debugLogger.Printf("Invalid step dependencies in %s: %v", queueItem.Workflow.Name, graphErr)
+		graph = &stepGraph{} // No steps run; the workflow fails with graphErr
 	}
 	
-	var stepWg sync.WaitGroup
-	
-	// Start all independent steps immediately, dependent steps wait for their dependencies
-	for i, step := range queueItem.Workflow.Steps {
-		stepWg.Add(1)
-		go func(stepIndex int, workflowStep *WorkflowStep) {
-			defer stepWg.Done()
-			defer func() {
-				// Signal completion for dependent steps
-				stepCompletionChans[stepIndex] <- true
-			}()
-			
-			// Wait for dependencies if any
-			if workflowStep.DependsOn != "" {
-				wo.debugLogger.Printf("Step %d (%s) waiting for dependency: %s", stepIndex+1, workflowStep.Name, workflowStep.DependsOn)
-				
-				// Find the dependency step
-				depIndex := -1
-				for j, depStep := range queueItem.Workflow.Steps {
-					if depStep.Name == workflowStep.DependsOn {
-						depIndex = j
-						break
-					}
-				}
-				
-				if depIndex != -1 {
-					// Wait for dependency to complete
-					<-stepCompletionChans[depIndex]
-					wo.debugLogger.Printf("Dependency satisfied for step %d (%s)", stepIndex+1, workflowStep.Name)
-				} else {
-					wo.debugLogger.Printf("WARNING: Dependency '%s' not found for step %d (%s)", workflowStep.DependsOn, stepIndex+1, workflowStep.Name)
-				}
-			} else {
-				wo.debugLogger.Printf("STARTING IMMEDIATELY: Step %d: %s (tool: %s, modes: %v) - NO DEPENDENCIES", stepIndex+1, workflowStep.Name, workflowStep.Tool, workflowStep.Modes)
-				if callback != nil {
-					callback(queueItem.Workflow.Name, queueItem.Target, "step_started", 
-						fmt.Sprintf("Started step %d/%d: %s", stepIndex+1, len(queueItem.Workflow.Steps), workflowStep.Name))
-				}
-			}
-			
-			wo.debugLogger.Printf("EXECUTING: Step %d: %s", stepIndex+1, workflowStep.Name)
-			
-			// Execute step with default options - get validation setting from config
-			validateOutput := false // Default fallback
-			if wo.config != nil && wo.config.Tools.CLIMode.ValidateOutput {
-				validateOutput = wo.config.Tools.CLIMode.ValidateOutput
-			}
-			
-			options := &ExecutionOptions{
-				CaptureOutput:  true,
-				ValidateOutput: validateOutput,
-			}
+	graph.run(func(stepIndex, order int) {
+		workflowStep := queueItem.Workflow.Steps[stepIndex]
+		defer wo.recoverStep(&stepErrors[stepIndex], queueItem, workflowStep)
+		if len(workflowStep.DependsOn) > 0 {
+			wo.debugLogger.Printf("Dependencies satisfied for step %d (%s): %s", stepIndex+1, workflowStep.Name, strings.Join(workflowStep.DependsOn, ", "))
+		} else {
+			wo.debugLogger.Printf("STARTING IMMEDIATELY: Step %d: %s (tool: %s, modes: %v) - NO DEPENDENCIES", stepIndex+1, workflowStep.Name, workflowStep.Tool, workflowStep.Modes)
+		}
+		wo.events.Publish(stepEvent(EventStepStarted, queueItem, stepIndex, workflowStep,
+			wo.stepStartedMessage(stepIndex, queueItem.Workflow, workflowStep)))
+		
+		wo.debugLogger.Printf("EXECUTING: Step %d: %s", stepIndex+1, workflowStep.Name)
+		if eta != nil {
+			eta.stepStarted(stepIndex)
+			wo.events.Publish(eta.event(time.Now()))
+		}
+		
+		// Execute step with default options - get validation setting from config
+		validateOutput := false // Default fallback
+		if wo.config != nil && wo.config.Tools.CLIMode.ValidateOutput {
+			validateOutput = wo.config.Tools.CLIMode.ValidateOutput
+		}
+		
+		options := &ExecutionOptions{
+			CaptureOutput:  true,
+			ValidateOutput: validateOutput,
+		}
 
-			result, err := wo.executor.ExecuteStepWithWorkflow(ctx, workflowStep, queueItem.Target, queueItem.Workflow.Name, options)
-			stepResults[stepIndex] = result
-			stepErrors[stepIndex] = err
-			stepCompleted[stepIndex] = true
-			
-			if err != nil {
-				wo.debugLogger.Printf("Step FAILED: %s - Error: %v", workflowStep.Name, err)
-			} else {
-				wo.debugLogger.Printf("Step COMPLETED: %s", workflowStep.Name)
-			}
-			
-			// Notify step completion immediately when it finishes
-			if callback != nil {
-				if err != nil {
-					callback(queueItem.Workflow.Name, queueItem.Target, "step_failed", 
-						fmt.Sprintf("Failed step %d/%d: %s - Error: %v", stepIndex+1, len(queueItem.Workflow.Steps), workflowStep.Name, err))
-				} else {
-					callback(queueItem.Workflow.Name, queueItem.Target, "step_completed", 
-						fmt.Sprintf("Completed step %d/%d: %s", stepIndex+1, len(queueItem.Workflow.Steps), workflowStep.Name))
-				}
-			}
-		}(i, step)
-	}
+		result, err := wo.executor.ExecuteStepWithWorkflow(workflowCtx, workflowStep, queueItem.Target, queueItem.Workflow.Name, options)
+		if result != nil {
+			result.Order = order
+		}
+		stepResults[stepIndex] = result
+		stepErrors[stepIndex] = err
+		
+		// Queue follow-up workflows for services this step discovered
+		if result != nil && workflowCtx.Err() == nil {
+			wo.fireTriggers(ctx, queueItem, result)
+		}
+		wo.publishNewFindings(queueItem, workflowStep, result)
+		wo.selectWordlists(result)
+		
+		if err != nil {
+			wo.debugLogger.Printf("Step FAILED: %s - Error: %v", workflowStep.Name, err)
+		} else if result.Skipped {
+			wo.debugLogger.Printf("Step SKIPPED: %s - %s", workflowStep.Name, result.SkipReason)
+		} else {
+			wo.debugLogger.Printf("Step COMPLETED: %s", workflowStep.Name)
+		}
+		
+		// Notify step completion immediately when it finishes
+		if err == nil && result != nil && result.Skipped {
+			wo.events.Publish(stepEvent(EventStepSkipped, queueItem, stepIndex, workflowStep,
+				fmt.Sprintf("Skipped step %d/%d: %s - %s", stepIndex+1, len(queueItem.Workflow.Steps), workflowStep.Name, result.SkipReason)))
+		} else if err != nil {
+			event := stepEvent(EventStepFailed, queueItem, stepIndex, workflowStep,
+				fmt.Sprintf("Failed step %d/%d: %s - Error: %v", stepIndex+1, len(queueItem.Workflow.Steps), workflowStep.Name, err))
+			event.Error = err.Error()
+			wo.events.Publish(event)
+		} else {
+			wo.events.Publish(stepEvent(EventStepCompleted, queueItem, stepIndex, workflowStep,
+				fmt.Sprintf("Completed step %d/%d: %s", stepIndex+1, len(queueItem.Workflow.Steps), workflowStep.Name)))
+		}
+		if eta != nil {
+			eta.stepFinished(stepIndex)
+		}
+	})
 	
-	// Wait for ALL steps to complete
-	wo.debugLogger.Printf("Waiting for all %d steps to complete (with dependencies)...", len(queueItem.Workflow.Steps))
-	stepWg.Wait()
+	close(etaDone)
 	wo.debugLogger.Printf("All steps completed!")
 	
 	// Process results and check for failures
-	var firstError error
+	firstError := graphErr
 	for i, result := range stepResults {
 		if result != nil {
 			execution.StepResults = append(execution.StepResults, result)
@@ -544,37 +713,63 @@ func (wo *WorkflowOrchestrator) executeWorkflowAsync(ctx context.Context, queueI
 		}
 	}
 	
-	// Set overall execution status
-	if firstError != nil {
+	// Set overall execution status; a workflow cancelled on its own is not a failure
+	wo.finishExecution(execution)
+	finished := Event{Type: EventWorkflowCompleted, Workflow: queueItem.Workflow.Name, Target: queueItem.Target,
+		StepCount: len(queueItem.Workflow.Steps), TriggeredBy: queueItem.TriggeredBy, Message: "Workflow completed successfully"}
+	if workflowCtx.Err() != nil && ctx.Err() == nil {
+		execution.Error = errWorkflowCancelled
+		execution.Status = WorkflowStatusCancelled
+		finished.Type, finished.Message = EventWorkflowCancelled, "Workflow cancelled"
+		wo.debugLogger.Printf("Workflow cancelled: %s", queueItem.Workflow.Name)
+	} else if firstError != nil {
 		execution.Error = firstError
 		execution.Status = WorkflowStatusFailed
+		finished.Type, finished.Message = EventWorkflowFailed, fmt.Sprintf("Workflow failed: %v", firstError)
+		finished.Error = firstError.Error()
 		wo.debugLogger.Printf("Workflow failed with error: %v", firstError)
-		if callback != nil {
-			callback(queueItem.Workflow.Name, queueItem.Target, "failed", fmt.Sprintf("Workflow failed: %v", firstError))
-		}
-	}
-
-	// Mark as completed
-	execution.EndTime = time.Now()
-	if execution.Error == nil {
+	} else {
 		execution.Status = WorkflowStatusCompleted
 		wo.debugLogger.Printf("Workflow completed successfully: %s", queueItem.Workflow.Name)
-		if callback != nil {
-			callback(queueItem.Workflow.Name, queueItem.Target, "completed", "Workflow completed successfully")
-		}
 	}
 
-	// Remove from active workflows
-	wo.mutex.Lock()
-	delete(wo.activeWorkflows, workflowKey)
-	wo.mutex.Unlock()
+	// Export the summary before announcing the result, so subscribers and the workflows
+	// started next already see its variables
+	wo.recordWorkflowSummary(execution)
+	wo.events.Publish(finished)
 
-	// Mark this workflow as done in the WaitGroup
-	wo.wg.Done()
+	// Record the finished workflow in the run report
+	wo.recordWorkflowReport(execution)
+	
+	wo.releaseWorkflow(ctx, workflowKey)
 
 	// Note: Removed recursive call to ExecuteQueuedWorkflows to prevent infinite loops
 }
 
+// releaseWorkflow removes a finished workflow from the active set and hands its slot to the
+// next queued workflow. Workflows interrupted with the whole run stay in the queue file to be resumed
+func (wo *WorkflowOrchestrator) releaseWorkflow(ctx context.Context, workflowKey string) {
+	wo.mutex.Lock()
+	defer wo.mutex.Unlock()
+	
+	if execution, exists := wo.activeWorkflows[workflowKey]; exists {
+		wo.finishedWorkflows = append(wo.finishedWorkflows, finishedWorkflow{workflow: execution.Workflow, target: execution.Target, status: execution.Status})
+	}
+	delete(wo.activeWorkflows, workflowKey)
+	delete(wo.startedWorkflows, workflowKey)
+	wo.ResourceMonitor.release(workflowKey)
+	if ctx.Err() == nil && wo.activeQueueItems != nil {
+		delete(wo.activeQueueItems, workflowKey)
+		wo.saveQueueState()
+	}
+	// Start anything that was waiting for a slot or for this workflow (e.g. workflows queued
+	// by triggers, or running after it)
+	if ctx.Err() == nil && len(wo.workflowQueue) > 0 {
+		wo.startQueuedWorkflows(ctx)
+		wo.saveQueueState()
+	}
+}
+
 // Helper methods for WorkflowOrchestrator
 
 // calculatePriority determines workflow execution priority
@@ -631,17 +826,10 @@ func (wo *WorkflowOrchestrator) calculatePriority(workflow *Workflow) int {
 	return basePriority
 }
 
-// extractDependencies identifies workflow dependencies
+// extractDependencies lists the workflows a queued workflow waits for: its after entries
 func (wo *WorkflowOrchestrator) extractDependencies(workflow *Workflow) []string {
-	dependencies := make([]string, 0)
-	
-	// If not independent, it may have external dependencies
-	if !workflow.IndependentExecution {
-		// For now, assume workflows with the same target might depend on each other
-		// This can be enhanced with explicit dependency declarations
-	}
-	
-	return dependencies
+	dependencies := make([]string, 0, len(workflow.After))
+	return append(dependencies, workflow.After...)
 }
 
 // insertByPriority inserts a workflow into the queue based on priority
@@ -664,24 +852,17 @@ func (wo *WorkflowOrchestrator) insertByPriority(queueItem *WorkflowQueueItem) {
 func (wo *WorkflowOrchestrator) findNextExecutableWorkflow() int {
 	for i, queueItem := range wo.workflowQueue {
 		// Check if dependencies are satisfied
-		if wo.areDependenciesSatisfied(queueItem.Dependencies) {
-			return i
+		if !wo.areDependenciesSatisfied(queueItem) {
+			continue
 		}
-	}
-	return -1
-}
-
-// areDependenciesSatisfied checks if all dependencies for a workflow are met
-func (wo *WorkflowOrchestrator) areDependenciesSatisfied(dependencies []string) bool {
-	for _, dep := range dependencies {
-		// Check if dependency workflow is completed
-		if execution, exists := wo.activeWorkflows[dep]; exists {
-			if execution.Status != WorkflowStatusCompleted {
-				return false
-			}
+		// A workflow whose resources do not fit waits; a lighter one behind it may start
+		if fits, reason := wo.ResourceMonitor.canReserve(queueItem.Workflow.Resources); !fits {
+			wo.debugLogger.Debug("Workflow waits for resources", "workflow", queueItem.Workflow.Name, "target", queueItem.Target, "reason", reason)
+			continue
 		}
+		return i
 	}
-	return true
+	return -1
 }
 
 // GetActiveWorkflows returns information about currently running workflows
@@ -718,20 +899,21 @@ func (rm *ResourceMonitor) canStartNewWorkflow() bool {
 	// Debug: Always log resource check attempts
 	if rm.debugLogger != nil {
 		rm.debugLogger.Debug("Checking workflow start permissions", 
-			"cpu_percent", rm.currentCPU, "cpu_max", rm.maxCPUUsage,
-			"memory_percent", rm.currentMemory, "memory_max", rm.maxMemoryUsage,
+			"cpu_percent", formatPercent(rm.cpuReading.reading(rm.currentCPU)), "cpu_max", rm.maxCPUUsage,
+			"memory_percent", formatPercent(rm.memoryReading.reading(rm.currentMemory)), "memory_max", rm.maxMemoryUsage,
 			"active_tools", rm.activeTools, "max_tools", rm.maxActiveTools)
 	}
 	
 	// Check CPU and memory limits
-	if rm.currentCPU > rm.maxCPUUsage {
+	// Unavailable readings do not block: without them the limits cannot be judged
+	if rm.cpuReading.available && rm.currentCPU > rm.maxCPUUsage {
 		if rm.debugLogger != nil {
 			rm.debugLogger.Debug("BLOCKED: CPU usage too high", "current", rm.currentCPU, "max", rm.maxCPUUsage)
 		}
 		return false
 	}
 	
-	if rm.currentMemory > rm.maxMemoryUsage {
+	if rm.memoryReading.available && rm.currentMemory > rm.maxMemoryUsage {
 		if rm.debugLogger != nil {
 			rm.debugLogger.Debug("BLOCKED: Memory usage too high", "current", rm.currentMemory, "max", rm.maxMemoryUsage)
 		}
@@ -767,20 +949,37 @@ func (rm *ResourceMonitor) UpdateResourceUsageFromSystem() error {
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
+	// Collectors that keep failing (permission denied in hardened containers) are switched
+	// off; the error reports each one once, when it is
+	var errs []error
+
 	// Get CPU usage
-	cpuPercents, err := cpu.Percent(0, false)
-	if err == nil && len(cpuPercents) > 0 {
-		rm.currentCPU = cpuPercents[0]
+	if !rm.cpuReading.disabled {
+		cpuPercents, err := cpu.Percent(0, false)
+		if err == nil && len(cpuPercents) == 0 {
+			err = fmt.Errorf("no CPU reading returned")
+		}
+		if err == nil {
+			rm.currentCPU = cpuPercents[0]
+		}
+		if err := rm.cpuReading.record(err); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	// Get memory usage
-	memInfo, err := mem.VirtualMemory()
-	if err == nil {
-		rm.currentMemory = memInfo.UsedPercent
+	if !rm.memoryReading.disabled {
+		memInfo, err := mem.VirtualMemory()
+		if err == nil {
+			rm.currentMemory = memInfo.UsedPercent
+		}
+		if err := rm.memoryReading.record(err); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	// Active tools count needs to be updated separately by the orchestrator
-	return nil
+	return errors.Join(errs...)
 }
 
 // ExecuteStep executes a single workflow step with parallel mode support
@@ -796,11 +995,30 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 		StepName:     step.Name,
 		Tool:         step.Tool,
 		Modes:        step.Modes,
+		Matrix:       step.Matrix,
+		DependsOn:    step.DependsOn,
 		Success:      false,
 		Results:      []*ExecutionResult{},
 		CombinedVars: make(map[string]string),
 	}
 	
+	// Skip the step when its run_if condition does not hold for the variables gathered so far
+	if step.RunIf != "" {
+		run, err := we.evaluateRunIf(step, target)
+		if err != nil {
+			result.ErrorMessage = err.Error()
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+		if !run {
+			result.Success = true
+			result.Skipped = true
+			result.SkipReason = fmt.Sprintf("run_if %s is false", step.RunIf)
+			result.Duration = time.Since(startTime)
+			return result, nil
+		}
+	}
+
 	// Create a copy of options to modify without affecting the original
 	var stepOptions *ExecutionOptions
 	if options != nil {
@@ -812,6 +1030,8 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 			CaptureOutput:  options.CaptureOutput,
 			ValidateOutput: options.ValidateOutput,
 			Priority:       options.Priority,
+			Parameters:     options.Parameters,
+			Retry:          options.Retry,
 		}
 	} else {
 		stepOptions = &ExecutionOptions{
@@ -826,10 +1046,30 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 		stepOptions.Priority = 100 // Default medium priority
 	}
 
-	// Apply variable mappings for this step
+	// The step's retry policy takes precedence over the caller's and the global default
+	if step.Retry != nil {
+		stepOptions.Retry = step.Retry
+	}
+
+	// Step parameters take precedence over any inherited from the caller
+	if len(step.Parameters) > 0 {
+		stepOptions.Parameters = step.Parameters
+	}
+
+	// Set the step's inputs, then its variable mappings and templates (see step_variables.go)
+	for _, input := range we.engine.GetTemplateResolver().ApplyStepInputs(step.Inputs) {
+		we.engine.debugLogger.Debug("Step input not set", "step", step.Name, "input", input)
+	}
 	if step.Variables != nil {
-		for sourceVar, targetVar := range step.Variables {
-			we.engine.GetTemplateResolver().MapWorkflowVariable(sourceVar, targetVar)
+		execCtx := we.engine.newExecutionContext(target, step.Tool, "", workflowName, step.Name)
+		unset, err := we.engine.GetTemplateResolver().ApplyStepVariables(step.Variables, execCtx)
+		if err != nil {
+			result.ErrorMessage = err.Error()
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+		for _, variable := range unset {
+			we.engine.debugLogger.Debug("Step variable not set", "step", step.Name, "variable", variable)
 		}
 	}
 
@@ -845,7 +1085,7 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 	} else {
 		// Execute modes sequentially
 		for _, mode := range step.Modes {
-			execResult, err := we.engine.ExecuteToolWithContext(ctx, step.Tool, mode, target, workflowName, step.Name, stepOptions)
+			execResult, err := we.executeModeWithFallback(ctx, step, mode, target, workflowName, stepOptions)
 			if err != nil {
 				result.ErrorMessage = fmt.Sprintf("mode %s failed: %v", mode, err)
 				result.Duration = time.Since(startTime)
@@ -857,11 +1097,12 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 
 	// Combine results if requested and tool has a combiner (even for single results to create magic variables)
 	if step.CombineResults && len(result.Results) >= 1 {
-		combinedVars, err := we.combineToolResults(step.Tool, result.Results)
+		combinedVars, err := we.combineToolResults(step.Tool, result.Results, step.Combiner)
 		if err != nil {
 			result.ErrorMessage = fmt.Sprintf("result combining failed: %v", err)
 		} else {
 			result.CombinedVars = combinedVars
+			result.CombinerOptions, _ = we.ResolveCombinerOptions(step.Tool, step.Combiner)
 			
 			// Add combined variables to template resolver
 			for varName, varValue := range combinedVars {
@@ -870,6 +1111,15 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 		}
 	}
 
+	// Publish the step's outputs under the names later steps read them by
+	if len(step.Outputs) > 0 {
+		published, unset := we.engine.GetTemplateResolver().PublishStepOutputs(step.Outputs, result.CombinedVars)
+		result.Outputs = published
+		for _, output := range unset {
+			we.engine.debugLogger.Debug("Step output not set", "step", step.Name, "output", output)
+		}
+	}
+
 	// Check if all executions succeeded
 	allSucceeded := true
 	for _, execResult := range result.Results {
@@ -884,6 +1134,19 @@ func (we *WorkflowExecutor) ExecuteStepWithWorkflow(ctx context.Context, step *W
 	return result, nil
 }
 
+// evaluateRunIf evaluates a step's run_if against the current magic variables and the target
+func (we *WorkflowExecutor) evaluateRunIf(step *WorkflowStep, target string) (bool, error) {
+	condition, err := ParseCondition(step.RunIf)
+	if err != nil {
+		return false, err
+	}
+	vars := we.engine.GetMagicVariables()
+	if _, exists := vars["target"]; !exists {
+		vars["target"] = target
+	}
+	return condition.Evaluate(vars)
+}
+
 // executeModesParallel executes multiple modes in parallel using goroutines
 func (we *WorkflowExecutor) executeModesParallel(ctx context.Context, step *WorkflowStep, target string, options *ExecutionOptions) ([]*ExecutionResult, error) {
 	return we.executeModesParallelWithWorkflow(ctx, step, target, "", options)
@@ -915,7 +1178,7 @@ func (we *WorkflowExecutor) executeModesParallelWithWorkflow(ctx context.Context
 			defer func() { <-semaphore }()
 			
 			// Execute this mode
-			execResult, err := we.engine.ExecuteToolWithContext(ctx, step.Tool, modeName, target, workflowName, step.Name, options)
+			execResult, err := we.executeModeWithFallback(ctx, step, modeName, target, workflowName, options)
 			results[index] = execResult
 			errors[index] = err
 		}(i, mode)
@@ -944,7 +1207,7 @@ func (we *WorkflowExecutor) executeModesParallelWithWorkflow(ctx context.Context
 }
 
 // combineToolResults combines multiple execution results using tool-specific combiner
-func (we *WorkflowExecutor) combineToolResults(toolName string, results []*ExecutionResult) (map[string]string, error) {
+func (we *WorkflowExecutor) combineToolResults(toolName string, results []*ExecutionResult, rawOptions map[string]string) (map[string]string, error) {
 	combiner, exists := we.combiners[toolName]
 	if !exists {
 		return nil, fmt.Errorf("no result combiner registered for tool: %s", toolName)
@@ -965,9 +1228,61 @@ func (we *WorkflowExecutor) combineToolResults(toolName string, results []*Execu
 	// Use tool-specific combiner
 	switch c := combiner.(type) {
 	case *naabu.ResultCombiner:
-		return c.CombineResults(outputPaths), nil
+		opts, err := merge.ParseOptions(toolName, rawOptions)
+		if err != nil {
+			return nil, err
+		}
+		return c.CombineResultsWithOptions(outputPaths, opts), nil
 	case *nmap.ResultCombiner:
+		opts, err := merge.ParseOptions(toolName, rawOptions)
+		if err != nil {
+			return nil, err
+		}
+		return c.CombineResultsWithOptions(outputPaths, opts), nil
+	case *masscan.ResultCombiner:
+		opts, err := merge.ParseOptions(toolName, rawOptions)
+		if err != nil {
+			return nil, err
+		}
+		return c.CombineResultsWithOptions(outputPaths, opts), nil
+	case *httpx.ResultCombiner:
+		if err := httpx.ValidateCombinerOptions(rawOptions); err != nil {
+			return nil, err
+		}
 		return c.CombineResults(outputPaths), nil
+	case *subdomains.ResultCombiner:
+		if err := subdomains.ValidateCombinerOptions(rawOptions); err != nil {
+			return nil, err
+		}
+		// Names outside the target domain are dropped
+		return c.CombineResults(outputPaths, results[0].Target), nil
+	default:
+		return nil, fmt.Errorf("unsupported combiner type for tool: %s", toolName)
+	}
+}
+
+// ResolveCombinerOptions validates a step's combiner options and returns the effective values, defaults included
+func (we *WorkflowExecutor) ResolveCombinerOptions(toolName string, rawOptions map[string]string) (map[string]string, error) {
+	combiner, exists := we.combiners[toolName]
+	if !exists {
+		if len(rawOptions) > 0 {
+			return nil, fmt.Errorf("no result combiner registered for tool: %s", toolName)
+		}
+		return nil, nil
+	}
+
+	switch combiner.(type) {
+	case *naabu.ResultCombiner, *nmap.ResultCombiner, *masscan.ResultCombiner:
+		opts, err := merge.ParseOptions(toolName, rawOptions)
+		if err != nil {
+			return nil, err
+		}
+		return opts.AsMap(), nil
+	case *httpx.ResultCombiner:
+		// No options; nothing to record
+		return nil, httpx.ValidateCombinerOptions(rawOptions)
+	case *subdomains.ResultCombiner:
+		return nil, subdomains.ValidateCombinerOptions(rawOptions)
 	default:
 		return nil, fmt.Errorf("unsupported combiner type for tool: %s", toolName)
 	}
@@ -987,30 +1302,43 @@ func (we *WorkflowExecutor) ExecuteWorkflow(ctx context.Context, steps []*Workfl
 	return we.ExecuteWorkflowWithName(ctx, steps, target, "", options)
 }
 
-// ExecuteWorkflowWithName executes a complete workflow with workflow context for unique filenames
+// ExecuteWorkflowWithName executes a complete workflow with workflow context for unique filenames.
+// Steps run as a dependency graph; a step whose dependency failed does not run. Results are in
+// step order, each with the position it started in
 func (we *WorkflowExecutor) ExecuteWorkflowWithName(ctx context.Context, steps []*WorkflowStep, target, workflowName string, options *ExecutionOptions) ([]*WorkflowResult, error) {
-	var results []*WorkflowResult
-	completed := make(map[string]bool)
+	graph, err := newStepGraph(steps)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, step := range steps {
-		// Check dependencies
-		if step.DependsOn != "" && !completed[step.DependsOn] {
-			return results, fmt.Errorf("dependency '%s' not completed for step '%s'", step.DependsOn, step.Name)
+	stepResults := make([]*WorkflowResult, len(steps))
+	stepErrors := make([]error, len(steps))
+	graph.run(func(step, order int) {
+		for _, dependency := range graph.dependencies[step] {
+			if stepResults[dependency] == nil || !stepResults[dependency].Success {
+				stepErrors[step] = fmt.Errorf("dependency '%s' not completed for step '%s'", steps[dependency].Name, steps[step].Name)
+				return
+			}
 		}
-
-		// Execute step
-		stepResult, err := we.ExecuteStepWithWorkflow(ctx, step, target, workflowName, options)
+		result, err := we.ExecuteStepWithWorkflow(ctx, steps[step], target, workflowName, options)
 		if err != nil {
-			return results, fmt.Errorf("step '%s' failed: %w", step.Name, err)
+			stepErrors[step] = fmt.Errorf("step '%s' failed: %w", steps[step].Name, err)
+		} else if !result.Success {
+			stepErrors[step] = fmt.Errorf("step '%s' failed", steps[step].Name)
 		}
+		result.Order = order
+		stepResults[step] = result
+	})
 
-		results = append(results, stepResult)
-		completed[step.Name] = stepResult.Success
-
-		if !stepResult.Success {
-			return results, fmt.Errorf("step '%s' failed", step.Name)
+	var results []*WorkflowResult
+	var firstError error
+	for i, result := range stepResults {
+		if result != nil {
+			results = append(results, result)
+		}
+		if stepErrors[i] != nil && firstError == nil {
+			firstError = stepErrors[i]
 		}
 	}
-
-	return results, nil
+	return results, firstError
 }
\ No newline at end of file
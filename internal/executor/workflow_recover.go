@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverWorkflow is deferred by executeWorkflowAsync: a panic in the workflow's goroutine fails
+// the workflow, records it in the run report and releases its slot and resources, so it is
+// never left listed as running and the rest of the run goes on
+func (wo *WorkflowOrchestrator) recoverWorkflow(ctx context.Context, workflowKey string, queueItem *WorkflowQueueItem, execution *WorkflowExecution) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	err := fmt.Errorf("workflow panicked: %v", recovered)
+	wo.debugLogger.Error("Workflow panicked", "workflow", queueItem.Workflow.Name, "target", queueItem.Target,
+		"panic", recovered, "stack", string(debug.Stack()))
+
+	execution.Status = WorkflowStatusFailed
+	execution.Error = err
+	wo.finishExecution(execution)
+	wo.events.Publish(Event{Type: EventWorkflowFailed, Workflow: queueItem.Workflow.Name, Target: queueItem.Target,
+		StepCount: len(queueItem.Workflow.Steps), TriggeredBy: queueItem.TriggeredBy,
+		Message: fmt.Sprintf("Workflow failed: %v", err), Error: err.Error()})
+	wo.recordWorkflowReport(execution)
+	wo.releaseWorkflow(ctx, workflowKey)
+}
+
+// recoverStep is deferred around each step of a workflow: a panic in the step becomes its
+// error, so the workflow fails like it does for any other step error
+func (wo *WorkflowOrchestrator) recoverStep(stepErr *error, queueItem *WorkflowQueueItem, step *WorkflowStep) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	*stepErr = fmt.Errorf("step %s panicked: %v", step.Name, recovered)
+	wo.debugLogger.Error("Step panicked", "workflow", queueItem.Workflow.Name, "step", step.Name,
+		"target", queueItem.Target, "panic", recovered, "stack", string(debug.Stack()))
+}
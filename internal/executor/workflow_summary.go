@@ -0,0 +1,220 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WorkflowSummaryDirName is the workspace directory holding one summary file per workflow
+const WorkflowSummaryDirName = "summaries"
+
+// workflowSummarySuffix ends every summary file name: "<workflow>.summary.json"
+const workflowSummarySuffix = ".summary.json"
+
+// WorkflowSummary is the key results of a finished workflow. Its variables are also exported
+// to the whole run as {{workflow_<name>_<key>}}, so later workflows and scripts reading the
+// workspace consume the results of earlier workflows the same way
+type WorkflowSummary struct {
+	Workflow   string            `json:"workflow"`
+	Targets    []string          `json:"targets"` // Every target the workflow ran against in this run
+	Status     string            `json:"status"`  // Status of its latest execution
+	FinishedAt time.Time         `json:"finished_at"`
+	OpenPorts  []int             `json:"open_ports"`
+	HostsAlive []string          `json:"hosts_alive"`
+	URLs       []string          `json:"urls"`
+	Variables  map[string]string `json:"variables"`
+}
+
+// workflowSummaries keeps the summary of every workflow finished in this run
+type workflowSummaries struct {
+	mutex     sync.Mutex
+	dir       string // Where summary files are written ("" = variables only)
+	dirPerm   os.FileMode
+	filePerm  os.FileMode
+	summaries map[string]*WorkflowSummary // Keyed by variable name of the workflow
+}
+
+// WorkflowVariableName returns the name a workflow's summaries use in variables and file names,
+// e.g. "Enhanced Reconnaissance" -> "enhanced_reconnaissance"
+func WorkflowVariableName(workflow string) string {
+	var name strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(workflow) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if underscore && name.Len() > 0 {
+				name.WriteByte('_')
+			}
+			name.WriteRune(r)
+			underscore = false
+		} else {
+			underscore = true
+		}
+	}
+	return name.String()
+}
+
+// LoadWorkflowSummaries reads the workflow summaries written to a workspace
+func LoadWorkflowSummaries(workspaceDir string) ([]*WorkflowSummary, error) {
+	return loadWorkflowSummaries(filepath.Join(workspaceDir, WorkflowSummaryDirName))
+}
+
+func loadWorkflowSummaries(dir string) ([]*WorkflowSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+workflowSummarySuffix))
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*WorkflowSummary, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workflow summary: %w", err)
+		}
+		var summary WorkflowSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+		summaries = append(summaries, &summary)
+	}
+	return summaries, nil
+}
+
+// SetSummaryDir writes a summary file per finished workflow to dir. Summaries already there
+// (from the run a resumed queue continues) are loaded and their variables exported again
+func (wo *WorkflowOrchestrator) SetSummaryDir(dir string, dirPerm, filePerm os.FileMode) {
+	wo.summaries.mutex.Lock()
+	defer wo.summaries.mutex.Unlock()
+	wo.summaries.dir, wo.summaries.dirPerm, wo.summaries.filePerm = dir, dirPerm, filePerm
+
+	existing, err := loadWorkflowSummaries(dir)
+	if err != nil {
+		wo.debugLogger.Printf("Previous workflow summaries not loaded: %v", err)
+		return
+	}
+	for _, summary := range existing {
+		wo.exportSummary(summary)
+	}
+}
+
+// recordWorkflowSummary adds a finished workflow's results to its summary, exports the summary
+// variables and rewrites the summary file
+func (wo *WorkflowOrchestrator) recordWorkflowSummary(execution *WorkflowExecution) {
+	wo.summaries.mutex.Lock()
+	defer wo.summaries.mutex.Unlock()
+	if wo.summaries.summaries == nil {
+		wo.summaries.summaries = make(map[string]*WorkflowSummary)
+	}
+
+	name := WorkflowVariableName(execution.Workflow.Name)
+	summary, exists := wo.summaries.summaries[name]
+	if !exists {
+		summary = &WorkflowSummary{Workflow: execution.Workflow.Name, OpenPorts: []int{}, HostsAlive: []string{}, URLs: []string{}}
+	}
+	summary.Status = execution.Status.String()
+	summary.FinishedAt = execution.EndTime
+	if !containsString(summary.Targets, execution.Target) {
+		summary.Targets = append(summary.Targets, execution.Target)
+	}
+
+	for _, step := range execution.StepResults {
+		for _, f := range stepFindings(wo.executor.engine, wo.findingsCatalog(), step) {
+			if f.Port > 0 && f.State == "open" {
+				summary.OpenPorts = appendUniquePort(summary.OpenPorts, f.Port)
+				summary.HostsAlive = appendUniqueString(summary.HostsAlive, f.Host)
+			} else if f.Port == 0 && f.State == "up" {
+				summary.HostsAlive = appendUniqueString(summary.HostsAlive, f.Host)
+			}
+			if f.URL != "" {
+				summary.URLs = appendUniqueString(summary.URLs, f.URL)
+			}
+		}
+	}
+	sort.Ints(summary.OpenPorts)
+	sort.Strings(summary.HostsAlive)
+	sort.Strings(summary.URLs)
+
+	ports := make([]string, len(summary.OpenPorts))
+	for i, port := range summary.OpenPorts {
+		ports[i] = strconv.Itoa(port)
+	}
+	prefix := "workflow_" + name + "_"
+	summary.Variables = map[string]string{
+		prefix + "status":            summary.Status,
+		prefix + "open_ports":        strings.Join(ports, ","),
+		prefix + "open_port_count":   strconv.Itoa(len(summary.OpenPorts)),
+		prefix + "hosts_alive":       strings.Join(summary.HostsAlive, ","),
+		prefix + "hosts_alive_count": strconv.Itoa(len(summary.HostsAlive)),
+		prefix + "urls":              strings.Join(summary.URLs, ","),
+		prefix + "url_count":         strconv.Itoa(len(summary.URLs)),
+	}
+	wo.exportSummary(summary)
+
+	if wo.summaries.dir == "" {
+		return
+	}
+	if err := wo.writeSummary(name, summary); err != nil {
+		wo.debugLogger.Printf("Failed to write workflow summary for %s: %v", execution.Workflow.Name, err)
+	}
+}
+
+// exportSummary keeps a summary and makes its variables available to every later step; callers
+// hold wo.summaries.mutex
+func (wo *WorkflowOrchestrator) exportSummary(summary *WorkflowSummary) {
+	if wo.summaries.summaries == nil {
+		wo.summaries.summaries = make(map[string]*WorkflowSummary)
+	}
+	wo.summaries.summaries[WorkflowVariableName(summary.Workflow)] = summary
+	if wo.executor == nil || wo.executor.engine == nil {
+		return
+	}
+	for name, value := range summary.Variables {
+		wo.executor.engine.GetTemplateResolver().AddVariable(name, value)
+	}
+}
+
+func (wo *WorkflowOrchestrator) writeSummary(name string, summary *WorkflowSummary) error {
+	if err := os.MkdirAll(wo.summaries.dir, wo.summaries.dirPerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(wo.summaries.dir, name+workflowSummarySuffix)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, wo.summaries.filePerm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func appendUniquePort(ports []int, port int) []int {
+	for _, existing := range ports {
+		if existing == port {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
+
+func appendUniqueString(values []string, value string) []string {
+	if value == "" || containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
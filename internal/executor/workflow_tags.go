@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// A workflow's tags describe it (fast, loud, passive, web) so that profiles can pick workflows
+// without naming them. A profile's tag filter combines tags with &&, || and ! and parentheses,
+// like run_if: `passive && !loud` selects the workflows tagged passive and not tagged loud
+
+// tagPattern is what a tag looks like: lowercase letters, digits and underscores
+var tagPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// TagFilter is a parsed profile tag filter
+type TagFilter struct {
+	expression string
+	root       conditionNode
+}
+
+// tagFilterOperators are the only operators of a tag filter; comparisons and functions of
+// run_if are not
+var tagFilterOperators = []string{"&&", "||", "!", "(", ")"}
+
+// ParseTagFilter parses a tag filter such as `passive && !loud`
+func ParseTagFilter(expression string) (*TagFilter, error) {
+	tokens, err := tokenizeCondition(expression)
+	for i := 0; err == nil && i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.kind == tokenIdent:
+			if !tagPattern.MatchString(token.text) {
+				err = fmt.Errorf("'%s' is not a tag (lowercase letters, digits and underscores)", token.text)
+			}
+		case token.kind != tokenOperator || !slices.Contains(tagFilterOperators, token.text),
+			token.text == "(" && i > 0 && tokens[i-1].kind == tokenIdent: // A function call
+			err = fmt.Errorf("unexpected %q: only tags, &&, ||, ! and parentheses are allowed", token.text)
+		}
+	}
+	var root conditionNode
+	if err == nil {
+		root, err = parseConditionTokens(tokens)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag filter %q: %v", expression, err)
+	}
+	return &TagFilter{expression: expression, root: root}, nil
+}
+
+// Matches reports whether a workflow with these tags passes the filter
+func (f *TagFilter) Matches(tags []string) bool {
+	vars := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		vars[tag] = "true"
+	}
+	value, err := f.root.eval(vars)
+	return err == nil && truthy(value)
+}
+
+// String returns the original expression
+func (f *TagFilter) String() string {
+	return f.expression
+}
+
+// ValidateTags checks the tags of a workflow when it loads
+func ValidateTags(workflow *Workflow) error {
+	seen := make(map[string]bool, len(workflow.Tags))
+	for _, tag := range workflow.Tags {
+		switch {
+		case !tagPattern.MatchString(tag) || tag == "true" || tag == "false":
+			return fmt.Errorf("invalid tag '%s': use lowercase letters, digits and underscores, starting with a letter", tag)
+		case seen[tag]:
+			return fmt.Errorf("tags lists '%s' twice", tag)
+		}
+		seen[tag] = true
+	}
+	return nil
+}
+
+// WorkflowTags returns every tag the workflows use, sorted
+func WorkflowTags(workflows map[string]*Workflow) []string {
+	seen := make(map[string]bool)
+	for _, workflow := range workflows {
+		for _, tag := range workflow.Tags {
+			seen[tag] = true
+		}
+	}
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
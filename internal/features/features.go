@@ -0,0 +1,118 @@
+// Package features gates experimental subsystems behind run-time flags, so large changes can
+// ship disabled and be turned on per user or per machine without a separate build
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EnvVariable turns flags on ("name") or off ("-name") for one invocation, comma-separated
+const EnvVariable = "IPCRAWLER_EXPERIMENTAL"
+
+// Experimental flags; check them with Enabled
+const (
+	DAGScheduler    = "dag_scheduler"
+	StreamingOutput = "streaming_output"
+)
+
+// Flag describes an experimental subsystem
+type Flag struct {
+	Name        string
+	Description string
+}
+
+// Flags lists every known flag; all are off unless configured
+var Flags = []Flag{
+	{DAGScheduler, "Schedule workflow steps as a dependency graph instead of in queue order"},
+	{StreamingOutput, "Stream tool output to the console and API while tools run"},
+}
+
+var (
+	mutex   sync.RWMutex
+	enabled = make(map[string]bool)
+)
+
+// Configure replaces the flag states with the given layers, later layers overriding earlier
+// ones (e.g. configs/experimental.yaml, then ~/.ipcrawler/config.yaml, then the environment).
+// Unknown names are reported in the error; the known ones are still applied
+func Configure(layers ...map[string]bool) error {
+	states := make(map[string]bool)
+	var unknown []string
+	for _, layer := range layers {
+		for name, on := range layer {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if !Known(name) {
+				unknown = append(unknown, name)
+				continue
+			}
+			states[name] = on
+		}
+	}
+
+	mutex.Lock()
+	enabled = states
+	mutex.Unlock()
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown experimental feature(s): %s (available: %s)", strings.Join(unknown, ", "), strings.Join(Names(), ", "))
+	}
+	return nil
+}
+
+// Enabled reports whether an experimental flag is on
+func Enabled(name string) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return enabled[name]
+}
+
+// EnabledNames returns the flags that are on, sorted
+func EnabledNames() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	var names []string
+	for name, on := range enabled {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Known reports whether name is a flag
+func Known(name string) bool {
+	for _, flag := range Flags {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of all flags
+func Names() []string {
+	names := make([]string, len(Flags))
+	for i, flag := range Flags {
+		names[i] = flag.Name
+	}
+	return names
+}
+
+// ParseList parses the EnvVariable form: "dag_scheduler,-streaming_output"
+func ParseList(list string) map[string]bool {
+	states := make(map[string]bool)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if name, off := strings.CutPrefix(item, "-"); off {
+			states[name] = false
+		} else if item != "" {
+			states[item] = true
+		}
+	}
+	return states
+}
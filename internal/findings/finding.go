@@ -0,0 +1,157 @@
+package findings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Finding is a single host/port observation extracted from a tool's output
+type Finding struct {
+	Tool         string         `json:"tool"`
+	Host         string         `json:"host"`
+	Hostnames    []string       `json:"hostnames,omitempty"`
+	Port         int            `json:"port,omitempty"`
+	Protocol     string         `json:"protocol,omitempty"`
+	State        string         `json:"state,omitempty"`
+	Service      string         `json:"service,omitempty"`
+	Product      string         `json:"product,omitempty"`
+	Version      string         `json:"version,omitempty"`
+	TLS          bool           `json:"tls,omitempty"`
+	URL          string         `json:"url,omitempty"`          // Probed URL for web services (e.g. httpx)
+	StatusCode   int            `json:"status_code,omitempty"`  // HTTP status the URL answered with
+	Title        string         `json:"title,omitempty"`        // HTML page title
+	Technologies []string       `json:"technologies,omitempty"` // Detected web technologies
+	OS           string         `json:"os,omitempty"`           // Best OS match for the host, e.g. "Linux 5.0 - 5.4 (95%)"
+	Scripts      []ScriptResult `json:"scripts,omitempty"`      // Script results for the port (or host, e.g. nmap NSE)
+	Labels       []string       `json:"labels,omitempty"`       // Host labels from labeling rules and the run's --label
+	Source       string         `json:"source" redact:"-"`      // Output file path relative to the workspace
+}
+
+// ScriptResult is the output of one script a tool ran against a host or port
+type ScriptResult struct {
+	ID     string `json:"id"`
+	Output string `json:"output"`
+}
+
+// Extractor turns a tool's output file into findings
+// Each tool implements this interface in its own isolated package
+type Extractor interface {
+	GetToolName() string
+	ExtractFindings(outputPath string) ([]Finding, error)
+}
+
+// Catalog holds the registered extractors, keyed by tool name
+type Catalog struct {
+	extractors map[string]Extractor
+}
+
+// NewCatalog creates an empty extractor catalog
+func NewCatalog() *Catalog {
+	return &Catalog{
+		extractors: make(map[string]Extractor),
+	}
+}
+
+// Register adds a tool-specific extractor
+func (c *Catalog) Register(extractor Extractor) {
+	c.extractors[strings.ToLower(extractor.GetToolName())] = extractor
+}
+
+// ExtractorFor returns the extractor responsible for an output file, based on the
+// "<tool>_<mode>_<target>..." naming used for workspace scan files
+func (c *Catalog) ExtractorFor(fileName string) (Extractor, bool) {
+	base := strings.ToLower(filepath.Base(fileName))
+	toolName, _, found := strings.Cut(base, "_")
+	if !found {
+		return nil, false
+	}
+	extractor, exists := c.extractors[toolName]
+	return extractor, exists
+}
+
+// LoadWorkspace extracts findings from every recognized file in the workspace scans directory.
+// Files that fail to parse are reported in the returned warnings rather than aborting the load
+func (c *Catalog) LoadWorkspace(workspaceDir string) ([]Finding, []string, error) {
+	scansDir := filepath.Join(workspaceDir, "scans")
+	entries, err := os.ReadDir(scansDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read scans directory: %w", err)
+	}
+
+	var results []Finding
+	var warnings []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		extractor, exists := c.ExtractorFor(entry.Name())
+		if !exists {
+			continue
+		}
+
+		path := filepath.Join(scansDir, entry.Name())
+		found, err := extractor.ExtractFindings(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", entry.Name(), err))
+			continue
+		}
+
+		relPath := filepath.Join("scans", entry.Name())
+		for i := range found {
+			found[i].Tool = extractor.GetToolName()
+			found[i].Source = relPath
+		}
+		results = append(results, found...)
+	}
+
+	Sort(results)
+	return results, warnings, nil
+}
+
+// ApplyLabels sets each finding's labels from labelsFor(host) plus labels shared by the whole workspace
+func ApplyLabels(list []Finding, labelsFor func(host string) []string, workspaceLabels []string) {
+	for i := range list {
+		var labels []string
+		if labelsFor != nil {
+			labels = append(labels, labelsFor(list[i].Host)...)
+		}
+		labels = append(labels, workspaceLabels...)
+		list[i].Labels = mergeLabels(list[i].Labels, labels)
+	}
+}
+
+// mergeLabels appends labels that are not already present
+func mergeLabels(existing, extra []string) []string {
+	seen := make(map[string]bool, len(existing))
+	for _, label := range existing {
+		seen[label] = true
+	}
+	for _, label := range extra {
+		if !seen[label] {
+			seen[label] = true
+			existing = append(existing, label)
+		}
+	}
+	sort.Strings(existing)
+	return existing
+}
+
+// Sort orders findings by host, port, protocol and tool
+func Sort(list []Finding) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.Tool < b.Tool
+	})
+}
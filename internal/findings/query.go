@@ -0,0 +1,490 @@
+package findings
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a compiled findings search expression
+//
+// Syntax:
+//
+//	port:445                 field match (case-insensitive)
+//	port:1-1024 port:>8000   numeric ranges and comparisons
+//	service:http*            glob wildcards: * and ? match any characters, / included
+//	label:dmz                any of the host's labels
+//	script:smb-vuln*         any script that produced output (by script ID)
+//	os:*linux*               the host's best OS match
+//	status:>=400 tech:nginx  HTTP status codes and web technologies (e.g. httpx)
+//	smb                      bare words match any text field
+//	a AND b, a b             both (AND is implicit)
+//	a OR b                   either
+//	NOT a, -a                negation
+//	(a OR b) AND c           grouping
+//	title:"Welcome page"     quotes keep spaces, parentheses and AND/OR/NOT in one term
+type Query struct {
+	root node
+}
+
+// Fields lists the field names accepted in queries
+var Fields = []string{"host", "hostname", "port", "protocol", "state", "service", "product", "version", "os", "script", "tool", "tls", "label", "source", "url", "status", "title", "tech"}
+
+var fieldAliases = map[string]string{
+	"ip":         "host",
+	"proto":      "protocol",
+	"svc":        "service",
+	"file":       "source",
+	"tag":        "label",
+	"technology": "tech",
+}
+
+// ParseQuery compiles a query string; an empty query matches everything
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &Query{root: matchAll{}}, nil
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s' in query", p.tokens[p.pos].text)
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether a finding satisfies the query
+func (q *Query) Match(f Finding) bool {
+	return q.root.match(f)
+}
+
+// Filter returns the findings that satisfy the query
+func (q *Query) Filter(list []Finding) []Finding {
+	matched := make([]Finding, 0)
+	for _, f := range list {
+		if q.Match(f) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// Expression tree
+
+type node interface {
+	match(f Finding) bool
+}
+
+type matchAll struct{}
+
+func (matchAll) match(Finding) bool { return true }
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(f Finding) bool { return n.left.match(f) && n.right.match(f) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(f Finding) bool { return n.left.match(f) || n.right.match(f) }
+
+type notNode struct{ inner node }
+
+func (n notNode) match(f Finding) bool { return !n.inner.match(f) }
+
+// fieldNode matches one field against a value, glob, range or comparison
+type fieldNode struct {
+	field string
+	value string         // Lowercased
+	glob  *regexp.Regexp // Compiled value when it has wildcards
+	op    string         // "=", "<", ">", "<=", ">=", "range"
+	low   int
+	high  int
+}
+
+func (n fieldNode) match(f Finding) bool {
+	switch n.field {
+	case "port":
+		return n.matchNumber(f.Port)
+	case "status":
+		return f.StatusCode != 0 && n.matchNumber(f.StatusCode)
+	case "tech":
+		for _, technology := range f.Technologies {
+			if n.matchText(technology) {
+				return true
+			}
+		}
+		return false
+	case "tls":
+		return strconv.FormatBool(f.TLS) == n.value
+	case "label":
+		for _, label := range f.Labels {
+			if n.matchText(label) {
+				return true
+			}
+		}
+		return false
+	case "hostname":
+		for _, hostname := range f.Hostnames {
+			if n.matchText(hostname) {
+				return true
+			}
+		}
+		return false
+	case "script":
+		for _, script := range f.Scripts {
+			if n.matchText(script.ID) {
+				return true
+			}
+		}
+		return false
+	}
+	return n.matchText(fieldValue(f, n.field))
+}
+
+func (n fieldNode) matchNumber(value int) bool {
+	switch n.op {
+	case "<":
+		return value < n.low
+	case "<=":
+		return value <= n.low
+	case ">":
+		return value > n.low
+	case ">=":
+		return value >= n.low
+	case "range":
+		return value >= n.low && value <= n.high
+	default:
+		return value == n.low
+	}
+}
+
+func (n fieldNode) matchText(value string) bool {
+	value = strings.ToLower(value)
+	if n.glob != nil {
+		return n.glob.MatchString(value)
+	}
+	return value == n.value
+}
+
+// wordNode matches a bare word against every text field
+type wordNode struct{ word string }
+
+func (n wordNode) match(f Finding) bool {
+	for _, field := range []string{"host", "protocol", "state", "service", "product", "version", "os", "tool", "url", "title"} {
+		if strings.Contains(strings.ToLower(fieldValue(f, field)), n.word) {
+			return true
+		}
+	}
+	for _, label := range f.Labels {
+		if strings.Contains(label, n.word) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValue(f Finding, field string) string {
+	switch field {
+	case "host":
+		return f.Host
+	case "protocol":
+		return f.Protocol
+	case "state":
+		return f.State
+	case "service":
+		return f.Service
+	case "product":
+		return f.Product
+	case "version":
+		return f.Version
+	case "os":
+		return f.OS
+	case "tool":
+		return f.Tool
+	case "source":
+		return f.Source
+	case "url":
+		return f.URL
+	case "title":
+		return f.Title
+	}
+	return ""
+}
+
+// Tokenizer
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenOpen, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenClose, ")"})
+			i++
+		case r == '-' && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, token{tokenNot, "-"})
+			i++
+		default:
+			var text strings.Builder
+			quoted := false
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if runes[i] == '"' {
+					end := i + 1
+					for end < len(runes) && runes[end] != '"' {
+						end++
+					}
+					if end >= len(runes) {
+						return nil, fmt.Errorf("unterminated quote in query")
+					}
+					text.WriteString(string(runes[i+1 : end]))
+					quoted = true
+					i = end + 1
+					continue
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+
+			// A quoted word is always a term, even "AND" or "OR"
+			word := text.String()
+			switch {
+			case quoted:
+				tokens = append(tokens, token{tokenTerm, word})
+			case word == "AND" || word == "&&":
+				tokens = append(tokens, token{tokenAnd, word})
+			case word == "OR" || word == "||":
+				tokens = append(tokens, token{tokenOr, word})
+			case word == "NOT" || word == "!":
+				tokens = append(tokens, token{tokenNot, word})
+			default:
+				tokens = append(tokens, token{tokenTerm, word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// Recursive descent parser: or -> and {OR and}; and -> not {[AND] not}; not -> NOT not | primary
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokenOr {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokenOr || tok.kind == tokenClose {
+			return left, nil
+		}
+		if tok.kind == tokenAnd {
+			p.pos++
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	tok, ok := p.peek()
+	if ok && tok.kind == tokenNot {
+		p.pos++
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("query ends unexpectedly")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case tokenOpen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing ')' in query")
+		}
+		p.pos++
+		return inner, nil
+	case tokenTerm:
+		return parseTerm(tok.text)
+	default:
+		return nil, fmt.Errorf("unexpected '%s' in query", tok.text)
+	}
+}
+
+// parseTerm turns "field:value" or a bare word into a node
+func parseTerm(text string) (node, error) {
+	field, value, hasField := strings.Cut(text, ":")
+	if !hasField {
+		return wordNode{word: strings.ToLower(text)}, nil
+	}
+
+	field = strings.ToLower(field)
+	if alias, exists := fieldAliases[field]; exists {
+		field = alias
+	}
+	if !isKnownField(field) {
+		return nil, fmt.Errorf("unknown field '%s' (valid: %s)", field, strings.Join(Fields, ", "))
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value for field '%s'", field)
+	}
+
+	n := fieldNode{field: field, value: strings.ToLower(value), op: "="}
+	switch field {
+	case "port", "status":
+		return parseNumberTerm(n, value)
+	case "tls":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls value '%s': must be true or false", value)
+		}
+		n.value = strconv.FormatBool(enabled)
+	default:
+		if strings.ContainsAny(n.value, "*?") {
+			glob, err := compileGlob(n.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s' for field '%s': %v", value, field, err)
+			}
+			n.glob = glob
+		}
+	}
+	return n, nil
+}
+
+// compileGlob turns a glob into an anchored regular expression. Unlike path.Match, * and ?
+// also match "/", so source:*nmap* and url:http* match paths and URLs; every other character
+// is literal
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	pattern.WriteString("(?s)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
+
+// parseNumberTerm handles numeric fields: port:N, port:N-M, port:>N, port:>=N, port:<N and
+// port:<=N, and the same forms for status
+func parseNumberTerm(n fieldNode, value string) (node, error) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, op) {
+			number, err := strconv.Atoi(value[len(op):])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", n.field, value)
+			}
+			n.op, n.low = op, number
+			return n, nil
+		}
+	}
+
+	if low, high, isRange := strings.Cut(value, "-"); isRange {
+		lowNumber, err1 := strconv.Atoi(low)
+		highNumber, err2 := strconv.Atoi(high)
+		if err1 != nil || err2 != nil || lowNumber > highNumber {
+			return nil, fmt.Errorf("invalid %s range '%s'", n.field, value)
+		}
+		n.op, n.low, n.high = "range", lowNumber, highNumber
+		return n, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s '%s'", n.field, value)
+	}
+	n.low = number
+	return n, nil
+}
+
+func isKnownField(field string) bool {
+	for _, known := range Fields {
+		if known == field {
+			return true
+		}
+	}
+	return false
+}
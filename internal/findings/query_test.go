@@ -0,0 +1,121 @@
+package findings
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// queryFindings are matched by every query test; findings are named host:port
+var queryFindings = []Finding{
+	{Tool: "nmap", Host: "10.0.0.1", Port: 22, Protocol: "tcp", State: "open", Service: "ssh", Product: "OpenSSH",
+		Labels: []string{"dmz"}, Source: "scans/nmap_tcp_10.0.0.1.xml"},
+	{Tool: "httpx", Host: "10.0.0.1", Port: 80, Protocol: "tcp", State: "open", Service: "http", StatusCode: 200,
+		URL: "http://10.0.0.1/", Title: "Welcome to nginx", Technologies: []string{"Nginx"}, Labels: []string{"dmz"},
+		Source: "scans/httpx_10.0.0.1.json"},
+	{Tool: "nmap", Host: "10.0.0.2", Port: 445, Protocol: "tcp", State: "open", Service: "microsoft-ds",
+		Scripts: []ScriptResult{{ID: "smb-vuln-ms17-010", Output: "VULNERABLE"}}, Source: "scans/nmap_smb_10.0.0.2.xml"},
+	{Tool: "httpx", Host: "10.0.0.2", Port: 8443, Protocol: "tcp", State: "open", Service: "https", TLS: true, StatusCode: 403,
+		URL: "https://10.0.0.2:8443/login", Title: "Login [admin]", Source: "scans/httpx_10.0.0.2.json"},
+}
+
+func TestParseQueryMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"empty", "", []string{"10.0.0.1:22", "10.0.0.1:80", "10.0.0.2:445", "10.0.0.2:8443"}},
+		{"field", "port:22", []string{"10.0.0.1:22"}},
+		{"range", "port:1-1024", []string{"10.0.0.1:22", "10.0.0.1:80", "10.0.0.2:445"}},
+		{"comparison", "port:>8000", []string{"10.0.0.2:8443"}},
+		{"status", "status:>=400", []string{"10.0.0.2:8443"}},
+		{"glob", "service:http*", []string{"10.0.0.1:80", "10.0.0.2:8443"}},
+		{"glob across slashes", "source:*nmap*", []string{"10.0.0.1:22", "10.0.0.2:445"}},
+		{"url glob", "url:http*", []string{"10.0.0.1:80", "10.0.0.2:8443"}},
+		{"url path glob", "url:*/login", []string{"10.0.0.2:8443"}},
+		{"single character glob", "source:scans/?map_*", []string{"10.0.0.1:22", "10.0.0.2:445"}},
+		{"anchored glob", "source:nmap*", nil},
+		{"literal brackets", "title:*[admin]", []string{"10.0.0.2:8443"}},
+		{"literal backslash", `title:*\*`, nil},
+		{"alias", "ip:10.0.0.2", []string{"10.0.0.2:445", "10.0.0.2:8443"}},
+		{"case insensitive", "label:DMZ", []string{"10.0.0.1:22", "10.0.0.1:80"}},
+		{"script", "script:smb-vuln*", []string{"10.0.0.2:445"}},
+		{"tech", "tech:nginx", []string{"10.0.0.1:80"}},
+		{"tls", "tls:true", []string{"10.0.0.2:8443"}},
+		{"bare word", "nginx", []string{"10.0.0.1:80"}},
+
+		// AND binds tighter than OR, NOT tighter than both
+		{"implicit and", "host:10.0.0.1 service:http", []string{"10.0.0.1:80"}},
+		{"and before or", "port:22 OR port:445 AND host:10.0.0.1", []string{"10.0.0.1:22"}},
+		{"implicit and before or", "port:22 OR port:80 service:http", []string{"10.0.0.1:22", "10.0.0.1:80"}},
+		{"grouping", "(port:22 OR port:445) AND host:10.0.0.2", []string{"10.0.0.2:445"}},
+		{"not before or", "NOT host:10.0.0.1 OR port:22", []string{"10.0.0.1:22", "10.0.0.2:445", "10.0.0.2:8443"}},
+		{"not group", "NOT (host:10.0.0.1 OR port:445)", []string{"10.0.0.2:8443"}},
+		{"symbols", "port:22 || port:445 && ! tool:httpx", []string{"10.0.0.1:22", "10.0.0.2:445"}},
+
+		// A leading dash negates; one inside a term does not
+		{"dash", "host:10.0.0.1 -port:22", []string{"10.0.0.1:80"}},
+		{"dash in group", "(-service:ssh) label:dmz", []string{"10.0.0.1:80"}},
+		{"double negation", "NOT -port:22", []string{"10.0.0.1:22"}},
+		{"dash in value", "service:microsoft-ds", []string{"10.0.0.2:445"}},
+
+		// Quotes keep spaces, parentheses and operators inside one term
+		{"quoted value", `title:"Welcome to nginx"`, []string{"10.0.0.1:80"}},
+		{"quoted glob", `title:"welcome *"`, []string{"10.0.0.1:80"}},
+		{"quoted word", `"to nginx"`, []string{"10.0.0.1:80"}},
+		{"quoted operator", `"OR"`, nil},
+		{"quoted parenthesis", `title:"(none)"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+			}
+			var got []string
+			for _, f := range query.Filter(queryFindings) {
+				got = append(got, fmt.Sprintf("%s:%d", f.Host, f.Port))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // Part of the error
+	}{
+		{`title:"unterminated`, "unterminated quote"},
+		{"(port:22", "missing ')'"},
+		{"port:22)", "unexpected ')'"},
+		{"()", "unexpected ')'"},
+		{"OR port:22", "unexpected 'OR'"},
+		{"port:22 AND", "ends unexpectedly"},
+		{"NOT", "ends unexpectedly"},
+		{"colour:red", "unknown field 'colour'"},
+		{"port:", "missing value for field 'port'"},
+		{"port:ssh", "invalid port 'ssh'"},
+		{"port:>x", "invalid port '>x'"},
+		{"port:100-10", "invalid port range '100-10'"},
+		{"status:2xx", "invalid status '2xx'"},
+		{"tls:maybe", "invalid tls value 'maybe'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) succeeded, want an error containing %q", tt.query, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseQuery(%q) error = %q, want it to contain %q", tt.query, err, tt.want)
+			}
+		})
+	}
+}
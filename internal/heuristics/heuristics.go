@@ -0,0 +1,376 @@
+// Package heuristics flags findings that deserve a closer look: services on ports they do not
+// usually use, tools that disagree about what answers on a port, and banners matching known
+// honeypots or other patterns from configs/heuristics.yaml. The results are hints for manual
+// review, never vulnerabilities
+package heuristics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// Anomaly kinds found by the built-in checks; rules name their own kinds
+const (
+	KindUnusualPort    = "unusual_port"
+	KindBannerConflict = "banner_conflict"
+	KindPortFlood      = "port_flood"
+)
+
+// Anomaly is one informational hint about a host or port
+type Anomaly struct {
+	Kind     string   `json:"kind"`
+	Rule     string   `json:"rule,omitempty"` // Rule from configs/heuristics.yaml that matched
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	Protocol string   `json:"protocol,omitempty"`
+	Message  string   `json:"message"`
+	Evidence string   `json:"evidence,omitempty"` // Text the hint is based on, e.g. the matching banner
+	Tools    []string `json:"tools,omitempty"`
+}
+
+// Location returns "host:port/protocol", or the host for host-level hints
+func (a Anomaly) Location() string {
+	if a.Port == 0 {
+		return a.Host
+	}
+	return fmt.Sprintf("%s:%d/%s", a.Host, a.Port, a.Protocol)
+}
+
+// Detector evaluates the configured checks and rules
+type Detector struct {
+	settings      config.HeuristicsConfig
+	expectedPorts map[string]map[int]bool // Normalized service -> ports it usually uses
+	rules         []rule
+}
+
+type rule struct {
+	config.HeuristicRule
+	pattern *regexp.Regexp
+	fields  map[string]bool
+}
+
+// Fields a rule pattern can be matched against
+var ruleFields = []string{"service", "product", "title", "technologies", "scripts", "url"}
+
+// New compiles the heuristics configuration; a disabled configuration yields a detector that
+// reports nothing
+func New(settings config.HeuristicsConfig) (*Detector, error) {
+	d := &Detector{settings: settings, expectedPorts: make(map[string]map[int]bool)}
+	for _, expected := range settings.ExpectedPorts {
+		service := normalizeService(expected.Service)
+		if service == "" || len(expected.Ports) == 0 {
+			return nil, fmt.Errorf("expected_ports entries need a service and ports")
+		}
+		if d.expectedPorts[service] == nil {
+			d.expectedPorts[service] = make(map[int]bool)
+		}
+		for _, port := range expected.Ports {
+			d.expectedPorts[service][port] = true
+		}
+	}
+	for _, configured := range settings.Rules {
+		if configured.Name == "" {
+			return nil, fmt.Errorf("heuristics rules need a name")
+		}
+		pattern, err := regexp.Compile(configured.Pattern)
+		if err != nil || configured.Pattern == "" {
+			return nil, fmt.Errorf("heuristics rule %s: invalid pattern %q", configured.Name, configured.Pattern)
+		}
+		r := rule{HeuristicRule: configured, pattern: pattern, fields: make(map[string]bool)}
+		if r.Kind == "" {
+			r.Kind = "banner"
+		}
+		fields := configured.Fields
+		if len(fields) == 0 {
+			fields = ruleFields
+		}
+		for _, field := range fields {
+			if !contains(ruleFields, field) {
+				return nil, fmt.Errorf("heuristics rule %s: unknown field %q (use %s)", configured.Name, field, strings.Join(ruleFields, ", "))
+			}
+			r.fields[field] = true
+		}
+		d.rules = append(d.rules, r)
+	}
+	return d, nil
+}
+
+// portFindings are the findings of one open port, from every tool that reported it
+type portFindings struct {
+	host     string
+	port     int
+	protocol string
+	list     []findings.Finding
+}
+
+// Evaluate returns the hints for a workspace's findings, ordered by host and port
+func (d *Detector) Evaluate(list []findings.Finding) []Anomaly {
+	if d == nil || !d.settings.Enabled {
+		return nil
+	}
+	ports := make(map[string]*portFindings)
+	var keys []string
+	openPorts := make(map[string]int)
+	for _, f := range list {
+		if f.Host == "" || f.Port == 0 || f.State != "open" {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d/%s", f.Host, f.Port, f.Protocol)
+		entry, exists := ports[key]
+		if !exists {
+			entry = &portFindings{host: f.Host, port: f.Port, protocol: f.Protocol}
+			ports[key] = entry
+			keys = append(keys, key)
+			openPorts[f.Host]++
+		}
+		entry.list = append(entry.list, f)
+	}
+
+	var anomalies []Anomaly
+	for _, key := range keys {
+		entry := ports[key]
+		anomalies = append(anomalies, d.unusualPort(entry)...)
+		if d.settings.BannerConflicts {
+			anomalies = append(anomalies, bannerConflicts(entry)...)
+		}
+		anomalies = append(anomalies, d.matchRules(entry)...)
+	}
+	if d.settings.MaxOpenPorts > 0 {
+		for host, count := range openPorts {
+			if count > d.settings.MaxOpenPorts {
+				anomalies = append(anomalies, Anomaly{
+					Kind:    KindPortFlood,
+					Host:    host,
+					Message: fmt.Sprintf("%d open ports (more than %d): the host may answer on every port, as tarpits and honeypots do", count, d.settings.MaxOpenPorts),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		a, b := anomalies[i], anomalies[j]
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Kind < b.Kind
+	})
+	return anomalies
+}
+
+// unusualPort flags services identified on a port outside the ports configured for them
+func (d *Detector) unusualPort(entry *portFindings) []Anomaly {
+	var anomalies []Anomaly
+	reported := make(map[string][]string) // Service -> tools
+	var services []string
+	for _, f := range entry.list {
+		service := normalizeService(f.Service)
+		if d.expectedPorts[service] == nil || d.expectedPorts[service][entry.port] {
+			continue
+		}
+		if reported[service] == nil {
+			services = append(services, service)
+		}
+		reported[service] = appendUnique(reported[service], f.Tool)
+	}
+	for _, service := range services {
+		anomalies = append(anomalies, Anomaly{
+			Kind:     KindUnusualPort,
+			Host:     entry.host,
+			Port:     entry.port,
+			Protocol: entry.protocol,
+			Message:  fmt.Sprintf("%s on %d/%s, usually on %s", service, entry.port, entry.protocol, formatPorts(d.expectedPorts[service])),
+			Tools:    reported[service],
+		})
+	}
+	return anomalies
+}
+
+// bannerConflicts flags ports where tools identified different services or server products
+func bannerConflicts(entry *portFindings) []Anomaly {
+	var anomalies []Anomaly
+	if claims, tools := distinctClaims(entry.list, func(f findings.Finding) string { return normalizeService(f.Service) }); len(claims) > 1 {
+		anomalies = append(anomalies, Anomaly{
+			Kind:     KindBannerConflict,
+			Host:     entry.host,
+			Port:     entry.port,
+			Protocol: entry.protocol,
+			Message:  "tools disagree about the service: " + strings.Join(claims, ", "),
+			Tools:    tools,
+		})
+	}
+	if claims, tools := distinctClaims(entry.list, func(f findings.Finding) string { return productFamily(f.Product) }); len(claims) > 1 {
+		anomalies = append(anomalies, Anomaly{
+			Kind:     KindBannerConflict,
+			Host:     entry.host,
+			Port:     entry.port,
+			Protocol: entry.protocol,
+			Message:  "tools disagree about the server: " + strings.Join(claims, ", "),
+			Tools:    tools,
+		})
+	}
+	return anomalies
+}
+
+// distinctClaims lists the different values several tools reported, as "value (tools)", and
+// the tools involved; tools reporting nothing are left out
+func distinctClaims(list []findings.Finding, value func(findings.Finding) string) ([]string, []string) {
+	byValue := make(map[string][]string)
+	var values, tools []string
+	for _, f := range list {
+		v := value(f)
+		if v == "" {
+			continue
+		}
+		if byValue[v] == nil {
+			values = append(values, v)
+		}
+		byValue[v] = appendUnique(byValue[v], f.Tool)
+		tools = appendUnique(tools, f.Tool)
+	}
+	if len(values) < 2 || len(tools) < 2 {
+		return nil, nil // One tool's modes disagreeing is not a conflict between tools
+	}
+	claims := make([]string, len(values))
+	for i, v := range values {
+		claims[i] = fmt.Sprintf("%s (%s)", v, strings.Join(byValue[v], ", "))
+	}
+	sort.Strings(tools)
+	return claims, tools
+}
+
+// matchRules applies the configured banner rules, one hint per rule and port
+func (d *Detector) matchRules(entry *portFindings) []Anomaly {
+	var anomalies []Anomaly
+	for _, r := range d.rules {
+		var tools []string
+		evidence := ""
+		for _, f := range entry.list {
+			if match := r.match(f); match != "" {
+				tools = appendUnique(tools, f.Tool)
+				if evidence == "" {
+					evidence = match
+				}
+			}
+		}
+		if len(tools) == 0 {
+			continue
+		}
+		message := r.Message
+		if message == "" {
+			message = "banner matches rule " + r.Name
+		}
+		anomalies = append(anomalies, Anomaly{
+			Kind:     r.Kind,
+			Rule:     r.Name,
+			Host:     entry.host,
+			Port:     entry.port,
+			Protocol: entry.protocol,
+			Message:  message,
+			Evidence: evidence,
+			Tools:    tools,
+		})
+	}
+	return anomalies
+}
+
+// match returns the line of the first of the rule's fields that matches, trimmed for display
+func (r rule) match(f findings.Finding) string {
+	candidates := map[string][]string{
+		"service":      {f.Service},
+		"product":      {strings.TrimSpace(f.Product + " " + f.Version)},
+		"title":        {f.Title},
+		"technologies": f.Technologies,
+		"url":          {f.URL},
+	}
+	for _, script := range f.Scripts {
+		candidates["scripts"] = append(candidates["scripts"], script.Output)
+	}
+	for _, field := range ruleFields {
+		if !r.fields[field] {
+			continue
+		}
+		for _, value := range candidates[field] {
+			if loc := r.pattern.FindStringIndex(value); loc != nil {
+				start := strings.LastIndex(value[:loc[0]], "\n") + 1
+				end := len(value)
+				if newline := strings.Index(value[loc[0]:], "\n"); newline >= 0 {
+					end = loc[0] + newline
+				}
+				return shorten(value[start:end], 120)
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeService folds the names tools give the same service: nmap's "ssl/" prefix and
+// uncertainty mark, and the http variants (https, http-proxy, http-alt)
+func normalizeService(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "ssl/"), "?")
+	switch {
+	case name == "unknown", name == "tcpwrapped":
+		return ""
+	case strings.HasPrefix(name, "http"):
+		return "http"
+	}
+	return name
+}
+
+// productFamily reduces a product banner to its first word, so "Apache httpd" and
+// "Apache/2.4.41 (Ubuntu)" or "Microsoft IIS httpd" and "Microsoft-IIS/10.0" agree
+func productFamily(product string) string {
+	fields := strings.FieldsFunc(strings.ToLower(product), func(r rune) bool {
+		return r == ' ' || r == '/' || r == '-' || r == '_' || r == '('
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func formatPorts(ports map[int]bool) string {
+	list := make([]int, 0, len(ports))
+	for port := range ports {
+		list = append(list, port)
+	}
+	sort.Ints(list)
+	text := make([]string, len(list))
+	for i, port := range list {
+		text[i] = strconv.Itoa(port)
+	}
+	return strings.Join(text, ", ")
+}
+
+func shorten(value string, maxLen int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if len(value) <= maxLen {
+		return value
+	}
+	return value[:maxLen] + "..."
+}
+
+func appendUnique(list []string, value string) []string {
+	if contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func contains(list []string, value string) bool {
+	for _, existing := range list {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
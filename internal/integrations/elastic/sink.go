@@ -0,0 +1,229 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// bulkBatchSize limits the number of documents sent per _bulk request
+const bulkBatchSize = 500
+
+// Options configure the connection and target indices
+type Options struct {
+	URL                string
+	FindingsIndex      string // May contain {date}, replaced with the run's start date (YYYY.MM.DD)
+	ExecutionsIndex    string
+	Username           string
+	Password           string
+	APIKey             string // Sent as "Authorization: ApiKey <key>"; takes precedence over basic auth
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// Sink indexes findings and tool executions into Elasticsearch or OpenSearch via the _bulk API
+type Sink struct {
+	options Options
+	client  *http.Client
+}
+
+// NewSink creates a sink for the given cluster
+func NewSink(options Options) (*Sink, error) {
+	if strings.TrimSpace(options.URL) == "" {
+		return nil, fmt.Errorf("no Elasticsearch URL configured")
+	}
+	if options.FindingsIndex == "" || options.ExecutionsIndex == "" {
+		return nil, fmt.Errorf("findings and executions indices must be set")
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 30 * time.Second
+	}
+	options.URL = strings.TrimSuffix(options.URL, "/")
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &Sink{
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout, Transport: transport},
+	}, nil
+}
+
+// FindingDocument is the indexed form of a finding
+type FindingDocument struct {
+	Timestamp time.Time `json:"@timestamp"`
+	ScanID    string    `json:"scan_id"`
+	Target    string    `json:"target"`
+	findings.Finding
+}
+
+// ExecutionDocument is the indexed form of a single tool execution
+type ExecutionDocument struct {
+	Timestamp       time.Time         `json:"@timestamp"`
+	ScanID          string            `json:"scan_id"`
+	Target          string            `json:"target"`
+	Workflow        string            `json:"workflow"`
+	Step            string            `json:"step"`
+	Tool            string            `json:"tool"`
+	Mode            string            `json:"mode"`
+	Matrix          map[string]string `json:"matrix,omitempty"`
+	Command         string            `json:"command"`
+	ExitCode        int               `json:"exit_code"`
+	Success         bool              `json:"success"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// Ship indexes a run's findings and executions and returns the number of documents indexed.
+// Document IDs are derived from the scan ID, so shipping the same run twice updates rather than duplicates
+func (s *Sink) Ship(ctx context.Context, report output.RunReport, list []findings.Finding) (int, error) {
+	timestamp := report.StartedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	date := timestamp.UTC().Format("2006.01.02")
+	findingsIndex := strings.ReplaceAll(s.options.FindingsIndex, "{date}", date)
+	executionsIndex := strings.ReplaceAll(s.options.ExecutionsIndex, "{date}", date)
+
+	var actions []bulkAction
+	for _, f := range list {
+		actions = append(actions, bulkAction{
+			index: findingsIndex,
+			id:    fmt.Sprintf("%s:%s:%d/%s:%s", report.ScanID, f.Host, f.Port, f.Protocol, f.Tool),
+			doc:   FindingDocument{Timestamp: timestamp, ScanID: report.ScanID, Target: report.Target, Finding: f},
+		})
+	}
+	for _, workflow := range report.Workflows {
+		for _, step := range workflow.Steps {
+			for i, execution := range step.Executions {
+				started := execution.StartTime
+				if started.IsZero() {
+					started = timestamp
+				}
+				actions = append(actions, bulkAction{
+					index: executionsIndex,
+					id:    fmt.Sprintf("%s:%s:%s:%s:%d", report.ScanID, workflow.Name, step.Name, execution.Mode, i),
+					doc: ExecutionDocument{
+						Timestamp:       started,
+						ScanID:          report.ScanID,
+						Target:          report.Target,
+						Workflow:        workflow.Name,
+						Step:            step.Name,
+						Tool:            execution.Tool,
+						Mode:            execution.Mode,
+						Matrix:          step.Matrix,
+						Command:         strings.Join(execution.Command, " "),
+						ExitCode:        execution.ExitCode,
+						Success:         execution.Success,
+						DurationSeconds: execution.DurationSeconds,
+						Error:           execution.Error,
+					},
+				})
+			}
+		}
+	}
+
+	indexed := 0
+	for start := 0; start < len(actions); start += bulkBatchSize {
+		end := start + bulkBatchSize
+		if end > len(actions) {
+			end = len(actions)
+		}
+		if err := s.bulk(ctx, actions[start:end]); err != nil {
+			return indexed, err
+		}
+		indexed += end - start
+	}
+	return indexed, nil
+}
+
+// bulkAction is one document to index
+type bulkAction struct {
+	index string
+	id    string
+	doc   interface{}
+}
+
+// bulk sends one _bulk request and reports item-level failures
+func (s *Sink) bulk(ctx context.Context, actions []bulkAction) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, action := range actions {
+		meta := map[string]map[string]string{"index": {"_index": action.index, "_id": action.id}}
+		if err := encoder.Encode(meta); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := encoder.Encode(action.doc); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.options.URL+"/_bulk", &body)
+	if err != nil {
+		return fmt.Errorf("failed to create bulk request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case s.options.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+s.options.APIKey)
+	case s.options.Username != "":
+		req.SetBasicAuth(s.options.Username, s.options.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bulk request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("bulk request returned %s: %s", resp.Status, truncate(string(respBody), 300))
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var firstError string
+	for _, item := range result.Items {
+		for _, status := range item {
+			if status.Status >= 300 {
+				failed++
+				if firstError == "" {
+					firstError = string(status.Error)
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%d of %d documents failed to index: %s", failed, len(actions), truncate(firstError, 300))
+}
+
+func truncate(s string, maxLen int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
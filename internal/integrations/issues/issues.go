@@ -0,0 +1,253 @@
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// Grouping modes for turning findings into issues
+const (
+	GroupByHost    = "host"
+	GroupByFinding = "finding"
+)
+
+// SeverityInfo is the level of findings no severity rule matches
+const SeverityInfo = "info"
+
+// severityOrder ranks levels from most to least severe
+var severityOrder = []string{"critical", "high", "medium", "low", SeverityInfo}
+
+// Issue is a tracker issue built from one host or one finding
+type Issue struct {
+	Key      string   `json:"key"` // Stable identity used to avoid opening duplicates
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Labels   []string `json:"labels"`
+	Severity string   `json:"severity"`
+}
+
+// SeverityRules map a level to service globs or port numbers that raise a finding to it
+type SeverityRules map[string][]string
+
+// Classify returns the most severe level whose rules match the finding
+func (r SeverityRules) Classify(f findings.Finding) string {
+	for _, level := range severityOrder {
+		for _, pattern := range r[level] {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if port, err := strconv.Atoi(pattern); err == nil {
+				if f.Port == port {
+					return level
+				}
+				continue
+			}
+			if f.Service == "" {
+				continue
+			}
+			if matched, _ := path.Match(pattern, strings.ToLower(f.Service)); matched {
+				return level
+			}
+		}
+	}
+	return SeverityInfo
+}
+
+// Count returns the number of open ports at each level, each port once however many tools
+// reported it
+func (r SeverityRules) Count(list []findings.Finding) map[string]int {
+	counts := make(map[string]int)
+	for _, f := range mergeFindings(list) {
+		counts[r.Classify(f)]++
+	}
+	return counts
+}
+
+// Levels returns the severity levels, most severe first
+func Levels() []string {
+	return append([]string(nil), severityOrder...)
+}
+
+// Build turns the open findings of a target into issues, one per host or one per finding
+func Build(target string, list []findings.Finding, groupBy string, rules SeverityRules, baseLabels []string) ([]Issue, error) {
+	merged := mergeFindings(list)
+
+	switch groupBy {
+	case "", GroupByHost:
+		return buildPerHost(target, merged, rules, baseLabels), nil
+	case GroupByFinding:
+		issues := make([]Issue, 0, len(merged))
+		for _, f := range merged {
+			severity := rules.Classify(f)
+			issues = append(issues, Issue{
+				Key:      fmt.Sprintf("%s:%d/%s", f.Host, f.Port, f.Protocol),
+				Title:    fmt.Sprintf("[%s] %s: %d/%s %s", strings.ToUpper(severity), f.Host, f.Port, f.Protocol, dashIfEmpty(f.Service)),
+				Body:     findingsBody(target, f.Host, []findings.Finding{f}, rules),
+				Labels:   issueLabels(baseLabels, severity, f.Labels),
+				Severity: severity,
+			})
+		}
+		return issues, nil
+	default:
+		return nil, fmt.Errorf("invalid group_by '%s': must be %s or %s", groupBy, GroupByHost, GroupByFinding)
+	}
+}
+
+// buildPerHost opens one issue per host carrying its most severe level
+func buildPerHost(target string, merged []findings.Finding, rules SeverityRules, baseLabels []string) []Issue {
+	var hosts []string
+	byHost := make(map[string][]findings.Finding)
+	for _, f := range merged {
+		if _, exists := byHost[f.Host]; !exists {
+			hosts = append(hosts, f.Host)
+		}
+		byHost[f.Host] = append(byHost[f.Host], f)
+	}
+
+	issues := make([]Issue, 0, len(hosts))
+	for _, host := range hosts {
+		hostFindings := byHost[host]
+		severity := SeverityInfo
+		var tags []string
+		for _, f := range hostFindings {
+			if level := rules.Classify(f); severityRank(level) < severityRank(severity) {
+				severity = level
+			}
+			tags = append(tags, f.Labels...)
+		}
+		issues = append(issues, Issue{
+			Key:      host,
+			Title:    fmt.Sprintf("[%s] %s: %d open port(s)", strings.ToUpper(severity), host, len(hostFindings)),
+			Body:     findingsBody(target, host, hostFindings, rules),
+			Labels:   issueLabels(baseLabels, severity, tags),
+			Severity: severity,
+		})
+	}
+	return issues
+}
+
+// mergeFindings keeps open ports only and merges duplicates reported by several tools
+func mergeFindings(list []findings.Finding) []findings.Finding {
+	var merged []findings.Finding
+	index := make(map[string]int)
+	for _, f := range list {
+		if f.Port == 0 || (f.State != "" && f.State != "open") {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d/%s", f.Host, f.Port, f.Protocol)
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			merged = append(merged, f)
+			continue
+		}
+		existing := &merged[i]
+		if existing.Service == "" {
+			existing.Service = f.Service
+		}
+		if existing.Product == "" {
+			existing.Product = f.Product
+			existing.Version = f.Version
+		}
+		existing.TLS = existing.TLS || f.TLS
+		if !strings.Contains(existing.Tool, f.Tool) {
+			existing.Tool += ", " + f.Tool
+		}
+		existing.Labels = append(existing.Labels, f.Labels...)
+	}
+	findings.Sort(merged)
+	return merged
+}
+
+// findingsBody renders the Markdown issue body shared by both trackers
+func findingsBody(target, host string, list []findings.Finding, rules SeverityRules) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Open ports found on `%s` while scanning `%s`.\n\n", host, target)
+	b.WriteString("| Port | Service | Product | Severity | Tools |\n|------|---------|---------|----------|-------|\n")
+	for _, f := range list {
+		product := strings.TrimSpace(f.Product + " " + f.Version)
+		fmt.Fprintf(&b, "| %d/%s | %s | %s | %s | %s |\n",
+			f.Port, f.Protocol, dashIfEmpty(f.Service), dashIfEmpty(product), rules.Classify(f), f.Tool)
+	}
+	b.WriteString("\n_Opened by ipcrawler._\n")
+	return b.String()
+}
+
+// issueLabels combines the configured labels, the severity label, and the host's tags
+func issueLabels(baseLabels []string, severity string, tags []string) []string {
+	labels := append([]string{}, baseLabels...)
+	labels = append(labels, "severity:"+severity)
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(labels)+len(tags))
+	for _, label := range append(labels, tags...) {
+		if label != "" && !seen[label] {
+			seen[label] = true
+			result = append(result, label)
+		}
+	}
+	return result
+}
+
+// severityRank orders levels; unknown levels rank after info
+func severityRank(level string) int {
+	for i, known := range severityOrder {
+		if level == known {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// LedgerFileName records the issues already opened for a workspace
+const LedgerFileName = "issues.json"
+
+// Ledger maps issue keys to the URLs of issues opened for them
+type Ledger struct {
+	Provider   string            `json:"provider"`
+	Repository string            `json:"repository"`
+	Issues     map[string]string `json:"issues"`
+}
+
+// LoadLedger reads the issue ledger from a workspace's reports directory
+// A missing ledger is not an error; an empty one is returned
+func LoadLedger(workspaceDir string) (*Ledger, error) {
+	ledger := &Ledger{Issues: make(map[string]string)}
+	data, err := os.ReadFile(filepath.Join(workspaceDir, "reports", LedgerFileName))
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issue ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse issue ledger: %w", err)
+	}
+	if ledger.Issues == nil {
+		ledger.Issues = make(map[string]string)
+	}
+	return ledger, nil
+}
+
+// Save writes the ledger to the workspace reports directory
+func (l *Ledger) Save(workspaceDir string, perm os.FileMode) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal issue ledger: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspaceDir, "reports", LedgerFileName), data, perm); err != nil {
+		return fmt.Errorf("failed to write issue ledger: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Supported issue tracker providers
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Tracker opens issues in a repository or project
+type Tracker interface {
+	Name() string
+	Create(ctx context.Context, issue Issue) (string, error) // Returns the URL of the new issue
+}
+
+// NewTracker creates the tracker for a provider; baseURL may be empty for the public service
+func NewTracker(provider, repository, baseURL, token string) (Tracker, error) {
+	if strings.TrimSpace(repository) == "" {
+		return nil, fmt.Errorf("no repository configured")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no API token available")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch strings.ToLower(provider) {
+	case ProviderGitHub:
+		if baseURL == "" {
+			baseURL = "https://api.github.com"
+		}
+		return &githubTracker{baseURL: strings.TrimSuffix(baseURL, "/"), repository: repository, token: token, client: client}, nil
+	case ProviderGitLab:
+		if baseURL == "" {
+			baseURL = "https://gitlab.com"
+		}
+		return &gitlabTracker{baseURL: strings.TrimSuffix(baseURL, "/"), project: repository, token: token, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown issue provider '%s': must be %s or %s", provider, ProviderGitHub, ProviderGitLab)
+	}
+}
+
+// DefaultTokenEnv returns the conventional token variable for a provider
+func DefaultTokenEnv(provider string) string {
+	if strings.ToLower(provider) == ProviderGitLab {
+		return "GITLAB_TOKEN"
+	}
+	return "GITHUB_TOKEN"
+}
+
+// githubTracker opens issues through the GitHub REST API
+type githubTracker struct {
+	baseURL    string
+	repository string // owner/repo
+	token      string
+	client     *http.Client
+}
+
+func (t *githubTracker) Name() string {
+	return ProviderGitHub
+}
+
+func (t *githubTracker) Create(ctx context.Context, issue Issue) (string, error) {
+	payload := map[string]interface{}{
+		"title":  issue.Title,
+		"body":   issue.Body,
+		"labels": issue.Labels,
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer " + t.token,
+		"Accept":        "application/vnd.github+json",
+	}
+
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/issues", t.baseURL, t.repository)
+	if err := postJSON(ctx, t.client, endpoint, headers, payload, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+// gitlabTracker opens issues through the GitLab REST API (v4)
+type gitlabTracker struct {
+	baseURL string
+	project string // group/project path or numeric ID
+	token   string
+	client  *http.Client
+}
+
+func (t *gitlabTracker) Name() string {
+	return ProviderGitLab
+}
+
+func (t *gitlabTracker) Create(ctx context.Context, issue Issue) (string, error) {
+	payload := map[string]interface{}{
+		"title":       issue.Title,
+		"description": issue.Body,
+		"labels":      strings.Join(issue.Labels, ","),
+	}
+	headers := map[string]string{
+		"PRIVATE-TOKEN": t.token,
+	}
+
+	var created struct {
+		WebURL string `json:"web_url"`
+	}
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/issues", t.baseURL, url.PathEscape(t.project))
+	if err := postJSON(ctx, t.client, endpoint, headers, payload, &created); err != nil {
+		return "", err
+	}
+	return created.WebURL, nil
+}
+
+// postJSON sends a JSON payload and decodes the JSON response into result
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, payload, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s: %s", endpoint, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// Events a webhook can be sent
+const (
+	EventWorkflowCompleted = "workflow_completed"
+	EventWorkflowFailed    = "workflow_failed"
+	EventNewFindings       = "new_findings"
+)
+
+// Events lists every event type
+var Events = []string{EventWorkflowCompleted, EventWorkflowFailed, EventNewFindings}
+
+// Payload formats
+const (
+	FormatJSON    = "json"    // The Event itself
+	FormatSlack   = "slack"   // {"text": ...} for Slack incoming webhooks
+	FormatDiscord = "discord" // {"content": ...} for Discord webhooks
+)
+
+// Formats lists every payload format
+var Formats = []string{FormatJSON, FormatSlack, FormatDiscord}
+
+const (
+	queueSize           = 256  // Events waiting per webhook before new ones are dropped
+	maxListedFindings   = 10   // Findings written out in a Slack or Discord message
+	discordContentLimit = 2000 // Longest message Discord accepts
+)
+
+// Event is something that happened during a scan
+type Event struct {
+	Type     string             `json:"event"`
+	Time     time.Time          `json:"time"`
+	ScanID   string             `json:"scan_id,omitempty"`
+	Target   string             `json:"target"`
+	Workflow string             `json:"workflow,omitempty"`
+	Message  string             `json:"message,omitempty"`
+	Findings []findings.Finding `json:"findings,omitempty"`
+}
+
+// Webhook is one endpoint and the events it is sent
+type Webhook struct {
+	Name    string
+	URL     string
+	Format  string        // Default FormatJSON
+	Events  []string      // Default: all events
+	Timeout time.Duration // Per request; default 10s
+}
+
+// Notifier posts events to webhooks in the background. Each webhook receives its events in
+// order, and a slow or unreachable webhook delays neither the scan nor the other webhooks
+type Notifier struct {
+	webhooks []*webhookWorker
+	client   *http.Client
+	wg       sync.WaitGroup
+	mutex    sync.Mutex
+	errors   []error
+	closed   bool
+}
+
+type webhookWorker struct {
+	Webhook
+	queue chan Event
+}
+
+// New validates the webhooks and starts delivering to them
+func New(webhooks []Webhook) (*Notifier, error) {
+	n := &Notifier{client: &http.Client{}}
+	for i, webhook := range webhooks {
+		if webhook.Name == "" {
+			webhook.Name = "webhook " + strconv.Itoa(i+1)
+		}
+		if strings.TrimSpace(webhook.URL) == "" {
+			return nil, fmt.Errorf("%s: no URL configured", webhook.Name)
+		}
+		if parsed, err := url.Parse(webhook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("%s: URL must be an http or https URL", webhook.Name)
+		}
+		if webhook.Format == "" {
+			webhook.Format = FormatJSON
+		}
+		webhook.Format = strings.ToLower(webhook.Format)
+		if !slices.Contains(Formats, webhook.Format) {
+			return nil, fmt.Errorf("%s: unknown format '%s' (available: %s)", webhook.Name, webhook.Format, strings.Join(Formats, ", "))
+		}
+		if len(webhook.Events) == 0 {
+			webhook.Events = Events
+		}
+		for _, event := range webhook.Events {
+			if !slices.Contains(Events, event) {
+				return nil, fmt.Errorf("%s: unknown event '%s' (available: %s)", webhook.Name, event, strings.Join(Events, ", "))
+			}
+		}
+		if webhook.Timeout <= 0 {
+			webhook.Timeout = 10 * time.Second
+		}
+		n.webhooks = append(n.webhooks, &webhookWorker{Webhook: webhook, queue: make(chan Event, queueSize)})
+	}
+
+	for _, worker := range n.webhooks {
+		n.wg.Add(1)
+		go n.deliver(worker)
+	}
+	return n, nil
+}
+
+// Notify queues an event for every webhook that is sent its type
+func (n *Notifier) Notify(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.closed {
+		return
+	}
+	for _, worker := range n.webhooks {
+		if !slices.Contains(worker.Events, event.Type) {
+			continue
+		}
+		select {
+		case worker.queue <- event:
+		default:
+			n.errors = append(n.errors, fmt.Errorf("%s: queue full, %s event dropped", worker.Name, event.Type))
+		}
+	}
+}
+
+// Close waits until the queued events are delivered and returns the delivery errors
+func (n *Notifier) Close() error {
+	n.mutex.Lock()
+	if !n.closed {
+		n.closed = true
+		for _, worker := range n.webhooks {
+			close(worker.queue)
+		}
+	}
+	n.mutex.Unlock()
+
+	n.wg.Wait()
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return errors.Join(n.errors...)
+}
+
+func (n *Notifier) deliver(worker *webhookWorker) {
+	defer n.wg.Done()
+	for event := range worker.queue {
+		if err := n.post(worker.Webhook, event); err != nil {
+			n.mutex.Lock()
+			n.errors = append(n.errors, fmt.Errorf("%s: %s event: %w", worker.Name, event.Type, err))
+			n.mutex.Unlock()
+		}
+	}
+}
+
+// post sends one event in the webhook's format
+func (n *Notifier) post(webhook Webhook, event Event) error {
+	body, err := Payload(webhook.Format, event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhook.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ipcrawler")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// Payload encodes an event in a webhook format
+func Payload(format string, event Event) ([]byte, error) {
+	switch format {
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": Text(event)})
+	case FormatDiscord:
+		content := Text(event)
+		if len(content) > discordContentLimit {
+			content = strings.ToValidUTF8(content[:discordContentLimit-3], "") + "..."
+		}
+		return json.Marshal(map[string]string{"content": content})
+	default:
+		return json.Marshal(event)
+	}
+}
+
+// Text describes an event in a short chat message
+func Text(event Event) string {
+	switch event.Type {
+	case EventWorkflowCompleted:
+		return fmt.Sprintf("ipcrawler: %s completed on %s", event.Workflow, event.Target)
+	case EventWorkflowFailed:
+		text := fmt.Sprintf("ipcrawler: %s failed on %s", event.Workflow, event.Target)
+		if event.Message != "" {
+			text += ": " + event.Message
+		}
+		return text
+	case EventNewFindings:
+		var text strings.Builder
+		noun := "findings"
+		if len(event.Findings) == 1 {
+			noun = "finding"
+		}
+		fmt.Fprintf(&text, "ipcrawler: %d new %s on %s", len(event.Findings), noun, event.Target)
+		if event.Workflow != "" {
+			fmt.Fprintf(&text, " (%s)", event.Workflow)
+		}
+		for i, f := range event.Findings {
+			if i == maxListedFindings {
+				fmt.Fprintf(&text, "\n... and %d more", len(event.Findings)-maxListedFindings)
+				break
+			}
+			text.WriteString("\n- " + describeFinding(f))
+		}
+		return text.String()
+	default:
+		return fmt.Sprintf("ipcrawler: %s on %s", event.Type, event.Target)
+	}
+}
+
+// describeFinding is one line per finding: the URL with its status and title, or the port
+// with its service
+func describeFinding(f findings.Finding) string {
+	if f.URL != "" {
+		line := f.URL
+		if f.StatusCode > 0 {
+			line += " [" + strconv.Itoa(f.StatusCode) + "]"
+		}
+		if f.Title != "" {
+			line += " " + strconv.Quote(f.Title)
+		}
+		return line
+	}
+	protocol := f.Protocol
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	line := fmt.Sprintf("%s %d/%s", f.Host, f.Port, protocol)
+	if service := strings.TrimSpace(strings.Join([]string{f.Service, f.Product, f.Version}, " ")); service != "" {
+		line += " " + strings.Join(strings.Fields(service), " ")
+	}
+	return line
+}
@@ -17,23 +17,27 @@ const (
 	OutputModeNormal  OutputMode = iota // Only raw tool output
 	OutputModeVerbose                   // Both logs and raw output
 	OutputModeDebug                     // Only logs, no raw tool output
+	OutputModePlain                     // Only single-line key=value status records (--plain)
 )
 
-// ANSI color codes for terminal output
-const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
-	colorBlue   = "\033[34m"
-	colorCyan   = "\033[36m"
-	colorGray   = "\033[90m"
-	colorBold   = "\033[1m"
-)
+// String returns the name of the output mode
+func (m OutputMode) String() string {
+	switch m {
+	case OutputModeVerbose:
+		return "verbose"
+	case OutputModeDebug:
+		return "debug"
+	case OutputModePlain:
+		return "plain"
+	default:
+		return "normal"
+	}
+}
 
 // OutputController manages console output based on the selected mode
 type OutputController struct {
 	mode        OutputMode
+	redactor    *Redactor  // Scrubs secrets and redaction patterns from everything printed (nil = none)
 	outputMutex sync.Mutex // Global mutex for synchronized output
 }
 
@@ -44,11 +48,16 @@ func NewOutputController(mode OutputMode) *OutputController {
 	}
 }
 
+// SetRedactor makes the controller redact everything it prints. Call it before output starts
+func (oc *OutputController) SetRedactor(redactor *Redactor) {
+	oc.redactor = redactor
+}
+
 // PrintRaw outputs raw tool output to console based on the current mode
 func (oc *OutputController) PrintRaw(content string) {
 	switch oc.mode {
 	case OutputModeNormal, OutputModeVerbose:
-		fmt.Print(content)
+		fmt.Print(oc.redactor.Redact(content))
 	case OutputModeDebug:
 		// In debug mode, don't show raw tool output
 	}
@@ -58,7 +67,7 @@ func (oc *OutputController) PrintRaw(content string) {
 func (oc *OutputController) PrintRawLine(line string) {
 	switch oc.mode {
 	case OutputModeNormal, OutputModeVerbose:
-		fmt.Println(line)
+		fmt.Println(oc.redactor.Redact(line))
 	case OutputModeDebug:
 		// In debug mode, don't show raw tool output
 	}
@@ -69,7 +78,7 @@ func (oc *OutputController) PrintToolSeparator(toolName, mode string) {
 	switch oc.mode {
 	case OutputModeNormal, OutputModeVerbose:
 		fmt.Printf("\n%s════════════════════════════════════════════════════════════════════════════════%s\n", colorCyan, colorReset)
-		fmt.Printf("%s▶ %s [%s]%s\n", colorBold+colorGreen, toolName, mode, colorReset)
+		fmt.Printf("%s%s %s [%s]%s\n", colorBold+colorGreen, StatusSymbol(StatusRunning), toolName, mode, colorReset)
 		fmt.Printf("%s════════════════════════════════════════════════════════════════════════════════%s\n\n", colorCyan, colorReset)
 	case OutputModeDebug:
 		// In debug mode, don't show separators
@@ -91,7 +100,7 @@ func (oc *OutputController) PrintRawSection(toolName, mode, output string) {
 	switch oc.mode {
 	case OutputModeNormal, OutputModeVerbose:
 		fmt.Printf("\n=== RAW OUTPUT: %s %s ===\n", toolName, mode)
-		fmt.Print(output)
+		fmt.Print(oc.redactor.Redact(output))
 		fmt.Printf("=== END OUTPUT ===\n\n")
 	case OutputModeDebug:
 		// In debug mode, don't show raw tool output
@@ -107,15 +116,15 @@ func (oc *OutputController) PrintLog(level, msg string, args ...interface{}) {
 	case OutputModeVerbose, OutputModeDebug:
 		// Show logs in verbose and debug modes
 		if len(args) > 0 {
-			fmt.Printf("[%s] "+msg+"\n", append([]interface{}{level}, args...)...)
-		} else {
-			fmt.Printf("[%s] %s\n", level, msg)
+			msg = fmt.Sprintf(msg, args...)
 		}
+		fmt.Printf("[%s] %s\n", level, oc.redactor.Redact(msg))
 	}
 }
 
 // PrintError outputs error messages (shown differently based on mode)
 func (oc *OutputController) PrintError(line string) {
+	line = oc.redactor.Redact(line)
 	switch oc.mode {
 	case OutputModeNormal:
 		// In normal mode, show stderr as plain text (it's tool output)
@@ -134,30 +143,25 @@ func (oc *OutputController) PrintWarning(msg string, args ...interface{}) {
 	case OutputModeVerbose, OutputModeDebug:
 		// Show warnings in verbose and debug modes
 		if len(args) > 0 {
-			fmt.Printf("Warning: "+msg+"\n", args...)
-		} else {
-			fmt.Printf("Warning: %s\n", msg)
+			msg = fmt.Sprintf(msg, args...)
 		}
+		fmt.Printf("Warning: %s\n", oc.redactor.Redact(msg))
 	}
 }
 
 // PrintInfo outputs info messages based on the current mode
 func (oc *OutputController) PrintInfo(msg string, args ...interface{}) {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	msg = oc.redactor.Redact(msg)
 	switch oc.mode {
 	case OutputModeNormal:
 		// In normal mode, show info messages about tool output status (but no IPCrawler logs)
-		if len(args) > 0 {
-			fmt.Printf(msg+"\n", args...)
-		} else {
-			fmt.Printf("%s\n", msg)
-		}
+		fmt.Printf("%s\n", msg)
 	case OutputModeVerbose, OutputModeDebug:
 		// Show info in verbose and debug modes with [INFO] prefix
-		if len(args) > 0 {
-			fmt.Printf("[INFO] "+msg+"\n", args...)
-		} else {
-			fmt.Printf("[INFO] %s\n", msg)
-		}
+		fmt.Printf("[INFO] %s\n", msg)
 	}
 }
 
@@ -166,6 +170,12 @@ func (oc *OutputController) ShouldShowRaw() bool {
 	return oc.mode == OutputModeVerbose
 }
 
+// ShouldShowProgress reports whether running tools are shown with spinners; plain output
+// reports them as records instead
+func (oc *OutputController) ShouldShowProgress() bool {
+	return oc.mode != OutputModePlain
+}
+
 // ShouldShowLogs returns true if log messages should be displayed
 func (oc *OutputController) ShouldShowLogs() bool {
 	return oc.mode == OutputModeVerbose || oc.mode == OutputModeDebug
@@ -173,7 +183,10 @@ func (oc *OutputController) ShouldShowLogs() bool {
 
 // PrintWorkflowTree displays a tree view of discovered workflow files
 func (oc *OutputController) PrintWorkflowTree(workflowsPath string, workflows map[string]interface{}) {
-	// Always show workflow tree regardless of mode
+	// Shown in every mode but plain, which prints no banners
+	if oc.mode == OutputModePlain {
+		return
+	}
 	fmt.Printf("\n%s+==============================================================================+%s\n", colorCyan, colorReset)
 	fmt.Printf("%s|                              WORKFLOW TREE                                 |%s\n", colorCyan, colorReset)
 	fmt.Printf("%s+==============================================================================+%s\n", colorCyan, colorReset)
@@ -330,6 +343,9 @@ func (oc *OutputController) PrintCompleteToolOutput(toolName, mode, stdout, stde
 	// Print tool separator
 	oc.printToolSeparatorUnsafe(toolName, mode)
 
+	stdout = oc.redactor.Redact(stdout)
+	stderr = oc.redactor.Redact(stderr)
+
 	// Print stdout if available
 	if stdout != "" {
 		for _, line := range strings.Split(strings.TrimRight(stdout, "\n"), "\n") {
@@ -357,12 +373,30 @@ func (oc *OutputController) PrintCompleteToolOutput(toolName, mode, stdout, stde
 	oc.printToolEndUnsafe()
 }
 
+// PrintToolLine displays one line of a running tool's output as it is produced, tagged with
+// the tool so lines from concurrent executions stay attributable
+func (oc *OutputController) PrintToolLine(toolName, mode, line string, isStderr bool) {
+	oc.outputMutex.Lock()
+	defer oc.outputMutex.Unlock()
+
+	tag := fmt.Sprintf("%s[%s %s]%s ", colorGray, toolName, mode, colorReset)
+	line = oc.redactor.Redact(line)
+	switch {
+	case oc.mode == OutputModeDebug:
+		// In debug mode, don't show raw tool output
+	case isStderr:
+		fmt.Fprintf(os.Stderr, "%s%s%s%s\n", tag, colorYellow, line, colorReset)
+	default:
+		fmt.Printf("%s%s\n", tag, line)
+	}
+}
+
 // Thread-unsafe helper methods (must be called with mutex held)
 func (oc *OutputController) printToolSeparatorUnsafe(toolName, mode string) {
 	switch oc.mode {
 	case OutputModeNormal, OutputModeVerbose:
 		fmt.Printf("\n%s════════════════════════════════════════════════════════════════════════════════%s\n", colorCyan, colorReset)
-		fmt.Printf("%s▶ %s [%s]%s\n", colorBold+colorGreen, toolName, mode, colorReset)
+		fmt.Printf("%s%s %s [%s]%s\n", colorBold+colorGreen, StatusSymbol(StatusRunning), toolName, mode, colorReset)
 		fmt.Printf("%s════════════════════════════════════════════════════════════════════════════════%s\n\n", colorCyan, colorReset)
 	case OutputModeDebug:
 		// In debug mode, don't show separators
@@ -0,0 +1,313 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// HTMLReportFileName is the standalone HTML run report written next to report.json
+const HTMLReportFileName = "report.html"
+
+// htmlReportView is the data passed to the HTML report template
+type htmlReportView struct {
+	Report       RunReport
+	Generated    string
+	StartedLocal time.Time // Start in the engagement timezone, zero without one
+	Hosts        int
+	DNSRecords   []htmlDNSRecord
+	Executions   []htmlExecution
+	RawFiles     []htmlRawFile
+	Trend        *htmlTrend // Nil without earlier runs of the target
+}
+
+// htmlDNSRecord is one DNS variable and its values
+type htmlDNSRecord struct {
+	Type   string
+	Values []string
+}
+
+// htmlExecution is a tool invocation flattened out of its workflow and step
+type htmlExecution struct {
+	Workflow string
+	Step     string
+	ToolExecution
+	Link string
+}
+
+// htmlRawFile links a raw or parsed tool output in the workspace
+type htmlRawFile struct {
+	Name string
+	Link string
+	Size string
+}
+
+// RenderHTMLReport renders a self-contained HTML report (embedded CSS and SVG charts, no
+// external assets). Raw output links are relative to the workspace reports directory. A trend
+// of at least two runs is charted
+func RenderHTMLReport(w io.Writer, report RunReport, trend *Trend) error {
+	view := htmlReportView{
+		Report:       report,
+		Generated:    time.Now().Format(time.RFC1123),
+		StartedLocal: inTimezone(report.StartedAt, report.Timezone),
+		DNSRecords:   dnsRecords(report.Variables),
+		RawFiles:     rawFiles(report.Workspace),
+		Trend:        renderTrend(trend),
+	}
+
+	hosts := make(map[string]bool)
+	for _, port := range report.Ports {
+		hosts[port.Host] = true
+	}
+	view.Hosts = len(hosts)
+
+	reportsDir := workspace.New(report.Workspace).Paths().Reports
+	for _, workflow := range report.Workflows {
+		for _, step := range workflow.Steps {
+			for _, execution := range step.Executions {
+				view.Executions = append(view.Executions, htmlExecution{
+					Workflow:      workflow.Name,
+					Step:          step.Name,
+					ToolExecution: execution,
+					Link:          relativeLink(reportsDir, execution.OutputPath),
+				})
+			}
+		}
+	}
+
+	return htmlReportTemplate.Execute(w, view)
+}
+
+// WriteHTML writes report.html, prefixed with the session name when the run has one, to the
+// workspace reports directory and returns its path
+func (rg *ReportGenerator) WriteHTML(perm os.FileMode) (string, error) {
+	report := rg.redactedReport()
+	rg.mutex.Lock()
+	trend := rg.trend
+	rg.mutex.Unlock()
+
+	var buf bytes.Buffer
+	if err := RenderHTMLReport(&buf, report, trend); err != nil {
+		return "", fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	reportPath := filepath.Join(workspace.New(report.Workspace).Paths().Reports, session.SessionFileName(report.Session, HTMLReportFileName))
+	if err := os.WriteFile(reportPath, buf.Bytes(), perm); err != nil {
+		return "", fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return reportPath, nil
+}
+
+// dnsRecords collects the dns_* magic variables, one record type per variable
+func dnsRecords(variables map[string]string) []htmlDNSRecord {
+	var records []htmlDNSRecord
+	for name, value := range variables {
+		if !strings.HasPrefix(name, "dns_") || strings.TrimSpace(value) == "" {
+			continue
+		}
+		record := htmlDNSRecord{Type: strings.ToUpper(strings.TrimSuffix(strings.TrimPrefix(name, "dns_"), "_records"))}
+		for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+			if v = strings.TrimSpace(v); v != "" {
+				record.Values = append(record.Values, v)
+			}
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Type < records[j].Type })
+	return records
+}
+
+// rawFiles lists tool outputs in the workspace raw and scans directories
+func rawFiles(workspaceDir string) []htmlRawFile {
+	var files []htmlRawFile
+	for _, dir := range []string{workspace.RawDir, workspace.ScansDir} {
+		entries, err := os.ReadDir(filepath.Join(workspaceDir, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() {
+				continue
+			}
+			files = append(files, htmlRawFile{
+				Name: dir + "/" + entry.Name(),
+				Link: "../" + dir + "/" + entry.Name(),
+				Size: formatSize(info.Size()),
+			})
+		}
+	}
+	return files
+}
+
+// relativeLink returns a link to path relative to base, or "" if it is outside the workspace
+func relativeLink(base, path string) string {
+	if path == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(rel, filepath.Join("..", "..")) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// formatSize renders a byte count for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"seconds": func(s float64) string { return fmt.Sprintf("%.2fs", s) },
+	"command": func(args []string) string { return strings.Join(args, " ") },
+	"join":    func(items []string) string { return strings.Join(items, ", ") },
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05 MST")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>IPCrawler report - {{.Report.Target}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #1f2328; background: #f6f8fa; }
+header { background: #24292f; color: #fff; padding: 1.5rem 2rem; }
+header h1 { margin: 0 0 .25rem; font-size: 1.5rem; }
+header p { margin: 0; color: #c9d1d9; font-size: .9rem; }
+main { padding: 1.5rem 2rem; max-width: 1200px; }
+section { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; padding: 1rem 1.25rem; margin-bottom: 1.25rem; }
+h2 { font-size: 1.15rem; margin: 0 0 .75rem; }
+table { border-collapse: collapse; width: 100%; font-size: .875rem; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+th { background: #f6f8fa; font-weight: 600; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .8rem; word-break: break-all; }
+.cards { display: flex; flex-wrap: wrap; gap: .75rem; }
+.card { flex: 1 1 140px; border: 1px solid #d0d7de; border-radius: 6px; padding: .75rem; }
+.card strong { display: block; font-size: 1.4rem; }
+.ok { color: #1a7f37; } .fail { color: #cf222e; } .warn { color: #9a6700; }
+.muted { color: #656d76; }
+h3 { font-size: .95rem; margin: 1rem 0 .25rem; }
+.chart { width: 100%; max-width: 720px; height: auto; font-size: 11px; fill: #656d76; }
+.swatch { display: inline-block; width: .8rem; height: .8rem; border-radius: 2px; margin: 0 .3rem 0 .75rem; vertical-align: middle; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Report.Target}}</h1>
+<p>{{with .Report.Session}}Session {{.}} &middot; {{end}}Scan {{.Report.ScanID}} &middot; {{time .Report.StartedAt}}{{if not .StartedLocal.IsZero}} &middot; target local {{time .StartedLocal}} ({{.Report.Timezone}}){{end}} &middot; generated {{.Generated}}</p>
+</header>
+<main>
+<section>
+<h2>Summary</h2>
+<div class="cards">
+<div class="card"><strong class="{{if eq .Report.Status "completed"}}ok{{else}}fail{{end}}">{{.Report.Status}}</strong>status</div>
+<div class="card"><strong>{{.Hosts}}</strong>hosts with open ports</div>
+<div class="card"><strong>{{len .Report.Ports}}</strong>open ports</div>
+<div class="card"><strong>{{len .Report.Services}}</strong>services</div>
+<div class="card"><strong>{{seconds .Report.DurationSeconds}}</strong>duration</div>
+<div class="card"><strong class="{{if .Report.Warnings}}warn{{else}}ok{{end}}">{{len .Report.Warnings}}</strong>warnings</div>
+</div>
+{{if .Report.Error}}<p class="fail">{{.Report.Error}}</p>{{end}}
+</section>
+
+{{with .Trend}}
+<section>
+<h2>Trend</h2>
+<p class="muted">The last {{.Runs}} runs of this target, since {{time .First}}, from the run database.</p>
+<h3>Open ports</h3>
+{{.OpenPorts}}
+{{if .Severities}}<h3>Findings by severity</h3>
+{{.Severities}}
+<p class="muted">{{range .Legend}}<span class="swatch" style="background: {{.Color}}"></span>{{.Name}}{{end}}</p>
+{{if .Skipped}}<p class="muted">{{.Skipped}} older run(s) recorded no severities and are left out.</p>{{end}}
+{{else}}<p class="muted">Findings by severity are charted once two runs have recorded them.</p>{{end}}
+</section>
+{{end}}
+
+{{if .Report.Warnings}}
+<section>
+<h2>Scan quality</h2>
+<p class="muted">The scan ran, but these conditions make its results less complete than configured.</p>
+<table>
+<tr><th>Kind</th><th>Tool</th><th>Mode</th><th>Target</th><th>Warning</th></tr>
+{{range .Report.Warnings}}<tr><td class="warn">{{.Kind}}</td><td>{{.Tool}}</td><td>{{.Mode}}</td><td>{{.Target}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+</section>
+{{end}}
+
+<section>
+<h2>Open ports</h2>
+{{if .Report.Ports}}
+<table>
+<tr><th>Host</th><th>Port</th><th>Service</th><th>Product</th><th>Version</th><th>Tools</th></tr>
+{{range .Report.Ports}}<tr><td>{{.Host}}</td><td>{{.Port}}/{{.Protocol}}</td><td>{{.Service}}</td><td>{{.Product}}</td><td>{{.Version}}</td><td class="muted">{{join .Tools}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No open ports found.</p>{{end}}
+</section>
+
+<section>
+<h2>Service fingerprints</h2>
+{{if .Report.Services}}<p>{{join .Report.Services}}</p>{{else}}<p class="muted">No services identified.</p>{{end}}
+</section>
+
+<section>
+<h2>DNS records</h2>
+{{if .DNSRecords}}
+<table>
+<tr><th>Type</th><th>Values</th></tr>
+{{range .DNSRecords}}<tr><td>{{.Type}}</td><td>{{range .Values}}<code>{{.}}</code><br>{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No DNS records collected.</p>{{end}}
+</section>
+
+<section>
+<h2>Workflows</h2>
+<table>
+<tr><th>Workflow</th><th>Status</th><th>Started</th><th>Duration</th><th>Steps</th></tr>
+{{range .Report.Workflows}}<tr><td>{{.Name}}</td><td class="{{if eq .Status "completed"}}ok{{else}}fail{{end}}">{{.Status}}</td><td>{{time .StartedAt}}</td><td>{{seconds .DurationSeconds}}</td><td>{{range .Steps}}{{.Name}} <span class="muted">({{.Tool}}, {{if .Skipped}}skipped{{else}}{{seconds .DurationSeconds}}{{end}})</span>{{if .Error}} <span class="fail">{{.Error}}</span>{{end}}<br>{{end}}</td></tr>
+{{end}}</table>
+</section>
+
+<section>
+<h2>Tool timings</h2>
+{{if .Executions}}
+<table>
+<tr><th>Tool</th><th>Mode</th><th>Workflow / step</th><th>Duration</th><th>Exit</th><th>Command</th><th>Output</th></tr>
+{{range .Executions}}<tr><td>{{.Tool}}</td><td>{{.Mode}}</td><td>{{.Workflow}} / {{.Step}}</td><td>{{seconds .DurationSeconds}}</td><td class="{{if .Warning}}warn{{else if .Success}}ok{{else}}fail{{end}}">{{.ExitCode}}</td><td><code>{{command .Command}}</code></td><td>{{if .Link}}<a href="{{.Link}}">{{.Link}}</a>{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No tools were executed.</p>{{end}}
+</section>
+
+<section>
+<h2>Raw output</h2>
+{{if .RawFiles}}
+<table>
+<tr><th>File</th><th>Size</th></tr>
+{{range .RawFiles}}<tr><td><a href="{{.Link}}">{{.Name}}</a></td><td>{{.Size}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No raw output in this workspace.</p>{{end}}
+</section>
+</main>
+</body>
+</html>
+`))
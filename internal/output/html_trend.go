@@ -0,0 +1,136 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Trend is a target's runs from the run database, oldest first and ending with the reported
+// run, charted in the HTML report
+type Trend struct {
+	Levels []string // Severity levels, most severe first
+	Points []TrendPoint
+}
+
+// TrendPoint is one run of the target
+type TrendPoint struct {
+	ScanID     string
+	StartedAt  time.Time
+	OpenPorts  int
+	Severities map[string]int // Open ports by severity level; nil when the run recorded none
+}
+
+// htmlTrend holds the rendered trend charts
+type htmlTrend struct {
+	Runs       int
+	First      time.Time
+	OpenPorts  template.HTML
+	Severities template.HTML
+	Legend     []trendSeries
+	Skipped    int // Runs recorded before severities were, left out of the severity chart
+}
+
+// trendSeries is one line of a chart
+type trendSeries struct {
+	Name   string
+	Color  string
+	Values []int
+}
+
+// severityColors colors the severity lines; other levels are grey
+var severityColors = map[string]string{
+	"critical": "#82071e",
+	"high":     "#cf222e",
+	"medium":   "#bc4c00",
+	"low":      "#9a6700",
+}
+
+// Chart geometry, in SVG user units
+const (
+	chartWidth  = 720
+	chartHeight = 200
+	chartLeft   = 40
+	chartRight  = 12
+	chartTop    = 12
+	chartBottom = 28
+)
+
+// renderTrend charts a trend of at least two runs; nil otherwise
+func renderTrend(trend *Trend) *htmlTrend {
+	if trend == nil || len(trend.Points) < 2 {
+		return nil
+	}
+	view := &htmlTrend{Runs: len(trend.Points), First: trend.Points[0].StartedAt}
+
+	ports := trendSeries{Name: "open ports", Color: "#0969da"}
+	for _, point := range trend.Points {
+		ports.Values = append(ports.Values, point.OpenPorts)
+	}
+	view.OpenPorts = trendChart(trend.Points, []trendSeries{ports})
+
+	var rated []TrendPoint
+	for _, point := range trend.Points {
+		if point.Severities != nil {
+			rated = append(rated, point)
+		}
+	}
+	view.Skipped = len(trend.Points) - len(rated)
+	if len(rated) < 2 {
+		return view
+	}
+	for _, level := range trend.Levels {
+		series := trendSeries{Name: level, Color: severityColors[level]}
+		if series.Color == "" {
+			series.Color = "#8c959f"
+		}
+		for _, point := range rated {
+			series.Values = append(series.Values, point.Severities[level])
+		}
+		view.Legend = append(view.Legend, series)
+	}
+	view.Severities = trendChart(rated, view.Legend)
+	return view
+}
+
+// trendChart draws one line per series over the runs as an inline SVG
+func trendChart(points []TrendPoint, series []trendSeries) template.HTML {
+	maxValue := 1
+	for _, s := range series {
+		for _, value := range s.Values {
+			maxValue = max(maxValue, value)
+		}
+	}
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	x := func(i int) float64 { return chartLeft + plotWidth*float64(i)/float64(len(points)-1) }
+	y := func(value int) float64 { return chartTop + plotHeight*(1-float64(value)/float64(maxValue)) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img">`, chartWidth, chartHeight)
+	for _, value := range []int{0, maxValue} {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#d0d7de"/>`, chartLeft, y(value), chartWidth-chartRight, y(value))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%d</text>`, chartLeft-6, y(value), value)
+	}
+	for _, i := range []int{0, len(points) - 1} {
+		anchor := "start"
+		if i > 0 {
+			anchor = "end"
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="%s">%s</text>`, x(i), chartHeight-8, anchor, points[i].StartedAt.Format("2006-01-02"))
+	}
+	for _, s := range series {
+		coordinates := make([]string, len(s.Values))
+		for i, value := range s.Values {
+			coordinates[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(value))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, s.Color, strings.Join(coordinates, " "))
+		for i, value := range s.Values {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s %s: %d %s</title></circle>`,
+				x(i), y(value), s.Color, points[i].StartedAt.Format("2006-01-02 15:04"), template.HTMLEscapeString(points[i].ScanID), value, s.Name)
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
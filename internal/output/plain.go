@@ -0,0 +1,34 @@
+package output
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FormatRecord formats alternating keys and values as one key=value line for --plain output,
+// e.g. event=step_failed workflow="Enhanced Reconnaissance" tool=nmap. Values that are empty or
+// contain spaces, quotes, '=' or unprintable characters are quoted Go-style, so a record never
+// spans lines and splits cleanly on unquoted spaces. A trailing key without a value is dropped
+func FormatRecord(keyvals ...string) string {
+	var record strings.Builder
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if i > 0 {
+			record.WriteByte(' ')
+		}
+		record.WriteString(keyvals[i])
+		record.WriteByte('=')
+		record.WriteString(recordValue(keyvals[i+1]))
+	}
+	return record.String()
+}
+
+// recordValue quotes a value when it would not read back as one field
+func recordValue(value string) string {
+	if value == "" || strings.ContainsFunc(value, func(r rune) bool {
+		return r == '"' || r == '=' || r == '\\' || unicode.IsSpace(r) || !unicode.IsPrint(r)
+	}) {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,585 @@
+package output
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Protocol buffer encoding of RunReport following proto/ipcrawler/v1/report.proto.
+// The wire format is written by hand to keep the binary free of a protobuf runtime;
+// any protobuf implementation can decode the output with the published schema.
+
+// Protobuf wire types used by the schema
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// MarshalRunReportProto encodes a run report as a protobuf RunReport message
+func MarshalRunReportProto(report RunReport) []byte {
+	var b protoBuffer
+	b.string(1, report.ScanID)
+	b.string(2, report.Target)
+	b.string(3, report.Workspace)
+	b.string(4, report.Status)
+	b.string(5, report.Error)
+	b.time(6, report.StartedAt)
+	b.time(7, report.FinishedAt)
+	b.double(8, report.DurationSeconds)
+	for _, workflow := range report.Workflows {
+		b.message(9, func(m *protoBuffer) { m.workflow(workflow) })
+	}
+	b.stringMap(10, report.Variables)
+	for _, port := range report.Ports {
+		b.message(11, func(m *protoBuffer) { m.port(port) })
+	}
+	for _, service := range report.Services {
+		b.repeatedString(12, service)
+	}
+	for _, warning := range report.Warnings {
+		b.message(13, func(m *protoBuffer) { m.warning(warning) })
+	}
+	b.string(14, report.Session)
+	b.string(15, report.Timezone)
+	return b.data
+}
+
+// UnmarshalRunReportProto decodes a protobuf RunReport message
+func UnmarshalRunReportProto(data []byte) (RunReport, error) {
+	report := RunReport{
+		Workflows: make([]WorkflowReport, 0),
+		Variables: make(map[string]string),
+		Ports:     make([]DiscoveredPort, 0),
+		Services:  make([]string, 0),
+	}
+	err := decodeMessage(data, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			return r.string(&report.ScanID)
+		case 2:
+			return r.string(&report.Target)
+		case 3:
+			return r.string(&report.Workspace)
+		case 4:
+			return r.string(&report.Status)
+		case 5:
+			return r.string(&report.Error)
+		case 6:
+			return r.time(&report.StartedAt)
+		case 7:
+			return r.time(&report.FinishedAt)
+		case 8:
+			return r.double(&report.DurationSeconds)
+		case 9:
+			var workflow WorkflowReport
+			if err := r.message(func(data []byte) error { return decodeWorkflow(data, &workflow) }); err != nil {
+				return err
+			}
+			report.Workflows = append(report.Workflows, workflow)
+		case 10:
+			return r.mapEntry(report.Variables)
+		case 11:
+			var port DiscoveredPort
+			if err := r.message(func(data []byte) error { return decodePort(data, &port) }); err != nil {
+				return err
+			}
+			report.Ports = append(report.Ports, port)
+		case 12:
+			var service string
+			if err := r.string(&service); err != nil {
+				return err
+			}
+			report.Services = append(report.Services, service)
+		case 13:
+			var warning RunWarning
+			if err := r.message(func(data []byte) error { return decodeWarning(data, &warning) }); err != nil {
+				return err
+			}
+			report.Warnings = append(report.Warnings, warning)
+		case 14:
+			return r.string(&report.Session)
+		case 15:
+			return r.string(&report.Timezone)
+		default:
+			return r.skip()
+		}
+		return nil
+	})
+	if err != nil {
+		return RunReport{}, fmt.Errorf("invalid protobuf run report: %w", err)
+	}
+	return report, nil
+}
+
+func (b *protoBuffer) workflow(workflow WorkflowReport) {
+	b.string(1, workflow.Name)
+	b.string(2, workflow.Target)
+	b.string(3, workflow.Status)
+	b.string(4, workflow.Error)
+	b.time(5, workflow.StartedAt)
+	b.time(6, workflow.FinishedAt)
+	b.double(7, workflow.DurationSeconds)
+	for _, step := range workflow.Steps {
+		b.message(8, func(m *protoBuffer) { m.step(step) })
+	}
+}
+
+func decodeWorkflow(data []byte, workflow *WorkflowReport) error {
+	return decodeMessage(data, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			return r.string(&workflow.Name)
+		case 2:
+			return r.string(&workflow.Target)
+		case 3:
+			return r.string(&workflow.Status)
+		case 4:
+			return r.string(&workflow.Error)
+		case 5:
+			return r.time(&workflow.StartedAt)
+		case 6:
+			return r.time(&workflow.FinishedAt)
+		case 7:
+			return r.double(&workflow.DurationSeconds)
+		case 8:
+			var step StepReport
+			if err := r.message(func(data []byte) error { return decodeStep(data, &step) }); err != nil {
+				return err
+			}
+			workflow.Steps = append(workflow.Steps, step)
+			return nil
+		default:
+			return r.skip()
+		}
+	})
+}
+
+func (b *protoBuffer) step(step StepReport) {
+	b.string(1, step.Name)
+	b.string(2, step.Tool)
+	for _, mode := range step.Modes {
+		b.repeatedString(3, mode)
+	}
+	b.bool(4, step.Success)
+	b.string(5, step.Error)
+	b.double(6, step.DurationSeconds)
+	b.stringMap(7, step.CombinedVariables)
+	for _, execution := range step.Executions {
+		b.message(8, func(m *protoBuffer) { m.execution(execution) })
+	}
+	b.stringMap(9, step.Matrix)
+	b.bool(10, step.Skipped)
+	b.string(11, step.SkipReason)
+	for _, dependency := range step.DependsOn {
+		b.repeatedString(12, dependency)
+	}
+	b.int(13, int64(step.Order))
+}
+
+func decodeStep(data []byte, step *StepReport) error {
+	return decodeMessage(data, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			return r.string(&step.Name)
+		case 2:
+			return r.string(&step.Tool)
+		case 3:
+			var mode string
+			if err := r.string(&mode); err != nil {
+				return err
+			}
+			step.Modes = append(step.Modes, mode)
+			return nil
+		case 4:
+			return r.bool(&step.Success)
+		case 5:
+			return r.string(&step.Error)
+		case 6:
+			return r.double(&step.DurationSeconds)
+		case 7:
+			if step.CombinedVariables == nil {
+				step.CombinedVariables = make(map[string]string)
+			}
+			return r.mapEntry(step.CombinedVariables)
+		case 8:
+			var execution ToolExecution
+			if err := r.message(func(data []byte) error { return decodeExecution(data, &execution) }); err != nil {
+				return err
+			}
+			step.Executions = append(step.Executions, execution)
+			return nil
+		case 9:
+			if step.Matrix == nil {
+				step.Matrix = make(map[string]string)
+			}
+			return r.mapEntry(step.Matrix)
+		case 10:
+			return r.bool(&step.Skipped)
+		case 11:
+			return r.string(&step.SkipReason)
+		case 12:
+			var dependency string
+			if err := r.string(&dependency); err != nil {
+				return err
+			}
+			step.DependsOn = append(step.DependsOn, dependency)
+			return nil
+		case 13:
+			return r.int32(&step.Order)
+		default:
+			return r.skip()
+		}
+	})
+}
+
+func (b *protoBuffer) execution(execution ToolExecution) {
+	b.string(1, execution.Tool)
+	b.string(2, execution.Mode)
+	for _, arg := range execution.Command {
+		b.repeatedString(3, arg)
+	}
+	b.int(4, int64(execution.ExitCode))
+	b.bool(5, execution.Success)
+	b.time(6, execution.StartTime)
+	b.time(7, execution.EndTime)
+	b.double(8, execution.DurationSeconds)
+	b.string(9, execution.OutputPath)
+	b.string(10, execution.Error)
+	b.string(11, execution.FallbackFor)
+	b.string(12, execution.FallbackReason)
+	b.string(13, execution.Warning)
+}
+
+func decodeExecution(data []byte, execution *ToolExecution) error {
+	return decodeMessage(data, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			return r.string(&execution.Tool)
+		case 2:
+			return r.string(&execution.Mode)
+		case 3:
+			var arg string
+			if err := r.string(&arg); err != nil {
+				return err
+			}
+			execution.Command = append(execution.Command, arg)
+			return nil
+		case 4:
+			return r.int32(&execution.ExitCode)
+		case 5:
+			return r.bool(&execution.Success)
+		case 6:
+			return r.time(&execution.StartTime)
+		case 7:
+			return r.time(&execution.EndTime)
+		case 8:
+			return r.double(&execution.DurationSeconds)
+		case 9:
+			return r.string(&execution.OutputPath)
+		case 10:
+			return r.string(&execution.Error)
+		case 11:
+			return r.string(&execution.FallbackFor)
+		case 12:
+			return r.string(&execution.FallbackReason)
+		case 13:
+			return r.string(&execution.Warning)
+		default:
+			return r.skip()
+		}
+	})
+}
+
+func (b *protoBuffer) warning(warning RunWarning) {
+	b.string(1, warning.Kind)
+	b.string(2, warning.Tool)
+	b.string(3, warning.Mode)
+	b.string(4, warning.Target)
+	b.string(5, warning.Message)
+	b.time(6, warning.Time)
+}
+
+func decodeWarning(data []byte, warning *RunWarning) error {
+	return decodeMessage(data, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			return r.string(&warning.Kind)
+		case 2:
+			return r.string(&warning.Tool)
+		case 3:
+			return r.string(&warning.Mode)
+		case 4:
+			return r.string(&warning.Target)
+		case 5:
+			return r.string(&warning.Message)
+		case 6:
+			return r.time(&warning.Time)
+		default:
+			return r.skip()
+		}
+	})
+}
+
+func (b *protoBuffer) port(port DiscoveredPort) {
+	b.string(1, port.Host)
+	b.int(2, int64(port.Port))
+	b.string(3, port.Protocol)
+	b.string(4, port.Service)
+	b.string(5, port.Product)
+	b.string(6, port.Version)
+	for _, tool := range port.Tools {
+		b.repeatedString(7, tool)
+	}
+}
+
+func decodePort(data []byte, port *DiscoveredPort) error {
+	return decodeMessage(data, func(field int, r *protoReader) error {
+		switch field {
+		case 1:
+			return r.string(&port.Host)
+		case 2:
+			return r.int32(&port.Port)
+		case 3:
+			return r.string(&port.Protocol)
+		case 4:
+			return r.string(&port.Service)
+		case 5:
+			return r.string(&port.Product)
+		case 6:
+			return r.string(&port.Version)
+		case 7:
+			var tool string
+			if err := r.string(&tool); err != nil {
+				return err
+			}
+			port.Tools = append(port.Tools, tool)
+			return nil
+		default:
+			return r.skip()
+		}
+	})
+}
+
+// protoBuffer appends protobuf fields; proto3 default values are omitted
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) tag(field, wireType int) {
+	b.data = binary.AppendUvarint(b.data, uint64(field)<<3|uint64(wireType))
+}
+
+func (b *protoBuffer) string(field int, value string) {
+	if value != "" {
+		b.repeatedString(field, value)
+	}
+}
+
+// repeatedString writes a string even when empty, as elements of repeated fields must be kept
+func (b *protoBuffer) repeatedString(field int, value string) {
+	b.tag(field, wireBytes)
+	b.data = binary.AppendUvarint(b.data, uint64(len(value)))
+	b.data = append(b.data, value...)
+}
+
+// int writes int32/int64 fields; negative values are sign-extended to 64 bits as protobuf requires
+func (b *protoBuffer) int(field int, value int64) {
+	if value != 0 {
+		b.tag(field, wireVarint)
+		b.data = binary.AppendUvarint(b.data, uint64(value))
+	}
+}
+
+func (b *protoBuffer) bool(field int, value bool) {
+	if value {
+		b.tag(field, wireVarint)
+		b.data = append(b.data, 1)
+	}
+}
+
+func (b *protoBuffer) double(field int, value float64) {
+	if value != 0 {
+		b.tag(field, wireFixed64)
+		b.data = binary.LittleEndian.AppendUint64(b.data, math.Float64bits(value))
+	}
+}
+
+func (b *protoBuffer) time(field int, value time.Time) {
+	if !value.IsZero() {
+		b.int(field, value.UnixNano())
+	}
+}
+
+func (b *protoBuffer) message(field int, encode func(*protoBuffer)) {
+	var m protoBuffer
+	encode(&m)
+	b.tag(field, wireBytes)
+	b.data = binary.AppendUvarint(b.data, uint64(len(m.data)))
+	b.data = append(b.data, m.data...)
+}
+
+// stringMap writes map<string, string> entries in key order for deterministic output
+func (b *protoBuffer) stringMap(field int, values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.message(field, func(m *protoBuffer) {
+			m.string(1, key)
+			m.string(2, values[key])
+		})
+	}
+}
+
+// protoReader reads the value of the current field
+type protoReader struct {
+	data     []byte
+	wireType int
+}
+
+// decodeMessage calls handle for every field of a message
+func decodeMessage(data []byte, handle func(field int, r *protoReader) error) error {
+	r := &protoReader{data: data}
+	for len(r.data) > 0 {
+		key, n := binary.Uvarint(r.data)
+		if n <= 0 {
+			return errTruncated
+		}
+		r.data = r.data[n:]
+		r.wireType = int(key & 7)
+		if err := handle(int(key>>3), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *protoReader) expect(wireType int) error {
+	if r.wireType != wireType {
+		return fmt.Errorf("unexpected wire type %d (want %d)", r.wireType, wireType)
+	}
+	return nil
+}
+
+func (r *protoReader) varint() (uint64, error) {
+	if err := r.expect(wireVarint); err != nil {
+		return 0, err
+	}
+	value, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	r.data = r.data[n:]
+	return value, nil
+}
+
+func (r *protoReader) bytes() ([]byte, error) {
+	if err := r.expect(wireBytes); err != nil {
+		return nil, err
+	}
+	length, n := binary.Uvarint(r.data)
+	if n <= 0 || uint64(len(r.data)-n) < length {
+		return nil, errTruncated
+	}
+	value := r.data[n : n+int(length)]
+	r.data = r.data[n+int(length):]
+	return value, nil
+}
+
+func (r *protoReader) string(target *string) error {
+	value, err := r.bytes()
+	*target = string(value)
+	return err
+}
+
+func (r *protoReader) int32(target *int) error {
+	value, err := r.varint()
+	*target = int(int32(value))
+	return err
+}
+
+func (r *protoReader) bool(target *bool) error {
+	value, err := r.varint()
+	*target = value != 0
+	return err
+}
+
+func (r *protoReader) double(target *float64) error {
+	if err := r.expect(wireFixed64); err != nil {
+		return err
+	}
+	if len(r.data) < 8 {
+		return errTruncated
+	}
+	*target = math.Float64frombits(binary.LittleEndian.Uint64(r.data))
+	r.data = r.data[8:]
+	return nil
+}
+
+func (r *protoReader) time(target *time.Time) error {
+	value, err := r.varint()
+	if err == nil && value != 0 {
+		*target = time.Unix(0, int64(value))
+	}
+	return err
+}
+
+func (r *protoReader) message(decode func([]byte) error) error {
+	value, err := r.bytes()
+	if err != nil {
+		return err
+	}
+	return decode(value)
+}
+
+func (r *protoReader) mapEntry(target map[string]string) error {
+	var key, value string
+	err := r.message(func(data []byte) error {
+		return decodeMessage(data, func(field int, entry *protoReader) error {
+			switch field {
+			case 1:
+				return entry.string(&key)
+			case 2:
+				return entry.string(&value)
+			default:
+				return entry.skip()
+			}
+		})
+	})
+	target[key] = value
+	return err
+}
+
+// skip discards the value of an unknown field so newer reports stay readable
+func (r *protoReader) skip() error {
+	switch r.wireType {
+	case wireVarint:
+		_, err := r.varint()
+		return err
+	case wireFixed64:
+		if len(r.data) < 8 {
+			return errTruncated
+		}
+		r.data = r.data[8:]
+	case wireBytes:
+		_, err := r.bytes()
+		return err
+	case 5: // fixed32
+		if len(r.data) < 4 {
+			return errTruncated
+		}
+		r.data = r.data[4:]
+	default:
+		return fmt.Errorf("unsupported wire type %d", r.wireType)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+	"github.com/neur0map/ipcrawler/internal/secrets"
+)
+
+// Redactor scrubs sensitive strings (the values of loaded secrets and matches of the patterns
+// in output.redaction) from console output, logs and reports before they are written.
+// A nil Redactor leaves text unchanged
+type Redactor struct {
+	secrets *secrets.Store
+	rules   []redactionRule
+}
+
+type redactionRule struct {
+	name        string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// NewRedactor compiles the configured patterns. Secret values are redacted whether or not
+// pattern redaction is enabled
+func NewRedactor(store *secrets.Store, cfg config.RedactionConfig) (*Redactor, error) {
+	redactor := &Redactor{secrets: store}
+	if !cfg.Enabled {
+		return redactor, nil
+	}
+	for i, rule := range cfg.Patterns {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("pattern_%d", i+1)
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("redaction pattern %s: empty pattern", name)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %s: %v", name, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = "[redacted:" + name + "]"
+		}
+		redactor.rules = append(redactor.rules, redactionRule{name: name, pattern: pattern, replacement: replacement})
+	}
+	return redactor, nil
+}
+
+// Enabled reports whether the redactor changes anything
+func (r *Redactor) Enabled() bool {
+	return r != nil && (r.secrets.Len() > 0 || len(r.rules) > 0)
+}
+
+// Redact scrubs one string. A pattern with capture groups replaces only the groups, so
+// `password=(\S+)` keeps "password=" and hides the value; otherwise the whole match goes
+func (r *Redactor) Redact(text string) string {
+	if !r.Enabled() || text == "" {
+		return text
+	}
+	text = r.secrets.Redact(text)
+	for _, rule := range r.rules {
+		text = rule.apply(text)
+	}
+	return text
+}
+
+// RedactAll redacts every string of a slice, returning a copy
+func (r *Redactor) RedactAll(values []string) []string {
+	if !r.Enabled() {
+		return values
+	}
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		redacted[i] = r.Redact(value)
+	}
+	return redacted
+}
+
+func (rule redactionRule) apply(text string) string {
+	if rule.pattern.NumSubexp() == 0 {
+		return rule.pattern.ReplaceAllLiteralString(text, rule.replacement)
+	}
+	matches := rule.pattern.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		for group := 2; group+1 < len(match); group += 2 {
+			start, end := match[group], match[group+1]
+			if start < last || start == end {
+				continue // Unmatched, empty or nested in a group already replaced
+			}
+			builder.WriteString(text[last:start])
+			builder.WriteString(rule.replacement)
+			last = end
+		}
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+// RedactCopy returns a deep copy of a report value (a struct, pointer, slice or map) with
+// every string field redacted; the original is left untouched. Fields tagged `redact:"-"`,
+// such as workspace paths the report writers need, are copied as they are
+func (r *Redactor) RedactCopy(value interface{}) interface{} {
+	if !r.Enabled() || value == nil {
+		return value
+	}
+	return r.redactValue(reflect.ValueOf(value)).Interface()
+}
+
+func (r *Redactor) redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(r.Redact(v.String()))
+		return out
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(r.redactValue(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(r.redactValue(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("redact") == "-" {
+				continue
+			}
+			out.Field(i).Set(r.redactValue(v.Field(i)))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(r.redactValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(r.redactValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), r.redactValue(iter.Value())) // Keys are names, not values
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redactingWriter redacts what a logger writes; loggers write one whole entry per call
+type redactingWriter struct {
+	w        io.Writer
+	redactor *Redactor
+}
+
+// NewRedactingWriter wraps w so everything written through it is redacted first. It expects
+// whole lines per write, as log sinks produce; a value split across writes is not caught
+func NewRedactingWriter(w io.Writer, redactor *Redactor) io.Writer {
+	if !redactor.Enabled() {
+		return w
+	}
+	return &redactingWriter{w: w, redactor: redactor}
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.redactor.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,346 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// JSONReportFileName is the machine-readable run report written to the workspace reports directory
+const JSONReportFileName = "report.json"
+
+// ProtobufReportFileName is the compact binary run report (see proto/ipcrawler/v1/report.proto)
+const ProtobufReportFileName = "report.pb"
+
+// Results encodings for the run report
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+// ValidateResultsEncoding checks a configured results encoding; empty means JSON
+func ValidateResultsEncoding(encoding string) error {
+	switch encoding {
+	case "", EncodingJSON, EncodingProtobuf:
+		return nil
+	default:
+		return fmt.Errorf("invalid results encoding '%s': must be %s or %s", encoding, EncodingJSON, EncodingProtobuf)
+	}
+}
+
+// RunReport is the machine-readable record of everything a run executed and discovered
+type RunReport struct {
+	ScanID          string            `json:"scan_id"`
+	Session         string            `json:"session,omitempty"`  // --session-name
+	Timezone        string            `json:"timezone,omitempty"` // Engagement timezone for target-local times
+	Target          string            `json:"target"`
+	Workspace       string            `json:"workspace" redact:"-"`
+	Status          string            `json:"status"`
+	Error           string            `json:"error,omitempty"`
+	StartedAt       time.Time         `json:"started_at"`
+	FinishedAt      time.Time         `json:"finished_at,omitempty"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Workflows       []WorkflowReport  `json:"workflows"`
+	Variables       map[string]string `json:"variables"`
+	Ports           []DiscoveredPort  `json:"ports"`
+	Services        []string          `json:"services"`
+	Warnings        []RunWarning      `json:"warnings,omitempty"`
+}
+
+// Warning kinds. A warning means the scan is imperfect (its results are less complete than
+// they should be), not that it failed
+const (
+	WarningParserDegraded     = "parser_degraded"     // A tool's output could not be parsed into variables
+	WarningCoverageReduced    = "coverage_reduced"    // A tool was cut short and its results are partial
+	WarningModeDowngraded     = "mode_downgraded"     // A mode was replaced by a less capable one
+	WarningExitCode           = "exit_code"           // A tool exited with a code its exit_codes declare a warning
+	WarningVersionUnsupported = "version_unsupported" // An installed tool is outside its supported versions
+)
+
+// RunWarning is one thing that made the scan imperfect without failing it
+type RunWarning struct {
+	Kind    string    `json:"kind"`
+	Tool    string    `json:"tool,omitempty"`
+	Mode    string    `json:"mode,omitempty"`
+	Target  string    `json:"target,omitempty"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// WorkflowReport records one workflow execution
+type WorkflowReport struct {
+	Name            string       `json:"name"`
+	Target          string       `json:"target"`
+	Status          string       `json:"status"`
+	Error           string       `json:"error,omitempty"`
+	StartedAt       time.Time    `json:"started_at"`
+	FinishedAt      time.Time    `json:"finished_at"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	Steps           []StepReport `json:"steps"`
+}
+
+// StepReport records one workflow step and its tool executions
+type StepReport struct {
+	Name              string            `json:"name"`
+	Tool              string            `json:"tool"`
+	Modes             []string          `json:"modes"`
+	Matrix            map[string]string `json:"matrix,omitempty"`     // Matrix values for steps expanded from a workflow matrix
+	DependsOn         []string          `json:"depends_on,omitempty"` // Steps this one waited for
+	Order             int               `json:"order,omitempty"`      // Position in which the step started within its workflow
+	Success           bool              `json:"success"`
+	Skipped           bool              `json:"skipped,omitempty"`     // run_if was false, so no tool ran
+	SkipReason        string            `json:"skip_reason,omitempty"` // The condition that skipped the step
+	Error             string            `json:"error,omitempty"`
+	DurationSeconds   float64           `json:"duration_seconds"`
+	CombinedVariables map[string]string `json:"combined_variables,omitempty"`
+	Executions        []ToolExecution   `json:"executions"`
+}
+
+// ToolExecution records a single tool invocation
+type ToolExecution struct {
+	Tool            string    `json:"tool"`
+	Mode            string    `json:"mode"`
+	Command         []string  `json:"command"`
+	ExitCode        int       `json:"exit_code"`
+	Success         bool      `json:"success"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	OutputPath      string    `json:"output_path,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	FallbackFor     string    `json:"fallback_for,omitempty"`    // Mode this execution replaced (unprivileged run or permission failure)
+	FallbackReason  string    `json:"fallback_reason,omitempty"` // Why the mode was replaced
+	Warning         string    `json:"warning,omitempty"`         // Exit code declared a warning in the tool's exit_codes
+}
+
+// DiscoveredPort is an open port found during the run
+type DiscoveredPort struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Protocol string   `json:"protocol"`
+	Service  string   `json:"service,omitempty"`
+	Product  string   `json:"product,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	Tools    []string `json:"tools,omitempty"`
+}
+
+// TargetLocalTime formats t in the engagement timezone of a run, or returns "" when the run
+// has none (or it no longer loads) or t is unknown
+func TargetLocalTime(t time.Time, timezone string) string {
+	local := inTimezone(t, timezone)
+	if local.IsZero() {
+		return ""
+	}
+	return local.Format(time.RFC3339)
+}
+
+// inTimezone converts t to the named timezone; the zero time when either is missing
+func inTimezone(t time.Time, timezone string) time.Time {
+	if timezone == "" || t.IsZero() {
+		return time.Time{}
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.In(location)
+}
+
+// ReportGenerator aggregates workflow results and variables from a run into a RunReport.
+// It is safe for concurrent use by parallel workflows
+type ReportGenerator struct {
+	report   RunReport
+	encoding string
+	redactor *Redactor // Applied to the written report (nil = none)
+	trend    *Trend    // Charted by WriteHTML (nil = none)
+	mutex    sync.Mutex
+}
+
+// NewReportGenerator starts a report for a run
+func NewReportGenerator(scanID, target, workspaceDir string, startedAt time.Time) *ReportGenerator {
+	return &ReportGenerator{
+		report: RunReport{
+			ScanID:    scanID,
+			Target:    target,
+			Workspace: workspaceDir,
+			Status:    "running",
+			StartedAt: startedAt,
+			Workflows: make([]WorkflowReport, 0),
+			Variables: make(map[string]string),
+			Ports:     make([]DiscoveredPort, 0),
+			Services:  make([]string, 0),
+		},
+	}
+}
+
+// SetEncoding selects how Write stores the report (EncodingJSON or EncodingProtobuf)
+func (rg *ReportGenerator) SetEncoding(encoding string) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.encoding = encoding
+}
+
+// SetRedactor redacts the report files Write and WriteHTML produce; Report stays unredacted
+func (rg *ReportGenerator) SetRedactor(redactor *Redactor) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.redactor = redactor
+}
+
+// SetSession records the run's session name, which also prefixes the HTML report's file name
+func (rg *ReportGenerator) SetSession(sessionName string) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.report.Session = sessionName
+}
+
+// SetTimezone records the engagement timezone the reports show target-local times in. The
+// host timezone records none, as in the run manifest
+func (rg *ReportGenerator) SetTimezone(location *time.Location) {
+	if location == nil || location == time.Local {
+		return
+	}
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.report.Timezone = location.String()
+}
+
+// SetTrend charts the target's earlier runs in the HTML report
+func (rg *ReportGenerator) SetTrend(trend *Trend) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.trend = trend
+}
+
+// RecordWorkflow adds a finished workflow to the report
+func (rg *ReportGenerator) RecordWorkflow(workflow WorkflowReport) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.report.Workflows = append(rg.report.Workflows, workflow)
+}
+
+// RecordWarning adds a warning to the report
+func (rg *ReportGenerator) RecordWarning(warning RunWarning) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.report.Warnings = append(rg.report.Warnings, warning)
+}
+
+// SetVariables replaces the recorded magic variables with the given snapshot
+func (rg *ReportGenerator) SetVariables(variables map[string]string) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.report.Variables = make(map[string]string, len(variables))
+	for name, value := range variables {
+		rg.report.Variables[name] = value
+	}
+}
+
+// SetDiscovered records the open ports found during the run and derives the service list
+func (rg *ReportGenerator) SetDiscovered(ports []DiscoveredPort) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+
+	rg.report.Ports = append(make([]DiscoveredPort, 0, len(ports)), ports...)
+	services := make(map[string]bool)
+	for _, port := range ports {
+		if port.Service != "" {
+			services[port.Service] = true
+		}
+	}
+	rg.report.Services = make([]string, 0, len(services))
+	for service := range services {
+		rg.report.Services = append(rg.report.Services, service)
+	}
+	sort.Strings(rg.report.Services)
+}
+
+// Finish records the final status of the run
+func (rg *ReportGenerator) Finish(status, errorMessage string, finishedAt time.Time, duration time.Duration) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+	rg.report.Status = status
+	rg.report.Error = errorMessage
+	rg.report.FinishedAt = finishedAt
+	rg.report.DurationSeconds = duration.Seconds()
+}
+
+// Report returns a copy of the aggregated report with workflows in start order
+func (rg *ReportGenerator) Report() RunReport {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+
+	report := rg.report
+	report.Workflows = append([]WorkflowReport(nil), rg.report.Workflows...)
+	report.Warnings = append([]RunWarning(nil), rg.report.Warnings...)
+	sort.SliceStable(report.Workflows, func(i, j int) bool {
+		return report.Workflows[i].StartedAt.Before(report.Workflows[j].StartedAt)
+	})
+	return report
+}
+
+// redactedReport returns the report as it is written: redacted when a redactor is set
+func (rg *ReportGenerator) redactedReport() RunReport {
+	report := rg.Report()
+	rg.mutex.Lock()
+	redactor := rg.redactor
+	rg.mutex.Unlock()
+	return redactor.RedactCopy(report).(RunReport)
+}
+
+// Write writes report.json (or report.pb with protobuf encoding) to the workspace reports
+// directory and returns its path
+func (rg *ReportGenerator) Write(perm os.FileMode) (string, error) {
+	report := rg.redactedReport()
+	rg.mutex.Lock()
+	encoding := rg.encoding
+	rg.mutex.Unlock()
+
+	var data []byte
+	fileName := JSONReportFileName
+	if encoding == EncodingProtobuf {
+		data = MarshalRunReportProto(report)
+		fileName = ProtobufReportFileName
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return "", fmt.Errorf("failed to marshal report: %w", err)
+		}
+	}
+
+	reportPath := filepath.Join(workspace.New(report.Workspace).Paths().Reports, fileName)
+	if err := os.WriteFile(reportPath, data, perm); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return reportPath, nil
+}
+
+// LoadRunReport reads report.json, or report.pb for protobuf-encoded runs, from a workspace's reports directory
+func LoadRunReport(workspaceDir string) (*RunReport, error) {
+	reportsDir := workspace.New(workspaceDir).Paths().Reports
+	data, err := os.ReadFile(filepath.Join(reportsDir, JSONReportFileName))
+	if os.IsNotExist(err) {
+		if binary, pbErr := os.ReadFile(filepath.Join(reportsDir, ProtobufReportFileName)); pbErr == nil {
+			report, err := UnmarshalRunReportProto(binary)
+			if err != nil {
+				return nil, err
+			}
+			return &report, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,370 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SARIF 2.1.0 identifiers
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF baseline states for results compared against an earlier run
+const (
+	SARIFBaselineNew       = "new"
+	SARIFBaselineUnchanged = "unchanged"
+	SARIFBaselineAbsent    = "absent"
+)
+
+// Fallback rules for results no workflow step can be attributed to
+const (
+	sarifOpenPortRule    = "ipcrawler/open-port"
+	sarifDNSTakeoverRule = "ipcrawler/dns-takeover-candidate"
+)
+
+// SARIFLog is the top-level SARIF document
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun holds the rules and results of one target's run
+type SARIFRun struct {
+	Tool              SARIFTool              `json:"tool"`
+	AutomationDetails *SARIFAutomation       `json:"automationDetails,omitempty"`
+	Results           []SARIFResult          `json:"results"`
+	Properties        map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFTool describes IPCrawler and its rules (one per workflow step)
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component that produced the results
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a workflow step or a built-in check
+type SARIFRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     SARIFMessage           `json:"shortDescription"`
+	FullDescription      SARIFMessage           `json:"fullDescription"`
+	DefaultConfiguration SARIFConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFConfiguration sets a rule's default level
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFAutomation identifies the run so CI dashboards can track it over time
+type SARIFAutomation struct {
+	ID string `json:"id"`
+}
+
+// SARIFMessage is a plain-text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is one finding
+type SARIFResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             SARIFMessage           `json:"message"`
+	Locations           []SARIFLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	BaselineState       string                 `json:"baselineState,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFLocation points at the tool output a result was parsed from and the affected host
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation is a workspace-relative file
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is a file URI
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation names a host or service
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+// takeoverServices maps CNAME suffixes of hosted services to their names.
+// A CNAME into one of these is a subdomain takeover candidate if the resource no longer exists
+var takeoverServices = map[string]string{
+	"s3.amazonaws.com":      "Amazon S3",
+	"cloudfront.net":        "Amazon CloudFront",
+	"elasticbeanstalk.com":  "AWS Elastic Beanstalk",
+	"azurewebsites.net":     "Azure App Service",
+	"cloudapp.net":          "Azure Cloud Services",
+	"trafficmanager.net":    "Azure Traffic Manager",
+	"blob.core.windows.net": "Azure Blob Storage",
+	"herokuapp.com":         "Heroku",
+	"herokudns.com":         "Heroku",
+	"github.io":             "GitHub Pages",
+	"netlify.app":           "Netlify",
+	"surge.sh":              "Surge",
+	"ghost.io":              "Ghost",
+	"myshopify.com":         "Shopify",
+	"pantheonsite.io":       "Pantheon",
+	"readthedocs.io":        "Read the Docs",
+	"bitbucket.io":          "Bitbucket",
+	"zendesk.com":           "Zendesk",
+	"wordpress.com":         "WordPress.com",
+	"fastly.net":            "Fastly",
+}
+
+// BuildSARIFRun maps a run's workflow steps to SARIF rules and its discovered ports,
+// failed steps, and DNS takeover candidates to results. baselineStates, keyed by
+// "host:port/protocol", marks results when comparing against an earlier run (may be nil)
+func BuildSARIFRun(report RunReport, baselineStates map[string]string) SARIFRun {
+	run := &sarifBuilder{ruleIndex: make(map[string]int)}
+	run.SARIFRun = SARIFRun{
+		Tool: SARIFTool{Driver: SARIFDriver{
+			Name:           "IPCrawler",
+			InformationURI: "https://github.com/neur0map/ipcrawler",
+			Rules:          make([]SARIFRule, 0),
+		}},
+		Results:    make([]SARIFResult, 0),
+		Properties: map[string]interface{}{"target": report.Target, "scan_id": report.ScanID},
+	}
+	if started := TargetLocalTime(report.StartedAt, report.Timezone); started != "" {
+		run.Properties["timezone"] = report.Timezone
+		run.Properties["started_at_target_local"] = started
+		if finished := TargetLocalTime(report.FinishedAt, report.Timezone); finished != "" {
+			run.Properties["finished_at_target_local"] = finished
+		}
+	}
+	if report.Target != "" {
+		run.AutomationDetails = &SARIFAutomation{ID: "ipcrawler/" + report.Target + "/"}
+	}
+
+	// Each workflow step is a rule; failed steps are error results of their rule
+	toolRules := make(map[string]string) // First step rule per tool, for attributing ports
+	outputs := make(map[string]string)   // First output file per tool, for result locations
+	dnsRule := ""
+	for _, workflow := range report.Workflows {
+		for _, step := range workflow.Steps {
+			ruleID := sarifSlug(workflow.Name) + "/" + sarifSlug(step.Name)
+			run.addRule(SARIFRule{
+				ID:                   ruleID,
+				Name:                 sarifRuleName(step.Name),
+				ShortDescription:     SARIFMessage{Text: step.Name},
+				FullDescription:      SARIFMessage{Text: fmt.Sprintf("Workflow %q step %q runs %s (%s)", workflow.Name, step.Name, step.Tool, strings.Join(step.Modes, ", "))},
+				DefaultConfiguration: SARIFConfiguration{Level: "note"},
+				Properties:           map[string]interface{}{"workflow": workflow.Name, "tool": step.Tool},
+			})
+			if _, exists := toolRules[step.Tool]; !exists {
+				toolRules[step.Tool] = ruleID
+			}
+			if _, produces := step.CombinedVariables["dns_cname_records"]; produces || step.Tool == "nslookup" {
+				dnsRule = ruleID
+			}
+
+			artifact := relativeArtifact(report.Workspace, step)
+			if artifact != "" {
+				if _, exists := outputs[step.Tool]; !exists {
+					outputs[step.Tool] = artifact
+				}
+			}
+			if !step.Success && step.Error != "" {
+				run.addResult(ruleID, "error", fmt.Sprintf("Step %q failed: %s", step.Name, step.Error),
+					sarifLocation(artifact, report.Target, ""), ruleID+":failed", "", nil)
+			}
+		}
+	}
+
+	for _, port := range report.Ports {
+		key := fmt.Sprintf("%s:%d/%s", port.Host, port.Port, port.Protocol)
+		ruleID, artifact := sarifOpenPortRule, ""
+		for _, tool := range port.Tools {
+			if id, exists := toolRules[tool]; exists && ruleID == sarifOpenPortRule {
+				ruleID = id
+			}
+			if artifact == "" {
+				artifact = outputs[tool]
+			}
+		}
+		if ruleID == sarifOpenPortRule {
+			run.addRule(builtinRule(sarifOpenPortRule, "OpenPort", "Open port", "An open port was discovered on a scanned host.", "note"))
+		}
+
+		service := strings.TrimSpace(strings.Join([]string{port.Service, port.Product, port.Version}, " "))
+		message := fmt.Sprintf("Open port %d/%s on %s", port.Port, port.Protocol, port.Host)
+		if service != "" {
+			message += " running " + service
+		}
+		run.addResult(ruleID, "note", message, sarifLocation(artifact, port.Host, key), key, baselineStates[key],
+			map[string]interface{}{
+				"host": port.Host, "port": port.Port, "protocol": port.Protocol,
+				"service": port.Service, "product": port.Product, "version": port.Version, "tools": port.Tools,
+			})
+	}
+
+	for _, candidate := range dnsTakeoverCandidates(report.Variables) {
+		ruleID := dnsRule
+		if ruleID == "" {
+			ruleID = sarifDNSTakeoverRule
+			run.addRule(builtinRule(sarifDNSTakeoverRule, "DNSTakeoverCandidate", "Subdomain takeover candidate",
+				"A CNAME points to a hosted service; if the resource was deleted, the name can be claimed by anyone.", "warning"))
+		}
+		run.addResult(ruleID, "warning",
+			fmt.Sprintf("%s is a CNAME to %s (%s); verify the resource still exists, dangling records can be taken over", report.Target, candidate.target, candidate.service),
+			sarifLocation("", report.Target, ""), "cname:"+candidate.target, "", map[string]interface{}{"cname": candidate.target, "service": candidate.service})
+	}
+
+	return run.SARIFRun
+}
+
+// WriteSARIF writes a SARIF log containing the given runs
+func WriteSARIF(w io.Writer, runs ...SARIFRun) error {
+	log := SARIFLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: runs}
+	if log.Runs == nil {
+		log.Runs = make([]SARIFRun, 0)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifBuilder tracks rule indices while a run is assembled
+type sarifBuilder struct {
+	SARIFRun
+	ruleIndex map[string]int
+}
+
+// addRule registers a rule once
+func (run *sarifBuilder) addRule(rule SARIFRule) {
+	if _, exists := run.ruleIndex[rule.ID]; exists {
+		return
+	}
+	run.ruleIndex[rule.ID] = len(run.Tool.Driver.Rules)
+	run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+}
+
+// addResult appends a result for a registered rule
+func (run *sarifBuilder) addResult(ruleID, level, message string, location SARIFLocation, fingerprint, baselineState string, properties map[string]interface{}) {
+	run.Results = append(run.Results, SARIFResult{
+		RuleID:              ruleID,
+		RuleIndex:           run.ruleIndex[ruleID],
+		Level:               level,
+		Message:             SARIFMessage{Text: message},
+		Locations:           []SARIFLocation{location},
+		PartialFingerprints: map[string]string{"ipcrawlerFinding/v1": fingerprint},
+		BaselineState:       baselineState,
+		Properties:          properties,
+	})
+}
+
+// builtinRule describes a check that is not tied to a workflow step
+func builtinRule(id, name, short, full, level string) SARIFRule {
+	return SARIFRule{
+		ID:                   id,
+		Name:                 name,
+		ShortDescription:     SARIFMessage{Text: short},
+		FullDescription:      SARIFMessage{Text: full},
+		DefaultConfiguration: SARIFConfiguration{Level: level},
+	}
+}
+
+// sarifLocation points at a workspace file (the run report when unknown) and names the host
+func sarifLocation(artifact, host, service string) SARIFLocation {
+	if artifact == "" {
+		artifact = "reports/" + JSONReportFileName
+	}
+	location := SARIFLocation{
+		PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: artifact}},
+	}
+	if host != "" {
+		location.LogicalLocations = append(location.LogicalLocations, SARIFLogicalLocation{Name: host, Kind: "host"})
+	}
+	if service != "" {
+		location.LogicalLocations = append(location.LogicalLocations, SARIFLogicalLocation{Name: service, FullyQualifiedName: service, Kind: "service"})
+	}
+	return location
+}
+
+// relativeArtifact returns the first output of a step relative to the workspace
+func relativeArtifact(workspaceDir string, step StepReport) string {
+	for _, execution := range step.Executions {
+		if execution.OutputPath == "" {
+			continue
+		}
+		rel, err := filepath.Rel(workspaceDir, execution.OutputPath)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return ""
+}
+
+// takeoverCandidate is a CNAME target on a hosted service
+type takeoverCandidate struct {
+	target  string
+	service string
+}
+
+// dnsTakeoverCandidates finds CNAME records that point to hosted services
+func dnsTakeoverCandidates(variables map[string]string) []takeoverCandidate {
+	seen := make(map[string]bool)
+	var candidates []takeoverCandidate
+	for name, value := range variables {
+		if !strings.Contains(name, "cname") {
+			continue
+		}
+		for _, record := range strings.Fields(strings.NewReplacer(",", " ", ";", " ").Replace(value)) {
+			record = strings.ToLower(strings.TrimSuffix(record, "."))
+			for suffix, service := range takeoverServices {
+				if (record == suffix || strings.HasSuffix(record, "."+suffix)) && !seen[record] {
+					seen[record] = true
+					candidates = append(candidates, takeoverCandidate{target: record, service: service})
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].target < candidates[j].target })
+	return candidates
+}
+
+var sarifSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// sarifSlug turns a workflow or step name into a rule ID segment
+func sarifSlug(name string) string {
+	return strings.Trim(sarifSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// sarifRuleName turns a step name into a PascalCase rule name
+func sarifRuleName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Fields(sarifSlugPattern.ReplaceAllString(strings.ToLower(name), " ")) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
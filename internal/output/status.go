@@ -0,0 +1,129 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Status kinds. Each is shown with its own symbol as well as its color, so statuses stay
+// distinguishable for color-blind users and on monochrome terminals
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusWarning = "warning"
+	StatusRunning = "running"
+	StatusPaused  = "paused"
+	StatusSkipped = "skipped"
+	StatusPending = "pending"
+	StatusInfo    = "info"
+)
+
+// Symbol sets for ui.display.status_symbols
+const (
+	SymbolsAuto    = "auto"    // Unicode when the locale is UTF-8, ASCII otherwise
+	SymbolsUnicode = "unicode" // ✓ ✗ ⚠ ▶ ⏸ ⊘ ○ ℹ
+	SymbolsASCII   = "ascii"   // + x ! > = - . i
+)
+
+// statusStyles holds each status kind's Unicode symbol, ASCII fallback and color
+var statusStyles = map[string]struct {
+	unicode, ascii, color string
+}{
+	StatusOK:      {"✓", "+", "\033[32m"},
+	StatusFailed:  {"✗", "x", "\033[31m"},
+	StatusWarning: {"⚠", "!", "\033[33m"},
+	StatusRunning: {"▶", ">", "\033[36m"},
+	StatusPaused:  {"⏸", "=", "\033[33m"},
+	StatusSkipped: {"⊘", "-", "\033[90m"},
+	StatusPending: {"○", ".", "\033[90m"},
+	StatusInfo:    {"ℹ", "i", "\033[36m"},
+}
+
+// ANSI color codes for terminal output; cleared when colors are off
+var (
+	colorReset  = "\033[0m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+	colorGray   = "\033[90m"
+	colorBold   = "\033[1m"
+)
+
+var (
+	asciiSymbols = !utf8Locale()
+	colorsOn     = true
+)
+
+func init() {
+	// https://no-color.org: any non-empty NO_COLOR turns colors off before config is read
+	if os.Getenv("NO_COLOR") != "" {
+		disableColors()
+	}
+}
+
+// ConfigureStatusStyle applies ui.display.status_symbols and symbols_only. In symbol-only
+// mode, and whenever NO_COLOR is set, no ANSI colors are written at all
+func ConfigureStatusStyle(symbols string, symbolsOnly bool) error {
+	switch strings.ToLower(strings.TrimSpace(symbols)) {
+	case "", SymbolsAuto:
+		asciiSymbols = !utf8Locale()
+	case SymbolsUnicode:
+		asciiSymbols = false
+	case SymbolsASCII:
+		asciiSymbols = true
+	default:
+		return fmt.Errorf("invalid status_symbols %q: use auto, unicode or ascii", symbols)
+	}
+	if symbolsOnly || os.Getenv("NO_COLOR") != "" {
+		disableColors()
+	}
+	return nil
+}
+
+// disableColors clears every color code so output is plain text
+func disableColors() {
+	colorsOn = false
+	colorReset, colorGreen, colorYellow, colorBlue = "", "", "", ""
+	colorCyan, colorGray, colorBold = "", "", ""
+}
+
+// utf8Locale reports whether the terminal locale can show the Unicode symbols
+func utf8Locale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToUpper(value)
+			return strings.Contains(value, "UTF-8") || strings.Contains(value, "UTF8")
+		}
+	}
+	// Windows consoles and macOS terminals are Unicode without setting a locale
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
+
+// StatusSymbol returns the symbol for a status kind in the configured symbol set
+func StatusSymbol(status string) string {
+	style, exists := statusStyles[status]
+	if !exists {
+		style = statusStyles[StatusPending]
+	}
+	if asciiSymbols {
+		return style.ascii
+	}
+	return style.unicode
+}
+
+// Paint colors text with a status kind's color, or returns it unchanged when colors are off
+func Paint(status, text string) string {
+	style, exists := statusStyles[status]
+	if !colorsOn || !exists {
+		return text
+	}
+	return style.color + text + colorReset
+}
+
+// FormatStatus returns text prefixed with the status symbol, in the status color
+func FormatStatus(status, text string) string {
+	return Paint(status, StatusSymbol(status)+" "+text)
+}
@@ -0,0 +1,80 @@
+package parsers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Parser extracts magic variables from a tool output file in a known format.
+// A tool's config.yaml selects one with "parser: <name>", so new tools get
+// structured extraction without engine or Go code changes
+type Parser interface {
+	Name() string
+	Parse(outputPath string) (map[string]string, error)
+}
+
+// LineParser is a Parser that can also consume output line by line while the tool is
+// still running, so results are ready the moment it exits
+type LineParser interface {
+	Parser
+	NewStream() LineStream
+}
+
+// LineStream accumulates variables from the lines of one output, fed in order
+type LineStream interface {
+	Line(line string)
+	Variables() map[string]string
+}
+
+var (
+	registry = make(map[string]Parser)
+	mutex    sync.RWMutex
+)
+
+// Register makes a parser available to tool configs by name
+func Register(parser Parser) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	registry[strings.ToLower(parser.Name())] = parser
+}
+
+// Lookup returns the parser registered under a name
+func Lookup(name string) (Parser, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	parser, exists := registry[strings.ToLower(strings.TrimSpace(name))]
+	return parser, exists
+}
+
+// Names returns the registered parser names in sorted order
+func Names() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// funcParser adapts a parse function to the Parser interface
+type funcParser struct {
+	name  string
+	parse func(outputPath string) map[string]string
+}
+
+func (p *funcParser) Name() string {
+	return p.name
+}
+
+func (p *funcParser) Parse(outputPath string) (map[string]string, error) {
+	return p.parse(outputPath), nil
+}
+
+// Adapt exposes a tool package's output parser under a format name (e.g. nmap's as "nmap_xml")
+// so other tools producing the same format can reuse it
+func Adapt(name string, parse func(outputPath string) map[string]string) Parser {
+	return &funcParser{name: name, parse: parse}
+}
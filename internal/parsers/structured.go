@@ -0,0 +1,236 @@
+package parsers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(&JSONLinesParser{})
+	Register(&JSONParser{})
+	Register(&LinesParser{})
+}
+
+// maxLineBytes bounds a single line of line-oriented output
+const maxLineBytes = 4 * 1024 * 1024
+
+// JSONLinesParser reads one JSON object per line (naabu -json, httpx -json, nuclei -jsonl, ...)
+type JSONLinesParser struct{}
+
+// Name returns the parser name used in tool configs
+func (p *JSONLinesParser) Name() string {
+	return "json_lines"
+}
+
+// Parse collects the records of a JSON Lines file; invalid lines are skipped
+func (p *JSONLinesParser) Parse(outputPath string) (map[string]string, error) {
+	return parseFileLines(outputPath, p.NewStream())
+}
+
+// NewStream returns an accumulator for JSON Lines output read as it is produced
+func (p *JSONLinesParser) NewStream() LineStream {
+	return &jsonLinesStream{records: newRecordAccumulator()}
+}
+
+// jsonLinesStream decodes one record per line
+type jsonLinesStream struct {
+	records *recordAccumulator
+}
+
+func (s *jsonLinesStream) Line(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return
+	}
+	s.records.add(record)
+}
+
+func (s *jsonLinesStream) Variables() map[string]string {
+	return s.records.variables()
+}
+
+// JSONParser reads a JSON document holding an array of objects or a single object
+type JSONParser struct{}
+
+// Name returns the parser name used in tool configs
+func (p *JSONParser) Name() string {
+	return "json"
+}
+
+// Parse collects the records of a JSON document
+func (p *JSONParser) Parse(outputPath string) (map[string]string, error) {
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output: %w", err)
+	}
+
+	var records []map[string]interface{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		var record map[string]interface{}
+		if objErr := json.Unmarshal(data, &record); objErr != nil {
+			return nil, fmt.Errorf("output is not a JSON object or array of objects: %w", err)
+		}
+		records = []map[string]interface{}{record}
+	}
+	return recordVariables(records), nil
+}
+
+// LinesParser treats every non-empty line as a result (subdomain lists, URL lists, ...)
+type LinesParser struct{}
+
+// Name returns the parser name used in tool configs
+func (p *LinesParser) Name() string {
+	return "lines"
+}
+
+// Parse returns the unique lines as "lines" and their number as "count"
+func (p *LinesParser) Parse(outputPath string) (map[string]string, error) {
+	return parseFileLines(outputPath, p.NewStream())
+}
+
+// NewStream returns an accumulator for line-list output read as it is produced
+func (p *LinesParser) NewStream() LineStream {
+	return &linesStream{seen: make(map[string]bool)}
+}
+
+// linesStream keeps the unique non-empty lines in the order they first appeared
+type linesStream struct {
+	lines []string
+	seen  map[string]bool
+}
+
+func (s *linesStream) Line(line string) {
+	line = strings.TrimSpace(line)
+	if line != "" && !s.seen[line] {
+		s.seen[line] = true
+		s.lines = append(s.lines, line)
+	}
+}
+
+func (s *linesStream) Variables() map[string]string {
+	return map[string]string{
+		"lines": strings.Join(s.lines, ","),
+		"count": strconv.Itoa(len(s.lines)),
+	}
+}
+
+// parseFileLines feeds every line of a finished output file to a stream
+func parseFileLines(outputPath string, stream LineStream) (map[string]string, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		stream.Line(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output: %w", err)
+	}
+	return stream.Variables(), nil
+}
+
+// recordVariables turns records into variables: "count" is the number of records, and every
+// top-level field becomes "<field>" (unique values, comma-separated) and "<field>_count"
+func recordVariables(records []map[string]interface{}) map[string]string {
+	accumulator := newRecordAccumulator()
+	for _, record := range records {
+		accumulator.add(record)
+	}
+	return accumulator.variables()
+}
+
+// recordAccumulator collects the unique field values of records added one at a time
+type recordAccumulator struct {
+	count  int
+	values map[string][]string
+	seen   map[string]map[string]bool
+}
+
+func newRecordAccumulator() *recordAccumulator {
+	return &recordAccumulator{
+		values: make(map[string][]string),
+		seen:   make(map[string]map[string]bool),
+	}
+}
+
+// add records one record's top-level fields
+func (a *recordAccumulator) add(record map[string]interface{}) {
+	a.count++
+	for key, raw := range record {
+		name := variableName(key)
+		if name == "" {
+			continue
+		}
+		if a.seen[name] == nil {
+			a.seen[name] = make(map[string]bool)
+		}
+		for _, value := range scalarValues(raw) {
+			if !a.seen[name][value] {
+				a.seen[name][value] = true
+				a.values[name] = append(a.values[name], value)
+			}
+		}
+	}
+}
+
+// variables returns the accumulated records as magic variables
+func (a *recordAccumulator) variables() map[string]string {
+	variables := map[string]string{"count": strconv.Itoa(a.count)}
+	for name, list := range a.values {
+		if name == "count" {
+			continue // Never shadow the record count
+		}
+		variables[name] = strings.Join(list, ",")
+		variables[name+"_count"] = strconv.Itoa(len(list))
+	}
+	return variables
+}
+
+// scalarValues flattens a JSON value into strings; nested objects are skipped
+func scalarValues(raw interface{}) []string {
+	switch value := raw.(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(value)}
+	case []interface{}:
+		var list []string
+		for _, item := range value {
+			list = append(list, scalarValues(item)...)
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+// variableName normalizes a JSON field name to a magic variable suffix (e.g. "status-code" -> "status_code")
+func variableName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
@@ -0,0 +1,224 @@
+package passive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseBytes bounds what one request may return (crt.sh answers for large domains run
+// to tens of megabytes)
+const maxResponseBytes = 64 << 20
+
+// maxRetries is how often a request is repeated after the source asks to slow down
+const maxRetries = 2
+
+// Client sends one source's requests: it waits for the source's rate limiter, answers from
+// the cache when it can and backs off when the source answers 429
+type Client struct {
+	source    string
+	http      *http.Client
+	limiter   *Limiter
+	cache     *Cache
+	userAgent string
+	apiKey    string
+}
+
+// APIKey returns the source's API key (its SourceInfo.Secret)
+func (c *Client) APIKey() string {
+	return c.apiKey
+}
+
+// Get fetches a URL and returns the body of a 200 response. The source's API key is kept out
+// of error messages when the URL carries it
+func (c *Client) Get(ctx context.Context, rawURL string, header http.Header) ([]byte, error) {
+	cacheKey := c.source + "\n" + rawURL
+	if body, ok := c.cache.Get(cacheKey); ok {
+		return body, nil
+	}
+	shown := c.hideKey(rawURL)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request %s: %v", shown, err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request to %s failed: %s", req.URL.Host, c.hideKey(err.Error())) // The error quotes the URL
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", shown, err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			c.cache.Put(cacheKey, body)
+			return body, nil
+		case (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) && attempt < maxRetries:
+			if err := sleep(ctx, retryAfter(resp, attempt)); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%s returned %s: %s", shown, resp.Status, truncate(c.hideKey(string(body)), 200))
+		}
+	}
+}
+
+// hideKey replaces the source's API key in text
+func (c *Client) hideKey(text string) string {
+	if c.apiKey == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, c.apiKey, "REDACTED")
+}
+
+// GetJSON fetches a URL like Get and decodes the JSON body into v
+func (c *Client) GetJSON(ctx context.Context, rawURL string, header http.Header, v interface{}) error {
+	body, err := c.Get(ctx, rawURL, header)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		c.cache.Remove(c.source + "\n" + rawURL) // An error page served as 200 is not worth keeping
+		return fmt.Errorf("unexpected %s response: %v", c.source, err)
+	}
+	return nil
+}
+
+// retryAfter is how long to wait before repeating a throttled request: the Retry-After
+// seconds when the source sends them (at most a minute), else 5s, 10s, ...
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		if seconds > 60 {
+			seconds = 60
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(5*(attempt+1)) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func truncate(s string, maxLen int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
+
+// Limiter spaces a source's requests evenly, at most the given number per minute
+type Limiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewLimiter creates a limiter; perMinute <= 0 does not limit
+func NewLimiter(perMinute int) *Limiter {
+	if perMinute <= 0 {
+		return &Limiter{}
+	}
+	return &Limiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// Wait blocks until the next request may be sent
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := time.Now()
+	wait := l.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	l.next = now.Add(wait + l.interval)
+	l.mutex.Unlock()
+	if wait == 0 {
+		return ctx.Err()
+	}
+	return sleep(ctx, wait)
+}
+
+// Cache keeps source responses on disk for a while, so repeated runs against a domain do not
+// spend the sources' rate limits. A nil Cache stores nothing
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewCache keeps responses in dir for ttl
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// path names a key's file; keys contain URLs, and sometimes API keys, so only a hash is used
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns a response stored less than the TTL ago
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return nil, false
+	}
+	body, err := os.ReadFile(path)
+	return body, err == nil
+}
+
+// Put stores a response; failures only cost a later request
+func (c *Cache) Put(key string, body []byte) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	path := c.path(key)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, body, 0600); err != nil {
+		return
+	}
+	os.Rename(tmpPath, path)
+}
+
+// Remove drops a stored response
+func (c *Cache) Remove(key string) {
+	if c != nil {
+		os.Remove(c.path(key))
+	}
+}
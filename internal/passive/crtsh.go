@@ -0,0 +1,42 @@
+package passive
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// crtshInfo searches certificate transparency logs: every name a certificate was issued for
+var crtshInfo = SourceInfo{
+	Name:              "crtsh",
+	Description:       "Certificate transparency logs (crt.sh)",
+	RequestsPerMinute: 5, // crt.sh throttles bursts hard
+	Default:           true,
+	Source:            crtsh{},
+}
+
+type crtsh struct{}
+
+// crtshEntry is one certificate of crt.sh's JSON output; name_value holds one name per line
+type crtshEntry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"`
+}
+
+func (crtsh) Query(ctx context.Context, domain string, client *Client) ([]Result, error) {
+	var entries []crtshEntry
+	query := url.Values{"q": {"%." + domain}, "output": {"json"}}
+	if err := client.GetJSON(ctx, "https://crt.sh/?"+query.Encode(), nil, &entries); err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, entry := range entries {
+		names := append(strings.Split(entry.NameValue, "\n"), entry.CommonName)
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" {
+				results = append(results, Result{Type: ResultSubdomain, Host: name})
+			}
+		}
+	}
+	return results, nil
+}
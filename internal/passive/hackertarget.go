@@ -0,0 +1,46 @@
+package passive
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// hackerTargetInfo uses HackerTarget's host search, a passive DNS lookup answering
+// "name,address" lines. The free tier allows a few dozen queries a day
+var hackerTargetInfo = SourceInfo{
+	Name:              "hackertarget",
+	Description:       "Passive DNS host search (HackerTarget)",
+	RequestsPerMinute: 2,
+	Default:           true,
+	Source:            hackerTarget{},
+}
+
+type hackerTarget struct{}
+
+func (hackerTarget) Query(ctx context.Context, domain string, client *Client) ([]Result, error) {
+	body, err := client.Get(ctx, "https://api.hackertarget.com/hostsearch/?q="+url.QueryEscape(domain), nil)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(body))
+	// Errors come back as 200 with a message instead of lines ("API count exceeded ...")
+	if text != "" && !strings.Contains(text, ",") {
+		return nil, fmt.Errorf("hackertarget: %s", truncate(text, 200))
+	}
+	var results []Result
+	for _, line := range strings.Split(text, "\n") {
+		host, address, _ := strings.Cut(strings.TrimSpace(line), ",")
+		if host == "" {
+			continue
+		}
+		result := Result{Type: ResultSubdomain, Host: host}
+		if net.ParseIP(address) != nil {
+			result.Address = address
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
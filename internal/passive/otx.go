@@ -0,0 +1,44 @@
+package passive
+
+import (
+	"context"
+	"net"
+	"net/url"
+)
+
+// otxInfo reads AlienVault OTX's passive DNS: names seen resolving under the domain, with
+// their addresses
+var otxInfo = SourceInfo{
+	Name:              "otx",
+	Description:       "Passive DNS (AlienVault OTX)",
+	RequestsPerMinute: 30,
+	Default:           true,
+	Source:            otx{},
+}
+
+type otx struct{}
+
+type otxPassiveDNS struct {
+	PassiveDNS []struct {
+		Hostname   string `json:"hostname"`
+		Address    string `json:"address"`
+		RecordType string `json:"record_type"`
+	} `json:"passive_dns"`
+}
+
+func (otx) Query(ctx context.Context, domain string, client *Client) ([]Result, error) {
+	var answer otxPassiveDNS
+	endpoint := "https://otx.alienvault.com/api/v1/indicators/domain/" + url.PathEscape(domain) + "/passive_dns"
+	if err := client.GetJSON(ctx, endpoint, nil, &answer); err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, record := range answer.PassiveDNS {
+		result := Result{Type: ResultSubdomain, Host: record.Hostname}
+		if (record.RecordType == "A" || record.RecordType == "AAAA") && net.ParseIP(record.Address) != nil {
+			result.Address = record.Address
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,269 @@
+// Package passive queries public data sources (certificate transparency logs, web archives,
+// passive DNS, Shodan) about a domain without touching it. Every source sends its requests
+// through a Client that rate limits and caches them, so a source only builds its requests and
+// reads the answers
+package passive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// Result types
+const (
+	ResultSubdomain = "subdomain" // A name under the domain, with its address when the source knows it
+	ResultURL       = "url"       // A URL seen under the domain (e.g. archived)
+	ResultService   = "service"   // A port the source saw open on one of the domain's hosts
+)
+
+// Result is one thing a source reported about the domain
+type Result struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Host    string `json:"host"`              // Name the result is about, lower-cased
+	Address string `json:"address,omitempty"` // Address the name resolved to
+	Port    int    `json:"port,omitempty"`    // Service results
+	Service string `json:"service,omitempty"` // Service or product on the port, when known
+	URL     string `json:"url,omitempty"`     // URL results
+}
+
+// Source is one passive data source
+type Source interface {
+	// Query reports what the source knows about domain, sending every request through client
+	Query(ctx context.Context, domain string, client *Client) ([]Result, error)
+}
+
+// SourceInfo describes a source
+type SourceInfo struct {
+	Name              string
+	Description       string
+	Secret            string // Secret holding the source's API key (e.g. shodan_api_key); empty for free sources
+	RequestsPerMinute int    // Default rate limit, below the source's published limits
+	Default           bool   // Queried when no sources are named
+	Source            Source
+}
+
+// sources are the available sources; a new source is a file implementing Source and a line here
+var sources = []SourceInfo{
+	crtshInfo,
+	hackerTargetInfo,
+	otxInfo,
+	shodanInfo,
+	waybackInfo,
+}
+
+// Sources returns every available source, by name
+func Sources() []SourceInfo {
+	list := append([]SourceInfo(nil), sources...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Lookup returns the source with the given name
+func Lookup(name string) (SourceInfo, bool) {
+	for _, info := range sources {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return SourceInfo{}, false
+}
+
+// SelectSources resolves source names: empty means the default sources, "all" every source
+func SelectSources(names []string) ([]SourceInfo, error) {
+	var selected []SourceInfo
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "all" {
+			return Sources(), nil
+		}
+		info, ok := Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown passive source %q (available: %s)", name, strings.Join(sourceNames(Sources()), ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, info)
+		}
+	}
+	if len(names) == 0 {
+		for _, info := range Sources() {
+			if info.Default {
+				selected = append(selected, info)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// Options configure a run over several sources
+type Options struct {
+	Sources           []string                          // Source names; empty for the default sources, "all" for every one
+	RequestsPerMinute map[string]int                    // Rate limits by source name, replacing the defaults
+	Cache             *Cache                            // Response cache; nil queries every time
+	Secret            func(name string) (string, error) // Looks up API keys; sources needing one are skipped without it
+	HTTPClient        *http.Client                      // Defaults to a client with a 30s timeout
+	UserAgent         string
+	OnSourceDone      func(source string, results int, err error) // Called as each source finishes
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Results []Result         // Deduplicated and sorted
+	Counts  map[string]int   // Results each source reported, by name
+	Errors  map[string]error // Sources that failed or were skipped, by name
+}
+
+// Run queries the selected sources about domain in parallel. A failing source does not stop
+// the others; Run only fails when the sources cannot be selected
+func Run(ctx context.Context, domain string, options Options) (*Report, error) {
+	domain = subdomains.NormalizeName(domain)
+	if !subdomains.IsHostname(domain) {
+		return nil, fmt.Errorf("passive sources need a domain, not %q", domain)
+	}
+	selected, err := SelectSources(options.Sources)
+	if err != nil {
+		return nil, err
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if options.UserAgent == "" {
+		options.UserAgent = "ipcrawler"
+	}
+
+	report := &Report{Counts: make(map[string]int), Errors: make(map[string]error)}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, info := range selected {
+		wg.Add(1)
+		go func(info SourceInfo) {
+			defer wg.Done()
+			results, err := runSource(ctx, domain, info, options)
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Counts[info.Name] = len(results)
+			if err != nil {
+				report.Errors[info.Name] = err
+			}
+			report.Results = append(report.Results, results...)
+			if options.OnSourceDone != nil {
+				options.OnSourceDone(info.Name, len(results), err)
+			}
+		}(info)
+	}
+	wg.Wait()
+
+	report.Results = dedupe(report.Results)
+	return report, nil
+}
+
+// runSource queries one source with its own limiter, keeping only results about the domain
+func runSource(ctx context.Context, domain string, info SourceInfo, options Options) ([]Result, error) {
+	client := &Client{
+		source:    info.Name,
+		http:      options.HTTPClient,
+		limiter:   NewLimiter(info.RequestsPerMinute),
+		cache:     options.Cache,
+		userAgent: options.UserAgent,
+	}
+	if perMinute, ok := options.RequestsPerMinute[info.Name]; ok {
+		client.limiter = NewLimiter(perMinute)
+	}
+	if info.Secret != "" {
+		if options.Secret == nil {
+			return nil, fmt.Errorf("skipped: needs secret %s", info.Secret)
+		}
+		key, err := options.Secret(info.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("skipped: %v", err)
+		}
+		client.apiKey = key
+	}
+
+	results, err := info.Source.Query(ctx, domain, client)
+	var kept []Result
+	for _, result := range results {
+		result.Source = info.Name
+		result.Host = subdomains.NormalizeName(result.Host)
+		if result.Host == domain || subdomains.IsSubdomain(result.Host, domain) {
+			kept = append(kept, result)
+		}
+	}
+	return kept, err
+}
+
+// dedupe merges identical results reported by several sources, joining their names. A
+// subdomain reported without an address is folded into the same name reported with one
+func dedupe(results []Result) []Result {
+	index := make(map[Result]int)
+	resolved := make(map[string][]int) // Subdomains with an address, by name
+	var merged []Result
+	for _, result := range results {
+		key := result
+		key.Source = ""
+		if i, exists := index[key]; exists {
+			merged[i].Source = joinSources(merged[i].Source, result.Source)
+			continue
+		}
+		index[key] = len(merged)
+		if result.Type == ResultSubdomain && result.Address != "" {
+			resolved[result.Host] = append(resolved[result.Host], len(merged))
+		}
+		merged = append(merged, result)
+	}
+	kept := merged[:0]
+	for _, result := range merged {
+		if result.Type == ResultSubdomain && result.Address == "" && len(resolved[result.Host]) > 0 {
+			for _, i := range resolved[result.Host] {
+				merged[i].Source = joinSources(merged[i].Source, result.Source)
+			}
+			continue
+		}
+		kept = append(kept, result)
+	}
+	merged = kept
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.URL < b.URL
+	})
+	return merged
+}
+
+// joinSources adds a source to a comma-separated list of source names
+func joinSources(list, source string) string {
+	for _, name := range strings.Split(list, ",") {
+		if name == source {
+			return list
+		}
+	}
+	return list + "," + source
+}
+
+func sourceNames(list []SourceInfo) []string {
+	names := make([]string, len(list))
+	for i, info := range list {
+		names[i] = info.Name
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package passive
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// shodanMaxPages bounds the pages read for one domain; every page costs a query credit
+const shodanMaxPages = 10
+
+// shodanInfo reads Shodan's DNS data for the domain: subdomains, their addresses and the
+// ports Shodan saw open on them. It needs an API key in the shodan_api_key secret
+var shodanInfo = SourceInfo{
+	Name:              "shodan",
+	Description:       "Subdomains and open ports (Shodan DNS API)",
+	Secret:            "shodan_api_key",
+	RequestsPerMinute: 60, // One request a second, the API's limit
+	Source:            shodan{},
+}
+
+type shodan struct{}
+
+type shodanDomain struct {
+	Data []struct {
+		Subdomain string `json:"subdomain"` // Relative to the domain; empty for the domain itself
+		Type      string `json:"type"`
+		Value     string `json:"value"`
+		Ports     []int  `json:"ports"`
+	} `json:"data"`
+	More bool `json:"more"`
+}
+
+func (shodan) Query(ctx context.Context, domain string, client *Client) ([]Result, error) {
+	var results []Result
+	for page := 1; ; page++ {
+		query := url.Values{"key": {client.APIKey()}, "page": {strconv.Itoa(page)}}
+		var answer shodanDomain
+		if err := client.GetJSON(ctx, "https://api.shodan.io/dns/domain/"+url.PathEscape(domain)+"?"+query.Encode(), nil, &answer); err != nil {
+			return results, err
+		}
+		for _, record := range answer.Data {
+			host := domain
+			if record.Subdomain != "" {
+				host = record.Subdomain + "." + domain
+			}
+			result := Result{Type: ResultSubdomain, Host: host}
+			if (record.Type == "A" || record.Type == "AAAA") && net.ParseIP(record.Value) != nil {
+				result.Address = record.Value
+			}
+			results = append(results, result)
+			for _, port := range record.Ports {
+				results = append(results, Result{Type: ResultService, Host: host, Address: result.Address, Port: port})
+			}
+		}
+		if !answer.More || page == shodanMaxPages {
+			return results, nil
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package passive
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// waybackLimit caps the archived URLs asked for; large sites have millions
+const waybackLimit = 10000
+
+// waybackInfo lists the URLs the Internet Archive captured under the domain, and the names
+// they were served from
+var waybackInfo = SourceInfo{
+	Name:              "wayback",
+	Description:       "Archived URLs (Wayback Machine CDX API)",
+	RequestsPerMinute: 15,
+	Default:           true,
+	Source:            wayback{},
+}
+
+type wayback struct{}
+
+func (wayback) Query(ctx context.Context, domain string, client *Client) ([]Result, error) {
+	query := url.Values{
+		"url":      {"*." + domain + "/*"},
+		"output":   {"json"},
+		"fl":       {"original"},
+		"collapse": {"urlkey"},
+		"limit":    {strconv.Itoa(waybackLimit)},
+	}
+	// Rows of fields, the first row naming them: [["original"], ["http://..."], ...]
+	var rows [][]string
+	if err := client.GetJSON(ctx, "https://web.archive.org/cdx/search/cdx?"+query.Encode(), nil, &rows); err != nil {
+		return nil, err
+	}
+	var results []Result
+	for i, row := range rows {
+		if i == 0 || len(row) == 0 {
+			continue
+		}
+		parsed, err := url.Parse(row[0])
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		host := parsed.Hostname()
+		results = append(results,
+			Result{Type: ResultURL, Host: host, URL: row[0]},
+			Result{Type: ResultSubdomain, Host: host},
+		)
+	}
+	return results, nil
+}
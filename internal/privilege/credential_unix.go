@@ -0,0 +1,20 @@
+//go:build !windows
+
+package privilege
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Apply makes the command run as the unprivileged user
+func (u *RunAsUser) Apply(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid: uint32(u.UID),
+		Gid: uint32(u.GID),
+	}
+	cmd.Env = u.Env(cmd.Env)
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package privilege
+
+import "os/exec"
+
+// Apply is a no-op on Windows, where sudo-style privilege dropping does not apply
+func (u *RunAsUser) Apply(cmd *exec.Cmd) {}
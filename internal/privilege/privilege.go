@@ -0,0 +1,116 @@
+// Package privilege drops root for the tools of a run started through sudo. Only the tools'
+// child processes run as the invoking user: IPCrawler itself stays root, so the workspace, its
+// logs and its reports are created owned by root and handed to the user by ChownTree when the
+// run starts and again when it ends, also after a first Ctrl-C. A run that never gets there
+// (a second interrupt, SIGKILL, a crash) leaves the files it wrote since owned by root until
+// they are chowned by hand
+package privilege
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RunAsUser identifies the unprivileged account that tools and workspace files run as
+// after IPCrawler was started through sudo
+type RunAsUser struct {
+	UID      int
+	GID      int
+	Username string
+	HomeDir  string
+}
+
+// FromSudo returns the invoking user when running as root via sudo.
+// It returns nil without error when the process is not running as root under sudo
+func FromSudo() (*RunAsUser, error) {
+	if os.Geteuid() != 0 {
+		return nil, nil
+	}
+
+	sudoUID := os.Getenv("SUDO_UID")
+	if sudoUID == "" {
+		return nil, nil
+	}
+
+	uid, err := strconv.Atoi(sudoUID)
+	if err != nil || uid < 0 {
+		return nil, fmt.Errorf("invalid SUDO_UID '%s'", sudoUID)
+	}
+	if uid == 0 {
+		return nil, nil // sudo from root, nothing to drop to
+	}
+
+	gid := uid
+	if sudoGID := os.Getenv("SUDO_GID"); sudoGID != "" {
+		gid, err = strconv.Atoi(sudoGID)
+		if err != nil || gid < 0 {
+			return nil, fmt.Errorf("invalid SUDO_GID '%s'", sudoGID)
+		}
+	}
+
+	runAs := &RunAsUser{
+		UID:      uid,
+		GID:      gid,
+		Username: os.Getenv("SUDO_USER"),
+	}
+
+	// Look up the home directory so tools write their own state under the user's home
+	if u, err := user.LookupId(sudoUID); err == nil {
+		runAs.HomeDir = u.HomeDir
+		if runAs.Username == "" {
+			runAs.Username = u.Username
+		}
+	}
+
+	return runAs, nil
+}
+
+// String returns a human-readable description of the user
+func (u *RunAsUser) String() string {
+	if u.Username != "" {
+		return fmt.Sprintf("%s (uid=%d gid=%d)", u.Username, u.UID, u.GID)
+	}
+	return fmt.Sprintf("uid=%d gid=%d", u.UID, u.GID)
+}
+
+// Env returns a copy of env with HOME, USER and LOGNAME pointing at the unprivileged user
+func (u *RunAsUser) Env(env []string) []string {
+	result := make([]string, 0, len(env)+3)
+	for _, entry := range env {
+		if (u.HomeDir != "" && strings.HasPrefix(entry, "HOME=")) ||
+			(u.Username != "" && (strings.HasPrefix(entry, "USER=") || strings.HasPrefix(entry, "LOGNAME="))) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	if u.HomeDir != "" {
+		result = append(result, "HOME="+u.HomeDir)
+	}
+	if u.Username != "" {
+		result = append(result, "USER="+u.Username, "LOGNAME="+u.Username)
+	}
+	return result
+}
+
+// Chown gives ownership of a single path to the user
+func (u *RunAsUser) Chown(path string) error {
+	if err := os.Lchown(path, u.UID, u.GID); err != nil {
+		return fmt.Errorf("failed to chown %s: %w", path, err)
+	}
+	return nil
+}
+
+// ChownTree gives ownership of root and everything below it to the user
+func (u *RunAsUser) ChownTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return u.Chown(path)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package privilege
+
+import "syscall"
+
+// SetUmask sets the process umask and returns the previous value
+func SetUmask(mask int) int {
+	return syscall.Umask(mask)
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package privilege
+
+// SetUmask is a no-op on Windows, which has no umask
+func SetUmask(mask int) int {
+	return 0
+}
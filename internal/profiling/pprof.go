@@ -0,0 +1,88 @@
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// DefaultAddress is used when --pprof is given without a value
+const DefaultAddress = "localhost:6060"
+
+// Server is an opt-in pprof HTTP listener
+type Server struct {
+	httpServer *http.Server
+	listener   net.Listener
+}
+
+// Start validates the address and starts serving /debug/pprof/ in the background.
+// Only loopback addresses are accepted since profiles expose memory contents
+func Start(address string) (*Server, error) {
+	if address == "" {
+		address = DefaultAddress
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pprof address '%s': %w", address, err)
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	if !isLoopback(host) {
+		return nil, fmt.Errorf("pprof address '%s' must be a loopback address (use an SSH tunnel for remote access)", address)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start pprof listener: %w", err)
+	}
+
+	// Sample contention so the block and mutex profiles are not empty
+	runtime.SetBlockProfileRate(int(time.Millisecond))
+	runtime.SetMutexProfileFraction(5)
+
+	// Dedicated mux so nothing else registered on the default mux is exposed
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &Server{
+		httpServer: &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener:   listener,
+	}
+	go server.httpServer.Serve(listener)
+
+	return server, nil
+}
+
+// URL returns the base URL of the profiling endpoints
+func (s *Server) URL() string {
+	return fmt.Sprintf("http://%s/debug/pprof/", s.listener.Addr().String())
+}
+
+// Stop shuts the listener down, waiting briefly for in-flight profile downloads
+func (s *Server) Stop() {
+	if s == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	s.httpServer.Shutdown(ctx)
+}
+
+// isLoopback reports whether host is localhost or a loopback IP
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
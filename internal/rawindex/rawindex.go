@@ -0,0 +1,355 @@
+// Package rawindex keeps a trigram index of a workspace's raw tool output, so a text search
+// reads only the files that can contain the query instead of every file in the workspace.
+// The index records which files hold each three-byte sequence (ASCII case folded); a search
+// intersects the sets of the query's trigrams and then scans just those files for the text.
+// Files written or changed after the index was built are scanned directly, so results are
+// never stale, only slower
+package rawindex
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// FileName is where the index is kept, relative to the workspace
+const FileName = "index/raw.idx"
+
+// Directories of a workspace holding raw tool output
+var indexedDirs = []string{workspace.ScansDir, workspace.RawDir}
+
+// binarySniffLen is how much of a file is checked for NUL bytes; binary files are skipped
+const binarySniffLen = 8000
+
+// File is one indexed file
+type File struct {
+	Path    string // Relative to the workspace, slash separated
+	Size    int64
+	ModTime time.Time
+}
+
+// Index maps trigrams to the files containing them
+type Index struct {
+	Built    time.Time
+	Files    []File
+	Postings map[uint32][]uint32 // Trigram -> sorted indexes into Files
+}
+
+// Hit is one matching line
+type Hit struct {
+	Path string `json:"path"` // Relative to the workspace
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Stats describes how a search used the index
+type Stats struct {
+	Indexed   int // Files covered by the index
+	Scanned   int // Files read: index candidates plus files the index does not cover
+	Unindexed int // Files new or changed since the index was built
+}
+
+// Build indexes the raw output of a workspace and writes the index into it, creating its
+// directory with dirPerm and the index file with filePerm
+func Build(workspaceDir string, dirPerm, filePerm os.FileMode) (*Index, error) {
+	files, err := listFiles(workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+	index := &Index{Built: time.Now(), Postings: make(map[uint32][]uint32)}
+	seen := newTrigramSet()
+	for _, file := range files {
+		// Binary files are listed without trigrams, so searches skip them
+		if err := addTrigrams(filepath.Join(workspaceDir, filepath.FromSlash(file.Path)), seen); err != nil {
+			return nil, err
+		}
+		id := uint32(len(index.Files))
+		index.Files = append(index.Files, file)
+		for _, trigram := range seen.list {
+			index.Postings[trigram] = append(index.Postings[trigram], id)
+		}
+		seen.reset()
+	}
+	if err := index.write(filepath.Join(workspaceDir, filepath.FromSlash(FileName)), dirPerm, filePerm); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// Load reads a workspace's index; the error satisfies os.IsNotExist when none was built
+func Load(workspaceDir string) (*Index, error) {
+	f, err := os.Open(filepath.Join(workspaceDir, filepath.FromSlash(FileName)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw output index: %w", err)
+	}
+	var index Index
+	if err := gob.NewDecoder(reader).Decode(&index); err != nil {
+		return nil, fmt.Errorf("invalid raw output index: %w", err)
+	}
+	return &index, nil
+}
+
+func (index *Index) write(path string, dirPerm, filePerm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(f)
+	err = gob.NewEncoder(writer).Encode(index)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write raw output index: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Search returns the lines of the workspace's raw output containing query (ASCII case
+// insensitive), at most limit of them when limit > 0. A nil index scans every file
+func Search(workspaceDir string, index *Index, query string, limit int) ([]Hit, Stats, error) {
+	var stats Stats
+	needle := foldASCII([]byte(query))
+	if len(needle) == 0 {
+		return nil, stats, fmt.Errorf("empty search text")
+	}
+	files, err := listFiles(workspaceDir)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	// Files the index covers unchanged are read only when they hold every trigram of the query
+	current := make(map[string]bool)
+	if index != nil {
+		stats.Indexed = len(index.Files)
+		candidates := index.candidates(needle)
+		for id, file := range index.Files {
+			current[file.Path] = true
+			if candidates != nil && !candidates[uint32(id)] {
+				current[file.Path] = false
+			}
+		}
+	}
+
+	var hits []Hit
+	for _, file := range files {
+		if index != nil {
+			read, indexed := current[file.Path]
+			if indexed && index.unchanged(file) {
+				if !read {
+					continue
+				}
+			} else {
+				stats.Unindexed++
+			}
+		}
+		stats.Scanned++
+		fileHits, err := grepFile(workspaceDir, file.Path, needle, limit-len(hits))
+		if err != nil {
+			return hits, stats, err
+		}
+		hits = append(hits, fileHits...)
+		if limit > 0 && len(hits) >= limit {
+			break
+		}
+	}
+	return hits, stats, nil
+}
+
+// candidates returns the files holding every trigram of needle, or nil when the needle is too
+// short to narrow the search
+func (index *Index) candidates(needle []byte) map[uint32]bool {
+	if len(needle) < 3 {
+		return nil
+	}
+	var result map[uint32]bool
+	for i := 0; i+3 <= len(needle); i++ {
+		next := make(map[uint32]bool)
+		for _, id := range index.Postings[trigramAt(needle, i)] {
+			if result == nil || result[id] {
+				next[id] = true
+			}
+		}
+		result = next
+		if len(result) == 0 {
+			break
+		}
+	}
+	return result
+}
+
+// unchanged reports whether file is in the index with the same size and modification time
+func (index *Index) unchanged(file File) bool {
+	i := sort.Search(len(index.Files), func(i int) bool { return index.Files[i].Path >= file.Path })
+	if i == len(index.Files) || index.Files[i].Path != file.Path {
+		return false
+	}
+	indexed := index.Files[i]
+	return indexed.Size == file.Size && indexed.ModTime.Equal(file.ModTime)
+}
+
+// listFiles lists the regular files under the indexed directories, sorted by path
+func listFiles(workspaceDir string) ([]File, error) {
+	var files []File
+	for _, dir := range indexedDirs {
+		root := filepath.Join(workspaceDir, dir)
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return nil
+				}
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(workspaceDir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, File{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", root, err)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// addTrigrams adds the trigrams of a text file to seen; binary files add none
+func addTrigrams(path string, seen *trigramSet) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReaderSize(f, 64*1024)
+	if head, _ := reader.Peek(binarySniffLen); bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+	var window uint32
+	n := 0
+	buf := make([]byte, 64*1024)
+	for {
+		count, err := reader.Read(buf)
+		for _, b := range buf[:count] {
+			if b == '\n' {
+				n = 0 // Searches match within a line
+				continue
+			}
+			window = (window<<8 | uint32(foldByte(b))) & 0xFFFFFF
+			if n++; n >= 3 {
+				seen.add(window)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// trigramSet collects the distinct trigrams of one file in a bitmap of every possible trigram,
+// reused across files
+type trigramSet struct {
+	bits []uint64
+	list []uint32
+}
+
+func newTrigramSet() *trigramSet {
+	return &trigramSet{bits: make([]uint64, 1<<24/64)}
+}
+
+func (s *trigramSet) add(trigram uint32) {
+	word, bit := trigram/64, uint64(1)<<(trigram%64)
+	if s.bits[word]&bit == 0 {
+		s.bits[word] |= bit
+		s.list = append(s.list, trigram)
+	}
+}
+
+func (s *trigramSet) reset() {
+	for _, trigram := range s.list {
+		s.bits[trigram/64] = 0
+	}
+	s.list = s.list[:0]
+}
+
+// grepFile returns the lines of a file containing needle, at most limit when limit > 0
+func grepFile(workspaceDir, rel string, needle []byte, limit int) ([]Hit, error) {
+	f, err := os.Open(filepath.Join(workspaceDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader := bufio.NewReaderSize(f, 64*1024)
+	if head, _ := reader.Peek(binarySniffLen); bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil
+	}
+	var hits []Hit
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && bytes.Contains(foldASCII(line), needle) {
+			hits = append(hits, Hit{Path: rel, Line: lineNumber, Text: string(bytes.TrimRight(line, "\r\n"))})
+			if limit > 0 && len(hits) >= limit {
+				return hits, nil
+			}
+		}
+		if err == io.EOF {
+			return hits, nil
+		}
+		if err != nil {
+			return hits, err
+		}
+	}
+}
+
+func trigramAt(b []byte, i int) uint32 {
+	return uint32(b[i])<<16 | uint32(b[i+1])<<8 | uint32(b[i+2])
+}
+
+func foldByte(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+func foldASCII(b []byte) []byte {
+	folded := make([]byte, len(b))
+	for i, c := range b {
+		folded[i] = foldByte(c)
+	}
+	return folded
+}
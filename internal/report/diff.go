@@ -0,0 +1,270 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/tools/nslookup"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// Diff change types
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// Kinds of results a diff compares, in the order they are listed
+const (
+	DiffKindPort      = "port"
+	DiffKindURL       = "url"
+	DiffKindDNS       = "dns"
+	DiffKindSubdomain = "subdomain"
+)
+
+// DiffKinds lists the result kinds a diff compares
+var DiffKinds = []string{DiffKindPort, DiffKindURL, DiffKindDNS, DiffKindSubdomain}
+
+// DiffRun holds the parsed results of one workspace for a diff
+type DiffRun struct {
+	Summary    *TargetSummary
+	DNS        []nslookup.Record
+	Subdomains []subdomains.Record
+}
+
+// Diff lists the results that were added, removed or changed between two runs
+type Diff struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Baseline    DiffRunInfo    `json:"baseline"`
+	Current     DiffRunInfo    `json:"current"`
+	Entries     []DiffEntry    `json:"entries"`
+	Added       int            `json:"added"`
+	Removed     int            `json:"removed"`
+	Changed     int            `json:"changed"`
+	Unchanged   map[string]int `json:"unchanged"`           // Results present and identical in both runs, by kind
+	NewHosts    []string       `json:"new_hosts,omitempty"` // Hosts with results in the current run only
+}
+
+// DiffRunInfo identifies a compared run
+type DiffRunInfo struct {
+	Workspace string    `json:"workspace"`
+	Target    string    `json:"target,omitempty"`
+	ScanID    string    `json:"scan_id,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+}
+
+// DiffEntry is one result that differs between the runs. Key identifies it within its kind
+// and host (e.g. "443/tcp", "MX example.com"); Before and After describe it in each run
+type DiffEntry struct {
+	Change string `json:"change"`
+	Kind   string `json:"kind"`
+	Host   string `json:"host,omitempty"`
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// diffFact is one comparable result: identity is kind, host and key; value is what can change
+type diffFact struct {
+	kind, host, key string
+	value           string
+}
+
+// BuildDiff compares the results of a current run with a baseline run of the same target
+func BuildDiff(baseline, current DiffRun) *Diff {
+	diff := &Diff{
+		GeneratedAt: time.Now(),
+		Baseline:    diffRunInfo(baseline.Summary),
+		Current:     diffRunInfo(current.Summary),
+		Entries:     make([]DiffEntry, 0),
+		Unchanged:   make(map[string]int),
+	}
+
+	before := diffFacts(baseline)
+	after := diffFacts(current)
+	for id, fact := range after {
+		previous, existed := before[id]
+		switch {
+		case !existed:
+			diff.Entries = append(diff.Entries, DiffEntry{Change: DiffAdded, Kind: fact.kind, Host: fact.host, Key: fact.key, After: fact.value})
+			diff.Added++
+		case previous.value != fact.value:
+			diff.Entries = append(diff.Entries, DiffEntry{Change: DiffChanged, Kind: fact.kind, Host: fact.host, Key: fact.key, Before: previous.value, After: fact.value})
+			diff.Changed++
+		default:
+			diff.Unchanged[fact.kind]++
+		}
+	}
+	for id, fact := range before {
+		if _, exists := after[id]; !exists {
+			diff.Entries = append(diff.Entries, DiffEntry{Change: DiffRemoved, Kind: fact.kind, Host: fact.host, Key: fact.key, Before: fact.value})
+			diff.Removed++
+		}
+	}
+
+	sortDiffEntries(diff.Entries)
+	diff.NewHosts = newHosts(baseline.Summary, current.Summary)
+	return diff
+}
+
+// NewPorts returns the ports open in the current run only
+func (d *Diff) NewPorts() []DiffEntry {
+	var added []DiffEntry
+	for _, entry := range d.Entries {
+		if entry.Change == DiffAdded && entry.Kind == DiffKindPort {
+			added = append(added, entry)
+		}
+	}
+	return added
+}
+
+// newHosts returns the hosts of current that baseline does not have
+func newHosts(baseline, current *TargetSummary) []string {
+	if current == nil {
+		return nil
+	}
+	known := make(map[string]bool)
+	if baseline != nil {
+		for _, host := range baseline.Hosts {
+			known[host.Host] = true
+		}
+	}
+	var hosts []string
+	for _, host := range current.Hosts {
+		if !known[host.Host] {
+			hosts = append(hosts, host.Host)
+		}
+	}
+	return hosts
+}
+
+// Filter keeps only the entries of the given kinds and recounts the totals
+func (d *Diff) Filter(kinds []string) {
+	keep := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		keep[kind] = true
+	}
+	entries := make([]DiffEntry, 0, len(d.Entries))
+	d.Added, d.Removed, d.Changed = 0, 0, 0
+	for _, entry := range d.Entries {
+		if !keep[entry.Kind] {
+			continue
+		}
+		entries = append(entries, entry)
+		switch entry.Change {
+		case DiffAdded:
+			d.Added++
+		case DiffRemoved:
+			d.Removed++
+		case DiffChanged:
+			d.Changed++
+		}
+	}
+	d.Entries = entries
+	for kind := range d.Unchanged {
+		if !keep[kind] {
+			delete(d.Unchanged, kind)
+		}
+	}
+}
+
+func diffRunInfo(summary *TargetSummary) DiffRunInfo {
+	if summary == nil {
+		return DiffRunInfo{}
+	}
+	return DiffRunInfo{Workspace: summary.Workspace, Target: summary.Target, ScanID: summary.ScanID, StartedAt: summary.StartedAt}
+}
+
+// diffFacts flattens a run's results into comparable facts keyed by their identity
+func diffFacts(run DiffRun) map[string]diffFact {
+	facts := make(map[string]diffFact)
+	add := func(fact diffFact) {
+		facts[fact.kind+"\x00"+fact.host+"\x00"+fact.key] = fact
+	}
+
+	target := ""
+	if run.Summary != nil {
+		target = run.Summary.Target
+		// Open ports; the service, banner and TLS are what can change
+		for _, host := range run.Summary.Hosts {
+			for _, port := range host.OpenPorts {
+				service := strings.TrimSpace(port.Service + " " + port.Banner())
+				if port.TLS {
+					service = strings.TrimSpace(service + " [tls]")
+				}
+				add(diffFact{kind: DiffKindPort, host: host.Host, key: port.Key(), value: service})
+			}
+		}
+		// Probed web URLs; the status and title are what can change
+		for _, f := range run.Summary.Findings {
+			if f.URL == "" {
+				continue
+			}
+			value := ""
+			if f.StatusCode > 0 {
+				value = strconv.Itoa(f.StatusCode)
+			}
+			if f.Title != "" {
+				value = strings.TrimSpace(fmt.Sprintf("%s %q", value, f.Title))
+			}
+			add(diffFact{kind: DiffKindURL, host: f.Host, key: f.URL, value: value})
+		}
+	}
+
+	// DNS records grouped by type and name; the answered values are what can change
+	values := make(map[string][]string)
+	for _, record := range run.DNS {
+		key := record.Type + " " + record.Name
+		values[key] = mergeStrings(values[key], []string{record.Value})
+	}
+	for key, list := range values {
+		add(diffFact{kind: DiffKindDNS, key: key, value: strings.Join(list, ", ")})
+	}
+
+	// Subdomains of the target; the addresses they resolved to are what can change
+	for _, record := range subdomains.Merge(run.Subdomains) {
+		if !subdomains.IsHostname(record.Name) || !subdomains.IsSubdomain(record.Name, target) {
+			continue
+		}
+		add(diffFact{kind: DiffKindSubdomain, key: record.Name, value: strings.Join(mergeStrings(nil, record.Addresses), ", ")})
+	}
+	return facts
+}
+
+// sortDiffEntries orders entries by kind, host and key, then removed, changed, added
+func sortDiffEntries(entries []DiffEntry) {
+	kindOrder := make(map[string]int, len(DiffKinds))
+	for i, kind := range DiffKinds {
+		kindOrder[kind] = i
+	}
+	changeOrder := map[string]int{DiffRemoved: 0, DiffChanged: 1, DiffAdded: 2}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Kind != b.Kind {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Kind == DiffKindPort {
+			if portA, portB := portNumber(a.Key), portNumber(b.Key); portA != portB {
+				return portA < portB
+			}
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return changeOrder[a.Change] < changeOrder[b.Change]
+	})
+}
+
+// portNumber returns the port of a "443/tcp" key
+func portNumber(key string) int {
+	number, _, _ := strings.Cut(key, "/")
+	port, _ := strconv.Atoi(number)
+	return port
+}
@@ -0,0 +1,193 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Results of checking a run against an exposure baseline
+const (
+	ExposureUnexpected = "unexpected" // Open, but not in the baseline
+	ExposureChanged    = "changed"    // In the baseline, but running another service
+	ExposureMissing    = "missing"    // In the baseline, but not found open
+)
+
+// ExposureBaseline is the approved list of ports a target may expose, kept in version
+// control next to the infrastructure it describes and checked by `ipcrawler verify`
+type ExposureBaseline struct {
+	Target   string         `json:"target,omitempty"` // Informational
+	Approved time.Time      `json:"approved,omitempty"`
+	Ports    []ExpectedPort `json:"ports"`
+}
+
+// ExpectedPort is one approved open port
+type ExpectedPort struct {
+	Host     string `json:"host,omitempty"`     // Any host of the target when empty
+	Port     string `json:"port"`               // "443/tcp"; a bare number is TCP
+	Service  string `json:"service,omitempty"`  // Service name or pattern ("http*"); any when empty
+	Optional bool   `json:"optional,omitempty"` // Not reported as missing when closed
+}
+
+// ExposureCheck is the result of checking a run's open ports against a baseline
+type ExposureCheck struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Target      string          `json:"target,omitempty"`
+	Workspace   string          `json:"workspace,omitempty"`
+	Entries     []ExposureEntry `json:"entries"`
+	Matched     int             `json:"matched"`
+	Unexpected  int             `json:"unexpected"`
+	Changed     int             `json:"changed"`
+	Missing     int             `json:"missing"`
+}
+
+// ExposureEntry is one port that differs from the baseline
+type ExposureEntry struct {
+	Result   string `json:"result"`
+	Host     string `json:"host,omitempty"`
+	Port     string `json:"port"`
+	Expected string `json:"expected,omitempty"` // Service the baseline allows
+	Found    string `json:"found,omitempty"`    // Service found open, with its banner
+}
+
+// LoadExposureBaseline reads and checks a baseline file
+func LoadExposureBaseline(file string) (*ExposureBaseline, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var baseline ExposureBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %v", file, err)
+	}
+	for i := range baseline.Ports {
+		expected := &baseline.Ports[i]
+		key, err := normalizePortKey(expected.Port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid baseline %s: ports[%d]: %v", file, i, err)
+		}
+		expected.Port = key
+		if _, err := path.Match(strings.ToLower(expected.Service), ""); err != nil {
+			return nil, fmt.Errorf("invalid baseline %s: ports[%d]: invalid service pattern %q", file, i, expected.Service)
+		}
+	}
+	return &baseline, nil
+}
+
+// ExposureBaselineFrom approves everything a run found open: each host's ports with the
+// service identified on them
+func ExposureBaselineFrom(summary *TargetSummary) *ExposureBaseline {
+	baseline := &ExposureBaseline{Target: summary.Target, Approved: time.Now().UTC().Truncate(time.Second), Ports: make([]ExpectedPort, 0)}
+	for _, host := range summary.Hosts {
+		for _, port := range host.OpenPorts {
+			baseline.Ports = append(baseline.Ports, ExpectedPort{Host: host.Host, Port: port.Key(), Service: port.Service})
+		}
+	}
+	return baseline
+}
+
+// Write saves the baseline as indented JSON, ready to commit
+func (b *ExposureBaseline) Write(file string, perm os.FileMode) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), perm)
+}
+
+// CheckExposure compares the open ports of a run with a baseline. Ports the baseline does not
+// allow are unexpected; a port running a service its entries do not allow has changed (ports
+// whose service was not identified are not compared); approved ports not found are missing
+func CheckExposure(baseline *ExposureBaseline, summary *TargetSummary) *ExposureCheck {
+	check := &ExposureCheck{
+		GeneratedAt: time.Now(),
+		Target:      summary.Target,
+		Workspace:   summary.Workspace,
+		Entries:     make([]ExposureEntry, 0),
+	}
+	found := make(map[int]bool) // Baseline entries matched by an open port
+
+	for _, host := range summary.Hosts {
+		for _, port := range host.OpenPorts {
+			key := port.Key()
+			description := strings.TrimSpace(port.Service + " " + port.Banner())
+			var allowed []string
+			matched := false
+			for i, expected := range baseline.Ports {
+				if expected.Port != key || (expected.Host != "" && expected.Host != host.Host) {
+					continue
+				}
+				found[i] = true
+				if expected.Service == "" || port.Service == "" || serviceMatches(expected.Service, port.Service) {
+					matched = true
+				} else {
+					allowed = append(allowed, expected.Service)
+				}
+			}
+			switch {
+			case matched:
+				check.Matched++
+			case len(allowed) > 0:
+				check.Entries = append(check.Entries, ExposureEntry{Result: ExposureChanged, Host: host.Host, Port: key, Expected: strings.Join(allowed, " or "), Found: description})
+				check.Changed++
+			default:
+				check.Entries = append(check.Entries, ExposureEntry{Result: ExposureUnexpected, Host: host.Host, Port: key, Found: description})
+				check.Unexpected++
+			}
+		}
+	}
+
+	for i, expected := range baseline.Ports {
+		if found[i] || expected.Optional {
+			continue
+		}
+		check.Entries = append(check.Entries, ExposureEntry{Result: ExposureMissing, Host: expected.Host, Port: expected.Port, Expected: expected.Service})
+		check.Missing++
+	}
+
+	order := map[string]int{ExposureUnexpected: 0, ExposureChanged: 1, ExposureMissing: 2}
+	sort.SliceStable(check.Entries, func(i, j int) bool {
+		a, b := check.Entries[i], check.Entries[j]
+		if a.Result != b.Result {
+			return order[a.Result] < order[b.Result]
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		return portNumber(a.Port) < portNumber(b.Port)
+	})
+	return check
+}
+
+// Failed reports whether the run exposes anything the baseline does not allow; with strict,
+// approved ports that were not found fail it too
+func (c *ExposureCheck) Failed(strict bool) bool {
+	return c.Unexpected > 0 || c.Changed > 0 || (strict && c.Missing > 0)
+}
+
+// serviceMatches compares a service with a baseline pattern, ignoring case
+func serviceMatches(pattern, service string) bool {
+	matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(service))
+	return matched
+}
+
+// normalizePortKey turns "443" and "443/TCP" into "443/tcp"
+func normalizePortKey(key string) (string, error) {
+	number, protocol, found := strings.Cut(strings.ToLower(strings.TrimSpace(key)), "/")
+	if !found {
+		protocol = "tcp"
+	}
+	port, err := strconv.Atoi(number)
+	if err != nil || port < 1 || port > 65535 {
+		return "", fmt.Errorf("invalid port %q", key)
+	}
+	if protocol != "tcp" && protocol != "udp" {
+		return "", fmt.Errorf("invalid protocol in port %q (tcp or udp)", key)
+	}
+	return fmt.Sprintf("%d/%s", port, protocol), nil
+}
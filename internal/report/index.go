@@ -0,0 +1,230 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IndexFileName is the generated overview at the top of every workspace
+const IndexFileName = "INDEX.md"
+
+// IndexWorkflow is one workflow's row in the workspace index
+type IndexWorkflow struct {
+	Name      string
+	Status    string
+	OpenPorts int
+	URLs      int
+}
+
+// indexSections describes the workspace directories, in the order the index lists them
+var indexSections = []struct {
+	dir         string
+	title       string
+	description string
+}{
+	{"reports", "Reports (reports/)", "rendered when the run ends"},
+	{"scans", "Scan outputs (scans/)", "raw tool output, one file per tool run"},
+	{"summaries", "Workflow summaries (summaries/)", "key results of each finished workflow"},
+	{"logs", "Logs (logs/)", "events.jsonl holds every workflow and step event"},
+	{"raw", "Raw tool output (raw/)", "stdout and stderr of every tool"},
+	{"", "Run metadata", "how the run was started and what it queued"},
+}
+
+// WriteIndex writes the workspace's INDEX.md: what was found, every artifact with the number
+// of findings it produced, and commands to start digging. It is rewritten after each workflow
+func WriteIndex(summary *TargetSummary, workflows []IndexWorkflow, perm os.FileMode) (string, error) {
+	path := filepath.Join(summary.Workspace, IndexFileName)
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create workspace index: %w", err)
+	}
+	err = RenderIndex(file, summary, workflows)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write workspace index: %w", err)
+	}
+	return path, os.Rename(tmpPath, path)
+}
+
+// RenderIndex renders the workspace index as Markdown
+func RenderIndex(w io.Writer, summary *TargetSummary, workflows []IndexWorkflow) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# %s\n\n", summary.Target)
+	var facts []string
+	if summary.Session != "" {
+		facts = append(facts, "Session `"+summary.Session+"`")
+	}
+	if summary.ScanID != "" {
+		facts = append(facts, "Scan `"+summary.ScanID+"`")
+	}
+	if summary.Status != "" {
+		facts = append(facts, summary.Status)
+	}
+	if !summary.StartedAt.IsZero() {
+		facts = append(facts, "started "+formatTime(summary.StartedAt))
+	}
+	if len(summary.Labels) > 0 {
+		facts = append(facts, "labels: "+strings.Join(summary.Labels, ", "))
+	}
+	if len(facts) > 0 {
+		fmt.Fprintf(out, "%s\n\n", strings.Join(facts, " · "))
+	}
+	fmt.Fprintf(out, "_Generated by IPCrawler at %s; rewritten after each workflow._\n\n", formatTime(time.Now()))
+
+	writeIndexCounts(out, summary)
+	writeIndexWorkflows(out, workflows)
+	if err := writeIndexArtifacts(out, summary); err != nil {
+		return err
+	}
+	writeIndexQuickStart(out)
+	return out.Flush()
+}
+
+func writeIndexCounts(out *bufio.Writer, s *TargetSummary) {
+	var webServices, scripts int
+	for _, host := range s.Hosts {
+		scripts += len(host.Scripts)
+		for _, port := range host.OpenPorts {
+			if port.URL != "" {
+				webServices++
+			}
+			scripts += len(port.Scripts)
+		}
+	}
+
+	fmt.Fprintln(out, "## Findings")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "| Section | Count |")
+	fmt.Fprintln(out, "|---------|-------|")
+	fmt.Fprintf(out, "| Hosts | %d |\n", len(s.Hosts))
+	fmt.Fprintf(out, "| Open ports | %d |\n", s.OpenPortCount())
+	fmt.Fprintf(out, "| Web services | %d |\n", webServices)
+	fmt.Fprintf(out, "| Script results | %d |\n", scripts)
+	fmt.Fprintf(out, "| Warnings | %d |\n", len(s.Warnings))
+	fmt.Fprintln(out)
+}
+
+func writeIndexWorkflows(out *bufio.Writer, workflows []IndexWorkflow) {
+	if len(workflows) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "## Workflows")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "| Workflow | Status | Open ports | URLs |")
+	fmt.Fprintln(out, "|----------|--------|------------|------|")
+	for _, workflow := range workflows {
+		fmt.Fprintf(out, "| %s | %s | %d | %d |\n", cell(workflow.Name), dashIfEmpty(workflow.Status), workflow.OpenPorts, workflow.URLs)
+	}
+	fmt.Fprintln(out)
+}
+
+// writeIndexArtifacts lists every file of the workspace by directory, with the findings each
+// scan output produced
+func writeIndexArtifacts(out *bufio.Writer, s *TargetSummary) error {
+	perSource := make(map[string]int)
+	for _, finding := range s.Findings {
+		perSource[filepath.ToSlash(finding.Source)]++
+	}
+
+	files := make(map[string][]string) // Keyed by top-level directory, "" for the workspace root
+	err := filepath.WalkDir(s.Workspace, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil // Unreadable entries are left out rather than failing the index
+		}
+		rel, err := filepath.Rel(s.Workspace, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == IndexFileName || strings.HasSuffix(rel, ".tmp") {
+			return nil
+		}
+		dir, _, nested := strings.Cut(rel, "/")
+		if !nested {
+			dir = ""
+		}
+		files[dir] = append(files[dir], rel)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list workspace files: %w", err)
+	}
+
+	fmt.Fprintln(out, "## Artifacts")
+	fmt.Fprintln(out)
+	listed := make(map[string]bool)
+	for _, section := range indexSections {
+		listed[section.dir] = true
+		writeIndexSection(out, section.title, section.description, files[section.dir], perSource)
+	}
+	var others []string
+	for dir := range files {
+		if !listed[dir] {
+			others = append(others, dir)
+		}
+	}
+	sort.Strings(others)
+	for _, dir := range others {
+		writeIndexSection(out, dir+"/", "", files[dir], perSource)
+	}
+	return nil
+}
+
+func writeIndexSection(out *bufio.Writer, title, description string, files []string, perSource map[string]int) {
+	if len(files) == 0 {
+		return
+	}
+	sort.Strings(files)
+	if description != "" {
+		fmt.Fprintf(out, "### %s\n\n_%s_\n\n", title, description)
+	} else {
+		fmt.Fprintf(out, "### %s\n\n", title)
+	}
+	for _, file := range files {
+		name := file
+		if _, rest, nested := strings.Cut(file, "/"); nested {
+			name = rest // The section title already names the directory
+		}
+		if count := perSource[file]; count > 0 {
+			fmt.Fprintf(out, "- [%s](%s) — %d findings\n", name, linkPath(file), count)
+		} else {
+			fmt.Fprintf(out, "- [%s](%s)\n", name, linkPath(file))
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+func writeIndexQuickStart(out *bufio.Writer) {
+	fmt.Fprintln(out, "## Quick start")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "```sh")
+	fmt.Fprintln(out, "# Open ports with their services (report.json is written when the run ends)")
+	fmt.Fprintln(out, `jq -r '.ports[] | "\(.host):\(.port)/\(.protocol) \(.service) \(.product)"' reports/report.json`)
+	fmt.Fprintln(out, "# Open ports straight from the scan outputs")
+	fmt.Fprintln(out, `grep -ho 'portid="[0-9]*"><state state="open"' scans/*.xml | sort -u`)
+	fmt.Fprintln(out, `grep -ho '"port":[0-9]*' scans/*.json | sort -u`)
+	fmt.Fprintln(out, "# Web services found by HTTP probes")
+	fmt.Fprintln(out, `grep -ho '"url":"[^"]*"' scans/*.json | sort -u`)
+	fmt.Fprintln(out, "# What failed or was skipped")
+	fmt.Fprintln(out, `grep -E '"type":"(step|workflow)_(failed|skipped)"' logs/events.jsonl`)
+	fmt.Fprintln(out, "# Regenerate the reports after editing findings or config")
+	fmt.Fprintln(out, "ipcrawler report .")
+	fmt.Fprintln(out, "```")
+}
+
+// linkPath escapes the characters Markdown links do not take in a relative path
+func linkPath(path string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(path)
+}
@@ -0,0 +1,410 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+func init() {
+	RegisterRenderer(&MarkdownRenderer{})
+}
+
+// MarkdownRenderer writes reports as Markdown for terminals and writeups
+type MarkdownRenderer struct{}
+
+// Format returns the format name used in configuration
+func (r *MarkdownRenderer) Format() string {
+	return "markdown"
+}
+
+// Extension returns the report file extension
+func (r *MarkdownRenderer) Extension() string {
+	return ".md"
+}
+
+// chartWidth is the width of the longest bar in text charts
+const chartWidth = 30
+
+// RenderTarget writes a single target's report
+func (r *MarkdownRenderer) RenderTarget(w io.Writer, s *TargetSummary) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# IPCrawler Report: %s\n\n", s.Target)
+	writeTargetOverview(out, s)
+
+	if len(s.Hosts) == 0 {
+		fmt.Fprintf(out, "No hosts or open ports were found.\n")
+	}
+	for _, host := range s.Hosts {
+		writeHostSection(out, host)
+	}
+	writeReviewHints(out, s)
+	writeMethodology(out, s.Run)
+	writeScanQuality(out, s)
+
+	return out.Flush()
+}
+
+// RenderRollup writes the engagement-level roll-up
+func (r *MarkdownRenderer) RenderRollup(w io.Writer, rollup *Rollup) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# IPCrawler Engagement Roll-up\n\n")
+	fmt.Fprintf(out, "- **Generated:** %s\n", rollup.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(out, "- **Targets:** %d\n", len(rollup.Targets))
+	fmt.Fprintf(out, "- **Hosts:** %d\n", rollup.HostCount)
+	fmt.Fprintf(out, "- **Open ports:** %d\n\n", rollup.OpenPorts)
+
+	fmt.Fprintf(out, "## Targets\n\n")
+	fmt.Fprintf(out, "| Target | Status | Hosts | Open ports | Labels | Workspace |\n")
+	fmt.Fprintf(out, "|---|---|---|---|---|---|\n")
+	for _, target := range rollup.Targets {
+		fmt.Fprintf(out, "| %s | %s | %d | %d | %s | `%s` |\n",
+			cell(target.Target), cell(target.Status), len(target.Hosts), target.OpenPortCount(),
+			cell(strings.Join(target.Labels, ", ")), target.Workspace)
+	}
+	fmt.Fprintln(out)
+
+	if len(rollup.TopPorts) > 0 {
+		fmt.Fprintf(out, "## Top Exposed Ports\n\n")
+		fmt.Fprintf(out, "| Port | Hosts | Affected hosts |\n")
+		fmt.Fprintf(out, "|---|---|---|\n")
+		for _, exposure := range rollup.TopPorts {
+			fmt.Fprintf(out, "| %s | %d | %s |\n", exposure.Name, exposure.Count, cell(abbreviateList(exposure.Hosts, 5)))
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(rollup.Services) > 0 {
+		fmt.Fprintf(out, "## Service Frequency\n\n")
+		writeBarChart(out, rollup.Services)
+	}
+
+	if len(rollup.BusiestHosts) > 0 {
+		fmt.Fprintf(out, "## Hosts by Open Ports\n\n")
+		fmt.Fprintf(out, "| Host | Target | Open ports | Labels |\n")
+		fmt.Fprintf(out, "|---|---|---|---|\n")
+		for _, host := range rollup.BusiestHosts {
+			fmt.Fprintf(out, "| %s | %s | %d | %s |\n", host.Host, cell(host.Target), host.OpenPorts, cell(strings.Join(host.Labels, ", ")))
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(rollup.Matrix.Ports) > 0 {
+		fmt.Fprintf(out, "## Host Matrix\n\n")
+		fmt.Fprintf(out, "| Host | %s |\n", strings.Join(rollup.Matrix.Ports, " | "))
+		fmt.Fprintf(out, "|---|%s\n", strings.Repeat("---|", len(rollup.Matrix.Ports)))
+		for _, row := range rollup.Matrix.Rows {
+			marks := make([]string, len(row.Open))
+			for i, open := range row.Open {
+				if open {
+					marks[i] = "x"
+				} else {
+					marks[i] = " "
+				}
+			}
+			fmt.Fprintf(out, "| %s | %s |\n", row.Host, strings.Join(marks, " | "))
+		}
+		fmt.Fprintln(out)
+	}
+
+	return out.Flush()
+}
+
+// RenderComparison writes a retest report of fixed, unchanged and new findings per host
+func (r *MarkdownRenderer) RenderComparison(w io.Writer, c *Comparison) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# IPCrawler Retest Report: %s\n\n", c.Current.Target)
+	fmt.Fprintf(out, "| | Baseline | Retest |\n")
+	fmt.Fprintf(out, "|---|---|---|\n")
+	fmt.Fprintf(out, "| Scan ID | %s | %s |\n", cell(c.Baseline.ScanID), cell(c.Current.ScanID))
+	fmt.Fprintf(out, "| Started | %s | %s |\n", formatTime(c.Baseline.StartedAt), formatTime(c.Current.StartedAt))
+	fmt.Fprintf(out, "| Open ports | %d | %d |\n\n", c.Baseline.OpenPortCount(), c.Current.OpenPortCount())
+
+	fmt.Fprintf(out, "- **Fixed:** %d\n", c.Fixed)
+	fmt.Fprintf(out, "- **Unchanged:** %d\n", c.Unchanged)
+	fmt.Fprintf(out, "- **New:** %d\n\n", c.New)
+
+	if len(c.Hosts) == 0 {
+		fmt.Fprintf(out, "No open ports in either run.\n")
+	}
+	for _, host := range c.Hosts {
+		fmt.Fprintf(out, "## %s\n\n", host.Host)
+		if len(host.Labels) > 0 {
+			fmt.Fprintf(out, "Labels: %s\n\n", strings.Join(host.Labels, ", "))
+		}
+		fmt.Fprintf(out, "| Status | Port | Service | Product | Notes |\n")
+		fmt.Fprintf(out, "|---|---|---|---|---|\n")
+		for _, result := range host.Results {
+			notes := ""
+			if result.BaselineBanner != "" {
+				notes = "was: " + result.BaselineBanner
+			}
+			fmt.Fprintf(out, "| %s | %s | %s | %s | %s |\n", strings.ToUpper(result.Status), result.Port.Key(),
+				cell(result.Port.Service), cell(result.Port.Banner()), cell(notes))
+		}
+		fmt.Fprintln(out)
+	}
+
+	return out.Flush()
+}
+
+func writeTargetOverview(out *bufio.Writer, s *TargetSummary) {
+	if s.Session != "" {
+		fmt.Fprintf(out, "- **Session:** %s\n", s.Session)
+	}
+	if s.ScanID != "" {
+		fmt.Fprintf(out, "- **Scan ID:** %s\n", s.ScanID)
+	}
+	if s.Status != "" {
+		fmt.Fprintf(out, "- **Status:** %s\n", s.Status)
+	}
+	if !s.StartedAt.IsZero() {
+		fmt.Fprintf(out, "- **Started:** %s\n", s.StartedAt.Format(time.RFC3339))
+	}
+	if started := output.TargetLocalTime(s.StartedAt, s.Timezone); started != "" {
+		fmt.Fprintf(out, "- **Started (target local):** %s (%s)\n", started, s.Timezone)
+	}
+	if s.DurationSeconds > 0 {
+		fmt.Fprintf(out, "- **Duration:** %s\n", time.Duration(s.DurationSeconds*float64(time.Second)).Round(time.Second))
+	}
+	if len(s.Labels) > 0 {
+		fmt.Fprintf(out, "- **Labels:** %s\n", strings.Join(s.Labels, ", "))
+	}
+	fmt.Fprintf(out, "- **Hosts:** %d\n", len(s.Hosts))
+	fmt.Fprintf(out, "- **Open ports:** %d\n\n", s.OpenPortCount())
+}
+
+func writeHostSection(out *bufio.Writer, host HostSummary) {
+	fmt.Fprintf(out, "## %s\n\n", host.Host)
+	if len(host.Labels) > 0 {
+		fmt.Fprintf(out, "Labels: %s\n\n", strings.Join(host.Labels, ", "))
+	}
+	if len(host.Hostnames) > 0 {
+		fmt.Fprintf(out, "Hostnames: %s\n\n", strings.Join(host.Hostnames, ", "))
+	}
+	if host.OS != "" {
+		fmt.Fprintf(out, "OS: %s\n\n", host.OS)
+	}
+	if len(host.OpenPorts) == 0 {
+		fmt.Fprintf(out, "No open ports found.\n\n")
+		writeScriptResults(out, host)
+		return
+	}
+
+	fmt.Fprintf(out, "| Port | Service | Product | Tools |\n")
+	fmt.Fprintf(out, "|---|---|---|---|\n")
+	for _, port := range host.OpenPorts {
+		service := port.Service
+		if port.TLS {
+			service = strings.TrimSpace(service + " (tls)")
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s |\n", port.Key(), cell(service), cell(port.Banner()), strings.Join(port.Tools, ", "))
+	}
+	fmt.Fprintln(out)
+	writeWebServices(out, host)
+	writeScriptResults(out, host)
+}
+
+// writeWebServices lists the URLs HTTP probes found on the host's open ports
+func writeWebServices(out *bufio.Writer, host HostSummary) {
+	var web []PortSummary
+	for _, port := range host.OpenPorts {
+		if port.URL != "" {
+			web = append(web, port)
+		}
+	}
+	if len(web) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "### Web Services\n\n")
+	fmt.Fprintf(out, "| URL | Status | Title | Technologies |\n")
+	fmt.Fprintf(out, "|---|---|---|---|\n")
+	for _, port := range web {
+		status := ""
+		if port.StatusCode > 0 {
+			status = strconv.Itoa(port.StatusCode)
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s |\n", cell(port.URL), cell(status), cell(port.Title), cell(strings.Join(port.Technologies, ", ")))
+	}
+	fmt.Fprintln(out)
+}
+
+// writeScriptResults prints the host's script output (e.g. nmap NSE), host-level first
+func writeScriptResults(out *bufio.Writer, host HostSummary) {
+	count := len(host.Scripts)
+	for _, port := range host.OpenPorts {
+		count += len(port.Scripts)
+	}
+	if count == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "### Script Results\n\n")
+	for _, script := range host.Scripts {
+		fmt.Fprintf(out, "**%s** (host)\n\n```\n%s\n```\n\n", script.ID, script.Output)
+	}
+	for _, port := range host.OpenPorts {
+		for _, script := range port.Scripts {
+			fmt.Fprintf(out, "**%s** (%s)\n\n```\n%s\n```\n\n", script.ID, port.Key(), script.Output)
+		}
+	}
+}
+
+// writeReviewHints lists the heuristics' hints; they point at things to check by hand
+func writeReviewHints(out *bufio.Writer, s *TargetSummary) {
+	if len(s.Anomalies) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "## Review Hints\n\n")
+	fmt.Fprintf(out, "Informational: patterns worth a manual look, not vulnerabilities.\n\n")
+	fmt.Fprintf(out, "| Location | Kind | Hint | Evidence | Tools |\n")
+	fmt.Fprintf(out, "|---|---|---|---|---|\n")
+	for _, anomaly := range s.Anomalies {
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s |\n", cell(anomaly.Location()), anomaly.Kind, cell(anomaly.Message),
+			cell(dashIfEmpty(anomaly.Evidence)), cell(dashIfEmpty(strings.Join(anomaly.Tools, ", "))))
+	}
+	fmt.Fprintln(out)
+}
+
+// writeScanQuality lists what made the scan imperfect, apart from whether it failed
+func writeScanQuality(out *bufio.Writer, s *TargetSummary) {
+	var warnings []output.RunWarning
+	if s.Run != nil {
+		warnings = s.Run.Warnings
+	}
+	if len(warnings) == 0 && len(s.Warnings) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "## Scan Quality\n\n")
+	if len(warnings) > 0 {
+		fmt.Fprintf(out, "The scan ran with %d warning(s): its results are less complete than configured", len(warnings))
+		if s.Status != "" {
+			fmt.Fprintf(out, " (run status: %s)", s.Status)
+		}
+		fmt.Fprintf(out, ".\n\n")
+		fmt.Fprintf(out, "| Kind | Tool | Mode | Warning |\n")
+		fmt.Fprintf(out, "|---|---|---|---|\n")
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "| %s | %s | %s | %s |\n", warning.Kind, cell(dashIfEmpty(warning.Tool)), cell(dashIfEmpty(warning.Mode)), cell(warning.Message))
+		}
+		fmt.Fprintln(out)
+	}
+	if len(s.Warnings) > 0 {
+		fmt.Fprintf(out, "Scan outputs this report could not read:\n\n")
+		for _, warning := range s.Warnings {
+			fmt.Fprintf(out, "- %s\n", warning)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// writeMethodology lists every tool execution of the run, noting modes that were substituted
+// (a privileged mode run unprivileged, or a fallback after a permission failure)
+func writeMethodology(out *bufio.Writer, run *output.RunReport) {
+	if run == nil || len(run.Workflows) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "## Methodology\n\n")
+	fmt.Fprintf(out, "| Workflow | Step | Tool | Mode | Result |\n")
+	fmt.Fprintf(out, "|---|---|---|---|---|\n")
+	var substitutions []string
+	for _, workflow := range run.Workflows {
+		for _, step := range workflow.Steps {
+			if step.Skipped {
+				fmt.Fprintf(out, "| %s | %s | %s | - | skipped: %s |\n", cell(workflow.Name), cell(step.Name), cell(step.Tool), cell(step.SkipReason))
+				continue
+			}
+			for _, execution := range step.Executions {
+				mode := execution.Mode
+				if execution.FallbackFor != "" {
+					mode = fmt.Sprintf("%s (instead of %s)", execution.Mode, execution.FallbackFor)
+					substitutions = append(substitutions, fmt.Sprintf("%s `%s` ran as `%s`: %s",
+						execution.Tool, execution.FallbackFor, execution.Mode, dashIfEmpty(execution.FallbackReason)))
+				}
+				result := "success"
+				if !execution.Success {
+					result = "failed"
+				} else if execution.Warning != "" {
+					result = "success (warning)"
+				}
+				fmt.Fprintf(out, "| %s | %s | %s | %s | %s |\n", cell(workflow.Name), cell(step.Name), cell(execution.Tool), cell(mode), result)
+			}
+		}
+	}
+	fmt.Fprintln(out)
+
+	if len(substitutions) > 0 {
+		fmt.Fprintf(out, "Coverage differs from the workflow definitions where modes were substituted:\n\n")
+		for _, substitution := range substitutions {
+			fmt.Fprintf(out, "- %s\n", substitution)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// dashIfEmpty returns "-" for an empty value
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// writeBarChart draws a horizontal text bar chart of exposure counts
+func writeBarChart(out *bufio.Writer, exposures []Exposure) {
+	maxCount, maxName := 0, 0
+	for _, exposure := range exposures {
+		if exposure.Count > maxCount {
+			maxCount = exposure.Count
+		}
+		if len(exposure.Name) > maxName {
+			maxName = len(exposure.Name)
+		}
+	}
+
+	fmt.Fprintf(out, "```\n")
+	for _, exposure := range exposures {
+		width := exposure.Count * chartWidth / maxCount
+		if width == 0 {
+			width = 1
+		}
+		fmt.Fprintf(out, "%-*s %s %d\n", maxName, exposure.Name, strings.Repeat("#", width), exposure.Count)
+	}
+	fmt.Fprintf(out, "```\n\n")
+}
+
+// formatTime formats a timestamp, or "-" when unknown
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// cell escapes a value for a Markdown table cell
+func cell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+// abbreviateList joins up to limit items and notes how many were omitted
+func abbreviateList(items []string, limit int) string {
+	if len(items) <= limit {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(items[:limit], ", "), len(items)-limit)
+}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// Renderer writes reports in one output format
+type Renderer interface {
+	Format() string
+	Extension() string
+	RenderTarget(w io.Writer, summary *TargetSummary) error
+	RenderRollup(w io.Writer, rollup *Rollup) error
+	RenderComparison(w io.Writer, comparison *Comparison) error
+}
+
+// Base names of generated report files (the extension comes from the renderer)
+const (
+	TargetReportName = "report"
+	RollupReportName = "engagement_rollup"
+	RetestReportName = "retest"
+)
+
+var renderers = map[string]Renderer{}
+
+// RegisterRenderer makes a report format available by name
+func RegisterRenderer(renderer Renderer) {
+	renderers[strings.ToLower(renderer.Format())] = renderer
+}
+
+// Formats returns the registered format names
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rendererFor looks up a renderer by format name
+func rendererFor(format string) (Renderer, error) {
+	renderer, exists := renderers[strings.ToLower(strings.TrimSpace(format))]
+	if !exists {
+		return nil, fmt.Errorf("unknown report format '%s' (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return renderer, nil
+}
+
+// ValidateFormats checks that every requested format is registered
+func ValidateFormats(formats []string) error {
+	for _, format := range formats {
+		if _, err := rendererFor(format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadTarget builds a target summary from a workspace's manifest and scan outputs
+func LoadTarget(workspaceDir string, catalog *findings.Catalog, labelsFor func(host string) []string) (*TargetSummary, error) {
+	manifest, err := session.LoadManifest(workspaceDir)
+	if err != nil {
+		// Older workspaces have no manifest; fall back to the directory name as target
+		manifest = &session.RunManifest{Target: filepath.Base(workspaceDir)}
+	}
+
+	list, warnings, err := catalog.LoadWorkspace(workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+	findings.ApplyLabels(list, labelsFor, manifest.Labels)
+
+	summary := BuildTargetSummary(manifest, workspaceDir, list)
+	summary.Warnings = warnings
+	if run, err := output.LoadRunReport(workspaceDir); err == nil {
+		summary.Run = run
+	}
+	return summary, nil
+}
+
+// WriteTargetReports renders the target report in every format into the workspace reports
+// directory, prefixing the file names with the run's session name when it has one
+func WriteTargetReports(summary *TargetSummary, formats []string, perm os.FileMode) ([]string, error) {
+	reportsDir := filepath.Join(summary.Workspace, "reports")
+	return writeReports(reportsDir, session.SessionFileName(summary.Session, TargetReportName), formats, perm, func(renderer Renderer, w io.Writer) error {
+		return renderer.RenderTarget(w, summary)
+	})
+}
+
+// WriteRollupReports renders the engagement roll-up in every format into dir
+func WriteRollupReports(rollup *Rollup, dir string, formats []string, perm os.FileMode) ([]string, error) {
+	return writeReports(dir, RollupReportName, formats, perm, func(renderer Renderer, w io.Writer) error {
+		return renderer.RenderRollup(w, rollup)
+	})
+}
+
+// WriteComparisonReports renders the retest comparison into the current run's reports directory
+func WriteComparisonReports(comparison *Comparison, formats []string, perm os.FileMode) ([]string, error) {
+	reportsDir := filepath.Join(comparison.Current.Workspace, "reports")
+	return writeReports(reportsDir, RetestReportName, formats, perm, func(renderer Renderer, w io.Writer) error {
+		return renderer.RenderComparison(w, comparison)
+	})
+}
+
+// writeReports renders one file per format and returns the written paths
+func writeReports(dir, baseName string, formats []string, perm os.FileMode, render func(Renderer, io.Writer) error) ([]string, error) {
+	var paths []string
+	for _, format := range formats {
+		renderer, err := rendererFor(format)
+		if err != nil {
+			return paths, err
+		}
+
+		path := filepath.Join(dir, baseName+renderer.Extension())
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+		if err != nil {
+			return paths, fmt.Errorf("failed to create report: %w", err)
+		}
+		err = render(renderer, file)
+		closeErr := file.Close()
+		if err != nil {
+			return paths, fmt.Errorf("failed to render %s report: %w", renderer.Format(), err)
+		}
+		if closeErr != nil {
+			return paths, fmt.Errorf("failed to write report: %w", closeErr)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
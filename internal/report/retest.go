@@ -0,0 +1,129 @@
+package report
+
+import (
+	"sort"
+	"time"
+)
+
+// Retest finding statuses
+const (
+	RetestFixed     = "fixed"
+	RetestUnchanged = "unchanged"
+	RetestNew       = "new"
+)
+
+// Comparison is a re-test report: the open ports of a new run compared with a baseline run
+type Comparison struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Baseline    *TargetSummary   `json:"baseline"`
+	Current     *TargetSummary   `json:"current"`
+	Hosts       []HostComparison `json:"hosts"`
+	Fixed       int              `json:"fixed"`
+	Unchanged   int              `json:"unchanged"`
+	New         int              `json:"new"`
+}
+
+// HostComparison lists the per-port retest results for one host
+type HostComparison struct {
+	Host    string         `json:"host"`
+	Labels  []string       `json:"labels,omitempty"`
+	Results []RetestResult `json:"results"`
+}
+
+// RetestResult is the retest status of one open port
+type RetestResult struct {
+	Status         string      `json:"status"`
+	Port           PortSummary `json:"port"`
+	BaselineBanner string      `json:"baseline_banner,omitempty"` // Set when an unchanged port's banner differs
+}
+
+// BuildComparison compares the open ports of a current run against a baseline
+func BuildComparison(baseline, current *TargetSummary) *Comparison {
+	comparison := &Comparison{
+		GeneratedAt: time.Now(),
+		Baseline:    baseline,
+		Current:     current,
+	}
+
+	baselinePorts := indexOpenPorts(baseline)
+	currentPorts := indexOpenPorts(current)
+
+	hostNames := make(map[string]bool)
+	labels := make(map[string][]string)
+	for _, summary := range []*TargetSummary{baseline, current} {
+		for _, host := range summary.Hosts {
+			hostNames[host.Host] = true
+			labels[host.Host] = mergeStrings(labels[host.Host], host.Labels)
+		}
+	}
+
+	hosts := make([]string, 0, len(hostNames))
+	for host := range hostNames {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		hostComparison := HostComparison{Host: host, Labels: labels[host]}
+
+		for key, port := range currentPorts[host] {
+			result := RetestResult{Status: RetestNew, Port: port}
+			if previous, existed := baselinePorts[host][key]; existed {
+				result.Status = RetestUnchanged
+				if previous.Banner() != port.Banner() {
+					result.BaselineBanner = previous.Banner()
+				}
+			}
+			hostComparison.Results = append(hostComparison.Results, result)
+		}
+		for key, port := range baselinePorts[host] {
+			if _, stillOpen := currentPorts[host][key]; !stillOpen {
+				hostComparison.Results = append(hostComparison.Results, RetestResult{Status: RetestFixed, Port: port})
+			}
+		}
+
+		if len(hostComparison.Results) == 0 {
+			continue
+		}
+		sortRetestResults(hostComparison.Results)
+		for _, result := range hostComparison.Results {
+			switch result.Status {
+			case RetestFixed:
+				comparison.Fixed++
+			case RetestUnchanged:
+				comparison.Unchanged++
+			case RetestNew:
+				comparison.New++
+			}
+		}
+		comparison.Hosts = append(comparison.Hosts, hostComparison)
+	}
+
+	return comparison
+}
+
+// indexOpenPorts maps host -> port key -> port for a summary
+func indexOpenPorts(summary *TargetSummary) map[string]map[string]PortSummary {
+	index := make(map[string]map[string]PortSummary)
+	for _, host := range summary.Hosts {
+		index[host.Host] = make(map[string]PortSummary)
+		for _, port := range host.OpenPorts {
+			index[host.Host][port.Key()] = port
+		}
+	}
+	return index
+}
+
+// sortRetestResults orders results fixed, new, unchanged, then by port
+func sortRetestResults(results []RetestResult) {
+	order := map[string]int{RetestFixed: 0, RetestNew: 1, RetestUnchanged: 2}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Status != results[j].Status {
+			return order[results[i].Status] < order[results[j].Status]
+		}
+		if results[i].Port.Port != results[j].Port.Port {
+			return results[i].Port.Port < results[j].Port.Port
+		}
+		return results[i].Port.Protocol < results[j].Port.Protocol
+	})
+}
@@ -0,0 +1,160 @@
+package report
+
+import (
+	"sort"
+	"time"
+)
+
+// Rollup is the engagement-level report across every target of a run
+type Rollup struct {
+	GeneratedAt  time.Time        `json:"generated_at"`
+	Targets      []*TargetSummary `json:"targets"`
+	HostCount    int              `json:"host_count"`
+	OpenPorts    int              `json:"open_port_count"`
+	TopPorts     []Exposure       `json:"top_ports"`     // Ports ranked by how many hosts expose them
+	Services     []Exposure       `json:"services"`      // Services ranked by how many hosts run them
+	BusiestHosts []HostExposure   `json:"busiest_hosts"` // Hosts ranked by open port count
+	Matrix       HostMatrix       `json:"host_matrix"`
+}
+
+// Exposure counts the hosts exposing a port or service
+type Exposure struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Hosts []string `json:"hosts"`
+}
+
+// HostExposure is a host with its open port count
+type HostExposure struct {
+	Host      string   `json:"host"`
+	Target    string   `json:"target"`
+	Labels    []string `json:"labels,omitempty"`
+	OpenPorts int      `json:"open_ports"`
+}
+
+// HostMatrix marks which of the most common ports are open on each host
+type HostMatrix struct {
+	Ports []string        `json:"ports"`
+	Rows  []HostMatrixRow `json:"rows"`
+}
+
+// HostMatrixRow is one host's row in the matrix
+type HostMatrixRow struct {
+	Host string `json:"host"`
+	Open []bool `json:"open"`
+}
+
+// matrixColumns caps the number of port columns in the host matrix
+const matrixColumns = 12
+
+// topLimit caps ranked lists in the roll-up
+const topLimit = 20
+
+// BuildRollup aggregates target summaries into an engagement roll-up
+func BuildRollup(targets []*TargetSummary) *Rollup {
+	rollup := &Rollup{
+		GeneratedAt: time.Now(),
+		Targets:     targets,
+	}
+
+	portHosts := make(map[string]map[string]bool)
+	serviceHosts := make(map[string]map[string]bool)
+	hostPorts := make(map[string]map[string]bool)
+	seenHosts := make(map[string]bool)
+
+	for _, target := range targets {
+		for _, host := range target.Hosts {
+			if !seenHosts[host.Host] {
+				seenHosts[host.Host] = true
+				hostPorts[host.Host] = make(map[string]bool)
+			}
+			exposure := HostExposure{Host: host.Host, Target: target.Target, Labels: host.Labels}
+			for _, port := range host.OpenPorts {
+				key := port.Key()
+				if !hostPorts[host.Host][key] {
+					hostPorts[host.Host][key] = true
+					rollup.OpenPorts++
+				}
+				addHost(portHosts, key, host.Host)
+				if port.Service != "" {
+					addHost(serviceHosts, port.Service, host.Host)
+				}
+				exposure.OpenPorts++
+			}
+			rollup.BusiestHosts = append(rollup.BusiestHosts, exposure)
+		}
+	}
+	rollup.HostCount = len(seenHosts)
+
+	rollup.TopPorts = rankExposures(portHosts)
+	rollup.Services = rankExposures(serviceHosts)
+
+	sort.SliceStable(rollup.BusiestHosts, func(i, j int) bool {
+		if rollup.BusiestHosts[i].OpenPorts != rollup.BusiestHosts[j].OpenPorts {
+			return rollup.BusiestHosts[i].OpenPorts > rollup.BusiestHosts[j].OpenPorts
+		}
+		return rollup.BusiestHosts[i].Host < rollup.BusiestHosts[j].Host
+	})
+	if len(rollup.BusiestHosts) > topLimit {
+		rollup.BusiestHosts = rollup.BusiestHosts[:topLimit]
+	}
+
+	rollup.Matrix = buildMatrix(rollup.TopPorts, hostPorts)
+	if len(rollup.TopPorts) > topLimit {
+		rollup.TopPorts = rollup.TopPorts[:topLimit]
+	}
+	return rollup
+}
+
+func addHost(index map[string]map[string]bool, key, host string) {
+	if index[key] == nil {
+		index[key] = make(map[string]bool)
+	}
+	index[key][host] = true
+}
+
+// rankExposures sorts keys by host count (descending), then name
+func rankExposures(index map[string]map[string]bool) []Exposure {
+	exposures := make([]Exposure, 0, len(index))
+	for name, hosts := range index {
+		exposure := Exposure{Name: name, Count: len(hosts)}
+		for host := range hosts {
+			exposure.Hosts = append(exposure.Hosts, host)
+		}
+		sort.Strings(exposure.Hosts)
+		exposures = append(exposures, exposure)
+	}
+	sort.Slice(exposures, func(i, j int) bool {
+		if exposures[i].Count != exposures[j].Count {
+			return exposures[i].Count > exposures[j].Count
+		}
+		return exposures[i].Name < exposures[j].Name
+	})
+	return exposures
+}
+
+// buildMatrix builds the host x common-port matrix
+func buildMatrix(topPorts []Exposure, hostPorts map[string]map[string]bool) HostMatrix {
+	matrix := HostMatrix{}
+	for i, exposure := range topPorts {
+		if i >= matrixColumns {
+			break
+		}
+		matrix.Ports = append(matrix.Ports, exposure.Name)
+	}
+
+	hosts := make([]string, 0, len(hostPorts))
+	for host := range hostPorts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		row := HostMatrixRow{Host: host, Open: make([]bool, len(matrix.Ports))}
+		for i, port := range matrix.Ports {
+			row.Open[i] = hostPorts[host][port]
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	return matrix
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+func init() {
+	RegisterRenderer(&SARIFRenderer{})
+}
+
+// SARIFRenderer writes reports as SARIF 2.1.0 for GitHub code scanning and other CI dashboards.
+// The SARIF mapping itself lives in the output package; this adapts report models to it
+type SARIFRenderer struct{}
+
+// Format returns the format name used in configuration
+func (r *SARIFRenderer) Format() string {
+	return "sarif"
+}
+
+// Extension returns the report file extension
+func (r *SARIFRenderer) Extension() string {
+	return ".sarif"
+}
+
+// RenderTarget writes a single target's run
+func (r *SARIFRenderer) RenderTarget(w io.Writer, s *TargetSummary) error {
+	return output.WriteSARIF(w, output.BuildSARIFRun(sarifRunReport(s, nil), nil))
+}
+
+// RenderRollup writes one SARIF run per target
+func (r *SARIFRenderer) RenderRollup(w io.Writer, rollup *Rollup) error {
+	runs := make([]output.SARIFRun, 0, len(rollup.Targets))
+	for _, target := range rollup.Targets {
+		runs = append(runs, output.BuildSARIFRun(sarifRunReport(target, nil), nil))
+	}
+	return output.WriteSARIF(w, runs...)
+}
+
+// RenderComparison writes the current run with SARIF baseline states; fixed ports are "absent" results
+func (r *SARIFRenderer) RenderComparison(w io.Writer, c *Comparison) error {
+	states := make(map[string]string)
+	var fixed []output.DiscoveredPort
+	for _, host := range c.Hosts {
+		for _, result := range host.Results {
+			key := fmt.Sprintf("%s:%s", host.Host, result.Port.Key())
+			switch result.Status {
+			case RetestNew:
+				states[key] = output.SARIFBaselineNew
+			case RetestUnchanged:
+				states[key] = output.SARIFBaselineUnchanged
+			case RetestFixed:
+				states[key] = output.SARIFBaselineAbsent
+				fixed = append(fixed, discoveredPort(host.Host, result.Port))
+			}
+		}
+	}
+	return output.WriteSARIF(w, output.BuildSARIFRun(sarifRunReport(c.Current, fixed), states))
+}
+
+// sarifRunReport combines a target's run report (if any) with its merged open ports
+func sarifRunReport(s *TargetSummary, extraPorts []output.DiscoveredPort) output.RunReport {
+	run := output.RunReport{ScanID: s.ScanID, Target: s.Target, Workspace: s.Workspace, StartedAt: s.StartedAt}
+	if s.Run != nil {
+		run = *s.Run
+	}
+	if run.Timezone == "" {
+		run.Timezone = s.Timezone
+	}
+
+	run.Ports = nil
+	for _, host := range s.Hosts {
+		for _, port := range host.OpenPorts {
+			run.Ports = append(run.Ports, discoveredPort(host.Host, port))
+		}
+	}
+	run.Ports = append(run.Ports, extraPorts...)
+	return run
+}
+
+// discoveredPort converts a merged port summary for the output package
+func discoveredPort(host string, port PortSummary) output.DiscoveredPort {
+	return output.DiscoveredPort{
+		Host:     host,
+		Port:     port.Port,
+		Protocol: port.Protocol,
+		Service:  port.Service,
+		Product:  port.Product,
+		Version:  port.Version,
+		Tools:    port.Tools,
+	}
+}
@@ -0,0 +1,205 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/heuristics"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// TargetSummary is the report model for a single target's workspace
+type TargetSummary struct {
+	Target          string             `json:"target"`
+	ScanID          string             `json:"scan_id,omitempty"`
+	Session         string             `json:"session,omitempty"`
+	Workspace       string             `json:"workspace" redact:"-"`
+	Status          string             `json:"status,omitempty"`
+	Labels          []string           `json:"labels,omitempty"`
+	StartedAt       time.Time          `json:"started_at,omitempty"`
+	Timezone        string             `json:"timezone,omitempty"` // Engagement timezone for target-local times
+	DurationSeconds float64            `json:"duration_seconds,omitempty"`
+	Hosts           []HostSummary      `json:"hosts"`
+	Findings        []findings.Finding `json:"findings"`
+	Warnings        []string           `json:"warnings,omitempty"`
+
+	// Anomalies are review hints from configs/heuristics.yaml, set by the caller
+	Anomalies []heuristics.Anomaly `json:"anomalies,omitempty"`
+
+	// Run is the workspace's report.json (workflow steps, tool executions), nil for older workspaces
+	Run *output.RunReport `json:"-"`
+}
+
+// HostSummary lists the open ports seen on one host, merged across tools
+type HostSummary struct {
+	Host      string                  `json:"host"`
+	Hostnames []string                `json:"hostnames,omitempty"`
+	OS        string                  `json:"os,omitempty"`
+	Labels    []string                `json:"labels,omitempty"`
+	OpenPorts []PortSummary           `json:"open_ports"`
+	Scripts   []findings.ScriptResult `json:"scripts,omitempty"` // Host-level script results
+}
+
+// PortSummary describes one open port, merged across the tools that reported it
+type PortSummary struct {
+	Port     int      `json:"port"`
+	Protocol string   `json:"protocol"`
+	Service  string   `json:"service,omitempty"`
+	Product  string   `json:"product,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	TLS      bool     `json:"tls,omitempty"`
+	Tools    []string `json:"tools"`
+
+	// Web service details from HTTP probes (e.g. httpx)
+	URL          string   `json:"url,omitempty"`
+	StatusCode   int      `json:"status_code,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	Technologies []string `json:"technologies,omitempty"`
+
+	Scripts []findings.ScriptResult `json:"scripts,omitempty"`
+}
+
+// Key identifies the port independent of host, e.g. "445/tcp"
+func (p PortSummary) Key() string {
+	return fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+}
+
+// Banner returns the product and version as one string
+func (p PortSummary) Banner() string {
+	return strings.TrimSpace(p.Product + " " + p.Version)
+}
+
+// BuildTargetSummary builds the report model from a run manifest and its findings
+func BuildTargetSummary(manifest *session.RunManifest, workspaceDir string, list []findings.Finding) *TargetSummary {
+	summary := &TargetSummary{
+		Workspace: workspaceDir,
+		Findings:  list,
+	}
+	if manifest != nil {
+		summary.Target = manifest.Target
+		summary.ScanID = manifest.ScanID
+		summary.Session = manifest.SessionName
+		summary.Status = manifest.Status
+		summary.Labels = manifest.Labels
+		summary.StartedAt = manifest.StartedAt
+		summary.Timezone = manifest.Timezone
+		summary.DurationSeconds = manifest.DurationSeconds
+	}
+
+	hosts := make(map[string]*HostSummary)
+	ports := make(map[string]map[string]*PortSummary) // host -> port key -> summary
+	for _, f := range list {
+		if f.Host == "" {
+			continue
+		}
+		host, exists := hosts[f.Host]
+		if !exists {
+			host = &HostSummary{Host: f.Host}
+			hosts[f.Host] = host
+			ports[f.Host] = make(map[string]*PortSummary)
+		}
+		host.Labels = mergeStrings(host.Labels, f.Labels)
+		host.Hostnames = mergeStrings(host.Hostnames, f.Hostnames)
+		if host.OS == "" {
+			host.OS = f.OS
+		}
+
+		if f.Port == 0 {
+			host.Scripts = mergeScripts(host.Scripts, f.Scripts)
+			continue
+		}
+		if f.State != "open" {
+			continue
+		}
+
+		key := fmt.Sprintf("%d/%s", f.Port, f.Protocol)
+		port, exists := ports[f.Host][key]
+		if !exists {
+			port = &PortSummary{Port: f.Port, Protocol: f.Protocol}
+			ports[f.Host][key] = port
+		}
+		// Keep the most detailed service information reported by any tool
+		if port.Service == "" {
+			port.Service = f.Service
+		}
+		if port.Product == "" {
+			port.Product, port.Version = f.Product, f.Version
+		}
+		if port.URL == "" {
+			port.URL, port.StatusCode, port.Title = f.URL, f.StatusCode, f.Title
+		}
+		port.Technologies = mergeStrings(port.Technologies, f.Technologies)
+		port.TLS = port.TLS || f.TLS
+		port.Tools = mergeStrings(port.Tools, []string{f.Tool})
+		port.Scripts = mergeScripts(port.Scripts, f.Scripts)
+	}
+
+	for name, host := range hosts {
+		for _, port := range ports[name] {
+			host.OpenPorts = append(host.OpenPorts, *port)
+		}
+		sort.Slice(host.OpenPorts, func(i, j int) bool {
+			if host.OpenPorts[i].Port != host.OpenPorts[j].Port {
+				return host.OpenPorts[i].Port < host.OpenPorts[j].Port
+			}
+			return host.OpenPorts[i].Protocol < host.OpenPorts[j].Protocol
+		})
+		summary.Hosts = append(summary.Hosts, *host)
+	}
+	sort.Slice(summary.Hosts, func(i, j int) bool {
+		return summary.Hosts[i].Host < summary.Hosts[j].Host
+	})
+
+	return summary
+}
+
+// OpenPortCount returns the number of open host/port pairs
+func (s *TargetSummary) OpenPortCount() int {
+	count := 0
+	for _, host := range s.Hosts {
+		count += len(host.OpenPorts)
+	}
+	return count
+}
+
+// mergeScripts appends script results whose ID is not already present
+func mergeScripts(existing, extra []findings.ScriptResult) []findings.ScriptResult {
+	for _, script := range extra {
+		found := false
+		for _, current := range existing {
+			if current.ID == script.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, script)
+		}
+	}
+	return existing
+}
+
+// mergeStrings appends values not already present and keeps the result sorted
+func mergeStrings(existing, extra []string) []string {
+	for _, value := range extra {
+		if value == "" {
+			continue
+		}
+		found := false
+		for _, current := range existing {
+			if current == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, value)
+		}
+	}
+	sort.Strings(existing)
+	return existing
+}
@@ -0,0 +1,251 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/config"
+)
+
+// TriageFileName is the checklist `ipcrawler triage` writes to a workspace's reports directory
+const TriageFileName = "triage.md"
+
+// Triage is a target's manual-review checklist: one item per host and open port, with the
+// suggested checks of configs/triage.yaml and the heuristics' review hints nested under it
+type Triage struct {
+	Target      string
+	ScanID      string
+	GeneratedAt time.Time
+	Hosts       []TriageHost
+	PortCount   int
+}
+
+// TriageHost is the checklist of one host
+type TriageHost struct {
+	Host      string
+	Hostnames []string
+	Labels    []string
+	Hints     []TriageItem // Host-level review hints
+	Ports     []TriagePort
+}
+
+// TriagePort is the item of one open port and the checks nested under it
+type TriagePort struct {
+	Item   TriageItem
+	Checks []TriageItem
+}
+
+// TriageItem is one checkbox
+type TriageItem struct {
+	Text string
+	Done bool
+}
+
+// BuildTriage builds the checklist of a target from its summary and the configured checks
+func BuildTriage(s *TargetSummary, settings config.TriageConfig) *Triage {
+	triage := &Triage{Target: s.Target, ScanID: s.ScanID, GeneratedAt: time.Now()}
+	for _, host := range s.Hosts {
+		entry := TriageHost{Host: host.Host, Hostnames: host.Hostnames, Labels: host.Labels}
+		for _, anomaly := range s.Anomalies {
+			if anomaly.Host == host.Host && anomaly.Port == 0 {
+				entry.Hints = append(entry.Hints, TriageItem{Text: "Review hint: " + anomaly.Message})
+			}
+		}
+		for _, port := range host.OpenPorts {
+			item := TriagePort{Item: TriageItem{Text: triagePortText(port)}}
+			for _, check := range triageChecks(port, settings) {
+				item.Checks = append(item.Checks, TriageItem{Text: fillTriageCheck(check, host.Host, port)})
+			}
+			for _, anomaly := range s.Anomalies {
+				if anomaly.Host == host.Host && anomaly.Port == port.Port && anomaly.Protocol == port.Protocol {
+					item.Checks = append(item.Checks, TriageItem{Text: "Review hint: " + anomaly.Message})
+				}
+			}
+			entry.Ports = append(entry.Ports, item)
+		}
+		triage.Hosts = append(triage.Hosts, entry)
+		triage.PortCount += len(host.OpenPorts)
+	}
+	return triage
+}
+
+// triagePortText describes a port, e.g. "**22/tcp** ssh, OpenSSH 8.9p1"
+func triagePortText(port PortSummary) string {
+	service := port.Service
+	if port.TLS && service != "" {
+		service += " (TLS)"
+	}
+	details := dashIfEmpty(service)
+	if banner := port.Banner(); banner != "" {
+		details += ", " + banner
+	}
+	return fmt.Sprintf("**%s** %s", port.Key(), details)
+}
+
+// triageChecks returns the checks of every entry matching the port, or the default checks
+func triageChecks(port PortSummary, settings config.TriageConfig) []string {
+	service := normalizeTriageService(port.Service)
+	var checks []string
+	seen := make(map[string]bool)
+	for _, entry := range settings.Services {
+		matches := service != "" && normalizeTriageService(entry.Service) == service
+		for _, candidate := range entry.Ports {
+			matches = matches || candidate == port.Port
+		}
+		if !matches {
+			continue
+		}
+		for _, check := range entry.Checks {
+			if !seen[check] {
+				seen[check] = true
+				checks = append(checks, check)
+			}
+		}
+	}
+	if len(checks) == 0 {
+		return settings.Default
+	}
+	return checks
+}
+
+// normalizeTriageService folds service names as the heuristics' expected_ports do: nmap's
+// "ssl/" prefix and uncertainty mark are dropped and every http* name is http
+func normalizeTriageService(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "ssl/"), "?")
+	switch {
+	case name == "unknown", name == "tcpwrapped":
+		return ""
+	case strings.HasPrefix(name, "http"):
+		return "http"
+	}
+	return name
+}
+
+// fillTriageCheck fills in the {{host}}, {{port}}, {{protocol}} and {{url}} of a check
+func fillTriageCheck(check, host string, port PortSummary) string {
+	url := port.URL
+	if url == "" {
+		scheme := "http"
+		if port.TLS || strings.HasPrefix(port.Service, "ssl/") || strings.HasPrefix(port.Service, "https") {
+			scheme = "https"
+		}
+		url = scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port.Port))
+	}
+	return strings.NewReplacer(
+		"{{host}}", host,
+		"{{port}}", strconv.Itoa(port.Port),
+		"{{protocol}}", port.Protocol,
+		"{{url}}", url,
+	).Replace(check)
+}
+
+// triageLine matches a checklist item: its indentation, box and text
+var triageLine = regexp.MustCompile(`^(\s*)- \[([ xX])\] (.*)$`)
+
+// MarkDone ticks the items ticked in an earlier checklist of the same target, so regenerating
+// the checklist after a rescan keeps the operator's progress. Items are matched by their text
+// under the same host and port
+func (t *Triage) MarkDone(previous io.Reader) error {
+	done := make(map[string]bool)
+	var host, port string
+	scanner := bufio.NewScanner(previous)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if heading, found := strings.CutPrefix(line, "## "); found {
+			host, port = strings.TrimSpace(heading), ""
+			continue
+		}
+		match := triageLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		parent := ""
+		if match[1] == "" {
+			port = match[3]
+		} else {
+			parent = port
+		}
+		if match[2] != " " {
+			done[triageKey(host, parent, match[3])] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for i := range t.Hosts {
+		host := &t.Hosts[i]
+		for j := range host.Hints {
+			host.Hints[j].Done = done[triageKey(host.Host, "", host.Hints[j].Text)]
+		}
+		for j := range host.Ports {
+			port := &host.Ports[j]
+			port.Item.Done = done[triageKey(host.Host, "", port.Item.Text)]
+			for k := range port.Checks {
+				port.Checks[k].Done = done[triageKey(host.Host, port.Item.Text, port.Checks[k].Text)]
+			}
+		}
+	}
+	return nil
+}
+
+func triageKey(host, parent, text string) string {
+	return host + "\x00" + parent + "\x00" + text
+}
+
+// RenderTriage writes the checklist as Markdown task lists
+func RenderTriage(w io.Writer, t *Triage) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# Triage Checklist: %s\n\n", t.Target)
+	if t.ScanID != "" {
+		fmt.Fprintf(out, "- **Scan ID:** %s\n", t.ScanID)
+	}
+	fmt.Fprintf(out, "- **Generated:** %s\n", t.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(out, "- **Hosts:** %d\n", len(t.Hosts))
+	fmt.Fprintf(out, "- **Open ports:** %d\n\n", t.PortCount)
+	fmt.Fprintf(out, "Tick each box (`[x]`) as you go; regenerating the checklist keeps the ticked boxes.\n\n")
+
+	if len(t.Hosts) == 0 {
+		fmt.Fprintf(out, "No hosts or open ports were found.\n")
+	}
+	for _, host := range t.Hosts {
+		fmt.Fprintf(out, "## %s\n\n", host.Host)
+		if len(host.Hostnames) > 0 {
+			fmt.Fprintf(out, "Hostnames: %s\n\n", strings.Join(host.Hostnames, ", "))
+		}
+		if len(host.Labels) > 0 {
+			fmt.Fprintf(out, "Labels: %s\n\n", strings.Join(host.Labels, ", "))
+		}
+		for _, hint := range host.Hints {
+			writeTriageItem(out, "", hint)
+		}
+		if len(host.Ports) == 0 {
+			fmt.Fprintf(out, "No open ports found.\n")
+		}
+		for _, port := range host.Ports {
+			writeTriageItem(out, "", port.Item)
+			for _, check := range port.Checks {
+				writeTriageItem(out, "  ", check)
+			}
+		}
+		fmt.Fprintln(out)
+	}
+
+	return out.Flush()
+}
+
+func writeTriageItem(out *bufio.Writer, indent string, item TriageItem) {
+	box := " "
+	if item.Done {
+		box = "x"
+	}
+	fmt.Fprintf(out, "%s- [%s] %s\n", indent, box, item.Text)
+}
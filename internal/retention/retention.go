@@ -0,0 +1,188 @@
+// Package retention decides which workspaces to prune from a workspace base directory under
+// limits on their number, age and total size. Pinned workspaces and runs still in progress
+// are outside the policy: they are neither counted nor removed
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/archive"
+	"github.com/neur0map/ipcrawler/internal/session"
+	"github.com/neur0map/ipcrawler/internal/workspace"
+)
+
+// Policy limits the workspaces kept; zero values do not limit
+type Policy struct {
+	MaxWorkspaces int
+	MaxAge        time.Duration
+	MaxTotalBytes int64
+}
+
+// Limited reports whether the policy limits anything
+func (p Policy) Limited() bool {
+	return p.MaxWorkspaces > 0 || p.MaxAge > 0 || p.MaxTotalBytes > 0
+}
+
+// Workspace is one workspace directory or archive under the base directory
+type Workspace struct {
+	Path    string
+	Name    string // Directory name, without an archive extension
+	Archive bool
+	Created time.Time
+	Size    int64
+	Pinned  bool
+	Running bool   // The manifest says the run has not finished
+	Reason  string // Why the plan removes it
+}
+
+// Scan lists the workspaces directly under baseDir, oldest first, with the archives of
+// workspaces when includeArchives is set. Directories that do not look like workspaces are
+// ignored
+func Scan(baseDir string, includeArchives bool) ([]Workspace, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", baseDir, err)
+	}
+
+	var workspaces []Workspace
+	for _, entry := range entries {
+		path := filepath.Join(baseDir, entry.Name())
+		var workspace Workspace
+		var ok bool
+		switch {
+		case entry.IsDir():
+			workspace, ok = scanDirectory(path)
+		case entry.Type().IsRegular() && includeArchives && archive.IsArchive(entry.Name()):
+			workspace, ok = scanArchive(path)
+		}
+		if ok {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	sort.SliceStable(workspaces, func(i, j int) bool { return workspaces[i].Created.Before(workspaces[j].Created) })
+	return workspaces, nil
+}
+
+func scanDirectory(path string) (Workspace, bool) {
+	name := filepath.Base(path)
+	created, ok := workspace.ParseName(name)
+	if !ok {
+		return Workspace{}, false
+	}
+	workspace := Workspace{Path: path, Name: name, Created: created}
+	if manifest, err := session.LoadManifest(path); err == nil {
+		workspace.Pinned = manifest.Pinned
+		workspace.Running = manifest.Status == session.RunStatusRunning
+	} else if _, err := os.Stat(filepath.Join(path, "scans")); err != nil {
+		return Workspace{}, false // Neither a manifest nor scan outputs: not a workspace
+	}
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				workspace.Size += info.Size()
+			}
+		}
+		return nil
+	})
+	return workspace, true
+}
+
+func scanArchive(path string) (Workspace, bool) {
+	base := filepath.Base(path)
+	name := base
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(strings.ToLower(base), ext) {
+			name = base[:len(base)-len(ext)]
+			break
+		}
+	}
+	created, ok := workspace.ParseName(name)
+	info, err := os.Stat(path)
+	if !ok || err != nil {
+		return Workspace{}, false
+	}
+	workspace := Workspace{Path: path, Name: name, Archive: true, Created: created, Size: info.Size()}
+	data, err := archive.ReadFile(path, session.ManifestFileName)
+	if err == nil {
+		var manifest session.RunManifest
+		if json.Unmarshal(data, &manifest) == nil {
+			workspace.Pinned = manifest.Pinned
+		}
+	}
+	return workspace, true
+}
+
+// Plan returns the workspaces the policy removes, oldest first: those older than MaxAge, then
+// the oldest until at most MaxWorkspaces remain, then the oldest until the rest fit in
+// MaxTotalBytes. Paths in keep (e.g. the workspace of the run starting) count towards the
+// limits but are never removed
+func Plan(workspaces []Workspace, policy Policy, now time.Time, keep ...string) []Workspace {
+	kept := make(map[string]bool, len(keep))
+	for _, path := range keep {
+		if abs, err := filepath.Abs(path); err == nil {
+			kept[abs] = true
+		}
+	}
+	keptCount := len(kept)
+	var candidates []Workspace
+	for _, workspace := range workspaces {
+		abs, _ := filepath.Abs(workspace.Path)
+		if workspace.Pinned || workspace.Running || kept[abs] {
+			continue
+		}
+		candidates = append(candidates, workspace)
+	}
+
+	var removed []Workspace
+	remaining := candidates[:0:0]
+	for _, workspace := range candidates {
+		if policy.MaxAge > 0 && now.Sub(workspace.Created) > policy.MaxAge {
+			workspace.Reason = fmt.Sprintf("older than %d days", int(policy.MaxAge.Hours()/24))
+			removed = append(removed, workspace)
+			continue
+		}
+		remaining = append(remaining, workspace)
+	}
+	for policy.MaxWorkspaces > 0 && len(remaining) > 0 && len(remaining)+keptCount > policy.MaxWorkspaces {
+		workspace := remaining[0]
+		workspace.Reason = fmt.Sprintf("more than %d workspaces", policy.MaxWorkspaces)
+		removed = append(removed, workspace)
+		remaining = remaining[1:]
+	}
+	if policy.MaxTotalBytes > 0 {
+		var total int64
+		for _, workspace := range remaining {
+			total += workspace.Size
+		}
+		for len(remaining) > 0 && total > policy.MaxTotalBytes {
+			workspace := remaining[0]
+			workspace.Reason = "total size over the limit"
+			removed = append(removed, workspace)
+			total -= workspace.Size
+			remaining = remaining[1:]
+		}
+	}
+	return removed
+}
+
+// Remove deletes a planned workspace. A directory's manifest is read again first, so a
+// workspace pinned or resumed since the scan is kept
+func Remove(workspace Workspace) error {
+	if workspace.Archive {
+		return os.Remove(workspace.Path)
+	}
+	if manifest, err := session.LoadManifest(workspace.Path); err == nil && (manifest.Pinned || manifest.Status == session.RunStatusRunning) {
+		return fmt.Errorf("%s is pinned or running", workspace.Path)
+	}
+	return os.RemoveAll(workspace.Path)
+}
@@ -0,0 +1,136 @@
+package rundb
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileName is the run database kept in the user's IPCrawler directory (~/.ipcrawler)
+const FileName = "runs.jsonl"
+
+// Run is what the database keeps of one finished run. The workspace holds the full results;
+// the run stays listed after its workspace is moved or deleted
+type Run struct {
+	ScanID          string         `json:"scan_id"`
+	Target          string         `json:"target"`
+	Labels          []string       `json:"labels,omitempty"`
+	Workflows       []string       `json:"workflows"`
+	Status          string         `json:"status"`
+	Error           string         `json:"error,omitempty"`
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      time.Time      `json:"finished_at"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	OpenPorts       int            `json:"open_ports"`
+	Findings        int            `json:"findings"`             // Open ports, web URLs and the other results the report lists
+	Severities      map[string]int `json:"severities,omitempty"` // Open ports by issues.severity level; absent in older records
+	Warnings        int            `json:"warnings,omitempty"`
+	Workspace       string         `json:"workspace"`
+}
+
+// Path returns the run database's location inside the given user directory
+func Path(userDir string) string {
+	return filepath.Join(userDir, FileName)
+}
+
+// Append adds a run to the database. Runs are appended one JSON line at a time, so concurrent
+// runs finishing together do not overwrite each other's records
+func Append(path string, run Run) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create run database directory: %v", err)
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open run database: %v", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write run database: %v", err)
+	}
+	return file.Close()
+}
+
+// Load reads the database, latest run first; a missing file is an empty database. A resumed
+// run is recorded again when it finishes and only its latest record is kept. Lines that do
+// not parse (a write cut short) are skipped
+func Load(path string) ([]Run, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run database: %v", err)
+	}
+	defer file.Close()
+
+	var runs []Run
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil || run.ScanID == "" {
+			continue
+		}
+		if i, seen := index[run.ScanID]; seen {
+			runs[i] = run
+			continue
+		}
+		index[run.ScanID] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read run database: %v", err)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// ForTarget returns the runs of a target, keeping their order
+func ForTarget(runs []Run, target string) []Run {
+	var matched []Run
+	for _, run := range runs {
+		if strings.EqualFold(run.Target, target) {
+			matched = append(matched, run)
+		}
+	}
+	return matched
+}
+
+// Find returns the run with the scan ID, or the only one whose scan ID starts with id (the
+// short IDs in workspace names and listings)
+func Find(runs []Run, id string) (*Run, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return nil, fmt.Errorf("no run ID given")
+	}
+	var found []*Run
+	for i := range runs {
+		scanID := strings.ToLower(runs[i].ScanID)
+		if scanID == id {
+			return &runs[i], nil
+		}
+		if strings.HasPrefix(scanID, id) {
+			found = append(found, &runs[i])
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no run with ID %s", id)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%d runs have IDs starting with %s; give more of the ID", len(found), id)
+	}
+}
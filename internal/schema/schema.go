@@ -0,0 +1,357 @@
+// Package schema checks YAML documents against the Go structs they are decoded into, with the
+// line of every problem: keys no field reads (typos the decoders silently ignore) and values
+// that cannot be decoded into their field. It reads the struct tags the decoders use, `yaml`
+// for tool and workflow files and `mapstructure` for the configs/ files
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of an issue
+const (
+	Error   = "error"   // The file fails to load or the run fails
+	Warning = "warning" // Loads, but something is ignored or likely wrong
+)
+
+// Issue is one problem found in a file
+type Issue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i Issue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+}
+
+// Document is a parsed YAML file whose values can be located by path
+type Document struct {
+	File  string
+	Root  *yaml.Node
+	lines map[string]int
+}
+
+// Parse reads a YAML document; a syntax error is returned as an issue with its line
+func Parse(file string, data []byte) (*Document, *Issue) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		issue := Issue{File: file, Severity: Error, Message: message}
+		var line int
+		if _, scanErr := fmt.Sscanf(message, "line %d:", &line); scanErr == nil {
+			issue.Line = line
+			issue.Message = strings.TrimSpace(strings.TrimPrefix(message, fmt.Sprintf("line %d:", line)))
+		}
+		return nil, &issue
+	}
+	doc := &Document{File: file, Root: &root, lines: make(map[string]int)}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		doc.Root = root.Content[0]
+	}
+	doc.index(doc.Root, "")
+	return doc, nil
+}
+
+// index records the line of every value under its path: "steps[1].depends_on"
+func (d *Document) index(node *yaml.Node, path string) {
+	if node == nil {
+		return
+	}
+	d.lines[path] = node.Line
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := key.Value
+			if path != "" {
+				child = path + "." + key.Value
+			}
+			d.index(value, child)
+			d.lines[child] = key.Line
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			d.index(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// Line returns the line of the value at path, or of its closest parent that exists
+func (d *Document) Line(path string) int {
+	for path != "" {
+		if line, ok := d.lines[path]; ok {
+			return line
+		}
+		if cut := strings.LastIndexAny(path, ".["); cut > 0 {
+			path = path[:cut]
+		} else {
+			break
+		}
+	}
+	if d.Root != nil {
+		return d.Root.Line
+	}
+	return 0
+}
+
+// Issue returns an issue located at path
+func (d *Document) Issue(path, severity, format string, args ...interface{}) Issue {
+	return Issue{File: d.File, Line: d.Line(path), Severity: severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// Node returns the node at a top-level key, nil when it is missing
+func (d *Document) Node(key string) *yaml.Node {
+	if d.Root == nil || d.Root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(d.Root.Content); i += 2 {
+		if d.Root.Content[i].Value == key {
+			return d.Root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Check compares node, found at path, with the type of target. tagKey names the struct tag
+// holding the keys: "yaml" or "mapstructure"
+func (d *Document) Check(node *yaml.Node, path string, target interface{}, tagKey string) []Issue {
+	c := checker{doc: d, tagKey: tagKey}
+	c.check(node, path, reflect.TypeOf(target))
+	return c.issues
+}
+
+// CheckStrict is Check for files whose loader rejects unknown keys: they are errors
+func (d *Document) CheckStrict(node *yaml.Node, path string, target interface{}, tagKey string) []Issue {
+	c := checker{doc: d, tagKey: tagKey, strict: true}
+	c.check(node, path, reflect.TypeOf(target))
+	return c.issues
+}
+
+type checker struct {
+	doc    *Document
+	tagKey string
+	strict bool // Unknown keys fail to load
+	issues []Issue
+}
+
+func (c *checker) add(node *yaml.Node, severity, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{File: c.doc.File, Line: node.Line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// yamlUnmarshaler is implemented by fields with their own YAML decoding
+var yamlUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+func (c *checker) check(node *yaml.Node, path string, t reflect.Type) {
+	if node == nil || t == nil {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return // An empty value leaves the field at its zero value
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(yamlUnmarshaler) {
+		return // Decodes itself, accepting more than one shape; its loader reports bad values
+	}
+	where := path
+	if where == "" {
+		where = "the document"
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			c.add(node, Error, "%s must be a mapping of keys", where)
+			return
+		}
+		fields := c.fields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := joinPath(path, key.Value)
+			field, ok := fields[key.Value]
+			if !ok && c.strict {
+				c.add(key, Error, "unknown key %s%s", child, suggest(key.Value, fields))
+				continue
+			}
+			if !ok {
+				c.add(key, Warning, "unknown key %s is ignored%s", child, suggest(key.Value, fields))
+				continue
+			}
+			c.check(value, child, field)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			c.add(node, Error, "%s must be a mapping", where)
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c.check(node.Content[i+1], joinPath(path, node.Content[i].Value), t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind == yaml.ScalarNode && c.tagKey == "mapstructure" && t.Elem().Kind() == reflect.String {
+			return // Viper splits a comma-separated string into a list
+		}
+		if node.Kind != yaml.SequenceNode {
+			c.add(node, Error, "%s must be a list", where)
+			return
+		}
+		for i, item := range node.Content {
+			c.check(item, fmt.Sprintf("%s[%d]", path, i), t.Elem())
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			c.add(node, Error, "%s must be a single value, not a %s", where, kindName(node.Kind))
+			return
+		}
+		if err := c.checkScalar(node, t); err != nil {
+			c.add(node, Error, "%s: %v", where, err)
+		}
+	}
+}
+
+// checkScalar reports values that cannot be decoded into a field of kind t. The configs/ files
+// are decoded weakly (a quoted "5" is a number), the tool and workflow files strictly
+func (c *checker) checkScalar(node *yaml.Node, t reflect.Type) error {
+	value := node.Value
+	quoted := node.Tag == "!!str"
+	weak := c.tagKey == "mapstructure"
+	switch t.Kind() {
+	case reflect.Bool:
+		if node.Tag == "!!bool" || (weak && isBool(value)) {
+			return nil
+		}
+		return fmt.Errorf("%q is not true or false", value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseInt(value, 0, 64); err == nil && (!quoted || weak) {
+			return nil
+		}
+		return fmt.Errorf("%q is not a whole number", value)
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err == nil && (!quoted || weak) {
+			return nil
+		}
+		return fmt.Errorf("%q is not a number", value)
+	}
+	return nil
+}
+
+// fields maps the keys a struct type reads to their field types, following inlined structs
+func (c *checker) fields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // Unexported
+		}
+		name, options, _ := strings.Cut(field.Tag.Get(c.tagKey), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous || strings.Contains(options, "inline") || strings.Contains(options, "squash") {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, fieldType := range c.fields(embedded) {
+					fields[key] = fieldType
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name) // Both decoders default to the lower-cased name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func isBool(value string) bool {
+	_, err := strconv.ParseBool(value)
+	return err == nil
+}
+
+func kindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	return "value"
+}
+
+// suggest returns ` (did you mean "x"?)` for the known key closest to an unknown one
+func suggest(key string, fields map[string]reflect.Type) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	if match := Closest(key, names); match != "" {
+		return fmt.Sprintf(" (did you mean %q?)", match)
+	}
+	return ""
+}
+
+// Closest returns the candidate within a small edit distance of name, "" when none is close
+func Closest(name string, candidates []string) string {
+	sort.Strings(candidates)
+	best, bestDistance := "", len(name)/3+1
+	if bestDistance > 3 {
+		bestDistance = 3
+	}
+	for _, candidate := range candidates {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance <= bestDistance && distance > 0 {
+			best, bestDistance = candidate, distance-1
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,241 @@
+package scope
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// auditFileMode keeps the audit log readable by its owner only, like the secrets store
+const auditFileMode = 0o600
+
+// Audit sources name where a refused host came from
+const (
+	AuditSourceTarget        = "target"         // A target given to ipcrawler
+	AuditSourceTool          = "tool"           // A tool run about to start
+	AuditSourceHostDiscovery = "host_discovery" // A live host found by a CIDR sweep
+	AuditSourceSubdomains    = "subdomains"     // A name found by subdomain enumeration
+)
+
+// Engagement is a scope file: the networks and domains an engagement may scan and the hosts
+// it never may. Every host outside it is refused and recorded in the audit log
+type Engagement struct {
+	path     string
+	allowed  *ExclusionList // Allowed IPs and CIDRs; ExclusionList is reused as a matcher
+	domains  []string       // Allowed domains, each covering its subdomains
+	excluded *ExclusionList
+	audit    string // Audit log path
+
+	auditMutex sync.Mutex
+	audited    map[AuditEntry]bool // Refusals already recorded, keyed without their time
+}
+
+// engagementFile is the YAML layout of a scope file
+type engagementFile struct {
+	Allow    []string `yaml:"allow"`     // IPs, CIDRs and domains (with their subdomains)
+	Exclude  []string `yaml:"exclude"`   // IPs, CIDRs and hostnames never scanned, even when allowed
+	AuditLog string   `yaml:"audit_log"` // Relative to the scope file; <name>-audit.jsonl beside it when empty
+}
+
+// AuditEntry records one refused host as a line of the audit log
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Host     string    `json:"host"`
+	Source   string    `json:"source"` // One of the AuditSource constants
+	Reason   string    `json:"reason"`
+	ScanID   string    `json:"scan_id,omitempty"`
+	Session  string    `json:"session,omitempty"`
+	Workflow string    `json:"workflow,omitempty"`
+	Tool     string    `json:"tool,omitempty"`
+}
+
+// LoadEngagement reads a scope file strictly and checks that its audit log can be written,
+// so a run never starts without one
+func LoadEngagement(path string) (*Engagement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scope file: %w", err)
+	}
+	var file engagementFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&file)
+	var typeErr *yaml.TypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(typeErr.Errors, "; "))
+	case errors.Is(err, io.EOF):
+		return nil, fmt.Errorf("%s is empty", path)
+	case err != nil:
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	engagement := &Engagement{
+		path:     path,
+		audited:  make(map[AuditEntry]bool),
+		allowed:  NewExclusionList(),
+		excluded: NewExclusionList(),
+	}
+	for _, entry := range file.Allow {
+		entry = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(entry)), ".")
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") || net.ParseIP(entry) != nil {
+			if err := engagement.allowed.Add(entry); err != nil {
+				return nil, fmt.Errorf("%s: allow: %w", path, err)
+			}
+			continue
+		}
+		if !isValidHostname(entry) {
+			return nil, fmt.Errorf("%s: allow: invalid entry '%s': must be an IP, CIDR, or domain", path, entry)
+		}
+		engagement.domains = append(engagement.domains, entry)
+	}
+	if engagement.allowed.IsEmpty() && len(engagement.domains) == 0 {
+		return nil, fmt.Errorf("%s: allow lists no networks or domains", path)
+	}
+	for _, entry := range file.Exclude {
+		if err := engagement.excluded.Add(entry); err != nil {
+			return nil, fmt.Errorf("%s: exclude: %w", path, err)
+		}
+	}
+
+	engagement.audit = file.AuditLog
+	switch {
+	case engagement.audit == "":
+		engagement.audit = strings.TrimSuffix(path, filepath.Ext(path)) + "-audit.jsonl"
+	case !filepath.IsAbs(engagement.audit):
+		engagement.audit = filepath.Join(filepath.Dir(path), engagement.audit)
+	}
+	auditFile, err := os.OpenFile(engagement.audit, os.O_CREATE|os.O_WRONLY|os.O_APPEND, auditFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: cannot write the audit log: %w", path, err)
+	}
+	auditFile.Close()
+	return engagement, nil
+}
+
+// Path returns the scope file the engagement was read from
+func (e *Engagement) Path() string {
+	if e == nil {
+		return ""
+	}
+	return e.path
+}
+
+// AuditLog returns the path of the audit log
+func (e *Engagement) AuditLog() string {
+	if e == nil {
+		return ""
+	}
+	return e.audit
+}
+
+// Exclusions returns the normalized exclude entries, to hand to tools with native exclusion support
+func (e *Engagement) Exclusions() []string {
+	if e == nil {
+		return nil
+	}
+	return e.excluded.Entries()
+}
+
+// Check returns why a target (IP, hostname, or CIDR) is out of scope, or nil when it may be
+// scanned. A hostname is in scope under an allowed domain, or when every address it resolves
+// to is in an allowed network; a CIDR must lie wholly inside one. Hostnames are resolved with
+// resolve, the resolver its tools get their addresses from, so the addresses checked are the
+// ones scanned (nil looks them up). A nil engagement allows everything
+func (e *Engagement) Check(target string, resolve Resolver) error {
+	if e == nil {
+		return nil
+	}
+	target = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(target)), ".")
+	if resolve == nil {
+		resolve = LookupHost
+	}
+	if err := e.excluded.CheckResolved(target, resolve); err != nil {
+		return fmt.Errorf("%v (%s)", err, e.path)
+	}
+	if e.allowed.Excludes(target) || e.inAllowedDomain(target) {
+		return nil
+	}
+	if net.ParseIP(target) == nil && !strings.Contains(target, "/") && isValidHostname(target) && !e.allowed.IsEmpty() {
+		if addrs, err := resolve(target); err == nil && len(addrs) > 0 {
+			inScope := true
+			for _, addr := range addrs {
+				ip := net.ParseIP(addr)
+				inScope = inScope && ip != nil && e.allowed.containsIP(ip)
+			}
+			if inScope {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s is not in the allowed networks or domains of %s", target, e.path)
+}
+
+// Audit appends an entry to the audit log, stamped with the current time. A refusal already
+// recorded (the same host, source, run, workflow and tool) is not written again
+func (e *Engagement) Audit(entry AuditEntry) error {
+	if e == nil {
+		return nil
+	}
+	e.auditMutex.Lock()
+	defer e.auditMutex.Unlock()
+	entry.Time = time.Time{}
+	if e.audited[entry] {
+		return nil
+	}
+	key := entry
+	entry.Time = time.Now().Round(0)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	file, err := os.OpenFile(e.audit, os.O_CREATE|os.O_WRONLY|os.O_APPEND, auditFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	e.audited[key] = true
+	return nil
+}
+
+// Refuse checks a host as Check does and records it in the audit log when it is out of scope;
+// the entry's Host and Reason are filled in. The returned error is the reason, or the audit failure
+func (e *Engagement) Refuse(host string, resolve Resolver, entry AuditEntry) error {
+	reason := e.Check(host, resolve)
+	if reason == nil {
+		return nil
+	}
+	entry.Host = host
+	entry.Reason = reason.Error()
+	if err := e.Audit(entry); err != nil {
+		return fmt.Errorf("%v (%v)", reason, err)
+	}
+	return reason
+}
+
+// inAllowedDomain reports whether a hostname is an allowed domain or one of its subdomains
+func (e *Engagement) inAllowedDomain(host string) bool {
+	for _, domain := range e.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package scope
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// LookupTimeout bounds the lookup of a hostname checked without a resolver
+const LookupTimeout = 10 * time.Second
+
+// Resolver returns the addresses of a hostname
+type Resolver func(host string) ([]string, error)
+
+// LookupHost resolves a hostname with the system resolver, giving up after LookupTimeout
+func LookupHost(host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), LookupTimeout)
+	defer cancel()
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+// ExclusionList holds hosts and networks that must never be scanned
+type ExclusionList struct {
+	networks  []*net.IPNet
+	hostnames map[string]bool
+	entries   []string // Normalized entries in the order they were added
+}
+
+// NewExclusionList creates an empty exclusion list
+func NewExclusionList() *ExclusionList {
+	return &ExclusionList{
+		hostnames: make(map[string]bool),
+	}
+}
+
+// Add adds an IP address, CIDR range, or hostname to the exclusion list
+func (el *ExclusionList) Add(entry string) error {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if entry == "" {
+		return nil
+	}
+
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return fmt.Errorf("invalid exclusion CIDR '%s': %w", entry, err)
+		}
+		el.networks = append(el.networks, network)
+		el.entries = append(el.entries, network.String())
+		return nil
+	}
+
+	if ip := net.ParseIP(entry); ip != nil {
+		el.networks = append(el.networks, singleHostNetwork(ip))
+		el.entries = append(el.entries, ip.String())
+		return nil
+	}
+
+	if !isValidHostname(entry) {
+		return fmt.Errorf("invalid exclusion entry '%s': must be an IP, CIDR, or hostname", entry)
+	}
+	el.hostnames[strings.TrimSuffix(entry, ".")] = true
+	el.entries = append(el.entries, strings.TrimSuffix(entry, "."))
+	return nil
+}
+
+// AddList adds comma-separated exclusion entries (as given to --exclude)
+func (el *ExclusionList) AddList(list string) error {
+	for _, entry := range strings.Split(list, ",") {
+		if err := el.Add(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile adds exclusion entries from a file, one per line; blank lines and # comments are ignored
+func (el *ExclusionList) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open exclude file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		if err := el.AddList(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read exclude file: %w", err)
+	}
+	return nil
+}
+
+// IsEmpty reports whether the list has no entries
+func (el *ExclusionList) IsEmpty() bool {
+	return el == nil || len(el.entries) == 0
+}
+
+// Entries returns the normalized exclusion entries
+func (el *ExclusionList) Entries() []string {
+	if el == nil {
+		return nil
+	}
+	entries := make([]string, len(el.entries))
+	copy(entries, el.entries)
+	return entries
+}
+
+// Excludes reports whether a target (IP, hostname, or CIDR) is fully covered by the exclusion list
+func (el *ExclusionList) Excludes(target string) bool {
+	if el.IsEmpty() {
+		return false
+	}
+
+	target = strings.ToLower(strings.TrimSpace(target))
+	if el.hostnames[strings.TrimSuffix(target, ".")] {
+		return true
+	}
+
+	if ip := net.ParseIP(target); ip != nil {
+		return el.containsIP(ip)
+	}
+
+	if _, network, err := net.ParseCIDR(target); err == nil {
+		for _, excluded := range el.networks {
+			if networkContains(excluded, network) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CheckResolved returns why a target (IP, hostname, or CIDR) is excluded, or nil when it may be
+// scanned. A hostname is also refused when an address resolve returns for it is excluded; pass
+// the resolver its tools get their addresses from (nil looks it up). When the list has networks
+// and the hostname does not resolve, it is refused too: a tool resolving it later could reach
+// an excluded address
+func (el *ExclusionList) CheckResolved(target string, resolve Resolver) error {
+	if el.Excludes(target) {
+		return fmt.Errorf("%s is excluded", target)
+	}
+	if el.IsEmpty() || len(el.networks) == 0 {
+		return nil
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(target)), ".")
+	if net.ParseIP(host) != nil || strings.Contains(host, "/") || !isValidHostname(host) {
+		return nil
+	}
+	if resolve == nil {
+		resolve = LookupHost
+	}
+	addrs, err := resolve(host)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("no addresses")
+	}
+	if err != nil {
+		return fmt.Errorf("%s cannot be checked against the excluded networks: %v", target, err)
+	}
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil && el.containsIP(ip) {
+			return fmt.Errorf("%s is excluded: it resolves to %s", target, addr)
+		}
+	}
+	return nil
+}
+
+// Overlaps reports whether a target range contains any excluded address
+// A single host overlaps only if it is excluded itself
+func (el *ExclusionList) Overlaps(target string) bool {
+	if el.IsEmpty() {
+		return false
+	}
+	if el.Excludes(target) {
+		return true
+	}
+
+	_, network, err := net.ParseCIDR(strings.TrimSpace(target))
+	if err != nil {
+		return false
+	}
+	for _, excluded := range el.networks {
+		if network.Contains(excluded.IP) || excluded.Contains(network.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterHosts removes excluded hosts from an expanded host list
+func (el *ExclusionList) FilterHosts(hosts []string) []string {
+	if el.IsEmpty() {
+		return hosts
+	}
+
+	filtered := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if !el.Excludes(host) {
+			filtered = append(filtered, host)
+		}
+	}
+	return filtered
+}
+
+// containsIP checks whether an IP falls inside any excluded network
+func (el *ExclusionList) containsIP(ip net.IP) bool {
+	for _, network := range el.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// singleHostNetwork returns a /32 (or /128) network for a single IP
+func singleHostNetwork(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// networkContains reports whether outer fully contains inner
+func networkContains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
+
+// isValidHostname performs basic hostname validation
+func isValidHostname(hostname string) bool {
+	hostname = strings.TrimSuffix(hostname, ".")
+	if hostname == "" || len(hostname) > 253 {
+		return false
+	}
+	for _, r := range hostname {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '-') {
+			return false
+		}
+	}
+	return !strings.HasPrefix(hostname, ".") && !strings.HasPrefix(hostname, "-") && !strings.HasSuffix(hostname, "-")
+}
@@ -0,0 +1,97 @@
+package scope
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Labeler assigns labels (e.g. "dmz", "internal") to hosts by IP, CIDR or hostname rules
+type Labeler struct {
+	rules []labelRule
+}
+
+// labelRule maps a set of hosts and networks to a label.
+// ExclusionList is reused purely as an IP/CIDR/hostname matcher
+type labelRule struct {
+	label string
+	hosts *ExclusionList
+}
+
+// NewLabeler creates a labeler with no rules
+func NewLabeler() *Labeler {
+	return &Labeler{}
+}
+
+// AddRule labels every host matching one of the IP, CIDR or hostname entries
+func (l *Labeler) AddRule(label string, entries []string) error {
+	label = NormalizeLabel(label)
+	if label == "" {
+		return fmt.Errorf("label name cannot be empty")
+	}
+
+	hosts := NewExclusionList()
+	for _, entry := range entries {
+		if err := hosts.Add(entry); err != nil {
+			return fmt.Errorf("label '%s': %w", label, err)
+		}
+	}
+	if hosts.IsEmpty() {
+		return fmt.Errorf("label '%s' has no hosts or CIDRs", label)
+	}
+
+	l.rules = append(l.rules, labelRule{label: label, hosts: hosts})
+	return nil
+}
+
+// LabelsFor returns the sorted, de-duplicated labels whose rules cover the host
+func (l *Labeler) LabelsFor(host string) []string {
+	if l == nil {
+		return nil
+	}
+	var labels []string
+	for _, rule := range l.rules {
+		if rule.hosts.Excludes(host) {
+			labels = append(labels, rule.label)
+		}
+	}
+	return MergeLabels(labels)
+}
+
+// IsEmpty reports whether the labeler has no rules
+func (l *Labeler) IsEmpty() bool {
+	return l == nil || len(l.rules) == 0
+}
+
+// NormalizeLabel lowercases and trims a label name
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// ParseLabelList splits a comma-separated label list (as given to --label)
+func ParseLabelList(list string) []string {
+	var labels []string
+	for _, label := range strings.Split(list, ",") {
+		if label = NormalizeLabel(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return MergeLabels(labels)
+}
+
+// MergeLabels combines label lists into a sorted list without duplicates
+func MergeLabels(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, list := range lists {
+		for _, label := range list {
+			label = NormalizeLabel(label)
+			if label != "" && !seen[label] {
+				seen[label] = true
+				merged = append(merged, label)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
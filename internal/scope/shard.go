@@ -0,0 +1,225 @@
+package scope
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"sort"
+	"strings"
+)
+
+// AddressRange is an inclusive range of IPv4 addresses. Planning works on ranges rather than
+// expanded host lists, so ranges far larger than MaxExpandedHosts can be split
+type AddressRange struct {
+	First uint32
+	Last  uint32
+}
+
+// Size is the number of addresses in the range
+func (r AddressRange) Size() uint64 {
+	return uint64(r.Last) - uint64(r.First) + 1
+}
+
+// CIDRs returns the fewest CIDR blocks that cover exactly this range
+func (r AddressRange) CIDRs() []string {
+	var blocks []string
+	first := uint64(r.First)
+	last := uint64(r.Last)
+	for first <= last {
+		// Largest block aligned at first that does not run past last (address 0 aligns to /0)
+		size := uint64(1) << uint(bits.TrailingZeros32(uint32(first)))
+		for first+size-1 > last {
+			size >>= 1
+		}
+		prefix := 32 - bits.TrailingZeros64(size)
+		blocks = append(blocks, fmt.Sprintf("%s/%d", uint32ToIP(uint32(first)), prefix))
+		first += size
+	}
+	return blocks
+}
+
+// Shard is one slice of a planned scan
+type Shard struct {
+	Ranges    []AddressRange
+	Hostnames []string
+}
+
+// Hosts is the number of targets in the shard
+func (s Shard) Hosts() uint64 {
+	total := uint64(len(s.Hostnames))
+	for _, r := range s.Ranges {
+		total += r.Size()
+	}
+	return total
+}
+
+// Targets lists the shard as CIDR blocks and hostnames, the form -iL accepts
+func (s Shard) Targets() []string {
+	var targets []string
+	for _, r := range s.Ranges {
+		targets = append(targets, r.CIDRs()...)
+	}
+	return append(targets, s.Hostnames...)
+}
+
+// ParseRanges turns IPs and IPv4 CIDRs into sorted, merged address ranges; hostnames are
+// returned separately, deduplicated and in order
+func ParseRanges(targets []string) ([]AddressRange, []string, error) {
+	var ranges []AddressRange
+	var hostnames []string
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		target = strings.ToLower(strings.TrimSpace(target))
+		if target == "" {
+			continue
+		}
+		if strings.Contains(target, "/") {
+			_, network, err := net.ParseCIDR(target)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid target CIDR '%s': %w", target, err)
+			}
+			base := network.IP.To4()
+			if base == nil {
+				return nil, nil, fmt.Errorf("cannot plan IPv6 range '%s'; list its hosts individually", target)
+			}
+			ones, size := network.Mask.Size()
+			first := binary.BigEndian.Uint32(base)
+			last := first | uint32(uint64(1)<<uint(size-ones)-1)
+			ranges = append(ranges, AddressRange{First: first, Last: last})
+			continue
+		}
+		if ip := net.ParseIP(target); ip != nil {
+			ipv4 := ip.To4()
+			if ipv4 == nil {
+				if !seen[ip.String()] {
+					seen[ip.String()] = true
+					hostnames = append(hostnames, ip.String())
+				}
+				continue
+			}
+			address := binary.BigEndian.Uint32(ipv4)
+			ranges = append(ranges, AddressRange{First: address, Last: address})
+			continue
+		}
+		if !isValidHostname(target) {
+			return nil, nil, fmt.Errorf("invalid target '%s': must be an IP, CIDR, or hostname", target)
+		}
+		hostname := strings.TrimSuffix(target, ".")
+		if !seen[hostname] {
+			seen[hostname] = true
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return mergeRanges(ranges), hostnames, nil
+}
+
+// SubtractRanges removes the excluded addresses from ranges
+func SubtractRanges(ranges, excluded []AddressRange) []AddressRange {
+	excluded = mergeRanges(excluded)
+	var remaining []AddressRange
+	for _, r := range ranges {
+		current := r
+		keep := true
+		for _, ex := range excluded {
+			if ex.Last < current.First || ex.First > current.Last {
+				continue
+			}
+			if ex.First > current.First {
+				remaining = append(remaining, AddressRange{First: current.First, Last: ex.First - 1})
+			}
+			if ex.Last >= current.Last {
+				keep = false
+				break
+			}
+			current.First = ex.Last + 1
+		}
+		if keep {
+			remaining = append(remaining, current)
+		}
+	}
+	return remaining
+}
+
+// SplitShards divides ranges into count shards of nearly equal size, keeping addresses in
+// order so each shard is a few contiguous CIDR blocks. Hostnames are dealt out round-robin
+func SplitShards(ranges []AddressRange, hostnames []string, count int) []Shard {
+	var total uint64
+	for _, r := range ranges {
+		total += r.Size()
+	}
+	if count < 1 {
+		count = 1
+	}
+	if items := total + uint64(len(hostnames)); items > 0 && uint64(count) > items {
+		count = int(items)
+	}
+
+	shards := make([]Shard, count)
+	index := 0
+	quota := shardQuota(total, count, index)
+	for _, r := range ranges {
+		for r.Size() > 0 && index < count {
+			if quota == 0 {
+				index++
+				quota = shardQuota(total, count, index)
+				continue
+			}
+			take := r.Size()
+			if take > quota {
+				take = quota
+			}
+			piece := AddressRange{First: r.First, Last: uint32(uint64(r.First) + take - 1)}
+			shards[index].Ranges = append(shards[index].Ranges, piece)
+			quota -= take
+			if take == r.Size() {
+				break
+			}
+			r.First = piece.Last + 1
+		}
+	}
+	for i, hostname := range hostnames {
+		shards[i%count].Hostnames = append(shards[i%count].Hostnames, hostname)
+	}
+	return shards
+}
+
+// shardQuota spreads the remainder of total/count over the first shards
+func shardQuota(total uint64, count, index int) uint64 {
+	if index >= count {
+		return 0
+	}
+	quota := total / uint64(count)
+	if uint64(index) < total%uint64(count) {
+		quota++
+	}
+	return quota
+}
+
+// mergeRanges sorts ranges and merges those that overlap or touch
+func mergeRanges(ranges []AddressRange) []AddressRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sorted := append([]AddressRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].First < sorted[j].First })
+	merged := []AddressRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if uint64(r.First) <= uint64(last.Last)+1 {
+			if r.Last > last.Last {
+				last.Last = r.Last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// uint32ToIP formats an IPv4 address
+func uint32ToIP(address uint32) string {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, address)
+	return ip.String()
+}
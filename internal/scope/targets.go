@@ -0,0 +1,124 @@
+package scope
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// MaxExpandedHosts caps how many addresses one target list may expand to (a /16)
+const MaxExpandedHosts = 65536
+
+// LoadTargetList reads targets from a file, one or more per line separated by commas or spaces;
+// blank lines and # comments are ignored. IPv4 CIDR ranges are expanded into their addresses
+// and duplicates are dropped, keeping the first occurrence
+func LoadTargetList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open target list: %w", err)
+	}
+	defer file.Close()
+
+	var targets []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		for _, field := range targetFields(scanner.Text()) {
+			hosts, err := ExpandTarget(field)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+			}
+			for _, host := range hosts {
+				if seen[host] {
+					continue
+				}
+				if len(targets) >= MaxExpandedHosts {
+					return nil, fmt.Errorf("%s: target list expands to more than %d hosts", path, MaxExpandedHosts)
+				}
+				seen[host] = true
+				targets = append(targets, host)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read target list: %w", err)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("target list %s contains no targets", path)
+	}
+	return targets, nil
+}
+
+// ReadTargetEntries reads a target list like LoadTargetList but returns its entries as written,
+// without expanding CIDR ranges, so ranges of any size can be planned
+func ReadTargetEntries(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open target list: %w", err)
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entries = append(entries, targetFields(scanner.Text())...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read target list: %w", err)
+	}
+	return entries, nil
+}
+
+// targetFields splits a target list line on commas and whitespace, dropping # comments
+func targetFields(line string) []string {
+	if idx := strings.Index(line, "#"); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.FieldsFunc(line, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// ExpandTarget normalizes one target. IPv4 CIDR ranges become every address they contain;
+// IPs and hostnames are returned as a single entry
+func ExpandTarget(target string) ([]string, error) {
+	target = strings.ToLower(strings.TrimSpace(target))
+
+	if strings.Contains(target, "/") {
+		_, network, err := net.ParseCIDR(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target CIDR '%s': %w", target, err)
+		}
+		base := network.IP.To4()
+		if base == nil {
+			return nil, fmt.Errorf("cannot expand IPv6 range '%s'; list its hosts individually", target)
+		}
+		ones, bits := network.Mask.Size()
+		if bits-ones > 16 {
+			return nil, fmt.Errorf("range '%s' is larger than %d hosts", target, MaxExpandedHosts)
+		}
+
+		start := binary.BigEndian.Uint32(base)
+		count := uint32(1) << uint(bits-ones)
+		hosts := make([]string, 0, count)
+		for i := uint32(0); i < count; i++ {
+			ip := make(net.IP, net.IPv4len)
+			binary.BigEndian.PutUint32(ip, start+i)
+			hosts = append(hosts, ip.String())
+		}
+		return hosts, nil
+	}
+
+	if ip := net.ParseIP(target); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	if !isValidHostname(target) {
+		return nil, fmt.Errorf("invalid target '%s': must be an IP, CIDR, or hostname", target)
+	}
+	return []string{strings.TrimSuffix(target, ".")}, nil
+}
@@ -0,0 +1,293 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileName is the encrypted secrets file kept in the user's IPCrawler directory (~/.ipcrawler)
+const FileName = "secrets.enc"
+
+// EnvPrefix names secrets given as environment variables: IPCRAWLER_SECRET_SHODAN_API_KEY is
+// {{secret:shodan_api_key}}. They take precedence over the file
+const EnvPrefix = "IPCRAWLER_SECRET_"
+
+// PassphraseEnv holds the passphrase of the secrets file for non-interactive runs
+const PassphraseEnv = "IPCRAWLER_SECRETS_PASSPHRASE"
+
+// minRedactLength keeps very short values (a "1" or "on") from being scrubbed out of every log
+const minRedactLength = 4
+
+// Key derivation parameters of new files; files record their own
+const (
+	kdfName       = "pbkdf2-sha256"
+	kdfIterations = 600000
+	saltBytes     = 16
+	keyBytes      = 32
+)
+
+// Secret is one API key or credential
+type Secret struct {
+	Value string   `json:"value"`
+	Tools []string `json:"tools,omitempty"` // Tools whose arguments may use it; empty means every tool
+}
+
+// Store holds the secrets of a run. Values only reach tool arguments through
+// {{secret:name}}; they never become template variables, so they cannot leak into reports
+type Store struct {
+	secrets map[string]Secret
+	sources map[string]string // "file" or the environment variable
+}
+
+// file is the on-disk format: the secrets map as JSON, encrypted with AES-256-GCM under a
+// key derived from the passphrase
+type file struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Path returns the secrets file's location inside the given user directory
+func Path(userDir string) string {
+	return filepath.Join(userDir, FileName)
+}
+
+// Load builds the store from the secrets file, when it exists and a passphrase is given, and
+// from IPCRAWLER_SECRET_* entries of environ (os.Environ())
+func Load(path, passphrase string, environ []string) (*Store, error) {
+	store := &Store{secrets: make(map[string]Secret), sources: make(map[string]string)}
+	if passphrase != "" {
+		stored, err := ReadFile(path, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		for name, secret := range stored {
+			store.secrets[name] = secret
+			store.sources[name] = "file"
+		}
+	}
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(key, EnvPrefix) || value == "" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(key, EnvPrefix))
+		if name == "" {
+			continue
+		}
+		store.secrets[name] = Secret{Value: value}
+		store.sources[name] = key
+	}
+	return store, nil
+}
+
+// Lookup returns a secret's value for a tool's arguments
+func (s *Store) Lookup(name, tool string) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("secret %q is not set", name)
+	}
+	secret, exists := s.secrets[strings.ToLower(name)]
+	if !exists {
+		return "", fmt.Errorf("secret %q is not set (ipcrawler secrets set %s, or %s%s)", name, name, EnvPrefix, strings.ToUpper(name))
+	}
+	if len(secret.Tools) > 0 && !containsFold(secret.Tools, tool) {
+		return "", fmt.Errorf("secret %q is restricted to %s", name, strings.Join(secret.Tools, ", "))
+	}
+	return secret.Value, nil
+}
+
+// Names returns where each secret comes from ("file" or its environment variable), by name
+func (s *Store) Names() map[string]string {
+	if s == nil {
+		return map[string]string{}
+	}
+	names := make(map[string]string, len(s.sources))
+	for name, source := range s.sources {
+		names[name] = source
+	}
+	return names
+}
+
+// Len returns the number of secrets
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.secrets)
+}
+
+// Enabled reports whether there is anything to redact
+func (s *Store) Enabled() bool {
+	return s.Len() > 0
+}
+
+// Redact replaces every secret value in text with [secret:name]
+func (s *Store) Redact(text string) string {
+	if s == nil || len(s.secrets) == 0 || text == "" {
+		return text
+	}
+	names := make([]string, 0, len(s.secrets))
+	for name, secret := range s.secrets {
+		if len(secret.Value) >= minRedactLength {
+			names = append(names, name)
+		}
+	}
+	// Longer values first, so a value containing another is replaced whole
+	sort.Slice(names, func(i, j int) bool {
+		return len(s.secrets[names[i]].Value) > len(s.secrets[names[j]].Value)
+	})
+	for _, name := range names {
+		text = strings.ReplaceAll(text, s.secrets[name].Value, "[secret:"+name+"]")
+	}
+	return text
+}
+
+// RedactAll redacts every string of a slice, returning a copy
+func (s *Store) RedactAll(values []string) []string {
+	if s == nil || len(s.secrets) == 0 {
+		return values
+	}
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		redacted[i] = s.Redact(value)
+	}
+	return redacted
+}
+
+// ReadFile decrypts the secrets file; a missing file holds no secrets
+func ReadFile(path, passphrase string) (map[string]Secret, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]Secret{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %v", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %v", path, err)
+	}
+	if f.Version != 1 || f.KDF != kdfName || f.Iterations <= 0 {
+		return nil, fmt.Errorf("secrets file %s: unsupported format (version %d, kdf %q)", path, f.Version, f.KDF)
+	}
+	gcm, err := newGCM(passphrase, f.Salt, f.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file %s: wrong passphrase or damaged file", path)
+	}
+	stored := make(map[string]Secret)
+	if err := json.Unmarshal(plaintext, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted secrets: %v", err)
+	}
+	return stored, nil
+}
+
+// WriteFile encrypts the secrets under the passphrase with a fresh salt and nonce, replacing
+// the previous file atomically. Only the owner can read it
+func WriteFile(path, passphrase string, stored map[string]Secret) error {
+	if passphrase == "" {
+		return fmt.Errorf("an empty passphrase cannot protect the secrets file")
+	}
+	plaintext, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %v", err)
+	}
+	f := file{Version: 1, KDF: kdfName, Iterations: kdfIterations, Salt: make([]byte, saltBytes)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %v", err)
+	}
+	gcm, err := newGCM(passphrase, f.Salt, f.Iterations)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	f.Ciphertext = gcm.Seal(nil, f.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %v", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %v", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// ValidName reports whether a name can be used as {{secret:name}} and as an environment variable
+func ValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2SHA256([]byte(passphrase), salt, iterations, keyBytes)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up secrets cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key from a passphrase (RFC 8018, PBKDF2 with HMAC-SHA256)
+func pbkdf2SHA256(password, salt []byte, iterations, length int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	block := make([]byte, 4)
+	for i := uint32(1); len(key) < length; i++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(block, i)
+		prf.Write(block)
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:length]
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package selftest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// Check is one assertion about the self-test run
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Verify checks that the run completed, that every workflow succeeded, and that the running
+// fixtures were found as open ports with the right service
+func Verify(report *output.RunReport, found []findings.Finding, fixtures []*Fixture) []Check {
+	var checks []Check
+
+	run := Check{Name: "run completed", Passed: report.Status == session.RunStatusCompleted}
+	if !run.Passed {
+		run.Detail = strings.TrimSpace(report.Status + ": " + report.Error)
+	}
+	checks = append(checks, run)
+
+	for _, workflow := range report.Workflows {
+		check := Check{Name: "workflow " + workflow.Name, Passed: workflow.Status == "completed"}
+		var failed []string
+		for _, step := range workflow.Steps {
+			if !step.Success && !step.Skipped {
+				failed = append(failed, fmt.Sprintf("%s (%s): %s", step.Name, step.Tool, step.Error))
+			}
+		}
+		if len(failed) > 0 {
+			check.Passed = false
+			check.Detail = strings.Join(failed, "; ")
+		} else if !check.Passed {
+			check.Detail = strings.TrimSpace(workflow.Status + " " + workflow.Error)
+		}
+		checks = append(checks, check)
+	}
+
+	for _, fixture := range fixtures {
+		var open, identified []string
+		for _, f := range found {
+			if f.Host != Host || f.Port != fixture.Port || f.State != "open" {
+				continue
+			}
+			open = append(open, f.Tool)
+			if strings.HasPrefix(strings.ToLower(f.Service), fixture.Service) {
+				identified = append(identified, f.Tool)
+			}
+		}
+
+		portCheck := Check{Name: fmt.Sprintf("port %d open", fixture.Port), Passed: len(open) > 0}
+		if portCheck.Passed {
+			portCheck.Detail = "found by " + strings.Join(unique(open), ", ")
+		} else {
+			portCheck.Detail = "no tool reported the port open"
+		}
+		serviceCheck := Check{Name: fmt.Sprintf("port %d identified as %s", fixture.Port, fixture.Service), Passed: len(identified) > 0}
+		if serviceCheck.Passed {
+			serviceCheck.Detail = "identified by " + strings.Join(unique(identified), ", ")
+		} else {
+			serviceCheck.Detail = "no service detection reported " + fixture.Service
+		}
+		checks = append(checks, portCheck, serviceCheck)
+	}
+	return checks
+}
+
+// Failed returns the number of checks that did not pass
+func Failed(checks []Check) int {
+	count := 0
+	for _, check := range checks {
+		if !check.Passed {
+			count++
+		}
+	}
+	return count
+}
+
+func unique(values []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package selftest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Host is the address every fixture listens on and the target the self-test scans
+const Host = "127.0.0.1"
+
+// Banner identifies fixture responses, so a check can tell them from a real service
+const Banner = "ipcrawler-selftest"
+
+// Fixture is a local service with a known port and service name
+type Fixture struct {
+	Name    string `json:"name"` // e.g. "http"
+	Port    int    `json:"port"`
+	Service string `json:"service"`         // Service name nmap reports for it
+	Error   string `json:"error,omitempty"` // Why the fixture could not start; empty when it is listening
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// Address returns host:port
+func (f *Fixture) Address() string {
+	return net.JoinHostPort(Host, strconv.Itoa(f.Port))
+}
+
+// Running reports whether the fixture is listening
+func (f *Fixture) Running() bool {
+	return f.listener != nil
+}
+
+// Fixtures are the services the self-test exposes. Their ports are the ones naabu's fast_scan
+// mode probes, so the default workflows find them without changes; ports below 1024 only start
+// as root
+var Fixtures = []Fixture{
+	{Name: "ssh", Port: 22, Service: "ssh"},
+	{Name: "http", Port: 80, Service: "http"},
+	{Name: "http-alt", Port: 8080, Service: "http"},
+}
+
+// FixtureSet serves a set of fixtures until Close
+type FixtureSet struct {
+	Fixtures []*Fixture
+	wg       sync.WaitGroup
+}
+
+// StartFixtures starts every fixture it can. A port that is taken or needs root is recorded
+// in the fixture's Error instead of failing the set
+func StartFixtures() *FixtureSet {
+	set := &FixtureSet{}
+	for _, template := range Fixtures {
+		fixture := template
+		set.Fixtures = append(set.Fixtures, &fixture)
+
+		listener, err := net.Listen("tcp", fixture.Address())
+		if err != nil {
+			fixture.Error = err.Error()
+			continue
+		}
+		fixture.listener = listener
+
+		set.wg.Add(1)
+		go func() {
+			defer set.wg.Done()
+			if fixture.Service == "http" {
+				fixture.server = newHTTPServer()
+				fixture.server.Serve(listener)
+				return
+			}
+			serveBanner(listener)
+		}()
+	}
+	return set
+}
+
+// Running returns the fixtures that are listening
+func (s *FixtureSet) Running() []*Fixture {
+	var running []*Fixture
+	for _, fixture := range s.Fixtures {
+		if fixture.Running() {
+			running = append(running, fixture)
+		}
+	}
+	return running
+}
+
+// Close stops every fixture and waits for them to exit
+func (s *FixtureSet) Close() {
+	for _, fixture := range s.Fixtures {
+		if fixture.server != nil {
+			fixture.server.Close()
+		} else if fixture.listener != nil {
+			fixture.listener.Close()
+		}
+	}
+	s.wg.Wait()
+}
+
+// newHTTPServer returns a web server with a recognizable title and Server header
+func newHTTPServer() *http.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", Banner)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<html><head><title>IPCrawler Self-Test</title></head><body>%s</body></html>\n", Banner)
+	})
+	return &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+}
+
+// serveBanner answers every connection like an SSH server: it sends its version line, reads
+// the client's, and closes
+func serveBanner(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(10 * time.Second))
+			fmt.Fprintf(conn, "SSH-2.0-OpenSSH_9.6 %s\r\n", Banner)
+			bufio.NewReader(conn).ReadString('\n')
+		}()
+	}
+}
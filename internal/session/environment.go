@@ -0,0 +1,143 @@
+package session
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// vpnInterfacePrefixes name the tunnel interfaces created by common VPN clients
+// (OpenVPN, WireGuard, macOS utun, PPP)
+var vpnInterfacePrefixes = []string{"tun", "tap", "wg", "utun", "ppp"}
+
+// RunEnvironment records what a run was made with and from where, so its results can be
+// reproduced and attributed long after the fact
+type RunEnvironment struct {
+	IPCrawlerVersion string             `json:"ipcrawler_version"`
+	GitCommit        string             `json:"git_commit,omitempty"` // Revision the binary was built from, "-dirty" if modified
+	GoVersion        string             `json:"go_version"`
+	OS               string             `json:"os"`
+	Arch             string             `json:"arch"`
+	Platform         string             `json:"platform,omitempty"` // Distribution and version, e.g. "kali 2024.2"
+	Kernel           string             `json:"kernel,omitempty"`
+	Hostname         string             `json:"hostname,omitempty"`
+	User             string             `json:"user,omitempty"`
+	Interfaces       []NetworkInterface `json:"interfaces,omitempty"`
+	Tools            map[string]string  `json:"tools,omitempty"`            // Tool name -> reported version
+	WorkflowsSHA256  string             `json:"workflows_sha256,omitempty"` // Embedded workflow bundle, as in `ipcrawler version`
+	ToolsSHA256      string             `json:"tools_sha256,omitempty"`     // tools/*/config.yaml at run time
+}
+
+// NetworkInterface is an active, non-loopback interface and its addresses at run time
+type NetworkInterface struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+	VPN       bool     `json:"vpn,omitempty"` // Tunnel interface: the scan likely left through this VPN endpoint
+}
+
+// CaptureEnvironment collects the host's environment; anything that cannot be read is left empty
+func CaptureEnvironment(version string) *RunEnvironment {
+	env := &RunEnvironment{
+		IPCrawlerVersion: version,
+		GitCommit:        ReadBuildInfo().Revision,
+		GoVersion:        runtime.Version(),
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+	}
+	if platform, _, platformVersion, err := host.PlatformInformation(); err == nil && platform != "" {
+		env.Platform = strings.TrimSpace(platform + " " + platformVersion)
+	}
+	if kernel, err := host.KernelVersion(); err == nil {
+		env.Kernel = kernel
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		env.Hostname = hostname
+	}
+	if current, err := user.Current(); err == nil {
+		env.User = current.Username
+	}
+	env.Interfaces = activeInterfaces()
+	return env
+}
+
+// SetToolVersion records the version a tool reported
+func (e *RunEnvironment) SetToolVersion(tool, version string) {
+	if e.Tools == nil {
+		e.Tools = make(map[string]string)
+	}
+	e.Tools[tool] = version
+}
+
+// BuildInfo is the VCS metadata the Go toolchain embeds in the binary; fields are empty
+// for builds made outside a repository (e.g. go run, or -buildvcs=false)
+type BuildInfo struct {
+	Revision string `json:"commit,omitempty"` // Revision the binary was built from, "-dirty" if modified
+	Time     string `json:"date,omitempty"`   // Commit time, RFC 3339
+	Modified bool   `json:"modified,omitempty"`
+}
+
+// ReadBuildInfo returns the binary's embedded VCS metadata
+func ReadBuildInfo() BuildInfo {
+	var build BuildInfo
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.Time = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	if build.Revision != "" && build.Modified {
+		build.Revision += "-dirty"
+	}
+	return build
+}
+
+// activeInterfaces lists interfaces that are up, skipping loopback
+func activeInterfaces() []NetworkInterface {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	var active []NetworkInterface
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		entry := NetworkInterface{Name: iface.Name, VPN: isVPNInterface(iface)}
+		for _, addr := range addrs {
+			entry.Addresses = append(entry.Addresses, addr.String())
+		}
+		active = append(active, entry)
+	}
+	return active
+}
+
+// isVPNInterface recognizes tunnel interfaces by name or point-to-point flag
+func isVPNInterface(iface net.Interface) bool {
+	if iface.Flags&net.FlagPointToPoint != 0 {
+		return true
+	}
+	for _, prefix := range vpnInterfacePrefixes {
+		if strings.HasPrefix(iface.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
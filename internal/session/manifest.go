@@ -0,0 +1,175 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ManifestFileName is the name of the run manifest written to the workspace root
+const ManifestFileName = "manifest.json"
+
+// Run status values recorded in the manifest
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+
+	// RunStatusInterrupted is a run stopped by SIGINT/SIGTERM; its unfinished
+	// workflows can be continued with --resume-queue
+	RunStatusInterrupted = "interrupted"
+)
+
+// RunManifest describes a single IPCrawler run and the workspace it produced
+type RunManifest struct {
+	ScanID     string   `json:"scan_id"`
+	Version    string   `json:"version"`
+	Target     string   `json:"target"`
+	Workspace  string   `json:"workspace"`
+	OutputMode string   `json:"output_mode"`
+	Workflows  []string `json:"workflows"`
+	Exclusions []string `json:"exclusions,omitempty"`
+	Labels     []string `json:"labels,omitempty"` // Target labels from --label and labeling rules
+
+	// Name given with --session-name, shared by the runs of one engagement
+	SessionName string `json:"session_name,omitempty"`
+
+	// Profile chosen with -p, which selected the workflows by their tags
+	Profile string `json:"profile,omitempty"`
+
+	// Scope file given with --scope, whose hosts are the only ones the run may scan
+	Scope string `json:"scope,omitempty"`
+
+	// Experimental features enabled for the run (see configs/experimental.yaml)
+	Experimental []string `json:"experimental,omitempty"`
+
+	// Build, host and tool versions the run was made with
+	Environment *RunEnvironment `json:"environment,omitempty"`
+
+	// Addresses the target's hostname resolved to, looked up once and shared by every tool
+	ResolvedHosts map[string][]string `json:"resolved_hosts,omitempty"`
+
+	// Effective result combiner options and step parameters keyed by "workflow/step"
+	Combiners  map[string]map[string]string `json:"combiners,omitempty"`
+	Parameters map[string]map[string]string `json:"parameters,omitempty"`
+	RateLimit  int                          `json:"rate_limit,omitempty"` // {{rate_limit}} in packets per second
+	Pacing     string                       `json:"pacing,omitempty"`     // Pacing preset applied to every tool
+	Status     string                       `json:"status"`
+	Error      string                       `json:"error,omitempty"`
+	StartedAt  time.Time                    `json:"started_at"`
+	FinishedAt *time.Time                   `json:"finished_at,omitempty"`
+	ResumedAt  []time.Time                  `json:"resumed_at,omitempty"` // Each --resume-queue of an interrupted run
+
+	// Duration is measured with the monotonic clock, not derived from the timestamps above
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+
+	// Target-local times, present only when an engagement timezone is configured
+	Timezone              string `json:"timezone,omitempty"`
+	StartedAtTargetLocal  string `json:"started_at_target_local,omitempty"`
+	FinishedAtTargetLocal string `json:"finished_at_target_local,omitempty"`
+
+	// Runs combined into this workspace by `ipcrawler merge`, with the files each contributed
+	MergedFrom []MergedRun `json:"merged_from,omitempty"`
+
+	// Pinned workspaces are never removed by the retention policy (`ipcrawler clean pin`)
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// MergedRun is the provenance of one run merged into a consolidated workspace
+type MergedRun struct {
+	ScanID      string          `json:"scan_id"`
+	Target      string          `json:"target"`
+	Workspace   string          `json:"workspace"` // Source workspace at merge time
+	Status      string          `json:"status"`
+	StartedAt   time.Time       `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	Environment *RunEnvironment `json:"environment,omitempty"`
+	Files       []string        `json:"files"` // Workspace-relative paths copied from this run
+}
+
+// SetTargetTimezone records target-local start and finish times for the given timezone
+func (m *RunManifest) SetTargetTimezone(location *time.Location) {
+	if location == nil || location == time.Local {
+		return
+	}
+	m.Timezone = location.String()
+	m.StartedAtTargetLocal = m.StartedAt.In(location).Format(time.RFC3339)
+	if m.FinishedAt != nil {
+		m.FinishedAtTargetLocal = m.FinishedAt.In(location).Format(time.RFC3339)
+	}
+}
+
+// NewScanID generates a random RFC 4122 version 4 UUID identifying a single run
+func NewScanID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand should never fail; fall back to a time-based identifier
+		return fmt.Sprintf("scan-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ShortScanID returns the first segment of a scan ID for use in file and directory names
+func ShortScanID(scanID string) string {
+	if len(scanID) > 8 {
+		return scanID[:8]
+	}
+	return scanID
+}
+
+// sessionNamePattern keeps session names usable in file and directory names on every platform
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.-]{0,63}$`)
+
+// ValidateSessionName checks a --session-name: up to 64 letters, digits, dots and hyphens,
+// starting with a letter or digit. Underscores separate the parts of workspace names
+func ValidateSessionName(name string) error {
+	if !sessionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name '%s': use up to 64 letters, digits, dots and hyphens, starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// SessionFileName prefixes a file name with the session name, when the run has one, so the
+// artifacts of an engagement are easy to pick out
+func SessionFileName(sessionName, name string) string {
+	if sessionName == "" {
+		return name
+	}
+	return sessionName + "_" + name
+}
+
+// WriteManifest writes the run manifest to the workspace root with the given file mode
+func WriteManifest(workspaceDir string, manifest *RunManifest, perm os.FileMode) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	manifestPath := filepath.Join(workspaceDir, ManifestFileName)
+	if err := os.WriteFile(manifestPath, data, perm); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// LoadManifest reads the run manifest from a workspace directory
+func LoadManifest(workspaceDir string) (*RunManifest, error) {
+	data, err := os.ReadFile(filepath.Join(workspaceDir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest RunManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
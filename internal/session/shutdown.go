@@ -0,0 +1,55 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ShutdownFileName is the marker written to the workspace root when a signal stops a run
+const ShutdownFileName = "shutdown.json"
+
+// ShutdownMarker records how a run was stopped, so --resume-queue can tell what it continues
+type ShutdownMarker struct {
+	Signal       string    `json:"signal"`
+	StoppedAt    time.Time `json:"stopped_at"`
+	GraceSeconds float64   `json:"grace_seconds"`           // Time tools had to exit after SIGTERM
+	StoppedTools []string  `json:"stopped_tools,omitempty"` // "tool mode" of each execution that was running
+	Unfinished   int       `json:"unfinished_workflows"`    // Workflows left in the resumable queue
+}
+
+// WriteShutdownMarker writes the shutdown marker to the workspace root with the given file mode
+func WriteShutdownMarker(workspaceDir string, marker *ShutdownMarker, perm os.FileMode) error {
+	data, err := json.MarshalIndent(marker, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shutdown marker: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspaceDir, ShutdownFileName), data, perm); err != nil {
+		return fmt.Errorf("failed to write shutdown marker: %w", err)
+	}
+	return nil
+}
+
+// LoadShutdownMarker reads the shutdown marker of a workspace; it fails if the run was not stopped by a signal
+func LoadShutdownMarker(workspaceDir string) (*ShutdownMarker, error) {
+	data, err := os.ReadFile(filepath.Join(workspaceDir, ShutdownFileName))
+	if err != nil {
+		return nil, err
+	}
+	var marker ShutdownMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("failed to parse shutdown marker: %w", err)
+	}
+	return &marker, nil
+}
+
+// RemoveShutdownMarker deletes the marker once the run is resumed
+func RemoveShutdownMarker(workspaceDir string) error {
+	err := os.Remove(filepath.Join(workspaceDir, ShutdownFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
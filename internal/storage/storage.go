@@ -0,0 +1,156 @@
+// Package storage copies workspaces to and from a storage backend: a local directory (e.g. a
+// shared mount) or an S3-compatible bucket (AWS S3, MinIO), so distributed and serve-mode
+// deployments keep their artifacts in one place and reports can be built from remote runs
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backend stores files under slash-separated keys
+type Backend interface {
+	// Put stores size bytes of body under key, replacing any previous object
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+	// Get opens the object stored under key; ErrNotFound when there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the objects whose keys start with prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// URL names a key for people, e.g. s3://bucket/key
+	URL(key string) string
+}
+
+// Object is one stored file
+type Object struct {
+	Key      string
+	Size     int64
+	Modified time.Time
+}
+
+// ErrNotFound is returned by Get for keys without an object
+var ErrNotFound = errors.New("object not found")
+
+// UploadDir stores every regular file below dir under prefix + its slash-separated path
+// relative to dir, and returns the number of files stored. Symlinks (the latest-scan links)
+// and unfinished .tmp files are skipped
+func UploadDir(ctx context.Context, backend Backend, dir, prefix string) (int, error) {
+	uploaded := 0
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || strings.HasSuffix(file, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		if err := UploadFile(ctx, backend, file, JoinKey(prefix, filepath.ToSlash(rel))); err != nil {
+			return err
+		}
+		uploaded++
+		return nil
+	})
+	return uploaded, err
+}
+
+// UploadFile stores one file under key
+func UploadFile(ctx context.Context, backend Backend, file, key string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := backend.Put(ctx, key, f, info.Size()); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", file, err)
+	}
+	return nil
+}
+
+// DownloadDir writes every object below prefix into dir, keeping the key structure under the
+// prefix, and returns the number of files written. Keys that would land outside dir are refused
+func DownloadDir(ctx context.Context, backend Backend, prefix, dir string, dirPerm, filePerm os.FileMode) (int, error) {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	objects, err := backend.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, fmt.Errorf("%s: %w", backend.URL(prefix), ErrNotFound)
+	}
+	downloaded := 0
+	for _, object := range objects {
+		rel := strings.TrimPrefix(object.Key, prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			continue // Directory markers some tools create
+		}
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return downloaded, fmt.Errorf("refusing to download %s outside %s", object.Key, dir)
+		}
+		if err := downloadFile(ctx, backend, object.Key, filepath.Join(dir, filepath.FromSlash(rel)), dirPerm, filePerm); err != nil {
+			return downloaded, err
+		}
+		downloaded++
+	}
+	return downloaded, nil
+}
+
+func downloadFile(ctx context.Context, backend Backend, key, target string, dirPerm, filePerm os.FileMode) error {
+	body, err := backend.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", backend.URL(key), err)
+	}
+	defer body.Close()
+	if err := os.MkdirAll(filepath.Dir(target), dirPerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", backend.URL(key), err)
+	}
+	return nil
+}
+
+// JoinKey joins key parts with single slashes
+func JoinKey(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.Trim(part, "/"); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "/")
+}
+
+// ParseS3URL splits an s3://bucket/key URL; ok is false for anything else
+func ParseS3URL(raw string) (bucket, key string, ok bool) {
+	rest, found := strings.CutPrefix(raw, "s3://")
+	if !found {
+		return "", "", false
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", false
+	}
+	return bucket, strings.Trim(path.Clean("/"+key), "/"), true
+}
@@ -0,0 +1,23 @@
+package amass
+
+import (
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// FindingsExtractor turns amass output into host findings
+type FindingsExtractor struct{}
+
+// GetToolName returns the tool name for registration
+func (e *FindingsExtractor) GetToolName() string {
+	return "amass"
+}
+
+// ExtractFindings returns one host finding per subdomain
+func (e *FindingsExtractor) ExtractFindings(outputPath string) ([]findings.Finding, error) {
+	records, err := ReadRecords(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	return subdomains.HostFindings(records), nil
+}
@@ -0,0 +1,37 @@
+package amass
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// OutputParser handles amass-specific output parsing
+// This is ISOLATED tool-specific code that implements the ToolOutputParser interface
+type OutputParser struct{}
+
+// GetToolName returns the tool name for registration
+func (p *OutputParser) GetToolName() string {
+	return "amass"
+}
+
+// ParseOutput extracts the subdomains amass found. The variables are published as
+// {{amass_<name>}}
+func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
+	records, err := ReadRecords(outputPath)
+	if err != nil {
+		return map[string]string{
+			"subdomains":      "",
+			"subdomain_count": "0",
+			"error":           "failed to read output file",
+		}
+	}
+
+	return map[string]string{
+		"subdomains":      strings.Join(subdomains.Names(records), ","),
+		"subdomain_count": strconv.Itoa(len(records)),
+		"ips":             strings.Join(subdomains.Addresses(records), ","),
+		"sources":         strings.Join(subdomains.Sources(records), ","),
+	}
+}
@@ -0,0 +1,121 @@
+package amass
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// jsonResult is one line of amass v3 -json output
+type jsonResult struct {
+	Name      string   `json:"name"`
+	Domain    string   `json:"domain"`
+	Sources   []string `json:"sources"`
+	Addresses []struct {
+		IP string `json:"ip"`
+	} `json:"addresses"`
+}
+
+// ReadRecords reads amass enum output in any of its formats: v4 relationship lines
+// ("www.example.com (FQDN) --> a_record --> 192.0.2.1 (IPAddress)"), v3 JSON lines (-json)
+// and plain names. Invalid lines are skipped
+//
+// In v4 output, names linked to the enumerated domain by "node" edges are its subdomains; other
+// FQDNs (nameservers, CNAME targets) are kept only when no node edges exist
+func ReadRecords(outputPath string) ([]subdomains.Record, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+
+	var records []subdomains.Record
+	graph := newGraph()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "{"):
+			var entry jsonResult
+			if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Name == "" {
+				continue
+			}
+			record := subdomains.Record{Name: entry.Name, Domain: entry.Domain, Sources: entry.Sources}
+			for _, address := range entry.Addresses {
+				record.Addresses = append(record.Addresses, address.IP)
+			}
+			records = append(records, record)
+		case strings.Contains(line, " --> "):
+			graph.add(line)
+		case subdomains.IsHostname(line):
+			records = append(records, subdomains.Record{Name: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	return subdomains.Merge(append(records, graph.records()...)), nil
+}
+
+// graph collects the FQDNs and addresses of amass v4 relationship lines
+type graph struct {
+	fqdns     []string
+	nodes     map[string]bool     // Names on either side of a "node" edge
+	addresses map[string][]string // FQDN -> a_record/aaaa_record targets
+}
+
+func newGraph() *graph {
+	return &graph{nodes: make(map[string]bool), addresses: make(map[string][]string)}
+}
+
+// add records one "<value> (<type>) --> <relation> --> <value> (<type>)" line
+func (g *graph) add(line string) {
+	parts := strings.Split(line, " --> ")
+	if len(parts) != 3 {
+		return
+	}
+	from, fromType := parseAsset(parts[0])
+	to, toType := parseAsset(parts[2])
+	relation := strings.TrimSpace(parts[1])
+
+	for _, asset := range [][2]string{{from, fromType}, {to, toType}} {
+		if asset[1] == "FQDN" && asset[0] != "" {
+			g.fqdns = append(g.fqdns, asset[0])
+		}
+	}
+	switch {
+	case relation == "node" && fromType == "FQDN" && toType == "FQDN":
+		g.nodes[from], g.nodes[to] = true, true
+	case (relation == "a_record" || relation == "aaaa_record") && fromType == "FQDN" && toType == "IPAddress":
+		g.addresses[from] = append(g.addresses[from], to)
+	}
+}
+
+// records returns the subdomains in the graph
+func (g *graph) records() []subdomains.Record {
+	var records []subdomains.Record
+	for _, fqdn := range g.fqdns {
+		if len(g.nodes) > 0 && !g.nodes[fqdn] {
+			continue
+		}
+		records = append(records, subdomains.Record{Name: fqdn, Addresses: g.addresses[fqdn]})
+	}
+	return records
+}
+
+// parseAsset splits "www.example.com (FQDN)" into its value and type
+func parseAsset(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	open := strings.LastIndex(text, " (")
+	if open < 0 || !strings.HasSuffix(text, ")") {
+		return subdomains.NormalizeName(text), ""
+	}
+	return subdomains.NormalizeName(text[:open]), text[open+2 : len(text)-1]
+}
@@ -0,0 +1,43 @@
+package httpx
+
+import (
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// FindingsExtractor turns httpx JSON lines output into web service findings
+type FindingsExtractor struct{}
+
+// GetToolName returns the tool name for registration
+func (e *FindingsExtractor) GetToolName() string {
+	return "httpx"
+}
+
+// ExtractFindings returns one finding per URL that answered; invalid lines are skipped
+func (e *FindingsExtractor) ExtractFindings(outputPath string) ([]findings.Finding, error) {
+	results, err := ReadResults(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]findings.Finding, 0, len(results))
+	for _, result := range results {
+		service := "http"
+		if result.TLS() {
+			service = "https"
+		}
+		found = append(found, findings.Finding{
+			Host:         result.Hostname(),
+			Port:         result.Port(),
+			Protocol:     "tcp",
+			State:        "open",
+			Service:      service,
+			Product:      result.WebServer,
+			TLS:          result.TLS(),
+			URL:          result.URL,
+			StatusCode:   result.StatusCode,
+			Title:        result.Title,
+			Technologies: result.Technologies,
+		})
+	}
+	return found, nil
+}
@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OutputParser handles httpx-specific output parsing
+// This is ISOLATED tool-specific code that implements the ToolOutputParser interface
+type OutputParser struct{}
+
+// GetToolName returns the tool name for registration
+func (p *OutputParser) GetToolName() string {
+	return "httpx"
+}
+
+// ParseOutput extracts live URLs, status codes and detected technologies from httpx JSON
+// lines output. The variables are published as {{httpx_<name>}}
+func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
+	results, err := ReadResults(outputPath)
+	if err != nil {
+		return map[string]string{
+			"live_urls":      "",
+			"live_url_count": "0",
+			"error":          "failed to read output file",
+		}
+	}
+	return webVariables(results, "")
+}
+
+// webVariables builds the variables for a set of results, each name prefixed with prefix
+func webVariables(results []Result, prefix string) map[string]string {
+	var liveURLs, okURLs, httpsURLs, statusCodes, technologies, webServers, titles, hosts, ports []string
+	for _, result := range results {
+		// Every URL httpx reports answered; a 404 at the root is still a web server to enumerate
+		liveURLs = appendUnique(liveURLs, result.URL)
+		if result.StatusCode > 0 && result.StatusCode < 400 {
+			okURLs = appendUnique(okURLs, result.URL)
+		}
+		if result.TLS() {
+			httpsURLs = appendUnique(httpsURLs, result.URL)
+		}
+		if result.StatusCode > 0 {
+			statusCodes = appendUnique(statusCodes, strconv.Itoa(result.StatusCode))
+		}
+		technologies = appendUnique(technologies, result.Technologies...)
+		webServers = appendUnique(webServers, result.WebServer)
+		titles = appendUnique(titles, strings.ReplaceAll(result.Title, ",", ""))
+		hosts = appendUnique(hosts, result.Hostname())
+		ports = appendUnique(ports, strconv.Itoa(result.Port()))
+	}
+
+	return map[string]string{
+		prefix + "live_urls":        strings.Join(liveURLs, ","),
+		prefix + "live_url_count":   strconv.Itoa(len(liveURLs)),
+		prefix + "ok_urls":          strings.Join(okURLs, ","), // 2xx and 3xx only
+		prefix + "https_urls":       strings.Join(httpsURLs, ","),
+		prefix + "status_codes":     strings.Join(statusCodes, ","),
+		prefix + "technologies":     strings.Join(technologies, ","),
+		prefix + "technology_count": strconv.Itoa(len(technologies)),
+		prefix + "web_servers":      strings.Join(webServers, ","),
+		prefix + "titles":           strings.Join(titles, ","),
+		prefix + "hosts":            strings.Join(hosts, ","),
+		prefix + "ports":            strings.Join(ports, ","),
+	}
+}
+
+// appendUnique appends the values not already in list, skipping empty ones
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := value == ""
+		for _, existing := range list {
+			if existing == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,51 @@
+package httpx
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ResultCombiner merges the results of several httpx modes into the live_http_* variables
+// that web enumeration steps consume, mirroring combined_ports from the port scanners
+type ResultCombiner struct{}
+
+// GetToolName returns the tool name for registration
+func (rc *ResultCombiner) GetToolName() string {
+	return "httpx"
+}
+
+// ValidateCombinerOptions rejects combiner options; the httpx combiner has none
+func ValidateCombinerOptions(raw map[string]string) error {
+	for key := range raw {
+		return fmt.Errorf("unsupported httpx combiner option: %s", key)
+	}
+	return nil
+}
+
+// CombineResults merges httpx JSON lines files. Besides {{live_http_urls}} and the other
+// http_* variables it sets {{combined_web_ports}}, the ports that served HTTP
+func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string {
+	var results []Result
+	seen := make(map[string]bool)
+	for _, outputPath := range outputPaths {
+		fileResults, err := ReadResults(outputPath)
+		if err != nil {
+			continue // Skip files that can't be read
+		}
+		for _, result := range fileResults {
+			if !seen[result.URL] {
+				seen[result.URL] = true
+				results = append(results, result)
+			}
+		}
+	}
+
+	vars := webVariables(results, "http_")
+	vars["live_http_urls"] = vars["http_live_urls"]
+	vars["live_http_url_count"] = vars["http_live_url_count"]
+	vars["combined_web_ports"] = vars["http_ports"]
+	vars["combined_scan_count"] = strconv.Itoa(len(outputPaths))
+	delete(vars, "http_live_urls")
+	delete(vars, "http_live_url_count")
+	return vars
+}
@@ -0,0 +1,93 @@
+package httpx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Result is one probed URL from httpx -json output. Only the fields IPCrawler uses are
+// decoded; the port is taken from the URL because httpx releases disagree on its JSON type
+type Result struct {
+	URL          string   `json:"url"`
+	Input        string   `json:"input"`
+	Host         string   `json:"host"`
+	Scheme       string   `json:"scheme"`
+	StatusCode   int      `json:"status_code"`
+	Title        string   `json:"title"`
+	WebServer    string   `json:"webserver"`
+	Technologies []string `json:"tech"`
+	Location     string   `json:"location"`
+	Failed       bool     `json:"failed"`
+}
+
+// Port returns the URL's port, or the scheme's default port
+func (r Result) Port() int {
+	parsed, err := url.Parse(r.URL)
+	if err != nil {
+		return 0
+	}
+	if port, err := strconv.Atoi(parsed.Port()); err == nil {
+		return port
+	}
+	if strings.EqualFold(parsed.Scheme, "https") {
+		return 443
+	}
+	return 80
+}
+
+// Hostname returns the host the URL was probed on, without the port
+func (r Result) Hostname() string {
+	if r.Host != "" {
+		return r.Host
+	}
+	parsed, err := url.Parse(r.URL)
+	if err != nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(parsed.Host); err == nil {
+		return host
+	}
+	return parsed.Host
+}
+
+// TLS reports whether the URL was served over HTTPS
+func (r Result) TLS() bool {
+	return strings.EqualFold(r.Scheme, "https") || strings.HasPrefix(strings.ToLower(r.URL), "https://")
+}
+
+// ReadResults returns the URLs that answered in an httpx -json file. Probes that failed
+// (written with -probe) and invalid lines are skipped
+func ReadResults(outputPath string) ([]Result, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+
+	var results []Result
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024) // Lines can carry response headers
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil || result.URL == "" || result.Failed {
+			continue
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	return results, nil
+}
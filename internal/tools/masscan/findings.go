@@ -0,0 +1,32 @@
+package masscan
+
+import (
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// FindingsExtractor turns masscan -oJ output into open port findings
+type FindingsExtractor struct{}
+
+// GetToolName returns the tool name for registration
+func (e *FindingsExtractor) GetToolName() string {
+	return "masscan"
+}
+
+// ExtractFindings returns one finding per reported open port; invalid lines are skipped
+func (e *FindingsExtractor) ExtractFindings(outputPath string) ([]findings.Finding, error) {
+	results, err := ReadOpenPorts(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]findings.Finding, 0, len(results))
+	for _, result := range results {
+		found = append(found, findings.Finding{
+			Host:     result.IP,
+			Port:     result.Port,
+			Protocol: result.Protocol,
+			State:    "open",
+		})
+	}
+	return found, nil
+}
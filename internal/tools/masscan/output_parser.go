@@ -0,0 +1,69 @@
+package masscan
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OutputParser handles masscan-specific output parsing
+// This is ISOLATED tool-specific code that implements the ToolOutputParser interface
+type OutputParser struct{}
+
+// GetToolName returns the tool name for registration
+func (p *OutputParser) GetToolName() string {
+	return "masscan"
+}
+
+// ParseOutput extracts open ports and hosts from masscan -oJ output as magic variables.
+// Unlike naabu there is no fallback port list: masscan is used for sweeps, where an empty
+// result means nothing answered and later steps should be skipped with run_if
+func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
+	results, err := ReadOpenPorts(outputPath)
+	if err != nil {
+		return map[string]string{
+			"ports":      "",
+			"port_count": "0",
+			"error":      "failed to read output file",
+		}
+	}
+
+	var ports, hosts, tcpPorts, udpPorts []string
+	for _, result := range results {
+		port := strconv.Itoa(result.Port)
+		ports = append(ports, port)
+		hosts = appendUnique(hosts, result.IP)
+		switch result.Protocol {
+		case "tcp":
+			tcpPorts = appendUnique(tcpPorts, port)
+		case "udp":
+			udpPorts = appendUnique(udpPorts, port)
+		}
+	}
+
+	return map[string]string{
+		"ports":        strings.Join(appendUnique(nil, ports...), ","),
+		"port_count":   strconv.Itoa(len(ports)),
+		"unique_ports": strings.Join(appendUnique(nil, ports...), ","),
+		"hosts":        strings.Join(hosts, ","),
+		"host_count":   strconv.Itoa(len(hosts)),
+		"tcp_ports":    strings.Join(tcpPorts, ","),
+		"udp_ports":    strings.Join(udpPorts, ","),
+	}
+}
+
+// appendUnique appends the values not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := false
+		for _, existing := range list {
+			if existing == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
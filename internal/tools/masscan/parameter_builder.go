@@ -0,0 +1,32 @@
+package masscan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParameterBuilder translates generic workflow step parameters into masscan flags
+// This is ISOLATED tool-specific code that implements the ToolParameterBuilder interface
+type ParameterBuilder struct{}
+
+// GetToolName returns the tool name for registration
+func (b *ParameterBuilder) GetToolName() string {
+	return "masscan"
+}
+
+// BuildArguments converts step parameters into masscan flags
+// masscan does not support any workflow parameters yet; its rate comes from {{rate_limit}}
+func (b *ParameterBuilder) BuildArguments(params map[string]string) ([]string, error) {
+	for key := range params {
+		return nil, fmt.Errorf("unsupported masscan parameter: %s", key)
+	}
+	return nil, nil
+}
+
+// ExclusionArguments returns the masscan flags that skip out-of-scope hosts
+func (b *ParameterBuilder) ExclusionArguments(entries []string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	return []string{"--exclude", strings.Join(entries, ",")}
+}
@@ -0,0 +1,126 @@
+package masscan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/tools/merge"
+)
+
+// ResultCombiner handles combining results from multiple masscan scan modes
+// This is ISOLATED tool-specific code for masscan result consolidation
+type ResultCombiner struct{}
+
+// GetToolName returns the tool name for registration
+func (rc *ResultCombiner) GetToolName() string {
+	return "masscan"
+}
+
+// CombineResults merges multiple masscan -oJ output files using the default combiner options
+func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string {
+	return rc.CombineResultsWithOptions(outputPaths, merge.DefaultOptions())
+}
+
+// CombineResultsWithOptions merges multiple masscan -oJ output files into the same combined_*
+// magic variables as the naabu combiner, so nmap pipeline modes can follow either scanner
+func (rc *ResultCombiner) CombineResultsWithOptions(outputPaths []string, opts merge.Options) map[string]string {
+	if len(outputPaths) == 0 {
+		return map[string]string{
+			"combined_ports":      "",
+			"combined_port_count": "0",
+			"error":               "no output files provided",
+		}
+	}
+
+	// Track which modes found each port (or host:port, depending on dedupe rule)
+	var allResults []OpenPort
+	coverage := make(map[string]map[string]bool)
+	for i, outputPath := range outputPaths {
+		results, err := ReadOpenPorts(outputPath)
+		if err != nil {
+			continue // Skip files that can't be read
+		}
+		sourceMode := fmt.Sprintf("mode_%d", i+1)
+		for _, result := range results {
+			key := coverageKey(result, opts.DedupeBy)
+			if coverage[key] == nil {
+				coverage[key] = make(map[string]bool)
+			}
+			coverage[key][sourceMode] = true
+		}
+		allResults = append(allResults, results...)
+	}
+
+	// A port's agreement is the highest number of distinct modes that found it
+	agreement := make(map[string]int)
+	for _, result := range allResults {
+		port := strconv.Itoa(result.Port)
+		if modes := len(coverage[coverageKey(result, opts.DedupeBy)]); modes > agreement[port] {
+			agreement[port] = modes
+		}
+	}
+
+	// Deduplicate and categorize results, dropping ports below the minimum mode agreement.
+	// A single mode cannot agree with itself, so the minimum only applies to several modes
+	var ports, hosts, tcpPorts, udpPorts []string
+	for _, result := range allResults {
+		port := strconv.Itoa(result.Port)
+		if len(outputPaths) > 1 && agreement[port] < opts.MinModeAgreement {
+			continue
+		}
+		ports = appendUnique(ports, port)
+		hosts = appendUnique(hosts, result.IP)
+		switch result.Protocol {
+		case "tcp":
+			tcpPorts = appendUnique(tcpPorts, port)
+		case "udp":
+			udpPorts = appendUnique(udpPorts, port)
+		}
+	}
+
+	// Calculate coverage statistics
+	var highCoveragePorts []string // Found by at least the high coverage threshold of modes
+	var uniqueDiscoveries []string // Found by only one mode
+	for _, port := range ports {
+		if agreement[port] >= opts.HighCoverageThreshold {
+			highCoveragePorts = append(highCoveragePorts, port)
+		}
+		if agreement[port] == 1 {
+			uniqueDiscoveries = append(uniqueDiscoveries, port)
+		}
+	}
+
+	return map[string]string{
+		// Core combined results
+		"combined_ports":        strings.Join(ports, ","),
+		"combined_port_count":   strconv.Itoa(len(ports)),
+		"combined_unique_ports": strings.Join(ports, ","),
+		"combined_hosts":        strings.Join(hosts, ","),
+		"combined_host_count":   strconv.Itoa(len(hosts)),
+
+		// Protocol-specific results
+		"combined_tcp_ports":      strings.Join(tcpPorts, ","),
+		"combined_tcp_port_count": strconv.Itoa(len(tcpPorts)),
+		"combined_udp_ports":      strings.Join(udpPorts, ","),
+		"combined_udp_port_count": strconv.Itoa(len(udpPorts)),
+
+		// Coverage analysis
+		"combined_high_coverage_ports":    strings.Join(highCoveragePorts, ","),
+		"combined_high_coverage_count":    strconv.Itoa(len(highCoveragePorts)),
+		"combined_unique_discoveries":     strings.Join(uniqueDiscoveries, ","),
+		"combined_unique_discovery_count": strconv.Itoa(len(uniqueDiscoveries)),
+
+		// Scan statistics
+		"combined_scan_count":    strconv.Itoa(len(outputPaths)),
+		"combined_total_results": strconv.Itoa(len(allResults)),
+	}
+}
+
+// coverageKey returns the key used to count mode agreement for a result
+func coverageKey(result OpenPort, dedupeBy string) string {
+	if dedupeBy == "host_port" {
+		return fmt.Sprintf("%s:%d", result.IP, result.Port)
+	}
+	return strconv.Itoa(result.Port)
+}
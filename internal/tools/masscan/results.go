@@ -0,0 +1,75 @@
+package masscan
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Result is one host entry of masscan -oJ output
+type Result struct {
+	IP    string       `json:"ip"`
+	Ports []PortResult `json:"ports"`
+}
+
+// PortResult is one port reported for a host
+type PortResult struct {
+	Port   int    `json:"port"`
+	Proto  string `json:"proto"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	TTL    int    `json:"ttl"`
+}
+
+// OpenPort is one open port on one host
+type OpenPort struct {
+	IP       string
+	Port     int
+	Protocol string
+}
+
+// ReadOpenPorts returns the open ports in a masscan -oJ file. masscan writes a JSON array with
+// one host per line, and releases before 1.3 leave a trailing comma before the closing bracket,
+// so the file is read line by line rather than decoded as a whole. A scan cut off by a timeout
+// has no closing bracket and still yields every line masscan wrote
+func ReadOpenPorts(outputPath string) ([]OpenPort, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+
+	var ports []OpenPort
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(line, "["))
+		line = strings.TrimSpace(strings.TrimSuffix(line, ","))
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil || result.IP == "" {
+			continue // Skip invalid lines and the {finished: 1} trailer of old releases
+		}
+		for _, port := range result.Ports {
+			if port.Status != "" && port.Status != "open" {
+				continue
+			}
+			protocol := strings.ToLower(port.Proto)
+			if protocol == "" {
+				protocol = "tcp"
+			}
+			ports = append(ports, OpenPort{IP: result.IP, Port: port.Port, Protocol: protocol})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	return ports, nil
+}
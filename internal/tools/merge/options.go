@@ -0,0 +1,69 @@
+// Package merge holds the options shared by the result combiners that merge the runs of one
+// tool in several scan modes (naabu, nmap and masscan)
+package merge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Options controls how results from multiple scan modes are merged
+// Values come from the "combiner" block of a workflow step
+type Options struct {
+	HighCoverageThreshold int    // Distinct modes that must find a port or service for it to count as high coverage
+	MinModeAgreement      int    // Ports or services found by fewer distinct modes are dropped from combined results
+	DedupeBy              string // "port" counts agreement per port/protocol, "host_port" per host as well
+}
+
+// DefaultOptions returns the options used when a workflow does not configure the combiner
+func DefaultOptions() Options {
+	return Options{
+		HighCoverageThreshold: 2,
+		MinModeAgreement:      1,
+		DedupeBy:              "port",
+	}
+}
+
+// ParseOptions builds options from the workflow YAML values of a step running tool, applying defaults
+func ParseOptions(tool string, raw map[string]string) (Options, error) {
+	opts := DefaultOptions()
+
+	for key, value := range raw {
+		value = strings.TrimSpace(value)
+		switch key {
+		case "high_coverage_threshold":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("invalid high_coverage_threshold '%s': must be a positive integer", value)
+			}
+			opts.HighCoverageThreshold = n
+		case "min_mode_agreement":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("invalid min_mode_agreement '%s': must be a positive integer", value)
+			}
+			opts.MinModeAgreement = n
+		case "dedupe_by":
+			switch strings.ToLower(value) {
+			case "port", "host_port":
+				opts.DedupeBy = strings.ToLower(value)
+			default:
+				return opts, fmt.Errorf("invalid dedupe_by '%s': must be port or host_port", value)
+			}
+		default:
+			return opts, fmt.Errorf("unsupported %s combiner option: %s", tool, key)
+		}
+	}
+
+	return opts, nil
+}
+
+// AsMap returns the effective options for recording in the run manifest
+func (o Options) AsMap() map[string]string {
+	return map[string]string{
+		"high_coverage_threshold": strconv.Itoa(o.HighCoverageThreshold),
+		"min_mode_agreement":      strconv.Itoa(o.MinModeAgreement),
+		"dedupe_by":               o.DedupeBy,
+	}
+}
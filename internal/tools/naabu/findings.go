@@ -0,0 +1,60 @@
+package naabu
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// FindingsExtractor turns naabu JSON lines output into open port findings
+type FindingsExtractor struct{}
+
+// GetToolName returns the tool name for registration
+func (e *FindingsExtractor) GetToolName() string {
+	return "naabu"
+}
+
+// ExtractFindings returns one finding per reported open port; invalid lines are skipped
+func (e *FindingsExtractor) ExtractFindings(outputPath string) ([]findings.Finding, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+
+	var results []findings.Finding
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var result NaabuResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			continue
+		}
+
+		protocol := strings.ToLower(result.Protocol)
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		results = append(results, findings.Finding{
+			Host:     result.IP,
+			Port:     result.Port,
+			Protocol: protocol,
+			State:    "open",
+			TLS:      result.TLS,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,32 @@
+package naabu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParameterBuilder translates generic workflow step parameters into naabu flags
+// This is ISOLATED tool-specific code that implements the ToolParameterBuilder interface
+type ParameterBuilder struct{}
+
+// GetToolName returns the tool name for registration
+func (b *ParameterBuilder) GetToolName() string {
+	return "naabu"
+}
+
+// BuildArguments converts step parameters into naabu flags
+// naabu does not support any workflow parameters yet
+func (b *ParameterBuilder) BuildArguments(params map[string]string) ([]string, error) {
+	for key := range params {
+		return nil, fmt.Errorf("unsupported naabu parameter: %s", key)
+	}
+	return nil, nil
+}
+
+// ExclusionArguments returns the naabu flags that skip out-of-scope hosts
+func (b *ParameterBuilder) ExclusionArguments(entries []string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	return []string{"-exclude-hosts", strings.Join(entries, ",")}
+}
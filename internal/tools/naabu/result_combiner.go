@@ -6,15 +6,22 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/tools/merge"
 )
 
 // ResultCombiner handles combining results from multiple naabu scan modes
 // This is ISOLATED tool-specific code for naabu result consolidation
 type ResultCombiner struct{}
 
-// CombineResults merges multiple naabu JSON output files into consolidated magic variables
-// This method contains ALL naabu-specific combining logic
+// CombineResults merges multiple naabu JSON output files using the default combiner options
 func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string {
+	return rc.CombineResultsWithOptions(outputPaths, merge.DefaultOptions())
+}
+
+// CombineResultsWithOptions merges multiple naabu JSON output files into consolidated magic variables
+// This method contains ALL naabu-specific combining logic
+func (rc *ResultCombiner) CombineResultsWithOptions(outputPaths []string, opts merge.Options) map[string]string {
 	if len(outputPaths) == 0 {
 		return map[string]string{
 			"combined_ports":      "",
@@ -23,7 +30,7 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 		}
 	}
 
-	// If only one file, parse it normally
+	// If only one file, parse it normally (mode agreement needs more than one mode)
 	if len(outputPaths) == 1 {
 		parser := &OutputParser{}
 		vars := parser.ParseOutput(outputPaths[0])
@@ -38,8 +45,8 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 
 	// Parse all files and collect results
 	var allResults []NaabuResult
+	var resultModes []string // Source mode for each entry in allResults
 	hosts := make(map[string]bool)
-	sources := make(map[string]string) // Track which file each port came from
 
 	for i, outputPath := range outputPaths {
 		data, err := os.ReadFile(outputPath)
@@ -47,6 +54,8 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 			continue // Skip files that can't be read
 		}
 
+		sourceMode := fmt.Sprintf("mode_%d", i+1)
+
 		// Parse JSONL format
 		lines := strings.Split(string(data), "\n")
 		for _, line := range lines {
@@ -61,37 +70,48 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 			}
 
 			allResults = append(allResults, result)
+			resultModes = append(resultModes, sourceMode)
 			hosts[result.IP] = true
-			
-			// Track source of this port discovery
-			portKey := fmt.Sprintf("%s:%d", result.IP, result.Port)
-			if _, exists := sources[portKey]; !exists {
-				sources[portKey] = fmt.Sprintf("mode_%d", i+1)
-			}
 		}
 	}
 
-	// Deduplicate and categorize results
+	// Track which modes found each port (or host:port, depending on dedupe rule)
+	coverage := make(map[string]map[string]bool)
+	for i, result := range allResults {
+		key := coverageKey(result, opts.DedupeBy)
+		if coverage[key] == nil {
+			coverage[key] = make(map[string]bool)
+		}
+		coverage[key][resultModes[i]] = true
+	}
+
+	// A port's agreement is the highest number of distinct modes that found it
+	agreement := make(map[string]int)
+	for _, result := range allResults {
+		portStr := strconv.Itoa(result.Port)
+		if modes := len(coverage[coverageKey(result, opts.DedupeBy)]); modes > agreement[portStr] {
+			agreement[portStr] = modes
+		}
+	}
+
+	// Deduplicate and categorize results, dropping ports below the minimum mode agreement
 	uniquePorts := make(map[string]bool)
 	var ports []string
 	var tlsPorts []string
 	var tcpPorts []string
 	var udpPorts []string
-	coverage := make(map[string][]string) // port -> list of modes that found it
 
 	for _, result := range allResults {
 		portStr := strconv.Itoa(result.Port)
-		portKey := fmt.Sprintf("%s:%d", result.IP, result.Port)
+		if agreement[portStr] < opts.MinModeAgreement {
+			continue
+		}
 		
 		if !uniquePorts[portStr] {
 			uniquePorts[portStr] = true
 			ports = append(ports, portStr)
 		}
 
-		// Track which modes found this port
-		sourceMode := sources[portKey]
-		coverage[portStr] = append(coverage[portStr], sourceMode)
-
 		// Categorize by protocol and features
 		switch strings.ToLower(result.Protocol) {
 		case "tcp":
@@ -110,18 +130,14 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 	}
 
 	// Calculate coverage statistics
-	var highCoveragePorts []string      // Found by multiple modes
+	var highCoveragePorts []string      // Found by at least the high coverage threshold of modes
 	var uniqueDiscoveries []string      // Found by only one mode
 	
-	for port, modes := range coverage {
-		modeSet := make(map[string]bool)
-		for _, mode := range modes {
-			modeSet[mode] = true
-		}
-		
-		if len(modeSet) > 1 {
+	for _, port := range ports {
+		if agreement[port] >= opts.HighCoverageThreshold {
 			highCoveragePorts = append(highCoveragePorts, port)
-		} else {
+		}
+		if agreement[port] == 1 {
 			uniqueDiscoveries = append(uniqueDiscoveries, port)
 		}
 	}
@@ -174,6 +190,14 @@ func (rc *ResultCombiner) GetToolName() string {
 	return "naabu"
 }
 
+// coverageKey returns the key used to count mode agreement for a result
+func coverageKey(result NaabuResult, dedupeBy string) string {
+	if dedupeBy == "host_port" {
+		return fmt.Sprintf("%s:%d", result.IP, result.Port)
+	}
+	return strconv.Itoa(result.Port)
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,12 @@
+package nmap
+
+import "strings"
+
+// ExclusionArguments returns the nmap flags that skip out-of-scope hosts
+// Entries may be IPs, CIDR ranges, or hostnames
+func (b *ParameterBuilder) ExclusionArguments(entries []string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	return []string{"--exclude", strings.Join(entries, ",")}
+}
@@ -0,0 +1,76 @@
+package nmap
+
+import (
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// FindingsExtractor turns nmap XML output into host/port findings
+type FindingsExtractor struct{}
+
+// GetToolName returns the tool name for registration
+func (e *FindingsExtractor) GetToolName() string {
+	return "nmap"
+}
+
+// ExtractFindings returns one finding per scanned port, plus one per host without ports or
+// with host script results
+func (e *FindingsExtractor) ExtractFindings(outputPath string) ([]findings.Finding, error) {
+	nmapRun, err := ParseFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []findings.Finding
+	for _, host := range nmapRun.Hosts {
+		address := host.Address()
+		if address == "" {
+			continue
+		}
+		hostnames, osMatch := host.Names(), host.BestOS()
+
+		// Host-level script results (e.g. smb-os-discovery) get a host finding of their own
+		hostScripts := scriptResults(host.Scripts)
+		if len(host.Ports.Ports) == 0 || len(hostScripts) > 0 {
+			results = append(results, findings.Finding{
+				Host:      address,
+				Hostnames: hostnames,
+				State:     strings.ToLower(host.Status.State),
+				OS:        osMatch,
+				Scripts:   hostScripts,
+			})
+		}
+
+		for _, port := range host.Ports.Ports {
+			results = append(results, findings.Finding{
+				Host:      address,
+				Hostnames: hostnames,
+				Port:      port.PortID,
+				Protocol:  strings.ToLower(port.Protocol),
+				State:     strings.ToLower(port.State.State),
+				Service:   port.Service.Name,
+				Product:   port.Service.Product,
+				Version:   port.Service.Version,
+				TLS:       port.Service.TLS(),
+				OS:        osMatch,
+				Scripts:   scriptResults(port.Scripts),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// scriptResults converts NSE results to findings, keeping scripts that printed something
+func scriptResults(scripts []Script) []findings.ScriptResult {
+	var results []findings.ScriptResult
+	for _, script := range scripts {
+		output := strings.TrimRight(strings.TrimLeft(script.Output, "\r\n"), " \t\r\n") // Keep nmap's indentation
+		if script.ID == "" || output == "" {
+			continue
+		}
+		results = append(results, findings.ScriptResult{ID: script.ID, Output: output})
+	}
+	return results
+}
@@ -1,8 +1,6 @@
 package nmap
 
 import (
-	"encoding/xml"
-	"os"
 	"strconv"
 	"strings"
 )
@@ -16,86 +14,16 @@ func (p *OutputParser) GetToolName() string {
 	return "nmap"
 }
 
-// NmapRun represents the root element of nmap XML output
-type NmapRun struct {
-	XMLName xml.Name `xml:"nmaprun"`
-	Hosts   []Host   `xml:"host"`
-	Stats   RunStats `xml:"runstats"`
-}
-
-// Host represents a scanned host
-type Host struct {
-	Addresses []Address `xml:"address"`
-	Ports     Ports     `xml:"ports"`
-	Status    Status    `xml:"status"`
-}
-
-// Address represents host address information
-type Address struct {
-	Addr     string `xml:"addr,attr"`
-	AddrType string `xml:"addrtype,attr"`
-}
-
-// Status represents host status
-type Status struct {
-	State string `xml:"state,attr"`
-}
-
-// Ports represents the ports section
-type Ports struct {
-	Ports []Port `xml:"port"`
-}
-
-// Port represents a single port
-type Port struct {
-	Protocol string  `xml:"protocol,attr"`
-	PortID   int     `xml:"portid,attr"`
-	State    State   `xml:"state"`
-	Service  Service `xml:"service"`
-}
-
-// State represents port state
-type State struct {
-	State string `xml:"state,attr"`
-}
-
-// Service represents service information
-type Service struct {
-	Name    string `xml:"name,attr"`
-	Product string `xml:"product,attr"`
-	Version string `xml:"version,attr"`
-}
-
-// RunStats represents scan statistics
-type RunStats struct {
-	Finished Finished `xml:"finished"`
-}
-
-// Finished represents completion information
-type Finished struct {
-	Time string `xml:"time,attr"`
-}
-
 // ParseOutput extracts useful data from nmap XML output and creates magic variables
 // This method contains ALL nmap-specific logic, isolated from the main executor
 func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
-	// Read the XML output file
-	data, err := os.ReadFile(outputPath)
+	// Parse the XML report; a truncated one still yields the hosts nmap finished
+	nmapRun, err := ParseFile(outputPath)
 	if err != nil {
 		return map[string]string{
 			"ports":        "",
 			"port_count":   "0",
-			"error":        "failed to read output file",
-		}
-	}
-
-	// Parse XML
-	var nmapRun NmapRun
-	if err := xml.Unmarshal(data, &nmapRun); err != nil {
-		return map[string]string{
-			"ports":        "",
-			"port_count":   "0", 
-			"error":        "failed to parse XML",
+			"error":        err.Error(),
 		}
 	}
 
@@ -108,15 +36,31 @@ func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
 	var services []string
 	var products []string
 	hosts := make(map[string]bool)
+	var liveHosts []string
+	var hostnames []string
+	var osMatches []string
+	var scripts []string
+	var cpes []string
 
 	for _, host := range nmapRun.Hosts {
 		// Extract host addresses
 		for _, addr := range host.Addresses {
 			if addr.AddrType == "ipv4" || addr.AddrType == "ipv6" {
 				hosts[addr.Addr] = true
+				if strings.EqualFold(host.Status.State, "up") {
+					liveHosts = append(liveHosts, addr.Addr)
+				}
 			}
 		}
 
+		hostnames = append(hostnames, host.Names()...)
+		if match := host.BestOS(); match != "" {
+			osMatches = append(osMatches, match)
+		}
+		for _, script := range host.Scripts {
+			scripts = append(scripts, script.ID)
+		}
+
 		// Extract port information
 		for _, port := range host.Ports.Ports {
 			portStr := strconv.Itoa(port.PortID)
@@ -146,6 +90,10 @@ func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
 			if port.Service.Product != "" {
 				products = append(products, port.Service.Product)
 			}
+			cpes = append(cpes, port.Service.CPEs...)
+			for _, script := range port.Scripts {
+				scripts = append(scripts, script.ID)
+			}
 		}
 	}
 
@@ -172,6 +120,16 @@ func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
 		"products":         strings.Join(removeDuplicates(products), ","),
 		"hosts":            strings.Join(hostList, ","),
 		"host_count":       strconv.Itoa(len(hostList)),
+		"live_hosts":       strings.Join(removeDuplicates(liveHosts), ","),
+		"live_host_count":  strconv.Itoa(len(removeDuplicates(liveHosts))),
+		"hostnames":        strings.Join(removeDuplicates(hostnames), ","),
+		"os":               strings.Join(removeDuplicates(osMatches), ","),
+		"scripts":          strings.Join(removeDuplicates(scripts), ","),
+		"script_count":     strconv.Itoa(len(removeDuplicates(scripts))),
+		"cpes":             strings.Join(removeDuplicates(cpes), ","),
+	}
+	if nmapRun.Truncated {
+		magicVars["truncated"] = "true" // nmap was stopped before finishing the report
 	}
 
 	// If no open ports found, provide fallback
@@ -1,11 +1,11 @@
 package nmap
 
 import (
-	"encoding/xml"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/tools/merge"
 )
 
 // ResultCombiner handles combining results from multiple nmap scan modes
@@ -23,9 +23,14 @@ type ServiceInfo struct {
 	Sources  []string // Which scan modes found this service
 }
 
-// CombineResults merges multiple nmap XML output files into consolidated magic variables
-// This method contains ALL nmap-specific combining logic
+// CombineResults merges multiple nmap XML output files using the default combiner options
 func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string {
+	return rc.CombineResultsWithOptions(outputPaths, merge.DefaultOptions())
+}
+
+// CombineResultsWithOptions merges multiple nmap XML output files into consolidated magic variables
+// This method contains ALL nmap-specific combining logic
+func (rc *ResultCombiner) CombineResultsWithOptions(outputPaths []string, opts merge.Options) map[string]string {
 	if len(outputPaths) == 0 {
 		return map[string]string{
 			"combined_ports":      "",
@@ -34,7 +39,7 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 		}
 	}
 
-	// If only one file, parse it normally
+	// If only one file, parse it normally (mode agreement needs more than one mode)
 	if len(outputPaths) == 1 {
 		parser := &OutputParser{}
 		vars := parser.ParseOutput(outputPaths[0])
@@ -49,17 +54,12 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 
 	// Parse all files and collect results
 	hosts := make(map[string]bool)
-	services := make(map[string]*ServiceInfo) // port:protocol -> ServiceInfo
+	services := make(map[string]*ServiceInfo) // port:protocol (or host:port:protocol) -> ServiceInfo
 	
 	for i, outputPath := range outputPaths {
-		data, err := os.ReadFile(outputPath)
+		nmapRun, err := ParseFile(outputPath)
 		if err != nil {
-			continue // Skip files that can't be read
-		}
-
-		var nmapRun NmapRun
-		if err := xml.Unmarshal(data, &nmapRun); err != nil {
-			continue // Skip invalid XML files
+			continue // Skip files that can't be read or are not nmap XML
 		}
 
 		sourceMode := fmt.Sprintf("mode_%d", i+1)
@@ -67,15 +67,22 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 		// Process each host
 		for _, host := range nmapRun.Hosts {
 			// Extract host addresses
+			hostAddr := ""
 			for _, addr := range host.Addresses {
 				if addr.AddrType == "ipv4" || addr.AddrType == "ipv6" {
 					hosts[addr.Addr] = true
+					if hostAddr == "" {
+						hostAddr = addr.Addr
+					}
 				}
 			}
 
 			// Process ports and services
 			for _, port := range host.Ports.Ports {
 				key := fmt.Sprintf("%d:%s", port.PortID, port.Protocol)
+				if opts.DedupeBy == "host_port" {
+					key = hostAddr + ":" + key
+				}
 				
 				if existing, exists := services[key]; exists {
 					// Merge information from multiple scans
@@ -120,12 +127,21 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 	var udpPorts []string
 	var serviceNames []string
 	var productNames []string
-	var highConfidenceServices []string  // Found by multiple scans
+	var highConfidenceServices []string  // Found by at least the high coverage threshold of scans
 	var uniqueDiscoveries []string       // Found by only one scan
 
 	for _, svc := range services {
 		portStr := strconv.Itoa(svc.Port)
 		
+		// Count distinct scan modes that found this service
+		uniqueSources := make(map[string]bool)
+		for _, source := range svc.Sources {
+			uniqueSources[source] = true
+		}
+		if len(uniqueSources) < opts.MinModeAgreement {
+			continue
+		}
+		
 		// Categorize by state
 		switch strings.ToLower(svc.State) {
 		case "open":
@@ -153,23 +169,24 @@ func (rc *ResultCombiner) CombineResults(outputPaths []string) map[string]string
 		}
 
 		// Analyze discovery confidence
-		uniqueSources := make(map[string]bool)
-		for _, source := range svc.Sources {
-			uniqueSources[source] = true
-		}
-		
 		serviceDesc := fmt.Sprintf("%d/%s", svc.Port, svc.Protocol)
 		if svc.Service != "" {
 			serviceDesc += fmt.Sprintf("(%s)", svc.Service)
 		}
 		
-		if len(uniqueSources) > 1 {
+		if len(uniqueSources) >= opts.HighCoverageThreshold {
 			highConfidenceServices = append(highConfidenceServices, serviceDesc)
-		} else {
+		}
+		if len(uniqueSources) == 1 {
 			uniqueDiscoveries = append(uniqueDiscoveries, serviceDesc)
 		}
 	}
 
+	// Per-host dedupe can list the same port for several hosts
+	openPorts = removeDuplicates(openPorts)
+	closedPorts = removeDuplicates(closedPorts)
+	filteredPorts = removeDuplicates(filteredPorts)
+
 	// Convert host map to slice
 	var hostList []string
 	for host := range hosts {
@@ -0,0 +1,97 @@
+package nmap
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParameterBuilder translates generic workflow step parameters into nmap flags
+// This is ISOLATED tool-specific code that implements the ToolParameterBuilder interface
+type ParameterBuilder struct{}
+
+// GetToolName returns the tool name for registration
+func (b *ParameterBuilder) GetToolName() string {
+	return "nmap"
+}
+
+// parameterKeys are the step parameters nmap accepts, in the order their flags are emitted
+var parameterKeys = []string{"timing", "decoys", "fragment", "mtu", "source_port"}
+
+// ParameterKeys returns the step parameters nmap accepts
+func (b *ParameterBuilder) ParameterKeys() []string {
+	return append([]string(nil), parameterKeys...)
+}
+
+// timingTemplates maps named nmap timing templates to their numeric level
+var timingTemplates = map[string]int{
+	"paranoid":   0,
+	"sneaky":     1,
+	"polite":     2,
+	"normal":     3,
+	"aggressive": 4,
+	"insane":     5,
+}
+
+// BuildArguments converts stealth parameters (decoys, fragmentation, source port,
+// timing template) into nmap command-line flags
+func (b *ParameterBuilder) BuildArguments(params map[string]string) ([]string, error) {
+	var args []string
+
+	// Process keys in a stable order so the generated command line is reproducible
+	for _, key := range parameterKeys {
+		value, exists := params[key]
+		if !exists || strings.TrimSpace(value) == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "timing":
+			level, err := ParseTimingTemplate(value)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, fmt.Sprintf("-T%d", level))
+		case "decoys":
+			args = append(args, "-D", strings.ReplaceAll(value, " ", ""))
+		case "fragment":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fragment value '%s': must be true or false", value)
+			}
+			if enabled {
+				args = append(args, "-f")
+			}
+		case "mtu":
+			args = append(args, "--mtu", value)
+		case "source_port":
+			args = append(args, "--source-port", value)
+		}
+	}
+
+	// Reject parameters nmap does not understand rather than silently ignoring them
+	for key := range params {
+		if !slices.Contains(parameterKeys, key) {
+			return nil, fmt.Errorf("unsupported nmap parameter: %s", key)
+		}
+	}
+
+	return args, nil
+}
+
+// ParseTimingTemplate converts a timing template (0-5, T0-T5, or a name like "polite") to its level
+func ParseTimingTemplate(value string) (int, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if level, exists := timingTemplates[normalized]; exists {
+		return level, nil
+	}
+
+	normalized = strings.TrimPrefix(normalized, "t")
+	level, err := strconv.Atoi(normalized)
+	if err != nil || level < 0 || level > 5 {
+		return 0, fmt.Errorf("invalid timing template '%s': use 0-5 or paranoid/sneaky/polite/normal/aggressive/insane", value)
+	}
+	return level, nil
+}
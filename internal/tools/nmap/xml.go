@@ -0,0 +1,355 @@
+package nmap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NmapRun is a parsed nmap XML report (-oX)
+type NmapRun struct {
+	XMLName  xml.Name   `xml:"nmaprun"`
+	Scanner  string     `xml:"scanner,attr"`
+	Args     string     `xml:"args,attr"`
+	Start    int64      `xml:"start,attr"`
+	Version  string     `xml:"version,attr"`
+	ScanInfo []ScanInfo `xml:"scaninfo"`
+	Hosts    []Host     `xml:"host"`
+	Stats    RunStats   `xml:"runstats"`
+
+	// Truncated is set when the file ends before </nmaprun>, e.g. nmap was stopped by a
+	// timeout; Hosts then holds the hosts nmap finished writing
+	Truncated bool `xml:"-"`
+}
+
+// ScanInfo describes one scan type nmap ran (e.g. syn over tcp)
+type ScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+// Host represents a scanned host
+type Host struct {
+	StartTime int64      `xml:"starttime,attr"`
+	EndTime   int64      `xml:"endtime,attr"`
+	Status    Status     `xml:"status"`
+	Addresses []Address  `xml:"address"`
+	Hostnames []Hostname `xml:"hostnames>hostname"`
+	Ports     Ports      `xml:"ports"`
+	OS        OS         `xml:"os"`
+	Uptime    *Uptime    `xml:"uptime"`
+	Distance  *Distance  `xml:"distance"`
+	Scripts   []Script   `xml:"hostscript>script"` // Host-level NSE results (e.g. smb-os-discovery)
+}
+
+// Address represents host address information
+type Address struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+	Vendor   string `xml:"vendor,attr"`
+}
+
+// Hostname is a name nmap resolved or was given for the host
+type Hostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"` // "user" or "PTR"
+}
+
+// Status represents host status
+type Status struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+// Ports represents the ports section
+type Ports struct {
+	ExtraPorts []ExtraPorts `xml:"extraports"`
+	Ports      []Port       `xml:"port"`
+}
+
+// ExtraPorts summarizes ports nmap did not list one by one (e.g. 995 closed)
+type ExtraPorts struct {
+	State string `xml:"state,attr"`
+	Count int    `xml:"count,attr"`
+}
+
+// Port represents a single port
+type Port struct {
+	Protocol string   `xml:"protocol,attr"`
+	PortID   int      `xml:"portid,attr"`
+	State    State    `xml:"state"`
+	Service  Service  `xml:"service"`
+	Scripts  []Script `xml:"script"`
+}
+
+// State represents port state
+type State struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+	TTL    int    `xml:"reason_ttl,attr"`
+}
+
+// Service represents service information
+type Service struct {
+	Name       string   `xml:"name,attr"`
+	Product    string   `xml:"product,attr"`
+	Version    string   `xml:"version,attr"`
+	ExtraInfo  string   `xml:"extrainfo,attr"`
+	OSType     string   `xml:"ostype,attr"`
+	Tunnel     string   `xml:"tunnel,attr"` // "ssl" when nmap detected the service over TLS
+	Method     string   `xml:"method,attr"` // "probed" or "table" (guessed from the port number)
+	Confidence int      `xml:"conf,attr"`
+	CPEs       []string `xml:"cpe"`
+}
+
+// Script is the result of one NSE script
+type Script struct {
+	ID       string        `xml:"id,attr"`
+	Output   string        `xml:"output,attr"`
+	Elements []ScriptElem  `xml:"elem"`
+	Tables   []ScriptTable `xml:"table"`
+}
+
+// ScriptElem is a key/value a script reported
+type ScriptElem struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ScriptTable is a (possibly nested, possibly unkeyed) group of script values
+type ScriptTable struct {
+	Key      string        `xml:"key,attr"`
+	Elements []ScriptElem  `xml:"elem"`
+	Tables   []ScriptTable `xml:"table"`
+}
+
+// OS holds nmap's OS detection results (-O)
+type OS struct {
+	Matches   []OSMatch  `xml:"osmatch"`
+	PortsUsed []PortUsed `xml:"portused"`
+}
+
+// OSMatch is one OS guess, best first
+type OSMatch struct {
+	Name     string    `xml:"name,attr"`
+	Accuracy int       `xml:"accuracy,attr"`
+	Classes  []OSClass `xml:"osclass"`
+}
+
+// OSClass classifies an OS match
+type OSClass struct {
+	Type     string   `xml:"type,attr"`
+	Vendor   string   `xml:"vendor,attr"`
+	Family   string   `xml:"osfamily,attr"`
+	Gen      string   `xml:"osgen,attr"`
+	Accuracy int      `xml:"accuracy,attr"`
+	CPEs     []string `xml:"cpe"`
+}
+
+// PortUsed is a port OS detection relied on
+type PortUsed struct {
+	State    string `xml:"state,attr"`
+	Protocol string `xml:"proto,attr"`
+	PortID   int    `xml:"portid,attr"`
+}
+
+// Uptime is nmap's uptime guess from TCP timestamps
+type Uptime struct {
+	Seconds  int64  `xml:"seconds,attr"`
+	LastBoot string `xml:"lastboot,attr"`
+}
+
+// Distance is the number of network hops to the host
+type Distance struct {
+	Value int `xml:"value,attr"`
+}
+
+// RunStats represents scan statistics
+type RunStats struct {
+	Finished Finished  `xml:"finished"`
+	Hosts    HostStats `xml:"hosts"`
+}
+
+// Finished represents completion information
+type Finished struct {
+	Time    string  `xml:"time,attr"`
+	Elapsed float64 `xml:"elapsed,attr"`
+	Summary string  `xml:"summary,attr"`
+	Exit    string  `xml:"exit,attr"` // "success" or "error"
+}
+
+// HostStats counts the hosts nmap scanned
+type HostStats struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
+
+// ParseFile parses an nmap XML report; see ParseXML
+func ParseFile(path string) (*NmapRun, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+	return ParseXML(file)
+}
+
+// ParseXML parses an nmap XML report. Hosts are decoded one at a time, so a report cut off
+// mid-scan still yields every host nmap finished writing, with Truncated set. It fails only
+// when the input is not an nmap report at all
+func ParseXML(r io.Reader) (*NmapRun, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false // nmap writes the DOCTYPE and stylesheet instructions some decoders reject
+	var run *NmapRun
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if run == nil {
+				return nil, fmt.Errorf("failed to parse XML: no <nmaprun> element")
+			}
+			run.Truncated = true
+			return run, nil
+		}
+		if err != nil {
+			if run == nil {
+				return nil, fmt.Errorf("failed to parse XML: %w", err)
+			}
+			run.Truncated = true // The rest of the file is unreadable; keep what was decoded
+			return run, nil
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if run == nil {
+				if element.Name.Local != "nmaprun" {
+					return nil, fmt.Errorf("failed to parse XML: root element is <%s>, not <nmaprun>", element.Name.Local)
+				}
+				run = &NmapRun{XMLName: element.Name}
+				run.setAttributes(element.Attr)
+				continue
+			}
+			if err := run.decodeChild(decoder, element); err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || isSyntaxError(err) {
+					run.Truncated = true // The element was cut off; drop it
+					return run, nil
+				}
+				return nil, fmt.Errorf("failed to parse XML: %w", err)
+			}
+		case xml.EndElement:
+			if run != nil && element.Name.Local == "nmaprun" {
+				return run, nil
+			}
+		}
+	}
+}
+
+// decodeChild decodes one top-level element of <nmaprun>; unknown elements are skipped
+func (run *NmapRun) decodeChild(decoder *xml.Decoder, element xml.StartElement) error {
+	switch element.Name.Local {
+	case "host":
+		var host Host
+		if err := decoder.DecodeElement(&host, &element); err != nil {
+			return err
+		}
+		run.Hosts = append(run.Hosts, host)
+	case "scaninfo":
+		var info ScanInfo
+		if err := decoder.DecodeElement(&info, &element); err != nil {
+			return err
+		}
+		run.ScanInfo = append(run.ScanInfo, info)
+	case "runstats":
+		return decoder.DecodeElement(&run.Stats, &element)
+	default:
+		return decoder.Skip()
+	}
+	return nil
+}
+
+func (run *NmapRun) setAttributes(attributes []xml.Attr) {
+	for _, attr := range attributes {
+		switch attr.Name.Local {
+		case "scanner":
+			run.Scanner = attr.Value
+		case "args":
+			run.Args = attr.Value
+		case "start":
+			run.Start, _ = strconv.ParseInt(attr.Value, 10, 64)
+		case "version":
+			run.Version = attr.Value
+		}
+	}
+}
+
+func isSyntaxError(err error) bool {
+	var syntaxErr *xml.SyntaxError
+	return errors.As(err, &syntaxErr)
+}
+
+// Address returns the host's IP address, preferring IPv4
+func (h Host) Address() string {
+	var fallback string
+	for _, addr := range h.Addresses {
+		switch addr.AddrType {
+		case "ipv4":
+			return addr.Addr
+		case "ipv6":
+			if fallback == "" {
+				fallback = addr.Addr
+			}
+		}
+	}
+	return fallback
+}
+
+// Names returns the host's unique hostnames
+func (h Host) Names() []string {
+	var names []string
+	for _, hostname := range h.Hostnames {
+		names = appendUniqueString(names, hostname.Name)
+	}
+	return names
+}
+
+// BestOS returns the most accurate OS match, e.g. "Linux 5.0 - 5.4 (95%)"; empty without -O results
+func (h Host) BestOS() string {
+	var best *OSMatch
+	for i := range h.OS.Matches {
+		if best == nil || h.OS.Matches[i].Accuracy > best.Accuracy {
+			best = &h.OS.Matches[i]
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%d%%)", best.Name, best.Accuracy)
+}
+
+// IsUp reports whether nmap saw the host up
+func (h Host) IsUp() bool {
+	return strings.EqualFold(h.Status.State, "up")
+}
+
+// TLS reports whether nmap detected the service behind TLS
+func (s Service) TLS() bool {
+	return s.Tunnel == "ssl" || strings.HasPrefix(s.Name, "ssl/") || s.Name == "https"
+}
+
+func appendUniqueString(list []string, value string) []string {
+	if value == "" {
+		return list
+	}
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,119 @@
+package nslookup
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// Record is one DNS record from an nslookup answer
+type Record struct {
+	Type  string `json:"type"`  // A, AAAA, CNAME, MX, NS, PTR, SOA or TXT
+	Name  string `json:"name"`  // Lower-cased, without a trailing dot
+	Value string `json:"value"` // Names lower-cased without a trailing dot; TXT data as printed
+}
+
+// answerTypes maps the "<name> <kind> = <value>" answer lines to record types
+var answerTypes = []struct {
+	marker string
+	kind   string
+}{
+	{"mail exchanger = ", "MX"},
+	{"nameserver = ", "NS"},
+	{"canonical name = ", "CNAME"},
+	{"text = ", "TXT"},
+	{"name = ", "PTR"},
+	{"origin = ", "SOA"},
+}
+
+// ReadRecords reads the records answered in nslookup's text output. The header naming the
+// server that answered and the "Authoritative answers can be found from:" section are skipped
+func ReadRecords(outputPath string) ([]Record, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	seen := make(map[Record]bool)
+	add := func(record Record) {
+		if record.Name == "" || record.Value == "" || seen[record] {
+			return
+		}
+		seen[record] = true
+		records = append(records, record)
+	}
+
+	var name string
+	header := true
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			header = false
+			continue
+		case header && (strings.HasPrefix(line, "Server:") || strings.HasPrefix(line, "Address:")):
+			continue
+		case strings.HasPrefix(line, "Authoritative answers can be found from"):
+			return records, scanner.Err()
+		}
+		header = false
+
+		if value, found := strings.CutPrefix(line, "Name:"); found {
+			name = normalizeName(value)
+			continue
+		}
+		if value, found := strings.CutPrefix(line, "Address:"); found {
+			add(addressRecord(name, value))
+			continue
+		}
+		if host, address, found := strings.Cut(line, " has AAAA address "); found {
+			add(addressRecord(normalizeName(host), address))
+			continue
+		}
+
+		matched := false
+		for _, answer := range answerTypes {
+			before, value, found := strings.Cut(line, answer.marker)
+			if !found {
+				continue
+			}
+			matched = true
+			if owner := normalizeName(before); owner != "" {
+				name = owner
+			}
+			value = strings.TrimSpace(value)
+			if answer.kind != "TXT" {
+				value = normalizeName(value)
+			}
+			add(Record{Type: answer.kind, Name: name, Value: value})
+			break
+		}
+		// A bare name introduces the SOA fields printed on the following lines
+		if !matched && !strings.ContainsAny(line, " \t=:*") {
+			name = normalizeName(line)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// addressRecord returns the A or AAAA record for an address line; empty for anything else
+func addressRecord(name, value string) Record {
+	value = strings.TrimSpace(value)
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return Record{}
+	}
+	if ip.To4() != nil {
+		return Record{Type: "A", Name: name, Value: ip.String()}
+	}
+	return Record{Type: "AAAA", Name: name, Value: ip.String()}
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
@@ -0,0 +1,47 @@
+package passive
+
+import (
+	"github.com/neur0map/ipcrawler/internal/findings"
+	sources "github.com/neur0map/ipcrawler/internal/passive"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// FindingsExtractor turns passive source output into host findings
+type FindingsExtractor struct{}
+
+// GetToolName returns the tool name for registration
+func (e *FindingsExtractor) GetToolName() string {
+	return "passive"
+}
+
+// ExtractFindings returns one host finding per subdomain, and a port finding per port a
+// source saw on it. Passive ports have no state: nothing confirmed them open during the run
+func (e *FindingsExtractor) ExtractFindings(outputPath string) ([]findings.Finding, error) {
+	records, err := ReadRecords(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	found := subdomains.HostFindings(records)
+
+	results, err := sources.ReadResults(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Type != sources.ResultService {
+			continue
+		}
+		host := result.Address
+		if host == "" {
+			host = result.Host
+		}
+		found = append(found, findings.Finding{
+			Host:      host,
+			Hostnames: []string{result.Host},
+			Port:      result.Port,
+			Protocol:  "tcp",
+			Service:   result.Service,
+		})
+	}
+	return found, nil
+}
@@ -0,0 +1,60 @@
+package passive
+
+import (
+	"strconv"
+	"strings"
+
+	sources "github.com/neur0map/ipcrawler/internal/passive"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// OutputParser handles the output of IPCrawler's passive sources (`ipcrawler passive`)
+// This is ISOLATED tool-specific code that implements the ToolOutputParser interface
+type OutputParser struct{}
+
+// GetToolName returns the tool name for registration
+func (p *OutputParser) GetToolName() string {
+	return "passive"
+}
+
+// ParseOutput extracts the subdomains, archived URLs and ports the sources reported. The
+// variables are published as {{passive_<name>}}
+func (p *OutputParser) ParseOutput(outputPath string) map[string]string {
+	results, err := sources.ReadResults(outputPath)
+	if err != nil {
+		return map[string]string{
+			"subdomains":      "",
+			"subdomain_count": "0",
+			"error":           "failed to read output file",
+		}
+	}
+	records, _ := ReadRecords(outputPath)
+
+	urls := 0
+	var ports []string
+	for _, result := range results {
+		switch result.Type {
+		case sources.ResultURL:
+			urls++
+		case sources.ResultService:
+			ports = appendUnique(ports, strconv.Itoa(result.Port))
+		}
+	}
+	return map[string]string{
+		"subdomains":      strings.Join(subdomains.Names(records), ","),
+		"subdomain_count": strconv.Itoa(len(records)),
+		"ips":             strings.Join(subdomains.Addresses(records), ","),
+		"sources":         strings.Join(subdomains.Sources(records), ","),
+		"url_count":       strconv.Itoa(urls),
+		"ports":           strings.Join(ports, ","), // Ports Shodan saw open on any host
+	}
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,36 @@
+package passive
+
+import (
+	"strings"
+
+	sources "github.com/neur0map/ipcrawler/internal/passive"
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// ReadRecords reads the subdomains in `ipcrawler passive` output, with the sources that
+// reported them and their addresses
+func ReadRecords(outputPath string) ([]subdomains.Record, error) {
+	results, err := sources.ReadResults(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	var records []subdomains.Record
+	for _, result := range results {
+		if result.Type != sources.ResultSubdomain {
+			continue
+		}
+		records = append(records, subdomains.Record{
+			Name:      result.Host,
+			Sources:   strings.Split(result.Source, ","),
+			Addresses: nonEmpty(result.Address),
+		})
+	}
+	return subdomains.Merge(records), nil
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
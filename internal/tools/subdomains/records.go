@@ -0,0 +1,144 @@
+package subdomains
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+)
+
+// Record is one subdomain reported by an enumeration tool
+type Record struct {
+	Name      string   // Lower-cased, without a trailing dot
+	Domain    string   // Domain that was enumerated, when the tool reports it
+	Sources   []string // Data sources that reported the name (e.g. "crtsh")
+	Addresses []string // Addresses the name resolved to, when the tool resolved it
+}
+
+// Reader reads the records from one tool's output file
+type Reader func(outputPath string) ([]Record, error)
+
+// NormalizeName lower-cases a host name and strips the trailing dot and any wildcard label
+func NormalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(name, ".")
+	return strings.TrimPrefix(name, "*.")
+}
+
+// IsHostname reports whether name looks like a DNS name with at least two labels
+func IsHostname(name string) bool {
+	name = NormalizeName(name)
+	if !strings.Contains(name, ".") || net.ParseIP(name) != nil {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubdomain reports whether name is a subdomain of domain (the domain itself is not). Every
+// name passes for an empty domain or an IP address, which no subdomain can belong to
+func IsSubdomain(name, domain string) bool {
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host // Triggered workflows scan "host:port"
+	}
+	domain = NormalizeName(domain)
+	if domain == "" || net.ParseIP(domain) != nil {
+		return true
+	}
+	return strings.HasSuffix(name, "."+domain)
+}
+
+// Merge combines records for the same name, keeping every source and address
+func Merge(records []Record) []Record {
+	index := make(map[string]int)
+	var merged []Record
+	for _, record := range records {
+		record.Name = NormalizeName(record.Name)
+		if record.Name == "" {
+			continue
+		}
+		if i, exists := index[record.Name]; exists {
+			merged[i].Sources = appendUnique(merged[i].Sources, record.Sources...)
+			merged[i].Addresses = appendUnique(merged[i].Addresses, record.Addresses...)
+			if merged[i].Domain == "" {
+				merged[i].Domain = record.Domain
+			}
+			continue
+		}
+		index[record.Name] = len(merged)
+		merged = append(merged, Record{
+			Name:      record.Name,
+			Domain:    record.Domain,
+			Sources:   appendUnique(nil, record.Sources...),
+			Addresses: appendUnique(nil, record.Addresses...),
+		})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// Names returns the records' names
+func Names(records []Record) []string {
+	names := make([]string, 0, len(records))
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	return names
+}
+
+// Addresses returns the unique addresses the records resolved to
+func Addresses(records []Record) []string {
+	var addresses []string
+	for _, record := range records {
+		addresses = appendUnique(addresses, record.Addresses...)
+	}
+	return addresses
+}
+
+// Sources returns the unique data sources that reported the records
+func Sources(records []Record) []string {
+	var sources []string
+	for _, record := range records {
+		sources = appendUnique(sources, record.Sources...)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// appendUnique appends the values not already in list, skipping empty ones
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := value == ""
+		for _, existing := range list {
+			if existing == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// HostFindings returns a host finding for each record: one per resolved address, named by the
+// subdomain, or the subdomain itself when it was not resolved
+func HostFindings(records []Record) []findings.Finding {
+	var found []findings.Finding
+	for _, record := range records {
+		if len(record.Addresses) == 0 {
+			found = append(found, findings.Finding{Host: record.Name, Hostnames: []string{record.Name}})
+			continue
+		}
+		for _, address := range record.Addresses {
+			found = append(found, findings.Finding{Host: address, Hostnames: []string{record.Name}})
+		}
+	}
+	return found
+}
@@ -0,0 +1,129 @@
+package subdomains
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResultCombiner deduplicates the subdomains found by every enumeration tool in a workspace
+// into {{discovered_subdomains}}. It is registered for each tool it has a reader for, so
+// whichever tool's step combines last publishes the names found by all of them
+type ResultCombiner struct {
+	readers map[string]Reader      // Keyed by tool name, the prefix of its scan file names
+	allow   func(name string) bool // Drops the names it returns false for (nil = keep all)
+}
+
+// NewResultCombiner creates a combiner that reads the given tools' output files
+func NewResultCombiner(readers map[string]Reader) *ResultCombiner {
+	return &ResultCombiner{readers: readers}
+}
+
+// SetFilter drops every name allow returns false for, e.g. the ones outside an engagement's scope
+func (rc *ResultCombiner) SetFilter(allow func(name string) bool) {
+	rc.allow = allow
+}
+
+// GetToolName returns the name the combiner is known by
+func (rc *ResultCombiner) GetToolName() string {
+	return "subdomains"
+}
+
+// ValidateCombinerOptions rejects combiner options; the subdomain combiner has none
+func ValidateCombinerOptions(raw map[string]string) error {
+	for key := range raw {
+		return fmt.Errorf("unsupported subdomain combiner option: %s", key)
+	}
+	return nil
+}
+
+// CombineResults merges the given output files with the other enumeration tools' results
+// already in the same scans directories. Only subdomains of domain (the scanned target) are
+// kept, so a nameserver or CNAME target in another domain is never scanned
+func (rc *ResultCombiner) CombineResults(outputPaths []string, domain string) map[string]string {
+	files := rc.workspaceFiles(outputPaths)
+
+	var records []Record
+	var tools []string
+	for _, file := range files {
+		reader, tool := rc.readerFor(file)
+		if reader == nil {
+			continue
+		}
+		fileRecords, err := reader(file)
+		if err != nil {
+			continue // Skip files that can't be read
+		}
+		inScope := 0
+		for _, record := range fileRecords {
+			if IsSubdomain(NormalizeName(record.Name), domain) {
+				records = append(records, record)
+				inScope++
+			}
+		}
+		if inScope > 0 {
+			tools = appendUnique(tools, tool)
+		}
+	}
+
+	merged := Merge(records)
+	if rc.allow != nil {
+		kept := merged[:0]
+		for _, record := range merged {
+			if rc.allow(record.Name) {
+				kept = append(kept, record)
+			}
+		}
+		merged = kept
+	}
+	sort.Strings(tools)
+	return map[string]string{
+		"discovered_subdomains":        strings.Join(Names(merged), ","),
+		"discovered_subdomain_count":   strconv.Itoa(len(merged)),
+		"discovered_subdomain_ips":     strings.Join(Addresses(merged), ","),
+		"discovered_subdomain_tools":   strings.Join(tools, ","), // Tools that found at least one
+		"discovered_subdomain_sources": strings.Join(Sources(merged), ","),
+	}
+}
+
+// workspaceFiles returns outputPaths plus every other file in their directories written by a
+// tool the combiner reads
+func (rc *ResultCombiner) workspaceFiles(outputPaths []string) []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, outputPath := range outputPaths {
+		add(outputPath)
+	}
+	for _, outputPath := range outputPaths {
+		entries, err := os.ReadDir(filepath.Dir(outputPath))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(filepath.Dir(outputPath), entry.Name())
+			if reader, _ := rc.readerFor(path); !entry.IsDir() && reader != nil {
+				add(path)
+			}
+		}
+	}
+	return files
+}
+
+// readerFor picks the reader from the "<tool>_<mode>_..." scan file name
+func (rc *ResultCombiner) readerFor(path string) (Reader, string) {
+	tool, _, found := strings.Cut(strings.ToLower(filepath.Base(path)), "_")
+	if !found {
+		return nil, ""
+	}
+	return rc.readers[tool], tool
+}
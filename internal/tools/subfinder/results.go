@@ -0,0 +1,70 @@
+package subfinder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/tools/subdomains"
+)
+
+// result is one line of subfinder -oJ output; "sources" is set with -cs, "ip" with -active -oI
+type result struct {
+	Host    string   `json:"host"`
+	Input   string   `json:"input"`
+	Source  string   `json:"source"`
+	Sources []string `json:"sources"`
+	IP      string   `json:"ip"`
+}
+
+// ReadRecords reads subfinder output: JSON lines (-oJ), or one host per line. Invalid lines
+// are skipped
+func ReadRecords(outputPath string) ([]subdomains.Record, error) {
+	file, err := os.Open(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer file.Close()
+
+	var records []subdomains.Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "{") {
+			// Plain output: "host" or "host,ip" with -oI
+			host, ip, _ := strings.Cut(line, ",")
+			if subdomains.IsHostname(host) {
+				records = append(records, subdomains.Record{Name: host, Addresses: nonEmpty(ip)})
+			}
+			continue
+		}
+
+		var entry result
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Host == "" {
+			continue
+		}
+		records = append(records, subdomains.Record{
+			Name:      entry.Host,
+			Domain:    entry.Input,
+			Sources:   append(nonEmpty(entry.Source), entry.Sources...),
+			Addresses: nonEmpty(entry.IP),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	return subdomains.Merge(records), nil
+}
+
+func nonEmpty(value string) []string {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil
+	}
+	return []string{value}
+}
@@ -0,0 +1,146 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/neur0map/ipcrawler/internal/output"
+)
+
+// FileName is the usage database kept in the user's IPCrawler directory (~/.ipcrawler)
+const FileName = "usage.json"
+
+// Stats is the local usage database: how often each workflow and tool ran and for how long.
+// It is only written when output.usage_stats is enabled and never leaves the machine
+type Stats struct {
+	Runs      int               `json:"runs"`
+	Since     time.Time         `json:"since"` // First recorded run
+	UpdatedAt time.Time         `json:"updated_at"`
+	Workflows map[string]*Entry `json:"workflows"`
+	Tools     map[string]*Entry `json:"tools"` // Keyed by "tool" and "tool mode"
+}
+
+// Entry accumulates the uses of one workflow or tool
+type Entry struct {
+	Uses         int       `json:"uses"`
+	Failures     int       `json:"failures"`
+	TotalSeconds float64   `json:"total_seconds"`
+	LastUsed     time.Time `json:"last_used"`
+}
+
+// NamedEntry is an entry with its workflow or tool name, for sorted listings
+type NamedEntry struct {
+	Name string
+	*Entry
+}
+
+// AverageSeconds is the mean duration of a use
+func (e *Entry) AverageSeconds() float64 {
+	if e.Uses == 0 {
+		return 0
+	}
+	return e.TotalSeconds / float64(e.Uses)
+}
+
+// add records one use
+func (e *Entry) add(success bool, seconds float64, at time.Time) {
+	e.Uses++
+	if !success {
+		e.Failures++
+	}
+	e.TotalSeconds += seconds
+	if at.After(e.LastUsed) {
+		e.LastUsed = at
+	}
+}
+
+// Path returns the usage database's location inside the given user directory
+func Path(userDir string) string {
+	return filepath.Join(userDir, FileName)
+}
+
+// Load reads the usage database; a missing file is an empty database
+func Load(path string) (*Stats, error) {
+	stats := &Stats{Workflows: make(map[string]*Entry), Tools: make(map[string]*Entry)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage stats: %v", err)
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to parse usage stats %s: %v", path, err)
+	}
+	if stats.Workflows == nil {
+		stats.Workflows = make(map[string]*Entry)
+	}
+	if stats.Tools == nil {
+		stats.Tools = make(map[string]*Entry)
+	}
+	return stats, nil
+}
+
+// Save writes the usage database, replacing the previous file atomically
+func (s *Stats) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage stats directory: %v", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage stats: %v", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write usage stats: %v", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Record adds a finished run's workflows and tool executions. Skipped steps ran no tool and are not counted
+func (s *Stats) Record(report output.RunReport) {
+	s.Runs++
+	if s.Since.IsZero() || report.StartedAt.Before(s.Since) {
+		s.Since = report.StartedAt
+	}
+	s.UpdatedAt = time.Now()
+
+	for _, workflow := range report.Workflows {
+		entry(s.Workflows, workflow.Name).add(workflow.Status == "completed", workflow.DurationSeconds, workflow.FinishedAt)
+		for _, step := range workflow.Steps {
+			for _, execution := range step.Executions {
+				entry(s.Tools, execution.Tool).add(execution.Success, execution.DurationSeconds, execution.EndTime)
+				if execution.Mode != "" {
+					entry(s.Tools, execution.Tool+" "+execution.Mode).add(execution.Success, execution.DurationSeconds, execution.EndTime)
+				}
+			}
+		}
+	}
+}
+
+// entry returns the named entry, creating it on first use
+func entry(entries map[string]*Entry, name string) *Entry {
+	if entries[name] == nil {
+		entries[name] = &Entry{}
+	}
+	return entries[name]
+}
+
+// Sorted lists entries from most to least used, then by name
+func Sorted(entries map[string]*Entry) []NamedEntry {
+	sorted := make([]NamedEntry, 0, len(entries))
+	for name, e := range entries {
+		sorted = append(sorted, NamedEntry{Name: name, Entry: e})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Uses != sorted[j].Uses {
+			return sorted[i].Uses > sorted[j].Uses
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
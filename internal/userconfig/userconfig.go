@@ -11,8 +11,9 @@ import (
 
 // UserConfig represents the persistent user configuration
 type UserConfig struct {
-	DefaultOutputDirectory string    `yaml:"default_output_directory,omitempty"`
-	LastUpdated            time.Time `yaml:"last_updated"`
+	DefaultOutputDirectory string          `yaml:"default_output_directory,omitempty"`
+	Experimental           map[string]bool `yaml:"experimental,omitempty"` // Per-user experimental feature flags, over configs/experimental.yaml
+	LastUpdated            time.Time       `yaml:"last_updated"`
 }
 
 // getConfigDir returns the user's IPCrawler config directory
@@ -26,6 +27,11 @@ func getConfigDir() (string, error) {
 	return configDir, nil
 }
 
+// Dir returns the user's IPCrawler directory, which also holds local state such as usage statistics
+func Dir() (string, error) {
+	return getConfigDir()
+}
+
 // getConfigPath returns the full path to the user config file
 func getConfigPath() (string, error) {
 	configDir, err := getConfigDir()
@@ -0,0 +1,257 @@
+package viewer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// checkpointInterval is the number of lines between recorded byte offsets.
+// Memory use is one int64 per interval lines regardless of file size
+const checkpointInterval = 1024
+
+// MaxLineBytes caps how much of a single line is kept; the rest is skipped
+const MaxLineBytes = 4096
+
+// File pages through a large text file by seeking instead of loading it into memory.
+// The line index is built lazily as the file is read, so opening is instant
+type File struct {
+	file *os.File
+	size int64
+
+	checkpoints   []int64 // Byte offset of line i*checkpointInterval
+	indexedLines  int     // Number of lines whose start offset is known
+	indexedOffset int64   // Byte offset just after the last indexed line
+	complete      bool    // Whether the whole file has been indexed
+}
+
+// Page is a window of lines read from a File
+type Page struct {
+	Start     int      // 0-based index of the first line
+	Lines     []string // Line contents without trailing newline, truncated to MaxLineBytes
+	EndOffset int64    // Approximate byte offset reached, for progress display
+	EOF       bool     // Whether the page reaches the end of the file
+}
+
+// Open opens a file for paging
+func Open(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	return &File{
+		file:        file,
+		size:        info.Size(),
+		checkpoints: []int64{0},
+	}, nil
+}
+
+// Close closes the underlying file
+func (f *File) Close() error {
+	return f.file.Close()
+}
+
+// Size returns the file size in bytes
+func (f *File) Size() int64 {
+	return f.size
+}
+
+// KnownLines returns the number of lines indexed so far and whether that is the total
+func (f *File) KnownLines() (int, bool) {
+	return f.indexedLines, f.complete
+}
+
+// TotalLines indexes the rest of the file if needed and returns the line count
+func (f *File) TotalLines() (int, error) {
+	if !f.complete {
+		if err := f.scanFrom(f.indexedLines, func(int, []byte) bool { return true }); err != nil {
+			return 0, err
+		}
+	}
+	return f.indexedLines, nil
+}
+
+// ReadPage returns up to count lines starting at the 0-based line start
+func (f *File) ReadPage(start, count int) (*Page, error) {
+	if start < 0 {
+		start = 0
+	}
+	page := &Page{Start: start, Lines: make([]string, 0, count)}
+
+	err := f.scanFrom(start, func(lineNum int, line []byte) bool {
+		page.Lines = append(page.Lines, string(line))
+		return len(page.Lines) < count
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page.EndOffset = f.offsetAfter(start + len(page.Lines))
+	page.EOF = f.complete && start+len(page.Lines) >= f.indexedLines
+	return page, nil
+}
+
+// Matcher reports whether a line matches a search
+type Matcher func(line []byte) bool
+
+// NewMatcher builds a case-insensitive substring matcher, or a regular expression matcher
+func NewMatcher(pattern string, useRegex bool) (Matcher, error) {
+	if useRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search pattern: %w", err)
+		}
+		return re.Match, nil
+	}
+
+	needle := []byte(strings.ToLower(pattern))
+	return func(line []byte) bool {
+		return bytes.Contains(bytes.ToLower(line), needle)
+	}, nil
+}
+
+// SearchForward returns the first line at or after from that matches, or -1
+func (f *File) SearchForward(from int, match Matcher) (int, error) {
+	found := -1
+	err := f.scanFrom(from, func(lineNum int, line []byte) bool {
+		if match(line) {
+			found = lineNum
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// SearchBackward returns the last line before the given line that matches, or -1
+func (f *File) SearchBackward(before int, match Matcher) (int, error) {
+	found := -1
+	if before <= 0 {
+		return found, nil
+	}
+
+	// Only the chunk preceding each checkpoint needs scanning, newest first
+	for chunk := (before - 1) / checkpointInterval; chunk >= 0 && found == -1; chunk-- {
+		end := (chunk + 1) * checkpointInterval
+		if end > before {
+			end = before
+		}
+		err := f.scanFrom(chunk*checkpointInterval, func(lineNum int, line []byte) bool {
+			if lineNum >= end {
+				return false
+			}
+			if match(line) {
+				found = lineNum
+			}
+			return true
+		})
+		if err != nil {
+			return -1, err
+		}
+	}
+	return found, nil
+}
+
+// Each calls fn for every line from the 0-based line start until fn returns false
+func (f *File) Each(start int, fn func(lineNum int, line []byte) bool) error {
+	return f.scanFrom(start, fn)
+}
+
+// scanFrom reads lines beginning at the 0-based line start, extending the index as it goes
+func (f *File) scanFrom(start int, fn func(lineNum int, line []byte) bool) error {
+	// Seek to the closest known position at or before start
+	lineNum := start / checkpointInterval * checkpointInterval
+	if lineNum > f.indexedLines {
+		lineNum = f.indexedLines / checkpointInterval * checkpointInterval
+	}
+	offset := f.checkpoints[lineNum/checkpointInterval]
+
+	if _, err := f.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek: %w", err)
+	}
+	reader := bufio.NewReaderSize(f.file, 64*1024)
+
+	for {
+		line, consumed, err := readLine(reader)
+		if consumed == 0 && err == io.EOF {
+			f.complete = true
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read: %w", err)
+		}
+
+		offset += consumed
+		f.record(lineNum, offset)
+
+		if lineNum >= start && !fn(lineNum, line) {
+			return nil
+		}
+		lineNum++
+
+		if err == io.EOF {
+			f.complete = true
+			return nil
+		}
+	}
+}
+
+// record notes that the line lineNum ends at offset
+func (f *File) record(lineNum int, offset int64) {
+	if lineNum != f.indexedLines {
+		return
+	}
+	f.indexedLines++
+	f.indexedOffset = offset
+	if f.indexedLines%checkpointInterval == 0 {
+		f.checkpoints = append(f.checkpoints, offset)
+	}
+}
+
+// offsetAfter returns the byte offset where line n starts, if known
+func (f *File) offsetAfter(n int) int64 {
+	if n >= f.indexedLines {
+		return f.indexedOffset
+	}
+	// Estimate from the previous checkpoint; exact offsets are only kept per interval
+	return f.checkpoints[n/checkpointInterval]
+}
+
+// readLine reads one line, keeping at most MaxLineBytes, and returns the bytes consumed
+func readLine(reader *bufio.Reader) ([]byte, int64, error) {
+	var line []byte
+	var consumed int64
+
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		consumed += int64(len(chunk))
+		if room := MaxLineBytes - len(line); room > 0 {
+			if len(chunk) > room {
+				line = append(line, chunk[:room]...)
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		return line, consumed, err
+	}
+}
@@ -0,0 +1,225 @@
+// Package loader reads workflow YAML files into executor workflows, in one place: the file
+// layout, strict decoding (an unknown key is an error, not a silently dropped setting), the
+// conversion to executor.Workflow and the checks a workflow must pass before it runs. Files
+// on disk, embedded workflows and `ipcrawler config validate` all go through it
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+)
+
+// File is the YAML layout of a workflow file
+type File struct {
+	Name                   string              `yaml:"name"`
+	Description            string              `yaml:"description"`
+	Category               string              `yaml:"category"`
+	Tags                   []string            `yaml:"tags"`
+	ParallelWorkflow       bool                `yaml:"parallel_workflow"`
+	IndependentExecution   bool                `yaml:"independent_execution"`
+	MaxConcurrentWorkflows int                 `yaml:"max_concurrent_workflows"`
+	WorkflowPriority       string              `yaml:"workflow_priority"`
+	After                  Dependencies        `yaml:"after"`
+	Matrix                 map[string][]string `yaml:"matrix"`
+	Triggers               []Trigger           `yaml:"triggers"`
+	Resources              *Resources          `yaml:"resources"`
+	Steps                  []Step              `yaml:"steps"`
+}
+
+// Step is one step of a workflow file
+type Step struct {
+	Name               string            `yaml:"name"`
+	Tool               string            `yaml:"tool"`
+	Description        string            `yaml:"description"`
+	Modes              []string          `yaml:"modes"`
+	FallbackModes      []string          `yaml:"fallback_modes"`
+	RunIf              string            `yaml:"run_if"`
+	Retry              *RetryPolicy      `yaml:"retry"`
+	Concurrent         bool              `yaml:"concurrent"`
+	CombineResults     bool              `yaml:"combine_results"`
+	DependsOn          Dependencies      `yaml:"depends_on"`
+	StepPriority       string            `yaml:"step_priority"`
+	MaxConcurrentTools int               `yaml:"max_concurrent_tools"`
+	Variables          map[string]string `yaml:"variables"`
+	Parameters         map[string]string `yaml:"parameters"`
+	Combiner           map[string]string `yaml:"combiner"`
+	Inputs             *StepVariables    `yaml:"inputs"`
+	Outputs            *StepVariables    `yaml:"outputs"`
+}
+
+// Dependencies is a step's depends_on or a workflow's after: one name or a list of them
+type Dependencies []string
+
+// UnmarshalYAML accepts a single name as well as a list
+func (d *Dependencies) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var name string
+		if err := node.Decode(&name); err != nil {
+			return err
+		}
+		if name != "" {
+			*d = Dependencies{name}
+		}
+		return nil
+	}
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	*d = names
+	return nil
+}
+
+// RetryPolicy is the retry block of a step
+type RetryPolicy struct {
+	Attempts int      `yaml:"attempts"`
+	Backoff  string   `yaml:"backoff"`
+	RetryOn  []string `yaml:"retry_on"`
+}
+
+// StepVariables is the inputs or outputs block of a step
+type StepVariables struct {
+	Variables []VariableMapping `yaml:"variables"`
+}
+
+// VariableMapping names a variable and the variable it is read from
+type VariableMapping struct {
+	Name   string `yaml:"name"`
+	Source string `yaml:"source"`
+}
+
+// Resources is the resources block of a workflow: what it reserves while it runs
+type Resources struct {
+	Network  string `yaml:"network"` // "light" or "heavy"
+	CPU      string `yaml:"cpu"`     // "light" or "heavy"
+	MemoryMB int    `yaml:"memory_mb"`
+}
+
+// Trigger is one entry of a workflow's triggers
+type Trigger struct {
+	When   string `yaml:"when"`
+	Target string `yaml:"target"`
+}
+
+// LoadWorkflow reads and checks the workflow file at path
+func LoadWorkflow(path string) (*executor.Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow file %s: %v", path, err)
+	}
+	return ParseWorkflow(data, path)
+}
+
+// ParseWorkflow decodes and checks a workflow; name, its path, identifies it in errors and its
+// file name is the workflow's ID
+func ParseWorkflow(data []byte, name string) (*executor.Workflow, error) {
+	file, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow YAML %s: %v", name, err)
+	}
+	workflow, err := file.Workflow()
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow %s: %v", name, err)
+	}
+	workflow.ID = strings.TrimSuffix(filepath.Base(name), ".yaml")
+
+	if err := executor.ExpandMatrix(workflow); err != nil {
+		return nil, fmt.Errorf("invalid matrix in workflow %s: %v", name, err)
+	}
+	for _, validate := range []func(*executor.Workflow) error{executor.ValidateDependencies, executor.ValidateAfter, executor.ValidateConditions, executor.ValidateTriggers, executor.ValidateStepVariables, executor.ValidateResources, executor.ValidateTags} {
+		if err := validate(workflow); err != nil {
+			return nil, fmt.Errorf("invalid workflow %s: %v", name, err)
+		}
+	}
+	return workflow, nil
+}
+
+// Decode reads a workflow file strictly: keys the layout does not have and values of the
+// wrong type are errors. An empty file is an empty workflow
+func Decode(data []byte) (*File, error) {
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&file)
+	var typeErr *yaml.TypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return nil, errors.New(strings.Join(typeErr.Errors, "; ")) // Not one line each
+	case err != nil && !errors.Is(err, io.EOF):
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Workflow converts the file to an executor workflow, before matrix expansion
+func (f *File) Workflow() (*executor.Workflow, error) {
+	workflow := &executor.Workflow{
+		Name:                   f.Name,
+		Description:            f.Description,
+		Category:               f.Category,
+		Tags:                   f.Tags,
+		ParallelWorkflow:       f.ParallelWorkflow,
+		IndependentExecution:   f.IndependentExecution,
+		MaxConcurrentWorkflows: f.MaxConcurrentWorkflows,
+		WorkflowPriority:       f.WorkflowPriority,
+		After:                  f.After,
+		Matrix:                 f.Matrix,
+		Steps:                  make([]*executor.WorkflowStep, len(f.Steps)),
+	}
+	if f.Resources != nil {
+		workflow.Resources = executor.WorkflowResources{Network: f.Resources.Network, CPU: f.Resources.CPU, MemoryMB: f.Resources.MemoryMB}
+	}
+	for _, trigger := range f.Triggers {
+		workflow.Triggers = append(workflow.Triggers, executor.WorkflowTrigger{When: trigger.When, Target: trigger.Target})
+	}
+
+	for i, fileStep := range f.Steps {
+		step := &executor.WorkflowStep{
+			Name:               fileStep.Name,
+			Tool:               fileStep.Tool,
+			Description:        fileStep.Description,
+			Modes:              fileStep.Modes,
+			FallbackModes:      fileStep.FallbackModes,
+			RunIf:              fileStep.RunIf,
+			Concurrent:         fileStep.Concurrent,
+			CombineResults:     fileStep.CombineResults,
+			DependsOn:          fileStep.DependsOn,
+			StepPriority:       fileStep.StepPriority,
+			MaxConcurrentTools: fileStep.MaxConcurrentTools,
+			Variables:          fileStep.Variables,
+			Parameters:         fileStep.Parameters,
+			Combiner:           fileStep.Combiner,
+			Inputs:             fileStep.Inputs.mappings(),
+			Outputs:            fileStep.Outputs.mappings(),
+		}
+		if fileStep.Retry != nil {
+			retry, err := executor.NewRetryPolicy(fileStep.Retry.Attempts, fileStep.Retry.Backoff, fileStep.Retry.RetryOn)
+			if err != nil {
+				return nil, fmt.Errorf("step %q: invalid retry: %v", fileStep.Name, err)
+			}
+			step.Retry = retry
+		}
+		workflow.Steps[i] = step
+	}
+	return workflow, nil
+}
+
+func (v *StepVariables) mappings() []executor.VariableMapping {
+	if v == nil {
+		return nil
+	}
+	mappings := make([]executor.VariableMapping, 0, len(v.Variables))
+	for _, variable := range v.Variables {
+		mappings = append(mappings, executor.VariableMapping{Name: variable.Name, Source: variable.Source})
+	}
+	return mappings
+}
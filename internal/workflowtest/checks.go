@@ -0,0 +1,74 @@
+package workflowtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/output"
+	"github.com/neur0map/ipcrawler/internal/selftest"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// Verify checks a test run against the test's expectations. A run expected to complete must
+// also have every step succeed or be skipped by its run_if
+func Verify(expect Expect, report *output.RunReport, found []findings.Finding) []selftest.Check {
+	var checks []selftest.Check
+
+	run := selftest.Check{Name: "run " + expect.Status, Passed: report.Status == expect.Status}
+	if !run.Passed {
+		run.Detail = strings.TrimSpace(report.Status + ": " + report.Error)
+	}
+	checks = append(checks, run)
+
+	if expect.Status == session.RunStatusCompleted {
+		for _, workflow := range report.Workflows {
+			for _, step := range workflow.Steps {
+				check := selftest.Check{Name: "step " + step.Name, Passed: step.Success || step.Skipped}
+				if !check.Passed {
+					check.Detail = fmt.Sprintf("%s failed: %s", step.Tool, step.Error)
+				}
+				checks = append(checks, check)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(expect.Variables))
+	for name := range expect.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := expect.Variables[name]
+		got, set := report.Variables[name]
+		check := selftest.Check{Name: fmt.Sprintf("variable %s = %q", name, want), Passed: set && got == want}
+		if !set {
+			check.Detail = "not set"
+		} else if !check.Passed {
+			check.Detail = fmt.Sprintf("got %q", got)
+		}
+		checks = append(checks, check)
+	}
+	for _, name := range expect.Produces {
+		check := selftest.Check{Name: "variable " + name + " set", Passed: report.Variables[name] != ""}
+		if !check.Passed {
+			check.Detail = "not set or empty"
+		}
+		checks = append(checks, check)
+	}
+
+	for _, count := range expect.Findings {
+		name := fmt.Sprintf("%d findings", count.Count)
+		if count.Query != "" {
+			name += " matching " + count.Query
+		}
+		got := len(count.query.Filter(found))
+		check := selftest.Check{Name: name, Passed: got == count.Count}
+		if !check.Passed {
+			check.Detail = fmt.Sprintf("got %d", got)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,136 @@
+package workflowtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/neur0map/ipcrawler/internal/executor"
+	"github.com/neur0map/ipcrawler/internal/findings"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// DefaultTarget is scanned when a test names no target. No tool runs, so it is never contacted
+const DefaultTarget = "127.0.0.1"
+
+// Spec is a workflow test: the workflow, the outputs its tools are given instead of running,
+// and what the run must produce from them
+type Spec struct {
+	Workflow string    `yaml:"workflow"` // Workflow file, relative to the test file
+	Target   string    `yaml:"target"`   // DefaultTarget when empty
+	Fixtures []Fixture `yaml:"fixtures"`
+	Expect   Expect    `yaml:"expect"`
+
+	path string
+}
+
+// Fixture is what one tool produces. The first fixture matching an execution's tool, step and
+// mode is used; an execution no fixture matches fails
+type Fixture struct {
+	Tool     string `yaml:"tool"`
+	Step     string `yaml:"step"`      // Any step when empty
+	Mode     string `yaml:"mode"`      // Any mode when empty
+	Output   string `yaml:"output"`    // Copied to the file the tool is told to write; printed when it is told none
+	Stdout   string `yaml:"stdout"`    // Printed on standard output
+	ExitCode int    `yaml:"exit_code"` // Exit code of the execution
+}
+
+// Expect is what the run must produce
+type Expect struct {
+	Status    string            `yaml:"status"`    // Run status; completed when empty
+	Variables map[string]string `yaml:"variables"` // Variables that must have exactly these values
+	Produces  []string          `yaml:"produces"`  // Variables that must be set, whatever their value
+	Findings  []FindingCount    `yaml:"findings"`
+}
+
+// FindingCount expects a number of findings
+type FindingCount struct {
+	Query string `yaml:"query"` // ipcrawler search query; every finding when empty
+	Count int    `yaml:"count"`
+
+	query *findings.Query
+}
+
+// Load reads a workflow test file strictly and checks its fixture files and findings queries.
+// Fixture paths are made absolute, relative to the test file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&spec)
+	var typeErr *yaml.TypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(typeErr.Errors, "; "))
+	case errors.Is(err, io.EOF):
+		return nil, fmt.Errorf("%s is empty", path)
+	case err != nil:
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	spec.path = path
+	if spec.Workflow == "" {
+		return nil, fmt.Errorf("%s: workflow is required", path)
+	}
+	spec.Workflow = spec.resolve(spec.Workflow)
+	if spec.Target == "" {
+		spec.Target = DefaultTarget
+	}
+	if spec.Expect.Status == "" {
+		spec.Expect.Status = session.RunStatusCompleted
+	}
+	for i := range spec.Fixtures {
+		fixture := &spec.Fixtures[i]
+		if fixture.Tool == "" {
+			return nil, fmt.Errorf("%s: fixture %d names no tool", path, i+1)
+		}
+		for _, file := range []*string{&fixture.Output, &fixture.Stdout} {
+			if *file == "" {
+				continue
+			}
+			*file = spec.resolve(*file)
+			if _, err := os.Stat(*file); err != nil {
+				return nil, fmt.Errorf("%s: fixture %d (%s): %v", path, i+1, fixture.Tool, err)
+			}
+		}
+	}
+	for i := range spec.Expect.Findings {
+		count := &spec.Expect.Findings[i]
+		if count.query, err = findings.ParseQuery(count.Query); err != nil {
+			return nil, fmt.Errorf("%s: invalid findings query '%s': %v", path, count.Query, err)
+		}
+	}
+	return &spec, nil
+}
+
+// resolve makes a path from the test file absolute
+func (s *Spec) resolve(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(s.path), path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// Fixture returns the fixture for a tool execution, or nil when none matches
+func (s *Spec) Fixture(call executor.ToolCall) *Fixture {
+	for i := range s.Fixtures {
+		fixture := &s.Fixtures[i]
+		if fixture.Tool == call.Tool && (fixture.Step == "" || fixture.Step == call.Step) && (fixture.Mode == "" || fixture.Mode == call.Mode) {
+			return fixture
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package workspace defines the layout of a scan workspace, <base>/<target>_<unix time>_<short
+// scan ID> (prefixed with <session name>_ for a named session), in one place: creating it, the
+// paths of its directories and log files, its run manifest, its log files, pinning and
+// archiving. The CLI, host discovery, the execution engine and the report writers all go
+// through it
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/neur0map/ipcrawler/internal/archive"
+	"github.com/neur0map/ipcrawler/internal/session"
+)
+
+// Directories of a workspace, relative to its root
+const (
+	ScansDir   = "scans"   // Output files of tool runs
+	RawDir     = "raw"     // Interleaved stdout and stderr of every tool
+	ReportsDir = "reports" // Reports rendered when the run ends
+	LogsDir    = "logs"
+)
+
+// RawLogFileName is the raw tool output log under RawDir
+const RawLogFileName = "tool_output.log"
+
+// subdirs are created with every workspace
+var subdirs = []string{"logs/info", "logs/debug", "logs/error", "logs/warning", RawDir, ScansDir, ReportsDir}
+
+// namePattern matches workspace directory names: [<session name>_]<target>_<unix time>_<short scan ID>
+var namePattern = regexp.MustCompile(`^(.+)_(\d{9,})_([0-9a-f-]+)$`)
+
+// Workspace is one scan workspace directory
+type Workspace struct {
+	Dir string
+}
+
+// Paths lists the files and directories of a workspace
+type Paths struct {
+	Root     string
+	Scans    string
+	Raw      string
+	Reports  string
+	Logs     string
+	Manifest string
+
+	ExecutionLog  string // logs/debug/execution.log: the CLI's and the engine's debug log
+	WorkflowLog   string // logs/info/workflow.log: the CLI's and the orchestrator's info log
+	WorkflowDebug string // logs/debug/workflow.log: the orchestrator's debug log
+	ToolsDebug    string // logs/debug/tools.log: the engine's debug log
+	ToolsInfo     string // logs/info/tools.log: the engine's info log
+	ErrorLog      string // logs/errors/error.log: tool errors
+	RawLog        string // raw/tool_output.log
+}
+
+// Loggers are the file loggers of a run, opened by Loggers
+type Loggers struct {
+	Debug *log.Logger
+	Info  *log.Logger
+	Raw   *log.Logger
+}
+
+// New returns the workspace in dir without touching the filesystem
+func New(dir string) *Workspace {
+	return &Workspace{Dir: dir}
+}
+
+// SanitizeTarget converts a target (IP, hostname, CIDR) to a safe directory name
+func SanitizeTarget(target string) string {
+	replacer := strings.NewReplacer(".", "_", ":", "_", "/", "_", "\\", "_")
+	return replacer.Replace(target)
+}
+
+// Name returns the directory name of a new workspace for target, in the named session when
+// sessionName is set
+func Name(sessionName, target string, created time.Time, scanID string) string {
+	return fmt.Sprintf("%s_%d_%s", Prefix(sessionName, target), created.Unix(), session.ShortScanID(scanID))
+}
+
+// Prefix is what the names of target's workspaces in a session start with, before the
+// creation time
+func Prefix(sessionName, target string) string {
+	return session.SessionFileName(sessionName, SanitizeTarget(target))
+}
+
+// ParseName reads the creation time from a workspace directory name
+func ParseName(name string) (time.Time, bool) {
+	match := namePattern.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// Create makes a new workspace for target under baseDir
+func Create(baseDir, sessionName, target, scanID string, created time.Time, dirPerm os.FileMode) (*Workspace, error) {
+	w := New(filepath.Join(baseDir, Name(sessionName, target, created, scanID)))
+	if err := w.Ensure(dirPerm); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Ensure creates the workspace's directories that do not exist yet, e.g. when resuming a run
+func (w *Workspace) Ensure(dirPerm os.FileMode) error {
+	if err := os.MkdirAll(w.Dir, dirPerm); err != nil {
+		return err
+	}
+	for _, subdir := range subdirs {
+		if err := os.MkdirAll(filepath.Join(w.Dir, filepath.FromSlash(subdir)), dirPerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open returns the workspace in dir, the current directory when empty, after checking it is
+// one: it has a run manifest or a scans directory
+func Open(dir string) (*Workspace, error) {
+	if dir == "" {
+		dir = "."
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace path: %v", err)
+	}
+	if !IsWorkspace(absDir) {
+		return nil, fmt.Errorf("%s is not an IPCrawler workspace (no %s or scans directory)", absDir, session.ManifestFileName)
+	}
+	return New(absDir), nil
+}
+
+// IsWorkspace reports whether dir has a run manifest or a scans directory
+func IsWorkspace(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, session.ManifestFileName)); err == nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, ScansDir))
+	return err == nil
+}
+
+// Paths returns the paths of the workspace's directories and log files
+func (w *Workspace) Paths() Paths {
+	join := func(elem ...string) string { return filepath.Join(append([]string{w.Dir}, elem...)...) }
+	return Paths{
+		Root:          w.Dir,
+		Scans:         join(ScansDir),
+		Raw:           join(RawDir),
+		Reports:       join(ReportsDir),
+		Logs:          join(LogsDir),
+		Manifest:      join(session.ManifestFileName),
+		ExecutionLog:  join(LogsDir, "debug", "execution.log"),
+		WorkflowLog:   join(LogsDir, "info", "workflow.log"),
+		WorkflowDebug: join(LogsDir, "debug", "workflow.log"),
+		ToolsDebug:    join(LogsDir, "debug", "tools.log"),
+		ToolsInfo:     join(LogsDir, "info", "tools.log"),
+		ErrorLog:      join(LogsDir, "errors", "error.log"),
+		RawLog:        join(RawDir, RawLogFileName),
+	}
+}
+
+// SessionInfo reads the workspace's run manifest
+func (w *Workspace) SessionInfo() (*session.RunManifest, error) {
+	return session.LoadManifest(w.Dir)
+}
+
+// WriteSessionInfo writes the workspace's run manifest
+func (w *Workspace) WriteSessionInfo(manifest *session.RunManifest, perm os.FileMode) error {
+	return session.WriteManifest(w.Dir, manifest, perm)
+}
+
+// Loggers opens the CLI's file loggers of a run (execution, workflow and raw tool output
+// logs), passing what they write through redact and tagging every entry with the scan ID and
+// the session name, when there is one
+func (w *Workspace) Loggers(scanID, sessionName string, perm os.FileMode, redact func(io.Writer) io.Writer) (*Loggers, error) {
+	paths := w.Paths()
+	open := func(path, prefix, what string) (*log.Logger, error) {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %v", what, err)
+		}
+		logger := log.NewWithOptions(redact(file), log.Options{
+			ReportCaller:    false,
+			ReportTimestamp: true,
+			TimeFormat:      time.RFC3339,
+			Prefix:          prefix,
+		})
+		logger = logger.With("scan_id", scanID)
+		if sessionName != "" {
+			logger = logger.With("session", sessionName)
+		}
+		return logger, nil
+	}
+
+	var loggers Loggers
+	var err error
+	if loggers.Debug, err = open(paths.ExecutionLog, "DEBUG", "debug log file"); err != nil {
+		return nil, err
+	}
+	if loggers.Info, err = open(paths.WorkflowLog, "INFO", "info log file"); err != nil {
+		return nil, err
+	}
+	if loggers.Raw, err = open(paths.RawLog, "RAW", "raw output file"); err != nil {
+		return nil, err
+	}
+	return &loggers, nil
+}
+
+// Pin marks the workspace so the retention policy never removes it, or clears the mark
+func (w *Workspace) Pin(pinned bool, perm os.FileMode) error {
+	manifest, err := w.SessionInfo()
+	if err != nil {
+		return fmt.Errorf("%s: %v (only workspaces with a manifest can be pinned)", w.Dir, err)
+	}
+	manifest.Pinned = pinned
+	return w.WriteSessionInfo(manifest, perm)
+}
+
+// Archive packs the workspace into archivePath (see internal/archive)
+func (w *Workspace) Archive(archivePath string, perm os.FileMode) (*archive.Summary, error) {
+	return archive.Create(w.Dir, archivePath, perm)
+}
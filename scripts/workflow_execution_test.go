@@ -43,9 +43,9 @@ func NewWorkflowExecutionSimulator(target string) (*WorkflowExecutionSimulator,
 	workflowOrchestrator := executor.NewWorkflowOrchestrator(workflowExecutor, cfg)
 
 	// Set up status callback for real-time monitoring
-	workflowOrchestrator.SetStatusCallback(func(workflowName, target, status, message string) {
-		timestamp := time.Now().Format("15:04:05.000")
-		fmt.Printf("[%s] [%s] %s -> %s: %s\n", timestamp, status, workflowName, target, message)
+	workflowOrchestrator.Events().Subscribe(func(event executor.Event) {
+		timestamp := event.Time.Format("15:04:05.000")
+		fmt.Printf("[%s] [%s] %s -> %s: %s\n", timestamp, event.Status(), event.Workflow, event.Target, event.Message)
 	})
 
 	return &WorkflowExecutionSimulator{